@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/communication"
+	"github.com/aldebaranode/syncguard/internal/crypto"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var certCmd = &cobra.Command{
+	Use:   "cert",
+	Short: "Create a cluster CA and issue node certificates for peer TLS",
+}
+
+var certInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Create the cluster certificate authority",
+	Long: `Creates a small cluster CA at tls.ca_file / tls.ca_key_file. Run this once
+on a trusted host, issue certificates for every node, then copy each node its
+certificate, key and the CA certificate. Keep the CA key off the validator
+hosts if you can.`,
+	Run: runCertInitCommand,
+}
+
+var certIssueCmd = &cobra.Command{
+	Use:   "issue",
+	Short: "Issue a node certificate signed by the cluster CA",
+	Long: `Issues a certificate for --node valid for server and client authentication.
+For this node the files are written to tls.cert_file / tls.key_file; for other
+nodes they are written next to the CA as <node>.pem and <node>-key.pem.`,
+	Run: runCertIssueCommand,
+}
+
+var certOptions struct {
+	days  int
+	force bool
+	node  string
+	sans  []string
+}
+
+func init() {
+	certCmd.PersistentFlags().IntVar(&certOptions.days, "days", 0,
+		"Validity in days (default 3650 for the CA, 825 for node certificates)")
+	certCmd.PersistentFlags().BoolVar(&certOptions.force, "force", false,
+		"Overwrite existing files")
+	certIssueCmd.Flags().StringVar(&certOptions.node, "node", "",
+		"Node ID to issue for (default: this node)")
+	certIssueCmd.Flags().StringSliceVar(&certOptions.sans, "san", nil,
+		"Additional hostnames or IPs the node is reached at (repeatable)")
+
+	certCmd.AddCommand(certInitCmd, certIssueCmd)
+	rootCmd.AddCommand(certCmd)
+}
+
+func runCertInitCommand(cmd *cobra.Command, args []string) {
+	cfg := loadConfigOrExit()
+
+	days := certOptions.days
+	if days == 0 {
+		days = 3650
+	}
+
+	ca, err := crypto.GenerateCA("SyncGuard Cluster CA", time.Duration(days)*24*time.Hour)
+	if err != nil {
+		log.Fatalf("Failed to create CA: %v", err)
+	}
+
+	writePEMOrExit(cfg.TLS.CAFile, ca.CertPEM, 0644)
+	writePEMOrExit(cfg.TLS.CAKeyFile, ca.KeyPEM, 0600)
+	fmt.Printf("Cluster CA written to %s\n", cfg.TLS.CAFile)
+}
+
+func runCertIssueCommand(cmd *cobra.Command, args []string) {
+	cfg := loadConfigOrExit()
+
+	node := certOptions.node
+	if node == "" {
+		node = cfg.Node.ID
+	}
+	days := certOptions.days
+	if days == 0 {
+		days = 825
+	}
+
+	caCert, err := os.ReadFile(cfg.TLS.CAFile)
+	if err != nil {
+		log.Fatalf("Failed to read CA certificate (run 'syncguard cert init' first): %v", err)
+	}
+	caKey, err := os.ReadFile(cfg.TLS.CAKeyFile)
+	if err != nil {
+		log.Fatalf("Failed to read CA key: %v", err)
+	}
+
+	sans := certOptions.sans
+	// For this node, include the peer addresses other nodes will dial
+	if node == cfg.Node.ID {
+		sans = append(sans, "localhost", "127.0.0.1")
+	}
+	for _, peer := range cfg.Peers {
+		if peer.ID == node {
+			sans = append(sans, communication.AddressHost(peer.Address))
+		}
+	}
+
+	bundle, err := crypto.IssueCertificate(&crypto.CertBundle{CertPEM: caCert, KeyPEM: caKey},
+		node, sans, time.Duration(days)*24*time.Hour)
+	if err != nil {
+		log.Fatalf("Failed to issue certificate: %v", err)
+	}
+
+	certPath, keyPath := cfg.TLS.CertFile, cfg.TLS.KeyFile
+	if node != cfg.Node.ID {
+		dir := filepath.Dir(cfg.TLS.CAFile)
+		certPath = filepath.Join(dir, node+".pem")
+		keyPath = filepath.Join(dir, node+"-key.pem")
+	}
+
+	writePEMOrExit(certPath, bundle.CertPEM, 0644)
+	writePEMOrExit(keyPath, bundle.KeyPEM, 0600)
+	fmt.Printf("Certificate for %s written to %s (key: %s)\n", node, certPath, keyPath)
+}
+
+// writePEMOrExit writes a PEM file, refusing to overwrite unless --force
+func writePEMOrExit(path string, data []byte, perm os.FileMode) {
+	if _, err := os.Stat(path); err == nil && !certOptions.force {
+		log.Fatalf("%s already exists (use --force to overwrite)", path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		log.Fatalf("Failed to create %s: %v", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, data, perm); err != nil {
+		log.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
@@ -0,0 +1,457 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/server"
+	"github.com/aldebaranode/syncguard/pkg/client"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var clusterCmd = &cobra.Command{
+	Use:   "cluster",
+	Short: "Query and control every node of the cluster",
+	Long: `Talks to the admin API of this node (admin.listen) and of every peer with an
+admin_url, concurrently. admin.token is sent to all of them.`,
+}
+
+var clusterStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show every node's role, health and height",
+	Run:   runClusterStatusCommand,
+}
+
+var clusterPauseCmd = &cobra.Command{
+	Use:   "pause",
+	Short: "Suspend automatic failover, failback and drills on every node",
+	Run:   runClusterPauseCommand,
+}
+
+var clusterResumeCmd = &cobra.Command{
+	Use:   "resume",
+	Short: "Re-enable automatic failover on every node",
+	Run:   runClusterResumeCommand,
+}
+
+var clusterHandoffCmd = &cobra.Command{
+	Use:   "handoff",
+	Short: "Hand validator duties from the active node to its standby",
+	Run:   runClusterHandoffCommand,
+}
+
+var clusterRetireSecretCmd = &cobra.Command{
+	Use:   "retire-secret",
+	Short: "Stop accepting the previous cluster secret on every node",
+	Long: `Ends a cluster secret rotation. Every node must answer and report the same
+current secret; then each stops accepting previous_secret at once. Remove
+previous_secret from every config file afterwards, or a restarted node accepts
+it again.`,
+	Run: runClusterRetireSecretCommand,
+}
+
+var clusterApproveCmd = &cobra.Command{
+	Use:   "approve [request-id]",
+	Short: "Approve the failover awaiting approval",
+	Long: `With failover.approval enabled, the active node prepares a failover when its
+health checks give up and waits for approval. This approves it, and the node
+hands validator duties to its standby at once. Without a request ID the
+pending request is approved, whichever it is.`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runClusterApproveCommand,
+}
+
+var clusterRejectCmd = &cobra.Command{
+	Use:   "reject [request-id]",
+	Short: "Reject the failover awaiting approval",
+	Long: `Keeps the failing node active. It does not ask again, nor auto-approve, until
+its health checks pass; hand off manually if that changes.`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runClusterRejectCommand,
+}
+
+var clusterConfirmActiveCmd = &cobra.Command{
+	Use:   "confirm-active",
+	Short: "Let a node held on first boot or after a reboot take the validator role",
+	Long: `A node configured active that starts with no saved state in node.data_dir and
+no reachable peer does not sign: it may be a copy of the active node's config.
+Neither does one whose host rebooted when its validator data fails its checks or
+no peer answers. Once you are sure no other node signs for this validator, this
+lets it take the role. It is refused while any node reports itself active.`,
+	Run: runClusterConfirmActiveCommand,
+}
+
+var failbackCmd = &cobra.Command{
+	Use:   "failback",
+	Short: "Return validator duties from the standby to the primary",
+	Long: `Asks the primary's admin API to reclaim validator duties. This is the only
+way back to the primary when failover.sticky_active is set. The primary must be
+healthy and within failover.failback_max_lag blocks of the tip; the stability
+period and hold-off of automatic failback are skipped. You are asked to confirm
+first; --force skips the question.`,
+	Run: runFailbackCommand,
+}
+
+var failoverCmd = &cobra.Command{
+	Use:   "failover",
+	Short: "Hand validator duties from the active node to its standby",
+	Long: `A planned switchover, e.g. before maintenance on the active node. Asks the
+active node's admin API to hand over to its healthy, passive standby, with the
+checks of syncguard cluster handoff. You are asked to confirm first; --force
+skips the question, for scripts.`,
+	Run: runFailoverCommand,
+}
+
+var clusterOptions struct {
+	timeout time.Duration
+	force   bool
+}
+
+func init() {
+	clusterCmd.PersistentFlags().DurationVar(&clusterOptions.timeout, "timeout", time.Minute,
+		"Overall time limit for the command")
+
+	clusterCmd.AddCommand(clusterStatusCmd)
+	clusterCmd.AddCommand(clusterPauseCmd)
+	clusterCmd.AddCommand(clusterResumeCmd)
+	clusterCmd.AddCommand(clusterHandoffCmd)
+	clusterCmd.AddCommand(clusterRetireSecretCmd)
+	clusterCmd.AddCommand(clusterApproveCmd)
+	clusterCmd.AddCommand(clusterRejectCmd)
+	clusterCmd.AddCommand(clusterConfirmActiveCmd)
+	rootCmd.AddCommand(clusterCmd)
+
+	for _, cmd := range []*cobra.Command{failoverCmd, failbackCmd} {
+		cmd.Flags().DurationVar(&clusterOptions.timeout, "timeout", time.Minute,
+			"Time limit for the handover, once confirmed")
+		cmd.Flags().BoolVar(&clusterOptions.force, "force", false,
+			"Do not ask for confirmation")
+		rootCmd.AddCommand(cmd)
+	}
+}
+
+func runClusterStatusCommand(cmd *cobra.Command, args []string) {
+	c := clusterClientOrExit(loadConfigOrExit())
+	ctx, cancel := context.WithTimeout(context.Background(), clusterOptions.timeout)
+	defer cancel()
+
+	view := c.Status(ctx)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NODE\tACTIVE\tHEALTHY\tPAUSED\tHEIGHT\tFAILURES\tIN STATE\tNEXT CHECK\tLAST TRANSITION\tERROR")
+	for _, nv := range view.Nodes {
+		if nv.Err != nil {
+			fmt.Fprintf(w, "%s\t-\t-\t-\t-\t-\t-\t-\t-\t%v\n", nv.Node.ID, nv.Err)
+			continue
+		}
+		s := nv.Status
+		failures, inState, next := progressColumns(s.Progress)
+		fmt.Fprintf(w, "%s\t%v\t%v\t%v\t%d\t%s\t%s\t%s\t%s\t\n",
+			nv.Node.ID, s.Active, s.Healthy, s.Paused, s.Height, failures, inState, next, lastTransition(s.Transitions))
+	}
+	w.Flush()
+
+	// Without admin_url on the peers, they are only known through heartbeats
+	for _, nv := range view.Nodes {
+		if len(view.Nodes) > 1 || nv.Status == nil || nv.Status.PeerProgress == nil {
+			continue
+		}
+		failures, inState, next := progressColumns(nv.Status.PeerProgress)
+		fmt.Printf("\nPeer of %s (via heartbeat): %s, failures %s, in state %s, next check %s\n",
+			nv.Node.ID, nv.Status.PeerProgress.Role, failures, inState, next)
+	}
+
+	fmt.Printf("\n%d/%d nodes reachable, max height %d\n", view.Reachable(), len(view.Nodes), view.MaxHeight)
+	for _, nv := range view.Nodes {
+		if nv.Status != nil && nv.Status.Approval.Pending() {
+			printApproval(nv.Node.ID, nv.Status.Approval)
+		}
+		if nv.Status != nil && nv.Status.MonitorOnly {
+			fmt.Printf("%s is monitor-only: it alerts but does not fail over\n", nv.Node.ID)
+		}
+	}
+	if view.SplitBrain() {
+		fmt.Printf("WARNING: split brain, active on %v\n", view.Active)
+		os.Exit(1)
+	}
+}
+
+func runClusterPauseCommand(cmd *cobra.Command, args []string) {
+	c := clusterClientOrExit(loadConfigOrExit())
+	ctx, cancel := context.WithTimeout(context.Background(), clusterOptions.timeout)
+	defer cancel()
+
+	if err := c.Pause(ctx); err != nil {
+		log.Fatalf("Failed to pause: %v", err)
+	}
+	fmt.Printf("Automatic failover paused on %d nodes\n", len(c.Nodes()))
+}
+
+func runClusterResumeCommand(cmd *cobra.Command, args []string) {
+	c := clusterClientOrExit(loadConfigOrExit())
+	ctx, cancel := context.WithTimeout(context.Background(), clusterOptions.timeout)
+	defer cancel()
+
+	if err := c.Resume(ctx); err != nil {
+		log.Fatalf("Failed to resume: %v", err)
+	}
+	fmt.Printf("Automatic failover resumed on %d nodes\n", len(c.Nodes()))
+}
+
+func runClusterHandoffCommand(cmd *cobra.Command, args []string) {
+	c := clusterClientOrExit(loadConfigOrExit())
+	ctx, cancel := context.WithTimeout(context.Background(), clusterOptions.timeout)
+	defer cancel()
+
+	from, err := c.Handoff(ctx)
+	if err != nil {
+		log.Fatalf("Failed to hand off: %v", err)
+	}
+	fmt.Printf("%s handed validator duties to its standby\n", from.ID)
+}
+
+func runClusterRetireSecretCommand(cmd *cobra.Command, args []string) {
+	c := clusterClientOrExit(loadConfigOrExit())
+	ctx, cancel := context.WithTimeout(context.Background(), clusterOptions.timeout)
+	defer cancel()
+
+	view, err := c.RetireSecret(ctx)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NODE\tSECRET\tPREVIOUS\tPREVIOUS LAST USED")
+	for _, nv := range view.Nodes {
+		if nv.Status == nil || nv.Status.Secret == nil {
+			fmt.Fprintf(w, "%s\t-\t-\t-\n", nv.Node.ID)
+			continue
+		}
+		s := nv.Status.Secret
+		previous, used := "-", "-"
+		if s.Previous != "" {
+			previous = s.Previous
+		}
+		if s.PreviousUsed != nil {
+			used = s.PreviousUsed.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", nv.Node.ID, s.Fingerprint, previous, used)
+	}
+	w.Flush()
+
+	if err != nil {
+		log.Fatalf("Failed to retire the previous secret: %v", err)
+	}
+	fmt.Println("\nPrevious cluster secret retired; remove previous_secret from every config file")
+}
+
+func runClusterApproveCommand(cmd *cobra.Command, args []string) {
+	c := clusterClientOrExit(loadConfigOrExit())
+	ctx, cancel := context.WithTimeout(context.Background(), clusterOptions.timeout)
+	defer cancel()
+
+	node, err := c.ApproveFailover(ctx, optionalArg(args))
+	if err != nil {
+		log.Fatalf("Failed to approve failover: %v", err)
+	}
+	fmt.Printf("Failover approved: %s handed validator duties to its standby\n", node.ID)
+}
+
+func runClusterRejectCommand(cmd *cobra.Command, args []string) {
+	c := clusterClientOrExit(loadConfigOrExit())
+	ctx, cancel := context.WithTimeout(context.Background(), clusterOptions.timeout)
+	defer cancel()
+
+	node, err := c.RejectFailover(ctx, optionalArg(args))
+	if err != nil {
+		log.Fatalf("Failed to reject failover: %v", err)
+	}
+	fmt.Printf("Failover rejected: %s stays active until it recovers\n", node.ID)
+}
+
+// progressColumns formats a failover countdown: failures against the
+// failover threshold, time in the current state and the next health check
+func progressColumns(p *client.Progress) (failures, inState, next string) {
+	if p == nil {
+		return "-", "-", "-"
+	}
+	failures = fmt.Sprintf("%d/%d", p.FailureCount, p.FailoverAfter)
+	inState = (time.Duration(p.InStateSeconds) * time.Second).String()
+	next = "-"
+	if !p.NextCheck.IsZero() {
+		next = time.Until(p.NextCheck).Round(time.Second).String()
+	}
+	return failures, inState, next
+}
+
+// lastTransition formats the most recent change of role as its type and
+// age, e.g. "failover 3d ago", and how many there were
+func lastTransition(t *client.Transitions) string {
+	if t == nil {
+		return "-"
+	}
+	kind, at := "", time.Time{}
+	for k, when := range t.Last {
+		if when.After(at) {
+			kind, at = k, when
+		}
+	}
+	if kind == "" {
+		return "none"
+	}
+	return fmt.Sprintf("%s %s ago (%d total)", kind, age(time.Since(at)), t.Epoch)
+}
+
+// age rounds d to its largest unit: days, hours, minutes or seconds
+func age(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", int(d/time.Hour))
+	case d >= time.Minute:
+		return fmt.Sprintf("%dm", int(d/time.Minute))
+	}
+	return fmt.Sprintf("%ds", int(d/time.Second))
+}
+
+// printApproval describes a failover awaiting approval
+func printApproval(nodeID string, req *client.ApprovalRequest) {
+	fmt.Printf("\nFailover %s of %s awaits approval (%s, since %s)\n",
+		req.ID, nodeID, req.Reason, req.Requested.Format(time.RFC3339))
+	for _, check := range req.Checks {
+		fmt.Printf("  ok:      %s\n", check)
+	}
+	for _, problem := range req.Problems {
+		fmt.Printf("  problem: %s\n", problem)
+	}
+	if req.AutoApproveAt != nil {
+		fmt.Printf("  auto-approves at %s\n", req.AutoApproveAt.Format(time.RFC3339))
+	}
+	fmt.Printf("  approve with 'syncguard cluster approve %s', or reject\n", req.ID)
+}
+
+// optionalArg returns the first argument, or "" when there is none
+func optionalArg(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	return args[0]
+}
+
+func runClusterConfirmActiveCommand(cmd *cobra.Command, args []string) {
+	c := clusterClientOrExit(loadConfigOrExit())
+	ctx, cancel := context.WithTimeout(context.Background(), clusterOptions.timeout)
+	defer cancel()
+
+	n, err := c.ConfirmActive(ctx)
+	if err != nil {
+		log.Fatalf("Failed to confirm: %v", err)
+	}
+	fmt.Printf("%s confirmed and now active\n", n.ID)
+}
+
+func runFailoverCommand(cmd *cobra.Command, args []string) {
+	c := clusterClientOrExit(loadConfigOrExit())
+	view := clusterViewOrExit(c)
+	if len(view.Active) == 0 {
+		log.Fatalf("Failed to fail over: %v", client.ErrNoActiveNode)
+	}
+	confirmOrExit(fmt.Sprintf("Hand validator duties from %s to its standby?", strings.Join(view.Active, ", ")))
+
+	ctx, cancel := context.WithTimeout(context.Background(), clusterOptions.timeout)
+	defer cancel()
+	from, err := c.Handoff(ctx)
+	if err != nil {
+		log.Fatalf("Failed to fail over: %v", err)
+	}
+	fmt.Printf("%s handed validator duties to its standby\n", from.ID)
+}
+
+func runFailbackCommand(cmd *cobra.Command, args []string) {
+	c := clusterClientOrExit(loadConfigOrExit())
+	view := clusterViewOrExit(c)
+	question := "Return validator duties to the primary?"
+	for _, nv := range view.Nodes {
+		if nv.Status != nil && nv.Status.Primary {
+			question = fmt.Sprintf("Return validator duties to the primary %s?", nv.Node.ID)
+		}
+	}
+	confirmOrExit(question)
+
+	ctx, cancel := context.WithTimeout(context.Background(), clusterOptions.timeout)
+	defer cancel()
+	to, err := c.Failback(ctx)
+	if err != nil {
+		log.Fatalf("Failed to fail back: %v", err)
+	}
+	fmt.Printf("%s reclaimed validator duties\n", to.ID)
+}
+
+// clusterViewOrExit fetches the cluster status shown before a handover is
+// confirmed
+func clusterViewOrExit(c *client.ClusterClient) client.ClusterView {
+	ctx, cancel := context.WithTimeout(context.Background(), clusterOptions.timeout)
+	defer cancel()
+	view := c.Status(ctx)
+	if view.Reachable() == 0 {
+		log.Fatal("No node answered; is the daemon running with admin.listen set?")
+	}
+	return view
+}
+
+// confirmOrExit asks the operator to confirm a handover, unless --force is
+// set, and exits unless they answer yes
+func confirmOrExit(question string) {
+	if clusterOptions.force {
+		return
+	}
+	fmt.Printf("%s [y/N] ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return
+	}
+	fmt.Println("Aborted")
+	os.Exit(1)
+}
+
+// clusterNodes lists this node and every peer with an admin_url
+func clusterNodes(cfg *config.Config) []client.Node {
+	var nodes []client.Node
+	if base := server.AdminURL(cfg.Admin.Listen, cfg.Node.Instance); base != "" {
+		nodes = append(nodes, client.Node{ID: cfg.Node.ID, URL: base})
+	}
+	for _, p := range cfg.Peers {
+		if p.AdminURL != "" {
+			nodes = append(nodes, client.Node{ID: p.ID, URL: p.AdminURL})
+		}
+	}
+	return nodes
+}
+
+// clusterClientOrExit builds a client for the configured cluster
+func clusterClientOrExit(cfg *config.Config) *client.ClusterClient {
+	c, err := client.New(client.Config{
+		Nodes:   clusterNodes(cfg),
+		Token:   cfg.Admin.Token,
+		Timeout: 30 * time.Second,
+	})
+	if err != nil {
+		log.Fatalf("Failed to create cluster client (set admin.listen and peers[].admin_url): %v", err)
+	}
+	return c
+}
+
+// localNodeOrExit returns this node's admin API endpoint
+func localNodeOrExit(cfg *config.Config) client.Node {
+	base := server.AdminURL(cfg.Admin.Listen, cfg.Node.Instance)
+	if base == "" {
+		log.Fatal("admin.listen is not configured")
+	}
+	return client.Node{ID: cfg.Node.ID, URL: base}
+}
@@ -1,13 +1,12 @@
 package cmd
 
 import (
-	"os"
-	"os/signal"
-	"syscall"
-
 	"github.com/aldebaranode/syncguard/internal/config"
 	"github.com/aldebaranode/syncguard/internal/constants"
+	"github.com/aldebaranode/syncguard/internal/errtrack"
+	"github.com/aldebaranode/syncguard/internal/logger"
 	"github.com/aldebaranode/syncguard/internal/manager"
+	"github.com/aldebaranode/syncguard/internal/wrapper"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
@@ -22,15 +21,21 @@ failover between active and passive validators while preventing double-signing.`
 }
 
 var options struct {
-	configFile string
-	role       constants.NodeStatus
+	configFile    string
+	role          constants.NodeStatus
+	lenient       bool
+	confirmActive bool
 }
 
 func init() {
-	rootCmd.Flags().StringVarP(&options.configFile, "config", "c", "config.yaml",
+	rootCmd.PersistentFlags().StringVarP(&options.configFile, "config", "c", "config.yaml",
 		"Configuration file path")
 	rootCmd.Flags().VarP(&options.role, "role", "r",
 		"Override node role (active/passive)")
+	rootCmd.PersistentFlags().BoolVar(&options.lenient, "lenient", false,
+		"Warn instead of failing on unknown config keys")
+	rootCmd.Flags().BoolVar(&options.confirmActive, "confirm-active", false,
+		"Sign as active on a first boot or after a reboot without waiting for confirmation")
 }
 
 // Execute runs the root command
@@ -40,11 +45,50 @@ func Execute() {
 	}
 }
 
-func runRootCommand(cmd *cobra.Command, args []string) {
-	cfg, err := config.Load(options.configFile)
+// loadConfigOrExit loads the config selected by the persistent flags
+func loadConfigOrExit() *config.Config {
+	cfg, err := config.LoadWithOptions(options.configFile, config.LoadOptions{Lenient: options.lenient})
 	if err != nil {
 		log.Fatalf("Error loading config: %v", err)
 	}
+	return cfg
+}
+
+// startErrorTracking installs the configured error tracker, if any; the
+// returned function sends what is still queued
+func startErrorTracking(cfg *config.Config) func() {
+	tracker, err := errtrack.New(cfg)
+	if err != nil {
+		log.Fatalf("Failed to configure error tracking: %v", err)
+	}
+	if tracker == nil {
+		return func() {}
+	}
+	errtrack.Install(tracker)
+	return tracker.Close
+}
+
+// startLogOutputs adds the outputs under logging.outputs; the returned
+// function sends what is still queued
+func startLogOutputs(cfg *config.Config) func() {
+	stop, err := logger.StartOutputs(cfg)
+	if err != nil {
+		log.Fatalf("Failed to configure log outputs: %v", err)
+	}
+	return stop
+}
+
+func runRootCommand(cmd *cobra.Command, args []string) {
+	cfg := loadConfigOrExit()
+	if cfg.Node.Role == constants.NodeStatusWitness {
+		runWitness(cfg)
+		return
+	}
+	stopOutputs := startLogOutputs(cfg)
+	defer stopOutputs()
+	stopTracking := startErrorTracking(cfg)
+	defer stopTracking()
+	defer errtrack.Recover()
 
 	// Override role if specified via CLI flag
 	if options.role != "" {
@@ -55,7 +99,13 @@ func runRootCommand(cmd *cobra.Command, args []string) {
 	}
 
 	// Initialize failover manager
-	failoverManager := manager.NewFailoverManager(cfg)
+	failoverManager, err := manager.NewFailoverManager(cfg)
+	if err != nil {
+		log.Fatalf("Failed to create failover manager: %v", err)
+	}
+	if options.confirmActive {
+		failoverManager.ConfirmFirstBoot()
+	}
 
 	if err := failoverManager.Start(); err != nil {
 		log.Fatalf("Failed to start failover manager: %v", err)
@@ -68,13 +118,12 @@ func runRootCommand(cmd *cobra.Command, args []string) {
 }
 
 func waitForShutdown(mgr *manager.FailoverManager) {
-	signalChan := make(chan os.Signal, 1)
-	signal.Notify(signalChan, syscall.SIGINT, syscall.SIGTERM)
-
-	sig := <-signalChan
-	log.Infof("Received signal %s. Shutting down...", sig)
+	reason, done := wrapper.WaitForStop(defaultServiceName)
+	log.Infof("Received %s. Shutting down...", reason)
 
+	mgr.HandOverOnShutdown()
 	mgr.Stop()
 
 	log.Info("SyncGuard stopped")
+	done()
 }
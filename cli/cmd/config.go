@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect and maintain configuration files",
+}
+
+var configMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Rewrite a legacy Server/Communication config into the current schema",
+	Long: `Detects the legacy schema (server/communication sections) and rewrites it
+into the current node/cometbft layout. The result is printed as YAML, or
+written to --output in the format given by its extension. Existing files
+are never overwritten.`,
+	Run: runConfigMigrateCommand,
+}
+
+var configMigrateOptions struct {
+	output string
+}
+
+func init() {
+	configMigrateCmd.Flags().StringVarP(&configMigrateOptions.output, "output", "o", "",
+		"Write migrated config to this file instead of stdout")
+
+	configCmd.AddCommand(configMigrateCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+func runConfigMigrateCommand(cmd *cobra.Command, args []string) {
+	migrated, changes, err := config.MigrateFile(options.configFile)
+	if err != nil {
+		log.Fatalf("Migration failed: %v", err)
+	}
+
+	for _, change := range changes {
+		fmt.Fprintf(os.Stderr, "note: %s\n", change)
+	}
+
+	if configMigrateOptions.output == "" {
+		out, err := yaml.Marshal(migrated)
+		if err != nil {
+			log.Fatalf("Failed to encode migrated config: %v", err)
+		}
+		os.Stdout.Write(out)
+		return
+	}
+
+	v := viper.New()
+	if err := v.MergeConfigMap(migrated); err != nil {
+		log.Fatalf("Failed to load migrated config: %v", err)
+	}
+	if err := v.SafeWriteConfigAs(configMigrateOptions.output); err != nil {
+		log.Fatalf("Failed to write %s: %v", configMigrateOptions.output, err)
+	}
+	fmt.Fprintf(os.Stderr, "Migrated config written to %s\n", configMigrateOptions.output)
+}
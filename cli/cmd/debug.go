@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/diag"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var debugCmd = &cobra.Command{
+	Use:   "debug",
+	Short: "Collect diagnostics for bug reports",
+}
+
+var debugBundleCmd = &cobra.Command{
+	Use:   "bundle",
+	Short: "Write a tarball with profiles, logs, redacted config, history and status",
+	Long: `Collects everything useful for a bug report into a .tar.gz: the config with
+secrets redacted, the tail of the log file, the event history, /health and
+/admin/status snapshots, and pprof profiles from the running daemon's admin
+API (requires admin.listen and self_monitor.pprof). Anything that cannot be
+collected is listed in manifest.json instead of failing the bundle.`,
+	Run: runDebugBundleCommand,
+}
+
+var debugBundleOptions struct {
+	output     string
+	logLines   int
+	cpuSeconds int
+}
+
+func init() {
+	debugBundleCmd.Flags().StringVarP(&debugBundleOptions.output, "output", "o", "",
+		"Bundle path (default syncguard-debug-<node>-<time>.tar.gz)")
+	debugBundleCmd.Flags().IntVar(&debugBundleOptions.logLines, "log-lines", 5000,
+		"Number of trailing log lines to include (0 for the whole file)")
+	debugBundleCmd.Flags().IntVar(&debugBundleOptions.cpuSeconds, "cpu-seconds", 0,
+		"Also capture a CPU profile of this many seconds")
+
+	debugCmd.AddCommand(debugBundleCmd)
+	rootCmd.AddCommand(debugCmd)
+}
+
+func runDebugBundleCommand(cmd *cobra.Command, args []string) {
+	cfg := loadConfigOrExit()
+
+	output := debugBundleOptions.output
+	if output == "" {
+		output = fmt.Sprintf("syncguard-debug-%s-%s.tar.gz", cfg.Node.ID, time.Now().UTC().Format("20060102-150405"))
+	}
+
+	f, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		log.Fatalf("Failed to create %s: %v", output, err)
+	}
+
+	err = diag.WriteBundle(cfg, diag.BundleOptions{
+		ConfigPath: options.configFile,
+		LogLines:   debugBundleOptions.logLines,
+		CPUSeconds: debugBundleOptions.cpuSeconds,
+	}, f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(output)
+		log.Fatalf("Failed to write debug bundle: %v", err)
+	}
+
+	fmt.Printf("Debug bundle written to %s\n", output)
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/diag"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check this host is ready to run syncguard",
+	Long: `Runs the checks that otherwise only fail at startup or during a failover:
+config validity, existence and permissions of the key and state files,
+CometBFT RPC reachability and version, peer reachability and authentication,
+clock sync, free disk space and the lock backend. Each failure is printed
+with the fix to apply. Exits non-zero if any check fails.`,
+	Run: runDoctorCommand,
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
+
+func runDoctorCommand(cmd *cobra.Command, args []string) {
+	findings := diag.Doctor(options.configFile, config.LoadOptions{Lenient: options.lenient})
+
+	for _, f := range findings {
+		fmt.Printf("%-5s %-20s %s\n", strings.ToUpper(f.Status), f.Check, f.Detail)
+		if f.Fix != "" {
+			fmt.Printf("%-26s fix: %s\n", "", f.Fix)
+		}
+	}
+	if diag.Failed(findings) {
+		log.Exit(1)
+	}
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/pkg/client"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var drainCmd = &cobra.Command{
+	Use:   "drain [node-id]",
+	Short: "Take a node out of service for patching or migration",
+	Long: `Asks a node's admin API, this node's by default, to drain: if it is active it
+hands its key and state to the standby, and from then on it refuses the
+validator role, from peers, failback and confirm-active alike. The drain is
+kept across restarts and shown as "draining" in /health until
+'syncguard undrain'. If the handoff fails the node is not drained.`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runDrainCommand,
+}
+
+var undrainCmd = &cobra.Command{
+	Use:   "undrain [node-id]",
+	Short: "Return a drained node to service",
+	Long: `Lets a drained node take the validator role again. It does not take it
+back by itself; a primary fails back once it is stable, as usual.`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runUndrainCommand,
+}
+
+var drainOptions struct {
+	timeout time.Duration
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{drainCmd, undrainCmd} {
+		cmd.Flags().DurationVar(&drainOptions.timeout, "timeout", time.Minute,
+			"Overall time limit for the command")
+		rootCmd.AddCommand(cmd)
+	}
+}
+
+func runDrainCommand(cmd *cobra.Command, args []string) {
+	c, node := drainTargetOrExit(loadConfigOrExit(), args)
+	ctx, cancel := context.WithTimeout(context.Background(), drainOptions.timeout)
+	defer cancel()
+
+	if err := c.Drain(ctx, node); err != nil {
+		log.Fatalf("Failed to drain %s: %v", node.ID, err)
+	}
+	fmt.Printf("%s drained; run 'syncguard undrain' to return it to service\n", node.ID)
+}
+
+func runUndrainCommand(cmd *cobra.Command, args []string) {
+	c, node := drainTargetOrExit(loadConfigOrExit(), args)
+	ctx, cancel := context.WithTimeout(context.Background(), drainOptions.timeout)
+	defer cancel()
+
+	if err := c.Undrain(ctx, node); err != nil {
+		log.Fatalf("Failed to undrain %s: %v", node.ID, err)
+	}
+	fmt.Printf("%s undrained\n", node.ID)
+}
+
+// drainTargetOrExit returns the node named in args, this node by default
+func drainTargetOrExit(cfg *config.Config, args []string) (*client.ClusterClient, client.Node) {
+	if len(args) == 0 {
+		return drillClientOrExit(cfg)
+	}
+	c := clusterClientOrExit(cfg)
+	node, err := c.Node(args[0])
+	if err != nil {
+		log.Fatalf("Unknown node %s (peers need an admin_url): %v", args[0], err)
+	}
+	return c, node
+}
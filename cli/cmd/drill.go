@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/drill"
+	"github.com/aldebaranode/syncguard/internal/history"
+	"github.com/aldebaranode/syncguard/pkg/client"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var drillCmd = &cobra.Command{
+	Use:   "drill",
+	Short: "Rehearse a failover to the standby with automatic failback",
+	Long: `Asks the running daemon (on the active node) to perform a real failover to
+the standby and fail back automatically after --duration, or earlier with
+'syncguard drill revert'. Handoff and failback timings are recorded in the
+history file. Requires admin.listen.`,
+	Run: runDrillCommand,
+}
+
+var drillRevertCmd = &cobra.Command{
+	Use:   "revert",
+	Short: "Fail back a running drill now",
+	Run:   runDrillRevertCommand,
+}
+
+var drillStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the current or last drill",
+	Run:   runDrillStatusCommand,
+}
+
+var drillReportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Summarize past drills and their timings from the history file",
+	Run:   runDrillReportCommand,
+}
+
+var drillOptions struct {
+	duration time.Duration
+	detach   bool
+	since    time.Duration
+}
+
+func init() {
+	drillCmd.Flags().DurationVar(&drillOptions.duration, "duration", 10*time.Minute,
+		"How long the standby keeps validator duties before failback")
+	drillCmd.Flags().BoolVar(&drillOptions.detach, "detach", false,
+		"Return after starting instead of following the drill")
+
+	drillReportCmd.Flags().DurationVar(&drillOptions.since, "since", 90*24*time.Hour,
+		"Only include drills newer than this")
+
+	drillCmd.AddCommand(drillRevertCmd)
+	drillCmd.AddCommand(drillReportCmd)
+	drillCmd.AddCommand(drillStatusCmd)
+	rootCmd.AddCommand(drillCmd)
+}
+
+func runDrillCommand(cmd *cobra.Command, args []string) {
+	cfg := loadConfigOrExit()
+	c, node := drillClientOrExit(cfg)
+	ctx := context.Background()
+
+	status, err := c.StartDrill(ctx, node, drillOptions.duration)
+	if err != nil {
+		log.Fatalf("Failed to start drill: %v", err)
+	}
+	fmt.Printf("Drill %s started, failback at %s\n", status.ID, status.RevertAt.Local().Format(time.RFC3339))
+	if drillOptions.detach {
+		return
+	}
+
+	phase := status.Phase
+	for !status.Done() {
+		time.Sleep(2 * time.Second)
+		next, err := c.DrillStatus(ctx, node)
+		if err != nil {
+			log.Warnf("Failed to poll drill status: %v", err)
+			continue
+		}
+		status = next
+		if status.Phase != phase {
+			phase = status.Phase
+			fmt.Printf("%s  %s\n", time.Now().Format("15:04:05"), phase)
+		}
+	}
+	printDrill(status)
+}
+
+func runDrillRevertCommand(cmd *cobra.Command, args []string) {
+	c, node := drillClientOrExit(loadConfigOrExit())
+	status, err := c.RevertDrill(context.Background(), node)
+	if err != nil {
+		log.Fatalf("Failed to revert drill: %v", err)
+	}
+	fmt.Printf("Drill %s failback requested\n", status.ID)
+}
+
+func runDrillStatusCommand(cmd *cobra.Command, args []string) {
+	c, node := drillClientOrExit(loadConfigOrExit())
+	status, err := c.DrillStatus(context.Background(), node)
+	if err != nil {
+		log.Fatalf("Failed to get drill status: %v", err)
+	}
+	printDrill(status)
+}
+
+func runDrillReportCommand(cmd *cobra.Command, args []string) {
+	cfg := loadConfigOrExit()
+	store := history.NewStore(cfg.History.Path, 0)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tTRIGGER\tRESULT\tDETECTION\tHANDOFF\tFAILBACK\tEST. RTO\tNOTE")
+
+	var completed int
+	var handoffTotal, rtoTotal float64
+	err := store.Read(time.Now().Add(-drillOptions.since), func(e history.Entry) error {
+		if e.Type != "drill" {
+			return nil
+		}
+		phase := e.Fields["phase"]
+		if phase != string(drill.PhaseCompleted) && phase != string(drill.PhaseFailed) && phase != string(drill.PhaseSkipped) {
+			return nil
+		}
+
+		note := e.Fields["error"]
+		if phase == string(drill.PhaseSkipped) {
+			note = strings.TrimPrefix(e.Message, "Scheduled drill skipped: ")
+		}
+		if phase == string(drill.PhaseCompleted) {
+			completed++
+			handoffTotal += parseSeconds(e.Fields["handoff_seconds"])
+			rtoTotal += parseSeconds(e.Fields["estimated_rto_seconds"])
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			e.Time.Local().Format("2006-01-02 15:04"), e.Fields["trigger"], phase,
+			formatSeconds(e.Fields["detection_seconds"]), formatSeconds(e.Fields["handoff_seconds"]),
+			formatSeconds(e.Fields["failback_seconds"]), formatSeconds(e.Fields["estimated_rto_seconds"]), note)
+		return nil
+	})
+	if err != nil {
+		log.Fatalf("Failed to read history: %v", err)
+	}
+	w.Flush()
+
+	if completed > 0 {
+		fmt.Printf("\n%d completed drills: average handoff %.1fs, average estimated RTO %.1fs\n",
+			completed, handoffTotal/float64(completed), rtoTotal/float64(completed))
+	}
+}
+
+// parseSeconds reads a seconds field from a history entry
+func parseSeconds(value string) float64 {
+	seconds, _ := strconv.ParseFloat(value, 64)
+	return seconds
+}
+
+// formatSeconds renders a seconds field, or - when it is missing
+func formatSeconds(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return fmt.Sprintf("%.1fs", parseSeconds(value))
+}
+
+// printDrill shows a drill's phase and timings
+func printDrill(status client.DrillStatus) {
+	fmt.Printf("Drill:    %s\n", status.ID)
+	fmt.Printf("Phase:    %s\n", status.Phase)
+	fmt.Printf("Trigger:  %s\n", status.Trigger)
+	fmt.Printf("Release:  %.1fs\n", status.ReleaseSeconds)
+	fmt.Printf("Handoff:  %.1fs\n", status.HandoffSeconds)
+	fmt.Printf("Standby:  %.1fs\n", status.StandbySeconds)
+	fmt.Printf("Failback: %.1fs\n", status.FailbackSeconds)
+	fmt.Printf("Est. RTO: %.1fs (%.0fs detection + handoff)\n", status.EstimatedRTOSeconds, status.DetectionSeconds)
+	if status.Error != "" {
+		fmt.Printf("Error:    %s\n", status.Error)
+	}
+}
+
+// drillClientOrExit returns a client for the local admin API, where drills run
+func drillClientOrExit(cfg *config.Config) (*client.ClusterClient, client.Node) {
+	node := localNodeOrExit(cfg)
+	c, err := client.New(client.Config{
+		Nodes:   []client.Node{node},
+		Token:   cfg.Admin.Token,
+		Timeout: 30 * time.Second,
+	})
+	if err != nil {
+		log.Fatalf("Failed to create admin client: %v", err)
+	}
+	return c, node
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/aldebaranode/syncguard/internal/state"
+	"github.com/spf13/cobra"
+)
+
+var gateCmd = &cobra.Command{
+	Use:   "gate",
+	Short: "Check the signing watermark before the node signs",
+	Long: `Thin shim for signer wrappers. Reads the watermark file maintained by
+SyncGuard and exits 0 only if this node is active and the requested
+height is at or above the cluster watermark. Any other outcome, including
+a missing or unreadable file, exits non-zero so the caller fails closed.`,
+	Run: runGateCommand,
+}
+
+var gateOptions struct {
+	file   string
+	height int64
+}
+
+func init() {
+	gateCmd.Flags().StringVarP(&gateOptions.file, "file", "f", "",
+		"Watermark file path (gatekeeper.path)")
+	gateCmd.Flags().Int64Var(&gateOptions.height, "height", 0,
+		"Height the node is about to sign")
+	gateCmd.MarkFlagRequired("file")
+	gateCmd.MarkFlagRequired("height")
+
+	rootCmd.AddCommand(gateCmd)
+}
+
+func runGateCommand(cmd *cobra.Command, args []string) {
+	wm, err := state.ReadWatermark(gateOptions.file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "deny: %v\n", err)
+		os.Exit(1)
+	}
+
+	if !wm.Allows(gateOptions.height) {
+		fmt.Fprintf(os.Stderr, "deny: active=%v min_height=%d requested=%d\n",
+			wm.Active, wm.MinHeight, gateOptions.height)
+		os.Exit(1)
+	}
+
+	fmt.Println("allow")
+}
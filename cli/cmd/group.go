@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/aldebaranode/syncguard/internal/group"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var groupCmd = &cobra.Command{
+	Use:   "group",
+	Short: "Inspect linked consumer-chain instances",
+}
+
+var groupStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the role and health of every group member, in cascade order",
+	Run:   runGroupStatusCommand,
+}
+
+func init() {
+	groupCmd.AddCommand(groupStatusCmd)
+	rootCmd.AddCommand(groupCmd)
+}
+
+func runGroupStatusCommand(cmd *cobra.Command, args []string) {
+	cfg := loadConfigOrExit()
+
+	g, err := group.New(cfg)
+	if err != nil {
+		log.Fatalf("Failed to configure group: %v", err)
+	}
+	if g == nil {
+		log.Fatal("No group members configured")
+	}
+
+	fmt.Printf("Group %q, cascade %v, on error %s\n\n", g.Name(), cfg.Group.Cascade, cfg.Group.OnError)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ORDER\tMEMBER\tACTIVE\tHEALTHY\tPAUSED\tHEIGHT\tERROR")
+	for i, m := range g.Status(context.Background()) {
+		if m.Status == nil {
+			fmt.Fprintf(w, "%d\t%s\t-\t-\t-\t-\t%s\n", i+1, m.ID, m.Error)
+			continue
+		}
+		s := m.Status
+		fmt.Fprintf(w, "%d\t%s\t%v\t%v\t%v\t%d\t\n", i+1, m.ID, s.Active, s.Healthy, s.Paused, s.Height)
+	}
+	w.Flush()
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/crypto"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var identityCmd = &cobra.Command{
+	Use:   "identity",
+	Short: "Manage the node identity key and pinned peer keys",
+}
+
+var identityShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print this node's identity public key (generated on first use)",
+	Run:   runIdentityShowCommand,
+}
+
+var identityRevokeCmd = &cobra.Command{
+	Use:   "revoke <node-id>",
+	Short: "Revoke a peer's identity key so its requests are rejected",
+	Args:  cobra.ExactArgs(1),
+	Run:   runIdentityRevokeCommand,
+}
+
+var identityUnrevokeCmd = &cobra.Command{
+	Use:   "unrevoke <node-id>",
+	Short: "Clear a revocation so the peer can enroll a new key",
+	Args:  cobra.ExactArgs(1),
+	Run:   runIdentityUnrevokeCommand,
+}
+
+func init() {
+	identityCmd.AddCommand(identityShowCmd, identityRevokeCmd, identityUnrevokeCmd)
+	rootCmd.AddCommand(identityCmd)
+}
+
+func loadKeyringOrExit(cfg *config.Config) *crypto.Keyring {
+	keyring, err := crypto.NewKeyring(cfg.Identity.KeyringPath, cfg.PinnedPeerKeys())
+	if err != nil {
+		log.Fatalf("Failed to load keyring: %v", err)
+	}
+	return keyring
+}
+
+func runIdentityShowCommand(cmd *cobra.Command, args []string) {
+	cfg := loadConfigOrExit()
+
+	identity, err := crypto.LoadOrCreateIdentity(cfg.Identity.KeyPath, cfg.Node.ID)
+	if err != nil {
+		log.Fatalf("Failed to load identity: %v", err)
+	}
+
+	fmt.Printf("node_id:    %s\n", identity.NodeID)
+	fmt.Printf("public_key: %s\n", identity.PublicKey())
+}
+
+func runIdentityRevokeCommand(cmd *cobra.Command, args []string) {
+	cfg := loadConfigOrExit()
+
+	if err := loadKeyringOrExit(cfg).Revoke(args[0]); err != nil {
+		log.Fatalf("Failed to revoke %s: %v", args[0], err)
+	}
+	fmt.Printf("Revoked identity of %s\n", args[0])
+}
+
+func runIdentityUnrevokeCommand(cmd *cobra.Command, args []string) {
+	cfg := loadConfigOrExit()
+
+	if err := loadKeyringOrExit(cfg).Unrevoke(args[0]); err != nil {
+		log.Fatalf("Failed to unrevoke %s: %v", args[0], err)
+	}
+	fmt.Printf("Cleared revocation of %s\n", args[0])
+}
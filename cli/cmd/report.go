@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/history"
+	"github.com/aldebaranode/syncguard/internal/report"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Report validator uptime and signing performance over a period",
+	Long: `Aggregates the history file into an uptime and signing report per chain:
+the time spent in downtime intervals, the blocks the signing activity feed
+saw signed and missed (chain.signing.enabled), failovers and not-signing
+alerts. Use it for delegator communications and internal SLO reviews.
+
+The report covers what this node recorded; run it on each node of a cluster
+for the full picture.`,
+	Run: runReportCommand,
+}
+
+var reportOptions struct {
+	period string
+	until  string
+	format string
+	output string
+}
+
+func init() {
+	reportCmd.Flags().StringVar(&reportOptions.period, "period", "30d",
+		"Length of the period: days like 30d or a duration like 72h")
+	reportCmd.Flags().StringVar(&reportOptions.until, "until", "",
+		"End of the period: RFC 3339 time or YYYY-MM-DD (default now)")
+	reportCmd.Flags().StringVar(&reportOptions.format, "format", "markdown",
+		"Output format: markdown or json")
+	reportCmd.Flags().StringVarP(&reportOptions.output, "output", "o", "",
+		"Write to a file instead of stdout")
+
+	rootCmd.AddCommand(reportCmd)
+}
+
+func runReportCommand(cmd *cobra.Command, args []string) {
+	cfg := loadConfigOrExit()
+
+	period, err := report.ParsePeriod(reportOptions.period)
+	if err != nil {
+		log.Fatal(err)
+	}
+	until := time.Now().UTC()
+	if reportOptions.until != "" {
+		if until, err = history.ParseSince(reportOptions.until, until); err != nil {
+			log.Fatalf("Invalid --until %q", reportOptions.until)
+		}
+	}
+
+	r, err := report.Build(history.NewStore(cfg.History.Path, 0), report.Options{
+		Since: until.Add(-period),
+		Until: until,
+	})
+	if err != nil {
+		log.Fatalf("Failed to build report: %v", err)
+	}
+
+	var out io.Writer = os.Stdout
+	if reportOptions.output != "" {
+		f, err := os.OpenFile(reportOptions.output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+		if err != nil {
+			log.Fatalf("Failed to create %s: %v", reportOptions.output, err)
+		}
+		defer f.Close()
+		out = f
+	}
+	if err := report.Write(out, r, reportOptions.format); err != nil {
+		log.Fatalf("Failed to write report: %v", err)
+	}
+	if reportOptions.output != "" {
+		fmt.Fprintf(os.Stderr, "Wrote report for %d chain(s) to %s\n", len(r.Chains), reportOptions.output)
+	}
+}
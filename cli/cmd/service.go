@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/wrapper"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// defaultServiceName is the name syncguard registers under
+const defaultServiceName = "syncguard"
+
+var serviceCmd = &cobra.Command{
+	Use:   "service",
+	Short: "Run syncguard under the host's service manager",
+	Long: `Registers syncguard as a systemd unit (Linux), a launchd job (macOS) or a
+Windows service. The daemon is restarted when it exits with an error and
+stays stopped after a clean shutdown. Installing on Linux, as a macOS
+LaunchDaemon or on Windows needs root or Administrator rights.`,
+}
+
+var serviceInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Register syncguard with the current --config and enable it at boot",
+	Run:   runServiceInstallCommand,
+}
+
+var serviceUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Stop syncguard and remove the service",
+	Run: func(cmd *cobra.Command, args []string) {
+		runServiceAction("Uninstalled", wrapper.Service.Uninstall)
+	},
+}
+
+var serviceStartCmd = &cobra.Command{
+	Use:   "start",
+	Short: "Start the installed service",
+	Run: func(cmd *cobra.Command, args []string) {
+		runServiceAction("Started", wrapper.Service.Start)
+	},
+}
+
+var serviceStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stop the installed service",
+	Run: func(cmd *cobra.Command, args []string) {
+		runServiceAction("Stopped", wrapper.Service.Stop)
+	},
+}
+
+var serviceStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether the service is installed and running",
+	Run:   runServiceStatusCommand,
+}
+
+var serviceOptions struct {
+	name        string
+	user        string
+	stopTimeout time.Duration
+}
+
+func init() {
+	serviceCmd.PersistentFlags().StringVar(&serviceOptions.name, "name", defaultServiceName,
+		"Service name")
+	serviceInstallCmd.Flags().StringVar(&serviceOptions.user, "user", "",
+		"Account to run the daemon as (systemd only)")
+	serviceInstallCmd.Flags().DurationVar(&serviceOptions.stopTimeout, "stop-timeout", time.Minute,
+		"Time allowed for a clean shutdown before the daemon is killed")
+	serviceCmd.AddCommand(serviceInstallCmd, serviceUninstallCmd, serviceStartCmd,
+		serviceStopCmd, serviceStatusCmd)
+	rootCmd.AddCommand(serviceCmd)
+}
+
+// serviceOrExit builds the service definition from the flags. The daemon
+// runs with the absolute path of this binary and of the config file.
+func serviceOrExit() wrapper.Service {
+	executable, err := os.Executable()
+	if err != nil {
+		log.Fatalf("Failed to find syncguard executable: %v", err)
+	}
+	configFile, err := filepath.Abs(options.configFile)
+	if err != nil {
+		log.Fatalf("Failed to resolve config path: %v", err)
+	}
+
+	args := []string{"--config", configFile}
+	if options.lenient {
+		args = append(args, "--lenient")
+	}
+	svc, err := wrapper.New(wrapper.Config{
+		Name:        serviceOptions.name,
+		DisplayName: "SyncGuard",
+		Description: "SyncGuard validator failover daemon",
+		Executable:  executable,
+		Args:        args,
+		WorkingDir:  filepath.Dir(configFile),
+		User:        serviceOptions.user,
+		StopTimeout: serviceOptions.stopTimeout,
+	})
+	if err != nil {
+		log.Fatalf("Failed to configure service: %v", err)
+	}
+	return svc
+}
+
+func runServiceInstallCommand(cmd *cobra.Command, args []string) {
+	// Refuse to register a daemon that would fail on its first start
+	loadConfigOrExit()
+
+	svc := serviceOrExit()
+	if err := svc.Install(); err != nil {
+		log.Fatalf("Failed to install service: %v", err)
+	}
+	fmt.Printf("Installed %s (%s)\n", serviceOptions.name, svc.Path())
+	fmt.Printf("Start it with: syncguard service start --name %s\n", serviceOptions.name)
+}
+
+func runServiceAction(done string, action func(wrapper.Service) error) {
+	if err := action(serviceOrExit()); err != nil {
+		log.Fatalf("Service %s: %v", serviceOptions.name, err)
+	}
+	fmt.Printf("%s %s\n", done, serviceOptions.name)
+}
+
+func runServiceStatusCommand(cmd *cobra.Command, args []string) {
+	svc := serviceOrExit()
+	status, err := svc.Status()
+	if err != nil {
+		log.Fatalf("Failed to read service status: %v", err)
+	}
+	fmt.Printf("%s: %s\n", serviceOptions.name, status)
+	if status != wrapper.StatusNotInstalled {
+		fmt.Printf("Definition: %s\n", svc.Path())
+	}
+	if status != wrapper.StatusRunning {
+		log.Exit(1)
+	}
+}
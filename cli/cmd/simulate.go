@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/aldebaranode/syncguard/internal/simulate"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var simulateCmd = &cobra.Command{
+	Use:   "simulate",
+	Short: "Replay a health timeline against the configured failover thresholds",
+	Long: `Replays a synthetic health and heartbeat timeline (YAML), or a past incident
+from 'syncguard history export' (.jsonl), through the failover decisions of
+this node with the thresholds of the config file, and reports when it would
+have failed over, failed back or raised a heartbeat alert. Nothing is
+probed or changed; edit the config and run it again to tune retries and
+grace periods.`,
+	Run: runSimulateCommand,
+}
+
+var simulateOptions struct {
+	scenario string
+	format   string
+}
+
+func init() {
+	simulateCmd.Flags().StringVar(&simulateOptions.scenario, "scenario", "",
+		"Scenario file: YAML samples, or a history export in JSON Lines (.jsonl)")
+	simulateCmd.Flags().StringVar(&simulateOptions.format, "format", "text",
+		"Output format: text or json")
+	simulateCmd.MarkFlagRequired("scenario")
+
+	rootCmd.AddCommand(simulateCmd)
+}
+
+func runSimulateCommand(cmd *cobra.Command, args []string) {
+	cfg := loadConfigOrExit()
+	scenario, err := simulate.Load(cfg, simulateOptions.scenario)
+	if err != nil {
+		log.Fatal(err)
+	}
+	report := simulate.Run(cfg, scenario)
+
+	switch simulateOptions.format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			log.Fatalf("Failed to write report: %v", err)
+		}
+		return
+	case "text":
+	default:
+		log.Fatalf("Unknown format %q (accepted: text, json)", simulateOptions.format)
+	}
+
+	fmt.Printf("%d checks, %d failed, at most %d in a row (failover.retry_attempts: %d)\n\n",
+		report.Checks, report.Failed, report.MaxConsecutive, cfg.Failover.RetryAttempts)
+	if len(report.Decisions) == 0 {
+		fmt.Println("No failover, failback or heartbeat alert would have happened")
+	} else {
+		printDecisions("SIMULATED", report.Decisions)
+	}
+	if len(report.Actual) > 0 {
+		fmt.Println()
+		printDecisions("ACTUAL", report.Actual)
+	}
+}
+
+// printDecisions lists decisions with their offset into the timeline
+func printDecisions(title string, decisions []simulate.Decision) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "%s\tOFFSET\tTIME\tREASON\tFAILURES\n", title)
+	for _, d := range decisions {
+		failures := ""
+		if d.Failures > 0 {
+			failures = fmt.Sprintf("%d", d.Failures)
+		}
+		at := "-"
+		if !d.Time.IsZero() {
+			at = d.Time.Local().Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", d.Action, d.Offset, at, d.Reason, failures)
+	}
+	w.Flush()
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/aldebaranode/syncguard/internal/coldstandby"
+	"github.com/aldebaranode/syncguard/internal/health"
+	"github.com/aldebaranode/syncguard/internal/logger"
+	"github.com/aldebaranode/syncguard/internal/state"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var activateStandbyCmd = &cobra.Command{
+	Use:   "activate-standby",
+	Short: "Promote the cold standby that runs no SyncGuard",
+	Long: `Hands validator duties to the cold standby configured under cold_standby.
+The local node must not be signing: the command refuses while the CometBFT RPC
+reports the validator key loaded, unless --force is given because the node is
+known to be down. The local key is then swapped for the mock key so a restart
+cannot sign, the latest state and key are shipped once more where the files
+can still be read, and the standby is promoted: over SSH the shipped key and
+state are installed and activate_command runs there; with transport command,
+activate_command runs here.
+
+Stop SyncGuard on this host afterwards and fail back by hand.`,
+	Run: runActivateStandbyCommand,
+}
+
+var standbyOptions struct {
+	force bool
+}
+
+func init() {
+	activateStandbyCmd.Flags().BoolVar(&standbyOptions.force, "force", false,
+		"Promote even though the local node cannot be confirmed stopped")
+	rootCmd.AddCommand(activateStandbyCmd)
+}
+
+func runActivateStandbyCommand(cmd *cobra.Command, args []string) {
+	cfg := loadConfigOrExit()
+	if !cfg.ColdStandby.Enabled {
+		log.Fatal("cold_standby is not enabled")
+	}
+	target, err := coldstandby.NewTarget(cfg.ColdStandby)
+	if err != nil {
+		log.Fatalf("Invalid cold standby: %v", err)
+	}
+
+	keys := state.NewKeyManager(cfg.CometBFT.KeyPath, cfg.CometBFT.BackupPath, logger.New(cfg, "cold-standby"))
+	key, keyErr := keys.LoadRealKey()
+
+	// Two signers with the same key double sign
+	signing, err := health.NewChecker(cfg, cfg.CometBFT.RPCURL).SigningAddress()
+	switch {
+	case err == nil && keyErr == nil && signing == key.Address && !standbyOptions.force:
+		log.Fatalf("The local node is signing with validator key %s; stop it first, or pass --force", signing)
+	case err == nil && keyErr == nil && signing == key.Address:
+		log.Warnf("The local node reports validator key %s loaded; promoting anyway (--force)", signing)
+	case keyErr != nil && !standbyOptions.force:
+		log.Fatalf("Cannot read the local validator key to check the node is not signing (%v); pass --force if it is down", keyErr)
+	}
+
+	shipper := coldstandby.NewShipper(cfg, target, keys)
+	if keyErr == nil {
+		if err := keys.DeleteKey(); err != nil {
+			log.Fatalf("Failed to disable the local validator key: %v", err)
+		}
+		fmt.Println("Local validator key replaced by the mock key")
+		if err := shipper.Ship(); err != nil {
+			log.Warnf("Could not ship the latest state, the standby uses the last shipped copy: %v", err)
+		} else {
+			fmt.Printf("Shipped state at height %d\n", shipper.Status().Height)
+		}
+	}
+
+	if err := target.Promote(); err != nil {
+		log.Fatalf("Failed to promote the standby: %v", err)
+	}
+	fmt.Println("Cold standby promoted; stop SyncGuard on this host before restarting anything here")
+}
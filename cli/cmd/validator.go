@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/chain"
+	"github.com/aldebaranode/syncguard/internal/state"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var validatorCmd = &cobra.Command{
+	Use:   "validator",
+	Short: "Inspect the validator as seen by the chain",
+}
+
+var validatorInfoCmd = &cobra.Command{
+	Use:   "info",
+	Short: "Look up the validator key on disk on chain and verify it",
+	Long: `Reads cometbft.key_path (or the real key parked by a passive node) and queries
+the CometBFT RPC for voting power and, when chain.lcd_url is set, the staking
+module for operator address and jail status. With chain.signing.enabled it
+also reads the commits of recent blocks for our precommits. Exits non-zero
+on problems.`,
+	Run: runValidatorInfoCommand,
+}
+
+func init() {
+	validatorCmd.AddCommand(validatorInfoCmd)
+	rootCmd.AddCommand(validatorCmd)
+}
+
+func runValidatorInfoCommand(cmd *cobra.Command, args []string) {
+	cfg := loadConfigOrExit()
+
+	key, err := state.NewKeyManager(cfg.CometBFT.KeyPath, cfg.CometBFT.BackupPath, nil).LoadRealKey()
+	if err != nil {
+		log.Fatalf("Failed to load validator key: %v", err)
+	}
+	info, err := chain.NewDiscoverer(cfg).Discover(key)
+	if err != nil {
+		log.Fatalf("Failed to query chain: %v", err)
+	}
+
+	fmt.Printf("Address:       %s\n", info.Address)
+	fmt.Printf("Public key:    %s (%s)\n", info.PubKey, info.PubKeyType)
+	fmt.Printf("Validator set: %v (height %d)\n", info.InValidatorSet, info.Height)
+	fmt.Printf("Voting power:  %d of %d\n", info.VotingPower, info.TotalVotingPower)
+	if cfg.Chain.LCDURL != "" {
+		fmt.Printf("Operator:      %s\n", info.OperatorAddress)
+		fmt.Printf("Moniker:       %s\n", info.Moniker)
+		fmt.Printf("Status:        %s\n", info.BondStatus)
+		fmt.Printf("Jailed:        %v\n", info.Jailed)
+		fmt.Printf("Tokens:        %s\n", info.Tokens)
+	}
+	if cfg.Chain.Signing.Enabled {
+		activity, err := chain.NewSigningFeed(cfg).Poll(info.Address)
+		if err != nil {
+			fmt.Printf("Signing:       unknown (%v)\n", err)
+		} else {
+			fmt.Printf("Signing:       %d of the last %d blocks (up to height %d)\n",
+				activity.Signed, activity.Blocks, activity.Height)
+			if activity.LastSignedHeight > 0 {
+				fmt.Printf("Last signed:   height %d at %s\n", activity.LastSignedHeight, activity.LastSignedAt.Format(time.RFC3339))
+			}
+			if activity.ConsecutiveMissed > 0 {
+				info.Problems = append(info.Problems,
+					fmt.Sprintf("the last %d blocks lack our precommit", activity.ConsecutiveMissed))
+			}
+		}
+	}
+	if len(info.Problems) > 0 {
+		for _, p := range info.Problems {
+			fmt.Printf("PROBLEM: %s\n", p)
+		}
+		log.Exit(1)
+	}
+}
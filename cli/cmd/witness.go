@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/crypto"
+	"github.com/aldebaranode/syncguard/internal/errtrack"
+	"github.com/aldebaranode/syncguard/internal/witness"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var witnessCmd = &cobra.Command{
+	Use:   "witness",
+	Short: "Run as a witness that arbitrates takeovers from a third region",
+	Long: `Runs a lightweight arbiter with no validator key and no node. The witness
+polls every configured peer, records what it observes in the history file and
+answers takeover votes: a node is granted a takeover only if the witness can
+reach it and sees no other node that is active and healthy.
+
+Deploy it in a third region or cloud so a partitioned node cannot promote
+itself. It uses its own minimal config (see witness-config-example.yaml);
+running syncguard with node.role: witness does the same.`,
+	Run: runWitnessCommand,
+}
+
+var witnessStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Print the witness's view of the cluster",
+	Long: `Fetches GET /witness/status from the witness at witness.address, signed with
+the cluster secret and, with tls.enabled, over mutual TLS. Run it with a node's
+config.`,
+	Run: runWitnessStatusCommand,
+}
+
+func init() {
+	witnessCmd.AddCommand(witnessStatusCmd)
+	rootCmd.AddCommand(witnessCmd)
+}
+
+func runWitnessStatusCommand(cmd *cobra.Command, args []string) {
+	cfg := loadConfigOrExit()
+	if cfg.Witness.Address == "" {
+		log.Fatal("No witness configured (witness.address)")
+	}
+
+	var tlsConfig *tls.Config
+	if cfg.TLS.Enabled {
+		var err error
+		tlsConfig, err = crypto.LoadTLSConfig(cfg.TLS.CAFile, cfg.TLS.CertFile, cfg.TLS.KeyFile)
+		if err != nil {
+			log.Fatalf("Failed to load peer TLS: %v", err)
+		}
+	}
+	client := witness.NewClient(cfg.Witness.Address, cfg.Node.ID,
+		crypto.NewSecretRing(cfg.Secret, cfg.PreviousSecret), cfg.Witness.Timeout.Duration(), tlsConfig)
+	status, err := client.Status()
+	if err != nil {
+		log.Fatalf("Failed to fetch witness status: %v", err)
+	}
+
+	fmt.Printf("Witness %s at %s\n", status.WitnessID, status.Time.Format(time.RFC3339))
+	if status.Lease != nil {
+		fmt.Printf("Vote leased to %s until %s\n", status.Lease.Holder, status.Lease.Expires.Format(time.RFC3339))
+	}
+	for _, peer := range status.Peers {
+		state := "unreachable"
+		if peer.Reachable {
+			state = fmt.Sprintf("healthy=%v active=%v height=%d", peer.Healthy, peer.Active, peer.Height)
+		}
+		fmt.Printf("  %-16s %-24s %s\n", peer.ID, peer.Address, state)
+		if peer.LastError != "" {
+			fmt.Printf("  %-16s last error: %s\n", "", peer.LastError)
+		}
+	}
+}
+
+func runWitnessCommand(cmd *cobra.Command, args []string) {
+	cfg, err := config.LoadWitness(options.configFile, config.LoadOptions{Lenient: options.lenient})
+	if err != nil {
+		log.Fatalf("Error loading witness config: %v", err)
+	}
+	runWitness(cfg)
+}
+
+// runWitness serves the witness until a signal stops it
+func runWitness(cfg *config.Config) {
+	stopOutputs := startLogOutputs(cfg)
+	defer stopOutputs()
+	stopTracking := startErrorTracking(cfg)
+	defer stopTracking()
+	defer errtrack.Recover()
+
+	w := witness.New(cfg)
+	go func() {
+		if err := w.Start(); err != nil {
+			log.Fatalf("Witness server error: %v", err)
+		}
+	}()
+
+	log.Infof("SyncGuard witness %s started, observing %d peers", cfg.Node.ID, len(cfg.Peers))
+
+	signalChan := make(chan os.Signal, 1)
+	signal.Notify(signalChan, syscall.SIGINT, syscall.SIGTERM)
+	sig := <-signalChan
+	log.Infof("Received signal %s. Shutting down...", sig)
+
+	w.Stop()
+	log.Info("SyncGuard witness stopped")
+}
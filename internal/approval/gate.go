@@ -0,0 +1,186 @@
+// Package approval holds automatic failover until an operator approves it.
+// When health checks give up on the active node, a request is opened with
+// the results of the preparation; the failover proceeds once an operator
+// approves it, or when the auto-approve timeout passes for unattended
+// hours. A rejected request stays in place until the node recovers, so the
+// same outage does not ask again.
+package approval
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+)
+
+// Phase is where a request stands
+type Phase string
+
+const (
+	PhasePending  Phase = "pending"
+	PhaseApproved Phase = "approved"
+	PhaseRejected Phase = "rejected"
+)
+
+// Who decided a request
+const (
+	DecidedByOperator = "operator"
+	DecidedByTimeout  = "timeout"
+)
+
+// Request is a failover waiting for, or given, a decision
+type Request struct {
+	ID        string    `json:"id"`
+	Reason    string    `json:"reason"`
+	Requested time.Time `json:"requested"`
+	// AutoApproveAt is when the request approves itself; nil waits forever
+	AutoApproveAt *time.Time `json:"auto_approve_at,omitempty"`
+	// Checks lists the preparation steps that passed
+	Checks []string `json:"checks,omitempty"`
+	// Problems lists the preparation steps that failed; approving still
+	// fails over, as automatic failover would
+	Problems  []string  `json:"problems,omitempty"`
+	Phase     Phase     `json:"phase"`
+	DecidedBy string    `json:"decided_by,omitempty"`
+	Decided   time.Time `json:"decided,omitempty"`
+}
+
+// Gate holds at most one request at a time
+type Gate struct {
+	autoApprove time.Duration
+
+	mu  sync.Mutex
+	req *Request
+}
+
+// New creates a gate from the failover configuration
+func New(cfg *config.Config) *Gate {
+	return &Gate{autoApprove: cfg.Failover.Approval.AutoApproveAfter.Duration()}
+}
+
+// Open returns the current request, or opens one for reason. created
+// reports whether the request is new and needs preparing and announcing.
+func (g *Gate) Open(reason string, now time.Time) (req Request, created bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.req != nil {
+		return g.req.snapshot(), false
+	}
+	b := make([]byte, 4)
+	rand.Read(b)
+	g.req = &Request{
+		ID:        hex.EncodeToString(b),
+		Reason:    reason,
+		Requested: now,
+		Phase:     PhasePending,
+	}
+	if g.autoApprove > 0 {
+		at := now.Add(g.autoApprove)
+		g.req.AutoApproveAt = &at
+	}
+	return g.req.snapshot(), true
+}
+
+// Prepared records the outcome of the preparation
+func (g *Gate) Prepared(id string, checks, problems []string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.req != nil && g.req.ID == id {
+		g.req.Checks = checks
+		g.req.Problems = problems
+	}
+}
+
+// Decide approves or rejects the pending request. An empty id decides
+// whichever request is pending.
+func (g *Gate) Decide(id string, approve bool, now time.Time) (Request, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.req == nil {
+		return Request{}, fmt.Errorf("no failover is awaiting approval")
+	}
+	if id != "" && id != g.req.ID {
+		return Request{}, fmt.Errorf("request %s is not current (current: %s)", id, g.req.ID)
+	}
+	if g.req.Phase != PhasePending {
+		return Request{}, fmt.Errorf("request %s is already %s", g.req.ID, g.req.Phase)
+	}
+	g.req.Phase = PhaseRejected
+	if approve {
+		g.req.Phase = PhaseApproved
+	}
+	g.req.DecidedBy = DecidedByOperator
+	g.req.Decided = now
+	return g.req.snapshot(), nil
+}
+
+// Expire approves a pending request whose auto-approve time has passed
+// and reports whether it did
+func (g *Gate) Expire(now time.Time) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	r := g.req
+	if r == nil || r.Phase != PhasePending || r.AutoApproveAt == nil || now.Before(*r.AutoApproveAt) {
+		return false
+	}
+	r.Phase = PhaseApproved
+	r.DecidedBy = DecidedByTimeout
+	r.Decided = now
+	return true
+}
+
+// Take removes and returns an approved request, so exactly one caller
+// carries out the failover
+func (g *Gate) Take() (Request, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.req == nil || g.req.Phase != PhaseApproved {
+		return Request{}, false
+	}
+	req := g.req.snapshot()
+	g.req = nil
+	return req, true
+}
+
+// Withdraw drops the request, e.g. when the node recovered, and returns it
+func (g *Gate) Withdraw() (Request, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.req == nil {
+		return Request{}, false
+	}
+	req := g.req.snapshot()
+	g.req = nil
+	return req, true
+}
+
+// Current returns the request in place, if any
+func (g *Gate) Current() *Request {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.req == nil {
+		return nil
+	}
+	req := g.req.snapshot()
+	return &req
+}
+
+func (r *Request) snapshot() Request {
+	c := *r
+	c.Checks = append([]string(nil), r.Checks...)
+	c.Problems = append([]string(nil), r.Problems...)
+	if r.AutoApproveAt != nil {
+		at := *r.AutoApproveAt
+		c.AutoApproveAt = &at
+	}
+	return c
+}
@@ -0,0 +1,102 @@
+package approval
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+)
+
+func testGate(autoApprove config.Seconds) *Gate {
+	return New(&config.Config{Failover: config.FailoverConfig{
+		Approval: config.ApprovalConfig{Enabled: true, AutoApproveAfter: autoApprove},
+	}})
+}
+
+func TestGate_OperatorApproval(t *testing.T) {
+	g := testGate(0)
+	now := time.Unix(1_700_000_000, 0)
+
+	req, created := g.Open("health_check_failed", now)
+	if !created || req.Phase != PhasePending || req.AutoApproveAt != nil {
+		t.Fatalf("first open = %+v (created=%v)", req, created)
+	}
+	if again, created := g.Open("health_check_failed", now.Add(5*time.Second)); created || again.ID != req.ID {
+		t.Fatalf("second open created a new request: %+v", again)
+	}
+	if g.Expire(now.Add(24 * time.Hour)) {
+		t.Fatal("request without auto-approve expired")
+	}
+	if _, ok := g.Take(); ok {
+		t.Fatal("took a pending request")
+	}
+
+	if _, err := g.Decide("other", true, now); err == nil {
+		t.Fatal("approved a request by the wrong ID")
+	}
+	decided, err := g.Decide(req.ID, true, now.Add(time.Minute))
+	if err != nil || decided.Phase != PhaseApproved || decided.DecidedBy != DecidedByOperator {
+		t.Fatalf("approve = %+v, %v", decided, err)
+	}
+	if _, err := g.Decide("", false, now); err == nil {
+		t.Fatal("rejected an approved request")
+	}
+
+	if _, ok := g.Take(); !ok {
+		t.Fatal("approved request not taken")
+	}
+	if _, ok := g.Take(); ok {
+		t.Fatal("approved request taken twice")
+	}
+	if g.Current() != nil {
+		t.Fatal("request still current after it was taken")
+	}
+}
+
+func TestGate_AutoApprove(t *testing.T) {
+	g := testGate(600)
+	now := time.Unix(1_700_000_000, 0)
+
+	req, _ := g.Open("node_unreachable", now)
+	if req.AutoApproveAt == nil || !req.AutoApproveAt.Equal(now.Add(10*time.Minute)) {
+		t.Fatalf("auto-approve at = %v", req.AutoApproveAt)
+	}
+	if g.Expire(now.Add(9 * time.Minute)) {
+		t.Fatal("expired early")
+	}
+	if !g.Expire(now.Add(10 * time.Minute)) {
+		t.Fatal("did not expire on time")
+	}
+	taken, ok := g.Take()
+	if !ok || taken.DecidedBy != DecidedByTimeout {
+		t.Fatalf("taken = %+v, %v", taken, ok)
+	}
+}
+
+func TestGate_RejectHoldsUntilWithdrawn(t *testing.T) {
+	g := testGate(60)
+	now := time.Unix(1_700_000_000, 0)
+
+	req, _ := g.Open("health_check_failed", now)
+	g.Prepared(req.ID, []string{"validator key readable"}, []string{"standby unreachable"})
+	if _, err := g.Decide("", false, now); err != nil {
+		t.Fatal(err)
+	}
+	// A rejected request neither expires nor is replaced during the outage
+	if g.Expire(now.Add(time.Hour)) {
+		t.Fatal("rejected request auto-approved")
+	}
+	if again, created := g.Open("health_check_failed", now.Add(time.Hour)); created || again.Phase != PhaseRejected {
+		t.Fatalf("open after reject = %+v (created=%v)", again, created)
+	}
+	if cur := g.Current(); cur == nil || len(cur.Problems) != 1 || len(cur.Checks) != 1 {
+		t.Fatalf("current = %+v", cur)
+	}
+
+	if _, ok := g.Withdraw(); !ok {
+		t.Fatal("nothing withdrawn")
+	}
+	if _, created := g.Open("health_check_failed", now.Add(2*time.Hour)); !created {
+		t.Fatal("no new request after recovery")
+	}
+}
@@ -0,0 +1,98 @@
+package chain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// bech32Charset maps 5-bit values to bech32 characters
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// bech32Encode encodes data (8-bit bytes) with the human readable part hrp
+func bech32Encode(hrp string, data []byte) (string, error) {
+	values, err := convertBits(data, 8, 5)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	sb.WriteString(hrp)
+	sb.WriteByte('1')
+	for _, v := range append(values, bech32Checksum(hrp, values)...) {
+		sb.WriteByte(bech32Charset[v])
+	}
+	return sb.String(), nil
+}
+
+// bech32Checksum computes the six checksum values for hrp and data
+func bech32Checksum(hrp string, data []byte) []byte {
+	values := make([]byte, 0, len(hrp)*2+1+len(data)+6)
+	for i := 0; i < len(hrp); i++ {
+		values = append(values, hrp[i]>>5)
+	}
+	values = append(values, 0)
+	for i := 0; i < len(hrp); i++ {
+		values = append(values, hrp[i]&31)
+	}
+	values = append(values, data...)
+	values = append(values, 0, 0, 0, 0, 0, 0)
+
+	mod := bech32Polymod(values) ^ 1
+	checksum := make([]byte, 6)
+	for i := range checksum {
+		checksum[i] = byte((mod >> uint(5*(5-i))) & 31)
+	}
+	return checksum
+}
+
+// bech32Polymod is the BCH checksum function from BIP 173
+func bech32Polymod(values []byte) uint32 {
+	generator := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= generator[i]
+			}
+		}
+	}
+	return chk
+}
+
+// convertBits regroups bits, padding the last group with zeros
+func convertBits(data []byte, from, to uint) ([]byte, error) {
+	var acc, bits uint
+	maxv := uint(1)<<to - 1
+	var out []byte
+	for _, b := range data {
+		if uint(b)>>from != 0 {
+			return nil, fmt.Errorf("invalid data byte %d", b)
+		}
+		acc = acc<<from | uint(b)
+		bits += from
+		for bits >= to {
+			bits -= to
+			out = append(out, byte(acc>>bits&maxv))
+		}
+	}
+	if bits > 0 {
+		out = append(out, byte(acc<<(to-bits)&maxv))
+	}
+	return out, nil
+}
+
+// consensusAddress derives the bech32 consensus address (valcons) of a hex
+// address from the operator address prefix, e.g. cosmosvaloper -> cosmosvalcons
+func consensusAddress(operatorAddress string, address []byte) (string, error) {
+	sep := strings.LastIndex(operatorAddress, "1")
+	if sep <= 0 {
+		return "", fmt.Errorf("invalid operator address %q", operatorAddress)
+	}
+	hrp := operatorAddress[:sep]
+	if !strings.HasSuffix(hrp, "valoper") {
+		return "", fmt.Errorf("operator address %q has no valoper prefix", operatorAddress)
+	}
+	return bech32Encode(strings.TrimSuffix(hrp, "valoper")+"valcons", address)
+}
@@ -0,0 +1,38 @@
+package chain
+
+import "testing"
+
+func TestBech32Encode(t *testing.T) {
+	// BIP 173 vectors
+	cases := []struct {
+		hrp  string
+		data []byte
+		want string
+	}{
+		{"a", nil, "a12uel5l"},
+		{"abcdef", []byte{0x00, 0x44, 0x32, 0x14, 0xc7, 0x42, 0x54, 0xb6, 0x35, 0xcf,
+			0x84, 0x65, 0x3a, 0x56, 0xd7, 0xc6, 0x75, 0xbe, 0x77, 0xdf}, "abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw"},
+	}
+	for _, c := range cases {
+		got, err := bech32Encode(c.hrp, c.data)
+		if err != nil {
+			t.Fatalf("bech32Encode(%q) failed: %v", c.hrp, err)
+		}
+		if got != c.want {
+			t.Errorf("bech32Encode(%q) = %s, want %s", c.hrp, got, c.want)
+		}
+	}
+}
+
+func TestConsensusAddress(t *testing.T) {
+	got, err := consensusAddress("cosmosvaloper1xyz", make([]byte, 20))
+	if err != nil {
+		t.Fatalf("consensusAddress failed: %v", err)
+	}
+	if got[:len("cosmosvalcons1")] != "cosmosvalcons1" {
+		t.Errorf("expected a cosmosvalcons address, got %s", got)
+	}
+	if _, err := consensusAddress("cosmos1xyz", nil); err == nil {
+		t.Error("expected an error for an account address")
+	}
+}
@@ -0,0 +1,142 @@
+package chain
+
+import (
+	"sync"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/metrics"
+)
+
+var (
+	downtimeGauge = metrics.NewGauge(
+		"syncguard_downtime_seconds",
+		"Time our validator was not signing, by window",
+		"window",
+	)
+	missedBlocksGauge    = metrics.NewGauge("syncguard_missed_blocks", "Missed blocks in the chain's signing window")
+	blocksUntilJailGauge = metrics.NewGauge("syncguard_blocks_until_jail", "Blocks that may still be missed before downtime jailing")
+)
+
+// budgetRetention is how far back the ledger keeps intervals (one week)
+const budgetRetention = 7 * 24 * time.Hour
+
+// Interval is a period in which our validator was not signing
+type Interval struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Budget summarizes signing downtime: our own measured not-signing time
+// over the last day and week, and the chain's missed-block window
+type Budget struct {
+	DaySeconds  float64 `json:"day_seconds"`
+	WeekSeconds float64 `json:"week_seconds"`
+	Down        bool    `json:"down"`
+	// Window is the chain's view; nil without slashing info
+	Window *Risk `json:"window,omitempty"`
+}
+
+// Ledger accumulates not-signing intervals over the last week
+type Ledger struct {
+	mu        sync.Mutex
+	intervals []Interval
+	openSince time.Time
+}
+
+// NewLedger creates an empty ledger
+func NewLedger() *Ledger {
+	return &Ledger{}
+}
+
+// Add records a completed interval, e.g. one read back from the history
+func (l *Ledger) Add(iv Interval) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.intervals = append(l.intervals, iv)
+}
+
+// Begin opens a not-signing interval; it is a no-op while one is open
+func (l *Ledger) Begin(t time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.openSince.IsZero() {
+		l.openSince = t
+	}
+}
+
+// End closes the open interval and returns it
+func (l *Ledger) End(t time.Time) (Interval, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.openSince.IsZero() {
+		return Interval{}, false
+	}
+	iv := Interval{Start: l.openSince, End: t}
+	l.openSince = time.Time{}
+	l.intervals = append(l.intervals, iv)
+	l.pruneLocked(t)
+	return iv, true
+}
+
+// Down reports whether an interval is open
+func (l *Ledger) Down() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return !l.openSince.IsZero()
+}
+
+// Total returns the downtime within [now-window, now], counting an open
+// interval up to now
+func (l *Ledger) Total(window time.Duration, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	from := now.Add(-window)
+	var total time.Duration
+	add := func(start, end time.Time) {
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(start) {
+			total += end.Sub(start)
+		}
+	}
+	for _, iv := range l.intervals {
+		add(iv.Start, iv.End)
+	}
+	if !l.openSince.IsZero() {
+		add(l.openSince, now)
+	}
+	return total
+}
+
+// Budget returns the day and week totals
+func (l *Ledger) Budget(now time.Time) Budget {
+	return Budget{
+		DaySeconds:  l.Total(24*time.Hour, now).Seconds(),
+		WeekSeconds: l.Total(budgetRetention, now).Seconds(),
+		Down:        l.Down(),
+	}
+}
+
+// pruneLocked drops intervals that ended before the retention period
+func (l *Ledger) pruneLocked(now time.Time) {
+	cutoff := now.Add(-budgetRetention)
+	kept := l.intervals[:0]
+	for _, iv := range l.intervals {
+		if iv.End.After(cutoff) {
+			kept = append(kept, iv)
+		}
+	}
+	l.intervals = kept
+}
+
+// Publish exports the budget as metrics
+func (b Budget) Publish() {
+	downtimeGauge.Set(b.DaySeconds, "day")
+	downtimeGauge.Set(b.WeekSeconds, "week")
+	if b.Window != nil {
+		missedBlocksGauge.Set(float64(b.Window.MissedBlocks))
+		blocksUntilJailGauge.Set(float64(b.Window.BlocksUntilJail))
+	}
+}
@@ -0,0 +1,42 @@
+package chain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/chain"
+)
+
+func TestLedger_Totals(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	l := chain.NewLedger()
+
+	// Two days ago: counts for the week only
+	l.Add(chain.Interval{Start: now.Add(-48 * time.Hour), End: now.Add(-48*time.Hour + 10*time.Minute)})
+	// Straddles the day boundary: only the last 5 minutes count for the day
+	l.Add(chain.Interval{Start: now.Add(-24*time.Hour - 5*time.Minute), End: now.Add(-24*time.Hour + 5*time.Minute)})
+	// Open interval, 2 minutes so far
+	l.Begin(now.Add(-2 * time.Minute))
+
+	b := l.Budget(now)
+	if b.DaySeconds != 7*60 {
+		t.Errorf("expected 420s in the last day, got %v", b.DaySeconds)
+	}
+	if b.WeekSeconds != 22*60 {
+		t.Errorf("expected 1320s in the last week, got %v", b.WeekSeconds)
+	}
+	if !b.Down {
+		t.Error("expected the open interval to be reported")
+	}
+
+	iv, ok := l.End(now)
+	if !ok || iv.End.Sub(iv.Start) != 2*time.Minute {
+		t.Errorf("unexpected closed interval %+v", iv)
+	}
+	if _, ok := l.End(now); ok {
+		t.Error("expected no open interval after End")
+	}
+	if got := l.Total(24*time.Hour, now); got != 7*time.Minute {
+		t.Errorf("expected the closed interval to be kept, got %v", got)
+	}
+}
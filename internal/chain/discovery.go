@@ -0,0 +1,264 @@
+package chain
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/state"
+	"github.com/cometbft/cometbft/crypto/ed25519"
+	k1 "github.com/cometbft/cometbft/crypto/secp256k1"
+)
+
+// validatorsPageSize is the page size for /validators and LCD queries
+const validatorsPageSize = 100
+
+// ValidatorInfo is our validator's metadata as seen by the chain
+type ValidatorInfo struct {
+	// Address is the consensus address (hex) of the key on disk
+	Address    string `json:"address"`
+	PubKeyType string `json:"pub_key_type"`
+	PubKey     string `json:"pub_key"`
+
+	// InValidatorSet is true when the CometBFT validator set includes us
+	InValidatorSet   bool  `json:"in_validator_set"`
+	VotingPower      int64 `json:"voting_power"`
+	TotalVotingPower int64 `json:"total_voting_power"`
+	Height           int64 `json:"height"`
+
+	// Staking module fields, only set when chain.lcd_url is configured
+	// and the validator was found
+	OperatorAddress string `json:"operator_address,omitempty"`
+	Moniker         string `json:"moniker,omitempty"`
+	Jailed          bool   `json:"jailed"`
+	BondStatus      string `json:"bond_status,omitempty"`
+	Tokens          string `json:"tokens,omitempty"`
+
+	// Slashing module fields, also only with chain.lcd_url
+	ConsensusAddress string          `json:"consensus_address,omitempty"`
+	Slashing         *SlashingParams `json:"slashing,omitempty"`
+	MissedBlocks     int64           `json:"missed_blocks"`
+
+	// Problems lists verification failures, e.g. a key whose address does
+	// not match its public key
+	Problems  []string  `json:"problems,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Discoverer looks up our validator on chain and caches the result
+type Discoverer struct {
+	rpcURL string
+	lcdURL string
+	client *http.Client
+
+	mu   sync.RWMutex
+	info *ValidatorInfo
+}
+
+// NewDiscoverer creates a discoverer for the configured RPC and LCD endpoints
+func NewDiscoverer(cfg *config.Config) *Discoverer {
+	return &Discoverer{
+		rpcURL: strings.TrimRight(cfg.CometBFT.RPCURL, "/"),
+		lcdURL: strings.TrimRight(cfg.Chain.LCDURL, "/"),
+		client: &http.Client{
+			Timeout: cfg.Health.Timeout.Duration(),
+		},
+	}
+}
+
+// Info returns the last discovered metadata, if any
+func (d *Discoverer) Info() (ValidatorInfo, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.info == nil {
+		return ValidatorInfo{}, false
+	}
+	return *d.info, true
+}
+
+// Discover queries the chain for the validator owning key and caches the
+// result. Staking lookups are skipped without an LCD URL.
+func (d *Discoverer) Discover(key *state.ValidatorKey) (*ValidatorInfo, error) {
+	info, err := identify(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := d.queryValidatorSet(info); err != nil {
+		return nil, err
+	}
+	if !info.InValidatorSet {
+		info.Problems = append(info.Problems, "key is not in the active validator set")
+	}
+
+	if d.lcdURL != "" {
+		found, err := d.queryStaking(info)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			info.Problems = append(info.Problems, "no staking validator has this consensus key")
+		} else {
+			if info.Jailed {
+				info.Problems = append(info.Problems, "validator is jailed")
+			}
+			if err := d.querySlashing(info); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	info.UpdatedAt = time.Now().UTC()
+	d.mu.Lock()
+	d.info = info
+	d.mu.Unlock()
+	return info, nil
+}
+
+// identify reads the address and public key of a validator key and checks
+// that the address is derived from the public key
+func identify(key *state.ValidatorKey) (*ValidatorInfo, error) {
+	var pub struct {
+		Type  string `json:"type"`
+		Value string `json:"value"`
+	}
+	if err := json.Unmarshal(key.PubKey, &pub); err != nil {
+		return nil, fmt.Errorf("failed to parse pub_key: %w", err)
+	}
+	raw, err := base64.StdEncoding.DecodeString(pub.Value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode pub_key: %w", err)
+	}
+
+	info := &ValidatorInfo{
+		Address:    strings.ToUpper(key.Address),
+		PubKeyType: pub.Type,
+		PubKey:     pub.Value,
+	}
+
+	var derived []byte
+	switch {
+	case strings.Contains(pub.Type, "Secp256k1"):
+		derived = k1.PubKey(raw).Address()
+	case strings.Contains(pub.Type, "Ed25519"):
+		derived = ed25519.PubKey(raw).Address()
+	default:
+		info.Problems = append(info.Problems, fmt.Sprintf("unknown key type %q, address not verified", pub.Type))
+	}
+	if derived != nil && !strings.EqualFold(hex.EncodeToString(derived), key.Address) {
+		info.Problems = append(info.Problems, fmt.Sprintf("key address %s does not match its public key (%X)", key.Address, derived))
+	}
+	return info, nil
+}
+
+// queryValidatorSet pages through the CometBFT validator set looking for us
+func (d *Discoverer) queryValidatorSet(info *ValidatorInfo) error {
+	for page := 1; ; page++ {
+		var resp struct {
+			Result struct {
+				BlockHeight string `json:"block_height"`
+				Validators  []struct {
+					Address     string `json:"address"`
+					VotingPower string `json:"voting_power"`
+				} `json:"validators"`
+				Total string `json:"total"`
+			} `json:"result"`
+		}
+		path := fmt.Sprintf("%s/validators?page=%d&per_page=%d", d.rpcURL, page, validatorsPageSize)
+		if err := d.getJSON(path, &resp); err != nil {
+			return fmt.Errorf("failed to query validator set: %w", err)
+		}
+
+		info.Height, _ = strconv.ParseInt(resp.Result.BlockHeight, 10, 64)
+		for _, v := range resp.Result.Validators {
+			power, _ := strconv.ParseInt(v.VotingPower, 10, 64)
+			info.TotalVotingPower += power
+			if strings.EqualFold(v.Address, info.Address) {
+				info.InValidatorSet = true
+				info.VotingPower = power
+			}
+		}
+
+		total, _ := strconv.Atoi(resp.Result.Total)
+		if len(resp.Result.Validators) == 0 || page*validatorsPageSize >= total {
+			return nil
+		}
+	}
+}
+
+// queryStaking pages through the staking module's validators looking for
+// our consensus key. It reports whether one was found.
+func (d *Discoverer) queryStaking(info *ValidatorInfo) (bool, error) {
+	nextKey := ""
+	for {
+		var resp struct {
+			Validators []struct {
+				OperatorAddress string `json:"operator_address"`
+				ConsensusPubkey struct {
+					Key string `json:"key"`
+				} `json:"consensus_pubkey"`
+				Jailed      bool   `json:"jailed"`
+				Status      string `json:"status"`
+				Tokens      string `json:"tokens"`
+				Description struct {
+					Moniker string `json:"moniker"`
+				} `json:"description"`
+			} `json:"validators"`
+			Pagination struct {
+				NextKey string `json:"next_key"`
+			} `json:"pagination"`
+		}
+
+		query := url.Values{"pagination.limit": {strconv.Itoa(validatorsPageSize)}}
+		if nextKey != "" {
+			query.Set("pagination.key", nextKey)
+		}
+		if err := d.getJSON(d.lcdURL+"/cosmos/staking/v1beta1/validators?"+query.Encode(), &resp); err != nil {
+			return false, fmt.Errorf("failed to query staking validators: %w", err)
+		}
+
+		for _, v := range resp.Validators {
+			if v.ConsensusPubkey.Key != info.PubKey {
+				continue
+			}
+			info.OperatorAddress = v.OperatorAddress
+			info.Moniker = v.Description.Moniker
+			info.Jailed = v.Jailed
+			info.BondStatus = v.Status
+			info.Tokens = v.Tokens
+			return true, nil
+		}
+
+		if resp.Pagination.NextKey == "" {
+			return false, nil
+		}
+		nextKey = resp.Pagination.NextKey
+	}
+}
+
+// getJSON fetches url and decodes its JSON body into out
+func (d *Discoverer) getJSON(url string, out interface{}) error {
+	resp, err := d.client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return json.Unmarshal(body, out)
+}
@@ -0,0 +1,134 @@
+package chain_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aldebaranode/syncguard/internal/chain"
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/state"
+	k1 "github.com/cometbft/cometbft/crypto/secp256k1"
+)
+
+// testKey returns a validator key file and its base64 public key
+func testKey() (*state.ValidatorKey, string) {
+	pub := k1.GenPrivKey().PubKey()
+	value := base64.StdEncoding.EncodeToString(pub.Bytes())
+	return &state.ValidatorKey{
+		Address: fmt.Sprintf("%X", pub.Address()),
+		PubKey:  json.RawMessage(fmt.Sprintf(`{"type":"tendermint/PubKeySecp256k1","value":"%s"}`, value)),
+	}, value
+}
+
+// fakeChain serves /validators and the staking validators endpoint
+func fakeChain(t *testing.T, address, pubKey string, jailed bool) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/validators":
+			fmt.Fprintf(w, `{"result":{"block_height":"42","validators":[
+				{"address":"%s","voting_power":"30"},
+				{"address":"AAAA","voting_power":"70"}],"total":"2"}}`, address)
+		case "/cosmos/staking/v1beta1/validators":
+			if r.URL.Query().Get("pagination.key") == "" {
+				fmt.Fprint(w, `{"validators":[{"operator_address":"valoper1other","consensus_pubkey":{"key":"b3RoZXI="}}],
+					"pagination":{"next_key":"page2"}}`)
+				return
+			}
+			fmt.Fprintf(w, `{"validators":[{"operator_address":"valoper1ours","consensus_pubkey":{"key":"%s"},
+				"jailed":%v,"status":"BOND_STATUS_BONDED","tokens":"1000","description":{"moniker":"ours"}}],
+				"pagination":{"next_key":null}}`, pubKey, jailed)
+		case "/cosmos/slashing/v1beta1/params":
+			fmt.Fprint(w, `{"params":{"signed_blocks_window":"10000","min_signed_per_window":"0.050000000000000000",
+				"downtime_jail_duration":"600s","slash_fraction_downtime":"0.000100000000000000"}}`)
+		default:
+			if strings.HasPrefix(r.URL.Path, "/cosmos/slashing/v1beta1/signing_infos/valcons1") {
+				fmt.Fprint(w, `{"val_signing_info":{"missed_blocks_counter":"250"}}`)
+				return
+			}
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newTestConfig(url string) *config.Config {
+	return &config.Config{
+		CometBFT: config.CometBFTConfig{RPCURL: url},
+		Chain:    config.ChainConfig{LCDURL: url},
+		Health:   config.HealthConfig{Timeout: 5},
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	key, pub := testKey()
+	d := chain.NewDiscoverer(newTestConfig(fakeChain(t, key.Address, pub, false).URL))
+
+	if _, ok := d.Info(); ok {
+		t.Fatal("expected no cached info before discovery")
+	}
+	info, err := d.Discover(key)
+	if err != nil {
+		t.Fatalf("Discover failed: %v", err)
+	}
+
+	if !info.InValidatorSet || info.VotingPower != 30 || info.TotalVotingPower != 100 || info.Height != 42 {
+		t.Errorf("unexpected validator set view: %+v", info)
+	}
+	if info.OperatorAddress != "valoper1ours" || info.Moniker != "ours" || info.Jailed {
+		t.Errorf("unexpected staking view: %+v", info)
+	}
+	if len(info.Problems) != 0 {
+		t.Errorf("expected no problems, got %v", info.Problems)
+	}
+	if info.Slashing == nil || info.Slashing.SignedBlocksWindow != 10000 || info.Slashing.DowntimeJailDuration != 600 {
+		t.Errorf("unexpected slashing params: %+v", info.Slashing)
+	}
+	if info.MissedBlocks != 250 {
+		t.Errorf("expected 250 missed blocks, got %d", info.MissedBlocks)
+	}
+	if share := info.VotingPowerShare(); share != 0.3 {
+		t.Errorf("expected voting power share 0.3, got %v", share)
+	}
+	if cached, ok := d.Info(); !ok || cached.OperatorAddress != "valoper1ours" {
+		t.Error("expected discovery to be cached")
+	}
+}
+
+func TestDiscover_Problems(t *testing.T) {
+	key, pub := testKey()
+	d := chain.NewDiscoverer(newTestConfig(fakeChain(t, "BBBB", pub, true).URL))
+
+	// Address that does not belong to the public key
+	key.Address = "48DC218393FCEEF56A37D963B804FAB92C62CA9D"
+	info, err := d.Discover(key)
+	if err != nil {
+		t.Fatalf("Discover failed: %v", err)
+	}
+
+	problems := strings.Join(info.Problems, "; ")
+	for _, want := range []string{"does not match its public key", "not in the active validator set", "jailed"} {
+		if !strings.Contains(problems, want) {
+			t.Errorf("expected problem %q, got %q", want, problems)
+		}
+	}
+}
+
+func TestAssessRisk(t *testing.T) {
+	params := chain.SlashingParams{SignedBlocksWindow: 10000, MinSignedPerWindow: 0.05}
+
+	risk := chain.AssessRisk(params, 4750)
+	if risk.MaxMissed != 9500 || risk.BlocksUntilJail != 4750 || risk.WindowFill != 0.5 {
+		t.Errorf("unexpected risk: %+v", risk)
+	}
+
+	risk = chain.AssessRisk(params, 12000)
+	if risk.BlocksUntilJail != 0 || risk.WindowFill != 1 {
+		t.Errorf("expected a full window, got %+v", risk)
+	}
+}
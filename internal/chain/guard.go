@@ -0,0 +1,41 @@
+package chain
+
+import (
+	"fmt"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+)
+
+// SignGuard reads recent commits for precommits of our validator before a
+// node takes over. A precommit above the validator state the node would
+// sign from means another node signed blocks it does not know about.
+type SignGuard struct {
+	feed  *SigningFeed
+	depth int
+}
+
+// NewSignGuard creates a guard over the configured CometBFT RPC, looking
+// back chain.double_sign_guard.depth blocks
+func NewSignGuard(cfg *config.Config) *SignGuard {
+	return &SignGuard{feed: NewSigningFeed(cfg), depth: cfg.Chain.DoubleSignGuard.Depth}
+}
+
+// SignedAbove returns the highest of the latest depth blocks, the tip
+// included, whose commit carries a precommit from address above height;
+// 0 when there is none. Blocks at or below height are not read.
+func (g *SignGuard) SignedAbove(address string, height int64) (int64, error) {
+	latest, err := g.feed.latestHeight()
+	if err != nil {
+		return 0, err
+	}
+	for h := latest; h > latest-int64(g.depth) && h > height && h > 0; h-- {
+		block, err := g.feed.commit(h, address)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read commit at height %d: %w", h, err)
+		}
+		if block.Signed {
+			return h, nil
+		}
+	}
+	return 0, nil
+}
@@ -0,0 +1,48 @@
+package chain_test
+
+import (
+	"testing"
+
+	"github.com/aldebaranode/syncguard/internal/chain"
+	"github.com/aldebaranode/syncguard/internal/config"
+)
+
+func TestSignGuard_SignedAbove(t *testing.T) {
+	fake := &fakeCommits{tip: 50, signed: func(h int64) bool { return h <= 40 }}
+	cfg := &config.Config{
+		CometBFT: config.CometBFTConfig{RPCURL: fake.serve(t).URL},
+		Health:   config.HealthConfig{Timeout: 5},
+		Chain:    config.ChainConfig{DoubleSignGuard: config.DoubleSignGuardConfig{Depth: 20}},
+	}
+	guard := chain.NewSignGuard(cfg)
+
+	// The validator stopped signing at 40, where our state is
+	if height, err := guard.SignedAbove("ABCD", 40); err != nil || height != 0 {
+		t.Errorf("state at the last precommit: got %d, %v", height, err)
+	}
+	// A state behind the chain misses precommits 36 to 40
+	if height, err := guard.SignedAbove("ABCD", 35); err != nil || height != 40 {
+		t.Errorf("state behind the chain: got %d, %v, want 40", height, err)
+	}
+	// Precommits older than the depth are not looked at
+	if height, err := guard.SignedAbove("ABCD", 0); err != nil || height != 40 {
+		t.Errorf("got %d, %v, want 40", height, err)
+	}
+	fake.mu.Lock()
+	fake.tip = 70
+	fake.mu.Unlock()
+	if height, err := guard.SignedAbove("ABCD", 0); err != nil || height != 0 {
+		t.Errorf("precommits past the depth: got %d, %v", height, err)
+	}
+}
+
+func TestSignGuard_RPCDown(t *testing.T) {
+	cfg := &config.Config{
+		CometBFT: config.CometBFTConfig{RPCURL: "http://127.0.0.1:1"},
+		Health:   config.HealthConfig{Timeout: 1},
+		Chain:    config.ChainConfig{DoubleSignGuard: config.DoubleSignGuardConfig{Depth: 20}},
+	}
+	if _, err := chain.NewSignGuard(cfg).SignedAbove("ABCD", 0); err == nil {
+		t.Error("expected an error without an RPC")
+	}
+}
@@ -0,0 +1,277 @@
+package chain
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/metrics"
+)
+
+var (
+	signedBlocksCounter = metrics.NewCounter(
+		"syncguard_signing_blocks_total",
+		"Blocks examined for our validator's precommit, by whether it was there",
+		"result",
+	)
+	consecutiveMissedGauge = metrics.NewGauge(
+		"syncguard_signing_consecutive_missed",
+		"Latest blocks in a row without our validator's precommit",
+	)
+)
+
+// blockIDFlagAbsent marks a validator that sent no precommit. Nil votes
+// count as signed, as they do for the slashing module's liveness.
+const blockIDFlagAbsent = 1
+
+// BlockSigning is whether one block's commit carries our precommit
+type BlockSigning struct {
+	Height int64     `json:"height"`
+	Time   time.Time `json:"time"`
+	Signed bool      `json:"signed"`
+}
+
+// SigningActivity summarizes our validator's precommits in recent blocks
+type SigningActivity struct {
+	// ChainID is the network the RPC reports
+	ChainID string `json:"chain_id,omitempty"`
+	Address string `json:"address"`
+	// Height is the latest block examined
+	Height int64 `json:"height"`
+	Blocks int   `json:"blocks"`
+	Signed int   `json:"signed"`
+	Missed int   `json:"missed"`
+	// ConsecutiveMissed counts the latest blocks in a row without our
+	// precommit
+	ConsecutiveMissed int       `json:"consecutive_missed"`
+	LastSignedHeight  int64     `json:"last_signed_height,omitempty"`
+	LastSignedAt      time.Time `json:"last_signed_at,omitempty"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// SigningFeed tails blocks and keeps a window of whether each carried our
+// precommit
+type SigningFeed struct {
+	rpcURL string
+	window int
+	client *http.Client
+
+	mu      sync.RWMutex
+	chainID string
+	address string
+	blocks  []BlockSigning
+	updated time.Time
+}
+
+// NewSigningFeed creates a feed over the configured CometBFT RPC
+func NewSigningFeed(cfg *config.Config) *SigningFeed {
+	return &SigningFeed{
+		rpcURL: strings.TrimRight(cfg.CometBFT.RPCURL, "/"),
+		window: cfg.Chain.Signing.Window,
+		client: &http.Client{Timeout: cfg.Health.Timeout.Duration()},
+	}
+}
+
+// Poll examines the blocks committed since the last poll, at most a window
+// of them, for precommits from address. The latest block is left out as
+// its commit is not final yet.
+func (f *SigningFeed) Poll(address string) (SigningActivity, error) {
+	latest, err := f.latestHeight()
+	if err != nil {
+		return SigningActivity{}, err
+	}
+
+	f.mu.RLock()
+	from := latest - 1 - int64(f.window)
+	if f.address == address && len(f.blocks) > 0 && f.blocks[len(f.blocks)-1].Height > from {
+		from = f.blocks[len(f.blocks)-1].Height
+	}
+	f.mu.RUnlock()
+
+	var fetched []BlockSigning
+	for height := from + 1; height < latest; height++ {
+		if height < 1 {
+			continue
+		}
+		block, err := f.commit(height, address)
+		if err != nil {
+			// Keep what was fetched; the next poll resumes from there
+			f.record(address, fetched)
+			return f.activity(), fmt.Errorf("failed to read commit at height %d: %w", height, err)
+		}
+		fetched = append(fetched, block)
+	}
+	f.record(address, fetched)
+	return f.activity(), nil
+}
+
+// record appends examined blocks, dropping those past the window and all
+// of them when the address changed
+func (f *SigningFeed) record(address string, fetched []BlockSigning) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.address != address {
+		f.address = address
+		f.blocks = nil
+	}
+	for _, b := range fetched {
+		result := "missed"
+		if b.Signed {
+			result = "signed"
+		}
+		signedBlocksCounter.Inc(result)
+	}
+	f.blocks = append(f.blocks, fetched...)
+	if excess := len(f.blocks) - f.window; excess > 0 {
+		f.blocks = append([]BlockSigning(nil), f.blocks[excess:]...)
+	}
+	f.updated = time.Now().UTC()
+}
+
+// Activity returns the summary of the last poll; false before the first
+func (f *SigningFeed) Activity() (SigningActivity, bool) {
+	f.mu.RLock()
+	polled := !f.updated.IsZero()
+	f.mu.RUnlock()
+	if !polled {
+		return SigningActivity{}, false
+	}
+	return f.activity(), true
+}
+
+func (f *SigningFeed) activity() SigningActivity {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	a := SigningActivity{ChainID: f.chainID, Address: f.address, Blocks: len(f.blocks), UpdatedAt: f.updated}
+	trailing := true
+	for i := len(f.blocks) - 1; i >= 0; i-- {
+		b := f.blocks[i]
+		if a.Height == 0 {
+			a.Height = b.Height
+		}
+		if b.Signed {
+			a.Signed++
+			trailing = false
+			if a.LastSignedHeight == 0 {
+				a.LastSignedHeight = b.Height
+				a.LastSignedAt = b.Time
+			}
+			continue
+		}
+		a.Missed++
+		if trailing {
+			a.ConsecutiveMissed++
+		}
+	}
+	consecutiveMissedGauge.Set(float64(a.ConsecutiveMissed))
+	return a
+}
+
+// MissedSince counts the latest blocks in a row without our precommit,
+// only looking at blocks committed after t
+func (f *SigningFeed) MissedSince(t time.Time) int {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	missed := 0
+	for i := len(f.blocks) - 1; i >= 0 && !f.blocks[i].Signed && f.blocks[i].Time.After(t); i-- {
+		missed++
+	}
+	return missed
+}
+
+// Blocks returns the examined blocks committed at or after since, oldest
+// first
+func (f *SigningFeed) Blocks(since time.Time) []BlockSigning {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	var out []BlockSigning
+	for _, b := range f.blocks {
+		if !b.Time.Before(since) {
+			out = append(out, b)
+		}
+	}
+	return out
+}
+
+// latestHeight asks the RPC for the latest block height, noting the
+// chain ID on the way
+func (f *SigningFeed) latestHeight() (int64, error) {
+	var resp struct {
+		Result struct {
+			NodeInfo struct {
+				Network string `json:"network"`
+			} `json:"node_info"`
+			SyncInfo struct {
+				LatestBlockHeight string `json:"latest_block_height"`
+			} `json:"sync_info"`
+		} `json:"result"`
+	}
+	if err := f.getJSON(f.rpcURL+"/status", &resp); err != nil {
+		return 0, fmt.Errorf("failed to query status: %w", err)
+	}
+	height, err := strconv.ParseInt(resp.Result.SyncInfo.LatestBlockHeight, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid latest block height %q", resp.Result.SyncInfo.LatestBlockHeight)
+	}
+	if network := resp.Result.NodeInfo.Network; network != "" {
+		f.mu.Lock()
+		f.chainID = network
+		f.mu.Unlock()
+	}
+	return height, nil
+}
+
+// commit reads the commit of height and whether address precommitted
+func (f *SigningFeed) commit(height int64, address string) (BlockSigning, error) {
+	var resp struct {
+		Result struct {
+			SignedHeader struct {
+				Header struct {
+					Time time.Time `json:"time"`
+				} `json:"header"`
+				Commit struct {
+					Signatures []struct {
+						BlockIDFlag      json.Number `json:"block_id_flag"`
+						ValidatorAddress string      `json:"validator_address"`
+					} `json:"signatures"`
+				} `json:"commit"`
+			} `json:"signed_header"`
+		} `json:"result"`
+	}
+	if err := f.getJSON(fmt.Sprintf("%s/commit?height=%d", f.rpcURL, height), &resp); err != nil {
+		return BlockSigning{}, err
+	}
+
+	block := BlockSigning{Height: height, Time: resp.Result.SignedHeader.Header.Time.UTC()}
+	for _, sig := range resp.Result.SignedHeader.Commit.Signatures {
+		if !strings.EqualFold(sig.ValidatorAddress, address) {
+			continue
+		}
+		flag, _ := sig.BlockIDFlag.Int64()
+		block.Signed = flag != blockIDFlagAbsent
+		break
+	}
+	return block, nil
+}
+
+// getJSON fetches url and decodes its JSON body into out
+func (f *SigningFeed) getJSON(url string, out interface{}) error {
+	resp, err := f.client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,97 @@
+package chain_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/chain"
+	"github.com/aldebaranode/syncguard/internal/config"
+)
+
+// fakeCommits serves /status and /commit for a chain at tip, where our
+// validator precommitted at the heights signed reports true for
+type fakeCommits struct {
+	mu     sync.Mutex
+	tip    int64
+	signed func(height int64) bool
+}
+
+func (f *fakeCommits) serve(t *testing.T) *httptest.Server {
+	genesis := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		switch r.URL.Path {
+		case "/status":
+			fmt.Fprintf(w, `{"result":{"node_info":{"network":"test-1"},"sync_info":{"latest_block_height":"%d"}}}`, f.tip)
+		case "/commit":
+			height, _ := strconv.ParseInt(r.URL.Query().Get("height"), 10, 64)
+			flag := 1
+			if f.signed(height) {
+				flag = 2
+			}
+			fmt.Fprintf(w, `{"result":{"signed_header":{"header":{"time":"%s"},"commit":{"signatures":[
+				{"block_id_flag":2,"validator_address":"AAAA"},
+				{"block_id_flag":%d,"validator_address":"%s"}]}}}}`,
+				genesis.Add(time.Duration(height)*time.Second).Format(time.RFC3339), flag, "abcd")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestSigningFeed(t *testing.T) {
+	fake := &fakeCommits{tip: 21, signed: func(h int64) bool { return h%5 != 0 }}
+	cfg := &config.Config{
+		CometBFT: config.CometBFTConfig{RPCURL: fake.serve(t).URL},
+		Health:   config.HealthConfig{Timeout: 5},
+		Chain:    config.ChainConfig{Signing: config.SigningConfig{Window: 10}},
+	}
+	feed := chain.NewSigningFeed(cfg)
+
+	if _, ok := feed.Activity(); ok {
+		t.Fatal("expected no activity before the first poll")
+	}
+	// Heights 11..20: the tip's commit is not final yet
+	activity, err := feed.Poll("ABCD")
+	if err != nil {
+		t.Fatalf("Poll failed: %v", err)
+	}
+	if activity.Blocks != 10 || activity.Height != 20 || activity.Signed != 8 || activity.Missed != 2 {
+		t.Errorf("unexpected activity: %+v", activity)
+	}
+	if activity.ChainID != "test-1" {
+		t.Errorf("expected chain ID from /status, got %q", activity.ChainID)
+	}
+	if activity.ConsecutiveMissed != 1 || activity.LastSignedHeight != 19 {
+		t.Errorf("expected height 20 missed after 19 signed: %+v", activity)
+	}
+
+	// The next poll only reads new blocks and slides the window
+	fake.mu.Lock()
+	fake.tip = 25
+	fake.signed = func(h int64) bool { return h < 20 }
+	fake.mu.Unlock()
+	activity, err = feed.Poll("ABCD")
+	if err != nil {
+		t.Fatalf("Poll failed: %v", err)
+	}
+	if activity.Blocks != 10 || activity.Height != 24 || activity.ConsecutiveMissed != 5 {
+		t.Errorf("unexpected activity after the second poll: %+v", activity)
+	}
+
+	since := time.Date(2026, 1, 1, 0, 0, 22, 0, time.UTC)
+	if missed := feed.MissedSince(since); missed != 2 {
+		t.Errorf("MissedSince = %d, want the 2 blocks after %s", missed, since)
+	}
+	if blocks := feed.Blocks(since); len(blocks) != 3 || blocks[0].Height != 22 {
+		t.Errorf("Blocks(since) = %+v, want heights 22-24", blocks)
+	}
+}
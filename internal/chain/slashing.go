@@ -0,0 +1,135 @@
+package chain
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SlashingParams are the slashing module's downtime parameters
+type SlashingParams struct {
+	SignedBlocksWindow    int64   `json:"signed_blocks_window"`
+	MinSignedPerWindow    float64 `json:"min_signed_per_window"`
+	DowntimeJailDuration  float64 `json:"downtime_jail_duration_seconds"`
+	SlashFractionDowntime float64 `json:"slash_fraction_downtime"`
+}
+
+// MaxMissed is the number of blocks a validator may miss per window
+// before it is jailed for downtime
+func (p SlashingParams) MaxMissed() int64 {
+	return int64(math.Floor(float64(p.SignedBlocksWindow) * (1 - p.MinSignedPerWindow)))
+}
+
+// Risk is how close the validator is to being jailed for downtime
+type Risk struct {
+	MissedBlocks    int64   `json:"missed_blocks"`
+	MaxMissed       int64   `json:"max_missed"`
+	BlocksUntilJail int64   `json:"blocks_until_jail"`
+	WindowFill      float64 `json:"window_fill"`
+}
+
+// AssessRisk computes the downtime jailing risk for a missed block count
+func AssessRisk(params SlashingParams, missed int64) Risk {
+	risk := Risk{MissedBlocks: missed, MaxMissed: params.MaxMissed()}
+	risk.BlocksUntilJail = risk.MaxMissed - missed
+	if risk.BlocksUntilJail < 0 {
+		risk.BlocksUntilJail = 0
+	}
+	if risk.MaxMissed > 0 {
+		risk.WindowFill = math.Min(float64(missed)/float64(risk.MaxMissed), 1)
+	} else if missed > 0 {
+		risk.WindowFill = 1
+	}
+	return risk
+}
+
+// VotingPowerShare is our fraction of the total voting power
+func (v ValidatorInfo) VotingPowerShare() float64 {
+	if v.TotalVotingPower == 0 {
+		return 0
+	}
+	return float64(v.VotingPower) / float64(v.TotalVotingPower)
+}
+
+// querySlashing reads the slashing parameters and our signing info
+func (d *Discoverer) querySlashing(info *ValidatorInfo) error {
+	var params struct {
+		Params struct {
+			SignedBlocksWindow    string `json:"signed_blocks_window"`
+			MinSignedPerWindow    string `json:"min_signed_per_window"`
+			DowntimeJailDuration  string `json:"downtime_jail_duration"`
+			SlashFractionDowntime string `json:"slash_fraction_downtime"`
+		} `json:"params"`
+	}
+	if err := d.getJSON(d.lcdURL+"/cosmos/slashing/v1beta1/params", &params); err != nil {
+		return fmt.Errorf("failed to query slashing params: %w", err)
+	}
+
+	p := &SlashingParams{}
+	p.SignedBlocksWindow, _ = strconv.ParseInt(params.Params.SignedBlocksWindow, 10, 64)
+	p.MinSignedPerWindow, _ = strconv.ParseFloat(params.Params.MinSignedPerWindow, 64)
+	p.SlashFractionDowntime, _ = strconv.ParseFloat(params.Params.SlashFractionDowntime, 64)
+	if jail, err := time.ParseDuration(params.Params.DowntimeJailDuration); err == nil {
+		p.DowntimeJailDuration = jail.Seconds()
+	}
+	info.Slashing = p
+
+	address, err := hex.DecodeString(info.Address)
+	if err != nil {
+		return fmt.Errorf("invalid validator address: %w", err)
+	}
+	cons, err := consensusAddress(info.OperatorAddress, address)
+	if err != nil {
+		return err
+	}
+	info.ConsensusAddress = cons
+
+	missed, err := d.queryMissedBlocks(cons)
+	if err != nil {
+		return err
+	}
+	info.MissedBlocks = missed
+	return nil
+}
+
+// queryMissedBlocks reads the missed block counter of a consensus address
+func (d *Discoverer) queryMissedBlocks(consensusAddress string) (int64, error) {
+	var resp struct {
+		ValSigningInfo struct {
+			MissedBlocksCounter string `json:"missed_blocks_counter"`
+		} `json:"val_signing_info"`
+	}
+	if err := d.getJSON(d.lcdURL+"/cosmos/slashing/v1beta1/signing_infos/"+consensusAddress, &resp); err != nil {
+		return 0, fmt.Errorf("failed to query signing info: %w", err)
+	}
+	missed, _ := strconv.ParseInt(strings.TrimSpace(resp.ValSigningInfo.MissedBlocksCounter), 10, 64)
+	return missed, nil
+}
+
+// MissedBlocks re-reads our missed block counter and updates the cache.
+// It needs a previous discovery that found the validator's signing info.
+func (d *Discoverer) MissedBlocks() (int64, error) {
+	d.mu.RLock()
+	var cons string
+	if d.info != nil {
+		cons = d.info.ConsensusAddress
+	}
+	d.mu.RUnlock()
+	if cons == "" || d.lcdURL == "" {
+		return 0, fmt.Errorf("signing info unavailable")
+	}
+
+	missed, err := d.queryMissedBlocks(cons)
+	if err != nil {
+		return 0, err
+	}
+	d.mu.Lock()
+	if d.info != nil {
+		d.info.MissedBlocks = missed
+	}
+	d.mu.Unlock()
+	return missed, nil
+}
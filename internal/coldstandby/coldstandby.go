@@ -0,0 +1,245 @@
+// Package coldstandby ships the validator state and key to a standby that
+// runs no SyncGuard, and promotes it on operator request. The standby is
+// reached over SSH or through an upload command, e.g. into object storage
+// it fetches from.
+package coldstandby
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/metrics"
+	"github.com/aldebaranode/syncguard/internal/state"
+)
+
+// Names of the shipped files
+const (
+	KeyFile      = "priv_validator_key.json"
+	StateFile    = "priv_validator_state.json"
+	ManifestFile = "manifest.json"
+)
+
+// maxOutput bounds the command output kept in errors
+const maxOutput = 1024
+
+var (
+	shippedHeightGauge = metrics.NewGauge(
+		"syncguard_cold_standby_shipped_height",
+		"Height of the validator state last shipped to the cold standby",
+	)
+	shipFailureCounter = metrics.NewCounter(
+		"syncguard_cold_standby_ship_failures_total",
+		"Failed attempts to ship state or key to the cold standby",
+	)
+)
+
+// Target is where state and key are shipped
+type Target interface {
+	// Put stores data under name, replacing what was shipped before
+	Put(name string, data []byte) error
+	// Promote installs the shipped files on the standby and starts it
+	Promote() error
+}
+
+// NewTarget creates the target for the configured transport
+func NewTarget(cfg config.ColdStandbyConfig) (Target, error) {
+	switch cfg.Transport {
+	case "ssh":
+		return &sshTarget{cfg: cfg}, nil
+	case "command":
+		return &commandTarget{cfg: cfg}, nil
+	default:
+		return nil, fmt.Errorf("unknown cold standby transport %q", cfg.Transport)
+	}
+}
+
+// Manifest describes the last shipment, so the standby side can tell how
+// fresh its copy is
+type Manifest struct {
+	NodeID     string    `json:"node_id"`
+	Height     int64     `json:"height"`
+	Round      int32     `json:"round"`
+	Step       int8      `json:"step"`
+	KeyAddress string    `json:"key_address"`
+	ShippedAt  time.Time `json:"shipped_at"`
+}
+
+// Status reports the shipping progress
+type Status struct {
+	Transport   string    `json:"transport"`
+	LastShipped time.Time `json:"last_shipped,omitempty"`
+	Height      int64     `json:"height"`
+	Failures    int       `json:"consecutive_failures"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// Shipper copies the state and key files to a target when they change
+type Shipper struct {
+	target    Target
+	transport string
+	nodeID    string
+	states    *state.Manager
+	keys      *state.KeyManager
+
+	mu      sync.Mutex
+	shipped map[string][32]byte
+	status  Status
+}
+
+// NewShipper creates a shipper for the node's state and key
+func NewShipper(cfg *config.Config, target Target, keys *state.KeyManager) *Shipper {
+	return &Shipper{
+		target:    target,
+		transport: cfg.ColdStandby.Transport,
+		nodeID:    cfg.Node.ID,
+		states:    state.NewManager(cfg.CometBFT.StatePath, ""),
+		keys:      keys,
+		shipped:   make(map[string][32]byte),
+		status:    Status{Transport: cfg.ColdStandby.Transport},
+	}
+}
+
+// Ship sends the state and key if they changed since the last shipment,
+// then the manifest. The key goes first: a standby must never hold a state
+// newer than the key it would sign with.
+func (s *Shipper) Ship() error {
+	err := s.ship()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		shipFailureCounter.Inc()
+		s.status.Failures++
+		s.status.Error = err.Error()
+		return err
+	}
+	s.status.Failures = 0
+	s.status.Error = ""
+	return nil
+}
+
+func (s *Shipper) ship() error {
+	key, err := s.keys.LoadRealKey()
+	if err != nil {
+		return fmt.Errorf("failed to load key: %w", err)
+	}
+	if state.IsMockKey(key) {
+		return fmt.Errorf("no real key on disk to ship")
+	}
+	keyData, err := json.MarshalIndent(key, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal key: %w", err)
+	}
+
+	validatorState, err := s.states.LoadState()
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+	stateData, err := json.MarshalIndent(validatorState, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %w", err)
+	}
+
+	keyChanged, err := s.put(KeyFile, keyData)
+	if err != nil {
+		return err
+	}
+	stateChanged, err := s.put(StateFile, stateData)
+	if err != nil {
+		return err
+	}
+	if !keyChanged && !stateChanged {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	manifest, _ := json.MarshalIndent(Manifest{
+		NodeID:     s.nodeID,
+		Height:     validatorState.Height,
+		Round:      validatorState.Round,
+		Step:       validatorState.Step,
+		KeyAddress: key.Address,
+		ShippedAt:  now,
+	}, "", "  ")
+	if err := s.target.Put(ManifestFile, manifest); err != nil {
+		return fmt.Errorf("failed to ship %s: %w", ManifestFile, err)
+	}
+
+	shippedHeightGauge.Set(float64(validatorState.Height))
+	s.mu.Lock()
+	s.status.LastShipped = now
+	s.status.Height = validatorState.Height
+	s.mu.Unlock()
+	return nil
+}
+
+// put ships a file unless the same content was shipped last time, and
+// reports whether it shipped
+func (s *Shipper) put(name string, data []byte) (bool, error) {
+	sum := sha256.Sum256(data)
+	s.mu.Lock()
+	unchanged := s.shipped[name] == sum
+	s.mu.Unlock()
+	if unchanged {
+		return false, nil
+	}
+
+	if err := s.target.Put(name, data); err != nil {
+		return false, fmt.Errorf("failed to ship %s: %w", name, err)
+	}
+	s.mu.Lock()
+	s.shipped[name] = sum
+	s.mu.Unlock()
+	return true, nil
+}
+
+// Status reports the last shipment and any failure since
+func (s *Shipper) Status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status
+}
+
+// run runs a command with data on stdin, killing it after timeout. The
+// error carries the tail of the command's output.
+func run(timeout time.Duration, stdin []byte, name string, args ...string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdin = bytes.NewReader(stdin)
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	cmd.WaitDelay = time.Second
+
+	err := cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%s timed out after %s", name, timeout)
+	}
+	if err != nil {
+		output := strings.TrimSpace(out.String())
+		if len(output) > maxOutput {
+			output = output[len(output)-maxOutput:]
+		}
+		if output == "" {
+			return err
+		}
+		return fmt.Errorf("%w: %s", err, output)
+	}
+	return nil
+}
+
+// shellQuote quotes s for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,105 @@
+package coldstandby_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aldebaranode/syncguard/internal/coldstandby"
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/state"
+)
+
+func TestShipper_CommandTransport(t *testing.T) {
+	dir := t.TempDir()
+	standby := filepath.Join(dir, "standby")
+	if err := os.Mkdir(standby, 0700); err != nil {
+		t.Fatal(err)
+	}
+	keyPath := filepath.Join(dir, "priv_validator_key.json")
+	statePath := filepath.Join(dir, "priv_validator_state.json")
+	os.WriteFile(keyPath, []byte(`{"address":"ABCD","pub_key":{},"priv_key":{}}`), 0600)
+	os.WriteFile(statePath, []byte(`{"height":"100","round":0,"step":3}`), 0600)
+
+	cfg := &config.Config{
+		Node:     config.NodeConfig{ID: "validator-1"},
+		CometBFT: config.CometBFTConfig{KeyPath: keyPath, StatePath: statePath},
+		ColdStandby: config.ColdStandbyConfig{
+			Transport:       "command",
+			Timeout:         10,
+			UploadCommand:   "cp {file} " + standby + "/{name} && echo {name} >> " + filepath.Join(dir, "uploads"),
+			ActivateCommand: "touch " + filepath.Join(dir, "activated"),
+		},
+	}
+	target, err := coldstandby.NewTarget(cfg.ColdStandby)
+	if err != nil {
+		t.Fatalf("NewTarget failed: %v", err)
+	}
+	shipper := coldstandby.NewShipper(cfg, target, state.NewKeyManager(keyPath, "", nil))
+
+	if err := shipper.Ship(); err != nil {
+		t.Fatalf("Ship failed: %v", err)
+	}
+	var manifest coldstandby.Manifest
+	data, _ := os.ReadFile(filepath.Join(standby, coldstandby.ManifestFile))
+	if err := json.Unmarshal(data, &manifest); err != nil || manifest.Height != 100 || manifest.KeyAddress != "ABCD" {
+		t.Errorf("manifest = %+v, %v", manifest, err)
+	}
+
+	// Nothing changed: nothing is uploaded again
+	if err := shipper.Ship(); err != nil {
+		t.Fatalf("Ship failed: %v", err)
+	}
+	uploads, _ := os.ReadFile(filepath.Join(dir, "uploads"))
+	if got := string(uploads); got != "priv_validator_key.json\npriv_validator_state.json\nmanifest.json\n" {
+		t.Errorf("uploads = %q", got)
+	}
+
+	os.WriteFile(statePath, []byte(`{"height":"101","round":0,"step":1}`), 0600)
+	if err := shipper.Ship(); err != nil {
+		t.Fatalf("Ship failed: %v", err)
+	}
+	var shipped state.ValidatorState
+	data, _ = os.ReadFile(filepath.Join(standby, coldstandby.StateFile))
+	if err := json.Unmarshal(data, &shipped); err != nil || shipped.Height != 101 {
+		t.Errorf("shipped state = %+v, %v", shipped, err)
+	}
+	if status := shipper.Status(); status.Height != 101 || status.Failures != 0 {
+		t.Errorf("status = %+v", status)
+	}
+
+	if err := target.Promote(); err != nil {
+		t.Fatalf("Promote failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "activated")); err != nil {
+		t.Error("activate_command did not run")
+	}
+}
+
+func TestShipper_ReportsFailures(t *testing.T) {
+	dir := t.TempDir()
+	keyPath := filepath.Join(dir, "priv_validator_key.json")
+	statePath := filepath.Join(dir, "priv_validator_state.json")
+	os.WriteFile(keyPath, []byte(`{"address":"ABCD","pub_key":{},"priv_key":{}}`), 0600)
+	os.WriteFile(statePath, []byte(`{"height":"100","round":0,"step":3}`), 0600)
+
+	cfg := &config.Config{
+		CometBFT: config.CometBFTConfig{KeyPath: keyPath, StatePath: statePath},
+		ColdStandby: config.ColdStandbyConfig{
+			Transport:     "command",
+			Timeout:       10,
+			UploadCommand: "echo bucket unreachable >&2; exit 1 # {file}",
+		},
+	}
+	target, _ := coldstandby.NewTarget(cfg.ColdStandby)
+	shipper := coldstandby.NewShipper(cfg, target, state.NewKeyManager(keyPath, "", nil))
+
+	if err := shipper.Ship(); err == nil {
+		t.Fatal("expected the upload to fail")
+	}
+	shipper.Ship()
+	if status := shipper.Status(); status.Failures != 2 || status.Error == "" {
+		t.Errorf("status = %+v", status)
+	}
+}
@@ -0,0 +1,41 @@
+package coldstandby
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+)
+
+// commandTarget ships files with upload_command, e.g. into object storage
+// the standby fetches from
+type commandTarget struct {
+	cfg config.ColdStandbyConfig
+}
+
+// Put writes data to a private temporary file and uploads it
+func (t *commandTarget) Put(name string, data []byte) error {
+	f, err := os.CreateTemp("", "syncguard-standby-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+
+	command := strings.NewReplacer("{file}", shellQuote(f.Name()), "{name}", shellQuote(name)).Replace(t.cfg.UploadCommand)
+	return run(t.cfg.Timeout.Duration(), nil, "sh", "-c", command)
+}
+
+// Promote runs activate_command locally; it installs the shipped files
+// on the standby and starts it
+func (t *commandTarget) Promote() error {
+	return run(t.cfg.Timeout.Duration(), nil, "sh", "-c", t.cfg.ActivateCommand)
+}
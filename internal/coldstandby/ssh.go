@@ -0,0 +1,55 @@
+package coldstandby
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+)
+
+// sshTarget ships files into remote_dir on the standby with the ssh client
+type sshTarget struct {
+	cfg config.ColdStandbyConfig
+}
+
+// Put writes to a temporary file and renames it, so the standby never
+// sees a partial file
+func (t *sshTarget) Put(name string, data []byte) error {
+	dest := path.Join(t.cfg.RemoteDir, name)
+	tmp := path.Join(t.cfg.RemoteDir, "."+name+".tmp")
+	script := fmt.Sprintf("umask 077 && mkdir -p %s && cat > %s && mv %s %s",
+		shellQuote(t.cfg.RemoteDir), shellQuote(tmp), shellQuote(tmp), shellQuote(dest))
+	return t.run(script, data)
+}
+
+// Promote copies the shipped key and state over the standby's own and
+// runs activate_command there
+func (t *sshTarget) Promote() error {
+	return t.run(t.promoteScript(), nil)
+}
+
+// promoteScript installs the key before the state, each with cp so the
+// existing files keep their owner
+func (t *sshTarget) promoteScript() string {
+	key := path.Join(t.cfg.RemoteDir, KeyFile)
+	st := path.Join(t.cfg.RemoteDir, StateFile)
+	return strings.Join([]string{
+		"set -e",
+		fmt.Sprintf("cp %s %s", shellQuote(key), shellQuote(t.cfg.RemoteKeyPath)),
+		fmt.Sprintf("chmod 600 %s", shellQuote(t.cfg.RemoteKeyPath)),
+		fmt.Sprintf("cp %s %s", shellQuote(st), shellQuote(t.cfg.RemoteStatePath)),
+		t.cfg.ActivateCommand,
+	}, "\n")
+}
+
+// run runs a shell script on the standby
+func (t *sshTarget) run(script string, stdin []byte) error {
+	args := []string{"-o", "BatchMode=yes", "-p", strconv.Itoa(t.cfg.Port)}
+	if t.cfg.IdentityFile != "" {
+		args = append(args, "-i", t.cfg.IdentityFile)
+	}
+	args = append(args, t.cfg.Host, "sh -c "+shellQuote(script))
+	return run(t.cfg.Timeout.Duration(), stdin, "ssh", args...)
+}
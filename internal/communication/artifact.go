@@ -0,0 +1,67 @@
+package communication
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// PathArtifacts is where a peer serves and accepts the files handed over
+// with the validator key, at PathArtifacts + name. The name is part of the
+// signed path.
+const PathArtifacts = "/artifacts/"
+
+// ArtifactTransfer carries one artifact. Encrypted data is sealed with the
+// cluster secret.
+type ArtifactTransfer struct {
+	Name      string `json:"name"`
+	Encrypted bool   `json:"encrypted,omitempty"`
+	Data      []byte `json:"data"`
+}
+
+// FetchArtifact requests the artifact called name from a peer
+func (c *Client) FetchArtifact(addr, name string) (*ArtifactTransfer, error) {
+	body, err := c.do(http.MethodGet, addr, PathArtifacts+name, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch artifact %s: %w", name, err)
+	}
+	artifact, err := DecodeArtifact(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse artifact %s: %w", name, err)
+	}
+	if artifact.Name != name {
+		return nil, fmt.Errorf("asked for artifact %s, peer sent %s", name, artifact.Name)
+	}
+	return &artifact, nil
+}
+
+// SendArtifact sends an artifact to a peer
+func (c *Client) SendArtifact(addr string, artifact ArtifactTransfer) error {
+	body, err := json.Marshal(artifact)
+	if err != nil {
+		return fmt.Errorf("failed to marshal artifact: %w", err)
+	}
+	if _, err := c.do(http.MethodPost, addr, PathArtifacts+artifact.Name, body); err != nil {
+		return fmt.Errorf("failed to send artifact %s: %w", artifact.Name, err)
+	}
+	return nil
+}
+
+// DecodeArtifact decodes an artifact transfer and checks its values
+func DecodeArtifact(data []byte) (ArtifactTransfer, error) {
+	var artifact ArtifactTransfer
+	if err := decodeJSON(data, &artifact); err != nil {
+		return ArtifactTransfer{}, err
+	}
+	if artifact.Name == "" {
+		return ArtifactTransfer{}, invalid("name is required")
+	}
+	if err := checkID("name", artifact.Name); err != nil {
+		return ArtifactTransfer{}, err
+	}
+	if strings.ContainsAny(artifact.Name, "/?#") {
+		return ArtifactTransfer{}, invalid("name %q is not a plain name", artifact.Name)
+	}
+	return artifact, nil
+}
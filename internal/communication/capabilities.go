@@ -0,0 +1,108 @@
+package communication
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/peerproto"
+)
+
+// Features a peer can announce in its handshake. Transition code checks
+// them before relying on an endpoint an older peer may lack.
+const (
+	FeatureProtobuf    = "protobuf"
+	FeatureCompression = "compression"
+	FeatureHeartbeat   = "heartbeat"
+	FeatureElection    = "election"
+	FeatureArtifacts   = "artifacts"
+	FeatureLinkProbe   = "link_probe"
+	FeatureDrain       = "drain"
+	FeatureKeyHandoff  = "key_handoff"
+)
+
+// Features lists what this build supports, announced to every peer
+var Features = []string{
+	FeatureProtobuf, FeatureCompression, FeatureHeartbeat, FeatureElection,
+	FeatureArtifacts, FeatureLinkProbe, FeatureDrain, FeatureKeyHandoff,
+}
+
+// BootNonce is drawn once per process. Peers see it change when this
+// node restarts, possibly as another build, and renegotiate.
+var BootNonce = newBootNonce()
+
+func newBootNonce() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return time.Now().UTC().Format(time.RFC3339Nano)
+	}
+	return hex.EncodeToString(b)
+}
+
+// Capabilities is what a peer announced in its last handshake, reduced
+// to the features both sides support
+type Capabilities struct {
+	Protocol     int       `json:"protocol"`
+	Features     []string  `json:"features"`
+	BootNonce    string    `json:"boot_nonce"`
+	NegotiatedAt time.Time `json:"negotiated_at"`
+}
+
+// Has reports whether the feature was negotiated
+func (c *Capabilities) Has(feature string) bool {
+	for _, f := range c.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// negotiate keeps the features of a handshake answer this node supports
+// too; nil for peers that predate capabilities
+func negotiate(resp *HandshakeResponse) *Capabilities {
+	if resp.Protocol == 0 {
+		return nil
+	}
+	caps := &Capabilities{Protocol: resp.Protocol, BootNonce: resp.BootNonce, NegotiatedAt: time.Now().UTC()}
+	if caps.Protocol > peerproto.Version {
+		caps.Protocol = peerproto.Version
+	}
+	for _, f := range resp.Features {
+		for _, ours := range Features {
+			if f == ours {
+				caps.Features = append(caps.Features, f)
+				break
+			}
+		}
+	}
+	return caps
+}
+
+// Capabilities returns the peer's negotiated capabilities while they are
+// younger than peer_api.capability_ttl and the peer has not restarted
+// since; nil otherwise
+func (c *Client) Capabilities(addr string) *Capabilities {
+	return c.peers.capabilities(addr, c.cfg.PeerAPI.CapabilityTTL.Duration())
+}
+
+// Supports reports whether a peer can be relied on for feature. Peers
+// whose capabilities are unknown or expired are assumed to support it,
+// as before capabilities were negotiated; the call then fails as it
+// would have. It never calls the peer.
+func (c *Client) Supports(addr, feature string) bool {
+	caps := c.Capabilities(addr)
+	return caps == nil || caps.Has(feature)
+}
+
+// Negotiate returns the peer's capabilities, handshaking only when none
+// are cached
+func (c *Client) Negotiate(addr string) (*Capabilities, error) {
+	if caps := c.Capabilities(addr); caps != nil {
+		return caps, nil
+	}
+	if _, err := c.Handshake(addr); err != nil {
+		return nil, err
+	}
+	return c.Capabilities(addr), nil
+}
@@ -0,0 +1,95 @@
+package communication
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+)
+
+func TestClient_NegotiatesCapabilities(t *testing.T) {
+	var handshakes int32
+	nonce := "first-boot"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case PathHandshake:
+			atomic.AddInt32(&handshakes, 1)
+			json.NewEncoder(w).Encode(HandshakeResponse{
+				NodeID:      "b",
+				ReachedBack: true,
+				Protocol:    1,
+				Features:    []string{FeatureHeartbeat, FeatureArtifacts, "teleport"},
+				BootNonce:   nonce,
+			})
+		case PathHealth:
+			json.NewEncoder(w).Encode(PeerHealth{Healthy: true, BootNonce: nonce})
+		}
+	}))
+	defer srv.Close()
+
+	cfg := &config.Config{
+		Node:    config.NodeConfig{ID: "a", Port: 8080},
+		Peers:   []config.PeerConfig{{ID: "b", Address: srv.URL}},
+		PeerAPI: config.PeerAPIConfig{CapabilityTTL: 60},
+		Logging: config.LoggingConfig{Level: "error", File: "/dev/null"},
+	}
+	client := NewClient(cfg, nil)
+
+	// Unknown capabilities do not stop anything
+	if !client.Supports(srv.URL, FeatureLinkProbe) {
+		t.Error("a peer with unknown capabilities should be assumed to support link probes")
+	}
+
+	caps, err := client.Negotiate(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(caps.Features) != 2 || !caps.Has(FeatureArtifacts) || caps.Has("teleport") {
+		t.Errorf("negotiated %v, want heartbeat and artifacts", caps.Features)
+	}
+	if client.Supports(srv.URL, FeatureLinkProbe) {
+		t.Error("a peer that did not announce link probes should not support them")
+	}
+	if _, err := client.Negotiate(srv.URL); err != nil || atomic.LoadInt32(&handshakes) != 1 {
+		t.Errorf("cached capabilities renegotiated: %d handshakes, %v", handshakes, err)
+	}
+	if status := client.PeerStatuses()[0]; status.Capabilities == nil {
+		t.Error("capabilities missing from the peer status")
+	}
+
+	// The peer restarts: its next health answer drops the cache
+	nonce = "second-boot"
+	if _, err := client.FetchHealth(srv.URL); err != nil {
+		t.Fatal(err)
+	}
+	if client.Capabilities(srv.URL) != nil {
+		t.Fatal("capabilities kept across a peer restart")
+	}
+	if caps, err := client.Negotiate(srv.URL); err != nil || caps.BootNonce != "second-boot" {
+		t.Errorf("renegotiated %+v, %v", caps, err)
+	}
+}
+
+func TestClient_CapabilitiesOfOlderPeer(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(HandshakeResponse{NodeID: "b", ReachedBack: true})
+	}))
+	defer srv.Close()
+
+	cfg := &config.Config{
+		Node:    config.NodeConfig{ID: "a", Port: 8080},
+		Peers:   []config.PeerConfig{{ID: "b", Address: srv.URL}},
+		PeerAPI: config.PeerAPIConfig{CapabilityTTL: 60},
+		Logging: config.LoggingConfig{Level: "error", File: "/dev/null"},
+	}
+	client := NewClient(cfg, nil)
+	if caps, err := client.Negotiate(srv.URL); err != nil || caps != nil {
+		t.Fatalf("older peer: got %+v, %v", caps, err)
+	}
+	if !client.Supports(srv.URL, FeatureArtifacts) {
+		t.Error("an older peer should be assumed to support artifacts")
+	}
+}
@@ -0,0 +1,467 @@
+package communication
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/crypto"
+	"github.com/aldebaranode/syncguard/internal/health"
+	"github.com/aldebaranode/syncguard/internal/logger"
+	"github.com/aldebaranode/syncguard/internal/peerproto"
+	"github.com/aldebaranode/syncguard/internal/state"
+)
+
+// Peer API paths
+const (
+	PathValidatorState = "/validator_state"
+	PathValidatorKey   = "/validator_key"
+	PathFailoverNotify = "/failover_notify"
+	PathFailbackNotify = "/failback_notify"
+	PathHealth         = "/health"
+	PathNodeStatus     = "/node_status"
+	PathEnroll         = "/enroll"
+)
+
+// HeaderReason carries the reason code of a failover or failback notification
+const HeaderReason = "X-SyncGuard-Reason"
+
+// HeaderTransition carries the correlation ID of the transition a request
+// belongs to, so both nodes log it
+const HeaderTransition = "X-SyncGuard-Transition"
+
+// defaultTimeout bounds a peer request whose peer_api.timeouts entry is
+// unset, as in configs that skipped defaults
+const defaultTimeout = 10 * time.Second
+
+// EnrollRequest registers a node's identity key with a peer.
+// It is authenticated with the shared cluster secret since the peer
+// does not know the key yet.
+type EnrollRequest struct {
+	NodeID    string `json:"node_id"`
+	PublicKey string `json:"public_key"`
+	Timestamp int64  `json:"timestamp"`
+	Signature string `json:"signature"`
+	// Address is the enrolling node's advertise_address, if it has one.
+	// It is not covered by the signature and only checked against the
+	// address configured for the node.
+	Address string `json:"address,omitempty"`
+}
+
+// EnrollPayload is the string covered by the enrollment HMAC
+func EnrollPayload(nodeID, publicKey string) string {
+	return nodeID + ":" + publicKey
+}
+
+// Client sends requests to peer SyncGuard nodes.
+// Every request is signed: with the identity when one is configured,
+// otherwise with an HMAC under the cluster secret.
+type Client struct {
+	cfg        *config.Config
+	identity   *crypto.Identity
+	httpClient *http.Client
+	peers      *peerTracker
+	peerIDs    map[string]string
+	secure     bool
+	stamps     *requestStamps
+	logger     *logger.Logger
+}
+
+// NewClient creates a peer client; identity may be nil
+func NewClient(cfg *config.Config, identity *crypto.Identity) *Client {
+	newLogger := logger.New(cfg, "communication")
+
+	peerIDs := make(map[string]string, len(cfg.Peers))
+	for _, peer := range cfg.Peers {
+		peerIDs[peer.Address] = peer.ID
+	}
+
+	var transport http.RoundTripper = http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.PeerAPI.Protocol == ProtocolGRPC {
+		transport = newGRPCTransport(cfg)
+	}
+
+	return &Client{
+		cfg:        cfg,
+		identity:   identity,
+		httpClient: &http.Client{Transport: transport},
+		peers:      newPeerTracker(peerIDs),
+		peerIDs:    peerIDs,
+		stamps:     &requestStamps{last: make(map[string]int64)},
+		logger:     newLogger,
+	}
+}
+
+// do sends a request to a peer and returns the response body
+func (c *Client) do(method, addr, path string, body []byte) ([]byte, error) {
+	return c.doWithHeaders(method, addr, path, body, nil)
+}
+
+// doWithHeaders sends a request with extra headers
+func (c *Client) doWithHeaders(method, addr, path string, body []byte, headers map[string]string) ([]byte, error) {
+	respBody, _, err := c.send(method, addr, path, body, headers)
+	c.peers.record(addr, err)
+	return respBody, err
+}
+
+// exchange sends a peer message in the configured encoding and reports
+// whether the answer is protobuf: a peer answers JSON unless asked for
+// protobuf, and older peers always do
+func (c *Client) exchange(method, addr, path string, body []byte, headers map[string]string) ([]byte, bool, error) {
+	respBody, respHeader, err := c.exchangeHeader(method, addr, path, body, headers)
+	return respBody, IsProto(respHeader.Get("Content-Type")), err
+}
+
+// exchangeHeader is exchange returning the response headers
+func (c *Client) exchangeHeader(method, addr, path string, body []byte, headers map[string]string) ([]byte, http.Header, error) {
+	if c.protobuf() {
+		merged := map[string]string{"Accept": peerproto.ContentType}
+		if len(body) > 0 {
+			merged["Content-Type"] = peerproto.ContentType
+		}
+		for k, v := range headers {
+			merged[k] = v
+		}
+		headers = merged
+	}
+	respBody, respHeader, err := c.send(method, addr, path, body, headers)
+	c.peers.record(addr, err)
+	return respBody, respHeader, err
+}
+
+// protobuf reports whether peer messages are sent as protobuf envelopes
+func (c *Client) protobuf() bool {
+	return c.cfg.PeerAPI.Encoding == EncodingProtobuf
+}
+
+// send performs a single request. With peer_api.compression, bodies of at
+// least compress_min_bytes are gzipped and gzip responses are accepted;
+// the signature always covers the uncompressed body. It returns the
+// response body and headers.
+func (c *Client) send(method, addr, path string, body []byte, headers map[string]string) ([]byte, http.Header, error) {
+	respBody, respHeader, err := c.sendSigned(method, addr, path, body, headers, c.cfg.Secret)
+	// A peer not rotated yet only knows the previous cluster secret
+	if c.identity == nil && c.cfg.PreviousSecret != "" && StatusCode(err) == http.StatusUnauthorized {
+		c.logger.Debug("Peer %s rejected the current cluster secret, signing with the previous one", addr)
+		return c.sendSigned(method, addr, path, body, headers, c.cfg.PreviousSecret)
+	}
+	return respBody, respHeader, err
+}
+
+// sendSigned is send with the HMAC under secret when there is no identity
+func (c *Client) sendSigned(method, addr, path string, body []byte, headers map[string]string, secret string) ([]byte, http.Header, error) {
+	compress := c.cfg.PeerAPI.Compression
+	wireBody, encoding := body, ""
+	if compress && len(body) > 0 && len(body) >= int(c.cfg.PeerAPI.CompressMinBytes) {
+		compressed, err := Compress(body)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to compress request: %w", err)
+		}
+		wireBody, encoding = compressed, EncodingGzip
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout(path))
+	defer cancel()
+	ctx = context.WithValue(ctx, peerIDKey{}, c.peerIDs[addr])
+	req, err := http.NewRequestWithContext(ctx, method, peerURL(addr, path, c.secure), bytes.NewReader(wireBody))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if len(body) > 0 {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if encoding != "" {
+		req.Header.Set("Content-Encoding", encoding)
+	}
+	if compress {
+		// Set explicitly so the transport leaves decoding, and counting, to us
+		req.Header.Set("Accept-Encoding", EncodingGzip)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	if id := logger.TransitionID(); id != "" {
+		req.Header.Set(HeaderTransition, id)
+	}
+	c.sign(req.Header, method, path, body, secret)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	peer := c.peerLabel(addr)
+	recordTraffic(peer, "sent", len(wireBody), len(body))
+	respBody, wireLen, err := ReadBody(resp.Body, resp.Header.Get("Content-Encoding"), int64(c.cfg.PeerAPI.MaxResponseBytes))
+	recordTraffic(peer, "received", int(wireLen), len(respBody))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, &statusError{code: resp.StatusCode}
+	}
+
+	return respBody, resp.Header, nil
+}
+
+// requestStamps hands out request timestamps. Signatures only change by
+// the second, and peers refuse a write whose signature they accepted
+// before as a replay, so the same write sent again within a second is
+// stamped a second later; peers accept that within identity.max_skew.
+type requestStamps struct {
+	mu   sync.Mutex
+	last map[string]int64
+}
+
+// next returns the timestamp to sign a request with
+func (s *requestStamps) next(method, path string, body []byte) int64 {
+	now := time.Now().Unix()
+	if method == http.MethodGet || method == http.MethodHead {
+		return now
+	}
+	digest := sha256.Sum256(body)
+	key := method + " " + path + " " + hex.EncodeToString(digest[:])
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, last := range s.last {
+		if last < now {
+			delete(s.last, k)
+		}
+	}
+	stamp := now
+	if last, ok := s.last[key]; ok {
+		stamp = last + 1
+	}
+	s.last[key] = stamp
+	return stamp
+}
+
+// sign sets the headers authenticating a request: the identity's
+// signature, or an HMAC under secret without one
+func (c *Client) sign(header http.Header, method, path string, body []byte, secret string) {
+	timestamp := c.stamps.next(method, path, body)
+	signature := crypto.SignRequestHMAC(secret, c.cfg.Node.ID, method, path, body, timestamp)
+	if c.identity != nil {
+		signature = c.identity.SignRequest(method, path, body, timestamp)
+	}
+	for k, v := range signature {
+		header.Set(k, v)
+	}
+}
+
+// timeout returns how long a request to path may take, from dialing to
+// reading the answer, per peer_api.timeouts
+func (c *Client) timeout(path string) time.Duration {
+	t := c.cfg.PeerAPI.Timeouts
+	var timeout config.Seconds
+	if strings.HasPrefix(path, PathArtifacts) || strings.HasPrefix(path, PathKeyHandoff) {
+		path = PathValidatorKey
+	}
+	switch path {
+	case PathHeartbeat, PathElection:
+		timeout = t.Heartbeat
+	case PathFailoverNotify, PathFailbackNotify:
+		timeout = t.Notify
+	case PathValidatorState:
+		timeout = t.State
+	case PathValidatorKey, PathLinkProbe:
+		timeout = t.Key
+	default:
+		timeout = t.Probe
+	}
+	if timeout <= 0 {
+		return defaultTimeout
+	}
+	return timeout.Duration()
+}
+
+// peerLabel names a peer address in metrics by its configured ID
+func (c *Client) peerLabel(addr string) string {
+	if id, ok := c.peerIDs[addr]; ok {
+		return id
+	}
+	return addr
+}
+
+// FetchState retrieves the peer's validator state and its provenance link,
+// which is nil from peers that predate provenance. With fresh set the peer
+// bypasses its response cache, which transitions require.
+func (c *Client) FetchState(addr string, fresh bool) (*state.ValidatorState, *state.Link, error) {
+	var headers map[string]string
+	if fresh {
+		headers = map[string]string{"Cache-Control": "no-cache"}
+	}
+
+	body, respHeader, err := c.exchangeHeader(http.MethodGet, addr, PathValidatorState, nil, headers)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch state from peer: %w", err)
+	}
+
+	remoteState, err := DecodeState(IsProto(respHeader.Get("Content-Type")), body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse remote state: %w", err)
+	}
+	link, err := ReadLink(respHeader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid state provenance: %w", err)
+	}
+	return remoteState, link, nil
+}
+
+// FetchKey retrieves the validator key from the peer
+func (c *Client) FetchKey(addr string) ([]byte, error) {
+	body, asProto, err := c.exchange(http.MethodGet, addr, PathValidatorKey, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request key from peer: %w", err)
+	}
+	keyData, err := DecodeKey(asProto, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key transfer: %w", err)
+	}
+	return keyData, nil
+}
+
+// SendKey posts the validator key to the peer
+func (c *Client) SendKey(addr string, keyData []byte) error {
+	body := EncodeKey(c.protobuf(), keyData)
+	if _, _, err := c.exchange(http.MethodPost, addr, PathValidatorKey, body, nil); err != nil {
+		return fmt.Errorf("failed to send key: %w", err)
+	}
+	return nil
+}
+
+// Notify posts a failover or failback notification to the peer, with the
+// reason code of the transition
+func (c *Client) Notify(addr, path, reason string) error {
+	headers := map[string]string{HeaderReason: reason}
+	kind := "failover"
+	if path == PathFailbackNotify {
+		kind = "failback"
+	}
+	body := EncodeTransition(c.protobuf(), kind, reason)
+	if _, _, err := c.exchange(http.MethodPost, addr, path, body, headers); err != nil {
+		return fmt.Errorf("failed to notify peer: %w", err)
+	}
+	return nil
+}
+
+// Probe checks that a peer answers on its health endpoint
+func (c *Client) Probe(addr string) error {
+	_, err := c.do(http.MethodGet, addr, PathHealth, nil)
+	return err
+}
+
+// PeerHealth is the part of a peer's /health response used for decisions
+type PeerHealth struct {
+	Healthy bool  `json:"healthy"`
+	Active  bool  `json:"active"`
+	Primary bool  `json:"primary"`
+	Height  int64 `json:"height"`
+	// Draining is set while an operator has drained the peer
+	Draining bool `json:"draining,omitempty"`
+	// Readiness is a passive peer's readiness to take over
+	Readiness *health.Readiness `json:"readiness,omitempty"`
+	// BootNonce changes whenever the peer restarts
+	BootNonce string `json:"boot_nonce,omitempty"`
+	// Custody is what the peer holds of the signing rights; nil from
+	// peers that predate it
+	Custody *KeyCustody `json:"custody,omitempty"`
+	// Transitions sums up the peer's changes of role; nil from peers that
+	// predate it
+	Transitions *health.Transitions `json:"transition_summary,omitempty"`
+}
+
+// KeyCustody is what a node holds of the validator's signing rights, as
+// the cluster invariants check them
+type KeyCustody struct {
+	// KeyPresent is set while the real key, not the mock key, is in place
+	KeyPresent bool `json:"key_present"`
+	// LockHeld is set while the node holds the state lock
+	LockHeld bool `json:"lock_held"`
+	// StateHeight is the height of the node's validator state file
+	StateHeight int64 `json:"state_height"`
+}
+
+// FetchHealth retrieves the peer's health and role
+func (c *Client) FetchHealth(addr string) (*PeerHealth, error) {
+	body, err := c.do(http.MethodGet, addr, PathHealth, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch health from peer: %w", err)
+	}
+
+	var health PeerHealth
+	if err := decodeJSON(body, &health); err != nil {
+		return nil, fmt.Errorf("failed to parse peer health: %w", err)
+	}
+	c.peers.observeBootNonce(addr, health.BootNonce)
+	return &health, nil
+}
+
+// FetchNodeStatus reads the CometBFT status a peer's node reports, through
+// the peer API rather than the node's RPC
+func (c *Client) FetchNodeStatus(addr string) (*health.NodeStatus, error) {
+	body, err := c.do(http.MethodGet, addr, PathNodeStatus, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch node status from peer: %w", err)
+	}
+
+	var status health.NodeStatus
+	if err := decodeJSON(body, &status); err != nil {
+		return nil, fmt.Errorf("failed to parse peer node status: %w", err)
+	}
+	return &status, nil
+}
+
+// PeerStatuses reports reachability of every peer contacted or configured
+func (c *Client) PeerStatuses() []PeerStatus {
+	return c.peers.snapshot()
+}
+
+// Enroll registers this node's identity key with the peer. While the
+// cluster secret is rotated a peer that still has the previous secret
+// rejects the current one, so enrollment is retried with previous_secret.
+func (c *Client) Enroll(addr string) error {
+	if c.identity == nil {
+		return fmt.Errorf("no identity configured")
+	}
+
+	err := c.enroll(addr, c.cfg.Secret)
+	if StatusCode(err) == http.StatusUnauthorized && c.cfg.PreviousSecret != "" {
+		c.logger.Warn("Peer %s rejected the current cluster secret, enrolling with the previous one", addr)
+		err = c.enroll(addr, c.cfg.PreviousSecret)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to enroll with peer: %w", err)
+	}
+	return nil
+}
+
+// enroll sends an enrollment authenticated with secret
+func (c *Client) enroll(addr, secret string) error {
+	ts := time.Now().Unix()
+	pub := c.identity.PublicKey()
+	body, err := json.Marshal(EnrollRequest{
+		NodeID:    c.identity.NodeID,
+		PublicKey: pub,
+		Timestamp: ts,
+		Signature: crypto.SignWithTimestamp(EnrollPayload(c.identity.NodeID, pub), secret, ts),
+		Address:   c.cfg.Node.AdvertiseAddress,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal enrollment: %w", err)
+	}
+
+	_, err = c.do(http.MethodPost, addr, PathEnroll, body)
+	return err
+}
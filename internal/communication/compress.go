@@ -0,0 +1,101 @@
+package communication
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/aldebaranode/syncguard/internal/metrics"
+)
+
+var (
+	peerWireBytes = metrics.NewCounter(
+		"syncguard_peer_bytes_total",
+		"Peer API body bytes on the wire, by peer and direction",
+		"peer", "direction",
+	)
+	peerPayloadBytes = metrics.NewCounter(
+		"syncguard_peer_payload_bytes_total",
+		"Peer API body bytes before compression, by peer and direction",
+		"peer", "direction",
+	)
+)
+
+// EncodingGzip is the only content encoding peers use
+const EncodingGzip = "gzip"
+
+// ErrPayloadTooLarge is returned when a body exceeds the configured limit
+var ErrPayloadTooLarge = errors.New("payload too large")
+
+// Compress gzips data
+func Compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ReadBody reads a body in the given content encoding and returns it
+// decoded, with the number of bytes read from the wire. More than max
+// bytes, on the wire or decoded, fails with ErrPayloadTooLarge; the
+// decoded limit stops small gzip bombs. A max of 0 disables the limit.
+func ReadBody(r io.Reader, encoding string, max int64) ([]byte, int64, error) {
+	wire := &countingReader{r: r}
+	var src io.Reader = wire
+	if max > 0 {
+		src = io.LimitReader(wire, max+1)
+	}
+
+	switch encoding {
+	case "", "identity":
+	case EncodingGzip:
+		zr, err := gzip.NewReader(src)
+		if err != nil {
+			if max > 0 && wire.n > max {
+				return nil, wire.n, ErrPayloadTooLarge
+			}
+			return nil, wire.n, fmt.Errorf("invalid gzip body: %w", err)
+		}
+		defer zr.Close()
+		src = zr
+		if max > 0 {
+			src = io.LimitReader(zr, max+1)
+		}
+	default:
+		return nil, wire.n, fmt.Errorf("unsupported content encoding %q", encoding)
+	}
+
+	body, err := io.ReadAll(src)
+	if max > 0 && (int64(len(body)) > max || wire.n > max) {
+		return nil, wire.n, ErrPayloadTooLarge
+	}
+	if err != nil {
+		return nil, wire.n, err
+	}
+	return body, wire.n, nil
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// recordTraffic counts body bytes exchanged with a peer
+func recordTraffic(peer, direction string, wire, payload int) {
+	peerWireBytes.Add(float64(wire), peer, direction)
+	peerPayloadBytes.Add(float64(payload), peer, direction)
+}
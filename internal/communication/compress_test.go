@@ -0,0 +1,73 @@
+package communication
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+)
+
+func TestReadBody(t *testing.T) {
+	data := []byte(strings.Repeat("state", 100))
+	compressed, err := Compress(data)
+	if err != nil {
+		t.Fatalf("Compress: %v", err)
+	}
+
+	body, wire, err := ReadBody(bytes.NewReader(compressed), EncodingGzip, 1024)
+	if err != nil || !bytes.Equal(body, data) || wire != int64(len(compressed)) {
+		t.Errorf("ReadBody = %d bytes, wire %d, %v", len(body), wire, err)
+	}
+
+	if _, _, err := ReadBody(bytes.NewReader(compressed), EncodingGzip, 100); !errors.Is(err, ErrPayloadTooLarge) {
+		t.Errorf("expected ErrPayloadTooLarge once decoded, got %v", err)
+	}
+	if _, _, err := ReadBody(bytes.NewReader(data), "", 100); !errors.Is(err, ErrPayloadTooLarge) {
+		t.Errorf("expected ErrPayloadTooLarge, got %v", err)
+	}
+	if _, _, err := ReadBody(bytes.NewReader(data), "br", 0); err == nil {
+		t.Error("expected an unsupported encoding to fail")
+	}
+}
+
+func TestClient_Compression(t *testing.T) {
+	var encoding string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding = r.Header.Get("Content-Encoding")
+		body, _ := Compress([]byte(`{"healthy":true,"height":42}`))
+		w.Header().Set("Content-Encoding", EncodingGzip)
+		w.Write(body)
+	}))
+	defer srv.Close()
+
+	cfg := &config.Config{
+		Peers: []config.PeerConfig{{ID: "peer", Address: srv.URL}},
+		PeerAPI: config.PeerAPIConfig{
+			Compression:      true,
+			CompressMinBytes: 16,
+			MaxResponseBytes: 1024,
+		},
+		Logging: config.LoggingConfig{Level: "error", File: "/dev/null"},
+	}
+	client := NewClient(cfg, nil)
+
+	health, err := client.FetchHealth(srv.URL)
+	if err != nil || !health.Healthy || health.Height != 42 {
+		t.Fatalf("FetchHealth = %+v, %v", health, err)
+	}
+	if err := client.SendKey(srv.URL, bytes.Repeat([]byte("k"), 64)); err != nil {
+		t.Fatalf("SendKey: %v", err)
+	}
+	if encoding != EncodingGzip {
+		t.Errorf("expected a gzip request body, got encoding %q", encoding)
+	}
+
+	cfg.PeerAPI.MaxResponseBytes = 8
+	if _, err := client.FetchHealth(srv.URL); !errors.Is(err, ErrPayloadTooLarge) {
+		t.Errorf("expected an oversized response to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,47 @@
+package communication
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/aldebaranode/syncguard/internal/election"
+)
+
+// PathElection is where a peer's voter answers lease requests
+const PathElection = "/election"
+
+// RequestLease asks the voter on a peer for the active lease, or gives it
+// back; it satisfies election.Transport
+func (c *Client) RequestLease(addr string, req election.Request) (*election.Response, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal lease request: %w", err)
+	}
+
+	respBody, err := c.do(http.MethodPost, addr, PathElection, body)
+	if err != nil {
+		return nil, fmt.Errorf("lease request failed: %w", err)
+	}
+
+	var resp election.Response
+	if err := decodeJSON(respBody, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse lease response: %w", err)
+	}
+	return &resp, nil
+}
+
+// DecodeLeaseRequest decodes a lease request and checks its values
+func DecodeLeaseRequest(data []byte) (election.Request, error) {
+	var req election.Request
+	if err := decodeJSON(data, &req); err != nil {
+		return election.Request{}, err
+	}
+	if req.Candidate == "" {
+		return election.Request{}, invalid("candidate is required")
+	}
+	if err := checkID("candidate", req.Candidate); err != nil {
+		return election.Request{}, err
+	}
+	return req, nil
+}
@@ -0,0 +1,105 @@
+package communication
+
+import (
+	"sync"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+)
+
+// Quorum is how many peers a broadcast must reach to count as delivered
+type Quorum string
+
+const (
+	QuorumAll      Quorum = "all"
+	QuorumMajority Quorum = "majority"
+	QuorumAny      Quorum = "any"
+)
+
+// Required returns how many of n peers the quorum needs
+func (q Quorum) Required(n int) int {
+	switch q {
+	case QuorumAll:
+		return n
+	case QuorumAny:
+		return min(n, 1)
+	default:
+		return n/2 + 1
+	}
+}
+
+// PeerResult is the outcome of a broadcast to one peer
+type PeerResult struct {
+	PeerID  string `json:"peer_id"`
+	Address string `json:"address"`
+	// Error is empty when the peer took the message
+	Error       string        `json:"error,omitempty"`
+	FailureKind string        `json:"failure_kind,omitempty"`
+	Duration    time.Duration `json:"-"`
+	Millis      int64         `json:"duration_ms"`
+}
+
+// Broadcast is the outcome of one message sent to every peer
+type Broadcast struct {
+	Message  string       `json:"message"`
+	Time     time.Time    `json:"time"`
+	Quorum   Quorum       `json:"quorum"`
+	Required int          `json:"required"`
+	Reached  int          `json:"reached"`
+	Results  []PeerResult `json:"results"`
+}
+
+// Met reports whether enough peers took the message
+func (b *Broadcast) Met() bool {
+	return b.Reached >= b.Required
+}
+
+// Missed returns the peers that did not take the message, to send it again
+// or reconcile them later
+func (b *Broadcast) Missed() []PeerResult {
+	var missed []PeerResult
+	for _, r := range b.Results {
+		if r.Error != "" {
+			missed = append(missed, r)
+		}
+	}
+	return missed
+}
+
+// FanOut sends a message to every peer at once and waits for all of them.
+// Results are in the order of peers, so a slow peer delays the outcome but
+// no other peer.
+func FanOut(message string, peers []config.PeerConfig, quorum Quorum, send func(peer config.PeerConfig) error) *Broadcast {
+	b := &Broadcast{
+		Message:  message,
+		Time:     time.Now().UTC(),
+		Quorum:   quorum,
+		Required: quorum.Required(len(peers)),
+		Results:  make([]PeerResult, len(peers)),
+	}
+
+	var wg sync.WaitGroup
+	for i, peer := range peers {
+		wg.Add(1)
+		go func(i int, peer config.PeerConfig) {
+			defer wg.Done()
+			start := time.Now()
+			err := send(peer)
+			r := PeerResult{PeerID: peer.ID, Address: peer.Address, Duration: time.Since(start)}
+			r.Millis = r.Duration.Milliseconds()
+			if err != nil {
+				r.Error = err.Error()
+				r.FailureKind = ClassifyError(err)
+			}
+			b.Results[i] = r
+		}(i, peer)
+	}
+	wg.Wait()
+
+	for _, r := range b.Results {
+		if r.Error == "" {
+			b.Reached++
+		}
+	}
+	return b
+}
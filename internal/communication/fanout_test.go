@@ -0,0 +1,73 @@
+package communication
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+)
+
+func TestQuorumRequired(t *testing.T) {
+	tests := []struct {
+		quorum Quorum
+		peers  int
+		want   int
+	}{
+		{QuorumAll, 3, 3},
+		{QuorumMajority, 1, 1},
+		{QuorumMajority, 2, 2},
+		{QuorumMajority, 3, 2},
+		{QuorumMajority, 4, 3},
+		{QuorumAny, 3, 1},
+		{QuorumAny, 0, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.quorum.Required(tt.peers); got != tt.want {
+			t.Errorf("%s of %d: required %d, want %d", tt.quorum, tt.peers, got, tt.want)
+		}
+	}
+}
+
+func TestFanOut(t *testing.T) {
+	peers := []config.PeerConfig{
+		{ID: "a", Address: "a:8080"},
+		{ID: "b", Address: "b:8080"},
+		{ID: "c", Address: "c:8080"},
+	}
+	// Sends run concurrently: the slow peers do not add up
+	start := time.Now()
+	b := FanOut("heartbeat", peers, QuorumMajority, func(peer config.PeerConfig) error {
+		time.Sleep(50 * time.Millisecond)
+		if peer.ID == "b" {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+	if elapsed := time.Since(start); elapsed > 140*time.Millisecond {
+		t.Errorf("fan-out took %s, want the peers sent to at once", elapsed)
+	}
+
+	if b.Reached != 2 || b.Required != 2 || !b.Met() {
+		t.Errorf("reached %d of required %d, met %v; want 2 of 2, met", b.Reached, b.Required, b.Met())
+	}
+	for i, r := range b.Results {
+		if r.PeerID != peers[i].ID {
+			t.Errorf("result %d is for %s, want %s", i, r.PeerID, peers[i].ID)
+		}
+	}
+	missed := b.Missed()
+	if len(missed) != 1 || missed[0].PeerID != "b" || missed[0].Error != "connection refused" {
+		t.Errorf("missed = %+v, want peer b with its error", missed)
+	}
+
+	b = FanOut("heartbeat", peers, QuorumAll, func(peer config.PeerConfig) error {
+		if peer.ID == "c" {
+			return errors.New("timeout")
+		}
+		return nil
+	})
+	if b.Met() {
+		t.Errorf("all quorum met with %d of %d peers", b.Reached, len(peers))
+	}
+}
@@ -0,0 +1,186 @@
+package communication
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aldebaranode/syncguard/internal/health"
+	"github.com/aldebaranode/syncguard/internal/peerproto"
+	"github.com/aldebaranode/syncguard/internal/state"
+)
+
+// The fuzz targets below cover every decoder a peer's bytes reach. None may
+// panic, and whatever they accept must pass validation and re-encode.
+
+func FuzzDecodeReport(f *testing.F) {
+	report := health.Report{NodeID: "node-1", Healthy: true, Height: 100, Peers: 5,
+		Progress: &health.Progress{Role: "active", FailoverAfter: 3}}
+	for _, asProto := range []bool{false, true} {
+		data, _ := EncodeReport(asProto, report)
+		f.Add(asProto, data)
+	}
+	f.Add(false, []byte(`{"node_id":1,"height":"100","progress":[]}`))
+	f.Add(false, []byte(strings.Repeat(`{"progress":`, 64)))
+
+	f.Fuzz(func(t *testing.T, asProto bool, data []byte) {
+		r, err := DecodeReport(asProto, data)
+		if err != nil {
+			return
+		}
+		if err := validateReport(r); err != nil {
+			t.Fatalf("accepted an invalid report: %v", err)
+		}
+		encoded, err := EncodeReport(asProto, r)
+		if err != nil {
+			t.Fatalf("accepted report does not encode: %v", err)
+		}
+		if _, err := DecodeReport(asProto, encoded); err != nil {
+			t.Fatalf("re-encoded report does not decode: %v", err)
+		}
+	})
+}
+
+func FuzzDecodeAck(f *testing.F) {
+	ack := HeartbeatAck{NodeID: "node-2", Healthy: true, Height: 99, Disagreement: "lagging"}
+	for _, asProto := range []bool{false, true} {
+		data, _ := EncodeAck(asProto, ack)
+		f.Add(asProto, data)
+	}
+	f.Add(false, []byte(`{"progress":{"failure_count":-1}}`))
+
+	f.Fuzz(func(t *testing.T, asProto bool, data []byte) {
+		ack, err := DecodeAck(asProto, data)
+		if err != nil {
+			return
+		}
+		if err := validateAck(ack); err != nil {
+			t.Fatalf("accepted an invalid ack: %v", err)
+		}
+	})
+}
+
+func FuzzDecodeState(f *testing.F) {
+	s := &state.ValidatorState{Height: 42, Round: 1, Step: 3, Signature: "sig", SignBytes: "bytes"}
+	for _, asProto := range []bool{false, true} {
+		data, _ := EncodeState(asProto, s)
+		f.Add(asProto, data)
+	}
+	f.Add(false, []byte(`{"height":"-1","round":0,"step":2}`))
+	f.Add(false, []byte(`{"height":"99999999999999999999","step":"3"}`))
+
+	f.Fuzz(func(t *testing.T, asProto bool, data []byte) {
+		s, err := DecodeState(asProto, data)
+		if err != nil {
+			return
+		}
+		if err := validateState(s); err != nil {
+			t.Fatalf("accepted an invalid state: %v", err)
+		}
+		encoded, err := EncodeState(asProto, s)
+		if err != nil {
+			t.Fatalf("accepted state does not encode: %v", err)
+		}
+		again, err := DecodeState(asProto, encoded)
+		if err != nil || *again != *s {
+			t.Fatalf("state round trip = %+v, %v; want %+v", again, err, s)
+		}
+	})
+}
+
+func FuzzDecodeKey(f *testing.F) {
+	f.Add(false, []byte("sealed"))
+	f.Add(true, EncodeKey(true, []byte("sealed")))
+	f.Add(true, []byte{})
+
+	f.Fuzz(func(t *testing.T, asProto bool, data []byte) {
+		key, err := DecodeKey(asProto, data)
+		if err == nil && len(key) == 0 {
+			t.Fatal("accepted an empty key")
+		}
+	})
+}
+
+func FuzzDecodeTransition(f *testing.F) {
+	f.Add(EncodeTransition(true, "failover", "drill"))
+	f.Add(peerproto.Marshal(&peerproto.Transition{Kind: "failover", Reason: strings.Repeat("r", 1000)}))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		_, reason, err := DecodeTransition(data)
+		if err == nil && ValidateReason(reason) != nil {
+			t.Fatal("accepted an invalid reason")
+		}
+	})
+}
+
+func FuzzDecodeHandshake(f *testing.F) {
+	f.Add([]byte(`{"node_id":"node-1","target_address":"10.0.0.2:8080","advertise_address":"203.0.113.7:8080"}`))
+	f.Add([]byte(`{"node_id":"node-1","advertise_address":"file:///etc/passwd"}`))
+	f.Add([]byte(`{"node_id":["node-1"]}`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		DecodeHandshake(data)
+		DecodeEnroll(data)
+		DecodeLeaseRequest(data)
+		DecodeArtifact(data)
+	})
+}
+
+func TestDecode_Rejects(t *testing.T) {
+	protoState := peerproto.Marshal(&peerproto.ValidatorState{Height: 10, Step: 300})
+	longID := strings.Repeat("n", maxIDLength+1)
+
+	tests := []struct {
+		name   string
+		decode func() error
+	}{
+		{"deeply nested heartbeat", func() error {
+			_, err := DecodeReport(false, []byte(`{"progress":`+strings.Repeat("[", 100)))
+			return err
+		}},
+		{"overlong node ID", func() error {
+			_, err := DecodeReport(false, []byte(`{"node_id":"`+longID+`"}`))
+			return err
+		}},
+		{"negative heartbeat height", func() error {
+			_, err := DecodeReport(false, []byte(`{"height":-1}`))
+			return err
+		}},
+		{"wrapping protobuf step", func() error {
+			_, err := DecodeState(true, protoState)
+			return err
+		}},
+		{"negative state height", func() error {
+			_, err := DecodeState(false, []byte(`{"height":"-5","step":1}`))
+			return err
+		}},
+		{"unknown JSON step", func() error {
+			_, err := DecodeState(false, []byte(`{"height":"5","step":9}`))
+			return err
+		}},
+		{"empty key", func() error {
+			_, err := DecodeKey(false, nil)
+			return err
+		}},
+		{"overlong reason", func() error {
+			_, _, err := DecodeTransition(EncodeTransition(true, "failover", longID))
+			return err
+		}},
+		{"advertised address not a peer address", func() error {
+			_, err := DecodeHandshake([]byte(`{"node_id":"node-1","advertise_address":"file:///etc/passwd"}`))
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.decode(); !errors.Is(err, ErrInvalidPayload) {
+				t.Errorf("expected ErrInvalidPayload, got %v", err)
+			}
+		})
+	}
+
+	// Brackets inside strings do not count towards nesting
+	if _, err := DecodeReport(false, []byte(`{"status_error":"`+strings.Repeat("[", 100)+`"}`)); err != nil {
+		t.Errorf("expected brackets in strings to pass, got %v", err)
+	}
+}
@@ -0,0 +1,225 @@
+package communication
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/peerproto"
+	"github.com/aldebaranode/syncguard/internal/state"
+)
+
+// ProtocolGRPC sends peer requests as calls of the Peer gRPC service
+// (peer_api.protocol); the default is HTTP
+const ProtocolGRPC = "grpc"
+
+// grpcOverhead leaves room for the path and headers around a body at the
+// peer_api size limits
+const grpcOverhead = 64 << 10
+
+// GRPCMessageLimit is the largest gRPC message that carries a body of at
+// most limit bytes, a peer_api size limit that is negative when disabled
+func GRPCMessageLimit(limit config.Size) int {
+	if limit < 0 || int64(limit) > math.MaxInt32-grpcOverhead {
+		return math.MaxInt32
+	}
+	return int(limit) + grpcOverhead
+}
+
+// watchStream describes the WatchState stream to the gRPC client
+var watchStream = &grpc.StreamDesc{StreamName: "WatchState", ServerStreams: true}
+
+// grpcTransport carries peer requests as Peer.Call gRPC calls. It stands
+// in for the HTTP transport, so requests are signed, compressed and
+// limited as over HTTP.
+type grpcTransport struct {
+	maxReply int
+	mu       sync.Mutex
+	tls      *tls.Config
+	conns    map[string]*grpc.ClientConn
+}
+
+func newGRPCTransport(cfg *config.Config) *grpcTransport {
+	return &grpcTransport{
+		maxReply: GRPCMessageLimit(cfg.PeerAPI.MaxResponseBytes),
+		conns:    make(map[string]*grpc.ClientConn),
+	}
+}
+
+// conn returns the connection to host, set up on first use. Over TLS the
+// peer's certificate must name the configured peer ID in ctx, or else the
+// host.
+func (t *grpcTransport) conn(ctx context.Context, host string) (*grpc.ClientConn, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if conn, ok := t.conns[host]; ok {
+		return conn, nil
+	}
+
+	creds := insecure.NewCredentials()
+	if t.tls != nil {
+		cfg := t.tls.Clone()
+		cfg.ServerName, _ = ctx.Value(peerIDKey{}).(string)
+		if cfg.ServerName == "" {
+			cfg.ServerName, _, _ = net.SplitHostPort(host)
+		}
+		creds = credentials.NewTLS(cfg)
+	}
+	conn, err := grpc.NewClient(host,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(peerproto.Codec{}), grpc.MaxCallRecvMsgSize(t.maxReply)))
+	if err != nil {
+		return nil, err
+	}
+	t.conns[host] = conn
+	return conn, nil
+}
+
+// RoundTrip sends req as a call and turns the reply into its response
+func (t *grpcTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	conn, err := t.conn(req.Context(), req.URL.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	call := &peerproto.Call{Method: req.Method, Path: req.URL.RequestURI(), Header: req.Header, Body: body}
+	var reply peerproto.Reply
+	if err := conn.Invoke(req.Context(), peerproto.MethodCall, call, &reply); err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		Status:        http.StatusText(int(reply.Status)),
+		StatusCode:    int(reply.Status),
+		Proto:         "HTTP/2.0",
+		ProtoMajor:    2,
+		Header:        reply.Header,
+		Body:          io.NopCloser(bytes.NewReader(reply.Body)),
+		ContentLength: int64(len(reply.Body)),
+		Request:       req,
+	}, nil
+}
+
+// CloseIdleConnections closes every connection, so the next call to a
+// peer dials it again
+func (t *grpcTransport) CloseIdleConnections() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for host, conn := range t.conns {
+		conn.Close()
+		delete(t.conns, host)
+	}
+}
+
+// watch opens a WatchState stream with call and hands fn each reply until
+// the stream ends, ctx is done or fn fails
+func (t *grpcTransport) watch(ctx context.Context, host string, call *peerproto.Call, fn func(*peerproto.Reply) error) error {
+	conn, err := t.conn(ctx, host)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := conn.NewStream(ctx, watchStream, peerproto.MethodWatchState)
+	if err != nil {
+		return err
+	}
+	if err := stream.SendMsg(call); err != nil {
+		return err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return err
+	}
+	for {
+		var reply peerproto.Reply
+		if err := stream.RecvMsg(&reply); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if err := fn(&reply); err != nil {
+			return err
+		}
+	}
+}
+
+// WatchState follows the peer's validator state over a gRPC stream: fn
+// gets it with its provenance link once on subscribing, then each time it
+// changes. It returns when ctx is done, fn fails or the peer ends the
+// stream, which it does now and then to have the subscription signed
+// again. It needs peer_api.protocol grpc.
+func (c *Client) WatchState(ctx context.Context, addr string, fn func(*state.ValidatorState, *state.Link) error) error {
+	transport, ok := c.httpClient.Transport.(*grpcTransport)
+	if !ok {
+		return fmt.Errorf("streaming the validator state needs peer_api.protocol %s", ProtocolGRPC)
+	}
+	err := c.watchState(ctx, transport, addr, c.cfg.Secret, fn)
+	// A peer not rotated yet only knows the previous cluster secret
+	if c.identity == nil && c.cfg.PreviousSecret != "" && StatusCode(err) == http.StatusUnauthorized {
+		err = c.watchState(ctx, transport, addr, c.cfg.PreviousSecret, fn)
+	}
+	return err
+}
+
+// watchState is WatchState with the HMAC under secret when there is no
+// identity
+func (c *Client) watchState(ctx context.Context, transport *grpcTransport, addr, secret string, fn func(*state.ValidatorState, *state.Link) error) error {
+	target, err := url.Parse(peerURL(addr, PathValidatorState, c.secure))
+	if err != nil {
+		return fmt.Errorf("invalid peer address: %w", err)
+	}
+	header := make(http.Header)
+	if c.protobuf() {
+		header.Set("Accept", peerproto.ContentType)
+	}
+	if c.cfg.PeerAPI.Compression {
+		header.Set("Accept-Encoding", EncodingGzip)
+	}
+	c.sign(header, http.MethodGet, PathValidatorState, nil, secret)
+	call := &peerproto.Call{Method: http.MethodGet, Path: PathValidatorState, Header: header}
+
+	peer := c.peerLabel(addr)
+	ctx = context.WithValue(ctx, peerIDKey{}, c.peerIDs[addr])
+	return transport.watch(ctx, target.Host, call, func(reply *peerproto.Reply) error {
+		body, wireLen, err := ReadBody(bytes.NewReader(reply.Body), reply.Header.Get("Content-Encoding"), int64(c.cfg.PeerAPI.MaxResponseBytes))
+		recordTraffic(peer, "received", int(wireLen), len(body))
+		if err != nil {
+			return fmt.Errorf("failed to read state: %w", err)
+		}
+		if reply.Status != http.StatusOK {
+			return &statusError{code: int(reply.Status)}
+		}
+		remoteState, err := DecodeState(IsProto(reply.Header.Get("Content-Type")), body)
+		if err != nil {
+			return fmt.Errorf("failed to parse remote state: %w", err)
+		}
+		link, err := ReadLink(reply.Header)
+		if err != nil {
+			return fmt.Errorf("invalid state provenance: %w", err)
+		}
+		return fn(remoteState, link)
+	})
+}
@@ -0,0 +1,123 @@
+package communication
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+)
+
+// PathHandshake is the reach-back handshake endpoint
+const PathHandshake = "/handshake"
+
+// HandshakeRequest announces this node, the address it used for the peer
+// and, with node.advertise_address, the address it wants to be called on
+type HandshakeRequest struct {
+	NodeID           string `json:"node_id"`
+	TargetAddress    string `json:"target_address"`
+	AdvertiseAddress string `json:"advertise_address,omitempty"`
+}
+
+// HandshakeResponse tells the caller whether the peer could call it back.
+// Nodes behind NAT often reach their peer fine while the address the peer
+// has for them is unreachable, which only shows up during a failover.
+type HandshakeResponse struct {
+	NodeID      string `json:"node_id"`
+	YourAddress string `json:"your_address"`
+	ReachedBack bool   `json:"reached_back"`
+	ReachError  string `json:"reach_error,omitempty"`
+	FailureKind string `json:"failure_kind,omitempty"`
+	// Advertised echoes the caller's advertise_address when the peer could
+	// not reach it on the address it has configured; AdvertisedReached
+	// says whether the advertised one answered instead
+	Advertised        string `json:"advertised,omitempty"`
+	AdvertisedReached bool   `json:"advertised_reached,omitempty"`
+	// Protocol, Features and BootNonce are the peer's capabilities;
+	// peers that predate them leave Protocol 0
+	Protocol  int      `json:"protocol,omitempty"`
+	Features  []string `json:"features,omitempty"`
+	BootNonce string   `json:"boot_nonce,omitempty"`
+}
+
+// Handshake asks a peer to call this node back and records the outcome,
+// along with the capabilities the peer announced
+func (c *Client) Handshake(addr string) (*HandshakeResponse, error) {
+	body, err := json.Marshal(HandshakeRequest{
+		NodeID:           c.cfg.Node.ID,
+		TargetAddress:    addr,
+		AdvertiseAddress: c.cfg.Node.AdvertiseAddress,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal handshake: %w", err)
+	}
+
+	respBody, err := c.do(http.MethodPost, addr, PathHandshake, body)
+	if err != nil {
+		return nil, fmt.Errorf("handshake failed: %w", err)
+	}
+
+	var resp HandshakeResponse
+	if err := decodeJSON(respBody, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse handshake response: %w", err)
+	}
+
+	c.peers.recordHandshake(addr, &resp, HandshakeProblems(&resp, c.cfg.Node), negotiate(&resp))
+	return &resp, nil
+}
+
+// HandshakeProblems explains why a peer cannot reach this node back. A
+// port differing from node.port is expected behind a port mapping, so it is
+// only pointed out when no advertise_address says that is intended.
+func HandshakeProblems(resp *HandshakeResponse, node config.NodeConfig) []string {
+	if resp.ReachedBack {
+		return nil
+	}
+
+	var problems []string
+	if resp.YourAddress == "" {
+		problems = append(problems, fmt.Sprintf("peer %s has no address configured for this node", resp.NodeID))
+	} else {
+		problems = append(problems, fmt.Sprintf("peer %s cannot reach me back at %s [%s]: %s",
+			resp.NodeID, resp.YourAddress, resp.FailureKind, DescribeFailure(resp.FailureKind)))
+
+		wantPort, listens := strconv.Itoa(node.Port), "listens on"
+		if node.AdvertiseAddress != "" {
+			_, wantPort, _ = net.SplitHostPort(hostPort(node.AdvertiseAddress))
+			listens = "advertises"
+		}
+		if _, port, err := net.SplitHostPort(hostPort(resp.YourAddress)); err == nil && port != wantPort {
+			problems = append(problems, fmt.Sprintf(
+				"peer %s uses port %s but this node %s %s; that only works through a port mapping",
+				resp.NodeID, port, listens, wantPort))
+		}
+	}
+
+	if resp.Advertised != "" {
+		if resp.AdvertisedReached {
+			problems = append(problems, fmt.Sprintf(
+				"peer %s reaches this node at its advertised address %s; set that as its address on %s",
+				resp.NodeID, resp.Advertised, resp.NodeID))
+		} else {
+			problems = append(problems, fmt.Sprintf(
+				"peer %s cannot reach this node at its advertised address %s either", resp.NodeID, resp.Advertised))
+		}
+	}
+	return problems
+}
+
+// SameAddress reports whether two peer addresses, given as host:port or
+// URL, name the same endpoint
+func SameAddress(a, b string) bool {
+	return hostPort(a) == hostPort(b)
+}
+
+// hostPort strips the scheme and path from a peer address
+func hostPort(addr string) string {
+	if u, err := parseURL(addr); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return addr
+}
@@ -0,0 +1,44 @@
+package communication
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/aldebaranode/syncguard/internal/health"
+)
+
+// PathHeartbeat receives the active node's health reports
+const PathHeartbeat = "/heartbeat"
+
+// HeartbeatAck is a passive's answer to a health report: its own view of
+// the chain and how the report disagrees with it, if it does
+type HeartbeatAck struct {
+	NodeID       string `json:"node_id"`
+	Healthy      bool   `json:"healthy"`
+	Height       int64  `json:"height"`
+	Disagreement string `json:"disagreement,omitempty"`
+	// Progress is the passive's failover countdown; nil from older nodes
+	Progress *health.Progress `json:"progress,omitempty"`
+	// Transitions sums up the passive's changes of role; nil from older
+	// nodes
+	Transitions *health.Transitions `json:"transitions,omitempty"`
+}
+
+// SendHeartbeat pushes this node's health report to a peer
+func (c *Client) SendHeartbeat(addr string, report health.Report) (*HeartbeatAck, error) {
+	body, err := EncodeReport(c.protobuf(), report)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal heartbeat: %w", err)
+	}
+
+	respBody, asProto, err := c.exchange(http.MethodPost, addr, PathHeartbeat, body, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send heartbeat: %w", err)
+	}
+
+	ack, err := DecodeAck(asProto, respBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse heartbeat ack: %w", err)
+	}
+	return &ack, nil
+}
@@ -0,0 +1,127 @@
+package communication
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/aldebaranode/syncguard/internal/crypto"
+)
+
+// PathKeyHandoff takes the validator key in two phases, at PathKeyHandoff
+// plus the phase. Prepare hands over the sealed key, which the standby
+// only stages; commit has it write the key in place and read it back;
+// abort undoes either. The releasing node gives up its key only once the
+// standby confirmed the commit.
+const PathKeyHandoff = "/key_handoff/"
+
+// Phases of a key handoff
+const (
+	HandoffPrepare = "prepare"
+	HandoffCommit  = "commit"
+	HandoffAbort   = "abort"
+)
+
+// KeyHandoff is the releasing node's request in each phase
+type KeyHandoff struct {
+	ID string `json:"id"`
+	// SealedKey is the key sealed with the cluster secret; prepare only
+	SealedKey []byte `json:"sealed_key,omitempty"`
+	// Digest is KeyDigest of the plaintext key
+	Digest string `json:"digest,omitempty"`
+}
+
+// KeyHandoffAck is the standby's answer. To prepare it echoes the digest
+// of the key it staged; to commit it reports the address of the key it
+// read back from disk.
+type KeyHandoffAck struct {
+	ID      string `json:"id"`
+	Digest  string `json:"digest,omitempty"`
+	Written bool   `json:"written,omitempty"`
+	Address string `json:"address,omitempty"`
+}
+
+// KeyDigest is the hex SHA-256 of a plaintext key
+func KeyDigest(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:])
+}
+
+// ErrKeyNotSealed is returned for a validator key a peer sent in the clear
+var ErrKeyNotSealed = errors.New("key is not sealed with the cluster secret")
+
+// UnsealKey opens a validator key transferred sealed with the cluster
+// secret, or the previous one. A key in the clear, as older nodes send
+// it, is refused with ErrKeyNotSealed unless allowPlaintext is set
+// (failover.allow_plaintext_key); plaintext reports that it was taken.
+func UnsealKey(secrets *crypto.SecretRing, data []byte, allowPlaintext bool) (key []byte, plaintext bool, err error) {
+	key, err = secrets.Decrypt(data)
+	if err == nil {
+		return key, false, nil
+	}
+	// Sealed keys are random bytes, never valid JSON
+	if !json.Valid(data) {
+		return nil, false, fmt.Errorf("failed to unseal key: %w", err)
+	}
+	if !allowPlaintext {
+		return nil, true, ErrKeyNotSealed
+	}
+	return data, true, nil
+}
+
+// PrepareKeyHandoff hands the sealed key to the standby to stage
+func (c *Client) PrepareKeyHandoff(addr string, h KeyHandoff) (*KeyHandoffAck, error) {
+	return c.keyHandoff(addr, HandoffPrepare, h)
+}
+
+// CommitKeyHandoff has the standby write the staged key in place
+func (c *Client) CommitKeyHandoff(addr, id string) (*KeyHandoffAck, error) {
+	return c.keyHandoff(addr, HandoffCommit, KeyHandoff{ID: id})
+}
+
+// AbortKeyHandoff has the standby drop the staged key, or swap back to the
+// mock key if it already wrote the real one
+func (c *Client) AbortKeyHandoff(addr, id string) error {
+	_, err := c.keyHandoff(addr, HandoffAbort, KeyHandoff{ID: id})
+	return err
+}
+
+func (c *Client) keyHandoff(addr, phase string, h KeyHandoff) (*KeyHandoffAck, error) {
+	body, err := json.Marshal(h)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal key handoff: %w", err)
+	}
+	respBody, err := c.do(http.MethodPost, addr, PathKeyHandoff+phase, body)
+	if err != nil {
+		return nil, fmt.Errorf("key handoff %s failed: %w", phase, err)
+	}
+	var ack KeyHandoffAck
+	if err := decodeJSON(respBody, &ack); err != nil {
+		return nil, fmt.Errorf("failed to parse key handoff %s answer: %w", phase, err)
+	}
+	if ack.ID != h.ID {
+		return nil, fmt.Errorf("key handoff %s answered for %q, not %q", phase, ack.ID, h.ID)
+	}
+	return &ack, nil
+}
+
+// DecodeKeyHandoff decodes a key handoff request and checks its values
+func DecodeKeyHandoff(data []byte) (KeyHandoff, error) {
+	var h KeyHandoff
+	if err := decodeJSON(data, &h); err != nil {
+		return KeyHandoff{}, err
+	}
+	if h.ID == "" {
+		return KeyHandoff{}, invalid("id is required")
+	}
+	if err := checkID("id", h.ID); err != nil {
+		return KeyHandoff{}, err
+	}
+	if err := checkID("digest", h.Digest); err != nil {
+		return KeyHandoff{}, err
+	}
+	return h, nil
+}
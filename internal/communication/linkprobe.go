@@ -0,0 +1,52 @@
+package communication
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// PathLinkProbe accepts and discards a probe body, so a peer about to hand
+// over can time an upload of known size
+const PathLinkProbe = "/link_probe"
+
+// LinkEstimate is a measurement of the link to a peer
+type LinkEstimate struct {
+	RTT time.Duration
+	// Throughput is the upload rate in bytes per second
+	Throughput float64
+}
+
+// TransferTime estimates how long a request carrying size bytes takes
+func (e LinkEstimate) TransferTime(size int64) time.Duration {
+	if e.Throughput <= 0 {
+		return e.RTT
+	}
+	return e.RTT + time.Duration(float64(size)/e.Throughput*float64(time.Second))
+}
+
+// ProbeLink measures the round trip to a peer with a health request, then
+// its upload throughput by sending probeBytes of random data, which does
+// not compress
+func (c *Client) ProbeLink(addr string, probeBytes int) (*LinkEstimate, error) {
+	start := time.Now()
+	if err := c.Probe(addr); err != nil {
+		return nil, fmt.Errorf("failed to reach peer: %w", err)
+	}
+	rtt := time.Since(start)
+
+	body := make([]byte, probeBytes)
+	if _, err := rand.Read(body); err != nil {
+		return nil, fmt.Errorf("failed to generate probe: %w", err)
+	}
+	start = time.Now()
+	if _, err := c.do(http.MethodPost, addr, PathLinkProbe, body); err != nil {
+		return nil, fmt.Errorf("failed to send link probe: %w", err)
+	}
+	upload := time.Since(start) - rtt
+	if upload < time.Millisecond {
+		upload = time.Millisecond
+	}
+	return &LinkEstimate{RTT: rtt, Throughput: float64(probeBytes) / upload.Seconds()}, nil
+}
@@ -0,0 +1,40 @@
+package communication
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+)
+
+func TestClient_ProbeLink(t *testing.T) {
+	var received int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == PathLinkProbe {
+			body, _ := io.ReadAll(r.Body)
+			received = len(body)
+			time.Sleep(50 * time.Millisecond)
+		}
+	}))
+	defer srv.Close()
+
+	client := NewClient(&config.Config{
+		Peers:   []config.PeerConfig{{ID: "peer", Address: srv.URL}},
+		Logging: config.LoggingConfig{Level: "error", File: "/dev/null"},
+	}, nil)
+
+	link, err := client.ProbeLink(srv.URL, 64*1024)
+	if err != nil {
+		t.Fatalf("ProbeLink: %v", err)
+	}
+	if received != 64*1024 {
+		t.Errorf("peer received %d probe bytes, want %d", received, 64*1024)
+	}
+	// The upload took at least 50ms, so 64KiB more takes at least as long
+	if got := link.TransferTime(64 * 1024); got < 40*time.Millisecond {
+		t.Errorf("TransferTime = %v, want at least the probe's duration", got)
+	}
+}
@@ -0,0 +1,230 @@
+package communication
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Failure kinds reported for unreachable peers
+const (
+	FailureDNS        = "dns_failure"
+	FailureRefused    = "connection_refused"
+	FailureTimeout    = "timeout"
+	FailureTLS        = "tls_error"
+	FailureHTTPStatus = "http_status"
+	FailureOther      = "error"
+)
+
+// PeerStatus is what this node knows about reaching one peer
+type PeerStatus struct {
+	ID            string    `json:"id"`
+	Address       string    `json:"address"`
+	EverContacted bool      `json:"ever_contacted"`
+	LastSuccess   time.Time `json:"last_success,omitempty"`
+	LastFailure   time.Time `json:"last_failure,omitempty"`
+	LastError     string    `json:"last_error,omitempty"`
+	FailureKind   string    `json:"failure_kind,omitempty"`
+	ReachableBack *bool     `json:"reachable_back,omitempty"`
+	SeenAs        string    `json:"seen_as,omitempty"`
+	// Resolved are the addresses the peer's hostname last resolved to
+	Resolved     []string `json:"resolved,omitempty"`
+	ConfigErrors []string `json:"config_errors,omitempty"`
+	// Capabilities were negotiated in the last handshake
+	Capabilities *Capabilities `json:"capabilities,omitempty"`
+}
+
+// peerTracker records the outcome of requests per peer address
+type peerTracker struct {
+	mu       sync.Mutex
+	statuses map[string]*PeerStatus
+}
+
+// newPeerTracker seeds the tracker with the configured peers so peers that
+// were never contacted still show up
+func newPeerTracker(peers map[string]string) *peerTracker {
+	t := &peerTracker{statuses: make(map[string]*PeerStatus)}
+	for addr, id := range peers {
+		t.statuses[addr] = &PeerStatus{ID: id, Address: addr}
+	}
+	return t
+}
+
+// record stores the outcome of a request to addr
+func (t *peerTracker) record(addr string, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	status, ok := t.statuses[addr]
+	if !ok {
+		status = &PeerStatus{Address: addr}
+		t.statuses[addr] = status
+	}
+
+	now := time.Now().UTC()
+	if err == nil {
+		status.EverContacted = true
+		status.LastSuccess = now
+		status.LastError = ""
+		status.FailureKind = ""
+		return
+	}
+	status.LastFailure = now
+	status.LastError = err.Error()
+	status.FailureKind = ClassifyError(err)
+}
+
+// recordHandshake stores the reach-back result and capabilities for addr
+func (t *peerTracker) recordHandshake(addr string, resp *HandshakeResponse, problems []string, caps *Capabilities) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	status, ok := t.statuses[addr]
+	if !ok {
+		status = &PeerStatus{Address: addr}
+		t.statuses[addr] = status
+	}
+	reached := resp.ReachedBack
+	status.ReachableBack = &reached
+	status.SeenAs = resp.YourAddress
+	status.ConfigErrors = problems
+	status.Capabilities = caps
+}
+
+// capabilities returns the capabilities negotiated with addr within ttl
+func (t *peerTracker) capabilities(addr string, ttl time.Duration) *Capabilities {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	status, ok := t.statuses[addr]
+	if !ok || status.Capabilities == nil || time.Since(status.Capabilities.NegotiatedAt) > ttl {
+		return nil
+	}
+	caps := *status.Capabilities
+	return &caps
+}
+
+// observeBootNonce drops the capabilities of a peer that restarted since
+// they were negotiated
+func (t *peerTracker) observeBootNonce(addr, nonce string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	status, ok := t.statuses[addr]
+	if ok && nonce != "" && status.Capabilities != nil && status.Capabilities.BootNonce != nonce {
+		status.Capabilities = nil
+	}
+}
+
+// snapshot returns a copy of all peer statuses
+func (t *peerTracker) snapshot() []PeerStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	out := make([]PeerStatus, 0, len(t.statuses))
+	for _, status := range t.statuses {
+		out = append(out, *status)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Address < out[j].Address })
+	return out
+}
+
+// statusError is returned when a peer answers with a non-200 status
+type statusError struct {
+	code int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("peer returned status %d", e.code)
+}
+
+// StatusCode returns the HTTP status a peer answered with, or 0 when the
+// request failed before the peer answered
+func StatusCode(err error) int {
+	var se *statusError
+	if errors.As(err, &se) {
+		return se.code
+	}
+	return 0
+}
+
+// ClassifyError tells DNS failures, refused connections and timeouts apart,
+// since each points at a different misconfiguration
+func ClassifyError(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return FailureDNS
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return FailureRefused
+	}
+	if errors.Is(err, os.ErrDeadlineExceeded) || errors.Is(err, context.DeadlineExceeded) {
+		return FailureTimeout
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return FailureTimeout
+	}
+	var tlsErr *tls.RecordHeaderError
+	if errors.As(err, &tlsErr) {
+		return FailureTLS
+	}
+	var certErr *tls.CertificateVerificationError
+	if errors.As(err, &certErr) {
+		return FailureTLS
+	}
+	var se *statusError
+	if errors.As(err, &se) {
+		return FailureHTTPStatus
+	}
+	return FailureOther
+}
+
+// DescribeFailure turns a failure kind into an actionable hint
+func DescribeFailure(kind string) string {
+	switch kind {
+	case FailureDNS:
+		return "hostname does not resolve; check the peer address and DNS"
+	case FailureRefused:
+		return "host reachable but nothing listens on the port; is syncguard running there and node.port correct?"
+	case FailureTimeout:
+		return "no answer; check firewalls, security groups and routing"
+	case FailureTLS:
+		return "TLS handshake failed; check certificates and the address scheme"
+	case FailureHTTPStatus:
+		return "peer answered with an error; check secrets, identity keys and versions"
+	default:
+		return "unexpected error"
+	}
+}
+
+// parseURL parses a peer address given as a URL
+func parseURL(addr string) (*url.URL, error) {
+	if !strings.Contains(addr, "://") {
+		return nil, errors.New("not a URL")
+	}
+	return url.Parse(addr)
+}
+
+// peerURL builds the URL for path on a peer given as host:port or URL;
+// secure picks https for host:port
+func peerURL(addr, path string, secure bool) string {
+	if strings.Contains(addr, "://") {
+		return strings.TrimRight(addr, "/") + path
+	}
+	if secure {
+		return "https://" + addr + path
+	}
+	return "http://" + addr + path
+}
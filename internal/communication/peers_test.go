@@ -0,0 +1,206 @@
+package communication
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/health"
+)
+
+func TestClassifyError(t *testing.T) {
+	// A port that was just released refuses connections
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	closedAddr := ln.Addr().String()
+	ln.Close()
+
+	_, refusedErr := net.Dial("tcp", closedAddr)
+	_, dnsErr := net.Dial("tcp", "peer.invalid:8080")
+	_, timeoutErr := net.DialTimeout("tcp", "10.255.255.1:8080", time.Nanosecond)
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"refused", refusedErr, FailureRefused},
+		{"dns", dnsErr, FailureDNS},
+		{"timeout", timeoutErr, FailureTimeout},
+		{"status", fmt.Errorf("wrapped: %w", &statusError{code: 401}), FailureHTTPStatus},
+		{"other", errors.New("boom"), FailureOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyError(tt.err); got != tt.want {
+				t.Errorf("ClassifyError(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_PeerStatuses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	cfg := &config.Config{
+		Peers: []config.PeerConfig{
+			{ID: "up", Address: srv.URL},
+			{ID: "never", Address: "127.0.0.1:1"},
+		},
+		Logging: config.LoggingConfig{Level: "error", File: "/dev/null"},
+	}
+	client := NewClient(cfg, nil)
+
+	if err := client.Probe(srv.URL); err != nil {
+		t.Fatalf("Probe: %v", err)
+	}
+
+	statuses := make(map[string]PeerStatus)
+	for _, s := range client.PeerStatuses() {
+		statuses[s.ID] = s
+	}
+	if !statuses["up"].EverContacted {
+		t.Error("Probed peer should be marked as contacted")
+	}
+	if statuses["never"].EverContacted {
+		t.Error("Unprobed peer should be reported as never contacted")
+	}
+}
+
+func TestHandshakeProblems(t *testing.T) {
+	tests := []struct {
+		name string
+		node config.NodeConfig
+		resp HandshakeResponse
+		want []string
+	}{
+		{
+			name: "reached back",
+			resp: HandshakeResponse{NodeID: "b", YourAddress: "10.0.0.1:8080", ReachedBack: true},
+		},
+		{
+			name: "not configured",
+			resp: HandshakeResponse{NodeID: "b"},
+			want: []string{"peer b has no address configured for this node"},
+		},
+		{
+			name: "unreachable on another port",
+			resp: HandshakeResponse{NodeID: "b", YourAddress: "http://203.0.113.7:9000", FailureKind: FailureTimeout},
+			want: []string{
+				"peer b cannot reach me back at http://203.0.113.7:9000 [timeout]",
+				"peer b uses port 9000 but this node listens on 8080",
+			},
+		},
+		{
+			name: "advertised port mapping",
+			node: config.NodeConfig{Port: 8080, AdvertiseAddress: "203.0.113.7:9000"},
+			resp: HandshakeResponse{NodeID: "b", YourAddress: "203.0.113.7:9000", FailureKind: FailureRefused},
+			want: []string{"peer b cannot reach me back at 203.0.113.7:9000 [connection_refused]"},
+		},
+		{
+			name: "reachable at advertised address",
+			node: config.NodeConfig{Port: 8080, AdvertiseAddress: "10.8.0.2:8080"},
+			resp: HandshakeResponse{NodeID: "b", YourAddress: "192.168.1.2:8080", FailureKind: FailureTimeout,
+				Advertised: "10.8.0.2:8080", AdvertisedReached: true},
+			want: []string{
+				"peer b cannot reach me back at 192.168.1.2:8080 [timeout]",
+				"peer b reaches this node at its advertised address 10.8.0.2:8080",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := tt.node
+			if node.Port == 0 {
+				node.Port = 8080
+			}
+			got := HandshakeProblems(&tt.resp, node)
+			if len(got) != len(tt.want) {
+				t.Fatalf("HandshakeProblems = %q, want %d problems", got, len(tt.want))
+			}
+			for i := range tt.want {
+				if !strings.HasPrefix(got[i], tt.want[i]) {
+					t.Errorf("Problem %d = %q, want prefix %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestClient_HandshakeRecordsReachBack(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(HandshakeResponse{
+			NodeID:      "b",
+			YourAddress: "10.0.0.1:8080",
+			FailureKind: FailureRefused,
+		})
+	}))
+	defer srv.Close()
+
+	cfg := &config.Config{
+		Node:    config.NodeConfig{ID: "a", Port: 8080},
+		Peers:   []config.PeerConfig{{ID: "b", Address: srv.URL}},
+		Logging: config.LoggingConfig{Level: "error", File: "/dev/null"},
+	}
+	client := NewClient(cfg, nil)
+
+	if _, err := client.Handshake(srv.URL); err != nil {
+		t.Fatalf("Handshake: %v", err)
+	}
+
+	status := client.PeerStatuses()[0]
+	if status.ReachableBack == nil || *status.ReachableBack {
+		t.Errorf("Expected reachable_back=false, got %v", status.ReachableBack)
+	}
+	if status.SeenAs != "10.0.0.1:8080" || len(status.ConfigErrors) != 1 {
+		t.Errorf("Unexpected status: %+v", status)
+	}
+}
+
+func TestClient_TimeoutsPerOperation(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		switch r.URL.Path {
+		case PathValidatorState:
+			w.Write([]byte(`{"height":"10","round":0,"step":3}`))
+		default:
+			w.Write([]byte(`{"node_id":"peer","healthy":true}`))
+		}
+	}))
+	defer srv.Close()
+
+	addr := strings.TrimPrefix(srv.URL, "http://")
+	client := NewClient(&config.Config{
+		Peers: []config.PeerConfig{{ID: "peer", Address: addr}},
+		PeerAPI: config.PeerAPIConfig{Timeouts: config.PeerTimeoutsConfig{
+			Heartbeat: 0.05, Probe: 0.05, Notify: 0.05, State: 2, Key: 2,
+		}},
+		Logging: config.LoggingConfig{Level: "error", File: "/dev/null"},
+	}, nil)
+
+	start := time.Now()
+	_, err := client.SendHeartbeat(addr, health.Report{NodeID: "node-1", Healthy: true})
+	if ClassifyError(err) != FailureTimeout {
+		t.Errorf("expected the heartbeat to time out, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Errorf("heartbeat gave up after %s, want about 50ms", elapsed)
+	}
+
+	// The state transfer has the longer budget and waits for the slow peer
+	if s, _, err := client.FetchState(addr, true); err != nil || s.Height != 10 {
+		t.Errorf("FetchState = %+v, %v", s, err)
+	}
+}
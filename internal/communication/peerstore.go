@@ -0,0 +1,99 @@
+package communication
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/health"
+)
+
+// PeerSnapshot is what a node last knew about its peers. It is kept on
+// disk so a restarted node resumes from it instead of a blank slate.
+type PeerSnapshot struct {
+	SavedAt time.Time    `json:"saved_at"`
+	Peers   []PeerStatus `json:"peers"`
+	// Heartbeat is the active peer's last report and when it arrived
+	Heartbeat  *health.Report `json:"heartbeat,omitempty"`
+	ReceivedAt time.Time      `json:"received_at,omitempty"`
+	// PeerProgress is the peer's failover countdown from its last report
+	PeerProgress *health.Progress `json:"peer_progress,omitempty"`
+}
+
+// PeerStore keeps the last peer snapshot in a file
+type PeerStore struct {
+	path string
+}
+
+// NewPeerStore creates a store backed by the given file
+func NewPeerStore(path string) *PeerStore {
+	return &PeerStore{path: path}
+}
+
+// Save replaces the stored snapshot
+func (s *PeerStore) Save(snap PeerSnapshot) error {
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal peer state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create peer state directory: %w", err)
+	}
+
+	// Write to temporary file first so a crash never leaves a partial file
+	tmpFile := s.path + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0600); err != nil {
+		return fmt.Errorf("failed to write temp peer state file: %w", err)
+	}
+	if err := os.Rename(tmpFile, s.path); err != nil {
+		return fmt.Errorf("failed to rename peer state file: %w", err)
+	}
+	return nil
+}
+
+// Load returns the stored snapshot; nil when none was saved yet
+func (s *PeerStore) Load() (*PeerSnapshot, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read peer state file: %w", err)
+	}
+
+	var snap PeerSnapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("failed to parse peer state file: %w", err)
+	}
+	return &snap, nil
+}
+
+// restore seeds the statuses of configured peers from a saved snapshot.
+// Peers no longer configured are dropped, and the configured ID wins over
+// the saved one.
+func (t *peerTracker) restore(saved []PeerStatus) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	restored := 0
+	for _, s := range saved {
+		status, ok := t.statuses[s.Address]
+		if !ok {
+			continue
+		}
+		id := status.ID
+		*status = s
+		status.ID = id
+		restored++
+	}
+	return restored
+}
+
+// RestorePeerStatuses seeds peer reachability from a saved snapshot and
+// returns how many configured peers it covered
+func (c *Client) RestorePeerStatuses(saved []PeerStatus) int {
+	return c.peers.restore(saved)
+}
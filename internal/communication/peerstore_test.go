@@ -0,0 +1,60 @@
+package communication
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/health"
+)
+
+func TestPeerStore_RoundTripAndRestore(t *testing.T) {
+	store := NewPeerStore(filepath.Join(t.TempDir(), "data", "peers.json"))
+
+	snap, err := store.Load()
+	if err != nil || snap != nil {
+		t.Fatalf("Load() before save = %v, %v; want nil, nil", snap, err)
+	}
+
+	reached := true
+	received := time.Now().UTC().Add(-5 * time.Second).Truncate(time.Second)
+	saved := PeerSnapshot{
+		SavedAt: time.Now().UTC(),
+		Peers: []PeerStatus{
+			{ID: "old-id", Address: "10.0.0.2:8080", EverContacted: true, LastSuccess: received,
+				ReachableBack: &reached, SeenAs: "10.0.0.1"},
+			{ID: "gone", Address: "10.0.0.9:8080", EverContacted: true},
+		},
+		Heartbeat:    &health.Report{NodeID: "node-2", Healthy: true, Height: 42},
+		ReceivedAt:   received,
+		PeerProgress: &health.Progress{Role: "active", FailureCount: 1, FailoverAfter: 3},
+	}
+	if err := store.Save(saved); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	snap, err = store.Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if snap.Heartbeat == nil || snap.Heartbeat.Height != 42 || !snap.ReceivedAt.Equal(received) {
+		t.Errorf("heartbeat = %+v at %s, want height 42 at %s", snap.Heartbeat, snap.ReceivedAt, received)
+	}
+	if snap.PeerProgress == nil || snap.PeerProgress.FailuresLeft() != 2 {
+		t.Errorf("peer progress = %+v, want 2 failures left", snap.PeerProgress)
+	}
+
+	tracker := newPeerTracker(map[string]string{"10.0.0.2:8080": "node-2"})
+	if n := tracker.restore(snap.Peers); n != 1 {
+		t.Errorf("restore() = %d, want only the configured peer", n)
+	}
+	statuses := tracker.snapshot()
+	if len(statuses) != 1 {
+		t.Fatalf("statuses = %+v, want one peer", statuses)
+	}
+	got := statuses[0]
+	if got.ID != "node-2" || !got.EverContacted || !got.LastSuccess.Equal(received) ||
+		got.ReachableBack == nil || !*got.ReachableBack || got.SeenAs != "10.0.0.1" {
+		t.Errorf("restored status = %+v", got)
+	}
+}
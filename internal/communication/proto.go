@@ -0,0 +1,340 @@
+package communication
+
+import (
+	"encoding/json"
+	"mime"
+	"strings"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/health"
+	"github.com/aldebaranode/syncguard/internal/peerproto"
+	"github.com/aldebaranode/syncguard/internal/state"
+)
+
+// EncodingProtobuf selects protobuf envelopes for peer messages
+// (peer_api.encoding); the default is JSON
+const EncodingProtobuf = "protobuf"
+
+// IsProto reports whether a Content-Type names a protobuf envelope
+func IsProto(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == peerproto.ContentType
+}
+
+// AcceptsProto reports whether an Accept header asks for protobuf
+func AcceptsProto(accept string) bool {
+	return strings.Contains(accept, peerproto.ContentType)
+}
+
+// The peer messages below are encoded as protobuf envelopes or as the JSON
+// bodies peers exchanged before, which every node still accepts
+
+// EncodeReport encodes a heartbeat
+func EncodeReport(asProto bool, r health.Report) ([]byte, error) {
+	if !asProto {
+		return json.Marshal(r)
+	}
+	m := &peerproto.Heartbeat{
+		NodeID:         r.NodeID,
+		Healthy:        r.Healthy,
+		Syncing:        r.Syncing,
+		Height:         r.Height,
+		Peers:          int64(r.Peers),
+		StatusError:    r.StatusError,
+		ExecutionError: r.ExecutionError,
+	}
+	if !r.Time.IsZero() {
+		m.TimeUnixNano = r.Time.UnixNano()
+	}
+	m.Progress = progressToProto(r.Progress)
+	m.Transitions = transitionsToProto(r.Transitions)
+	return peerproto.Marshal(m), nil
+}
+
+// DecodeReport decodes a heartbeat and checks its values
+func DecodeReport(asProto bool, data []byte) (health.Report, error) {
+	r, err := decodeReport(asProto, data)
+	if err != nil {
+		return health.Report{}, err
+	}
+	return r, validateReport(r)
+}
+
+func decodeReport(asProto bool, data []byte) (health.Report, error) {
+	var r health.Report
+	if !asProto {
+		err := decodeJSON(data, &r)
+		return r, err
+	}
+	var m peerproto.Heartbeat
+	err := peerproto.Unmarshal(data, &m)
+	if err != nil {
+		return r, err
+	}
+	r = health.Report{
+		NodeID:         m.NodeID,
+		Healthy:        m.Healthy,
+		Syncing:        m.Syncing,
+		Height:         m.Height,
+		Peers:          int(m.Peers),
+		StatusError:    m.StatusError,
+		ExecutionError: m.ExecutionError,
+	}
+	if m.TimeUnixNano != 0 {
+		r.Time = time.Unix(0, m.TimeUnixNano).UTC()
+	}
+	r.Progress = progressFromProto(m.Progress)
+	r.Transitions, err = transitionsFromProto(m.Transitions)
+	return r, err
+}
+
+// EncodeAck encodes a heartbeat ack
+func EncodeAck(asProto bool, ack HeartbeatAck) ([]byte, error) {
+	if !asProto {
+		return json.Marshal(ack)
+	}
+	return peerproto.Marshal(&peerproto.HeartbeatAck{
+		NodeID:       ack.NodeID,
+		Healthy:      ack.Healthy,
+		Height:       ack.Height,
+		Disagreement: ack.Disagreement,
+		Progress:     progressToProto(ack.Progress),
+		Transitions:  transitionsToProto(ack.Transitions),
+	}), nil
+}
+
+// DecodeAck decodes a heartbeat ack and checks its values
+func DecodeAck(asProto bool, data []byte) (HeartbeatAck, error) {
+	ack, err := decodeAck(asProto, data)
+	if err != nil {
+		return HeartbeatAck{}, err
+	}
+	return ack, validateAck(ack)
+}
+
+func decodeAck(asProto bool, data []byte) (HeartbeatAck, error) {
+	var ack HeartbeatAck
+	if !asProto {
+		err := decodeJSON(data, &ack)
+		return ack, err
+	}
+	var m peerproto.HeartbeatAck
+	if err := peerproto.Unmarshal(data, &m); err != nil {
+		return ack, err
+	}
+	transitions, err := transitionsFromProto(m.Transitions)
+	return HeartbeatAck{
+		NodeID:       m.NodeID,
+		Healthy:      m.Healthy,
+		Height:       m.Height,
+		Disagreement: m.Disagreement,
+		Progress:     progressFromProto(m.Progress),
+		Transitions:  transitions,
+	}, err
+}
+
+// transitionsToProto flattens a summary of role changes; nil leaves it
+// unset
+func transitionsToProto(t *health.Transitions) peerproto.Transitions {
+	if t == nil {
+		return peerproto.Transitions{}
+	}
+	last := make(map[string]int64, len(t.Last))
+	for kind, at := range t.Last {
+		last[kind] = unixNano(at)
+	}
+	reasons := make(map[string]int64, len(t.Reasons))
+	for reason, count := range t.Reasons {
+		reasons[reason] = int64(count)
+	}
+	return peerproto.Transitions{
+		Epoch:   int64(t.Epoch),
+		Last:    peerproto.EncodeCounts(last),
+		Reasons: peerproto.EncodeCounts(reasons),
+	}
+}
+
+// transitionsFromProto restores a summary; nil when none was sent, or the
+// sender never changed role
+func transitionsFromProto(m peerproto.Transitions) (*health.Transitions, error) {
+	if m.Epoch == 0 && len(m.Last) == 0 && len(m.Reasons) == 0 {
+		return nil, nil
+	}
+	last, err := peerproto.DecodeCounts(m.Last)
+	if err != nil {
+		return nil, err
+	}
+	reasons, err := peerproto.DecodeCounts(m.Reasons)
+	if err != nil {
+		return nil, err
+	}
+	t := &health.Transitions{Epoch: int(m.Epoch)}
+	for kind, at := range last {
+		if t.Last == nil {
+			t.Last = make(map[string]time.Time, len(last))
+		}
+		t.Last[kind] = fromUnixNano(at)
+	}
+	for reason, count := range reasons {
+		if t.Reasons == nil {
+			t.Reasons = make(map[string]int, len(reasons))
+		}
+		t.Reasons[reason] = int(count)
+	}
+	return t, nil
+}
+
+// progressToProto flattens a failover countdown; nil leaves it unset
+func progressToProto(p *health.Progress) peerproto.Progress {
+	if p == nil {
+		return peerproto.Progress{}
+	}
+	m := peerproto.Progress{
+		Role:                 p.Role,
+		FailureCount:         int64(p.FailureCount),
+		FailoverAfter:        int64(p.FailoverAfter),
+		FailbackAfterSeconds: int64(p.FailbackAfterSeconds),
+		SinceUnixNano:        unixNano(p.Since),
+		NextCheckUnixNano:    unixNano(p.NextCheck),
+	}
+	if p.NextStateSync != nil {
+		m.NextStateSyncUnixNano = unixNano(*p.NextStateSync)
+	}
+	return m
+}
+
+// progressFromProto restores a countdown; nil when none was sent
+func progressFromProto(m peerproto.Progress) *health.Progress {
+	if m.Role == "" {
+		return nil
+	}
+	p := &health.Progress{
+		Role:                 m.Role,
+		Since:                fromUnixNano(m.SinceUnixNano),
+		FailureCount:         int(m.FailureCount),
+		FailoverAfter:        int(m.FailoverAfter),
+		FailbackAfterSeconds: float64(m.FailbackAfterSeconds),
+		NextCheck:            fromUnixNano(m.NextCheckUnixNano),
+	}
+	if !p.Since.IsZero() {
+		p.InStateSeconds = time.Since(p.Since).Seconds()
+	}
+	if m.NextStateSyncUnixNano != 0 {
+		next := fromUnixNano(m.NextStateSyncUnixNano)
+		p.NextStateSync = &next
+	}
+	return p
+}
+
+func unixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func fromUnixNano(n int64) time.Time {
+	if n == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, n).UTC()
+}
+
+// EncodeState encodes the validator state
+func EncodeState(asProto bool, s *state.ValidatorState) ([]byte, error) {
+	if !asProto {
+		return json.Marshal(s)
+	}
+	return peerproto.Marshal(&peerproto.ValidatorState{
+		Height:    s.Height,
+		Round:     s.Round,
+		Step:      int32(s.Step),
+		Signature: s.Signature,
+		SignBytes: s.SignBytes,
+	}), nil
+}
+
+// DecodeState decodes the validator state and checks its values
+func DecodeState(asProto bool, data []byte) (*state.ValidatorState, error) {
+	s, err := decodeState(asProto, data)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateState(s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func decodeState(asProto bool, data []byte) (*state.ValidatorState, error) {
+	if !asProto {
+		var s state.ValidatorState
+		if err := decodeJSON(data, &s); err != nil {
+			return nil, err
+		}
+		return &s, nil
+	}
+	var m peerproto.ValidatorState
+	if err := peerproto.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	// Checked here, as the conversion to int8 would wrap
+	if m.Step < 0 || m.Step > maxStep {
+		return nil, invalid("unknown step %d", m.Step)
+	}
+	return &state.ValidatorState{
+		Height:    m.Height,
+		Round:     m.Round,
+		Step:      int8(m.Step),
+		Signature: m.Signature,
+		SignBytes: m.SignBytes,
+	}, nil
+}
+
+// EncodeKey wraps the sealed validator key; as JSON it is sent as is
+func EncodeKey(asProto bool, sealed []byte) []byte {
+	if !asProto {
+		return sealed
+	}
+	return peerproto.Marshal(&peerproto.KeyTransfer{SealedKey: sealed})
+}
+
+// DecodeKey unwraps the sealed validator key
+func DecodeKey(asProto bool, data []byte) ([]byte, error) {
+	sealed := data
+	if asProto {
+		var m peerproto.KeyTransfer
+		if err := peerproto.Unmarshal(data, &m); err != nil {
+			return nil, err
+		}
+		sealed = m.SealedKey
+	}
+	if len(sealed) == 0 {
+		return nil, invalid("empty key")
+	}
+	return sealed, nil
+}
+
+// EncodeTransition encodes a takeover or release request. As JSON there is
+// no body: the reason travels in HeaderReason.
+func EncodeTransition(asProto bool, kind, reason string) []byte {
+	if !asProto {
+		return nil
+	}
+	return peerproto.Marshal(&peerproto.Transition{Kind: kind, Reason: reason})
+}
+
+// DecodeTransition decodes a takeover or release request
+func DecodeTransition(data []byte) (kind, reason string, err error) {
+	var m peerproto.Transition
+	if err := peerproto.Unmarshal(data, &m); err != nil {
+		return "", "", err
+	}
+	if err := checkID("kind", m.Kind); err != nil {
+		return "", "", err
+	}
+	if err := ValidateReason(m.Reason); err != nil {
+		return "", "", err
+	}
+	return m.Kind, m.Reason, nil
+}
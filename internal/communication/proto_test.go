@@ -0,0 +1,85 @@
+package communication
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/health"
+	"github.com/aldebaranode/syncguard/internal/peerproto"
+)
+
+func protoClient() *Client {
+	return NewClient(&config.Config{
+		PeerAPI: config.PeerAPIConfig{Encoding: EncodingProtobuf, MaxResponseBytes: 1024},
+		Logging: config.LoggingConfig{Level: "error", File: "/dev/null"},
+	}, nil)
+}
+
+func TestClient_Protobuf(t *testing.T) {
+	var received health.Report
+	since := time.Now().Add(-time.Minute).UTC()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		report, err := DecodeReport(IsProto(r.Header.Get("Content-Type")), body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		received = report
+
+		asProto := AcceptsProto(r.Header.Get("Accept"))
+		ack, _ := EncodeAck(asProto, HeartbeatAck{NodeID: "passive", Healthy: true, Height: 41,
+			Progress: &health.Progress{Role: "passive", FailureCount: 1, FailoverAfter: 3, Since: since},
+			Transitions: &health.Transitions{Epoch: 3, Last: map[string]time.Time{"failover": since},
+				Reasons: map[string]int{"node_unhealthy": 2, "manual": 1}}})
+		if asProto {
+			w.Header().Set("Content-Type", peerproto.ContentType)
+		}
+		w.Write(ack)
+	}))
+	defer srv.Close()
+
+	now := time.Now().UTC()
+	report := health.Report{NodeID: "active", Healthy: true, Height: 42, Peers: 8, Time: now}
+	ack, err := protoClient().SendHeartbeat(srv.URL, report)
+	if err != nil {
+		t.Fatalf("SendHeartbeat failed: %v", err)
+	}
+	if received != report {
+		t.Errorf("peer received %+v, want %+v", received, report)
+	}
+	if ack.NodeID != "passive" || ack.Height != 41 {
+		t.Errorf("ack = %+v", ack)
+	}
+	if p := ack.Progress; p == nil || p.Role != "passive" || p.FailuresLeft() != 2 || !p.Since.Equal(since) {
+		t.Errorf("ack progress = %+v", p)
+	}
+	if tr := ack.Transitions; tr == nil || tr.Epoch != 3 || !tr.Last["failover"].Equal(since) ||
+		tr.Reasons["node_unhealthy"] != 2 || tr.Reasons["manual"] != 1 {
+		t.Errorf("ack transitions = %+v", tr)
+	}
+}
+
+func TestClient_ProtobufFromJSONPeer(t *testing.T) {
+	// A peer that predates protobuf answers JSON whatever it is asked for
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"height":"1200","round":1,"step":3}`))
+	}))
+	defer srv.Close()
+
+	remote, link, err := protoClient().FetchState(srv.URL, true)
+	if err != nil {
+		t.Fatalf("FetchState failed: %v", err)
+	}
+	if remote.Height != 1200 || remote.Round != 1 || remote.Step != 3 {
+		t.Errorf("state = %+v", remote)
+	}
+	if link != nil {
+		t.Errorf("provenance = %+v from a peer that sends none", link)
+	}
+}
@@ -0,0 +1,43 @@
+package communication
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/aldebaranode/syncguard/internal/state"
+)
+
+// Headers carrying the provenance link of a served validator state
+const (
+	HeaderStateEpoch = "X-SyncGuard-State-Epoch"
+	HeaderStateSeq   = "X-SyncGuard-State-Seq"
+	HeaderStatePrev  = "X-SyncGuard-State-Prev"
+	HeaderStateHash  = "X-SyncGuard-State-Hash"
+)
+
+// WriteLink sets the provenance headers of a state response
+func WriteLink(h http.Header, link state.Link) {
+	h.Set(HeaderStateEpoch, link.Epoch)
+	h.Set(HeaderStateSeq, strconv.FormatUint(link.Seq, 10))
+	h.Set(HeaderStatePrev, link.Prev)
+	h.Set(HeaderStateHash, link.Hash)
+}
+
+// ReadLink parses the provenance headers; it returns nil when the peer sent
+// none
+func ReadLink(h http.Header) (*state.Link, error) {
+	if h.Get(HeaderStateHash) == "" {
+		return nil, nil
+	}
+	seq, err := strconv.ParseUint(h.Get(HeaderStateSeq), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", HeaderStateSeq, err)
+	}
+	return &state.Link{
+		Epoch: h.Get(HeaderStateEpoch),
+		Seq:   seq,
+		Prev:  h.Get(HeaderStatePrev),
+		Hash:  h.Get(HeaderStateHash),
+	}, nil
+}
@@ -0,0 +1,100 @@
+package communication
+
+import (
+	"context"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/metrics"
+)
+
+// resolveTimeout bounds the lookup of one peer hostname
+const resolveTimeout = 5 * time.Second
+
+var dnsChangeCounter = metrics.NewCounter(
+	"syncguard_peer_dns_changes_total",
+	"Times a peer hostname resolved to different addresses than before",
+	"peer",
+)
+
+// lookupHost resolves a hostname; tests replace it
+var lookupHost = net.DefaultResolver.LookupHost
+
+// peerHost returns the hostname of a peer address; "" for IP addresses,
+// which need no resolving
+func peerHost(addr string) string {
+	host, _, err := net.SplitHostPort(hostPort(addr))
+	if err != nil || net.ParseIP(host) != nil {
+		return ""
+	}
+	return host
+}
+
+// AddressHost returns the name or IP of a peer address given as host:port
+// or URL, without scheme, port or brackets: what the peer's certificate
+// must carry
+func AddressHost(addr string) string {
+	if u, err := parseURL(addr); err == nil && u.Hostname() != "" {
+		return u.Hostname()
+	}
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return strings.Trim(addr, "[]")
+}
+
+// recordResolved stores the addresses addr resolved to and returns the
+// previous ones when they differ; the first resolution is no change
+func (t *peerTracker) recordResolved(addr string, ips []string) (previous []string, changed bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	status, ok := t.statuses[addr]
+	if !ok {
+		status = &PeerStatus{Address: addr}
+		t.statuses[addr] = status
+	}
+	previous = status.Resolved
+	status.Resolved = ips
+	return previous, previous != nil && !slices.Equal(previous, ips)
+}
+
+// ResolvePeers re-resolves the hostname of every configured peer. Pooled
+// keep-alive connections keep talking to the address a hostname had when
+// they were opened, so when a peer's addresses change they are dropped and
+// the next request dials the new one. A failed lookup keeps the previous
+// addresses. It returns the IDs of the peers whose addresses changed.
+func (c *Client) ResolvePeers(ctx context.Context) []string {
+	var changed []string
+	for _, peer := range c.cfg.Peers {
+		host := peerHost(peer.Address)
+		if host == "" {
+			continue
+		}
+
+		lookupCtx, cancel := context.WithTimeout(ctx, resolveTimeout)
+		ips, err := lookupHost(lookupCtx, host)
+		cancel()
+		if err != nil {
+			c.logger.Debug("Failed to resolve peer %s (%s): %v", peer.ID, host, err)
+			continue
+		}
+		slices.Sort(ips)
+
+		previous, moved := c.peers.recordResolved(peer.Address, ips)
+		if !moved {
+			continue
+		}
+		dnsChangeCounter.Inc(peer.ID)
+		c.logger.Warn("Peer %s (%s) now resolves to %s, was %s; dropping pooled connections",
+			peer.ID, host, strings.Join(ips, ", "), strings.Join(previous, ", "))
+		changed = append(changed, peer.ID)
+	}
+
+	if len(changed) > 0 {
+		c.httpClient.CloseIdleConnections()
+	}
+	return changed
+}
@@ -0,0 +1,84 @@
+package communication
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+)
+
+func TestPeerHost(t *testing.T) {
+	tests := map[string]string{
+		"peer.example.com:8080":         "peer.example.com",
+		"https://peer.example.com:8443": "peer.example.com",
+		"10.0.0.2:8080":                 "",
+		"http://[2001:db8::2]:8080":     "",
+	}
+	for addr, want := range tests {
+		if got := peerHost(addr); got != want {
+			t.Errorf("peerHost(%q) = %q, want %q", addr, got, want)
+		}
+	}
+}
+
+func TestAddressHost(t *testing.T) {
+	tests := map[string]string{
+		"10.0.2.10:8080":                "10.0.2.10",
+		"peer.example.com:8080":         "peer.example.com",
+		"[2001:db8::2]:8080":            "2001:db8::2",
+		"https://10.0.2.10:8080":        "10.0.2.10",
+		"http://peer.example.com":       "peer.example.com",
+		"https://[2001:db8::2]:8443/x/": "2001:db8::2",
+		"peer.example.com":              "peer.example.com",
+	}
+	for addr, want := range tests {
+		if got := AddressHost(addr); got != want {
+			t.Errorf("AddressHost(%q) = %q, want %q", addr, got, want)
+		}
+	}
+}
+
+func TestClient_ResolvePeers(t *testing.T) {
+	answers := map[string][]string{"peer.example.com": {"10.0.0.2"}}
+	var lookupErr error
+	original := lookupHost
+	defer func() { lookupHost = original }()
+	lookupHost = func(ctx context.Context, host string) ([]string, error) {
+		return answers[host], lookupErr
+	}
+
+	cfg := &config.Config{Peers: []config.PeerConfig{
+		{ID: "b", Address: "peer.example.com:8080"},
+		{ID: "c", Address: "10.0.0.3:8080"},
+	}}
+	c := NewClient(cfg, nil)
+
+	if changed := c.ResolvePeers(context.Background()); len(changed) != 0 {
+		t.Errorf("first resolution reported changes: %v", changed)
+	}
+	if changed := c.ResolvePeers(context.Background()); len(changed) != 0 {
+		t.Errorf("unchanged addresses reported as changes: %v", changed)
+	}
+
+	// A failed lookup keeps the last known addresses
+	lookupErr = errors.New("no such host")
+	if changed := c.ResolvePeers(context.Background()); len(changed) != 0 {
+		t.Errorf("failed lookup reported changes: %v", changed)
+	}
+	lookupErr = nil
+
+	answers["peer.example.com"] = []string{"10.0.1.2", "10.0.0.9"}
+	changed := c.ResolvePeers(context.Background())
+	if len(changed) != 1 || changed[0] != "b" {
+		t.Fatalf("changed = %v, want [b]", changed)
+	}
+	for _, status := range c.PeerStatuses() {
+		if status.ID == "b" && (len(status.Resolved) != 2 || status.Resolved[0] != "10.0.0.9") {
+			t.Errorf("resolved = %v, want sorted new addresses", status.Resolved)
+		}
+		if status.ID == "c" && status.Resolved != nil {
+			t.Errorf("IP peer was resolved: %v", status.Resolved)
+		}
+	}
+}
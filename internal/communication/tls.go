@@ -0,0 +1,44 @@
+package communication
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"time"
+)
+
+// peerIDKey carries the ID of the configured peer a request goes to
+type peerIDKey struct{}
+
+// UseTLS sends every request over mutual TLS with base, presenting this
+// node's certificate. A configured peer's certificate must name its node
+// ID, whatever address it is reached at; other addresses must be named in
+// the certificate they answer with.
+func (c *Client) UseTLS(base *tls.Config) {
+	c.secure = true
+	if grpcTransport, ok := c.httpClient.Transport.(*grpcTransport); ok {
+		grpcTransport.tls = base
+		return
+	}
+
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	transport := c.httpClient.Transport.(*http.Transport)
+	transport.DialTLSContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dialer.DialContext(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		cfg := base.Clone()
+		cfg.ServerName, _ = ctx.Value(peerIDKey{}).(string)
+		if cfg.ServerName == "" {
+			cfg.ServerName, _, _ = net.SplitHostPort(addr)
+		}
+		tlsConn := tls.Client(conn, cfg)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		return tlsConn, nil
+	}
+}
@@ -0,0 +1,87 @@
+package communication
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/crypto"
+)
+
+// issueTLS writes a node certificate signed by ca and loads its config
+func issueTLS(t *testing.T, ca *crypto.CertBundle, nodeID string) *tls.Config {
+	t.Helper()
+	dir := t.TempDir()
+	bundle, err := crypto.IssueCertificate(ca, nodeID, []string{"127.0.0.1"}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths := map[string][]byte{"ca.pem": ca.CertPEM, "node.pem": bundle.CertPEM, "node-key.pem": bundle.KeyPEM}
+	for name, data := range paths {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg, err := crypto.LoadTLSConfig(filepath.Join(dir, "ca.pem"), filepath.Join(dir, "node.pem"), filepath.Join(dir, "node-key.pem"))
+	if err != nil {
+		t.Fatalf("LoadTLSConfig() error = %v", err)
+	}
+	return cfg
+}
+
+func TestClient_MutualTLS(t *testing.T) {
+	ca, err := crypto.GenerateCA("Test CA", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"healthy":true}`))
+	}))
+	srv.TLS = issueTLS(t, ca, "node-a")
+	srv.StartTLS()
+	defer srv.Close()
+	addr := srv.Listener.Addr().String()
+
+	newClient := func(peerID string) *Client {
+		cfg := &config.Config{}
+		cfg.Node.ID = "node-b"
+		cfg.Logging = config.LoggingConfig{Level: "error", File: "/dev/null"}
+		cfg.Peers = []config.PeerConfig{{ID: peerID, Address: addr}}
+		return NewClient(cfg, nil)
+	}
+
+	client := newClient("node-a")
+	client.UseTLS(issueTLS(t, ca, "node-b"))
+	if err := client.Probe(addr); err != nil {
+		t.Fatalf("Probe() over mutual TLS error = %v", err)
+	}
+
+	// The certificate names another node than the one configured there
+	impostor := newClient("node-c")
+	impostor.UseTLS(issueTLS(t, ca, "node-b"))
+	if err := impostor.Probe(addr); err == nil {
+		t.Error("Probe() accepted a certificate that does not name the configured peer")
+	}
+
+	// A client without a certificate is turned away
+	plain := newClient("node-a")
+	base := issueTLS(t, ca, "node-b")
+	base.Certificates = nil
+	plain.UseTLS(base)
+	if err := plain.Probe(addr); err == nil {
+		t.Error("Probe() without a client certificate succeeded")
+	}
+
+	// A certificate from another CA is not trusted
+	otherCA, _ := crypto.GenerateCA("Other CA", time.Hour)
+	stranger := newClient("node-a")
+	stranger.UseTLS(issueTLS(t, otherCA, "node-b"))
+	if err := stranger.Probe(addr); err == nil {
+		t.Error("Probe() trusted a certificate from another CA")
+	}
+}
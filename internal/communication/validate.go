@@ -0,0 +1,229 @@
+package communication
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/health"
+	"github.com/aldebaranode/syncguard/internal/state"
+)
+
+// Limits on what a peer may send. Bodies are already capped by
+// peer_api.max_request_bytes; these keep a buggy or hostile peer from
+// filling logs, alerts and metric labels with a single field, or from
+// sending values the rest of the daemon was never meant to see.
+const (
+	// maxJSONDepth is the deepest nesting a peer message needs, with room
+	maxJSONDepth = 16
+	// maxIDLength bounds node IDs, roles and reason codes
+	maxIDLength = 256
+	// maxTextLength bounds free-form text such as error messages
+	maxTextLength = 4096
+	// maxStep is the last CometBFT signing step (precommit)
+	maxStep = 3
+)
+
+// ErrInvalidPayload is returned for a peer message that decodes but holds
+// values no well-behaved peer sends
+var ErrInvalidPayload = errors.New("invalid peer payload")
+
+// invalid wraps a validation failure in ErrInvalidPayload
+func invalid(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidPayload, fmt.Sprintf(format, args...))
+}
+
+// decodeJSON unmarshals a peer's JSON body after checking its nesting, so
+// deeply nested input is refused before the decoder walks it
+func decodeJSON(data []byte, v interface{}) error {
+	if err := checkJSONDepth(data, maxJSONDepth); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// checkJSONDepth fails when objects and arrays in data nest deeper than
+// max. It only tracks brackets outside strings; malformed input is left
+// for the decoder to reject.
+func checkJSONDepth(data []byte, max int) error {
+	depth := 0
+	inString, escaped := false, false
+	for _, c := range data {
+		switch {
+		case escaped:
+			escaped = false
+		case inString:
+			switch c {
+			case '\\':
+				escaped = true
+			case '"':
+				inString = false
+			}
+		case c == '"':
+			inString = true
+		case c == '{' || c == '[':
+			depth++
+			if depth > max {
+				return invalid("JSON nested deeper than %d levels", max)
+			}
+		case c == '}' || c == ']':
+			depth--
+		}
+	}
+	return nil
+}
+
+// checkID rejects an overlong identifier
+func checkID(name, value string) error {
+	if len(value) > maxIDLength {
+		return invalid("%s is %d bytes, at most %d allowed", name, len(value), maxIDLength)
+	}
+	return nil
+}
+
+// checkText rejects overlong free-form text
+func checkText(name, value string) error {
+	if len(value) > maxTextLength {
+		return invalid("%s is %d bytes, at most %d allowed", name, len(value), maxTextLength)
+	}
+	return nil
+}
+
+// validateReport checks a heartbeat's values
+func validateReport(r health.Report) error {
+	if r.Height < 0 {
+		return invalid("negative height %d", r.Height)
+	}
+	if r.Peers < 0 {
+		return invalid("negative peer count %d", r.Peers)
+	}
+	if err := checkID("node_id", r.NodeID); err != nil {
+		return err
+	}
+	if err := checkText("status_error", r.StatusError); err != nil {
+		return err
+	}
+	if err := checkText("execution_error", r.ExecutionError); err != nil {
+		return err
+	}
+	if err := validateTransitions(r.Transitions); err != nil {
+		return err
+	}
+	return validateProgress(r.Progress)
+}
+
+// validateAck checks a heartbeat ack's values
+func validateAck(ack HeartbeatAck) error {
+	if ack.Height < 0 {
+		return invalid("negative height %d", ack.Height)
+	}
+	if err := checkID("node_id", ack.NodeID); err != nil {
+		return err
+	}
+	if err := checkText("disagreement", ack.Disagreement); err != nil {
+		return err
+	}
+	if err := validateTransitions(ack.Transitions); err != nil {
+		return err
+	}
+	return validateProgress(ack.Progress)
+}
+
+// validateTransitions checks a summary of role changes: its keys end up
+// in status output and logs
+func validateTransitions(t *health.Transitions) error {
+	if t == nil {
+		return nil
+	}
+	if t.Epoch < 0 {
+		return invalid("negative transition epoch %d", t.Epoch)
+	}
+	for kind := range t.Last {
+		if err := checkID("transitions.last", kind); err != nil {
+			return err
+		}
+	}
+	for reason, count := range t.Reasons {
+		if err := checkID("transitions.reasons", reason); err != nil {
+			return err
+		}
+		if count < 0 {
+			return invalid("negative count for reason %q", reason)
+		}
+	}
+	return nil
+}
+
+// validateProgress checks a failover countdown; nil is valid
+func validateProgress(p *health.Progress) error {
+	if p == nil {
+		return nil
+	}
+	if err := checkID("progress.role", p.Role); err != nil {
+		return err
+	}
+	if p.FailureCount < 0 || p.FailoverAfter < 0 || p.FailbackAfterSeconds < 0 {
+		return invalid("negative failover countdown")
+	}
+	return nil
+}
+
+// validateState checks a validator state before it can reach the state
+// file: a negative height or an unknown step would corrupt double-sign
+// protection
+func validateState(s *state.ValidatorState) error {
+	if s.Height < 0 {
+		return invalid("negative height %d", s.Height)
+	}
+	if s.Round < 0 {
+		return invalid("negative round %d", s.Round)
+	}
+	if s.Step < 0 || s.Step > maxStep {
+		return invalid("unknown step %d", s.Step)
+	}
+	return nil
+}
+
+// ValidateReason rejects an overlong transition reason code
+func ValidateReason(reason string) error {
+	return checkID("reason", reason)
+}
+
+// DecodeHandshake decodes a handshake request and checks its values; the
+// advertised address is probed, so it must be a peer address
+func DecodeHandshake(data []byte) (HandshakeRequest, error) {
+	var req HandshakeRequest
+	if err := decodeJSON(data, &req); err != nil {
+		return HandshakeRequest{}, err
+	}
+	for name, value := range map[string]string{
+		"node_id": req.NodeID, "target_address": req.TargetAddress, "advertise_address": req.AdvertiseAddress,
+	} {
+		if err := checkID(name, value); err != nil {
+			return HandshakeRequest{}, err
+		}
+	}
+	if req.AdvertiseAddress != "" {
+		if err := config.ValidatePeerAddress(req.AdvertiseAddress); err != nil {
+			return HandshakeRequest{}, invalid("advertise_address: %v", err)
+		}
+	}
+	return req, nil
+}
+
+// DecodeEnroll decodes an enrollment request and checks its values
+func DecodeEnroll(data []byte) (EnrollRequest, error) {
+	var req EnrollRequest
+	if err := decodeJSON(data, &req); err != nil {
+		return EnrollRequest{}, err
+	}
+	for name, value := range map[string]string{
+		"node_id": req.NodeID, "public_key": req.PublicKey, "signature": req.Signature, "address": req.Address,
+	} {
+		if err := checkID(name, value); err != nil {
+			return EnrollRequest{}, err
+		}
+	}
+	return req, nil
+}
@@ -3,23 +3,56 @@ package config
 import (
 	"fmt"
 	"io"
+	"net"
+	"net/url"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/aldebaranode/syncguard/internal/constants"
+	"github.com/aldebaranode/syncguard/internal/cron"
+	"github.com/aldebaranode/syncguard/internal/deprecation"
+	"github.com/aldebaranode/syncguard/internal/policy"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 )
 
 // Config holds all configuration settings
 type Config struct {
-	Secret    string          `mapstructure:"secret"`
-	Node      NodeConfig      `mapstructure:"node"`
-	Validator ValidatorConfig `mapstructure:"validator"`
-	Peers     []PeerConfig    `mapstructure:"peers"`
-	CometBFT  CometBFTConfig  `mapstructure:"cometbft"`
-	Health    HealthConfig    `mapstructure:"health"`
-	Failover  FailoverConfig  `mapstructure:"failover"`
-	Logging   LoggingConfig   `mapstructure:"logging"`
+	Secret string `mapstructure:"secret"`
+	// PreviousSecret is still accepted while the cluster secret is rotated
+	// node by node; it is never used to sign or encrypt
+	PreviousSecret string              `mapstructure:"previous_secret"`
+	Node           NodeConfig          `mapstructure:"node"`
+	Validator      ValidatorConfig     `mapstructure:"validator"`
+	Execution      ExecutionConfig     `mapstructure:"execution"`
+	Peers          []PeerConfig        `mapstructure:"peers"`
+	CometBFT       CometBFTConfig      `mapstructure:"cometbft"`
+	Health         HealthConfig        `mapstructure:"health"`
+	Failover       FailoverConfig      `mapstructure:"failover"`
+	Lock           LockConfig          `mapstructure:"lock"`
+	Election       ElectionConfig      `mapstructure:"election"`
+	Maintenance    MaintenanceConfig   `mapstructure:"maintenance"`
+	Gatekeeper     GatekeeperConfig    `mapstructure:"gatekeeper"`
+	Identity       IdentityConfig      `mapstructure:"identity"`
+	TLS            TLSConfig           `mapstructure:"tls"`
+	Ping           PingConfig          `mapstructure:"ping"`
+	Alerts         AlertsConfig        `mapstructure:"alerts"`
+	ErrorTracking  ErrorTrackingConfig `mapstructure:"error_tracking"`
+	SelfMonitor    SelfMonitorConfig   `mapstructure:"self_monitor"`
+	History        HistoryConfig       `mapstructure:"history"`
+	Admin          AdminConfig         `mapstructure:"admin"`
+	PeerAPI        PeerAPIConfig       `mapstructure:"peer_api"`
+	Witness        WitnessConfig       `mapstructure:"witness"`
+	ColdStandby    ColdStandbyConfig   `mapstructure:"cold_standby"`
+	Drill          DrillConfig         `mapstructure:"drill"`
+	Chain          ChainConfig         `mapstructure:"chain"`
+	Group          GroupConfig         `mapstructure:"group"`
+	Invariants     InvariantsConfig    `mapstructure:"invariants"`
+	Signer         SignerConfig        `mapstructure:"signer"`
+	Logging        LoggingConfig       `mapstructure:"logging"`
 }
 
 // ValidatorConfig controls the managed validator node process
@@ -31,8 +64,63 @@ type ValidatorConfig struct {
 	Container    string                    `mapstructure:"container"`
 	ComposeFile  string                    `mapstructure:"compose_file"`
 	Service      string                    `mapstructure:"service"`
-	StopTimeout  float64                   `mapstructure:"stop_timeout"`
-	RestartDelay float64                   `mapstructure:"restart_delay"`
+	StopTimeout  Seconds                   `mapstructure:"stop_timeout"`
+	RestartDelay Seconds                   `mapstructure:"restart_delay"`
+	// DependsOn names services the consensus client starts after
+	DependsOn []string        `mapstructure:"depends_on"`
+	Services  []ServiceConfig `mapstructure:"services"`
+	// HealthCmd is a shell command whose exit code counts toward node
+	// health (0 is healthy); it runs in every mode, even without a managed
+	// node, and is killed after HealthCmdTimeout seconds
+	HealthCmd        string           `mapstructure:"health_cmd"`
+	HealthCmdTimeout Seconds          `mapstructure:"health_cmd_timeout"`
+	Activation       ActivationConfig `mapstructure:"activation"`
+}
+
+// ActivationConfig selects how the node picks up a swapped key: "restart"
+// restarts it, "signal" sends Signal to the consensus client, and "service"
+// restarts only the named entry of services, such as a remote signer the
+// node reconnects to over privval. Unless the node reports the new key
+// within Timeout seconds, it is restarted after all.
+type ActivationConfig struct {
+	Method  string  `mapstructure:"method"`
+	Signal  string  `mapstructure:"signal"`
+	Service string  `mapstructure:"service"`
+	Timeout Seconds `mapstructure:"timeout"`
+}
+
+// ServiceConfig is an extra process managed alongside the consensus client,
+// such as a remote signer sidecar. Services start after the ones they
+// depend on and stop before them; "consensus" names the consensus client
+// and "execution" the managed execution client.
+type ServiceConfig struct {
+	Name        string                    `mapstructure:"name"`
+	Mode        constants.NodeManagerType `mapstructure:"mode"`
+	Binary      string                    `mapstructure:"binary"`
+	Args        []string                  `mapstructure:"args"`
+	Container   string                    `mapstructure:"container"`
+	ComposeFile string                    `mapstructure:"compose_file"`
+	Service     string                    `mapstructure:"service"`
+	DependsOn   []string                  `mapstructure:"depends_on"`
+}
+
+// ExecutionConfig pairs the consensus client with an EVM execution client
+// (Story, ethermint-style chains). With rpc_url set, its sync state counts
+// toward node health; engine_url and jwt_secret_path add an authenticated
+// Engine API check. With managed (and validator.enabled) the execution
+// client is started before and stopped after the consensus client, and
+// both are restarted together.
+type ExecutionConfig struct {
+	RPCURL        string                    `mapstructure:"rpc_url"`
+	EngineURL     string                    `mapstructure:"engine_url"`
+	JWTSecretPath string                    `mapstructure:"jwt_secret_path"`
+	Managed       bool                      `mapstructure:"managed"`
+	Mode          constants.NodeManagerType `mapstructure:"mode"`
+	Binary        string                    `mapstructure:"binary"`
+	Args          []string                  `mapstructure:"args"`
+	Container     string                    `mapstructure:"container"`
+	ComposeFile   string                    `mapstructure:"compose_file"`
+	Service       string                    `mapstructure:"service"`
 }
 
 // NodeConfig identifies this node
@@ -41,71 +129,814 @@ type NodeConfig struct {
 	Role      constants.NodeStatus `mapstructure:"role"`
 	IsPrimary bool                 `mapstructure:"is_primary"`
 	Port      int                  `mapstructure:"port"`
+	DataDir   string               `mapstructure:"data_dir"`
+	// AdvertiseAddress is where peers should call this node, as host:port
+	// or URL, when that is not the address it listens on: behind NAT, on
+	// an overlay network or on one of several interfaces
+	AdvertiseAddress string `mapstructure:"advertise_address"`
+	// Instance names this syncguard process among others on the same host,
+	// e.g. one per chain. It namespaces the lock file, the default data
+	// directory, metrics and admin API routes; "" keeps them as they are.
+	Instance string `mapstructure:"instance"`
+	// MonitorOnly turns automation off: the node checks health, scores
+	// readiness and alerts, but never swaps or transfers the key, starts
+	// or restarts the validator, writes the validator state or changes role
+	MonitorOnly bool `mapstructure:"monitor_only"`
+	// AfterReboot is how a node configured active starts after its host
+	// rebooted: "reconcile" checks its data and peers and starts passive
+	// unless they confirm it, "trust" starts as after a plain restart
+	AfterReboot string `mapstructure:"after_reboot"`
 }
 
 // PeerConfig defines a peer node
 type PeerConfig struct {
-	ID      string `mapstructure:"id"`
-	Address string `mapstructure:"address"`
+	ID        string `mapstructure:"id"`
+	Address   string `mapstructure:"address"`
+	PublicKey string `mapstructure:"public_key"`
+	// AdminURL is the peer's admin API base URL, used by cluster commands
+	AdminURL string `mapstructure:"admin_url"`
+	// Priority orders the peers for promotion: on failover the healthy
+	// passive peer with the highest priority takes over, and peers of
+	// equal priority go in the order they are listed
+	Priority int `mapstructure:"priority"`
 }
 
-// CometBFTConfig holds CometBFT consensus layer settings
+// CometBFTConfig holds CometBFT consensus layer settings.
+// Key files (the key, its backup and the parked real key) must be mode
+// 0600 and owned by key_owner, by default the user syncguard runs as;
+// insecure_key_files says whether to fix, warn about or refuse others.
+// StateFormat is the format of the signer's last-sign state at state_path:
+// "cometbft-json" (priv_validator_state.json) or "tmkms-toml".
 type CometBFTConfig struct {
-	RPCURL     string `mapstructure:"rpc_url"`
-	KeyPath    string `mapstructure:"key_path"`
-	StatePath  string `mapstructure:"state_path"`
-	BackupPath string `mapstructure:"backup_path"`
+	RPCURL           string `mapstructure:"rpc_url"`
+	KeyPath          string `mapstructure:"key_path"`
+	StatePath        string `mapstructure:"state_path"`
+	BackupPath       string `mapstructure:"backup_path"`
+	KeyOwner         string `mapstructure:"key_owner"`
+	InsecureKeyFiles string `mapstructure:"insecure_key_files"`
+	StateFormat      string `mapstructure:"state_format"`
+	// Artifacts are other files handed over with the key
+	Artifacts []ArtifactConfig `mapstructure:"artifacts"`
+}
+
+// ArtifactConfig is a file the active node hands over with the validator
+// key, such as node_key.json or addrbook.json. The releasing node sends
+// it on failover, and the primary fetches it on failback; it is written
+// before the node restarts. With encrypt it travels sealed with the
+// cluster secret.
+type ArtifactConfig struct {
+	Name    string `mapstructure:"name"`
+	Path    string `mapstructure:"path"`
+	Encrypt bool   `mapstructure:"encrypt"`
 }
 
 // HealthConfig controls health checking behavior
 type HealthConfig struct {
-	Interval float64 `mapstructure:"interval"`
-	MinPeers int     `mapstructure:"min_peers"`
-	Timeout  float64 `mapstructure:"timeout"`
+	Interval          Seconds          `mapstructure:"interval"`
+	MinPeers          int              `mapstructure:"min_peers"`
+	Timeout           Seconds          `mapstructure:"timeout"`
+	SlowLatency       Seconds          `mapstructure:"slow_latency"`
+	MaxInterval       Seconds          `mapstructure:"max_interval"`
+	ProbePeersOnStart bool             `mapstructure:"probe_peers_on_start"`
+	Trend             TrendConfig      `mapstructure:"trend"`
+	Heartbeat         HeartbeatConfig  `mapstructure:"heartbeat"`
+	Host              HostConfig       `mapstructure:"host"`
+	Policy            PolicyConfig     `mapstructure:"policy"`
+	StateChurn        StateChurnConfig `mapstructure:"state_churn"`
+	// Unknown decides what a check that passed but could not tell whether
+	// the node signs (no validator key in /status) counts as: "pass" as
+	// healthy, "fail" towards failover.retry_attempts, or "hold" as
+	// neither, keeping the failure count where it is
+	Unknown string `mapstructure:"unknown"`
+}
+
+// StateChurnConfig samples the validator state file every Interval
+// seconds and measures, over the last Window seconds, how often the
+// signer writes it and how fast its height advances. On the active node a
+// height rate below DropRatio of its usual rate raises a "state_churn"
+// alert; with Enforce it also counts as a failed health check.
+type StateChurnConfig struct {
+	Enabled   bool    `mapstructure:"enabled"`
+	Interval  Seconds `mapstructure:"interval"`
+	Window    Seconds `mapstructure:"window"`
+	DropRatio float64 `mapstructure:"drop_ratio"`
+	Enforce   bool    `mapstructure:"enforce"`
+}
+
+// PolicyConfig replaces the built-in health and takeover conditions with
+// expressions over the facts each health check collects, for example
+// "height_lag < 5 && peers >= 3 && !catching_up". Healthy decides whether
+// the node counts as healthy; Takeover decides whether a passive node is
+// ready to take over. Empty keeps the built-in condition.
+type PolicyConfig struct {
+	Healthy  string `mapstructure:"healthy"`
+	Takeover string `mapstructure:"takeover"`
+}
+
+// HealthPolicyFacts are the facts health.policy.healthy can use
+var HealthPolicyFacts = []string{
+	"healthy", "catching_up", "height", "tip", "height_lag", "peers", "min_peers",
+	"rpc_ok", "execution_ok", "command_ok", "latency",
+}
+
+// TakeoverPolicyFacts are the facts health.policy.takeover can use: the
+// health facts plus the standby's readiness
+var TakeoverPolicyFacts = append(append([]string{}, HealthPolicyFacts...), "running", "score", "ready")
+
+// HostConfig samples the host's load, memory, swap and iowait on every
+// health check. While active, a signal whose last few samples are all at
+// its limit, or that trends toward it with health.trend enabled, raises a
+// "degrading" pre-alert recommending a planned handoff. MaxLoad is the
+// 1-minute load average per CPU, MaxMemoryUsed and MaxIOWait are
+// fractions, and MaxSwapRate is pages swapped in and out per second; a
+// negative limit turns its signal off.
+type HostConfig struct {
+	Enabled       bool    `mapstructure:"enabled"`
+	MaxLoad       float64 `mapstructure:"max_load"`
+	MaxMemoryUsed float64 `mapstructure:"max_memory_used"`
+	MaxSwapRate   float64 `mapstructure:"max_swap_rate"`
+	MaxIOWait     float64 `mapstructure:"max_iowait"`
+}
+
+// HeartbeatConfig makes the active node push its own health report to the
+// passives after every check. A passive compares each report with the
+// chain tip its own node sees: a report of healthy while more than MaxLag
+// blocks behind, or of unhealthy while at the tip, is a disagreement. A
+// passive that hears nothing for StaleAfter seconds raises an alert. With
+// Enforce, the active counts a disagreement its passive reports as a
+// failed health check.
+type HeartbeatConfig struct {
+	Enabled    bool    `mapstructure:"enabled"`
+	MaxLag     int64   `mapstructure:"max_lag"`
+	StaleAfter Seconds `mapstructure:"stale_after"`
+	Enforce    bool    `mapstructure:"enforce"`
 }
 
-// FailoverConfig controls failover behavior
+// TrendConfig enables "degrading" pre-alerts: the last Samples health
+// samples of each check are fitted to a line, and a check whose line
+// crosses its failure threshold within Horizon seconds is reported while
+// it is still passing.
+type TrendConfig struct {
+	Enabled bool    `mapstructure:"enabled"`
+	Samples int     `mapstructure:"samples"`
+	Horizon Seconds `mapstructure:"horizon"`
+}
+
+// FailoverConfig controls failover behavior. The primary fails back only
+// after grace_period seconds of continuous health while within
+// failback_max_lag blocks of the chain tip; each failed failback doubles
+// the hold-off before the next attempt, from failback_holdoff up to
+// failback_max_holdoff seconds. With sticky_active the standby keeps the
+// active role until an operator fails back. With preheat the passive keeps
+// its node running and synced on the mock key, so a takeover is only a key
+// swap and a restart; a standby within standby_max_lag blocks of the active
+// node counts as ready.
 type FailoverConfig struct {
-	RetryAttempts     int     `mapstructure:"retry_attempts"`
-	GracePeriod       float64 `mapstructure:"grace_period"`
-	StateSyncInterval float64 `mapstructure:"state_sync_interval"`
+	RetryAttempts      int             `mapstructure:"retry_attempts"`
+	GracePeriod        Seconds         `mapstructure:"grace_period"`
+	StateSyncInterval  Seconds         `mapstructure:"state_sync_interval"`
+	FailbackMaxLag     int64           `mapstructure:"failback_max_lag"`
+	FailbackHoldOff    Seconds         `mapstructure:"failback_holdoff"`
+	FailbackMaxHoldOff Seconds         `mapstructure:"failback_max_holdoff"`
+	StickyActive       bool            `mapstructure:"sticky_active"`
+	Preheat            bool            `mapstructure:"preheat"`
+	StandbyMaxLag      int64           `mapstructure:"standby_max_lag"`
+	Approval           ApprovalConfig  `mapstructure:"approval"`
+	LinkCheck          LinkCheckConfig `mapstructure:"link_check"`
+	// AllowPlaintextKey accepts a validator key a peer transfers without
+	// sealing it with the cluster secret, as nodes did before
+	AllowPlaintextKey bool `mapstructure:"allow_plaintext_key"`
+	// ShutdownHandoff hands over to the standby when syncguard is stopped
+	// on the active node, waiting at most deadline seconds
+	ShutdownHandoff ShutdownHandoffConfig `mapstructure:"shutdown_handoff"`
+	// PreHooks run before each change of role, PostHooks after it
+	PreHooks  []HookConfig `mapstructure:"pre_hooks"`
+	PostHooks []HookConfig `mapstructure:"post_hooks"`
+}
+
+// HookConfig is an executable run before or after a change of role.
+// Command is the executable and its arguments, run without a shell; the
+// hook is killed after timeout seconds.
+type HookConfig struct {
+	Name    string   `mapstructure:"name"`
+	Command []string `mapstructure:"command"`
+	Timeout Seconds  `mapstructure:"timeout"`
+}
+
+// ShutdownHandoffConfig is the handover made when the active node's
+// syncguard is asked to stop
+type ShutdownHandoffConfig struct {
+	Enabled  bool    `mapstructure:"enabled"`
+	Deadline Seconds `mapstructure:"deadline"`
+}
+
+// LinkCheckConfig probes the link to the standby before an operator
+// handoff or drill: the round trip and the time to upload probe_bytes give
+// an estimate of how long the key, artifacts and state take to cross,
+// compared against peer_api.timeouts.key and .state. When a transfer would
+// not fit, action warn alerts and goes on, abort refuses the handover.
+// Approval requests list a slow link among their problems; automatic
+// failover never waits for the probe.
+type LinkCheckConfig struct {
+	Enabled    bool   `mapstructure:"enabled"`
+	ProbeBytes Size   `mapstructure:"probe_bytes"`
+	Action     string `mapstructure:"action"`
+}
+
+// ApprovalConfig holds automatic failover for an operator. Detection and
+// preparation run as usual, but the handover waits for approval through
+// the admin API, or until auto_approve_after seconds pass when set.
+type ApprovalConfig struct {
+	Enabled          bool    `mapstructure:"enabled"`
+	AutoApproveAfter Seconds `mapstructure:"auto_approve_after"`
+	// ApproveURL is linked from the approval alert; {id} is replaced by
+	// the request ID, e.g. for a chat button behind an approval relay
+	ApproveURL string `mapstructure:"approve_url"`
+}
+
+// LockConfig selects the lock backend and what happens when it is unreachable.
+// The active node keeps signing for grace_ttl seconds after the backend goes
+// away, then follows on_grace_expired; passive nodes refuse to take over
+// until the backend is reachable again.
+//
+// Backend file is a lock file next to the validator state, which only
+// excludes nodes sharing a filesystem. Backends etcd and consul hold a key
+// in a cluster both sites reach, with a session that expires ttl seconds after
+// the holder stops renewing it.
+type LockConfig struct {
+	Backend        string  `mapstructure:"backend"`
+	CheckInterval  Seconds `mapstructure:"check_interval"`
+	GraceTTL       Seconds `mapstructure:"grace_ttl"`
+	OnGraceExpired string  `mapstructure:"on_grace_expired"`
+	// Endpoints are the etcd or Consul HTTP(S) URLs, tried in order
+	Endpoints []string `mapstructure:"endpoints"`
+	Key       string   `mapstructure:"key"`
+	TTL       Seconds  `mapstructure:"ttl"`
+	// Token is a Consul ACL token; Username and Password log in to etcd
+	Token    string `mapstructure:"token"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	CAFile   string `mapstructure:"ca_file"`
+	CertFile string `mapstructure:"cert_file"`
+	KeyFile  string `mapstructure:"key_file"`
+}
+
+// ElectionConfig makes the active role a lease granted by a majority of
+// the nodes (this one and every peer), so a node cut off from the majority
+// stops signing once its lease runs out and cannot take over. The active
+// node renews its lease every renew_interval seconds; leases last lease_ttl.
+type ElectionConfig struct {
+	Enabled       bool    `mapstructure:"enabled"`
+	LeaseTTL      Seconds `mapstructure:"lease_ttl"`
+	RenewInterval Seconds `mapstructure:"renew_interval"`
+}
+
+// MaintenanceConfig names external flags that mark this host under
+// maintenance. While one is set the node refuses to become active.
+type MaintenanceConfig struct {
+	// File is a path whose existence marks the host under maintenance
+	File       string                      `mapstructure:"file"`
+	Consul     ConsulMaintenanceConfig     `mapstructure:"consul"`
+	Kubernetes KubernetesMaintenanceConfig `mapstructure:"kubernetes"`
+	// OnError is "allow" or "refuse": whether a flag that cannot be read
+	// lets the node become active
+	OnError string  `mapstructure:"on_error"`
+	Timeout Seconds `mapstructure:"timeout"`
+}
+
+// ConsulMaintenanceConfig checks Consul node maintenance mode
+// (consul maint -enable)
+type ConsulMaintenanceConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Address string `mapstructure:"address"`
+	// Node is this host's Consul node name; defaults to the hostname
+	Node  string `mapstructure:"node"`
+	Token string `mapstructure:"token"`
+}
+
+// KubernetesMaintenanceConfig checks the Kubernetes node this host runs
+// on for cordoning or an annotation
+type KubernetesMaintenanceConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// APIURL defaults to the in-cluster service address
+	APIURL string `mapstructure:"api_url"`
+	// Node defaults to $NODE_NAME
+	Node string `mapstructure:"node"`
+	// Annotation marks maintenance when set to "true"
+	Annotation string `mapstructure:"annotation"`
+	// Cordon counts a cordoned (unschedulable) node as under maintenance
+	Cordon    bool   `mapstructure:"cordon"`
+	TokenFile string `mapstructure:"token_file"`
+	CAFile    string `mapstructure:"ca_file"`
+}
+
+// GatekeeperConfig controls the signing watermark file shared with the node
+type GatekeeperConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Path    string `mapstructure:"path"`
+}
+
+// IdentityConfig controls per-node ed25519 signing of peer messages
+type IdentityConfig struct {
+	Enabled     bool    `mapstructure:"enabled"`
+	KeyPath     string  `mapstructure:"key_path"`
+	KeyringPath string  `mapstructure:"keyring_path"`
+	MaxSkew     Seconds `mapstructure:"max_skew"`
+}
+
+// TLSConfig locates the cluster CA and this node's certificate. With
+// Enabled, peers talk mutual TLS: each side presents a certificate the CA
+// signed, and a peer's must name its node ID.
+type TLSConfig struct {
+	Enabled   bool   `mapstructure:"enabled"`
+	CAFile    string `mapstructure:"ca_file"`
+	CAKeyFile string `mapstructure:"ca_key_file"`
+	CertFile  string `mapstructure:"cert_file"`
+	KeyFile   string `mapstructure:"key_file"`
+}
+
+// PingConfig controls the outbound dead man's switch ping
+type PingConfig struct {
+	URL      string  `mapstructure:"url"`
+	Interval Seconds `mapstructure:"interval"`
+	Timeout  Seconds `mapstructure:"timeout"`
+}
+
+// AlertsConfig lists the sinks that receive failover and health events
+type AlertsConfig struct {
+	Sinks       []AlertSinkConfig `mapstructure:"sinks"`
+	DedupWindow Seconds           `mapstructure:"dedup_window"`
+}
+
+// AlertSinkConfig configures one alert destination.
+// Type selects which of the type-specific fields apply.
+type AlertSinkConfig struct {
+	Type        string           `mapstructure:"type"`
+	Name        string           `mapstructure:"name"`
+	MinSeverity string           `mapstructure:"min_severity"`
+	Template    string           `mapstructure:"template"`
+	URL         string           `mapstructure:"url"`
+	SMTP        SMTPConfig       `mapstructure:"smtp"`
+	SNMP        SNMPConfig       `mapstructure:"snmp"`
+	StatusPage  StatusPageConfig `mapstructure:"status_page"`
+	Telegram    TelegramConfig   `mapstructure:"telegram"`
+	// Events limits the sink to these event types; empty sends them all
+	Events []string `mapstructure:"events"`
+}
+
+// SMTPConfig configures the email alert sink
+type SMTPConfig struct {
+	Host     string   `mapstructure:"host"`
+	Port     int      `mapstructure:"port"`
+	Username string   `mapstructure:"username"`
+	Password string   `mapstructure:"password"`
+	From     string   `mapstructure:"from"`
+	To       []string `mapstructure:"to"`
+	Subject  string   `mapstructure:"subject"`
+}
+
+// SNMPConfig configures the SNMPv2c trap alert sink
+type SNMPConfig struct {
+	Target        string `mapstructure:"target"`
+	Community     string `mapstructure:"community"`
+	EnterpriseOID string `mapstructure:"enterprise_oid"`
+}
+
+// StatusPageConfig configures the status page sink. Provider is
+// statuspage or instatus, which take the page, the validator's component
+// and an API key, or webhook, which posts to the sink's url.
+type StatusPageConfig struct {
+	Provider    string `mapstructure:"provider"`
+	APIURL      string `mapstructure:"api_url"`
+	APIKey      string `mapstructure:"api_key"`
+	PageID      string `mapstructure:"page_id"`
+	ComponentID string `mapstructure:"component_id"`
+}
+
+// TelegramConfig configures the Telegram sink: the bot that sends the
+// alerts and the chat they go to
+type TelegramConfig struct {
+	BotToken string `mapstructure:"bot_token"`
+	ChatID   string `mapstructure:"chat_id"`
+	APIURL   string `mapstructure:"api_url"`
+}
+
+// ErrorTrackingConfig sends panics and Error-level log lines to Sentry,
+// given its DSN, or as JSON to URL for any other tracker. Every event is
+// tagged with the node, Cluster and Environment so failures across a fleet
+// of validators are grouped in one place. Unset DSN and URL disable it.
+type ErrorTrackingConfig struct {
+	DSN         string  `mapstructure:"dsn"`
+	URL         string  `mapstructure:"url"`
+	Cluster     string  `mapstructure:"cluster"`
+	Environment string  `mapstructure:"environment"`
+	Timeout     Seconds `mapstructure:"timeout"`
+}
+
+// SelfMonitorConfig sets thresholds for syncguard's own resource usage.
+// MaxMemoryMB is the deprecated spelling of MaxMemory.
+type SelfMonitorConfig struct {
+	Interval      Seconds `mapstructure:"interval"`
+	MaxMemory     Size    `mapstructure:"max_memory"`
+	MaxMemoryMB   float64 `mapstructure:"max_memory_mb"`
+	MaxGoroutines int     `mapstructure:"max_goroutines"`
+	MaxOpenFDs    int     `mapstructure:"max_open_fds"`
+	Pprof         bool    `mapstructure:"pprof"`
+}
+
+// HistoryConfig locates the event history file. MaxSizeMB is the
+// deprecated spelling of MaxSize.
+type HistoryConfig struct {
+	Path      string  `mapstructure:"path"`
+	MaxSize   Size    `mapstructure:"max_size"`
+	MaxSizeMB float64 `mapstructure:"max_size_mb"`
+}
+
+// AdminConfig controls the local operator API.
+// The admin server only runs when listen is set.
+type AdminConfig struct {
+	Listen string `mapstructure:"listen"`
+	Token  string `mapstructure:"token"`
+}
+
+// PeerAPIConfig tunes the HTTP API served to peers and operators. With
+// compression, peer request bodies of at least compress_min_bytes are
+// gzipped and responses are gzipped for clients that accept it. Bodies
+// larger than the max_*_bytes limits, compressed or not, are refused.
+// Encoding selects how heartbeats, state, key transfers and transitions
+// are sent: "json" or "protobuf" envelopes. Protocol selects how peer
+// requests travel: "http", or "grpc" calls on the same port, which can
+// also stream the validator state. Nodes accept both.
+type PeerAPIConfig struct {
+	CacheTTL         Seconds `mapstructure:"cache_ttl"`
+	Compression      bool    `mapstructure:"compression"`
+	CompressMinBytes Size    `mapstructure:"compress_min_bytes"`
+	MaxRequestBytes  Size    `mapstructure:"max_request_bytes"`
+	MaxResponseBytes Size    `mapstructure:"max_response_bytes"`
+	Encoding         string  `mapstructure:"encoding"`
+	Protocol         string  `mapstructure:"protocol"`
+	// DNSRefresh is how often peer hostnames are re-resolved. When a peer's
+	// addresses change, pooled connections to it are dropped.
+	DNSRefresh Seconds `mapstructure:"dns_refresh"`
+	// Timeouts bounds peer requests by what they carry
+	Timeouts PeerTimeoutsConfig `mapstructure:"timeouts"`
+	// CapabilityTTL is how long capabilities negotiated in a handshake are
+	// trusted; a peer's restart drops them sooner
+	CapabilityTTL Seconds `mapstructure:"capability_ttl"`
+	// BroadcastQuorum is how many peers a message sent to all of them must
+	// reach: 'all', 'majority' or 'any'
+	BroadcastQuorum string `mapstructure:"broadcast_quorum"`
+}
+
+// PeerTimeoutsConfig bounds each kind of peer request, in seconds, from
+// dialing to reading the last byte of the answer. Control-plane calls fail
+// fast so an unreachable peer does not hold up the health loop or a
+// failover; key and state transfers get longer for slow links.
+type PeerTimeoutsConfig struct {
+	Heartbeat Seconds `mapstructure:"heartbeat"`
+	// Probe covers /health probes, handshakes and enrollment
+	Probe Seconds `mapstructure:"probe"`
+	// Notify covers failover and failback notifications, which the peer
+	// answers once it has taken over or released
+	Notify Seconds `mapstructure:"notify"`
+	State  Seconds `mapstructure:"state"`
+	Key    Seconds `mapstructure:"key"`
+}
+
+// WitnessConfig configures `syncguard witness` (or node.role: witness), an
+// arbiter for a third region that runs without a key or node. It observes
+// the peers and answers takeover votes.
+//
+// On a validator node, Address names the witness to ask: with it set, the
+// node takes over from a peer only once the witness grants its vote.
+type WitnessConfig struct {
+	Listen          string  `mapstructure:"listen"`
+	ObserveInterval Seconds `mapstructure:"observe_interval"`
+	StaleAfter      Seconds `mapstructure:"stale_after"`
+	LeaseTTL        Seconds `mapstructure:"lease_ttl"`
+	Address         string  `mapstructure:"address"`
+	Timeout         Seconds `mapstructure:"timeout"`
+}
+
+// ColdStandbyConfig ships the validator state and key to a standby that
+// runs no SyncGuard, for teams that cannot run the daemon on both ends.
+// With transport ssh the files go to remote_dir on host; with transport
+// command, upload_command stores each one (e.g. in object storage), with
+// {file} and {name} replaced by the local file and its name. The standby is
+// promoted by hand with `syncguard activate-standby`: over SSH the files are
+// installed at remote_key_path and remote_state_path before
+// activate_command runs on the standby; with transport command,
+// activate_command runs locally and must do the installing. Peers are not
+// used in this topology.
+type ColdStandbyConfig struct {
+	Enabled         bool    `mapstructure:"enabled"`
+	Transport       string  `mapstructure:"transport"`
+	Interval        Seconds `mapstructure:"interval"`
+	Timeout         Seconds `mapstructure:"timeout"`
+	Host            string  `mapstructure:"host"`
+	Port            int     `mapstructure:"port"`
+	IdentityFile    string  `mapstructure:"identity_file"`
+	RemoteDir       string  `mapstructure:"remote_dir"`
+	RemoteKeyPath   string  `mapstructure:"remote_key_path"`
+	RemoteStatePath string  `mapstructure:"remote_state_path"`
+	UploadCommand   string  `mapstructure:"upload_command"`
+	ActivateCommand string  `mapstructure:"activate_command"`
+}
+
+// ChainConfig controls discovery of our validator's on-chain metadata.
+// Voting power comes from the CometBFT RPC; operator address, jail status
+// and slashing parameters need the Cosmos SDK REST (LCD) endpoint.
+//
+// While the active node is down, downtime alerts turn critical once
+// escalate_fill of the allowed missed blocks is used, or half of that for
+// validators holding at least high_power_share of the voting power.
+type ChainConfig struct {
+	LCDURL          string  `mapstructure:"lcd_url"`
+	RefreshInterval Seconds `mapstructure:"refresh_interval"`
+	EscalateFill    float64 `mapstructure:"escalate_fill"`
+	HighPowerShare  float64 `mapstructure:"high_power_share"`
+	// BudgetAlertFill warns once the missed-block window is this full
+	BudgetAlertFill float64 `mapstructure:"budget_alert_fill"`
+	// BlockTime (seconds) estimates missed blocks when the LCD is unreachable
+	BlockTime       Seconds               `mapstructure:"block_time"`
+	Signing         SigningConfig         `mapstructure:"signing"`
+	DoubleSignGuard DoubleSignGuardConfig `mapstructure:"double_sign_guard"`
+}
+
+// SigningConfig tails recent blocks over the CometBFT RPC to see whether
+// our validator's precommits are in them: whether it is really signing,
+// whatever the health checks say. Window is the number of recent blocks
+// kept. Once missed_blocks blocks in a row lack our precommit while this
+// node is active, it alerts; with enforce, each health check also counts
+// as failed until it signs again.
+type SigningConfig struct {
+	Enabled      bool    `mapstructure:"enabled"`
+	Interval     Seconds `mapstructure:"interval"`
+	Window       int     `mapstructure:"window"`
+	MissedBlocks int     `mapstructure:"missed_blocks"`
+	Enforce      bool    `mapstructure:"enforce"`
+}
+
+// DoubleSignGuardConfig has a node read the last depth blocks over the
+// CometBFT RPC before it takes over or fails back. It refuses while a
+// block carries our validator's precommit above its local validator
+// state, or while the RPC cannot tell.
+type DoubleSignGuardConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	Depth   int  `mapstructure:"depth"`
+}
+
+// GroupConfig links this (provider) instance to the SyncGuard instances of
+// consumer chains on the same host, e.g. ICS consumers with assigned keys.
+// With cascade, a failover of this instance hands off every active member,
+// one at a time in the listed order.
+type GroupConfig struct {
+	Name    string              `mapstructure:"name"`
+	Cascade bool                `mapstructure:"cascade"`
+	OnError string              `mapstructure:"on_error"`
+	Timeout Seconds             `mapstructure:"timeout"`
+	Members []GroupMemberConfig `mapstructure:"members"`
+}
+
+// GroupMemberConfig is a linked instance, reached over its admin API.
+// The token defaults to admin.token.
+type GroupMemberConfig struct {
+	ID       string `mapstructure:"id"`
+	AdminURL string `mapstructure:"admin_url"`
+	Token    string `mapstructure:"token"`
+}
+
+// InvariantsConfig checks every Interval seconds, against every peer's
+// answer, that exactly one node has the real key and it is the active
+// node holding the lock, and that no validator state moves backwards. A
+// violation seen Confirmations checks in a row raises a critical
+// "invariant" alert; with Fence, the node that should not sign stops.
+type InvariantsConfig struct {
+	Enabled       bool    `mapstructure:"enabled"`
+	Interval      Seconds `mapstructure:"interval"`
+	Confirmations int     `mapstructure:"confirmations"`
+	Fence         bool    `mapstructure:"fence"`
+}
+
+// SignerConfig makes syncguard the node's remote signer: the node runs
+// with priv_validator_laddr set to Address, and syncguard dials it and
+// signs with the key at cometbft.key_path while this node is active.
+// Every node keeps the key, so a failover moves no key file.
+type SignerConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Address is the node's priv_validator_laddr, tcp:// or unix://
+	Address string `mapstructure:"address"`
+	// ChainID must match the node's; requests for another chain are refused
+	ChainID string  `mapstructure:"chain_id"`
+	Timeout Seconds `mapstructure:"timeout"`
+}
+
+// DrillConfig schedules automatic failover drills.
+// Scheduled drills run only when every node is healthy and never inside a
+// blackout window; an empty schedule disables them.
+type DrillConfig struct {
+	Schedule  string           `mapstructure:"schedule"`
+	Duration  Seconds          `mapstructure:"duration"`
+	Blackouts []BlackoutConfig `mapstructure:"blackouts"`
+}
+
+// BlackoutConfig is a period in which no drill may run: either a fixed
+// range (start/end, RFC 3339) or a recurring one (cron start plus duration
+// in seconds)
+type BlackoutConfig struct {
+	Reason   string  `mapstructure:"reason"`
+	Start    string  `mapstructure:"start"`
+	End      string  `mapstructure:"end"`
+	Cron     string  `mapstructure:"cron"`
+	Duration Seconds `mapstructure:"duration"`
 }
 
 // LoggingConfig controls logging behavior
 type LoggingConfig struct {
-	Level   string `mapstructure:"level"`
-	File    string `mapstructure:"file"`
+	Level string `mapstructure:"level"`
+	File  string `mapstructure:"file"`
+	// Format is "text" or "json", one object per line
+	Format  string `mapstructure:"format"`
 	Verbose bool   `mapstructure:"verbose"`
+	// Outputs ship log lines elsewhere too, in addition to the file and
+	// stdout
+	Outputs []LogOutputConfig `mapstructure:"outputs"`
 }
 
-// Load reads and parses the configuration file
+// LogOutputConfig configures one extra log destination. Type selects which
+// of the type-specific fields apply; Level, when set, sends only lines at
+// that level or above.
+type LogOutputConfig struct {
+	Type   string       `mapstructure:"type"`
+	Level  string       `mapstructure:"level"`
+	Syslog SyslogConfig `mapstructure:"syslog"`
+}
+
+// SyslogConfig sends log lines to a syslog collector as RFC 5424 messages.
+// Network is "udp", "tcp" or "tls"; Address defaults to port 514, or 6514
+// over TLS. CAFile verifies the collector instead of the system roots, and
+// CertFile and KeyFile authenticate this node to it.
+type SyslogConfig struct {
+	Address  string `mapstructure:"address"`
+	Network  string `mapstructure:"network"`
+	Facility string `mapstructure:"facility"`
+	AppName  string `mapstructure:"app_name"`
+	CAFile   string `mapstructure:"ca_file"`
+	CertFile string `mapstructure:"cert_file"`
+	KeyFile  string `mapstructure:"key_file"`
+}
+
+// SyslogFacilities are the facility names logging.outputs accepts, in
+// order of their RFC 5424 codes
+var SyslogFacilities = []string{
+	"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news",
+	"uucp", "cron", "authpriv", "ftp", "ntp", "security", "console", "solaris-cron",
+	"local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7",
+}
+
+// supportedFormats maps config file extensions to viper config types
+var supportedFormats = map[string]string{
+	".yaml": "yaml",
+	".yml":  "yaml",
+	".toml": "toml",
+	".json": "json",
+}
+
+// detectFormat returns the config type for a file based on its extension
+func detectFormat(path string) (string, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	format, ok := supportedFormats[ext]
+	if !ok {
+		return "", fmt.Errorf("unsupported config format %q (accepted: .yaml, .yml, .toml, .json)", ext)
+	}
+	return format, nil
+}
+
+// LoadOptions controls how the configuration file is parsed
+type LoadOptions struct {
+	// Lenient downgrades unknown config keys from an error to a warning
+	Lenient bool
+}
+
+// Load reads and parses the configuration file with strict key checking
 func Load(path string) (*Config, error) {
-	viper.SetConfigFile(path)
+	return LoadWithOptions(path, LoadOptions{})
+}
+
+// LoadWithOptions reads and parses the configuration file.
+// The format (YAML, TOML or JSON) is detected from the file extension;
+// all formats share the same keys, defaults and validation.
+func LoadWithOptions(path string, opts LoadOptions) (*Config, error) {
+	cfg, err := read(path, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	check := validate
+	if cfg.Node.Role == constants.NodeStatusWitness {
+		check = validateWitness
+	}
+	if err := check(cfg); err != nil {
+		return nil, fmt.Errorf("config validation error: %w", err)
+	}
+
+	initLogger(cfg)
+
+	return cfg, nil
+}
+
+// LoadWitness reads a witness config: the cluster secret, the witness id
+// (node.id), the peers to observe and the witness section. Node, CometBFT
+// and validator settings are not required.
+func LoadWitness(path string, opts LoadOptions) (*Config, error) {
+	cfg, err := read(path, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateWitness(cfg); err != nil {
+		return nil, fmt.Errorf("config validation error: %w", err)
+	}
+
+	initLogger(cfg)
+
+	return cfg, nil
+}
+
+// read parses a config file and applies defaults without validating it
+func read(path string, opts LoadOptions) (*Config, error) {
+	format, err := detectFormat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType(format)
 
 	// Enable environment variable overrides (SYNCGUARD_NODE_ID, etc.)
-	viper.SetEnvPrefix("SYNCGUARD")
-	viper.AutomaticEnv()
+	v.SetEnvPrefix("SYNCGUARD")
+	v.AutomaticEnv()
 
-	if err := viper.ReadInConfig(); err != nil {
+	if err := v.ReadInConfig(); err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	var cfg Config
-	if err := viper.Unmarshal(&cfg); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	// Compatibility shim: load the old Server/Communication schema in place
+	if IsLegacy(v.AllSettings()) {
+		migrated, changes, err := MigrateLegacy(v.AllSettings())
+		if err != nil {
+			return nil, fmt.Errorf("failed to migrate legacy config: %w", err)
+		}
+		for _, change := range changes {
+			deprecation.Record(deprecation.KindConfig, change.Old, change.New)
+		}
+		log.Warnf("Config %s uses the legacy schema, run 'syncguard config migrate' to upgrade it", path)
+
+		v = viper.New()
+		v.SetEnvPrefix("SYNCGUARD")
+		v.AutomaticEnv()
+		if err := v.MergeConfigMap(migrated); err != nil {
+			return nil, fmt.Errorf("failed to load migrated config: %w", err)
+		}
 	}
 
-	setDefaults(&cfg)
+	// Typos like retry_attemps would otherwise be silently ignored
+	if err := checkUnknownKeys(v.AllSettings()); err != nil {
+		if !opts.Lenient {
+			return nil, err
+		}
+		log.Warnf("Ignoring %v", err)
+	}
 
-	if err := validate(&cfg); err != nil {
-		return nil, fmt.Errorf("config validation error: %w", err)
+	var cfg Config
+	if err := v.Unmarshal(&cfg, viper.DecodeHook(decodeHook)); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
-	initLogger(&cfg)
+	migrateSizes(&cfg)
+	setDefaults(&cfg)
 
 	return &cfg, nil
 }
 
+// migrateSizes carries the deprecated *_mb keys over to their Size
+// replacements, which win when both are set
+func migrateSizes(cfg *Config) {
+	if cfg.History.MaxSizeMB != 0 {
+		deprecation.Record(deprecation.KindConfig, "history.max_size_mb", "history.max_size")
+		if cfg.History.MaxSize == 0 {
+			cfg.History.MaxSize = Size(cfg.History.MaxSizeMB * float64(Megabyte))
+		}
+	}
+	if cfg.SelfMonitor.MaxMemoryMB != 0 {
+		deprecation.Record(deprecation.KindConfig, "self_monitor.max_memory_mb", "self_monitor.max_memory")
+		if cfg.SelfMonitor.MaxMemory == 0 {
+			cfg.SelfMonitor.MaxMemory = Size(cfg.SelfMonitor.MaxMemoryMB * float64(Megabyte))
+		}
+	}
+}
+
 // setDefaults applies default values for missing fields
 func setDefaults(cfg *Config) {
 	if cfg.Node.Role == "" {
@@ -114,6 +945,12 @@ func setDefaults(cfg *Config) {
 	if cfg.Node.Port == 0 {
 		cfg.Node.Port = 8080
 	}
+	if cfg.Node.DataDir == "" {
+		cfg.Node.DataDir = filepath.Join("data", cfg.Node.Instance)
+	}
+	if cfg.Node.AfterReboot == "" {
+		cfg.Node.AfterReboot = "reconcile"
+	}
 	if cfg.Health.Interval == 0 {
 		cfg.Health.Interval = 5
 	}
@@ -123,6 +960,48 @@ func setDefaults(cfg *Config) {
 	if cfg.Health.Timeout == 0 {
 		cfg.Health.Timeout = 5
 	}
+	if cfg.Health.SlowLatency == 0 {
+		cfg.Health.SlowLatency = 1
+	}
+	if cfg.Health.MaxInterval == 0 {
+		cfg.Health.MaxInterval = cfg.Health.Interval * 4
+	}
+	if cfg.Health.Trend.Samples == 0 {
+		cfg.Health.Trend.Samples = 30
+	}
+	if cfg.Health.Trend.Horizon == 0 {
+		cfg.Health.Trend.Horizon = 300
+	}
+	if cfg.Health.Host.MaxLoad == 0 {
+		cfg.Health.Host.MaxLoad = 2
+	}
+	if cfg.Health.Host.MaxMemoryUsed == 0 {
+		cfg.Health.Host.MaxMemoryUsed = 0.9
+	}
+	if cfg.Health.Host.MaxSwapRate == 0 {
+		cfg.Health.Host.MaxSwapRate = 100
+	}
+	if cfg.Health.Host.MaxIOWait == 0 {
+		cfg.Health.Host.MaxIOWait = 0.2
+	}
+	if cfg.Health.Heartbeat.MaxLag == 0 {
+		cfg.Health.Heartbeat.MaxLag = 5
+	}
+	if cfg.Health.Heartbeat.StaleAfter == 0 {
+		cfg.Health.Heartbeat.StaleAfter = cfg.Health.Interval * 3
+	}
+	if cfg.Health.StateChurn.Interval == 0 {
+		cfg.Health.StateChurn.Interval = 1
+	}
+	if cfg.Health.StateChurn.Window == 0 {
+		cfg.Health.StateChurn.Window = 60
+	}
+	if cfg.Health.StateChurn.DropRatio == 0 {
+		cfg.Health.StateChurn.DropRatio = 0.5
+	}
+	if cfg.Health.Unknown == "" {
+		cfg.Health.Unknown = "pass"
+	}
 	if cfg.Failover.RetryAttempts == 0 {
 		cfg.Failover.RetryAttempts = 3
 	}
@@ -132,12 +1011,116 @@ func setDefaults(cfg *Config) {
 	if cfg.Failover.StateSyncInterval == 0 {
 		cfg.Failover.StateSyncInterval = 5
 	}
+	if cfg.Failover.FailbackMaxLag == 0 {
+		cfg.Failover.FailbackMaxLag = 5
+	}
+	if cfg.Failover.StandbyMaxLag == 0 {
+		cfg.Failover.StandbyMaxLag = 5
+	}
+	if cfg.Failover.LinkCheck.ProbeBytes == 0 {
+		cfg.Failover.LinkCheck.ProbeBytes = 256 * Kilobyte
+	}
+	if cfg.Failover.LinkCheck.Action == "" {
+		cfg.Failover.LinkCheck.Action = "warn"
+	}
+	if cfg.Failover.ShutdownHandoff.Deadline == 0 {
+		cfg.Failover.ShutdownHandoff.Deadline = 30
+	}
+	for _, hooks := range [][]HookConfig{cfg.Failover.PreHooks, cfg.Failover.PostHooks} {
+		for i := range hooks {
+			if hooks[i].Timeout == 0 {
+				hooks[i].Timeout = 30
+			}
+		}
+	}
+	if cfg.Failover.FailbackHoldOff == 0 {
+		cfg.Failover.FailbackHoldOff = 60
+	}
+	if cfg.Failover.FailbackMaxHoldOff == 0 {
+		cfg.Failover.FailbackMaxHoldOff = 3600
+	}
+	// Lock defaults
+	if cfg.Lock.Backend == "" {
+		cfg.Lock.Backend = "file"
+	}
+	if cfg.CometBFT.InsecureKeyFiles == "" {
+		cfg.CometBFT.InsecureKeyFiles = "fix"
+	}
+	if cfg.CometBFT.StateFormat == "" {
+		cfg.CometBFT.StateFormat = "cometbft-json"
+	}
+	if cfg.Lock.CheckInterval == 0 {
+		cfg.Lock.CheckInterval = 5
+	}
+	if cfg.Lock.Backend != "file" {
+		if cfg.Lock.Key == "" {
+			cfg.Lock.Key = "syncguard/lock"
+			if cfg.Node.Instance != "" {
+				cfg.Lock.Key += "/" + cfg.Node.Instance
+			}
+		}
+		if cfg.Lock.TTL == 0 {
+			cfg.Lock.TTL = 30
+		}
+		// A remote lock expires on its own, so the active node must stop
+		// signing well before the other node can take it
+		if cfg.Lock.GraceTTL == 0 {
+			cfg.Lock.GraceTTL = cfg.Lock.TTL / 3
+		}
+	}
+	if cfg.Lock.GraceTTL == 0 {
+		cfg.Lock.GraceTTL = 60
+	}
+	if cfg.Lock.OnGraceExpired == "" {
+		cfg.Lock.OnGraceExpired = "stop_signing"
+	}
+	if cfg.Election.LeaseTTL == 0 {
+		cfg.Election.LeaseTTL = 45
+	}
+	if cfg.Maintenance.OnError == "" {
+		cfg.Maintenance.OnError = "allow"
+	}
+	if cfg.Maintenance.Timeout == 0 {
+		cfg.Maintenance.Timeout = 5
+	}
+	if cfg.Maintenance.Consul.Address == "" {
+		cfg.Maintenance.Consul.Address = "http://127.0.0.1:8500"
+	}
+	if cfg.Maintenance.Kubernetes.Annotation == "" {
+		cfg.Maintenance.Kubernetes.Annotation = "syncguard.io/maintenance"
+	}
+	if cfg.Maintenance.Kubernetes.TokenFile == "" {
+		cfg.Maintenance.Kubernetes.TokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	}
+	if cfg.Maintenance.Kubernetes.CAFile == "" {
+		cfg.Maintenance.Kubernetes.CAFile = "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"
+	}
+	if cfg.Election.RenewInterval == 0 {
+		cfg.Election.RenewInterval = 5
+	}
 	if cfg.Logging.Level == "" {
 		cfg.Logging.Level = "info"
 	}
 	if cfg.Logging.File == "" {
 		cfg.Logging.File = "syncguard.log"
 	}
+	if cfg.Logging.Format == "" {
+		cfg.Logging.Format = "text"
+	}
+	for i := range cfg.Logging.Outputs {
+		out := &cfg.Logging.Outputs[i]
+		if out.Type == "syslog" {
+			if out.Syslog.Network == "" {
+				out.Syslog.Network = "udp"
+			}
+			if out.Syslog.Facility == "" {
+				out.Syslog.Facility = "daemon"
+			}
+			if out.Syslog.AppName == "" {
+				out.Syslog.AppName = "syncguard"
+			}
+		}
+	}
 	// Validator defaults
 	if cfg.Validator.StopTimeout == 0 {
 		cfg.Validator.StopTimeout = 30
@@ -145,83 +1128,1086 @@ func setDefaults(cfg *Config) {
 	if cfg.Validator.RestartDelay == 0 {
 		cfg.Validator.RestartDelay = 2
 	}
-}
-
-// validate checks required fields and valid values
-func validate(cfg *Config) error {
-	if cfg.Secret == "" {
-		return fmt.Errorf("secret is required")
+	if cfg.Validator.Activation.Method == "" {
+		cfg.Validator.Activation.Method = "restart"
 	}
-	if cfg.Node.ID == "" {
-		return fmt.Errorf("node.id is required")
+	if cfg.Validator.Activation.Signal == "" {
+		cfg.Validator.Activation.Signal = "SIGHUP"
 	}
-	if cfg.Node.Role != constants.NodeStatusActive && cfg.Node.Role != constants.NodeStatusPassive {
-		return fmt.Errorf("node.role must be 'active' or 'passive'")
+	if cfg.Validator.Activation.Timeout == 0 {
+		cfg.Validator.Activation.Timeout = 10
 	}
-	if cfg.CometBFT.RPCURL == "" {
-		return fmt.Errorf("cometbft.rpc_url is required")
+	if cfg.Validator.HealthCmdTimeout == 0 {
+		cfg.Validator.HealthCmdTimeout = 10
 	}
-	if cfg.CometBFT.StatePath == "" {
-		return fmt.Errorf("cometbft.state_path is required")
+	// Identity defaults
+	if cfg.Identity.KeyPath == "" {
+		cfg.Identity.KeyPath = filepath.Join(cfg.Node.DataDir, "identity.json")
 	}
-	// Validator config validation
-	if cfg.Validator.Enabled {
-		switch cfg.Validator.Mode {
-		case "binary":
-			if cfg.Validator.Binary == "" {
-				return fmt.Errorf("validator.binary is required when mode is 'binary'")
+	if cfg.Identity.KeyringPath == "" {
+		cfg.Identity.KeyringPath = filepath.Join(cfg.Node.DataDir, "keyring.json")
+	}
+	if cfg.Identity.MaxSkew == 0 {
+		cfg.Identity.MaxSkew = 30
+	}
+	// TLS defaults: everything lives under data_dir/tls
+	tlsDir := filepath.Join(cfg.Node.DataDir, "tls")
+	if cfg.TLS.CAFile == "" {
+		cfg.TLS.CAFile = filepath.Join(tlsDir, "ca.pem")
+	}
+	if cfg.TLS.CAKeyFile == "" {
+		cfg.TLS.CAKeyFile = filepath.Join(tlsDir, "ca-key.pem")
+	}
+	if cfg.TLS.CertFile == "" {
+		cfg.TLS.CertFile = filepath.Join(tlsDir, cfg.Node.ID+".pem")
+	}
+	if cfg.TLS.KeyFile == "" {
+		cfg.TLS.KeyFile = filepath.Join(tlsDir, cfg.Node.ID+"-key.pem")
+	}
+	// Ping defaults
+	if cfg.Ping.Interval == 0 {
+		cfg.Ping.Interval = 60
+	}
+	if cfg.Ping.Timeout == 0 {
+		cfg.Ping.Timeout = 10
+	}
+	if cfg.ErrorTracking.Timeout == 0 {
+		cfg.ErrorTracking.Timeout = 5
+	}
+	// Alert defaults; a negative dedup window disables deduplication
+	if cfg.Alerts.DedupWindow == 0 {
+		cfg.Alerts.DedupWindow = 300
+	}
+	for i := range cfg.Alerts.Sinks {
+		sink := &cfg.Alerts.Sinks[i]
+		switch sink.Type {
+		case "email":
+			if sink.SMTP.Port == 0 {
+				sink.SMTP.Port = 587
 			}
-		case "docker":
-			if cfg.Validator.Container == "" {
-				return fmt.Errorf("validator.container is required when mode is 'docker'")
+		case "snmp":
+			if sink.SNMP.Community == "" {
+				sink.SNMP.Community = "public"
 			}
-		case "docker-compose":
-			if cfg.Validator.ComposeFile == "" {
-				return fmt.Errorf("validator.compose_file is required when mode is 'docker-compose'")
+			if sink.SNMP.EnterpriseOID == "" {
+				sink.SNMP.EnterpriseOID = "1.3.6.1.4.1.8072.9999.1"
 			}
-			if cfg.Validator.Service == "" {
-				return fmt.Errorf("validator.service is required when mode is 'docker-compose'")
+		case "status_page":
+			if sink.StatusPage.APIURL == "" {
+				switch sink.StatusPage.Provider {
+				case "statuspage":
+					sink.StatusPage.APIURL = "https://api.statuspage.io/v1"
+				case "instatus":
+					sink.StatusPage.APIURL = "https://api.instatus.com/v1"
+				}
+			}
+		case "telegram":
+			if sink.Telegram.APIURL == "" {
+				sink.Telegram.APIURL = "https://api.telegram.org"
 			}
-		default:
-			return fmt.Errorf("validator.mode must be 'binary', 'docker', or 'docker-compose'")
 		}
 	}
-	return nil
-}
-
-// initLogger configures the global logger
-func initLogger(cfg *Config) {
-	switch cfg.Logging.Level {
-	case "debug":
-		log.SetLevel(log.DebugLevel)
-	case "info":
-		log.SetLevel(log.InfoLevel)
-	case "warn":
-		log.SetLevel(log.WarnLevel)
-	case "error":
-		log.SetLevel(log.ErrorLevel)
-	default:
-		log.SetLevel(log.InfoLevel)
+	// Self-monitoring defaults
+	if cfg.SelfMonitor.Interval == 0 {
+		cfg.SelfMonitor.Interval = 30
 	}
-
-	file, err := os.OpenFile(cfg.Logging.File, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
-	if err != nil {
-		log.Warnf("Failed to open log file %s: %v, using stdout only", cfg.Logging.File, err)
-		return
+	if cfg.SelfMonitor.MaxMemory == 0 {
+		cfg.SelfMonitor.MaxMemory = 512 * Megabyte
 	}
-
-	log.SetOutput(io.MultiWriter(file, os.Stdout))
-	log.SetFormatter(&log.TextFormatter{
-		FullTimestamp:   true,
-		TimestampFormat: "2006-01-02 15:04:05",
-	})
-}
-
+	if cfg.SelfMonitor.MaxGoroutines == 0 {
+		cfg.SelfMonitor.MaxGoroutines = 1000
+	}
+	if cfg.SelfMonitor.MaxOpenFDs == 0 {
+		cfg.SelfMonitor.MaxOpenFDs = 1000
+	}
+	// Peer API defaults; a negative cache TTL disables caching
+	if cfg.PeerAPI.CacheTTL == 0 {
+		cfg.PeerAPI.CacheTTL = 1
+	}
+	// A negative DNS refresh keeps the addresses resolved when connecting
+	if cfg.PeerAPI.DNSRefresh == 0 {
+		cfg.PeerAPI.DNSRefresh = 30
+	}
+	if cfg.PeerAPI.BroadcastQuorum == "" {
+		cfg.PeerAPI.BroadcastQuorum = "majority"
+	}
+	if cfg.PeerAPI.Encoding == "" {
+		cfg.PeerAPI.Encoding = "json"
+	}
+	if cfg.PeerAPI.Protocol == "" {
+		cfg.PeerAPI.Protocol = "http"
+	}
+	// Handshakes repeat every 5 minutes, well within the TTL; a negative
+	// TTL never trusts negotiated capabilities
+	if cfg.PeerAPI.CapabilityTTL == 0 {
+		cfg.PeerAPI.CapabilityTTL = 900
+	}
+	// A heartbeat gives up before the next one is due, even with short
+	// health intervals
+	if cfg.PeerAPI.Timeouts.Heartbeat == 0 {
+		cfg.PeerAPI.Timeouts.Heartbeat = min(3, cfg.Health.Interval/2)
+	}
+	if cfg.PeerAPI.Timeouts.Probe == 0 {
+		cfg.PeerAPI.Timeouts.Probe = 5
+	}
+	if cfg.PeerAPI.Timeouts.Notify == 0 {
+		cfg.PeerAPI.Timeouts.Notify = 10
+	}
+	if cfg.PeerAPI.Timeouts.State == 0 {
+		cfg.PeerAPI.Timeouts.State = 15
+	}
+	if cfg.PeerAPI.Timeouts.Key == 0 {
+		cfg.PeerAPI.Timeouts.Key = 30
+	}
+	if cfg.PeerAPI.CompressMinBytes == 0 {
+		cfg.PeerAPI.CompressMinBytes = 512
+	}
+	if cfg.PeerAPI.MaxRequestBytes == 0 {
+		cfg.PeerAPI.MaxRequestBytes = 1 * Megabyte
+	}
+	if cfg.PeerAPI.MaxResponseBytes == 0 {
+		cfg.PeerAPI.MaxResponseBytes = 4 * Megabyte
+	}
+	// Witness defaults
+	if cfg.Witness.Listen == "" {
+		cfg.Witness.Listen = "0.0.0.0:8090"
+	}
+	if cfg.Witness.ObserveInterval == 0 {
+		cfg.Witness.ObserveInterval = 5
+	}
+	if cfg.Witness.StaleAfter == 0 {
+		cfg.Witness.StaleAfter = 30
+	}
+	if cfg.Witness.LeaseTTL == 0 {
+		cfg.Witness.LeaseTTL = 60
+	}
+	if cfg.Witness.Timeout == 0 {
+		cfg.Witness.Timeout = 5
+	}
+	// Cold standby defaults
+	if cfg.ColdStandby.Transport == "" {
+		cfg.ColdStandby.Transport = "ssh"
+	}
+	if cfg.ColdStandby.Interval == 0 {
+		cfg.ColdStandby.Interval = 5
+	}
+	if cfg.ColdStandby.Timeout == 0 {
+		cfg.ColdStandby.Timeout = 30
+	}
+	if cfg.ColdStandby.Port == 0 {
+		cfg.ColdStandby.Port = 22
+	}
+	if cfg.ColdStandby.RemoteDir == "" {
+		cfg.ColdStandby.RemoteDir = "syncguard-standby"
+	}
+	// Drill defaults
+	if cfg.Drill.Duration == 0 {
+		cfg.Drill.Duration = 600
+	}
+	// Chain discovery defaults
+	if cfg.Chain.RefreshInterval == 0 {
+		cfg.Chain.RefreshInterval = 300
+	}
+	if cfg.Chain.EscalateFill == 0 {
+		cfg.Chain.EscalateFill = 0.5
+	}
+	if cfg.Chain.HighPowerShare == 0 {
+		cfg.Chain.HighPowerShare = 0.05
+	}
+	if cfg.Chain.BlockTime == 0 {
+		cfg.Chain.BlockTime = 6
+	}
+	if cfg.Chain.BudgetAlertFill == 0 {
+		cfg.Chain.BudgetAlertFill = 0.25
+	}
+	if cfg.Chain.Signing.Interval == 0 {
+		cfg.Chain.Signing.Interval = 10
+	}
+	if cfg.Chain.Signing.Window == 0 {
+		cfg.Chain.Signing.Window = 100
+	}
+	if cfg.Chain.Signing.MissedBlocks == 0 {
+		cfg.Chain.Signing.MissedBlocks = 10
+	}
+	if cfg.Chain.DoubleSignGuard.Depth == 0 {
+		cfg.Chain.DoubleSignGuard.Depth = 100
+	}
+	// Group defaults
+	if cfg.Group.OnError == "" {
+		cfg.Group.OnError = "stop"
+	}
+	if cfg.Group.Timeout == 0 {
+		cfg.Group.Timeout = 120
+	}
+	for i := range cfg.Group.Members {
+		if cfg.Group.Members[i].Token == "" {
+			cfg.Group.Members[i].Token = cfg.Admin.Token
+		}
+	}
+	if cfg.Signer.Timeout == 0 {
+		cfg.Signer.Timeout = 5
+	}
+	if cfg.Invariants.Interval == 0 {
+		cfg.Invariants.Interval = 30
+	}
+	if cfg.Invariants.Confirmations == 0 {
+		cfg.Invariants.Confirmations = 2
+	}
+	// History defaults
+	if cfg.History.Path == "" {
+		cfg.History.Path = filepath.Join(cfg.Node.DataDir, "history.jsonl")
+	}
+	if cfg.History.MaxSize == 0 {
+		cfg.History.MaxSize = 50 * Megabyte
+	}
+	// Gatekeeper defaults: keep the watermark next to the validator state
+	if cfg.Gatekeeper.Enabled && cfg.Gatekeeper.Path == "" && cfg.CometBFT.StatePath != "" {
+		cfg.Gatekeeper.Path = filepath.Join(filepath.Dir(cfg.CometBFT.StatePath), "syncguard_watermark.json")
+	}
+}
+
+// validate checks required fields and valid values
+func validate(cfg *Config) error {
+	if cfg.Secret == "" {
+		return fmt.Errorf("secret is required")
+	}
+	if cfg.PreviousSecret != "" && cfg.PreviousSecret == cfg.Secret {
+		return fmt.Errorf("previous_secret must differ from secret")
+	}
+	if err := validateLogging(cfg.Logging); err != nil {
+		return err
+	}
+	if err := validateErrorTracking(cfg.ErrorTracking); err != nil {
+		return err
+	}
+	if cfg.Node.ID == "" {
+		return fmt.Errorf("node.id is required")
+	}
+	if cfg.Node.Role != constants.NodeStatusActive && cfg.Node.Role != constants.NodeStatusPassive {
+		return fmt.Errorf("node.role must be 'active' or 'passive'")
+	}
+	if cfg.Node.AfterReboot != "reconcile" && cfg.Node.AfterReboot != "trust" {
+		return fmt.Errorf("node.after_reboot must be 'reconcile' or 'trust'")
+	}
+	if cfg.Witness.Address != "" {
+		if err := ValidatePeerAddress(cfg.Witness.Address); err != nil {
+			return fmt.Errorf("witness.address %q is invalid: %w", cfg.Witness.Address, err)
+		}
+		if cfg.ColdStandby.Enabled {
+			return fmt.Errorf("witness.address does not apply with cold_standby, which has no peers")
+		}
+	}
+	if cfg.Node.AdvertiseAddress != "" {
+		if err := ValidatePeerAddress(cfg.Node.AdvertiseAddress); err != nil {
+			return fmt.Errorf("node.advertise_address %q is invalid: %w", cfg.Node.AdvertiseAddress, err)
+		}
+	}
+	if cfg.Node.Instance != "" && !validInstance(cfg.Node.Instance) {
+		return fmt.Errorf("node.instance %q must be 1-64 lowercase letters, digits, '-' or '_', starting with a letter or digit", cfg.Node.Instance)
+	}
+	if cfg.CometBFT.RPCURL == "" {
+		return fmt.Errorf("cometbft.rpc_url is required")
+	}
+	if cfg.CometBFT.StatePath == "" {
+		return fmt.Errorf("cometbft.state_path is required")
+	}
+	switch cfg.CometBFT.InsecureKeyFiles {
+	case "fix", "warn", "refuse":
+	default:
+		return fmt.Errorf("cometbft.insecure_key_files must be 'fix', 'warn' or 'refuse'")
+	}
+	switch cfg.CometBFT.StateFormat {
+	case "cometbft-json":
+	case "tmkms-toml":
+		if cfg.ColdStandby.Enabled {
+			return fmt.Errorf("cold_standby ships CometBFT's priv_validator files and needs cometbft.state_format 'cometbft-json'")
+		}
+	default:
+		return fmt.Errorf("cometbft.state_format must be 'cometbft-json' or 'tmkms-toml'")
+	}
+	if err := validateArtifacts(cfg.CometBFT); err != nil {
+		return err
+	}
+	// Validator config validation
+	if cfg.Validator.Enabled {
+		if err := validateProcess("validator", cfg.Validator.Mode, cfg.Validator.Binary,
+			cfg.Validator.Container, cfg.Validator.ComposeFile, cfg.Validator.Service); err != nil {
+			return err
+		}
+	}
+	if err := validateExecution(cfg); err != nil {
+		return err
+	}
+	if err := validateServices(cfg); err != nil {
+		return err
+	}
+	if err := validateActivation(cfg); err != nil {
+		return err
+	}
+	if cfg.Failover.FailbackMaxLag < 0 || cfg.Failover.FailbackHoldOff < 0 ||
+		cfg.Failover.FailbackMaxHoldOff < cfg.Failover.FailbackHoldOff {
+		return fmt.Errorf("failover.failback_max_lag and failback_holdoff must not be negative, and failback_max_holdoff must be at least failback_holdoff")
+	}
+	if cfg.Failover.StandbyMaxLag < 0 {
+		return fmt.Errorf("failover.standby_max_lag must not be negative")
+	}
+	if cfg.Failover.Approval.AutoApproveAfter < 0 {
+		return fmt.Errorf("failover.approval.auto_approve_after must not be negative")
+	}
+	if cfg.Failover.Approval.Enabled && cfg.ColdStandby.Enabled {
+		return fmt.Errorf("failover.approval does not apply with cold_standby, which never fails over automatically")
+	}
+	if err := validateLinkCheck(cfg); err != nil {
+		return err
+	}
+	if cfg.Failover.ShutdownHandoff.Deadline < 0 {
+		return fmt.Errorf("failover.shutdown_handoff.deadline must not be negative")
+	}
+	if err := validateHooks("failover.pre_hooks", cfg.Failover.PreHooks); err != nil {
+		return err
+	}
+	if err := validateHooks("failover.post_hooks", cfg.Failover.PostHooks); err != nil {
+		return err
+	}
+	if cfg.Health.Trend.Enabled && cfg.Health.Trend.Samples < 3 {
+		return fmt.Errorf("health.trend.samples must be at least 3")
+	}
+	if cfg.Health.Host.MaxMemoryUsed > 1 || cfg.Health.Host.MaxIOWait > 1 {
+		return fmt.Errorf("health.host.max_memory_used and max_iowait are fractions and must not exceed 1")
+	}
+	if cfg.Health.Policy.Healthy != "" {
+		if _, err := policy.Compile(cfg.Health.Policy.Healthy, HealthPolicyFacts); err != nil {
+			return fmt.Errorf("health.policy.healthy: %w", err)
+		}
+	}
+	if cfg.Health.Policy.Takeover != "" {
+		if _, err := policy.Compile(cfg.Health.Policy.Takeover, TakeoverPolicyFacts); err != nil {
+			return fmt.Errorf("health.policy.takeover: %w", err)
+		}
+	}
+	if cfg.Health.Heartbeat.MaxLag < 0 || cfg.Health.Heartbeat.StaleAfter < 0 {
+		return fmt.Errorf("health.heartbeat.max_lag and stale_after must not be negative")
+	}
+	if err := validateStateChurn(cfg.Health.StateChurn); err != nil {
+		return err
+	}
+	switch cfg.Health.Unknown {
+	case "pass", "fail", "hold":
+	default:
+		return fmt.Errorf("health.unknown must be 'pass', 'fail' or 'hold'")
+	}
+	if cfg.Validator.HealthCmdTimeout < 0 {
+		return fmt.Errorf("validator.health_cmd_timeout must not be negative")
+	}
+	if err := validatePeers(cfg.Peers); err != nil {
+		return err
+	}
+	if cfg.PeerAPI.CompressMinBytes < 0 {
+		return fmt.Errorf("peer_api.compress_min_bytes must not be negative")
+	}
+	if cfg.PeerAPI.Encoding != "json" && cfg.PeerAPI.Encoding != "protobuf" {
+		return fmt.Errorf("peer_api.encoding must be 'json' or 'protobuf'")
+	}
+	if cfg.PeerAPI.Protocol != "http" && cfg.PeerAPI.Protocol != "grpc" {
+		return fmt.Errorf("peer_api.protocol must be 'http' or 'grpc'")
+	}
+	switch cfg.PeerAPI.BroadcastQuorum {
+	case "all", "majority", "any":
+	default:
+		return fmt.Errorf("peer_api.broadcast_quorum must be 'all', 'majority' or 'any'")
+	}
+	if err := validatePeerTimeouts(cfg.PeerAPI.Timeouts, cfg.Health.Interval); err != nil {
+		return err
+	}
+	if cfg.Admin.Listen != "" {
+		if _, _, err := net.SplitHostPort(cfg.Admin.Listen); err != nil {
+			return fmt.Errorf("admin.listen must be host:port: %w", err)
+		}
+	}
+	if cfg.Lock.OnGraceExpired != "stop_signing" && cfg.Lock.OnGraceExpired != "keep_signing" {
+		return fmt.Errorf("lock.on_grace_expired must be 'stop_signing' or 'keep_signing'")
+	}
+	if err := validateLock(cfg.Lock); err != nil {
+		return err
+	}
+	if cfg.Election.Enabled {
+		if cfg.Election.RenewInterval <= 0 || cfg.Election.RenewInterval*2 > cfg.Election.LeaseTTL {
+			return fmt.Errorf("election.renew_interval must be positive and at most half of election.lease_ttl")
+		}
+		// The node starts to stop signing validator.stop_timeout before its
+		// lease runs out, a tenth of lease_ttl early; a renewal must come first
+		if !cfg.Signer.Enabled && cfg.Election.LeaseTTL*9/10 <= cfg.Election.RenewInterval+cfg.Validator.StopTimeout {
+			return fmt.Errorf("election.lease_ttl less a tenth must exceed election.renew_interval plus validator.stop_timeout (%gs), the time stopping the node can take",
+				float64(cfg.Validator.StopTimeout))
+		}
+		if len(cfg.Peers) == 0 {
+			return fmt.Errorf("election needs at least one peer")
+		}
+		if cfg.ColdStandby.Enabled {
+			return fmt.Errorf("election does not apply with cold_standby, which has no peers")
+		}
+	}
+	if err := validateMaintenance(cfg.Maintenance); err != nil {
+		return err
+	}
+	if err := validateAlerts(cfg.Alerts); err != nil {
+		return err
+	}
+	if err := validateDrill(cfg.Drill); err != nil {
+		return err
+	}
+	if err := validateGroup(cfg.Group); err != nil {
+		return err
+	}
+	if err := validateSigner(cfg.Signer); err != nil {
+		return err
+	}
+	if cfg.Invariants.Interval < 0 || cfg.Invariants.Confirmations < 0 {
+		return fmt.Errorf("invariants.interval and confirmations must not be negative")
+	}
+	if cfg.Invariants.Enabled && cfg.ColdStandby.Enabled {
+		return fmt.Errorf("invariants does not apply with cold_standby, whose standby runs no syncguard")
+	}
+	if err := validateColdStandby(cfg); err != nil {
+		return err
+	}
+	if cfg.Chain.LCDURL != "" {
+		u, err := url.Parse(cfg.Chain.LCDURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("chain.lcd_url %q must be an http(s) URL", cfg.Chain.LCDURL)
+		}
+	}
+	if cfg.Chain.RefreshInterval < 0 {
+		return fmt.Errorf("chain.refresh_interval must not be negative")
+	}
+	if cfg.Chain.EscalateFill < 0 || cfg.Chain.EscalateFill > 1 {
+		return fmt.Errorf("chain.escalate_fill must be between 0 and 1")
+	}
+	if cfg.Chain.HighPowerShare < 0 || cfg.Chain.HighPowerShare > 1 {
+		return fmt.Errorf("chain.high_power_share must be between 0 and 1")
+	}
+	if cfg.Chain.BudgetAlertFill < 0 || cfg.Chain.BudgetAlertFill > 1 {
+		return fmt.Errorf("chain.budget_alert_fill must be between 0 and 1")
+	}
+	if cfg.Chain.BlockTime < 0 {
+		return fmt.Errorf("chain.block_time must not be negative")
+	}
+	if cfg.Chain.Signing.Interval < 0 {
+		return fmt.Errorf("chain.signing.interval must not be negative")
+	}
+	if cfg.Chain.Signing.Window < 0 {
+		return fmt.Errorf("chain.signing.window must not be negative")
+	}
+	if cfg.Chain.Signing.MissedBlocks < 0 || cfg.Chain.Signing.MissedBlocks > cfg.Chain.Signing.Window {
+		return fmt.Errorf("chain.signing.missed_blocks must be between 1 and chain.signing.window")
+	}
+	if cfg.Chain.DoubleSignGuard.Depth < 0 {
+		return fmt.Errorf("chain.double_sign_guard.depth must not be negative")
+	}
+	return nil
+}
+
+// validateDrill checks the drill schedule and blackout windows
+// validateProcess checks that a managed process has what its mode needs
+func validateProcess(section string, mode constants.NodeManagerType, binary, container, composeFile, service string) error {
+	switch mode {
+	case "binary":
+		if binary == "" {
+			return fmt.Errorf("%s.binary is required when mode is 'binary'", section)
+		}
+	case "docker":
+		if container == "" {
+			return fmt.Errorf("%s.container is required when mode is 'docker'", section)
+		}
+	case "docker-compose":
+		if composeFile == "" {
+			return fmt.Errorf("%s.compose_file is required when mode is 'docker-compose'", section)
+		}
+		if service == "" {
+			return fmt.Errorf("%s.service is required when mode is 'docker-compose'", section)
+		}
+	default:
+		return fmt.Errorf("%s.mode must be 'binary', 'docker', or 'docker-compose'", section)
+	}
+	return nil
+}
+
+// validateExecution checks the execution client settings
+func validateExecution(cfg *Config) error {
+	exec := cfg.Execution
+	for name, value := range map[string]string{"rpc_url": exec.RPCURL, "engine_url": exec.EngineURL} {
+		if value == "" {
+			continue
+		}
+		u, err := url.Parse(value)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("execution.%s %q must be an http(s) URL", name, value)
+		}
+	}
+	if exec.EngineURL != "" && exec.JWTSecretPath == "" {
+		return fmt.Errorf("execution.jwt_secret_path is required with engine_url")
+	}
+	if !exec.Managed {
+		return nil
+	}
+	if !cfg.Validator.Enabled {
+		return fmt.Errorf("execution.managed requires validator.enabled")
+	}
+	return validateProcess("execution", exec.Mode, exec.Binary, exec.Container, exec.ComposeFile, exec.Service)
+}
+
+// validateServices checks the extra managed services and that every
+// dependency names a known service; cycles are reported when the services
+// are ordered at startup
+func validateServices(cfg *Config) error {
+	v := cfg.Validator
+	if len(v.Services) == 0 && len(v.DependsOn) == 0 {
+		return nil
+	}
+	if !v.Enabled {
+		return fmt.Errorf("validator.services and validator.depends_on require validator.enabled")
+	}
+
+	known := map[string]bool{"consensus": true}
+	if cfg.Execution.Managed {
+		known["execution"] = true
+	}
+	for i, svc := range v.Services {
+		section := fmt.Sprintf("validator.services[%d]", i)
+		if svc.Name == "" {
+			return fmt.Errorf("%s.name is required", section)
+		}
+		if svc.Name == "consensus" || svc.Name == "execution" {
+			return fmt.Errorf("%s.name %q is reserved", section, svc.Name)
+		}
+		if known[svc.Name] {
+			return fmt.Errorf("%s.name %q is duplicated", section, svc.Name)
+		}
+		known[svc.Name] = true
+		if err := validateProcess(section, svc.Mode, svc.Binary, svc.Container, svc.ComposeFile, svc.Service); err != nil {
+			return err
+		}
+	}
+
+	check := func(section string, deps []string) error {
+		for _, dep := range deps {
+			if !known[dep] {
+				return fmt.Errorf("%s.depends_on: unknown service %q", section, dep)
+			}
+		}
+		return nil
+	}
+	if err := check("validator", v.DependsOn); err != nil {
+		return err
+	}
+	for i, svc := range v.Services {
+		if err := check(fmt.Sprintf("validator.services[%d]", i), svc.DependsOn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateActivation checks the key activation method and what it acts on
+func validateActivation(cfg *Config) error {
+	a := cfg.Validator.Activation
+	switch a.Method {
+	case "restart":
+	case "signal":
+		switch a.Signal {
+		case "SIGHUP", "SIGUSR1", "SIGUSR2":
+		default:
+			return fmt.Errorf("validator.activation.signal must be 'SIGHUP', 'SIGUSR1' or 'SIGUSR2'")
+		}
+	case "service":
+		found := false
+		for _, svc := range cfg.Validator.Services {
+			found = found || svc.Name == a.Service
+		}
+		if !found {
+			return fmt.Errorf("validator.activation.service must name an entry of validator.services")
+		}
+	default:
+		return fmt.Errorf("validator.activation.method must be 'restart', 'signal' or 'service'")
+	}
+	if a.Timeout < 0 {
+		return fmt.Errorf("validator.activation.timeout must not be negative")
+	}
+	return nil
+}
+
+// validateStateChurn checks that the window holds enough samples to
+// measure a rate and that the drop ratio is a fraction
+func validateStateChurn(c StateChurnConfig) error {
+	if c.Interval < 0 || c.Window < c.Interval*2 {
+		return fmt.Errorf("health.state_churn.window must be at least twice a positive interval")
+	}
+	if c.DropRatio <= 0 || c.DropRatio >= 1 {
+		return fmt.Errorf("health.state_churn.drop_ratio must be between 0 and 1")
+	}
+	return nil
+}
+
+// validateHooks checks the hooks listed under key
+func validateHooks(key string, hooks []HookConfig) error {
+	for i, h := range hooks {
+		if len(h.Command) == 0 || h.Command[0] == "" {
+			return fmt.Errorf("%s[%d].command must name an executable", key, i)
+		}
+		if h.Timeout < 0 {
+			return fmt.Errorf("%s[%d].timeout must not be negative", key, i)
+		}
+	}
+	return nil
+}
+
+// validateLinkCheck checks the probe run before planned handovers. The
+// probe body must fit under the peer's request limit.
+func validateLinkCheck(cfg *Config) error {
+	l := cfg.Failover.LinkCheck
+	switch l.Action {
+	case "warn", "abort":
+	default:
+		return fmt.Errorf("failover.link_check.action must be 'warn' or 'abort'")
+	}
+	if l.ProbeBytes < 0 || l.ProbeBytes > cfg.PeerAPI.MaxRequestBytes {
+		return fmt.Errorf("failover.link_check.probe_bytes must be positive and at most peer_api.max_request_bytes")
+	}
+	return nil
+}
+
+// validateColdStandby checks the transport settings of a cold standby
+func validateColdStandby(cfg *Config) error {
+	cold := cfg.ColdStandby
+	if !cold.Enabled {
+		return nil
+	}
+	if len(cfg.Peers) > 0 {
+		return fmt.Errorf("cold_standby replaces peers; remove peers or disable cold_standby")
+	}
+	if cold.Interval <= 0 {
+		return fmt.Errorf("cold_standby.interval must be positive")
+	}
+	if cold.Timeout <= 0 {
+		return fmt.Errorf("cold_standby.timeout must be positive")
+	}
+	if cold.ActivateCommand == "" {
+		return fmt.Errorf("cold_standby.activate_command is required")
+	}
+	switch cold.Transport {
+	case "ssh":
+		if cold.Host == "" {
+			return fmt.Errorf("cold_standby.host is required with transport ssh")
+		}
+		if cold.RemoteKeyPath == "" || cold.RemoteStatePath == "" {
+			return fmt.Errorf("cold_standby.remote_key_path and remote_state_path are required with transport ssh")
+		}
+	case "command":
+		if !strings.Contains(cold.UploadCommand, "{file}") {
+			return fmt.Errorf("cold_standby.upload_command must contain {file} with transport command")
+		}
+	default:
+		return fmt.Errorf("cold_standby.transport must be 'ssh' or 'command'")
+	}
+	return nil
+}
+
+// validateGroup checks the linked instances of a cascade group
+func validateGroup(group GroupConfig) error {
+	if group.OnError != "stop" && group.OnError != "continue" {
+		return fmt.Errorf("group.on_error must be 'stop' or 'continue'")
+	}
+	if group.Timeout < 0 {
+		return fmt.Errorf("group.timeout must not be negative")
+	}
+	if group.Cascade && len(group.Members) == 0 {
+		return fmt.Errorf("group.cascade needs at least one member")
+	}
+	seen := make(map[string]bool)
+	for i, m := range group.Members {
+		if m.ID == "" {
+			return fmt.Errorf("group.members[%d].id is required", i)
+		}
+		if seen[m.ID] {
+			return fmt.Errorf("group.members[%d].id %q is duplicated", i, m.ID)
+		}
+		seen[m.ID] = true
+		u, err := url.Parse(m.AdminURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("group.members[%d].admin_url %q must be an http(s) URL", i, m.AdminURL)
+		}
+	}
+	return nil
+}
+
+// validateSigner checks the remote signer settings when it is enabled
+func validateSigner(s SignerConfig) error {
+	if !s.Enabled {
+		return nil
+	}
+	if !strings.HasPrefix(s.Address, "tcp://") && !strings.HasPrefix(s.Address, "unix://") {
+		return fmt.Errorf("signer.address must be the node's priv_validator_laddr, starting with tcp:// or unix://")
+	}
+	if s.ChainID == "" {
+		return fmt.Errorf("signer.chain_id is required")
+	}
+	if s.Timeout <= 0 {
+		return fmt.Errorf("signer.timeout must be positive")
+	}
+	return nil
+}
+
+func validateDrill(drill DrillConfig) error {
+	if drill.Schedule != "" {
+		if _, err := cron.Parse(drill.Schedule); err != nil {
+			return fmt.Errorf("drill.schedule: %w", err)
+		}
+	}
+	if drill.Duration < 0 {
+		return fmt.Errorf("drill.duration must be positive")
+	}
+	for i, b := range drill.Blackouts {
+		switch {
+		case b.Cron != "":
+			if _, err := cron.Parse(b.Cron); err != nil {
+				return fmt.Errorf("drill.blackouts[%d].cron: %w", i, err)
+			}
+			if b.Duration <= 0 {
+				return fmt.Errorf("drill.blackouts[%d].duration is required with cron", i)
+			}
+		case b.Start != "" && b.End != "":
+			start, err := time.Parse(time.RFC3339, b.Start)
+			if err != nil {
+				return fmt.Errorf("drill.blackouts[%d].start must be RFC 3339: %w", i, err)
+			}
+			end, err := time.Parse(time.RFC3339, b.End)
+			if err != nil {
+				return fmt.Errorf("drill.blackouts[%d].end must be RFC 3339: %w", i, err)
+			}
+			if !end.After(start) {
+				return fmt.Errorf("drill.blackouts[%d].end must be after start", i)
+			}
+		default:
+			return fmt.Errorf("drill.blackouts[%d] needs start and end, or cron and duration", i)
+		}
+	}
+	return nil
+}
+
+// validateMaintenance checks the maintenance flag sources
+func validateMaintenance(m MaintenanceConfig) error {
+	if m.OnError != "allow" && m.OnError != "refuse" {
+		return fmt.Errorf("maintenance.on_error must be 'allow' or 'refuse'")
+	}
+	if m.Timeout < 0 {
+		return fmt.Errorf("maintenance.timeout must not be negative")
+	}
+	if m.Consul.Enabled && !strings.HasPrefix(m.Consul.Address, "http://") && !strings.HasPrefix(m.Consul.Address, "https://") {
+		return fmt.Errorf("maintenance.consul.address must be an http(s) URL")
+	}
+	if k := m.Kubernetes; k.Enabled {
+		if k.APIURL != "" && !strings.HasPrefix(k.APIURL, "https://") && !strings.HasPrefix(k.APIURL, "http://") {
+			return fmt.Errorf("maintenance.kubernetes.api_url must be an http(s) URL")
+		}
+		if k.Node == "" && os.Getenv("NODE_NAME") == "" {
+			return fmt.Errorf("maintenance.kubernetes.node is required when NODE_NAME is not set")
+		}
+	}
+	return nil
+}
+
+// validateArtifacts checks that artifacts have unique names and do not
+// stand in for the key and state, which are handed over on their own
+func validateArtifacts(cometbft CometBFTConfig) error {
+	seen := map[string]bool{}
+	for i, artifact := range cometbft.Artifacts {
+		if artifact.Name == "" {
+			return fmt.Errorf("cometbft.artifacts[%d].name is required", i)
+		}
+		if !validInstance(artifact.Name) {
+			return fmt.Errorf("cometbft.artifacts[%d].name %q must be 1-64 lowercase letters, digits, '-' or '_'", i, artifact.Name)
+		}
+		if seen[artifact.Name] {
+			return fmt.Errorf("cometbft.artifacts: name %q is used twice", artifact.Name)
+		}
+		seen[artifact.Name] = true
+		if artifact.Path == "" {
+			return fmt.Errorf("cometbft.artifacts[%d].path is required", i)
+		}
+		path := filepath.Clean(artifact.Path)
+		if path == filepath.Clean(cometbft.KeyPath) || path == filepath.Clean(cometbft.StatePath) {
+			return fmt.Errorf("cometbft.artifacts[%d]: %s is handed over already and must not be listed", i, artifact.Path)
+		}
+	}
+	return nil
+}
+
+// validateLock checks the lock backend. A remote lock expires ttl seconds
+// after its last renewal, every third of the ttl, so an active node cut
+// off from the backend must have stopped signing within two thirds of it:
+// it notices within a check_interval, and its own checks run a
+// check_interval apart.
+func validateLock(lock LockConfig) error {
+	switch lock.Backend {
+	case "file":
+		return nil
+	case "etcd", "consul":
+	default:
+		return fmt.Errorf("lock.backend must be 'file', 'etcd' or 'consul'")
+	}
+	if len(lock.Endpoints) == 0 {
+		return fmt.Errorf("lock.endpoints is required with lock.backend %s", lock.Backend)
+	}
+	for _, endpoint := range lock.Endpoints {
+		u, err := url.Parse(endpoint)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("lock.endpoints: %q must be an http(s) URL", endpoint)
+		}
+	}
+	if strings.Trim(lock.Key, "/") == "" || strings.ContainsAny(lock.Key, "?#% ") {
+		return fmt.Errorf("lock.key %q must be a non-empty path without '?', '#', '%%' or spaces", lock.Key)
+	}
+	if lock.Backend == "consul" && lock.TTL < 10 {
+		return fmt.Errorf("lock.ttl must be at least 10 seconds with consul")
+	}
+	if lock.TTL < 3 {
+		return fmt.Errorf("lock.ttl must be at least 3 seconds")
+	}
+	if lock.OnGraceExpired == "keep_signing" {
+		return fmt.Errorf("lock.on_grace_expired keep_signing would sign after another node took the %s lock", lock.Backend)
+	}
+	if (lock.GraceTTL+2*lock.CheckInterval)*3 > lock.TTL*2 {
+		return fmt.Errorf("lock.grace_ttl plus twice lock.check_interval must be at most two thirds of lock.ttl")
+	}
+	if lock.CertFile != "" && lock.KeyFile == "" {
+		return fmt.Errorf("lock.key_file is required with lock.cert_file")
+	}
+	return nil
+}
+
+// validateWitness checks the subset of settings a witness uses
+func validateWitness(cfg *Config) error {
+	if cfg.Secret == "" {
+		return fmt.Errorf("secret is required")
+	}
+	if cfg.PreviousSecret != "" && cfg.PreviousSecret == cfg.Secret {
+		return fmt.Errorf("previous_secret must differ from secret")
+	}
+	if err := validateLogging(cfg.Logging); err != nil {
+		return err
+	}
+	if err := validateErrorTracking(cfg.ErrorTracking); err != nil {
+		return err
+	}
+	if cfg.Node.ID == "" {
+		return fmt.Errorf("node.id is required")
+	}
+	if len(cfg.Peers) == 0 {
+		return fmt.Errorf("peers must list the nodes to witness")
+	}
+	if err := validatePeers(cfg.Peers); err != nil {
+		return err
+	}
+	if _, _, err := net.SplitHostPort(cfg.Witness.Listen); err != nil {
+		return fmt.Errorf("witness.listen must be host:port: %w", err)
+	}
+	return nil
+}
+
+// validatePeers checks every peer has a usable address
+func validatePeers(peers []PeerConfig) error {
+	for i, peer := range peers {
+		if peer.Address == "" {
+			return fmt.Errorf("peers[%d].address is required", i)
+		}
+		if err := ValidatePeerAddress(peer.Address); err != nil {
+			return fmt.Errorf("peers[%d].address %q is invalid: %w", i, peer.Address, err)
+		}
+		if peer.AdminURL != "" {
+			u, err := url.Parse(peer.AdminURL)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("peers[%d].admin_url %q must be an http(s) URL", i, peer.AdminURL)
+			}
+		}
+	}
+	return nil
+}
+
+// validatePeerTimeouts checks every peer request timeout is positive and
+// that a heartbeat gives up before the next one is due
+func validatePeerTimeouts(t PeerTimeoutsConfig, healthInterval Seconds) error {
+	for _, timeout := range []struct {
+		key   string
+		value Seconds
+	}{
+		{"heartbeat", t.Heartbeat}, {"probe", t.Probe}, {"notify", t.Notify}, {"state", t.State}, {"key", t.Key},
+	} {
+		if timeout.value <= 0 {
+			return fmt.Errorf("peer_api.timeouts.%s must be positive", timeout.key)
+		}
+	}
+	if healthInterval > 0 && t.Heartbeat >= healthInterval {
+		return fmt.Errorf("peer_api.timeouts.heartbeat must be shorter than health.interval (%s)", healthInterval)
+	}
+	return nil
+}
+
+// validateAlerts checks that each alert sink has what its type needs
+// validateErrorTracking checks the Sentry DSN or tracker URL
+func validateErrorTracking(cfg ErrorTrackingConfig) error {
+	if cfg.DSN != "" && cfg.URL != "" {
+		return fmt.Errorf("error_tracking: set either dsn or url, not both")
+	}
+	if cfg.DSN != "" {
+		u, err := url.Parse(cfg.DSN)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.User == nil || strings.Trim(u.Path, "/") == "" {
+			return fmt.Errorf("error_tracking.dsn must look like https://<key>@<host>/<project>")
+		}
+	}
+	if cfg.URL != "" {
+		if u, err := url.Parse(cfg.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return fmt.Errorf("error_tracking.url must be an http or https URL")
+		}
+	}
+	return nil
+}
+
+func validateAlerts(alerts AlertsConfig) error {
+	for i, sink := range alerts.Sinks {
+		switch sink.Type {
+		case "webhook", "slack", "discord":
+			if sink.URL == "" {
+				return fmt.Errorf("alerts.sinks[%d].url is required for type '%s'", i, sink.Type)
+			}
+		case "telegram":
+			if sink.Telegram.BotToken == "" || sink.Telegram.ChatID == "" {
+				return fmt.Errorf("alerts.sinks[%d].telegram.bot_token and chat_id are required for type 'telegram'", i)
+			}
+		case "email":
+			if sink.SMTP.Host == "" {
+				return fmt.Errorf("alerts.sinks[%d].smtp.host is required for type 'email'", i)
+			}
+			if sink.SMTP.From == "" || len(sink.SMTP.To) == 0 {
+				return fmt.Errorf("alerts.sinks[%d].smtp.from and smtp.to are required for type 'email'", i)
+			}
+		case "snmp":
+			if sink.SNMP.Target == "" {
+				return fmt.Errorf("alerts.sinks[%d].snmp.target is required for type 'snmp'", i)
+			}
+		case "status_page":
+			if err := validateStatusPage(i, sink); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("alerts.sinks[%d].type must be 'webhook', 'slack', 'discord', 'telegram', 'email', 'snmp', or 'status_page'", i)
+		}
+	}
+	return nil
+}
+
+// validateStatusPage checks that a status page sink can reach its page
+func validateStatusPage(i int, sink AlertSinkConfig) error {
+	page := sink.StatusPage
+	switch page.Provider {
+	case "statuspage", "instatus":
+		if page.APIKey == "" || page.PageID == "" || page.ComponentID == "" {
+			return fmt.Errorf("alerts.sinks[%d].status_page.api_key, page_id and component_id are required for provider '%s'", i, page.Provider)
+		}
+	case "webhook":
+		if sink.URL == "" {
+			return fmt.Errorf("alerts.sinks[%d].url is required for status_page provider 'webhook'", i)
+		}
+	default:
+		return fmt.Errorf("alerts.sinks[%d].status_page.provider must be 'statuspage', 'instatus', or 'webhook'", i)
+	}
+	return nil
+}
+
+// validateLogging checks the logging section shared by nodes and witnesses
+func validateLogging(cfg LoggingConfig) error {
+	if cfg.Format != "text" && cfg.Format != "json" {
+		return fmt.Errorf("logging.format must be 'text' or 'json'")
+	}
+	for i, out := range cfg.Outputs {
+		switch out.Level {
+		case "", "debug", "info", "warn", "error":
+		default:
+			return fmt.Errorf("logging.outputs[%d].level must be 'debug', 'info', 'warn' or 'error'", i)
+		}
+		if out.Type != "syslog" {
+			return fmt.Errorf("logging.outputs[%d].type must be 'syslog'", i)
+		}
+		if out.Syslog.Address == "" {
+			return fmt.Errorf("logging.outputs[%d].syslog.address is required", i)
+		}
+		switch out.Syslog.Network {
+		case "udp", "tcp":
+			if out.Syslog.CAFile != "" || out.Syslog.CertFile != "" {
+				return fmt.Errorf("logging.outputs[%d].syslog.ca_file and cert_file need network 'tls'", i)
+			}
+		case "tls":
+		default:
+			return fmt.Errorf("logging.outputs[%d].syslog.network must be 'udp', 'tcp' or 'tls'", i)
+		}
+		if (out.Syslog.CertFile == "") != (out.Syslog.KeyFile == "") {
+			return fmt.Errorf("logging.outputs[%d].syslog.cert_file and key_file must be set together", i)
+		}
+		if !slices.Contains(SyslogFacilities, out.Syslog.Facility) {
+			return fmt.Errorf("logging.outputs[%d].syslog.facility %q is unknown", i, out.Syslog.Facility)
+		}
+	}
+	return nil
+}
+
+// initLogger configures the global logger
+func initLogger(cfg *Config) {
+	switch cfg.Logging.Level {
+	case "debug":
+		log.SetLevel(log.DebugLevel)
+	case "info":
+		log.SetLevel(log.InfoLevel)
+	case "warn":
+		log.SetLevel(log.WarnLevel)
+	case "error":
+		log.SetLevel(log.ErrorLevel)
+	default:
+		log.SetLevel(log.InfoLevel)
+	}
+
+	if cfg.Logging.Format == "json" {
+		log.SetFormatter(&log.JSONFormatter{TimestampFormat: time.RFC3339Nano})
+	} else {
+		log.SetFormatter(&log.TextFormatter{
+			FullTimestamp:   true,
+			TimestampFormat: "2006-01-02 15:04:05",
+		})
+	}
+
+	file, err := os.OpenFile(cfg.Logging.File, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
+	if err != nil {
+		log.Warnf("Failed to open log file %s: %v, using stdout only", cfg.Logging.File, err)
+		return
+	}
+
+	log.SetOutput(io.MultiWriter(file, os.Stdout))
+}
+
 // IsActive returns true if this node should be signing
 func (c *Config) IsActive() bool {
 	return c.Node.Role == constants.NodeStatusActive
 }
 
+// PinnedPeerKeys returns the public keys pinned for peers in the config
+func (c *Config) PinnedPeerKeys() map[string]string {
+	keys := make(map[string]string)
+	for _, peer := range c.Peers {
+		if peer.PublicKey != "" {
+			keys[peer.ID] = peer.PublicKey
+		}
+	}
+	return keys
+}
+
 // GetPeerAddress returns the first peer's address
 func (c *Config) GetPeerAddress() string {
 	if len(c.Peers) > 0 {
@@ -229,3 +2215,20 @@ func (c *Config) GetPeerAddress() string {
 	}
 	return ""
 }
+
+// validInstance reports whether name is usable in file names, metric label
+// values and URL paths as is
+func validInstance(name string) bool {
+	if len(name) > 64 {
+		return false
+	}
+	for i, c := range name {
+		switch {
+		case c >= 'a' && c <= 'z', c >= '0' && c <= '9':
+		case (c == '-' || c == '_') && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -3,13 +3,23 @@ package config_test
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/aldebaranode/syncguard/internal/config"
 	"github.com/aldebaranode/syncguard/internal/constants"
 )
 
+// logInTempDir runs the test from a temporary directory: loading a config
+// opens logging.file, which is relative, syncguard.log by default
+func logInTempDir(t *testing.T) {
+	t.Helper()
+	t.Chdir(t.TempDir())
+}
+
 func TestConfig_Load(t *testing.T) {
+	logInTempDir(t)
 	tmpDir := t.TempDir()
 	configPath := filepath.Join(tmpDir, "config.yaml")
 
@@ -42,7 +52,7 @@ failover:
 
 logging:
   level: "info"
-  file: "/tmp/test.log"
+  file: "test.log"
   verbose: false
 `
 
@@ -74,6 +84,7 @@ logging:
 }
 
 func TestConfig_LoadInvalid(t *testing.T) {
+	logInTempDir(t)
 	tmpDir := t.TempDir()
 
 	tests := []struct {
@@ -130,78 +141,1206 @@ cometbft:
 `,
 			wantErr: "cometbft.state_path is required",
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			configPath := filepath.Join(tmpDir, tt.name+".yaml")
-			if err := os.WriteFile(configPath, []byte(tt.content), 0644); err != nil {
-				t.Fatalf("Failed to write test config: %v", err)
-			}
-
-			_, err := config.Load(configPath)
-			if err == nil {
-				t.Errorf("Expected error containing %q, got nil", tt.wantErr)
-				return
-			}
-			if !containsString(err.Error(), tt.wantErr) {
-				t.Errorf("Error = %v, want containing %q", err, tt.wantErr)
-			}
-		})
-	}
-}
-
-func TestConfig_Defaults(t *testing.T) {
-	tmpDir := t.TempDir()
-	configPath := filepath.Join(tmpDir, "minimal.yaml")
-
-	minimalConfig := `
+		{
+			name: "email sink without recipients",
+			content: `
 secret: "test-secret"
 node:
   id: "test"
+  role: "active"
 cometbft:
   rpc_url: "http://localhost:26657"
   state_path: "/tmp/state.json"
-`
-
-	if err := os.WriteFile(configPath, []byte(minimalConfig), 0644); err != nil {
-		t.Fatalf("Failed to write test config: %v", err)
-	}
-
-	cfg, err := config.Load(configPath)
-	if err != nil {
-		t.Fatalf("Failed to load config: %v", err)
-	}
-
-	// Check defaults
-	if cfg.Node.Role != constants.NodeStatusPassive {
-		t.Errorf("Default role should be passive, got %s", cfg.Node.Role)
-	}
-	if cfg.Node.Port != 8080 {
-		t.Errorf("Default port should be 8080, got %d", cfg.Node.Port)
-	}
-	if cfg.Health.Interval != 5 {
-		t.Errorf("Default health interval should be 5, got %v", cfg.Health.Interval)
-	}
-	if cfg.Failover.RetryAttempts != 3 {
-		t.Errorf("Default retry attempts should be 3, got %d", cfg.Failover.RetryAttempts)
-	}
-}
-
-func TestConfig_IsActive(t *testing.T) {
-	cfg := &config.Config{
-		Node: config.NodeConfig{Role: "active"},
-	}
-	if !cfg.IsActive() {
-		t.Error("IsActive() should return true for active role")
-	}
-
-	cfg.Node.Role = constants.NodeStatusPassive
-	if cfg.IsActive() {
-		t.Error("IsActive() should return false for passive role")
-	}
-}
-
-func containsString(s, substr string) bool {
-	return len(s) >= len(substr) && (s == substr || len(s) > 0 && containsString(s[1:], substr) || s[:len(substr)] == substr)
+alerts:
+  sinks:
+    - type: email
+      smtp:
+        host: "smtp.example.com"
+        from: "syncguard@example.com"
+`,
+			wantErr: "alerts.sinks[0].smtp.from and smtp.to are required",
+		},
+		{
+			name: "unknown sink type",
+			content: `
+secret: "test-secret"
+node:
+  id: "test"
+  role: "active"
+cometbft:
+  rpc_url: "http://localhost:26657"
+  state_path: "/tmp/state.json"
+alerts:
+  sinks:
+    - type: pager
+`,
+			wantErr: "alerts.sinks[0].type must be",
+		},
+		{
+			name: "status page without component",
+			content: `
+secret: "test-secret"
+node:
+  id: "test"
+cometbft:
+  rpc_url: "http://localhost:26657"
+  state_path: "/tmp/state.json"
+alerts:
+  sinks:
+    - type: status_page
+      status_page:
+        provider: statuspage
+        api_key: "key"
+        page_id: "page"
+`,
+			wantErr: "alerts.sinks[0].status_page.api_key, page_id and component_id are required",
+		},
+		{
+			name: "telegram sink without chat",
+			content: `
+secret: "test-secret"
+node:
+  id: "test"
+cometbft:
+  rpc_url: "http://localhost:26657"
+  state_path: "/tmp/state.json"
+alerts:
+  sinks:
+    - type: telegram
+      telegram:
+        bot_token: "123:abc"
+`,
+			wantErr: "alerts.sinks[0].telegram.bot_token and chat_id are required",
+		},
+		{
+			name: "peer address without port",
+			content: `
+secret: "test-secret"
+node:
+  id: "test"
+  role: "active"
+peers:
+  - id: "peer-1"
+    address: "192.168.1.2"
+cometbft:
+  rpc_url: "http://localhost:26657"
+  state_path: "/tmp/state.json"
+`,
+			wantErr: `peers[0].address "192.168.1.2" is invalid`,
+		},
+		{
+			name: "signing misses beyond the window",
+			content: `
+secret: "test-secret"
+node:
+  id: "test"
+  role: "active"
+cometbft:
+  rpc_url: "http://localhost:26657"
+  state_path: "/tmp/state.json"
+chain:
+  signing:
+    enabled: true
+    window: 20
+    missed_blocks: 50
+`,
+			wantErr: "chain.signing.missed_blocks must be between 1 and chain.signing.window",
+		},
+		{
+			name: "heartbeat timeout not shorter than the health interval",
+			content: `
+secret: "test-secret"
+node:
+  id: "test"
+  role: "active"
+cometbft:
+  rpc_url: "http://localhost:26657"
+  state_path: "/tmp/state.json"
+health:
+  interval: 2
+peer_api:
+  timeouts:
+    heartbeat: 2
+`,
+			wantErr: "peer_api.timeouts.heartbeat must be shorter than health.interval (2s)",
+		},
+		{
+			name: "negative key transfer timeout",
+			content: `
+secret: "test-secret"
+node:
+  id: "test"
+  role: "active"
+cometbft:
+  rpc_url: "http://localhost:26657"
+  state_path: "/tmp/state.json"
+peer_api:
+  timeouts:
+    key: -1
+`,
+			wantErr: "peer_api.timeouts.key must be positive",
+		},
+		{
+			name: "unknown broadcast quorum",
+			content: `
+secret: "test-secret"
+node:
+  id: "test"
+  role: "active"
+cometbft:
+  rpc_url: "http://localhost:26657"
+  state_path: "/tmp/state.json"
+peer_api:
+  broadcast_quorum: "half"
+`,
+			wantErr: "peer_api.broadcast_quorum must be 'all', 'majority' or 'any'",
+		},
+		{
+			name: "unknown peer protocol",
+			content: `
+secret: "test-secret"
+node:
+  id: "test"
+  role: "active"
+cometbft:
+  rpc_url: "http://localhost:26657"
+  state_path: "/tmp/state.json"
+peer_api:
+  protocol: "quic"
+`,
+			wantErr: "peer_api.protocol must be 'http' or 'grpc'",
+		},
+		{
+			name: "unknown health verdict",
+			content: `
+secret: "test-secret"
+node:
+  id: "test"
+  role: "active"
+cometbft:
+  rpc_url: "http://localhost:26657"
+  state_path: "/tmp/state.json"
+health:
+  unknown: "ignore"
+`,
+			wantErr: "health.unknown must be 'pass', 'fail' or 'hold'",
+		},
+		{
+			name: "remote signer without chain id",
+			content: `
+secret: "test-secret"
+node:
+  id: "test"
+  role: "active"
+cometbft:
+  rpc_url: "http://localhost:26657"
+  state_path: "/tmp/state.json"
+signer:
+  enabled: true
+  address: "tcp://127.0.0.1:26659"
+`,
+			wantErr: "signer.chain_id is required",
+		},
+		{
+			name: "host memory limit given as a percentage",
+			content: `
+secret: "test-secret"
+node:
+  id: "test"
+  role: "active"
+cometbft:
+  rpc_url: "http://localhost:26657"
+  state_path: "/tmp/state.json"
+health:
+  host:
+    enabled: true
+    max_memory_used: 90
+`,
+			wantErr: "health.host.max_memory_used and max_iowait are fractions",
+		},
+		{
+			name: "witness address not a peer address",
+			content: `
+secret: "test-secret"
+node:
+  id: "test"
+  role: "active"
+cometbft:
+  rpc_url: "http://localhost:26657"
+  key_path: "/tmp/key.json"
+  state_path: "/tmp/state.json"
+witness:
+  address: "ftp://witness:8090"
+`,
+			wantErr: "witness.address",
+		},
+		{
+			name: "unknown maintenance on_error",
+			content: `
+secret: "test-secret"
+node:
+  id: "test"
+  role: "active"
+cometbft:
+  rpc_url: "http://localhost:26657"
+  key_path: "/tmp/key.json"
+  state_path: "/tmp/state.json"
+maintenance:
+  file: "/etc/syncguard/maintenance"
+  on_error: "ignore"
+`,
+			wantErr: "maintenance.on_error",
+		},
+		{
+			name: "artifact listing the validator key",
+			content: `
+secret: "test-secret"
+node:
+  id: "test"
+  role: "active"
+cometbft:
+  rpc_url: "http://localhost:26657"
+  key_path: "/tmp/priv_validator_key.json"
+  state_path: "/tmp/state.json"
+  artifacts:
+    - name: "key"
+      path: "/tmp/priv_validator_key.json"
+`,
+			wantErr: "is handed over already",
+		},
+		{
+			name: "unknown state format",
+			content: `
+secret: "test-secret"
+node:
+  id: "test"
+  role: "active"
+cometbft:
+  rpc_url: "http://localhost:26657"
+  state_path: "/tmp/state.json"
+  state_format: "horcrux"
+`,
+			wantErr: "cometbft.state_format must be 'cometbft-json' or 'tmkms-toml'",
+		},
+		{
+			name: "syslog output with an unknown network",
+			content: `
+secret: "test-secret"
+node:
+  id: "test"
+  role: "active"
+cometbft:
+  rpc_url: "http://localhost:26657"
+  state_path: "/tmp/state.json"
+logging:
+  outputs:
+    - type: syslog
+      syslog:
+        address: "logs.example.com"
+        network: "quic"
+`,
+			wantErr: "logging.outputs[0].syslog.network must be 'udp', 'tcp' or 'tls'",
+		},
+		{
+			name: "health policy with an unknown fact",
+			content: `
+secret: "test-secret"
+node:
+  id: "test"
+  role: "active"
+cometbft:
+  rpc_url: "http://localhost:26657"
+  state_path: "/tmp/state.json"
+health:
+  policy:
+    healthy: "height_lag < 5 && peer >= 3"
+`,
+			wantErr: `health.policy.healthy: policy "height_lag < 5 && peer >= 3": unknown fact "peer"`,
+		},
+		{
+			name: "advertise address without port",
+			content: `
+secret: "test-secret"
+node:
+  id: "test"
+  role: "active"
+  advertise_address: "203.0.113.7"
+peers:
+  - id: "peer-1"
+    address: "192.168.1.2:8080"
+cometbft:
+  rpc_url: "http://localhost:26657"
+  state_path: "/tmp/state.json"
+`,
+			wantErr: `node.advertise_address "203.0.113.7" is invalid`,
+		},
+		{
+			name: "instance with a path separator",
+			content: `
+secret: "test-secret"
+node:
+  id: "test"
+  role: "active"
+  instance: "story/mainnet"
+cometbft:
+  rpc_url: "http://localhost:26657"
+  state_path: "/tmp/state.json"
+`,
+			wantErr: `node.instance "story/mainnet" must be`,
+		},
+		{
+			name: "peer URL with unsupported scheme",
+			content: `
+secret: "test-secret"
+node:
+  id: "test"
+  role: "active"
+peers:
+  - id: "peer-1"
+    address: "tcp://192.168.1.2:8080"
+cometbft:
+  rpc_url: "http://localhost:26657"
+  state_path: "/tmp/state.json"
+`,
+			wantErr: "scheme must be http or https",
+		},
+		{
+			name: "unknown lock grace policy",
+			content: `
+secret: "test-secret"
+node:
+  id: "test"
+  role: "active"
+cometbft:
+  rpc_url: "http://localhost:26657"
+  state_path: "/tmp/state.json"
+lock:
+  on_grace_expired: "panic"
+`,
+			wantErr: "lock.on_grace_expired must be",
+		},
+		{
+			name: "etcd lock without endpoints",
+			content: `
+secret: "test-secret"
+node:
+  id: "test"
+  role: "active"
+cometbft:
+  rpc_url: "http://localhost:26657"
+  state_path: "/tmp/state.json"
+lock:
+  backend: "etcd"
+`,
+			wantErr: "lock.endpoints is required",
+		},
+		{
+			name: "consul lock outliving its grace",
+			content: `
+secret: "test-secret"
+node:
+  id: "test"
+  role: "active"
+cometbft:
+  rpc_url: "http://localhost:26657"
+  state_path: "/tmp/state.json"
+lock:
+  backend: "consul"
+  endpoints: ["http://127.0.0.1:8500"]
+  ttl: 30
+  grace_ttl: 60
+`,
+			wantErr: "lock.grace_ttl plus twice lock.check_interval",
+		},
+		{
+			name: "election renewing too rarely",
+			content: `
+secret: "test-secret"
+node:
+  id: "test"
+  role: "active"
+cometbft:
+  rpc_url: "http://localhost:26657"
+  state_path: "/tmp/state.json"
+peers:
+  - id: "backup"
+    address: "10.0.0.2:8080"
+election:
+  enabled: true
+  lease_ttl: 10
+  renew_interval: 6
+`,
+			wantErr: "election.renew_interval must be",
+		},
+		{
+			name: "election lease shorter than a node stop",
+			content: `
+secret: "test-secret"
+node:
+  id: "test"
+  role: "active"
+cometbft:
+  rpc_url: "http://localhost:26657"
+  state_path: "/tmp/state.json"
+validator:
+  stop_timeout: 30
+peers:
+  - id: "backup"
+    address: "10.0.0.2:8080"
+election:
+  enabled: true
+  lease_ttl: 15
+  renew_interval: 5
+`,
+			wantErr: "election.lease_ttl less a tenth must exceed",
+		},
+		{
+			name: "invalid drill schedule",
+			content: `
+secret: "test-secret"
+node:
+  id: "test"
+  role: "active"
+cometbft:
+  rpc_url: "http://localhost:26657"
+  state_path: "/tmp/state.json"
+drill:
+  schedule: "every tuesday"
+`,
+			wantErr: "drill.schedule",
+		},
+		{
+			name: "drill blackout without end",
+			content: `
+secret: "test-secret"
+node:
+  id: "test"
+  role: "active"
+cometbft:
+  rpc_url: "http://localhost:26657"
+  state_path: "/tmp/state.json"
+drill:
+  blackouts:
+    - start: "2026-12-24T00:00:00Z"
+`,
+			wantErr: "drill.blackouts[0] needs start and end",
+		},
+		{
+			name: "group member without admin url",
+			content: `
+secret: "test-secret"
+node:
+  id: "test"
+  role: "active"
+cometbft:
+  rpc_url: "http://localhost:26657"
+  state_path: "/tmp/state.json"
+group:
+  cascade: true
+  members:
+    - id: "consumer"
+`,
+			wantErr: "group.members[0].admin_url",
+		},
+		{
+			name: "service depends on unknown service",
+			content: `
+secret: "test-secret"
+node:
+  id: "test"
+  role: "active"
+cometbft:
+  rpc_url: "http://localhost:26657"
+  state_path: "/tmp/state.json"
+validator:
+  enabled: true
+  mode: "binary"
+  binary: "/usr/local/bin/story"
+  depends_on: ["signer"]
+  services:
+    - name: "tmkms"
+      mode: "binary"
+      binary: "/usr/local/bin/tmkms"
+`,
+			wantErr: `validator.depends_on: unknown service "signer"`,
+		},
+		{
+			name: "sentry dsn without key",
+			content: `
+secret: "test-secret"
+node:
+  id: "test"
+cometbft:
+  rpc_url: "http://localhost:26657"
+  state_path: "/tmp/state.json"
+error_tracking:
+  dsn: "https://sentry.example.com/42"
+`,
+			wantErr: "error_tracking.dsn must look like",
+		},
+		{
+			name: "unknown insecure key file policy",
+			content: `
+secret: "test-secret"
+node:
+  id: "test"
+cometbft:
+  rpc_url: "http://localhost:26657"
+  state_path: "/tmp/state.json"
+  insecure_key_files: "ignore"
+`,
+			wantErr: "cometbft.insecure_key_files must be",
+		},
+		{
+			name: "activation service not managed",
+			content: `
+secret: "test-secret"
+node:
+  id: "test"
+cometbft:
+  rpc_url: "http://localhost:26657"
+  state_path: "/tmp/state.json"
+validator:
+  activation:
+    method: "service"
+    service: "signer"
+`,
+			wantErr: "validator.activation.service must name",
+		},
+		{
+			name: "previous secret same as secret",
+			content: `
+secret: "test-secret"
+previous_secret: "test-secret"
+node:
+  id: "test"
+cometbft:
+  rpc_url: "http://localhost:26657"
+  state_path: "/tmp/state.json"
+`,
+			wantErr: "previous_secret must differ",
+		},
+		{
+			name: "cold standby upload without file",
+			content: `
+secret: "test-secret"
+node:
+  id: "test"
+cometbft:
+  rpc_url: "http://localhost:26657"
+  state_path: "/tmp/state.json"
+cold_standby:
+  enabled: true
+  transport: "command"
+  upload_command: "aws s3 cp - s3://standby/{name}"
+  activate_command: "ssh standby systemctl start story"
+`,
+			wantErr: "cold_standby.upload_command must contain {file}",
+		},
+		{
+			name: "approval with cold standby",
+			content: `
+secret: "test-secret"
+node:
+  id: "test"
+cometbft:
+  rpc_url: "http://localhost:26657"
+  state_path: "/tmp/state.json"
+failover:
+  approval:
+    enabled: true
+cold_standby:
+  enabled: true
+  host: "standby.example"
+  remote_key_path: "/home/story/.story/config/priv_validator_key.json"
+  remote_state_path: "/home/story/.story/data/priv_validator_state.json"
+  activate_command: "systemctl start story"
+`,
+			wantErr: "failover.approval does not apply with cold_standby",
+		},
+		{
+			name: "link check probe over request limit",
+			content: `
+secret: "test-secret"
+node:
+  id: "test"
+cometbft:
+  rpc_url: "http://localhost:26657"
+  state_path: "/tmp/state.json"
+failover:
+  link_check:
+    enabled: true
+    probe_bytes: "2MB"
+`,
+			wantErr: "failover.link_check.probe_bytes must be positive and at most peer_api.max_request_bytes",
+		},
+		{
+			name: "negative shutdown handoff deadline",
+			content: `
+secret: "test-secret"
+node:
+  id: "test"
+cometbft:
+  rpc_url: "http://localhost:26657"
+  state_path: "/tmp/state.json"
+failover:
+  shutdown_handoff:
+    enabled: true
+    deadline: -5
+`,
+			wantErr: "failover.shutdown_handoff.deadline must not be negative",
+		},
+		{
+			name: "unknown after reboot policy",
+			content: `
+secret: "test-secret"
+node:
+  id: "test"
+  after_reboot: "ignore"
+cometbft:
+  rpc_url: "http://localhost:26657"
+  state_path: "/tmp/state.json"
+`,
+			wantErr: "node.after_reboot must be 'reconcile' or 'trust'",
+		},
+		{
+			name: "state churn window too short",
+			content: `
+secret: "test-secret"
+node:
+  id: "test"
+cometbft:
+  rpc_url: "http://localhost:26657"
+  state_path: "/tmp/state.json"
+health:
+  state_churn:
+    enabled: true
+    interval: 10
+    window: 15
+`,
+			wantErr: "health.state_churn.window must be at least twice a positive interval",
+		},
+		{
+			name: "hook without command",
+			content: `
+secret: "test-secret"
+node:
+  id: "test"
+cometbft:
+  rpc_url: "http://localhost:26657"
+  state_path: "/tmp/state.json"
+failover:
+  post_hooks:
+    - name: "dns"
+      timeout: 10
+`,
+			wantErr: "failover.post_hooks[0].command must name an executable",
+		},
+		{
+			name: "negative invariant confirmations",
+			content: `
+secret: "test-secret"
+node:
+  id: "test"
+cometbft:
+  rpc_url: "http://localhost:26657"
+  state_path: "/tmp/state.json"
+invariants:
+  enabled: true
+  confirmations: -1
+`,
+			wantErr: "invariants.interval and confirmations must not be negative",
+		},
+		{
+			name: "negative double-sign guard depth",
+			content: `
+secret: "test-secret"
+node:
+  id: "test"
+cometbft:
+  rpc_url: "http://localhost:26657"
+  state_path: "/tmp/state.json"
+chain:
+  double_sign_guard:
+    enabled: true
+    depth: -5
+`,
+			wantErr: "chain.double_sign_guard.depth must not be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(tmpDir, tt.name+".yaml")
+			if err := os.WriteFile(configPath, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write test config: %v", err)
+			}
+
+			_, err := config.Load(configPath)
+			if err == nil {
+				t.Errorf("Expected error containing %q, got nil", tt.wantErr)
+				return
+			}
+			if !containsString(err.Error(), tt.wantErr) {
+				t.Errorf("Error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_LoadFormats(t *testing.T) {
+	logInTempDir(t)
+	tmpDir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{
+			name: "yaml",
+			file: "config.yml",
+			content: `
+secret: "test-secret"
+node:
+  id: "fmt-node"
+  role: "active"
+peers:
+  - id: "peer-1"
+    address: "192.168.1.2:8080"
+cometbft:
+  rpc_url: "http://localhost:26657"
+  state_path: "/tmp/state.json"
+failover:
+  retry_attempts: 4
+logging:
+  file: "test.log"
+`,
+		},
+		{
+			name: "toml",
+			file: "config.toml",
+			content: `
+secret = "test-secret"
+
+[node]
+id = "fmt-node"
+role = "active"
+
+[[peers]]
+id = "peer-1"
+address = "192.168.1.2:8080"
+
+[cometbft]
+rpc_url = "http://localhost:26657"
+state_path = "/tmp/state.json"
+
+[failover]
+retry_attempts = 4
+
+[logging]
+file = "test.log"
+`,
+		},
+		{
+			name: "json",
+			file: "config.json",
+			content: `{
+  "secret": "test-secret",
+  "node": {"id": "fmt-node", "role": "active"},
+  "peers": [{"id": "peer-1", "address": "192.168.1.2:8080"}],
+  "cometbft": {"rpc_url": "http://localhost:26657", "state_path": "/tmp/state.json"},
+  "failover": {"retry_attempts": 4},
+  "logging": {"file": "test.log"}
+}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(tmpDir, tt.file)
+			if err := os.WriteFile(configPath, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write test config: %v", err)
+			}
+
+			cfg, err := config.Load(configPath)
+			if err != nil {
+				t.Fatalf("Failed to load %s config: %v", tt.name, err)
+			}
+
+			if cfg.Node.ID != "fmt-node" {
+				t.Errorf("Node.ID = %s, want fmt-node", cfg.Node.ID)
+			}
+			if cfg.Node.Role != constants.NodeStatusActive {
+				t.Errorf("Node.Role = %s, want active", cfg.Node.Role)
+			}
+			if len(cfg.Peers) != 1 || cfg.Peers[0].Address != "192.168.1.2:8080" {
+				t.Errorf("Peers = %+v, want one peer at 192.168.1.2:8080", cfg.Peers)
+			}
+			if cfg.Failover.RetryAttempts != 4 {
+				t.Errorf("Failover.RetryAttempts = %d, want 4", cfg.Failover.RetryAttempts)
+			}
+			// Defaults apply regardless of format
+			if cfg.Node.Port != 8080 {
+				t.Errorf("Default port should be 8080, got %d", cfg.Node.Port)
+			}
+		})
+	}
+}
+
+func TestConfig_LoadUnsupportedFormat(t *testing.T) {
+	logInTempDir(t)
+	configPath := filepath.Join(t.TempDir(), "config.ini")
+	if err := os.WriteFile(configPath, []byte("secret=x"), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	_, err := config.Load(configPath)
+	if err == nil || !containsString(err.Error(), "unsupported config format") {
+		t.Errorf("Expected unsupported format error, got %v", err)
+	}
+}
+
+func TestConfig_UnknownKeys(t *testing.T) {
+	logInTempDir(t)
+	configPath := filepath.Join(t.TempDir(), "typo.yaml")
+	content := `
+secret: "test-secret"
+node:
+  id: "test"
+peers:
+  - id: "peer-1"
+    adress: "192.168.1.2:8080"
+cometbft:
+  rpc_url: "http://localhost:26657"
+  state_path: "/tmp/state.json"
+failover:
+  retry_attemps: 5
+logging:
+  file: "test.log"
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	_, err := config.Load(configPath)
+	if err == nil {
+		t.Fatal("Expected strict parsing to reject unknown keys")
+	}
+	for _, want := range []string{
+		`failover.retry_attemps (did you mean "failover.retry_attempts"?)`,
+		`peers[0].adress (did you mean "peers[0].address"?)`,
+	} {
+		if !containsString(err.Error(), want) {
+			t.Errorf("Error = %v, want containing %q", err, want)
+		}
+	}
+
+	// Lenient mode ignores unknown keys, but a peer without an address is
+	// still rejected by validation
+	_, err = config.LoadWithOptions(configPath, config.LoadOptions{Lenient: true})
+	if err == nil || !containsString(err.Error(), "peers[0].address is required") {
+		t.Fatalf("Expected missing peer address error, got %v", err)
+	}
+
+	content = strings.Replace(content, "adress", "address", 1)
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+	cfg, err := config.LoadWithOptions(configPath, config.LoadOptions{Lenient: true})
+	if err != nil {
+		t.Fatalf("Lenient load should succeed: %v", err)
+	}
+	if cfg.Failover.RetryAttempts != 3 {
+		t.Errorf("Misspelled key should be ignored, got retry attempts %d", cfg.Failover.RetryAttempts)
+	}
+}
+
+func TestConfig_Defaults(t *testing.T) {
+	logInTempDir(t)
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "minimal.yaml")
+
+	minimalConfig := `
+secret: "test-secret"
+node:
+  id: "test"
+cometbft:
+  rpc_url: "http://localhost:26657"
+  state_path: "/tmp/state.json"
+`
+
+	if err := os.WriteFile(configPath, []byte(minimalConfig), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	// Check defaults
+	if cfg.Node.Role != constants.NodeStatusPassive {
+		t.Errorf("Default role should be passive, got %s", cfg.Node.Role)
+	}
+	if cfg.Node.Port != 8080 {
+		t.Errorf("Default port should be 8080, got %d", cfg.Node.Port)
+	}
+	if cfg.Health.Interval != 5 {
+		t.Errorf("Default health interval should be 5, got %v", cfg.Health.Interval)
+	}
+	if cfg.Failover.RetryAttempts != 3 {
+		t.Errorf("Default retry attempts should be 3, got %d", cfg.Failover.RetryAttempts)
+	}
+}
+
+func TestConfig_IsActive(t *testing.T) {
+	cfg := &config.Config{
+		Node: config.NodeConfig{Role: "active"},
+	}
+	if !cfg.IsActive() {
+		t.Error("IsActive() should return true for active role")
+	}
+
+	cfg.Node.Role = constants.NodeStatusPassive
+	if cfg.IsActive() {
+		t.Error("IsActive() should return false for passive role")
+	}
+}
+
+func containsString(s, substr string) bool {
+	return len(s) >= len(substr) && (s == substr || len(s) > 0 && containsString(s[1:], substr) || s[:len(substr)] == substr)
+}
+
+func TestConfig_LoadLegacySchema(t *testing.T) {
+	logInTempDir(t)
+	configPath := filepath.Join(t.TempDir(), "legacy.yaml")
+	legacyConfig := `
+server:
+  id: "legacy-node"
+  role: "active"
+  is_primary: true
+  port: 9090
+  rpc_url: "http://localhost:26657"
+  state_path: "/tmp/state.json"
+communication:
+  protocol: "grpc"
+  secret: "legacy-secret"
+  peers:
+    - "192.168.1.2:9090"
+logging:
+  file: "test.log"
+`
+	if err := os.WriteFile(configPath, []byte(legacyConfig), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load legacy config: %v", err)
+	}
+
+	if cfg.Node.ID != "legacy-node" || cfg.Node.Port != 9090 || !cfg.Node.IsPrimary {
+		t.Errorf("Node not migrated: %+v", cfg.Node)
+	}
+	if cfg.Secret != "legacy-secret" {
+		t.Errorf("Secret = %q, want legacy-secret", cfg.Secret)
+	}
+	if cfg.CometBFT.StatePath != "/tmp/state.json" {
+		t.Errorf("CometBFT.StatePath = %q, want /tmp/state.json", cfg.CometBFT.StatePath)
+	}
+	if len(cfg.Peers) != 1 || cfg.Peers[0].Address != "192.168.1.2:9090" || cfg.Peers[0].ID != "peer-1" {
+		t.Errorf("Peers not migrated: %+v", cfg.Peers)
+	}
+
+	_, changes, err := config.MigrateFile(configPath)
+	if err != nil {
+		t.Fatalf("MigrateFile failed: %v", err)
+	}
+	found := false
+	for _, change := range changes {
+		if change.Old == "communication.protocol" && change.New == "" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected dropped protocol key in changes, got %v", changes)
+	}
+}
+
+func TestConfig_LoadWitness(t *testing.T) {
+	logInTempDir(t)
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "witness.yaml")
+	content := `
+secret: "test-secret"
+node:
+  id: "witness-1"
+peers:
+  - id: "validator-1"
+    address: "10.0.1.10:8080"
+  - id: "validator-2"
+    address: "https://validator-2.example.com"
+witness:
+  lease_ttl: 90
+logging:
+  file: "/dev/null"
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	cfg, err := config.LoadWitness(configPath, config.LoadOptions{})
+	if err != nil {
+		t.Fatalf("Failed to load witness config: %v", err)
+	}
+	if cfg.Witness.Listen != "0.0.0.0:8090" {
+		t.Errorf("Witness.Listen = %q, want default 0.0.0.0:8090", cfg.Witness.Listen)
+	}
+	if cfg.Witness.LeaseTTL != 90 {
+		t.Errorf("Witness.LeaseTTL = %v, want 90", cfg.Witness.LeaseTTL)
+	}
+
+	// The same file is not a valid node config
+	if _, err := config.Load(configPath); err == nil {
+		t.Error("Witness config should not load as a node config")
+	}
+
+	// With node.role: witness it loads as a node config too
+	withRole := filepath.Join(tmpDir, "witness-role.yaml")
+	os.WriteFile(withRole, []byte(strings.Replace(content, `id: "witness-1"`, "id: \"witness-1\"\n  role: \"witness\"", 1)), 0644)
+	if cfg, err := config.Load(withRole); err != nil || cfg.Node.Role != constants.NodeStatusWitness {
+		t.Errorf("Witness role config should load as a witness, got %v", err)
+	}
+
+	noPeers := filepath.Join(tmpDir, "no-peers.yaml")
+	os.WriteFile(noPeers, []byte("secret: s\nnode:\n  id: w\n"), 0644)
+	if _, err := config.LoadWitness(noPeers, config.LoadOptions{}); err == nil || !containsString(err.Error(), "peers must list") {
+		t.Errorf("Expected missing peers error, got %v", err)
+	}
+}
+
+func TestParseSeconds(t *testing.T) {
+	tests := []struct {
+		text string
+		want time.Duration
+	}{
+		{"30", 30 * time.Second},
+		{"2.5", 2500 * time.Millisecond},
+		{"30s", 30 * time.Second},
+		{"250ms", 250 * time.Millisecond},
+		{"5m", 5 * time.Minute},
+		{"1h30m", 90 * time.Minute},
+	}
+	for _, tt := range tests {
+		got, err := config.ParseSeconds(tt.text)
+		if err != nil {
+			t.Errorf("ParseSeconds(%q) failed: %v", tt.text, err)
+			continue
+		}
+		if got.Duration() != tt.want {
+			t.Errorf("ParseSeconds(%q) = %v, want %v", tt.text, got.Duration(), tt.want)
+		}
+	}
+
+	_, err := config.ParseSeconds("5 minutes")
+	if err == nil || !strings.Contains(err.Error(), `"1h30m"`) {
+		t.Errorf("Expected error listing accepted formats, got %v", err)
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		text string
+		want config.Size
+	}{
+		{"512", 512},
+		{"512B", 512},
+		{"64KB", 64 * config.Kilobyte},
+		{"100MB", 100 * config.Megabyte},
+		{"100 mb", 100 * config.Megabyte},
+		{"1.5GiB", 1536 * config.Megabyte},
+		{"2T", 2 * config.Terabyte},
+	}
+	for _, tt := range tests {
+		got, err := config.ParseSize(tt.text)
+		if err != nil {
+			t.Errorf("ParseSize(%q) failed: %v", tt.text, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseSize(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+
+	for _, text := range []string{"", "MB", "10XB", "ten"} {
+		_, err := config.ParseSize(text)
+		if err == nil || !strings.Contains(err.Error(), `"100MB"`) {
+			t.Errorf("ParseSize(%q): expected error listing accepted formats, got %v", text, err)
+		}
+	}
+
+	if s := (100 * config.Megabyte).String(); s != "100MB" {
+		t.Errorf("String() = %q, want 100MB", s)
+	}
+}
+
+func TestConfig_LoadUnits(t *testing.T) {
+	logInTempDir(t)
+	configPath := filepath.Join(t.TempDir(), "units.yaml")
+	content := `
+secret: "test-secret"
+node:
+  id: "test"
+cometbft:
+  rpc_url: "http://localhost:26657"
+  state_path: "/tmp/state.json"
+health:
+  interval: "10s"
+  timeout: 3
+failover:
+  grace_period: "2m"
+history:
+  max_size: "100MB"
+self_monitor:
+  max_memory_mb: 256
+peer_api:
+  max_request_bytes: "2MiB"
+logging:
+  file: "/dev/null"
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Health.Interval.Duration() != 10*time.Second {
+		t.Errorf("Health.Interval = %v, want 10s", cfg.Health.Interval)
+	}
+	if cfg.Health.Timeout.Duration() != 3*time.Second {
+		t.Errorf("Health.Timeout = %v, want 3s", cfg.Health.Timeout)
+	}
+	if cfg.Failover.GracePeriod.Duration() != 2*time.Minute {
+		t.Errorf("Failover.GracePeriod = %v, want 2m", cfg.Failover.GracePeriod)
+	}
+	if cfg.History.MaxSize != 100*config.Megabyte {
+		t.Errorf("History.MaxSize = %v, want 100MB", cfg.History.MaxSize)
+	}
+	if cfg.SelfMonitor.MaxMemory != 256*config.Megabyte {
+		t.Errorf("SelfMonitor.MaxMemory = %v, want 256MB from max_memory_mb", cfg.SelfMonitor.MaxMemory)
+	}
+	if cfg.PeerAPI.MaxRequestBytes != 2*config.Megabyte {
+		t.Errorf("PeerAPI.MaxRequestBytes = %v, want 2MB", cfg.PeerAPI.MaxRequestBytes)
+	}
+
+	bad := strings.Replace(content, `interval: "10s"`, `interval: "ten seconds"`, 1)
+	if err := os.WriteFile(configPath, []byte(bad), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+	if _, err := config.Load(configPath); err == nil || !strings.Contains(err.Error(), "invalid duration") {
+		t.Errorf("Expected invalid duration error, got %v", err)
+	}
 }
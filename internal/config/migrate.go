@@ -0,0 +1,160 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/spf13/viper"
+)
+
+// legacyKeyMap maps keys of the old Server/Communication schema to the
+// current Node/CometBFT schema. A nil destination marks keys that no longer
+// have an equivalent and are dropped with a warning.
+var legacyKeyMap = map[string][]string{
+	"server.id":              {"node", "id"},
+	"server.role":            {"node", "role"},
+	"server.is_primary":      {"node", "is_primary"},
+	"server.port":            {"node", "port"},
+	"server.rpc_url":         {"cometbft", "rpc_url"},
+	"server.state_path":      {"cometbft", "state_path"},
+	"server.key_path":        {"cometbft", "key_path"},
+	"server.backup_path":     {"cometbft", "backup_path"},
+	"communication.secret":   {"secret"},
+	"communication.peers":    {"peers"},
+	"communication.timeout":  {"health", "timeout"},
+	"communication.protocol": nil,
+}
+
+// KeyChange describes how a legacy key was handled during migration.
+// New is empty when the key was dropped.
+type KeyChange struct {
+	Old string
+	New string
+}
+
+// String renders the change as a human-readable warning
+func (c KeyChange) String() string {
+	if c.New == "" {
+		return fmt.Sprintf("%s is no longer supported and was dropped", c.Old)
+	}
+	return fmt.Sprintf("%s is deprecated, use %s", c.Old, c.New)
+}
+
+// IsLegacy reports whether raw settings use the old Server/Communication schema
+func IsLegacy(settings map[string]interface{}) bool {
+	_, hasNode := settings["node"]
+	_, hasServer := settings["server"]
+	_, hasCommunication := settings["communication"]
+	return !hasNode && (hasServer || hasCommunication)
+}
+
+// MigrateLegacy rewrites old-schema settings into the current schema.
+// Sections that already use the current layout are carried over unchanged.
+// The returned changes describe every deprecated key that was rewritten or dropped.
+func MigrateLegacy(settings map[string]interface{}) (map[string]interface{}, []KeyChange, error) {
+	migrated := make(map[string]interface{})
+	var changes []KeyChange
+
+	for section, value := range settings {
+		if section != "server" && section != "communication" {
+			migrated[section] = value
+			continue
+		}
+
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, nil, fmt.Errorf("legacy section %q must be a map", section)
+		}
+
+		for key, fieldValue := range fields {
+			oldKey := section + "." + key
+			dest := legacyKeyMap[oldKey]
+			if dest == nil {
+				changes = append(changes, KeyChange{Old: oldKey})
+				continue
+			}
+
+			if oldKey == "communication.peers" {
+				peers, err := migratePeers(fieldValue)
+				if err != nil {
+					return nil, nil, err
+				}
+				fieldValue = peers
+			}
+
+			setNested(migrated, dest, fieldValue)
+			changes = append(changes, KeyChange{Old: oldKey, New: joinKey(dest)})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Old < changes[j].Old })
+	return migrated, changes, nil
+}
+
+// MigrateFile reads a config file of any supported format and returns its
+// settings rewritten into the current schema
+func MigrateFile(path string) (map[string]interface{}, []KeyChange, error) {
+	format, err := detectFormat(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType(format)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	if !IsLegacy(v.AllSettings()) {
+		return nil, nil, fmt.Errorf("%s already uses the current schema", path)
+	}
+
+	return MigrateLegacy(v.AllSettings())
+}
+
+// migratePeers accepts both plain address strings and {id, address} maps
+func migratePeers(value interface{}) ([]interface{}, error) {
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("communication.peers must be a list")
+	}
+
+	peers := make([]interface{}, 0, len(list))
+	for i, item := range list {
+		switch peer := item.(type) {
+		case string:
+			peers = append(peers, map[string]interface{}{
+				"id":      fmt.Sprintf("peer-%d", i+1),
+				"address": peer,
+			})
+		case map[string]interface{}:
+			peers = append(peers, peer)
+		default:
+			return nil, fmt.Errorf("communication.peers[%d] must be an address or a map", i)
+		}
+	}
+	return peers, nil
+}
+
+// setNested assigns value at the given key path, creating maps as needed
+func setNested(settings map[string]interface{}, path []string, value interface{}) {
+	current := settings
+	for _, key := range path[:len(path)-1] {
+		next, ok := current[key].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			current[key] = next
+		}
+		current = next
+	}
+	current[path[len(path)-1]] = value
+}
+
+func joinKey(path []string) string {
+	key := path[0]
+	for _, p := range path[1:] {
+		key += "." + p
+	}
+	return key
+}
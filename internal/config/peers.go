@@ -0,0 +1,37 @@
+package config
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// ValidatePeerAddress accepts host:port or an http(s) URL with a host
+func ValidatePeerAddress(addr string) error {
+	if strings.Contains(addr, "://") {
+		u, err := url.Parse(addr)
+		if err != nil {
+			return err
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return errors.New("scheme must be http or https")
+		}
+		if u.Host == "" {
+			return errors.New("URL has no host")
+		}
+		return nil
+	}
+
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return errors.New("expected host:port or http(s)://host:port")
+	}
+	if host == "" {
+		return errors.New("host is empty")
+	}
+	if port == "" {
+		return errors.New("port is empty")
+	}
+	return nil
+}
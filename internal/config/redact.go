@@ -0,0 +1,87 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/spf13/viper"
+)
+
+// redactedValue replaces secrets in redacted output
+const redactedValue = "REDACTED"
+
+// secretKeys are config keys whose values are always redacted
+var secretKeys = map[string]bool{
+	"secret":          true,
+	"previous_secret": true,
+	"password":        true,
+	"token":           true,
+	"community":       true,
+}
+
+// RedactedSettings reads a config file and returns its settings with
+// secrets removed, suitable for attaching to bug reports. URLs keep only
+// their scheme and host, since webhook and ping URLs embed credentials.
+func RedactedSettings(path string) (map[string]interface{}, error) {
+	format, err := detectFormat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType(format)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	return redactMap(v.AllSettings()), nil
+}
+
+// redactMap returns a copy of settings with secret values replaced
+func redactMap(settings map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(settings))
+	for key, value := range settings {
+		out[key] = redactValue(key, value)
+	}
+	return out
+}
+
+// redactValue redacts a single setting, recursing into maps and lists
+func redactValue(key string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return redactMap(v)
+	case []interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = redactValue(key, item)
+		}
+		return items
+	case string:
+		if v == "" {
+			return v
+		}
+		if secretKeys[key] {
+			return redactedValue
+		}
+		if key == "url" {
+			return redactURL(v)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// redactURL keeps the scheme and host of a URL
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return redactedValue
+	}
+	if u.Path == "" && u.RawQuery == "" && u.User == nil {
+		return raw
+	}
+	return u.Scheme + "://" + u.Host + "/" + redactedValue
+}
@@ -0,0 +1,144 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// UnknownKeyError reports config keys that do not map to any field
+type UnknownKeyError struct {
+	Keys        []string
+	Suggestions map[string]string
+}
+
+func (e *UnknownKeyError) Error() string {
+	parts := make([]string, 0, len(e.Keys))
+	for _, key := range e.Keys {
+		if suggestion, ok := e.Suggestions[key]; ok {
+			parts = append(parts, fmt.Sprintf("%s (did you mean %q?)", key, suggestion))
+		} else {
+			parts = append(parts, key)
+		}
+	}
+	return fmt.Sprintf("unknown config keys: %s", strings.Join(parts, ", "))
+}
+
+// checkUnknownKeys walks the raw settings against the Config struct and
+// returns an UnknownKeyError listing every key that would be silently ignored
+func checkUnknownKeys(settings map[string]interface{}) error {
+	unknown := &UnknownKeyError{Suggestions: make(map[string]string)}
+	walkSettings(settings, reflect.TypeOf(Config{}), "", unknown)
+
+	if len(unknown.Keys) == 0 {
+		return nil
+	}
+	sort.Strings(unknown.Keys)
+	return unknown
+}
+
+// walkSettings recursively compares a settings map with a struct type
+func walkSettings(settings map[string]interface{}, t reflect.Type, prefix string, unknown *UnknownKeyError) {
+	fields := structFields(t)
+
+	for key, value := range settings {
+		fullKey := prefix + key
+		field, ok := fields[strings.ToLower(key)]
+		if !ok {
+			unknown.Keys = append(unknown.Keys, fullKey)
+			if suggestion := closestKey(strings.ToLower(key), fields); suggestion != "" {
+				unknown.Suggestions[fullKey] = prefix + suggestion
+			}
+			continue
+		}
+
+		fieldType := indirectType(field)
+		switch nested := value.(type) {
+		case map[string]interface{}:
+			if fieldType.Kind() == reflect.Struct {
+				walkSettings(nested, fieldType, fullKey+".", unknown)
+			}
+		case []interface{}:
+			if fieldType.Kind() != reflect.Slice {
+				continue
+			}
+			elemType := indirectType(fieldType.Elem())
+			if elemType.Kind() != reflect.Struct {
+				continue
+			}
+			for i, item := range nested {
+				if itemMap, ok := item.(map[string]interface{}); ok {
+					walkSettings(itemMap, elemType, fmt.Sprintf("%s[%d].", fullKey, i), unknown)
+				}
+			}
+		}
+	}
+}
+
+// structFields returns the mapstructure keys of a struct type
+func structFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := strings.Split(f.Tag.Get("mapstructure"), ",")[0]
+		if tag == "" || tag == "-" {
+			continue
+		}
+		fields[tag] = f.Type
+	}
+	return fields
+}
+
+// indirectType dereferences pointer types
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// closestKey returns the known key nearest to key, if it is a plausible typo
+func closestKey(key string, fields map[string]reflect.Type) string {
+	best := ""
+	bestDistance := -1
+	for candidate := range fields {
+		d := levenshtein(key, candidate)
+		if bestDistance == -1 || d < bestDistance || (d == bestDistance && candidate < best) {
+			best = candidate
+			bestDistance = d
+		}
+	}
+
+	// Allow roughly one typo per four characters, and at least one
+	maxDistance := len(key) / 4
+	if maxDistance < 1 {
+		maxDistance = 1
+	}
+	if bestDistance < 0 || bestDistance > maxDistance {
+		return ""
+	}
+	return best
+}
+
+// levenshtein computes the edit distance between two strings
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// Seconds is a config duration. It accepts Go duration strings ("30s",
+// "5m", "1h30m", "250ms") and, as before, plain numbers of seconds.
+type Seconds float64
+
+// Duration converts the value to a time.Duration
+func (s Seconds) Duration() time.Duration {
+	return time.Duration(float64(s) * float64(time.Second))
+}
+
+// String renders the value as a Go duration
+func (s Seconds) String() string {
+	return s.Duration().String()
+}
+
+// Size is a config size in bytes. It accepts plain numbers of bytes and
+// numbers with a unit: B, KB, MB, GB, TB or KiB, MiB, GiB, TiB. Both
+// spellings are powers of 1024, so "100MB" is 100 * 1024 * 1024 bytes.
+type Size int64
+
+// Size units
+const (
+	Byte     Size = 1
+	Kilobyte      = 1024 * Byte
+	Megabyte      = 1024 * Kilobyte
+	Gigabyte      = 1024 * Megabyte
+	Terabyte      = 1024 * Gigabyte
+)
+
+// sizeUnits maps unit suffixes to their size in bytes
+var sizeUnits = map[string]Size{
+	"":    Byte,
+	"b":   Byte,
+	"k":   Kilobyte,
+	"kb":  Kilobyte,
+	"kib": Kilobyte,
+	"m":   Megabyte,
+	"mb":  Megabyte,
+	"mib": Megabyte,
+	"g":   Gigabyte,
+	"gb":  Gigabyte,
+	"gib": Gigabyte,
+	"t":   Terabyte,
+	"tb":  Terabyte,
+	"tib": Terabyte,
+}
+
+// Formats shown when a value cannot be parsed
+const (
+	secondsFormats = `a number of seconds or a duration like "30s", "5m", "1h30m"`
+	sizeFormats    = `a number of bytes or a size like "512KB", "100MB", "1.5GiB"`
+)
+
+// ParseSeconds parses a duration string or a number of seconds
+func ParseSeconds(text string) (Seconds, error) {
+	text = strings.TrimSpace(text)
+	if n, err := strconv.ParseFloat(text, 64); err == nil {
+		return Seconds(n), nil
+	}
+	d, err := time.ParseDuration(text)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q: use %s", text, secondsFormats)
+	}
+	return Seconds(d.Seconds()), nil
+}
+
+// ParseSize parses a size with an optional unit
+func ParseSize(text string) (Size, error) {
+	trimmed := strings.TrimSpace(text)
+	split := strings.IndexFunc(trimmed, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.' && r != '-' && r != '+'
+	})
+	number, unit := trimmed, ""
+	if split >= 0 {
+		number, unit = trimmed[:split], strings.ToLower(strings.TrimSpace(trimmed[split:]))
+	}
+
+	n, err := strconv.ParseFloat(number, 64)
+	scale, ok := sizeUnits[unit]
+	if err != nil || !ok || math.IsInf(n, 0) || math.IsNaN(n) {
+		return 0, fmt.Errorf("invalid size %q: use %s", text, sizeFormats)
+	}
+	return Size(n * float64(scale)), nil
+}
+
+// String renders the size with the largest unit that divides it evenly
+func (s Size) String() string {
+	for _, u := range []struct {
+		suffix string
+		size   Size
+	}{{"TB", Terabyte}, {"GB", Gigabyte}, {"MB", Megabyte}, {"KB", Kilobyte}} {
+		if s != 0 && s%u.size == 0 {
+			return fmt.Sprintf("%d%s", s/u.size, u.suffix)
+		}
+	}
+	return fmt.Sprintf("%dB", int64(s))
+}
+
+var (
+	secondsType = reflect.TypeOf(Seconds(0))
+	sizeType    = reflect.TypeOf(Size(0))
+)
+
+// unitsDecodeHook parses strings into Seconds and Size fields; numbers are
+// left to the default decoding
+func unitsDecodeHook(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+	text, ok := data.(string)
+	if !ok {
+		return data, nil
+	}
+	switch to {
+	case secondsType:
+		return ParseSeconds(text)
+	case sizeType:
+		return ParseSize(text)
+	}
+	return data, nil
+}
+
+// decodeHook is viper's default hook chain with unit parsing in front
+var decodeHook = mapstructure.ComposeDecodeHookFunc(
+	unitsDecodeHook,
+	mapstructure.StringToTimeDurationHookFunc(),
+	mapstructure.StringToSliceHookFunc(","),
+)
@@ -8,6 +8,8 @@ type NodeManagerType string
 const (
 	NodeStatusActive  NodeStatus = "active"
 	NodeStatusPassive NodeStatus = "passive"
+	// NodeStatusWitness runs the arbiter instead of a validator
+	NodeStatusWitness NodeStatus = "witness"
 
 	NodeManagerTypeBinary        NodeManagerType = "binary"
 	NodeManagerTypeDocker        NodeManagerType = "docker"
@@ -0,0 +1,40 @@
+package constants
+
+// Reason is the machine-readable cause of a change of active role. It is
+// carried by transition alerts, history entries and metrics labels.
+type Reason string
+
+const (
+	// ReasonRPCTimeout: the CometBFT RPC did not answer
+	ReasonRPCTimeout Reason = "rpc_timeout"
+	// ReasonHeightStall: the block height stopped advancing
+	ReasonHeightStall Reason = "height_stall"
+	// ReasonUnhealthy: another health check failed (sync, peers, execution client, health_cmd)
+	ReasonUnhealthy Reason = "unhealthy"
+	// ReasonOperatorManual: an operator ran handoff or failback
+	ReasonOperatorManual Reason = "operator_manual"
+	// ReasonPeerRequest: the peer asked this node to take over or release
+	ReasonPeerRequest Reason = "peer_request"
+	// ReasonWatchdog: the lock grace TTL expired and signing was stopped
+	ReasonWatchdog Reason = "watchdog"
+	// ReasonDrill: a failover drill handed over or failed back
+	ReasonDrill Reason = "drill"
+	// ReasonPrimaryRecovered: the primary failed back automatically
+	ReasonPrimaryRecovered Reason = "primary_recovered"
+	// ReasonRecovery: a transition interrupted by a crash was finished on start
+	ReasonRecovery Reason = "recovery"
+	// ReasonHealthDisputed: the passive saw this node lagging while it reported healthy
+	ReasonHealthDisputed Reason = "health_disputed"
+	// ReasonNotSigning: recent blocks lacked this node's precommits while it was active
+	ReasonNotSigning Reason = "not_signing"
+	// ReasonStateChurn: the validator state file advanced far slower than usual while active
+	ReasonStateChurn Reason = "state_churn"
+	// ReasonLeaseLost: a majority stopped renewing this node's election lease
+	ReasonLeaseLost Reason = "lease_lost"
+	// ReasonShutdown: syncguard was stopped on the active node and handed over first
+	ReasonShutdown Reason = "shutdown"
+	// ReasonInvariant: the invariant checker fenced a node that had the real key but should not sign
+	ReasonInvariant Reason = "invariant_violation"
+	// ReasonSigningUnknown: the RPC answered but did not report the validator key, with health.unknown "fail"
+	ReasonSigningUnknown Reason = "signing_unknown"
+)
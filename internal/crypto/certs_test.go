@@ -0,0 +1,45 @@
+package crypto
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+)
+
+func TestIssueCertificate_VerifiesAgainstCA(t *testing.T) {
+	ca, err := GenerateCA("Test CA", time.Hour)
+	if err != nil {
+		t.Fatalf("GenerateCA failed: %v", err)
+	}
+
+	bundle, err := IssueCertificate(ca, "validator-1", []string{"10.0.0.5", "val1.example.com"}, time.Hour)
+	if err != nil {
+		t.Fatalf("IssueCertificate failed: %v", err)
+	}
+
+	caBlock, _ := pem.Decode(ca.CertPEM)
+	caCert, _ := x509.ParseCertificate(caBlock.Bytes)
+	pool := x509.NewCertPool()
+	pool.AddCert(caCert)
+
+	block, _ := pem.Decode(bundle.CertPEM)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("Failed to parse issued certificate: %v", err)
+	}
+
+	for _, name := range []string{"validator-1", "val1.example.com", "10.0.0.5"} {
+		if _, err := cert.Verify(x509.VerifyOptions{
+			DNSName:   name,
+			Roots:     pool,
+			KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+		}); err != nil {
+			t.Errorf("Certificate should verify for %s: %v", name, err)
+		}
+	}
+
+	if _, err := cert.Verify(x509.VerifyOptions{DNSName: "other-node", Roots: pool}); err == nil {
+		t.Error("Certificate should not verify for an unrelated name")
+	}
+}
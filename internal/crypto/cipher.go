@@ -5,6 +5,7 @@ import (
 	"crypto/cipher"
 	"crypto/rand"
 	"crypto/sha256"
+	"errors"
 	"io"
 
 	"golang.org/x/crypto/hkdf"
@@ -59,6 +60,9 @@ func Encrypt(data []byte, secret string) ([]byte, error) {
 }
 
 func Decrypt(data []byte, secret string) ([]byte, error) {
+	if len(data) < SALT_SIZE+NONCE_SIZE {
+		return nil, errors.New("ciphertext too short")
+	}
 	salt := data[:SALT_SIZE]
 	nonce := data[SALT_SIZE : SALT_SIZE+NONCE_SIZE]
 	ciphertext := data[SALT_SIZE+NONCE_SIZE:]
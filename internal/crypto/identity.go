@@ -0,0 +1,148 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// Headers carrying the per-node request signature
+const (
+	HeaderNodeID    = "X-Syncguard-Node"
+	HeaderTimestamp = "X-Syncguard-Timestamp"
+	HeaderSignature = "X-Syncguard-Signature"
+)
+
+// Identity is this node's ed25519 keypair used to sign peer messages
+type Identity struct {
+	NodeID     string
+	PrivateKey ed25519.PrivateKey
+}
+
+// identityFile is the on-disk format of an identity key
+type identityFile struct {
+	NodeID     string `json:"node_id"`
+	PublicKey  string `json:"public_key"`
+	PrivateKey string `json:"private_key"`
+}
+
+// LoadOrCreateIdentity loads the identity key at path, generating and
+// persisting a new one on first start
+func LoadOrCreateIdentity(path, nodeID string) (*Identity, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		var f identityFile
+		if err := json.Unmarshal(data, &f); err != nil {
+			return nil, fmt.Errorf("failed to parse identity file: %w", err)
+		}
+		priv, err := base64.StdEncoding.DecodeString(f.PrivateKey)
+		if err != nil || len(priv) != ed25519.PrivateKeySize {
+			return nil, fmt.Errorf("invalid private key in identity file")
+		}
+		if f.NodeID != nodeID {
+			return nil, fmt.Errorf("identity file belongs to node %q, not %q", f.NodeID, nodeID)
+		}
+		return &Identity{NodeID: nodeID, PrivateKey: ed25519.PrivateKey(priv)}, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read identity file: %w", err)
+	}
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate identity key: %w", err)
+	}
+
+	out, err := json.MarshalIndent(identityFile{
+		NodeID:     nodeID,
+		PublicKey:  base64.StdEncoding.EncodeToString(pub),
+		PrivateKey: base64.StdEncoding.EncodeToString(priv),
+	}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal identity: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create identity directory: %w", err)
+	}
+	if err := os.WriteFile(path, out, 0600); err != nil {
+		return nil, fmt.Errorf("failed to write identity file: %w", err)
+	}
+
+	return &Identity{NodeID: nodeID, PrivateKey: priv}, nil
+}
+
+// PublicKey returns the base64-encoded public key for pinning on peers
+func (id *Identity) PublicKey() string {
+	return base64.StdEncoding.EncodeToString(id.PrivateKey.Public().(ed25519.PublicKey))
+}
+
+// SignRequest signs a peer request and returns the signature headers
+func (id *Identity) SignRequest(method, path string, body []byte, timestamp int64) map[string]string {
+	msg := canonicalRequest(id.NodeID, method, path, body, timestamp)
+	return map[string]string{
+		HeaderNodeID:    id.NodeID,
+		HeaderTimestamp: strconv.FormatInt(timestamp, 10),
+		HeaderSignature: base64.StdEncoding.EncodeToString(ed25519.Sign(id.PrivateKey, msg)),
+	}
+}
+
+// VerifyRequest checks a signed peer request against a pinned public key.
+// Requests older than maxAge (or too far in the future) are rejected to
+// limit replay.
+func VerifyRequest(publicKey, nodeID, method, path string, body []byte, timestamp int64, signature string, maxAge time.Duration) error {
+	if err := checkSkew(timestamp, maxAge); err != nil {
+		return err
+	}
+
+	pub, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid pinned public key for %s", nodeID)
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("malformed signature")
+	}
+
+	msg := canonicalRequest(nodeID, method, path, body, timestamp)
+	if !ed25519.Verify(ed25519.PublicKey(pub), msg, sig) {
+		return fmt.Errorf("signature verification failed for %s", nodeID)
+	}
+	return nil
+}
+
+// SignRequestHMAC signs a peer request with the cluster secret, for nodes
+// without an identity key. It sets the same headers as SignRequest.
+func SignRequestHMAC(secret, nodeID, method, path string, body []byte, timestamp int64) map[string]string {
+	return map[string]string{
+		HeaderNodeID:    nodeID,
+		HeaderTimestamp: strconv.FormatInt(timestamp, 10),
+		HeaderSignature: Sign(string(canonicalRequest(nodeID, method, path, body, timestamp)), secret),
+	}
+}
+
+// checkSkew rejects a request timestamp older than maxAge, or as far in
+// the future
+func checkSkew(timestamp int64, maxAge time.Duration) error {
+	skew := time.Since(time.Unix(timestamp, 0))
+	if skew > maxAge || skew < -maxAge {
+		return fmt.Errorf("request timestamp outside allowed window")
+	}
+	return nil
+}
+
+// canonicalRequest is the exact byte string covered by a request signature
+func canonicalRequest(nodeID, method, path string, body []byte, timestamp int64) []byte {
+	bodyHash := sha256.Sum256(body)
+	return []byte(fmt.Sprintf("%s\n%s\n%s\n%d\n%s",
+		nodeID, method, path, timestamp, hex.EncodeToString(bodyHash[:])))
+}
@@ -0,0 +1,130 @@
+package crypto
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestIdentity_SignAndVerifyRequest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "identity.json")
+	id, err := LoadOrCreateIdentity(path, "node-1")
+	if err != nil {
+		t.Fatalf("Failed to create identity: %v", err)
+	}
+
+	// Reloading returns the same key
+	reloaded, err := LoadOrCreateIdentity(path, "node-1")
+	if err != nil {
+		t.Fatalf("Failed to reload identity: %v", err)
+	}
+	if reloaded.PublicKey() != id.PublicKey() {
+		t.Error("Reloaded identity has a different public key")
+	}
+
+	body := []byte(`{"height":"100"}`)
+	ts := time.Now().Unix()
+	headers := id.SignRequest("POST", "/validator_key", body, ts)
+
+	if err := VerifyRequest(id.PublicKey(), "node-1", "POST", "/validator_key", body, ts,
+		headers[HeaderSignature], time.Minute); err != nil {
+		t.Errorf("Valid signature rejected: %v", err)
+	}
+
+	if err := VerifyRequest(id.PublicKey(), "node-1", "POST", "/validator_key", []byte("tampered"), ts,
+		headers[HeaderSignature], time.Minute); err == nil {
+		t.Error("Tampered body should fail verification")
+	}
+
+	if err := VerifyRequest(id.PublicKey(), "node-2", "POST", "/validator_key", body, ts,
+		headers[HeaderSignature], time.Minute); err == nil {
+		t.Error("Signature from a different node ID should fail verification")
+	}
+
+	old := time.Now().Add(-time.Hour).Unix()
+	oldHeaders := id.SignRequest("POST", "/validator_key", body, old)
+	if err := VerifyRequest(id.PublicKey(), "node-1", "POST", "/validator_key", body, old,
+		oldHeaders[HeaderSignature], time.Minute); err == nil {
+		t.Error("Stale request should be rejected")
+	}
+}
+
+func TestIdentity_WrongNode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "identity.json")
+	if _, err := LoadOrCreateIdentity(path, "node-1"); err != nil {
+		t.Fatalf("Failed to create identity: %v", err)
+	}
+	if _, err := LoadOrCreateIdentity(path, "node-2"); err == nil {
+		t.Error("Loading another node's identity file should fail")
+	}
+}
+
+func TestKeyring_EnrollAndRevoke(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keyring.json")
+	k, err := NewKeyring(path, map[string]string{"static-node": "static-key"})
+	if err != nil {
+		t.Fatalf("Failed to create keyring: %v", err)
+	}
+
+	if err := k.Enroll("node-2", "key-a"); err != nil {
+		t.Fatalf("Enroll failed: %v", err)
+	}
+	if err := k.Enroll("node-2", "key-b"); err == nil {
+		t.Error("Re-enrolling with a different key should fail")
+	}
+	if err := k.Enroll("static-node", "other"); err == nil {
+		t.Error("Enrolling over a config pin should fail")
+	}
+
+	// A second instance (e.g. the CLI) sees the persisted key and revokes it
+	cli, err := NewKeyring(path, nil)
+	if err != nil {
+		t.Fatalf("Failed to reload keyring: %v", err)
+	}
+	if key, ok := cli.Lookup("node-2"); !ok || key != "key-a" {
+		t.Errorf("Lookup after reload = %q, %v", key, ok)
+	}
+	if err := cli.Revoke("node-2"); err != nil {
+		t.Fatalf("Revoke failed: %v", err)
+	}
+
+	// Force the modification time forward so the daemon instance reloads;
+	// its next enrollment must not write the revocation away
+	k.modTime = time.Time{}
+	if err := k.Enroll("node-3", "key-d"); err != nil {
+		t.Fatalf("Enroll failed: %v", err)
+	}
+	reread, err := NewKeyring(path, nil)
+	if err != nil {
+		t.Fatalf("Failed to reload keyring: %v", err)
+	}
+	if _, ok := reread.Lookup("node-2"); ok {
+		t.Error("Revocation lost to a later enrollment")
+	}
+	if _, ok := k.Lookup("node-2"); ok {
+		t.Error("Revoked node should not be found")
+	}
+	if err := k.Enroll("node-2", "key-c"); err == nil {
+		t.Error("Revoked node should not re-enroll")
+	}
+
+	// A corrupt file keeps the loaded keys and is reported once
+	var reported []error
+	k.OnReloadError(func(err error) { reported = append(reported, err) })
+	if err := os.WriteFile(path, []byte(`{"keys": {`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	k.modTime = time.Time{}
+	k.Lookup("node-2")
+	k.Lookup("node-2")
+	if len(reported) != 1 {
+		t.Errorf("corrupt keyring reported %d times, want once", len(reported))
+	}
+	if _, ok := k.Lookup("static-node"); !ok {
+		t.Error("Static pin lost after a failed reload")
+	}
+	if err := k.Enroll("node-4", "key-e"); err == nil {
+		t.Error("Enroll overwrote a keyring file it could not load")
+	}
+}
@@ -0,0 +1,225 @@
+package crypto
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Keyring holds the pinned public keys of peer nodes.
+// Keys come from two sources: static pins in the config file and keys
+// enrolled at runtime, which are persisted to disk. Revoking a node removes
+// its enrolled key and blocks re-enrollment until it is explicitly cleared.
+type Keyring struct {
+	mu       sync.RWMutex
+	path     string
+	static   map[string]string
+	enrolled map[string]string
+	revoked  map[string]bool
+	modTime  time.Time
+	// failedMod is the modification time of a file that failed to load,
+	// so it is not read again until it changes, and failedErr the failure
+	failedMod     time.Time
+	failedErr     error
+	onReloadError func(error)
+}
+
+// keyringFile is the on-disk format of enrolled and revoked keys
+type keyringFile struct {
+	Keys    map[string]string `json:"keys"`
+	Revoked []string          `json:"revoked"`
+}
+
+// NewKeyring loads the keyring file at path (if any) on top of static pins
+func NewKeyring(path string, static map[string]string) (*Keyring, error) {
+	k := &Keyring{
+		path:     path,
+		static:   static,
+		enrolled: make(map[string]string),
+		revoked:  make(map[string]bool),
+	}
+	if err := k.load(); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// load replaces enrolled and revoked keys with the file contents
+func (k *Keyring) load() error {
+	info, err := os.Stat(k.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to stat keyring: %w", err)
+	}
+
+	data, err := os.ReadFile(k.path)
+	if err != nil {
+		return fmt.Errorf("failed to read keyring: %w", err)
+	}
+
+	var f keyringFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return fmt.Errorf("failed to parse keyring: %w", err)
+	}
+
+	k.enrolled = make(map[string]string)
+	k.revoked = make(map[string]bool)
+	for id, key := range f.Keys {
+		k.enrolled[id] = key
+	}
+	for _, id := range f.Revoked {
+		k.revoked[id] = true
+	}
+	k.modTime = info.ModTime()
+	return nil
+}
+
+// OnReloadError sets fn to hear of a changed keyring file that could not
+// be loaded. The keys loaded before stay in force, so a revocation in
+// such a file has not taken effect; fn hears of each change once.
+func (k *Keyring) OnReloadError(fn func(error)) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.onReloadError = fn
+}
+
+// refresh reloads the file when another process (the CLI) changed it,
+// so a revocation takes effect on the running daemon without a restart
+func (k *Keyring) refresh() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	failed := k.failedMod
+	if err := k.reloadLocked(); err != nil && !k.failedMod.Equal(failed) && k.onReloadError != nil {
+		k.onReloadError(err)
+	}
+}
+
+// reloadLocked loads the file if it changed since it was last read, and
+// fails for as long as a changed file cannot be loaded; caller holds k.mu
+func (k *Keyring) reloadLocked() error {
+	info, err := os.Stat(k.path)
+	if err != nil || !info.ModTime().After(k.modTime) {
+		return nil
+	}
+	if info.ModTime().Equal(k.failedMod) {
+		return k.failedErr
+	}
+	if err := k.load(); err != nil {
+		k.failedMod, k.failedErr = info.ModTime(), err
+		return err
+	}
+	return nil
+}
+
+// Lookup returns the pinned public key for a node, unless it is revoked
+func (k *Keyring) Lookup(nodeID string) (string, bool) {
+	k.refresh()
+
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	if k.revoked[nodeID] {
+		return "", false
+	}
+	if key, ok := k.static[nodeID]; ok && key != "" {
+		return key, true
+	}
+	key, ok := k.enrolled[nodeID]
+	return key, ok
+}
+
+// Enroll pins a node's public key. A node that is already pinned to a
+// different key must be revoked first, so a stolen shared secret cannot be
+// used to silently replace an identity.
+func (k *Keyring) Enroll(nodeID, publicKey string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if err := k.reloadLocked(); err != nil {
+		return err
+	}
+
+	if k.revoked[nodeID] {
+		return fmt.Errorf("node %s is revoked", nodeID)
+	}
+	if existing, ok := k.static[nodeID]; ok && existing != "" {
+		if existing != publicKey {
+			return fmt.Errorf("node %s is pinned to a different key in config", nodeID)
+		}
+		return nil
+	}
+	if existing, ok := k.enrolled[nodeID]; ok {
+		if existing != publicKey {
+			return fmt.Errorf("node %s is already enrolled with a different key", nodeID)
+		}
+		return nil
+	}
+
+	k.enrolled[nodeID] = publicKey
+	return k.saveLocked()
+}
+
+// Revoke removes a node's enrolled key and refuses it from now on
+func (k *Keyring) Revoke(nodeID string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if err := k.reloadLocked(); err != nil {
+		return err
+	}
+
+	delete(k.enrolled, nodeID)
+	k.revoked[nodeID] = true
+	return k.saveLocked()
+}
+
+// Unrevoke clears a revocation so the node can enroll a new key
+func (k *Keyring) Unrevoke(nodeID string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if err := k.reloadLocked(); err != nil {
+		return err
+	}
+
+	delete(k.revoked, nodeID)
+	return k.saveLocked()
+}
+
+// saveLocked persists enrolled and revoked keys; caller holds k.mu
+func (k *Keyring) saveLocked() error {
+	f := keyringFile{Keys: k.enrolled, Revoked: make([]string, 0, len(k.revoked))}
+	for id := range k.revoked {
+		f.Revoked = append(f.Revoked, id)
+	}
+	sort.Strings(f.Revoked)
+
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal keyring: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(k.path), 0700); err != nil {
+		return fmt.Errorf("failed to create keyring directory: %w", err)
+	}
+
+	tmpFile := k.path + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0600); err != nil {
+		return fmt.Errorf("failed to write keyring: %w", err)
+	}
+	if err := os.Rename(tmpFile, k.path); err != nil {
+		return fmt.Errorf("failed to rename keyring: %w", err)
+	}
+
+	if info, err := os.Stat(k.path); err == nil {
+		k.modTime = info.ModTime()
+	}
+	return nil
+}
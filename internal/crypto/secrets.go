@@ -0,0 +1,146 @@
+package crypto
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// fingerprintLabel is the message whose HMAC identifies a secret
+const fingerprintLabel = "SYNCGUARD_SECRET_FINGERPRINT"
+
+// Fingerprint identifies a secret without revealing it, so nodes can
+// compare secrets over the admin API
+func Fingerprint(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return Sign(fingerprintLabel, secret)[:16]
+}
+
+// SecretRing holds the cluster secret during a rotation. Signatures and
+// encryption always use the current secret; the previous secret is still
+// accepted until it is retired, so nodes can switch one at a time.
+type SecretRing struct {
+	mu           sync.Mutex
+	current      string
+	previous     string
+	previousUsed time.Time
+}
+
+// SecretStatus describes the secrets a node holds, by fingerprint
+type SecretStatus struct {
+	Fingerprint string `json:"fingerprint"`
+	// Previous is the fingerprint of the previous secret, while accepted
+	Previous string `json:"previous,omitempty"`
+	// PreviousUsed is when a peer last authenticated with the previous secret
+	PreviousUsed *time.Time `json:"previous_used,omitempty"`
+}
+
+// NewSecretRing creates a ring; previous may be empty
+func NewSecretRing(current, previous string) *SecretRing {
+	return &SecretRing{current: current, previous: previous}
+}
+
+// Current returns the secret to sign and encrypt with
+func (r *SecretRing) Current() string {
+	return r.current
+}
+
+// Verify checks a signature against the current secret, then the
+// previous one
+func (r *SecretRing) Verify(data, signature string) bool {
+	if Verify(data, signature, r.current) {
+		return true
+	}
+	previous := r.accepted()
+	if previous == "" || !Verify(data, signature, previous) {
+		return false
+	}
+	r.markPreviousUsed()
+	return true
+}
+
+// VerifyTimed checks a timed signature against the current secret, then
+// the previous one
+func (r *SecretRing) VerifyTimed(data, signature string, timestamp, timeoutMs int64) bool {
+	if VerifyTimedSignature(data, signature, r.current, timestamp, timeoutMs) {
+		return true
+	}
+	previous := r.accepted()
+	if previous == "" || !VerifyTimedSignature(data, signature, previous, timestamp, timeoutMs) {
+		return false
+	}
+	r.markPreviousUsed()
+	return true
+}
+
+// VerifyRequest checks a peer request signed with SignRequestHMAC against
+// the current secret, then the previous one. Requests older than maxAge
+// (or too far in the future) are rejected to limit replay.
+func (r *SecretRing) VerifyRequest(nodeID, method, path string, body []byte, timestamp int64, signature string, maxAge time.Duration) error {
+	if err := checkSkew(timestamp, maxAge); err != nil {
+		return err
+	}
+	if !r.Verify(string(canonicalRequest(nodeID, method, path, body, timestamp)), signature) {
+		return fmt.Errorf("signature verification failed for %s", nodeID)
+	}
+	return nil
+}
+
+// Decrypt decrypts data sealed with the current secret, or the previous one
+func (r *SecretRing) Decrypt(data []byte) ([]byte, error) {
+	plain, err := Decrypt(data, r.current)
+	if err == nil {
+		return plain, nil
+	}
+	previous := r.accepted()
+	if previous == "" {
+		return nil, err
+	}
+	plain, prevErr := Decrypt(data, previous)
+	if prevErr != nil {
+		return nil, err
+	}
+	r.markPreviousUsed()
+	return plain, nil
+}
+
+// Retire stops accepting the previous secret
+func (r *SecretRing) Retire() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.previous == "" {
+		return fmt.Errorf("no previous secret to retire")
+	}
+	r.previous = ""
+	r.previousUsed = time.Time{}
+	return nil
+}
+
+// Status reports the fingerprints of the secrets held
+func (r *SecretRing) Status() SecretStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	status := SecretStatus{Fingerprint: Fingerprint(r.current), Previous: Fingerprint(r.previous)}
+	if !r.previousUsed.IsZero() {
+		used := r.previousUsed
+		status.PreviousUsed = &used
+	}
+	return status
+}
+
+// accepted returns the previous secret while it is accepted
+func (r *SecretRing) accepted() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.previous
+}
+
+func (r *SecretRing) markPreviousUsed() {
+	r.mu.Lock()
+	r.previousUsed = time.Now().UTC()
+	r.mu.Unlock()
+}
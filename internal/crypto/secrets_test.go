@@ -0,0 +1,50 @@
+package crypto
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSecretRing_AcceptsPreviousUntilRetired(t *testing.T) {
+	ring := NewSecretRing("new-secret", "old-secret")
+	ts := time.Now().Unix()
+
+	if !ring.VerifyTimed("payload", SignWithTimestamp("payload", "new-secret", ts), ts, 60000) {
+		t.Error("current secret rejected")
+	}
+	if ring.Status().PreviousUsed != nil {
+		t.Error("previous secret reported used")
+	}
+
+	oldSig := SignWithTimestamp("payload", "old-secret", ts)
+	if !ring.VerifyTimed("payload", oldSig, ts, 60000) {
+		t.Error("previous secret rejected during rotation")
+	}
+	if ring.Status().PreviousUsed == nil {
+		t.Error("use of the previous secret not recorded")
+	}
+
+	sealed, err := Encrypt([]byte("key"), "old-secret")
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+	if plain, err := ring.Decrypt(sealed); err != nil || string(plain) != "key" {
+		t.Errorf("Decrypt = %q, %v", plain, err)
+	}
+
+	if err := ring.Retire(); err != nil {
+		t.Fatalf("Retire failed: %v", err)
+	}
+	if ring.VerifyTimed("payload", oldSig, ts, 60000) {
+		t.Error("previous secret accepted after retirement")
+	}
+	if _, err := ring.Decrypt(sealed); err == nil {
+		t.Error("key sealed with the previous secret decrypted after retirement")
+	}
+	if status := ring.Status(); status.Previous != "" || status.Fingerprint != Fingerprint("new-secret") {
+		t.Errorf("status = %+v", status)
+	}
+	if err := ring.Retire(); err == nil {
+		t.Error("expected an error retiring twice")
+	}
+}
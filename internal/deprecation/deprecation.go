@@ -0,0 +1,143 @@
+package deprecation
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/metrics"
+	log "github.com/sirupsen/logrus"
+)
+
+// Kind classifies what was deprecated
+type Kind string
+
+const (
+	KindConfig   Kind = "config"
+	KindEndpoint Kind = "endpoint"
+)
+
+// reminderInterval is how often a repeatedly used deprecated item is logged again
+const reminderInterval = 24 * time.Hour
+
+var usageCounter = metrics.NewCounter(
+	"syncguard_deprecated_usage_total",
+	"Number of times a deprecated config key or API endpoint was used",
+	"kind", "name",
+)
+
+// Usage describes a deprecated item seen at runtime
+type Usage struct {
+	Kind        Kind      `json:"kind"`
+	Name        string    `json:"name"`
+	Replacement string    `json:"replacement,omitempty"`
+	Count       int64     `json:"count"`
+	FirstSeen   time.Time `json:"first_seen"`
+	LastSeen    time.Time `json:"last_seen"`
+	lastLogged  time.Time
+}
+
+// Registry records deprecated usage and rate-limits the warnings
+type Registry struct {
+	mu     sync.Mutex
+	usages map[string]*Usage
+	now    func() time.Time
+}
+
+// Default is the process-wide registry
+var Default = NewRegistry()
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		usages: make(map[string]*Usage),
+		now:    time.Now,
+	}
+}
+
+// Record notes a use of a deprecated item. The warning is logged on first use
+// and then at most once per day, while the metric counts every use.
+func (r *Registry) Record(kind Kind, name, replacement string) {
+	r.mu.Lock()
+	now := r.now()
+	key := string(kind) + ":" + name
+	usage, ok := r.usages[key]
+	if !ok {
+		usage = &Usage{Kind: kind, Name: name, Replacement: replacement, FirstSeen: now}
+		r.usages[key] = usage
+	}
+	usage.Count++
+	usage.LastSeen = now
+
+	shouldLog := usage.lastLogged.IsZero() || now.Sub(usage.lastLogged) >= reminderInterval
+	if shouldLog {
+		usage.lastLogged = now
+	}
+	r.mu.Unlock()
+
+	usageCounter.Inc(string(kind), name)
+
+	if shouldLog {
+		entry := log.WithFields(log.Fields{"module": "deprecation", "kind": kind})
+		if replacement != "" {
+			entry.Warnf("%s is deprecated, use %s instead", name, replacement)
+		} else {
+			entry.Warnf("%s is deprecated and will be removed", name)
+		}
+	}
+}
+
+// Remind re-logs deprecated items that are still in use but have not been
+// reported for a day. Config keys are only read at startup, so without this
+// a long-running daemon would mention them once and never again.
+func (r *Registry) Remind() {
+	r.mu.Lock()
+	now := r.now()
+	var due []Usage
+	for _, usage := range r.usages {
+		if now.Sub(usage.lastLogged) >= reminderInterval {
+			usage.lastLogged = now
+			due = append(due, *usage)
+		}
+	}
+	r.mu.Unlock()
+
+	for _, usage := range due {
+		log.WithFields(log.Fields{"module": "deprecation", "kind": usage.Kind}).
+			Warnf("%s is deprecated and still in use (%d uses since %s)",
+				usage.Name, usage.Count, usage.FirstSeen.Format(time.RFC3339))
+	}
+}
+
+// Snapshot returns all recorded usages sorted by kind and name
+func (r *Registry) Snapshot() []Usage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	usages := make([]Usage, 0, len(r.usages))
+	for _, usage := range r.usages {
+		usages = append(usages, *usage)
+	}
+	sort.Slice(usages, func(i, j int) bool {
+		if usages[i].Kind != usages[j].Kind {
+			return usages[i].Kind < usages[j].Kind
+		}
+		return usages[i].Name < usages[j].Name
+	})
+	return usages
+}
+
+// Record notes a deprecated usage in the default registry
+func Record(kind Kind, name, replacement string) {
+	Default.Record(kind, name, replacement)
+}
+
+// Remind re-logs stale deprecations in the default registry
+func Remind() {
+	Default.Remind()
+}
+
+// Snapshot returns the usages recorded in the default registry
+func Snapshot() []Usage {
+	return Default.Snapshot()
+}
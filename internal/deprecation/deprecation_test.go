@@ -0,0 +1,38 @@
+package deprecation
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRegistry_RecordAndRemind(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := NewRegistry()
+	r.now = func() time.Time { return now }
+
+	r.Record(KindConfig, "server.id", "node.id")
+	r.Record(KindConfig, "server.id", "node.id")
+	r.Record(KindEndpoint, "/old", "")
+
+	usages := r.Snapshot()
+	if len(usages) != 2 {
+		t.Fatalf("Expected 2 usages, got %d", len(usages))
+	}
+	if usages[0].Kind != KindConfig || usages[0].Count != 2 || usages[0].Replacement != "node.id" {
+		t.Errorf("Unexpected config usage: %+v", usages[0])
+	}
+
+	// Within a day nothing is due for a reminder
+	now = now.Add(time.Hour)
+	r.Remind()
+	if got := r.usages["config:server.id"].lastLogged; !got.Equal(now.Add(-time.Hour)) {
+		t.Errorf("Reminder fired too early, lastLogged = %v", got)
+	}
+
+	// After a day the reminder fires and resets the timer
+	now = now.Add(24 * time.Hour)
+	r.Remind()
+	if got := r.usages["config:server.id"].lastLogged; !got.Equal(now) {
+		t.Errorf("Reminder did not fire, lastLogged = %v", got)
+	}
+}
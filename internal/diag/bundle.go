@@ -0,0 +1,209 @@
+package diag
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/communication"
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/server"
+	"gopkg.in/yaml.v3"
+)
+
+// BundleOptions controls what a debug bundle collects
+type BundleOptions struct {
+	ConfigPath string
+	LogLines   int
+	CPUSeconds int
+}
+
+// manifest describes the contents of a bundle
+type manifest struct {
+	CreatedAt time.Time         `json:"created_at"`
+	NodeID    string            `json:"node_id"`
+	Files     []string          `json:"files"`
+	Skipped   map[string]string `json:"skipped,omitempty"`
+}
+
+// bundleStep is one item to collect
+type bundleStep struct {
+	name  string
+	fetch func() ([]byte, error)
+}
+
+// bundleWriter adds files to a tarball and tracks what was collected
+type bundleWriter struct {
+	tw       *tar.Writer
+	now      time.Time
+	manifest manifest
+}
+
+// add writes one file into the bundle
+func (b *bundleWriter) add(name string, data []byte) error {
+	if err := b.tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    int64(len(data)),
+		ModTime: b.now,
+	}); err != nil {
+		return err
+	}
+	if _, err := b.tw.Write(data); err != nil {
+		return err
+	}
+	b.manifest.Files = append(b.manifest.Files, name)
+	return nil
+}
+
+// skip records why an item could not be collected
+func (b *bundleWriter) skip(name string, err error) {
+	b.manifest.Skipped[name] = err.Error()
+}
+
+// collect adds an item, or records the reason it was skipped
+func (b *bundleWriter) collect(name string, fetch func() ([]byte, error)) error {
+	data, err := fetch()
+	if err != nil {
+		b.skip(name, err)
+		return nil
+	}
+	return b.add(name, data)
+}
+
+// WriteBundle collects diagnostics for bug reports into a gzipped tarball:
+// redacted config, recent logs, event history, status snapshots from the
+// running daemon and pprof profiles from its admin API. Items that cannot be
+// collected (daemon not running, admin API disabled) are listed as skipped
+// in manifest.json rather than failing the whole bundle.
+func WriteBundle(cfg *config.Config, opts BundleOptions, w io.Writer) error {
+	gz := gzip.NewWriter(w)
+	b := &bundleWriter{
+		tw:  tar.NewWriter(gz),
+		now: time.Now().UTC(),
+		manifest: manifest{
+			NodeID:  cfg.Node.ID,
+			Skipped: make(map[string]string),
+		},
+	}
+	b.manifest.CreatedAt = b.now
+
+	steps := []bundleStep{
+		{"config.yaml", func() ([]byte, error) { return redactedConfig(opts.ConfigPath) }},
+		{"logs/syncguard.log", func() ([]byte, error) { return tailFile(cfg.Logging.File, opts.LogLines) }},
+		{"history/history.jsonl.1", func() ([]byte, error) { return os.ReadFile(cfg.History.Path + ".1") }},
+		{"history/history.jsonl", func() ([]byte, error) { return os.ReadFile(cfg.History.Path) }},
+		{"status/health.json", func() ([]byte, error) {
+			return fetch(fmt.Sprintf("http://127.0.0.1:%d%s", cfg.Node.Port, communication.PathHealth), "", 10*time.Second)
+		}},
+	}
+
+	admin := server.AdminURL(cfg.Admin.Listen, cfg.Node.Instance)
+	if admin == "" {
+		b.skip("status/admin.json", fmt.Errorf("admin.listen is not configured"))
+		b.skip("pprof", fmt.Errorf("admin.listen is not configured"))
+	} else {
+		steps = append(steps, bundleStep{"status/admin.json", func() ([]byte, error) {
+			return fetch(admin+server.PathAdminStatus, cfg.Admin.Token, 10*time.Second)
+		}})
+
+		profiles := []struct{ name, path string }{
+			{"pprof/goroutine.txt", "goroutine?debug=2"},
+			{"pprof/heap.pb.gz", "heap"},
+			{"pprof/allocs.pb.gz", "allocs"},
+			{"pprof/mutex.pb.gz", "mutex"},
+			{"pprof/block.pb.gz", "block"},
+		}
+		if opts.CPUSeconds > 0 {
+			profiles = append(profiles, struct{ name, path string }{
+				"pprof/cpu.pb.gz", fmt.Sprintf("profile?seconds=%d", opts.CPUSeconds),
+			})
+		}
+		timeout := time.Duration(opts.CPUSeconds+30) * time.Second
+		for _, p := range profiles {
+			url := admin + server.PathDebugPprof + p.path
+			steps = append(steps, bundleStep{p.name, func() ([]byte, error) {
+				return fetch(url, cfg.Admin.Token, timeout)
+			}})
+		}
+	}
+
+	for _, step := range steps {
+		if err := b.collect(step.name, step.fetch); err != nil {
+			return fmt.Errorf("failed to write %s: %w", step.name, err)
+		}
+	}
+
+	manifestData, err := json.MarshalIndent(b.manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	if err := b.add("manifest.json", manifestData); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	if err := b.tw.Close(); err != nil {
+		return fmt.Errorf("failed to finish bundle: %w", err)
+	}
+	return gz.Close()
+}
+
+// redactedConfig renders the config file with secrets removed
+func redactedConfig(path string) ([]byte, error) {
+	settings, err := config.RedactedSettings(path)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(settings)
+}
+
+// tailFile returns the last n lines of a file
+func tailFile(path string, n int) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if n <= 0 {
+		return data, nil
+	}
+
+	lines := bytes.Split(bytes.TrimRight(data, "\n"), []byte("\n"))
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return append(bytes.Join(lines, []byte("\n")), '\n'), nil
+}
+
+// fetch GETs a URL from the local daemon
+func fetch(url, token string, timeout time.Duration) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := (&http.Client{Timeout: timeout}).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned status %d: %s", filepath.Base(url), resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
@@ -0,0 +1,107 @@
+package diag_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/diag"
+)
+
+func TestWriteBundle(t *testing.T) {
+	dir := t.TempDir()
+
+	configPath := filepath.Join(dir, "config.yaml")
+	os.WriteFile(configPath, []byte(`
+secret: "super-secret"
+node:
+  id: "node-a"
+ping:
+  url: "https://hc-ping.com/0f3c-uuid"
+alerts:
+  sinks:
+    - type: email
+      smtp:
+        host: "smtp.example.com"
+        password: "mail-password"
+`), 0600)
+
+	logPath := filepath.Join(dir, "syncguard.log")
+	os.WriteFile(logPath, []byte("line 1\nline 2\nline 3\n"), 0600)
+
+	historyPath := filepath.Join(dir, "history.jsonl")
+	os.WriteFile(historyPath, []byte(`{"kind":"event","type":"failover"}`+"\n"), 0600)
+
+	cfg := &config.Config{
+		Node:    config.NodeConfig{ID: "node-a", Port: 1},
+		Logging: config.LoggingConfig{File: logPath},
+		History: config.HistoryConfig{Path: historyPath},
+	}
+
+	var buf bytes.Buffer
+	if err := diag.WriteBundle(cfg, diag.BundleOptions{ConfigPath: configPath, LogLines: 2}, &buf); err != nil {
+		t.Fatalf("WriteBundle: %v", err)
+	}
+
+	files := readTarball(t, &buf)
+
+	cfgOut := files["config.yaml"]
+	for _, secret := range []string{"super-secret", "mail-password", "0f3c-uuid"} {
+		if strings.Contains(cfgOut, secret) {
+			t.Errorf("Redacted config leaks %q:\n%s", secret, cfgOut)
+		}
+	}
+	if !strings.Contains(cfgOut, "smtp.example.com") {
+		t.Errorf("Redacted config should keep non-secret values:\n%s", cfgOut)
+	}
+
+	if files["logs/syncguard.log"] != "line 2\nline 3\n" {
+		t.Errorf("Expected last two log lines, got %q", files["logs/syncguard.log"])
+	}
+	if !strings.Contains(files["history/history.jsonl"], "failover") {
+		t.Error("Expected history in bundle")
+	}
+
+	var manifest struct {
+		Skipped map[string]string `json:"skipped"`
+	}
+	if err := json.Unmarshal([]byte(files["manifest.json"]), &manifest); err != nil {
+		t.Fatalf("Invalid manifest: %v", err)
+	}
+	for _, name := range []string{"status/health.json", "pprof"} {
+		if _, ok := manifest.Skipped[name]; !ok {
+			t.Errorf("Expected %s to be reported as skipped", name)
+		}
+	}
+}
+
+func readTarball(t *testing.T, r io.Reader) map[string]string {
+	t.Helper()
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		t.Fatalf("Not a gzip stream: %v", err)
+	}
+	tr := tar.NewReader(gz)
+
+	files := make(map[string]string)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Invalid tarball: %v", err)
+		}
+		data, _ := io.ReadAll(tr)
+		files[hdr.Name] = string(data)
+	}
+	return files
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !darwin && !freebsd && !windows
+
+package diag
+
+// diskSpace is not implemented on this platform
+func diskSpace(dir string) (free, total uint64, err error) {
+	return 0, 0, errDiskUnsupported
+}
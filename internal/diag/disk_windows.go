@@ -0,0 +1,16 @@
+package diag
+
+import "golang.org/x/sys/windows"
+
+// diskSpace returns the bytes available to this user and the size of the
+// volume holding dir
+func diskSpace(dir string) (free, total uint64, err error) {
+	path, err := windows.UTF16PtrFromString(dir)
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := windows.GetDiskFreeSpaceEx(path, &free, &total, nil); err != nil {
+		return 0, 0, err
+	}
+	return free, total, nil
+}
@@ -0,0 +1,517 @@
+package diag
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/communication"
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/crypto"
+	"github.com/aldebaranode/syncguard/internal/deprecation"
+	"github.com/aldebaranode/syncguard/internal/maintenance"
+	"github.com/aldebaranode/syncguard/internal/manager"
+	"github.com/aldebaranode/syncguard/internal/state"
+)
+
+// Doctor check outcomes
+const (
+	StatusOK   = "ok"
+	StatusWarn = "warn"
+	StatusFail = "fail"
+	StatusSkip = "skip"
+)
+
+// Free space below which the disk check warns or fails
+const (
+	lowDiskBytes      = 1 << 30
+	criticalDiskBytes = 100 << 20
+	lowDiskFraction   = 0.10
+)
+
+// errDiskUnsupported is returned where free space cannot be queried
+var errDiskUnsupported = errors.New("not supported")
+
+// Finding is the outcome of one doctor check; failures and warnings come
+// with the fix to apply
+type Finding struct {
+	Check  string `json:"check"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+	Fix    string `json:"fix,omitempty"`
+}
+
+// Failed reports whether any finding is a failure
+func Failed(findings []Finding) bool {
+	for _, f := range findings {
+		if f.Status == StatusFail {
+			return true
+		}
+	}
+	return false
+}
+
+// Doctor runs the startup self-checks for the config at path: files and
+// their permissions, CometBFT, clock, peers, disk space and the lock
+// backend, and any maintenance flags. A config that does not load is the only finding, since every
+// other check depends on it.
+func Doctor(path string, opts config.LoadOptions) []Finding {
+	cfg, err := config.LoadWithOptions(path, opts)
+	if err != nil {
+		return []Finding{{
+			Check:  "config",
+			Status: StatusFail,
+			Detail: err.Error(),
+			Fix:    "correct the key named above; `syncguard config migrate` rewrites deprecated keys",
+		}}
+	}
+
+	findings := []Finding{checkConfig(path)}
+	findings = append(findings, checkFiles(cfg)...)
+	status, comet := checkCometBFT(cfg)
+	findings = append(findings, comet, checkClock(cfg, status))
+	findings = append(findings, checkPeers(cfg)...)
+	findings = append(findings, checkDisk(cfg)...)
+	findings = append(findings, checkLock(cfg))
+	findings = append(findings, checkMaintenance(cfg)...)
+	return findings
+}
+
+// checkConfig reports a valid config, pointing out deprecated keys
+func checkConfig(path string) Finding {
+	var deprecated []string
+	for _, usage := range deprecation.Snapshot() {
+		if usage.Kind == deprecation.KindConfig {
+			deprecated = append(deprecated, fmt.Sprintf("%s (use %s)", usage.Name, usage.Replacement))
+		}
+	}
+	if len(deprecated) > 0 {
+		return Finding{
+			Check:  "config",
+			Status: StatusWarn,
+			Detail: fmt.Sprintf("%s is valid but uses deprecated keys: %s", path, strings.Join(deprecated, ", ")),
+			Fix:    "run `syncguard config migrate` to rewrite them",
+		}
+	}
+	return Finding{Check: "config", Status: StatusOK, Detail: path + " is valid"}
+}
+
+// fileSpec is a file syncguard reads or writes
+type fileSpec struct {
+	key      string
+	path     string
+	secret   bool
+	required bool
+	writable bool
+}
+
+// checkFiles verifies that key and state files exist, that secrets are
+// readable by their owner only and that the files and directories
+// syncguard writes are writable
+func checkFiles(cfg *config.Config) []Finding {
+	specs := []fileSpec{
+		{key: "cometbft.key_path", path: cfg.CometBFT.KeyPath, secret: true, required: true, writable: true},
+		{key: "cometbft.state_path", path: cfg.CometBFT.StatePath, required: true, writable: true},
+	}
+	// A passive node parks the real key next to the mock key
+	specs = append(specs, fileSpec{key: "parked key", path: cfg.CometBFT.KeyPath + ".real", secret: true})
+	if cfg.CometBFT.BackupPath != "" {
+		specs = append(specs, fileSpec{key: "backup key", path: filepath.Join(cfg.CometBFT.BackupPath, "priv_validator_key.json.bak"), secret: true})
+	}
+	if cfg.Identity.Enabled {
+		specs = append(specs, fileSpec{key: "identity.key_path", path: cfg.Identity.KeyPath, secret: true})
+	}
+	// TLS material only exists once `syncguard cert` has created it
+	specs = append(specs,
+		fileSpec{key: "tls.key_file", path: cfg.TLS.KeyFile, secret: true},
+		fileSpec{key: "tls.ca_key_file", path: cfg.TLS.CAKeyFile, secret: true})
+
+	var findings []Finding
+	owner, err := state.ResolveOwner(cfg.CometBFT.KeyOwner)
+	if err != nil {
+		findings = append(findings, Finding{
+			Check:  "cometbft.key_owner",
+			Status: StatusFail,
+			Detail: err.Error(),
+			Fix:    "set cometbft.key_owner to the user CometBFT runs as, or leave it empty",
+		})
+	}
+	for _, spec := range specs {
+		findings = append(findings, checkFile(spec, owner, cfg.CometBFT.KeyOwner))
+	}
+	for _, dir := range writableDirs(cfg) {
+		if err := dirWritable(dir); err != nil {
+			findings = append(findings, Finding{
+				Check:  "directory",
+				Status: StatusFail,
+				Detail: fmt.Sprintf("%s is not writable: %v", dir, err),
+				Fix:    fmt.Sprintf("create %s and make it writable by the user syncguard runs as", dir),
+			})
+		}
+	}
+	return findings
+}
+
+// checkFile checks one file against its spec; key material must be mode
+// 0600 and belong to owner (named ownerName in the config)
+func checkFile(spec fileSpec, owner int, ownerName string) Finding {
+	finding := Finding{Check: spec.key, Status: StatusOK, Detail: spec.path}
+	info, err := os.Stat(spec.path)
+	switch {
+	case os.IsNotExist(err) && spec.required:
+		finding.Status = StatusFail
+		finding.Detail = spec.path + " does not exist"
+		finding.Fix = fmt.Sprintf("point %s at the existing file", spec.key)
+		return finding
+	case os.IsNotExist(err):
+		finding.Status = StatusSkip
+		finding.Detail = spec.path + " does not exist yet"
+		return finding
+	case err != nil:
+		finding.Status = StatusFail
+		finding.Detail = err.Error()
+		finding.Fix = fmt.Sprintf("make %s readable by the user syncguard runs as", spec.path)
+		return finding
+	}
+
+	if spec.secret {
+		problems, _ := state.KeyFileProblems(spec.path, owner)
+		if len(problems) > 0 {
+			var fixes []string
+			if info.Mode().Perm()&^0600 != 0 {
+				fixes = append(fixes, "chmod 600 "+spec.path)
+			}
+			if len(problems) > len(fixes) {
+				if ownerName == "" {
+					ownerName = "the user syncguard runs as"
+				}
+				fixes = append(fixes, fmt.Sprintf("chown %s %s", ownerName, spec.path))
+			}
+			finding.Status = StatusFail
+			finding.Detail = fmt.Sprintf("%s is %s", spec.path, strings.Join(problems, ", "))
+			finding.Fix = strings.Join(fixes, " && ")
+			return finding
+		}
+	}
+	if spec.writable {
+		f, err := os.OpenFile(spec.path, os.O_WRONLY, 0)
+		if err != nil {
+			finding.Status = StatusFail
+			finding.Detail = fmt.Sprintf("%s is not writable: %v", spec.path, err)
+			finding.Fix = fmt.Sprintf("chown %s to the user syncguard runs as", spec.path)
+			return finding
+		}
+		f.Close()
+	}
+	return finding
+}
+
+// writableDirs lists the directories syncguard creates files in
+func writableDirs(cfg *config.Config) []string {
+	dirs := []string{cfg.Node.DataDir}
+	if cfg.CometBFT.StatePath != "" {
+		dirs = append(dirs, filepath.Dir(cfg.CometBFT.StatePath))
+	}
+	if cfg.CometBFT.BackupPath != "" {
+		dirs = append(dirs, cfg.CometBFT.BackupPath)
+	}
+	return dedupe(dirs)
+}
+
+// dirWritable creates and removes a file in dir
+func dirWritable(dir string) error {
+	f, err := os.CreateTemp(dir, ".syncguard-doctor-*")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+// cometStatus is the part of CometBFT's /status the doctor reports on
+type cometStatus struct {
+	Result struct {
+		NodeInfo struct {
+			Network string `json:"network"`
+			Version string `json:"version"`
+		} `json:"node_info"`
+		SyncInfo struct {
+			LatestBlockHeight string    `json:"latest_block_height"`
+			LatestBlockTime   time.Time `json:"latest_block_time"`
+			CatchingUp        bool      `json:"catching_up"`
+		} `json:"sync_info"`
+	} `json:"result"`
+}
+
+// checkCometBFT queries the RPC for the node's version and sync state
+func checkCometBFT(cfg *config.Config) (*cometStatus, Finding) {
+	finding := Finding{Check: "cometbft"}
+	status, err := fetchCometStatus(cfg)
+	if err != nil {
+		finding.Status = StatusFail
+		finding.Detail = fmt.Sprintf("RPC at %s unreachable: %v", cfg.CometBFT.RPCURL, err)
+		finding.Fix = "start CometBFT, or set cometbft.rpc_url to the address in the [rpc] laddr of its config.toml"
+		return nil, finding
+	}
+
+	info, sync := status.Result.NodeInfo, status.Result.SyncInfo
+	finding.Status = StatusOK
+	finding.Detail = fmt.Sprintf("CometBFT %s on %s at height %s", info.Version, info.Network, sync.LatestBlockHeight)
+	switch {
+	case info.Version == "":
+		finding.Status = StatusWarn
+		finding.Detail = fmt.Sprintf("%s does not report a CometBFT version", cfg.CometBFT.RPCURL)
+		finding.Fix = "check that cometbft.rpc_url points at CometBFT and not at another RPC"
+	case sync.CatchingUp:
+		finding.Status = StatusWarn
+		finding.Detail += ", catching up"
+		finding.Fix = "wait for the node to sync; until then it counts as unhealthy and cannot take over"
+	}
+	return status, finding
+}
+
+// fetchCometStatus performs the /status RPC call
+func fetchCometStatus(cfg *config.Config) (*cometStatus, error) {
+	client := &http.Client{Timeout: cfg.Health.Timeout.Duration()}
+	resp, err := client.Get(strings.TrimSuffix(cfg.CometBFT.RPCURL, "/") + "/status")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var status cometStatus
+	if err := json.Unmarshal(body, &status); err != nil {
+		return nil, fmt.Errorf("invalid /status response: %w", err)
+	}
+	return &status, nil
+}
+
+// checkClock compares the local clock with the latest block time, which
+// is the median of the validators' clocks. Signed peer requests are
+// rejected once clocks drift apart by more than identity.max_skew.
+func checkClock(cfg *config.Config, status *cometStatus) Finding {
+	finding := Finding{Check: "clock"}
+	if status == nil || status.Result.SyncInfo.CatchingUp || status.Result.SyncInfo.LatestBlockTime.IsZero() {
+		finding.Status = StatusSkip
+		finding.Detail = "needs a synced CometBFT node to compare with"
+		return finding
+	}
+
+	offset := time.Since(status.Result.SyncInfo.LatestBlockTime)
+	maxSkew := cfg.Identity.MaxSkew.Duration()
+	// The latest block is up to a few block times old on a healthy chain
+	ahead := maxSkew + 2*cfg.Chain.BlockTime.Duration()
+	finding.Detail = fmt.Sprintf("latest block is %s old", offset.Round(time.Millisecond))
+	if offset > ahead || offset < -maxSkew {
+		finding.Status = StatusWarn
+		finding.Detail = fmt.Sprintf("local clock is %s off the chain's latest block time", offset.Round(time.Second))
+		finding.Fix = "enable time sync (timedatectl set-ntp true, or chrony); clocks further apart than identity.max_skew break signed peer requests"
+		return finding
+	}
+	finding.Status = StatusOK
+	return finding
+}
+
+// checkPeers checks each peer is reachable and accepts this node's
+// signed requests, and whether it can call this node back
+func checkPeers(cfg *config.Config) []Finding {
+	var identity *crypto.Identity
+	if cfg.Identity.Enabled {
+		// Load only: the key is created on first start, not by the doctor
+		if _, err := os.Stat(cfg.Identity.KeyPath); err == nil {
+			identity, _ = crypto.LoadOrCreateIdentity(cfg.Identity.KeyPath, cfg.Node.ID)
+		}
+	}
+	client := communication.NewClient(cfg, identity)
+	if cfg.TLS.Enabled {
+		tlsConfig, err := crypto.LoadTLSConfig(cfg.TLS.CAFile, cfg.TLS.CertFile, cfg.TLS.KeyFile)
+		if err != nil {
+			return []Finding{{
+				Check:  "peer tls",
+				Status: StatusFail,
+				Detail: err.Error(),
+				Fix:    "Issue this node's certificate with 'syncguard cert issue' and copy the CA certificate to tls.ca_file",
+			}}
+		}
+		client.UseTLS(tlsConfig)
+	}
+
+	var findings []Finding
+	for _, peer := range cfg.Peers {
+		findings = append(findings, checkPeer(client, peer, cfg.Node))
+	}
+	return findings
+}
+
+// checkPeer probes one peer and then handshakes with it
+func checkPeer(client *communication.Client, peer config.PeerConfig, node config.NodeConfig) Finding {
+	finding := Finding{Check: "peer " + peer.ID}
+	if err := client.Probe(peer.Address); err != nil {
+		kind := communication.ClassifyError(err)
+		finding.Status = StatusFail
+		finding.Detail = fmt.Sprintf("%s unreachable [%s]: %v", peer.Address, kind, err)
+		finding.Fix = communication.DescribeFailure(kind)
+		return finding
+	}
+
+	resp, err := client.Handshake(peer.Address)
+	if err != nil {
+		finding.Status = StatusFail
+		finding.Detail = fmt.Sprintf("%s reachable, but the handshake failed: %v", peer.Address, err)
+		code := communication.StatusCode(err)
+		if code == http.StatusUnauthorized || code == http.StatusForbidden {
+			finding.Fix = "the peer rejected this node's signature: enroll this node's identity key with it " +
+				"(done on first start), check it is not revoked and that both clocks are in sync"
+		} else {
+			finding.Fix = communication.DescribeFailure(communication.ClassifyError(err))
+		}
+		return finding
+	}
+
+	if problems := communication.HandshakeProblems(resp, node); len(problems) > 0 {
+		finding.Status = StatusWarn
+		finding.Detail = strings.Join(problems, "; ")
+		finding.Fix = "fix the address the peer has for this node; if syncguard is not running here yet, run the doctor again once it is"
+		return finding
+	}
+	finding.Status = StatusOK
+	finding.Detail = fmt.Sprintf("%s reachable, authenticated and reaches this node back", peer.Address)
+	return finding
+}
+
+// checkDisk reports the free space where state, history and logs are kept
+func checkDisk(cfg *config.Config) []Finding {
+	dirs := []string{cfg.Node.DataDir}
+	if cfg.CometBFT.StatePath != "" {
+		dirs = append(dirs, filepath.Dir(cfg.CometBFT.StatePath))
+	}
+
+	var findings []Finding
+	for _, dir := range dedupe(dirs) {
+		finding := Finding{Check: "disk", Status: StatusOK}
+		free, total, err := diskSpace(dir)
+		switch {
+		case errors.Is(err, errDiskUnsupported):
+			finding.Status = StatusSkip
+			finding.Detail = "free space cannot be checked on " + runtime.GOOS
+		case err != nil:
+			finding.Status = StatusSkip
+			finding.Detail = fmt.Sprintf("%s: %v", dir, err)
+		default:
+			finding.Detail = fmt.Sprintf("%s: %s free of %s", dir, humanBytes(free), humanBytes(total))
+			if free < criticalDiskBytes {
+				finding.Status = StatusFail
+				finding.Fix = "free up space: without it state saves and key transfers fail"
+			} else if free < lowDiskBytes || float64(free) < lowDiskFraction*float64(total) {
+				finding.Status = StatusWarn
+				finding.Fix = "free up space or lower history.max_size"
+			}
+		}
+		findings = append(findings, finding)
+	}
+	return findings
+}
+
+// checkLock verifies the lock backend can be reached and reports a lock
+// file left behind
+func checkLock(cfg *config.Config) Finding {
+	finding := Finding{Check: "lock"}
+	lockPath := state.LockPath(cfg.CometBFT.StatePath, cfg.Node.Instance)
+	if cfg.Lock.Backend != "file" {
+		lock, err := manager.NewLockBackend(cfg)
+		if err == nil {
+			err = lock.Check()
+		}
+		if err != nil {
+			finding.Status = StatusFail
+			finding.Detail = fmt.Sprintf("%s backend: %v", cfg.Lock.Backend, err)
+			finding.Fix = "check lock.endpoints and credentials; while the lock is unreachable the standby cannot take over"
+			return finding
+		}
+		finding.Status = StatusOK
+		finding.Detail = fmt.Sprintf("%s backend reachable, key %s", cfg.Lock.Backend, cfg.Lock.Key)
+		return finding
+	}
+	stateManager := state.NewManager(cfg.CometBFT.StatePath, cfg.CometBFT.BackupPath)
+	stateManager.SetLockBackend(state.NewFileLock(lockPath))
+	if err := stateManager.CheckLock(); err != nil {
+		finding.Status = StatusFail
+		finding.Detail = fmt.Sprintf("%s backend: %v", cfg.Lock.Backend, err)
+		finding.Fix = "make the directory of cometbft.state_path available; while the lock is unreachable the standby cannot take over"
+		return finding
+	}
+
+	if pid, err := os.ReadFile(lockPath); err == nil {
+		finding.Status = StatusWarn
+		finding.Detail = fmt.Sprintf("%s is held by pid %s", lockPath, strings.TrimSpace(string(pid)))
+		finding.Fix = "if syncguard is not running on this host, remove the stale lock file"
+		return finding
+	}
+	finding.Status = StatusOK
+	finding.Detail = cfg.Lock.Backend + " backend reachable"
+	return finding
+}
+
+// checkMaintenance reports whether a maintenance flag marks this host;
+// nothing when no flag is configured
+func checkMaintenance(cfg *config.Config) []Finding {
+	finding := Finding{Check: "maintenance"}
+	checker, err := maintenance.New(cfg.Maintenance)
+	if checker == nil && err == nil {
+		return nil
+	}
+	if err == nil {
+		err = checker.Check()
+	}
+	if err != nil {
+		finding.Status = StatusWarn
+		finding.Detail = err.Error()
+		finding.Fix = "this node will not become active until the maintenance flag is cleared"
+		return []Finding{finding}
+	}
+	finding.Status = StatusOK
+	finding.Detail = "no maintenance flag set (" + strings.Join(checker.Sources(), ", ") + ")"
+	return []Finding{finding}
+}
+
+// humanBytes renders a byte count with one decimal in the largest unit
+func humanBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// dedupe drops repeated and empty entries, keeping the order
+func dedupe(items []string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, item := range items {
+		if item == "" || seen[item] {
+			continue
+		}
+		seen[item] = true
+		out = append(out, item)
+	}
+	return out
+}
@@ -0,0 +1,85 @@
+package diag_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/diag"
+)
+
+func TestDoctor(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file permission checks do not apply on Windows")
+	}
+	dir := t.TempDir()
+
+	comet := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"result":{"node_info":{"network":"story-1","version":"0.38.12"},
+			"sync_info":{"latest_block_height":"100","latest_block_time":%q,"catching_up":false}}}`,
+			time.Now().Add(-2*time.Second).UTC().Format(time.RFC3339Nano))
+	}))
+	defer comet.Close()
+
+	keyPath := filepath.Join(dir, "priv_validator_key.json")
+	statePath := filepath.Join(dir, "priv_validator_state.json")
+	os.WriteFile(keyPath, []byte(`{}`), 0644)
+	os.WriteFile(statePath, []byte(`{}`), 0600)
+	os.WriteFile(statePath+".lock", []byte("4242\n"), 0600)
+
+	configPath := filepath.Join(dir, "config.yaml")
+	os.WriteFile(configPath, []byte(fmt.Sprintf(`
+secret: "test-secret"
+node:
+  id: "validator-1"
+  data_dir: %q
+cometbft:
+  rpc_url: %q
+  key_path: %q
+  state_path: %q
+logging:
+  file: %q
+`, dir, comet.URL, keyPath, statePath, filepath.Join(dir, "syncguard.log"))), 0600)
+
+	findings := diag.Doctor(configPath, config.LoadOptions{})
+	got := make(map[string]diag.Finding)
+	for _, f := range findings {
+		got[f.Check] = f
+	}
+
+	want := map[string]string{
+		"config":              diag.StatusOK,
+		"cometbft.key_path":   diag.StatusFail,
+		"cometbft.state_path": diag.StatusOK,
+		"cometbft":            diag.StatusOK,
+		"clock":               diag.StatusOK,
+		"lock":                diag.StatusWarn,
+	}
+	for check, status := range want {
+		if got[check].Status != status {
+			t.Errorf("%s: status %q, want %q (%+v)", check, got[check].Status, status, got[check])
+		}
+	}
+	if fix := got["cometbft.key_path"].Fix; fix != "chmod 600 "+keyPath {
+		t.Errorf("key_path fix = %q", fix)
+	}
+	if !diag.Failed(findings) {
+		t.Error("expected the doctor to fail")
+	}
+}
+
+func TestDoctor_InvalidConfig(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	os.WriteFile(configPath, []byte("node:\n  id: \"validator-1\"\n"), 0600)
+
+	findings := diag.Doctor(configPath, config.LoadOptions{})
+	if len(findings) != 1 || findings[0].Check != "config" || findings[0].Status != diag.StatusFail {
+		t.Fatalf("expected a single config failure, got %+v", findings)
+	}
+}
@@ -0,0 +1,346 @@
+package drill
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/history"
+	"github.com/aldebaranode/syncguard/internal/logger"
+)
+
+const (
+	// pollInterval is how often the standby is checked during handover
+	pollInterval = time.Second
+	// handoffTimeout bounds how long the standby may take to become active
+	handoffTimeout = 2 * time.Minute
+)
+
+// ErrRunning is returned when a drill is already in progress
+var ErrRunning = errors.New("a drill is already running")
+
+// ErrNotRunning is returned when there is no drill to revert
+var ErrNotRunning = errors.New("no drill is running")
+
+// ErrNotActive is returned by a target that cannot start a drill because
+// it is not the active node; scheduled drills skip such nodes silently
+var ErrNotActive = errors.New("drills must be started on the active node")
+
+// Phase is the stage a drill is in
+type Phase string
+
+const (
+	PhaseHandover  Phase = "handover"
+	PhaseStandby   Phase = "standby_active"
+	PhaseFailback  Phase = "failback"
+	PhaseCompleted Phase = "completed"
+	PhaseFailed    Phase = "failed"
+	// PhaseSkipped marks a scheduled drill that did not start
+	PhaseSkipped Phase = "skipped"
+)
+
+// Trigger says what started a drill
+type Trigger string
+
+const (
+	TriggerManual   Trigger = "manual"
+	TriggerSchedule Trigger = "schedule"
+)
+
+// Status reports the progress and timings of a drill.
+// Detection is not exercised by a drill (the active node hands over on
+// purpose), so the estimated RTO adds the configured detection time -
+// retry_attempts health intervals - to the measured handoff.
+type Status struct {
+	ID               string    `json:"id"`
+	Trigger          Trigger   `json:"trigger"`
+	Phase            Phase     `json:"phase"`
+	Started          time.Time `json:"started"`
+	RevertAt         time.Time `json:"revert_at"`
+	Finished         time.Time `json:"finished,omitempty"`
+	DetectionSeconds float64   `json:"detection_seconds"`
+	ReleaseSeconds   float64   `json:"release_seconds,omitempty"`
+	HandoffSeconds   float64   `json:"handoff_seconds,omitempty"`
+	StandbySeconds   float64   `json:"standby_seconds,omitempty"`
+	FailbackSeconds  float64   `json:"failback_seconds,omitempty"`
+	EstimatedRTO     float64   `json:"estimated_rto_seconds,omitempty"`
+	Error            string    `json:"error,omitempty"`
+}
+
+// Done reports whether the drill has finished
+func (s Status) Done() bool {
+	return s.Phase == PhaseCompleted || s.Phase == PhaseFailed
+}
+
+// Target is the node a drill is run on
+type Target interface {
+	// Preflight checks it is safe to start: this node is active and the
+	// standby is healthy
+	Preflight() error
+	// HandOver fails over to the standby
+	HandOver() error
+	// PeerActive reports whether the standby has taken over
+	PeerActive() (bool, error)
+	// TakeBack fails back to this node
+	TakeBack() error
+}
+
+// Runner performs failover rehearsals: a real handover to the standby that
+// is automatically reverted after a fixed time, or earlier on request.
+// Each drill's timings are recorded in the history store.
+type Runner struct {
+	target    Target
+	history   *history.Store
+	nodeID    string
+	detection time.Duration
+	logger    *logger.Logger
+
+	mu      sync.Mutex
+	current *Status
+	revert  chan struct{}
+	stopCh  chan struct{}
+}
+
+// NewRunner creates a drill runner for target
+func NewRunner(cfg *config.Config, target Target, store *history.Store) *Runner {
+	newLogger := logger.New(cfg, "drill")
+
+	detection := time.Duration(cfg.Failover.RetryAttempts) * cfg.Health.Interval.Duration()
+
+	return &Runner{
+		target:    target,
+		history:   store,
+		nodeID:    cfg.Node.ID,
+		detection: detection,
+		logger:    newLogger,
+		stopCh:    make(chan struct{}),
+	}
+}
+
+// Start begins a manual drill that fails back after duration
+func (r *Runner) Start(duration time.Duration) (Status, error) {
+	return r.start(duration, TriggerManual)
+}
+
+// start begins a drill
+func (r *Runner) start(duration time.Duration, trigger Trigger) (Status, error) {
+	if duration <= 0 {
+		return Status{}, fmt.Errorf("drill duration must be positive")
+	}
+
+	r.mu.Lock()
+	if r.current != nil && !r.current.Done() {
+		r.mu.Unlock()
+		return Status{}, ErrRunning
+	}
+	r.mu.Unlock()
+
+	if err := r.target.Preflight(); err != nil {
+		return Status{}, fmt.Errorf("drill preflight failed: %w", err)
+	}
+
+	now := time.Now().UTC()
+	status := &Status{
+		ID:               now.Format("20060102-150405"),
+		Trigger:          trigger,
+		Phase:            PhaseHandover,
+		Started:          now,
+		RevertAt:         now.Add(duration),
+		DetectionSeconds: r.detection.Seconds(),
+	}
+
+	r.mu.Lock()
+	r.current = status
+	r.revert = make(chan struct{}, 1)
+	r.mu.Unlock()
+
+	r.logger.Info("Starting failover drill %s, reverting after %v", status.ID, duration)
+	r.record(*status, fmt.Sprintf("Drill started, reverting after %v", duration))
+
+	go r.run(duration)
+	return *status, nil
+}
+
+// Revert ends the standby phase early and fails back now
+func (r *Runner) Revert() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.current == nil || r.current.Done() {
+		return ErrNotRunning
+	}
+	select {
+	case r.revert <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Status returns the current or last drill
+func (r *Runner) Status() (Status, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.current == nil {
+		return Status{}, false
+	}
+	return *r.current, true
+}
+
+// Running reports whether a drill is in progress. Automatic failback is
+// suppressed meanwhile so it cannot cut the standby phase short.
+func (r *Runner) Running() bool {
+	if r == nil {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.current != nil && !r.current.Done()
+}
+
+// Stop abandons a running drill on shutdown. The standby keeps validator
+// duties rather than racing a failback against the shutdown.
+func (r *Runner) Stop() {
+	close(r.stopCh)
+}
+
+// run drives a drill through its phases
+func (r *Runner) run(duration time.Duration) {
+	start := time.Now()
+
+	if err := r.target.HandOver(); err != nil {
+		r.fail(fmt.Errorf("handover failed: %w", err))
+		return
+	}
+	release := time.Since(start)
+	r.update(func(s *Status) {
+		s.ReleaseSeconds = release.Seconds()
+	})
+	if err := r.waitForPeer(); err != nil {
+		// The standby never took over: reclaim duties right away
+		r.fail(err)
+		if err := r.target.TakeBack(); err != nil {
+			r.logger.Error("Failed to reclaim duties after aborted drill: %v", err)
+		}
+		return
+	}
+	handoff := time.Since(start)
+	r.update(func(s *Status) {
+		s.Phase = PhaseStandby
+		s.HandoffSeconds = handoff.Seconds()
+		s.EstimatedRTO = (r.detection + handoff).Seconds()
+	})
+	r.logger.Info("Standby took over after %v", handoff.Round(time.Millisecond))
+
+	standbyStart := time.Now()
+	timer := time.NewTimer(duration - handoff)
+	select {
+	case <-timer.C:
+	case <-r.revert:
+		r.logger.Info("Drill revert requested by operator")
+	case <-r.stopCh:
+		timer.Stop()
+		r.fail(fmt.Errorf("aborted by shutdown, standby remains active"))
+		return
+	}
+	timer.Stop()
+	standby := time.Since(standbyStart)
+
+	r.update(func(s *Status) {
+		s.Phase = PhaseFailback
+		s.StandbySeconds = standby.Seconds()
+	})
+
+	failbackStart := time.Now()
+	if err := r.target.TakeBack(); err != nil {
+		r.fail(fmt.Errorf("failback failed: %w", err))
+		return
+	}
+	failback := time.Since(failbackStart)
+
+	r.update(func(s *Status) {
+		s.Phase = PhaseCompleted
+		s.FailbackSeconds = failback.Seconds()
+		s.Finished = time.Now().UTC()
+	})
+	final, _ := r.Status()
+	summary := fmt.Sprintf("handoff %.1fs, failback %.1fs, estimated RTO %.1fs",
+		final.HandoffSeconds, final.FailbackSeconds, final.EstimatedRTO)
+	r.logger.Info("Drill %s completed: %s", final.ID, summary)
+	r.record(final, "Drill completed: "+summary)
+}
+
+// waitForPeer polls until the standby reports itself active
+func (r *Runner) waitForPeer() error {
+	deadline := time.Now().Add(handoffTimeout)
+	for time.Now().Before(deadline) {
+		active, err := r.target.PeerActive()
+		if err == nil && active {
+			return nil
+		}
+		time.Sleep(pollInterval)
+	}
+	return fmt.Errorf("standby did not become active within %v", handoffTimeout)
+}
+
+// update mutates the current status under the lock
+func (r *Runner) update(fn func(*Status)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fn(r.current)
+}
+
+// fail marks the drill failed and records why
+func (r *Runner) fail(err error) {
+	r.update(func(s *Status) {
+		s.Phase = PhaseFailed
+		s.Error = err.Error()
+		s.Finished = time.Now().UTC()
+	})
+	final, _ := r.Status()
+	r.logger.Error("Drill %s failed: %v", final.ID, err)
+	r.record(final, fmt.Sprintf("Drill failed: %v", err))
+}
+
+// record writes the drill's state and timings to the history
+func (r *Runner) record(status Status, message string) {
+	fields := map[string]string{
+		"drill_id":          status.ID,
+		"trigger":           string(status.Trigger),
+		"phase":             string(status.Phase),
+		"detection_seconds": fmt.Sprintf("%.3f", status.DetectionSeconds),
+	}
+	if status.ReleaseSeconds > 0 {
+		fields["release_seconds"] = fmt.Sprintf("%.3f", status.ReleaseSeconds)
+	}
+	if status.HandoffSeconds > 0 {
+		fields["handoff_seconds"] = fmt.Sprintf("%.3f", status.HandoffSeconds)
+	}
+	if status.EstimatedRTO > 0 {
+		fields["estimated_rto_seconds"] = fmt.Sprintf("%.3f", status.EstimatedRTO)
+	}
+	if status.StandbySeconds > 0 {
+		fields["standby_seconds"] = fmt.Sprintf("%.3f", status.StandbySeconds)
+	}
+	if status.FailbackSeconds > 0 {
+		fields["failback_seconds"] = fmt.Sprintf("%.3f", status.FailbackSeconds)
+	}
+	if status.Error != "" {
+		fields["error"] = status.Error
+	}
+
+	r.append(message, fields)
+}
+
+// append writes a drill decision to the history
+func (r *Runner) append(message string, fields map[string]string) {
+	if err := r.history.Append(history.Entry{
+		Kind:    history.KindDecision,
+		Type:    "drill",
+		NodeID:  r.nodeID,
+		Message: message,
+		Fields:  fields,
+	}); err != nil {
+		r.logger.Warn("Failed to record drill history: %v", err)
+	}
+}
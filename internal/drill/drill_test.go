@@ -0,0 +1,140 @@
+package drill
+
+import (
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/history"
+)
+
+// fakeTarget simulates a two-node cluster
+type fakeTarget struct {
+	mu           sync.Mutex
+	preflightErr error
+	peerActive   bool
+	takeBacks    int
+}
+
+func (f *fakeTarget) Preflight() error { return f.preflightErr }
+
+func (f *fakeTarget) HandOver() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.peerActive = true
+	return nil
+}
+
+func (f *fakeTarget) PeerActive() (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.peerActive, nil
+}
+
+func (f *fakeTarget) TakeBack() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.peerActive = false
+	f.takeBacks++
+	return nil
+}
+
+func newTestRunner(t *testing.T, target Target) (*Runner, *history.Store) {
+	cfg := &config.Config{
+		Node:    config.NodeConfig{ID: "node-1"},
+		Logging: config.LoggingConfig{Level: "error", File: "/dev/null"},
+	}
+	store := history.NewStore(filepath.Join(t.TempDir(), "history.jsonl"), 0)
+	return NewRunner(cfg, target, store), store
+}
+
+// waitDone polls until the drill finishes
+func waitDone(t *testing.T, r *Runner) Status {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if status, ok := r.Status(); ok && status.Done() {
+			return status
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("Drill did not finish")
+	return Status{}
+}
+
+func TestRunner_AutomaticFailback(t *testing.T) {
+	target := &fakeTarget{}
+	r, store := newTestRunner(t, target)
+
+	if _, err := r.Start(100 * time.Millisecond); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if _, err := r.Start(time.Minute); !errors.Is(err, ErrRunning) {
+		t.Errorf("Second Start = %v, want ErrRunning", err)
+	}
+
+	status := waitDone(t, r)
+	if status.Phase != PhaseCompleted {
+		t.Fatalf("Phase = %s, want completed (%s)", status.Phase, status.Error)
+	}
+	if target.takeBacks != 1 {
+		t.Errorf("Expected one failback, got %d", target.takeBacks)
+	}
+	if status.StandbySeconds < 0.05 {
+		t.Errorf("Standby phase too short: %.3fs", status.StandbySeconds)
+	}
+
+	var completed *history.Entry
+	store.Read(time.Time{}, func(e history.Entry) error {
+		if e.Type == "drill" && e.Fields["phase"] == string(PhaseCompleted) {
+			completed = &e
+		}
+		return nil
+	})
+	if completed == nil {
+		t.Fatal("Completed drill not recorded in history")
+	}
+	if completed.Kind != history.KindDecision || completed.Fields["failback_seconds"] == "" {
+		t.Errorf("Drill history entry missing timings: %+v", completed)
+	}
+}
+
+func TestRunner_RevertEarly(t *testing.T) {
+	target := &fakeTarget{}
+	r, _ := newTestRunner(t, target)
+
+	if _, err := r.Start(time.Hour); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if status, _ := r.Status(); status.Phase == PhaseStandby {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := r.Revert(); err != nil {
+		t.Fatalf("Revert failed: %v", err)
+	}
+
+	if status := waitDone(t, r); status.Phase != PhaseCompleted {
+		t.Errorf("Phase = %s, want completed", status.Phase)
+	}
+	if err := r.Revert(); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("Revert after completion = %v, want ErrNotRunning", err)
+	}
+}
+
+func TestRunner_PreflightFailure(t *testing.T) {
+	target := &fakeTarget{preflightErr: errors.New("standby is unhealthy")}
+	r, _ := newTestRunner(t, target)
+
+	if _, err := r.Start(time.Minute); err == nil {
+		t.Fatal("Start should fail when preflight fails")
+	}
+	if r.Running() {
+		t.Error("No drill should be running after a failed preflight")
+	}
+}
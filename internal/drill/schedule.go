@@ -0,0 +1,143 @@
+package drill
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/cron"
+)
+
+// Blackout is a period in which no drill may run
+type Blackout struct {
+	Reason   string
+	start    time.Time
+	end      time.Time
+	schedule *cron.Schedule
+	duration time.Duration
+}
+
+// ParseBlackouts converts configured blackout windows
+func ParseBlackouts(cfgs []config.BlackoutConfig) ([]Blackout, error) {
+	blackouts := make([]Blackout, 0, len(cfgs))
+	for i, c := range cfgs {
+		b := Blackout{Reason: c.Reason}
+		if c.Cron != "" {
+			schedule, err := cron.Parse(c.Cron)
+			if err != nil {
+				return nil, fmt.Errorf("blackouts[%d]: %w", i, err)
+			}
+			b.schedule = schedule
+			b.duration = c.Duration.Duration()
+		} else {
+			var err error
+			if b.start, err = time.Parse(time.RFC3339, c.Start); err != nil {
+				return nil, fmt.Errorf("blackouts[%d].start: %w", i, err)
+			}
+			if b.end, err = time.Parse(time.RFC3339, c.End); err != nil {
+				return nil, fmt.Errorf("blackouts[%d].end: %w", i, err)
+			}
+		}
+		blackouts = append(blackouts, b)
+	}
+	return blackouts, nil
+}
+
+// Overlaps reports whether the blackout intersects [from, to)
+func (b Blackout) Overlaps(from, to time.Time) bool {
+	if b.schedule == nil {
+		return b.start.Before(to) && b.end.After(from)
+	}
+	// A recurrence still running at from started no earlier than
+	// from - duration; check each one that starts before to
+	for next := b.schedule.Next(from.Add(-b.duration - time.Minute)); !next.IsZero() && next.Before(to); next = b.schedule.Next(next) {
+		if next.Add(b.duration).After(from) {
+			return true
+		}
+	}
+	return false
+}
+
+// Scheduler starts drills on a cron schedule, skipping runs that would
+// overlap a blackout window or whose preflight checks fail
+type Scheduler struct {
+	runner    *Runner
+	schedule  *cron.Schedule
+	duration  time.Duration
+	blackouts []Blackout
+}
+
+// NewScheduler creates a scheduler from config; it returns nil when no
+// schedule is configured
+func NewScheduler(cfg *config.Config, runner *Runner) (*Scheduler, error) {
+	if cfg.Drill.Schedule == "" {
+		return nil, nil
+	}
+
+	schedule, err := cron.Parse(cfg.Drill.Schedule)
+	if err != nil {
+		return nil, fmt.Errorf("invalid drill schedule: %w", err)
+	}
+	blackouts, err := ParseBlackouts(cfg.Drill.Blackouts)
+	if err != nil {
+		return nil, fmt.Errorf("invalid drill blackout: %w", err)
+	}
+
+	return &Scheduler{
+		runner:    runner,
+		schedule:  schedule,
+		duration:  cfg.Drill.Duration.Duration(),
+		blackouts: blackouts,
+	}, nil
+}
+
+// Run waits for each scheduled time and triggers a drill until stopCh closes
+func (s *Scheduler) Run(stopCh <-chan struct{}) {
+	for {
+		next := s.schedule.Next(time.Now())
+		if next.IsZero() {
+			s.runner.logger.Warn("Drill schedule never matches, scheduled drills disabled")
+			return
+		}
+		s.runner.logger.Info("Next scheduled drill at %s", next.Format(time.RFC3339))
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-timer.C:
+			s.Trigger(time.Now())
+		case <-stopCh:
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// Trigger attempts a scheduled drill at now. Skips are recorded in the
+// history so reports show why a drill did not happen.
+func (s *Scheduler) Trigger(now time.Time) {
+	for _, b := range s.blackouts {
+		if b.Overlaps(now, now.Add(s.duration)) {
+			s.skip(fmt.Sprintf("blackout window %q", b.Reason))
+			return
+		}
+	}
+
+	if _, err := s.runner.start(s.duration, TriggerSchedule); err != nil {
+		if errors.Is(err, ErrNotActive) {
+			// Every node runs the schedule; only the active one drills
+			s.runner.logger.Debug("Scheduled drill skipped on passive node")
+			return
+		}
+		s.skip(err.Error())
+	}
+}
+
+// skip logs and records a skipped scheduled drill
+func (s *Scheduler) skip(reason string) {
+	s.runner.logger.Warn("Scheduled drill skipped: %s", reason)
+	s.runner.append("Scheduled drill skipped: "+reason, map[string]string{
+		"trigger": string(TriggerSchedule),
+		"phase":   string(PhaseSkipped),
+	})
+}
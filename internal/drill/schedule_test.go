@@ -0,0 +1,147 @@
+package drill
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/history"
+)
+
+func TestBlackout_Overlaps(t *testing.T) {
+	blackouts, err := ParseBlackouts([]config.BlackoutConfig{
+		{Reason: "holidays", Start: "2026-12-24T00:00:00Z", End: "2026-12-27T00:00:00Z"},
+		{Reason: "weekly upgrade window", Cron: "0 14 * * 3", Duration: 7200}, // Wednesdays 14:00-16:00
+	})
+	if err != nil {
+		t.Fatalf("ParseBlackouts failed: %v", err)
+	}
+	holidays, weekly := blackouts[0], blackouts[1]
+
+	at := func(s string) time.Time {
+		ts, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatalf("bad time %q", s)
+		}
+		return ts
+	}
+
+	tests := []struct {
+		name     string
+		blackout Blackout
+		from     string
+		minutes  int
+		want     bool
+	}{
+		{"inside fixed window", holidays, "2026-12-25T10:00:00Z", 10, true},
+		{"ends inside fixed window", holidays, "2026-12-23T23:55:00Z", 10, true},
+		{"before fixed window", holidays, "2026-12-23T10:00:00Z", 10, false},
+		{"inside recurring window", weekly, "2026-10-14T15:00:00Z", 10, true},
+		{"runs into recurring window", weekly, "2026-10-14T13:55:00Z", 10, true},
+		{"after recurring window", weekly, "2026-10-14T16:00:00Z", 10, false},
+		{"other weekday", weekly, "2026-10-15T15:00:00Z", 10, false},
+	}
+
+	for _, tt := range tests {
+		from := at(tt.from)
+		if got := tt.blackout.Overlaps(from, from.Add(time.Duration(tt.minutes)*time.Minute)); got != tt.want {
+			t.Errorf("%s: Overlaps = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestScheduler_Trigger(t *testing.T) {
+	cfg := &config.Config{
+		Node:    config.NodeConfig{ID: "node-1"},
+		Logging: config.LoggingConfig{Level: "error", File: "/dev/null"},
+		Drill: config.DrillConfig{
+			Schedule: "0 10 * * 2",
+			Duration: 600,
+			Blackouts: []config.BlackoutConfig{
+				{Reason: "freeze", Start: "2026-10-20T00:00:00Z", End: "2026-10-21T00:00:00Z"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		now       string
+		preflight error
+		wantSkip  string
+	}{
+		{"blackout", "2026-10-20T10:00:00Z", nil, `blackout window "freeze"`},
+		{"unhealthy peer", "2026-10-27T10:00:00Z", fmt.Errorf("peer validator-2 is unhealthy"), "peer validator-2 is unhealthy"},
+		{"passive node", "2026-10-27T10:00:00Z", ErrNotActive, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := &fakeTarget{preflightErr: tt.preflight}
+			runner, store := newTestRunner(t, target)
+			scheduler, err := NewScheduler(cfg, runner)
+			if err != nil {
+				t.Fatalf("NewScheduler failed: %v", err)
+			}
+
+			now, _ := time.Parse(time.RFC3339, tt.now)
+			scheduler.Trigger(now)
+
+			var skipped []history.Entry
+			store.Read(time.Time{}, func(e history.Entry) error {
+				if e.Fields["phase"] == string(PhaseSkipped) {
+					skipped = append(skipped, e)
+				}
+				return nil
+			})
+
+			if tt.wantSkip == "" {
+				if len(skipped) != 0 {
+					t.Errorf("Expected no recorded skip, got %v", skipped)
+				}
+				return
+			}
+			if len(skipped) != 1 || !strings.Contains(skipped[0].Message, tt.wantSkip) {
+				t.Errorf("Expected skip mentioning %q, got %v", tt.wantSkip, skipped)
+			}
+			if runner.Running() {
+				t.Error("Skipped drill must not run")
+			}
+		})
+	}
+}
+
+func TestScheduler_StartsDrill(t *testing.T) {
+	cfg := &config.Config{
+		Node:     config.NodeConfig{ID: "node-1"},
+		Logging:  config.LoggingConfig{Level: "error", File: "/dev/null"},
+		Failover: config.FailoverConfig{RetryAttempts: 3},
+		Health:   config.HealthConfig{Interval: 5},
+		Drill:    config.DrillConfig{Schedule: "* * * * *", Duration: 0.1},
+	}
+	target := &fakeTarget{}
+	runner, _ := newTestRunner(t, target)
+	runner.detection = 15 * time.Second
+	scheduler, err := NewScheduler(cfg, runner)
+	if err != nil {
+		t.Fatalf("NewScheduler failed: %v", err)
+	}
+
+	scheduler.Trigger(time.Now())
+	status := waitDone(t, runner)
+	if status.Phase != PhaseCompleted || status.Trigger != TriggerSchedule {
+		t.Fatalf("Scheduled drill = %s/%s, want completed/schedule (%s)", status.Phase, status.Trigger, status.Error)
+	}
+	if status.EstimatedRTO < 15 {
+		t.Errorf("Estimated RTO %.1fs should include 15s detection", status.EstimatedRTO)
+	}
+}
+
+func TestNewScheduler_Disabled(t *testing.T) {
+	runner, _ := newTestRunner(t, &fakeTarget{})
+	scheduler, err := NewScheduler(&config.Config{}, runner)
+	if err != nil || scheduler != nil {
+		t.Errorf("NewScheduler without schedule = %v, %v; want nil, nil", scheduler, err)
+	}
+}
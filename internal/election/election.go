@@ -0,0 +1,361 @@
+// Package election decides which node holds the active role by majority
+// vote, so a node cut off from the rest of the cluster can neither keep
+// nor take the role.
+//
+// It follows Raft's leader election with leases. Every node is a voter.
+// A candidate starts a new term and asks every voter for a lease; a voter
+// grants at most one lease per term and, once it has, refuses any other
+// candidate until that lease runs out, whatever the term. A candidate
+// granted a lease by a majority holds the role until its lease expires,
+// and keeps it by renewing the lease within the same term.
+//
+// The leader counts its lease from before it asked and ends it early, by
+// a tenth of the TTL, while voters count theirs from when they answered.
+// So the leader always gives up the role before any voter would let
+// another node have it, as long as clocks tick at roughly the same rate;
+// they need not agree on the time.
+package election
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/metrics"
+)
+
+var (
+	termGauge = metrics.NewGauge(
+		"syncguard_election_term",
+		"Latest election term this node has seen",
+	)
+	leaderGauge = metrics.NewGauge(
+		"syncguard_election_leader",
+		"1 while this node holds the active lease",
+	)
+	campaignCounter = metrics.NewCounter(
+		"syncguard_election_campaigns_total",
+		"Elections this node started, by result",
+		"result",
+	)
+)
+
+var (
+	// ErrLeaseHeld means a voter has granted the lease to another node
+	// and it has not expired
+	ErrLeaseHeld = errors.New("lease held by another node")
+	// ErrNoQuorum means too few voters answered to reach a majority
+	ErrNoQuorum = errors.New("no majority of voters reachable")
+)
+
+// Request asks a voter for the lease in a term, or with Resign gives the
+// lease back
+type Request struct {
+	Term      uint64 `json:"term"`
+	Candidate string `json:"candidate"`
+	Resign    bool   `json:"resign,omitempty"`
+}
+
+// Response is a voter's answer. A denied request names the holder of the
+// lease that blocked it, if one did.
+type Response struct {
+	Voter   string `json:"voter"`
+	Term    uint64 `json:"term"`
+	Granted bool   `json:"granted"`
+	Holder  string `json:"holder,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// Transport sends a request to the voter at a peer address
+type Transport interface {
+	RequestLease(addr string, req Request) (*Response, error)
+}
+
+// Status is this node's view of the election
+type Status struct {
+	Term uint64 `json:"term"`
+	// Leader is true while this node holds the lease, until LeaseUntil
+	Leader     bool      `json:"leader"`
+	LeaseUntil time.Time `json:"lease_until,omitempty"`
+	// Holder is who this node, as a voter, has granted the lease to, until
+	// HolderUntil
+	Holder      string    `json:"holder,omitempty"`
+	HolderUntil time.Time `json:"holder_until,omitempty"`
+	Voters      int       `json:"voters"`
+}
+
+// voterState is what a voter must remember across restarts: a grant made
+// before a crash still binds it
+type voterState struct {
+	Term     uint64    `json:"term"`
+	VotedFor string    `json:"voted_for,omitempty"`
+	Holder   string    `json:"holder,omitempty"`
+	Expires  time.Time `json:"expires,omitempty"`
+}
+
+// Elector is this node as both voter and candidate
+type Elector struct {
+	id        string
+	path      string
+	peers     []string
+	ttl       time.Duration
+	transport Transport
+
+	mu          sync.Mutex
+	voter       voterState
+	leaderTerm  uint64
+	leaderUntil time.Time
+}
+
+// New creates the elector for node id, voting with the peers at the given
+// addresses; the voter's state is kept at path
+func New(id, path string, peers []string, ttl time.Duration, transport Transport) (*Elector, error) {
+	e := &Elector{id: id, path: path, peers: peers, ttl: ttl, transport: transport}
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &e.voter); err != nil {
+			return nil, fmt.Errorf("failed to parse election state %s: %w", path, err)
+		}
+	case !os.IsNotExist(err):
+		return nil, fmt.Errorf("failed to read election state: %w", err)
+	}
+	termGauge.Set(float64(e.voter.Term))
+	return e, nil
+}
+
+// Vote answers a candidate, or takes back a resigned lease
+func (e *Elector) Vote(req Request) Response {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := time.Now()
+	resp := Response{Voter: e.id, Term: e.voter.Term}
+
+	if req.Resign {
+		if e.voter.Holder == req.Candidate {
+			e.voter.Holder = ""
+			e.voter.Expires = time.Time{}
+			if err := e.save(); err != nil {
+				resp.Reason = err.Error()
+				return resp
+			}
+		}
+		resp.Granted = true
+		return resp
+	}
+
+	// A live lease binds the voter whatever the term, so a node that was
+	// cut off cannot unseat the leader by campaigning with higher terms
+	if e.voter.Holder != "" && e.voter.Holder != req.Candidate && now.Before(e.voter.Expires) {
+		resp.Holder = e.voter.Holder
+		resp.Reason = fmt.Sprintf("lease granted to %s until %s", e.voter.Holder, e.voter.Expires.Format(time.RFC3339))
+		return resp
+	}
+	if req.Term < e.voter.Term {
+		resp.Reason = fmt.Sprintf("term %d is behind term %d", req.Term, e.voter.Term)
+		return resp
+	}
+	if req.Term > e.voter.Term {
+		e.voter.Term = req.Term
+		e.voter.VotedFor = ""
+	}
+	if e.voter.VotedFor != "" && e.voter.VotedFor != req.Candidate {
+		resp.Term = e.voter.Term
+		resp.Reason = fmt.Sprintf("already voted for %s in term %d", e.voter.VotedFor, e.voter.Term)
+		return resp
+	}
+
+	previous := e.voter
+	e.voter.VotedFor = req.Candidate
+	e.voter.Holder = req.Candidate
+	e.voter.Expires = now.Add(e.ttl).UTC()
+	if err := e.save(); err != nil {
+		// A grant that is not on disk could be forgotten in a crash
+		e.voter = previous
+		resp.Reason = err.Error()
+		return resp
+	}
+	termGauge.Set(float64(e.voter.Term))
+	resp.Term = e.voter.Term
+	resp.Granted = true
+	return resp
+}
+
+// Campaign starts a new term and asks every voter for the lease. It
+// returns nil once a majority granted it; otherwise the grants it did get
+// are given back.
+func (e *Elector) Campaign() error {
+	e.mu.Lock()
+	term := e.voter.Term + 1
+	e.mu.Unlock()
+
+	err := e.solicit(term)
+	result := "won"
+	if err != nil {
+		result = "lost"
+		e.resign(term)
+	}
+	campaignCounter.Inc(result)
+	return err
+}
+
+// Renew extends the lease within the term it was won in. Failing that,
+// the node remains leader until the current lease expires.
+func (e *Elector) Renew() error {
+	e.mu.Lock()
+	term := e.leaderTerm
+	e.mu.Unlock()
+	if term == 0 {
+		return fmt.Errorf("not the leader")
+	}
+	return e.solicit(term)
+}
+
+// Resign gives the lease back so another node can be elected without
+// waiting for it to expire. It must only be called once this node has
+// stopped signing.
+func (e *Elector) Resign() {
+	e.mu.Lock()
+	term := e.leaderTerm
+	e.mu.Unlock()
+	if term != 0 {
+		e.resign(term)
+	}
+}
+
+// Leader reports whether this node holds an unexpired lease
+func (e *Elector) Leader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.leaderTerm != 0 && time.Now().Before(e.leaderUntil)
+}
+
+// Status returns this node's view of the election
+func (e *Elector) Status() Status {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	now := time.Now()
+	s := Status{Term: e.voter.Term, Voters: len(e.peers) + 1}
+	if e.leaderTerm != 0 && now.Before(e.leaderUntil) {
+		s.Leader = true
+		s.LeaseUntil = e.leaderUntil.UTC()
+	}
+	if e.voter.Holder != "" && now.Before(e.voter.Expires) {
+		s.Holder = e.voter.Holder
+		s.HolderUntil = e.voter.Expires
+	}
+	return s
+}
+
+// solicit asks this node and every peer for the lease in term, and takes
+// the role when a majority grants it
+func (e *Elector) solicit(term uint64) error {
+	start := time.Now()
+	req := Request{Term: term, Candidate: e.id}
+	responses := e.ask(req)
+
+	granted, reachable := 0, 0
+	var holder, reasons string
+	for _, resp := range responses {
+		if resp == nil {
+			continue
+		}
+		reachable++
+		if resp.Granted {
+			granted++
+			continue
+		}
+		if resp.Holder != "" {
+			holder = resp.Holder
+		}
+		if reasons != "" {
+			reasons += "; "
+		}
+		reasons += resp.Voter + ": " + resp.Reason
+	}
+
+	majority := len(responses)/2 + 1
+	if granted >= majority {
+		e.mu.Lock()
+		e.leaderTerm = term
+		e.leaderUntil = start.Add(e.ttl - e.ttl/10)
+		e.mu.Unlock()
+		leaderGauge.Set(1)
+		return nil
+	}
+
+	e.mu.Lock()
+	if e.leaderTerm == term && time.Now().After(e.leaderUntil) {
+		e.leaderTerm = 0
+		leaderGauge.Set(0)
+	}
+	e.mu.Unlock()
+
+	switch {
+	case holder != "":
+		return fmt.Errorf("%w (%s): %s", ErrLeaseHeld, holder, reasons)
+	case reachable < majority:
+		return fmt.Errorf("%w: %d of %d voters answered, %d granted", ErrNoQuorum, reachable, len(responses), granted)
+	}
+	return fmt.Errorf("lease granted by %d of %d voters, %d needed: %s", granted, len(responses), majority, reasons)
+}
+
+// resign gives back the lease of term to every voter; failures are left to
+// expire
+func (e *Elector) resign(term uint64) {
+	e.mu.Lock()
+	if e.leaderTerm == term {
+		e.leaderTerm = 0
+		e.leaderUntil = time.Time{}
+	}
+	e.mu.Unlock()
+	leaderGauge.Set(0)
+	e.ask(Request{Term: term, Candidate: e.id, Resign: true})
+}
+
+// ask sends req to this node's voter and every peer at once; an
+// unreachable peer's response is nil
+func (e *Elector) ask(req Request) []*Response {
+	responses := make([]*Response, len(e.peers)+1)
+	self := e.Vote(req)
+	responses[0] = &self
+
+	var wg sync.WaitGroup
+	for i, addr := range e.peers {
+		wg.Add(1)
+		go func(i int, addr string) {
+			defer wg.Done()
+			resp, err := e.transport.RequestLease(addr, req)
+			if err != nil {
+				return
+			}
+			responses[i+1] = resp
+		}(i, addr)
+	}
+	wg.Wait()
+	return responses
+}
+
+// save writes the voter's state atomically; the caller holds e.mu
+func (e *Elector) save() error {
+	data, err := json.Marshal(e.voter)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(e.path), 0700); err != nil {
+		return fmt.Errorf("failed to create election state directory: %w", err)
+	}
+	tmp := e.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write election state: %w", err)
+	}
+	if err := os.Rename(tmp, e.path); err != nil {
+		return fmt.Errorf("failed to write election state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package election_test
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/election"
+)
+
+// cluster connects electors in process; addresses are node ids
+type cluster struct {
+	mu       sync.Mutex
+	nodes    map[string]*election.Elector
+	isolated map[string]bool
+}
+
+// transport is one node's view of the cluster
+type transport struct {
+	c    *cluster
+	from string
+}
+
+func (t transport) RequestLease(addr string, req election.Request) (*election.Response, error) {
+	t.c.mu.Lock()
+	node := t.c.nodes[addr]
+	cut := t.c.isolated[t.from] || t.c.isolated[addr]
+	t.c.mu.Unlock()
+	if node == nil || cut {
+		return nil, fmt.Errorf("%s unreachable", addr)
+	}
+	resp := node.Vote(req)
+	return &resp, nil
+}
+
+func (c *cluster) isolate(id string, cut bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.isolated[id] = cut
+}
+
+func newCluster(t *testing.T, dir string, ttl time.Duration, ids ...string) *cluster {
+	c := &cluster{nodes: map[string]*election.Elector{}, isolated: map[string]bool{}}
+	for _, id := range ids {
+		var peers []string
+		for _, other := range ids {
+			if other != id {
+				peers = append(peers, other)
+			}
+		}
+		e, err := election.New(id, filepath.Join(dir, id+".json"), peers, ttl, transport{c: c, from: id})
+		if err != nil {
+			t.Fatalf("New(%s): %v", id, err)
+		}
+		c.nodes[id] = e
+	}
+	return c
+}
+
+func TestElector_MajorityWins(t *testing.T) {
+	c := newCluster(t, t.TempDir(), time.Minute, "a", "b", "c")
+
+	if err := c.nodes["a"].Campaign(); err != nil {
+		t.Fatalf("Campaign() = %v", err)
+	}
+	if !c.nodes["a"].Leader() {
+		t.Error("a should lead after winning")
+	}
+
+	err := c.nodes["b"].Campaign()
+	if !errors.Is(err, election.ErrLeaseHeld) {
+		t.Fatalf("second Campaign() = %v, want ErrLeaseHeld", err)
+	}
+	if c.nodes["b"].Leader() {
+		t.Error("b must not lead while a's lease is live")
+	}
+	if err := c.nodes["a"].Renew(); err != nil {
+		t.Errorf("Renew() = %v", err)
+	}
+}
+
+func TestElector_PartitionedNodeCannotWin(t *testing.T) {
+	c := newCluster(t, t.TempDir(), time.Minute, "a", "b", "c")
+	c.isolate("a", true)
+
+	err := c.nodes["a"].Campaign()
+	if !errors.Is(err, election.ErrNoQuorum) {
+		t.Fatalf("Campaign() = %v, want ErrNoQuorum", err)
+	}
+
+	// The majority side can still elect
+	if err := c.nodes["b"].Campaign(); err != nil {
+		t.Fatalf("Campaign() on the majority side = %v", err)
+	}
+
+	// Once healed, a's higher terms do not unseat b
+	c.isolate("a", false)
+	for i := 0; i < 3; i++ {
+		if err := c.nodes["a"].Campaign(); err == nil {
+			t.Fatal("a must not win while b's lease is live")
+		}
+	}
+	if err := c.nodes["b"].Renew(); err != nil {
+		t.Errorf("b should still renew: %v", err)
+	}
+}
+
+func TestElector_TwoNodesNeedBoth(t *testing.T) {
+	c := newCluster(t, t.TempDir(), time.Minute, "a", "b")
+	c.isolate("b", true)
+
+	if err := c.nodes["a"].Campaign(); err == nil {
+		t.Fatal("one of two voters is not a majority")
+	}
+	if err := c.nodes["b"].Campaign(); err == nil {
+		t.Fatal("one of two voters is not a majority")
+	}
+}
+
+func TestElector_LeaseExpires(t *testing.T) {
+	c := newCluster(t, t.TempDir(), 100*time.Millisecond, "a", "b", "c")
+
+	if err := c.nodes["a"].Campaign(); err != nil {
+		t.Fatalf("Campaign() = %v", err)
+	}
+	c.isolate("a", true)
+	if err := c.nodes["a"].Renew(); err == nil {
+		t.Fatal("Renew() without a majority should fail")
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	if c.nodes["a"].Leader() {
+		t.Error("a should stop leading once its lease ran out")
+	}
+	if err := c.nodes["b"].Campaign(); err != nil {
+		t.Errorf("b should win once a's lease expired: %v", err)
+	}
+}
+
+func TestElector_Resign(t *testing.T) {
+	c := newCluster(t, t.TempDir(), time.Minute, "a", "b", "c")
+
+	if err := c.nodes["a"].Campaign(); err != nil {
+		t.Fatalf("Campaign() = %v", err)
+	}
+	c.nodes["a"].Resign()
+	if c.nodes["a"].Leader() {
+		t.Error("a should not lead after resigning")
+	}
+	if err := c.nodes["b"].Campaign(); err != nil {
+		t.Errorf("b should win right after a resigned: %v", err)
+	}
+}
+
+func TestElector_VoteRules(t *testing.T) {
+	e, err := election.New("v", filepath.Join(t.TempDir(), "v.json"), nil, time.Minute, nil)
+	if err != nil {
+		t.Fatalf("New() = %v", err)
+	}
+
+	if resp := e.Vote(election.Request{Term: 5, Candidate: "a"}); !resp.Granted {
+		t.Fatalf("first vote denied: %s", resp.Reason)
+	}
+	if resp := e.Vote(election.Request{Term: 5, Candidate: "a"}); !resp.Granted {
+		t.Errorf("renewal in the same term denied: %s", resp.Reason)
+	}
+	e.Vote(election.Request{Term: 5, Candidate: "a", Resign: true})
+
+	if resp := e.Vote(election.Request{Term: 5, Candidate: "b"}); resp.Granted {
+		t.Error("second candidate granted in a term already voted in")
+	}
+	if resp := e.Vote(election.Request{Term: 4, Candidate: "b"}); resp.Granted {
+		t.Error("stale term granted")
+	}
+	if resp := e.Vote(election.Request{Term: 6, Candidate: "b"}); !resp.Granted {
+		t.Errorf("newer term denied: %s", resp.Reason)
+	}
+}
+
+func TestElector_GrantSurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "v.json")
+	e, err := election.New("v", path, nil, time.Minute, nil)
+	if err != nil {
+		t.Fatalf("New() = %v", err)
+	}
+	if resp := e.Vote(election.Request{Term: 3, Candidate: "a"}); !resp.Granted {
+		t.Fatalf("vote denied: %s", resp.Reason)
+	}
+
+	restarted, err := election.New("v", path, nil, time.Minute, nil)
+	if err != nil {
+		t.Fatalf("New() after restart = %v", err)
+	}
+	resp := restarted.Vote(election.Request{Term: 4, Candidate: "b"})
+	if resp.Granted {
+		t.Error("a restarted voter must honour the lease it granted")
+	}
+	if resp.Holder != "a" {
+		t.Errorf("Holder = %q, want a", resp.Holder)
+	}
+	if got := restarted.Status().Term; got != 3 {
+		t.Errorf("Term = %d, want 3", got)
+	}
+}
@@ -0,0 +1,58 @@
+package election
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aldebaranode/syncguard/internal/state"
+)
+
+// Lock makes winning the election part of taking the state lock: Acquire
+// campaigns before taking the inner lock, and Release resigns after
+// giving it up
+type Lock struct {
+	elector *Elector
+	inner   state.LockBackend
+}
+
+// NewLock wraps inner so it is only taken with a majority's lease
+func NewLock(elector *Elector, inner state.LockBackend) *Lock {
+	return &Lock{elector: elector, inner: inner}
+}
+
+// Name returns the backend name
+func (l *Lock) Name() string {
+	return "election+" + l.inner.Name()
+}
+
+// Acquire wins the lease, then takes the inner lock
+func (l *Lock) Acquire() error {
+	if err := l.elector.Campaign(); err != nil {
+		if errors.Is(err, ErrLeaseHeld) {
+			return fmt.Errorf("%w: %v", state.ErrLockHeld, err)
+		}
+		return fmt.Errorf("%w: %v", state.ErrLockUnreachable, err)
+	}
+	if err := l.inner.Acquire(); err != nil {
+		l.elector.Resign()
+		return err
+	}
+	return nil
+}
+
+// Release gives up the inner lock, then the lease
+func (l *Lock) Release() error {
+	err := l.inner.Release()
+	l.elector.Resign()
+	return err
+}
+
+// Held reports whether this node holds the inner lock
+func (l *Lock) Held() bool {
+	return l.inner.Held()
+}
+
+// Check reports whether the inner lock can be reached
+func (l *Lock) Check() error {
+	return l.inner.Check()
+}
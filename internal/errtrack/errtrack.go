@@ -0,0 +1,233 @@
+// Package errtrack reports panics and Error-level log lines to an error
+// tracker, so intermittent failures across a fleet of validators are
+// aggregated in one place.
+package errtrack
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/logger"
+	log "github.com/sirupsen/logrus"
+)
+
+// queueSize bounds the events waiting to be sent; more are dropped
+const queueSize = 100
+
+// Levels of reported events
+const (
+	LevelError = "error"
+	LevelFatal = "fatal"
+)
+
+// FieldPanic marks the log line of a panic already sent with CapturePanic,
+// so the hook does not report it again
+const FieldPanic = "panic"
+
+// Event is one error or panic
+type Event struct {
+	ID          string            `json:"event_id"`
+	Time        time.Time         `json:"timestamp"`
+	Level       string            `json:"level"`
+	Message     string            `json:"message"`
+	Panic       bool              `json:"panic,omitempty"`
+	Stack       string            `json:"stack,omitempty"`
+	Node        string            `json:"node"`
+	Cluster     string            `json:"cluster,omitempty"`
+	Environment string            `json:"environment,omitempty"`
+	Fields      map[string]string `json:"fields,omitempty"`
+}
+
+// transport delivers an event to the tracker
+type transport interface {
+	send(ctx context.Context, event Event) error
+}
+
+// Tracker sends events in the background. Panics and fatal errors are sent
+// before returning, since the process is about to exit.
+type Tracker struct {
+	node        string
+	cluster     string
+	environment string
+	timeout     time.Duration
+	transport   transport
+	queue       chan Event
+	closeMu     sync.RWMutex
+	closed      bool
+	wg          sync.WaitGroup
+	logger      *logger.Logger
+}
+
+// New creates the tracker configured under error_tracking; it returns nil
+// when neither a DSN nor a URL is set
+func New(cfg *config.Config) (*Tracker, error) {
+	tc := cfg.ErrorTracking
+	var tr transport
+	switch {
+	case tc.DSN != "":
+		sentry, err := newSentryTransport(tc.DSN)
+		if err != nil {
+			return nil, err
+		}
+		tr = sentry
+	case tc.URL != "":
+		tr = newWebhookTransport(tc.URL)
+	default:
+		return nil, nil
+	}
+
+	t := &Tracker{
+		node:        cfg.Node.ID,
+		cluster:     tc.Cluster,
+		environment: tc.Environment,
+		timeout:     tc.Timeout.Duration(),
+		transport:   tr,
+		queue:       make(chan Event, queueSize),
+		logger:      logger.New(cfg, "errtrack"),
+	}
+	t.wg.Add(1)
+	go t.run()
+	return t, nil
+}
+
+// Capture queues an event; it is dropped when the queue is full or the
+// tracker is closed
+func (t *Tracker) Capture(level, message string, fields map[string]string) {
+	t.closeMu.RLock()
+	defer t.closeMu.RUnlock()
+	if t.closed {
+		return
+	}
+	select {
+	case t.queue <- t.newEvent(level, message, fields):
+	default:
+		t.logger.Warn("Error tracker queue full, dropping event: %s", message)
+	}
+}
+
+// CapturePanic sends a recovered panic and waits for it to be delivered
+func (t *Tracker) CapturePanic(value interface{}, stack []byte) {
+	event := t.newEvent(LevelFatal, fmt.Sprintf("panic: %v", value), nil)
+	event.Panic = true
+	event.Stack = string(stack)
+	t.deliver(event)
+}
+
+// Close sends the queued events and stops the tracker
+func (t *Tracker) Close() {
+	t.closeMu.Lock()
+	if !t.closed {
+		t.closed = true
+		close(t.queue)
+	}
+	t.closeMu.Unlock()
+	t.wg.Wait()
+}
+
+// newEvent stamps an event with this node's context
+func (t *Tracker) newEvent(level, message string, fields map[string]string) Event {
+	return Event{
+		ID:          newEventID(),
+		Time:        time.Now().UTC(),
+		Level:       level,
+		Message:     message,
+		Node:        t.node,
+		Cluster:     t.cluster,
+		Environment: t.environment,
+		Fields:      fields,
+	}
+}
+
+// run sends queued events until the tracker is closed
+func (t *Tracker) run() {
+	defer t.wg.Done()
+	for event := range t.queue {
+		t.deliver(event)
+	}
+}
+
+// deliver sends one event. Failures are logged at Warn level so they are
+// not reported again.
+func (t *Tracker) deliver(event Event) {
+	ctx, cancel := context.WithTimeout(context.Background(), t.timeout)
+	defer cancel()
+	if err := t.transport.send(ctx, event); err != nil {
+		t.logger.Warn("Failed to report error to tracker: %v", err)
+	}
+}
+
+// newEventID returns 32 hex characters, the format Sentry expects
+func newEventID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// installed is the tracker that receives log lines and panics
+var (
+	mu        sync.RWMutex
+	installed *Tracker
+)
+
+// Install makes t receive every Error-level log line and the panics
+// reported with Recover
+func Install(t *Tracker) {
+	mu.Lock()
+	installed = t
+	mu.Unlock()
+	log.AddHook(&hook{t: t})
+}
+
+// CapturePanic reports a recovered panic to the installed tracker, if any
+func CapturePanic(value interface{}, stack []byte) {
+	mu.RLock()
+	t := installed
+	mu.RUnlock()
+	if t != nil {
+		t.CapturePanic(value, stack)
+	}
+}
+
+// Recover reports a panic in progress and panics again. Defer it at the
+// top of a goroutine.
+func Recover() {
+	if r := recover(); r != nil {
+		CapturePanic(r, debug.Stack())
+		panic(r)
+	}
+}
+
+// hook forwards Error-level and worse log lines to a tracker
+type hook struct {
+	t *Tracker
+}
+
+// Levels returns the levels that are reported
+func (h *hook) Levels() []log.Level {
+	return []log.Level{log.PanicLevel, log.FatalLevel, log.ErrorLevel}
+}
+
+// Fire reports a log line with its fields (module, transition_id, ...)
+func (h *hook) Fire(entry *log.Entry) error {
+	if _, ok := entry.Data[FieldPanic]; ok {
+		return nil
+	}
+	fields := make(map[string]string, len(entry.Data))
+	for k, v := range entry.Data {
+		fields[k] = fmt.Sprint(v)
+	}
+
+	if entry.Level == log.ErrorLevel {
+		h.t.Capture(LevelError, entry.Message, fields)
+		return nil
+	}
+	// Fatal and panic lines end the process, so send them right away
+	h.t.deliver(h.t.newEvent(LevelFatal, entry.Message, fields))
+	return nil
+}
@@ -0,0 +1,159 @@
+package errtrack_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/errtrack"
+)
+
+// collector records the requests an error tracker receives
+type collector struct {
+	mu       sync.Mutex
+	paths    []string
+	headers  []http.Header
+	bodies   [][]byte
+	response int
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	c.mu.Lock()
+	c.paths = append(c.paths, r.URL.Path)
+	c.headers = append(c.headers, r.Header.Clone())
+	c.bodies = append(c.bodies, body)
+	c.mu.Unlock()
+	if c.response != 0 {
+		w.WriteHeader(c.response)
+	}
+}
+
+func testConfig(tracking config.ErrorTrackingConfig) *config.Config {
+	tracking.Timeout = 2
+	return &config.Config{
+		Node:          config.NodeConfig{ID: "validator-1"},
+		Logging:       config.LoggingConfig{Level: "error", File: "/dev/null"},
+		ErrorTracking: tracking,
+	}
+}
+
+func TestNew_Disabled(t *testing.T) {
+	tracker, err := errtrack.New(testConfig(config.ErrorTrackingConfig{}))
+	if err != nil || tracker != nil {
+		t.Errorf("expected no tracker, got %v, %v", tracker, err)
+	}
+}
+
+func TestTracker_Sentry(t *testing.T) {
+	c := &collector{}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	dsn := strings.Replace(srv.URL, "http://", "http://publickey@", 1) + "/sentry/42"
+	tracker, err := errtrack.New(testConfig(config.ErrorTrackingConfig{
+		DSN:         dsn,
+		Cluster:     "story-mainnet",
+		Environment: "production",
+	}))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	tracker.Capture(errtrack.LevelError, "Failed to restart node", map[string]string{
+		"module":        "failover",
+		"transition_id": "abc123",
+		"error":         "exit status 1",
+	})
+	tracker.Close()
+
+	if len(c.paths) != 1 || c.paths[0] != "/sentry/api/42/envelope/" {
+		t.Fatalf("unexpected requests to %v", c.paths)
+	}
+	if auth := c.headers[0].Get("X-Sentry-Auth"); !strings.Contains(auth, "sentry_key=publickey") {
+		t.Errorf("X-Sentry-Auth = %q", auth)
+	}
+
+	// header, item header, event
+	scanner := bufio.NewScanner(bytes.NewReader(c.bodies[0]))
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if len(lines) != 3 {
+		t.Fatalf("expected a 3-line envelope, got %q", c.bodies[0])
+	}
+	var event struct {
+		Level      string            `json:"level"`
+		Logger     string            `json:"logger"`
+		ServerName string            `json:"server_name"`
+		Message    map[string]string `json:"message"`
+		Tags       map[string]string `json:"tags"`
+		Extra      map[string]string `json:"extra"`
+	}
+	if err := json.Unmarshal([]byte(lines[2]), &event); err != nil {
+		t.Fatalf("invalid event: %v", err)
+	}
+	if event.Level != "error" || event.Logger != "failover" || event.ServerName != "validator-1" {
+		t.Errorf("unexpected event %+v", event)
+	}
+	if event.Message["formatted"] != "Failed to restart node" {
+		t.Errorf("message = %v", event.Message)
+	}
+	if event.Tags["cluster"] != "story-mainnet" || event.Tags["node"] != "validator-1" || event.Tags["transition_id"] != "abc123" {
+		t.Errorf("tags = %v", event.Tags)
+	}
+	if event.Extra["error"] != "exit status 1" {
+		t.Errorf("extra = %v", event.Extra)
+	}
+}
+
+func TestTracker_Webhook(t *testing.T) {
+	c := &collector{}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	tracker, err := errtrack.New(testConfig(config.ErrorTrackingConfig{URL: srv.URL, Cluster: "story-mainnet"}))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	tracker.CapturePanic("boom", []byte("goroutine 1 [running]"))
+	tracker.Close()
+
+	if len(c.bodies) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(c.bodies))
+	}
+	var event errtrack.Event
+	if err := json.Unmarshal(c.bodies[0], &event); err != nil {
+		t.Fatalf("invalid event: %v", err)
+	}
+	if !event.Panic || event.Level != errtrack.LevelFatal || event.Message != "panic: boom" ||
+		event.Stack == "" || event.Node != "validator-1" || event.Cluster != "story-mainnet" || len(event.ID) != 32 {
+		t.Errorf("unexpected event %+v", event)
+	}
+}
+
+func TestTracker_CaptureAfterClose(t *testing.T) {
+	c := &collector{response: http.StatusInternalServerError}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	tracker, err := errtrack.New(testConfig(config.ErrorTrackingConfig{URL: srv.URL}))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	tracker.Capture(errtrack.LevelError, "rejected", nil)
+	tracker.Close()
+	// Must not panic on the closed queue
+	tracker.Capture(errtrack.LevelError, "late", nil)
+
+	if len(c.bodies) != 1 {
+		t.Errorf("expected 1 delivery attempt, got %d", len(c.bodies))
+	}
+}
@@ -0,0 +1,176 @@
+package errtrack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// sentryClient identifies syncguard to Sentry
+const sentryClient = "syncguard/1.0"
+
+// sentryTags are the fields sent as Sentry tags, which can be searched and
+// grouped by; every other field is sent as extra data
+var sentryTags = []string{"module", "transition_id"}
+
+// sentryTransport sends events to Sentry's envelope endpoint
+type sentryTransport struct {
+	dsn      string
+	endpoint string
+	auth     string
+	client   *http.Client
+}
+
+// newSentryTransport derives the envelope endpoint and auth header from a
+// DSN of the form https://<key>@<host>[/<prefix>]/<project>
+func newSentryTransport(dsn string) (*sentryTransport, error) {
+	u, err := url.Parse(dsn)
+	if err != nil || u.User == nil || u.User.Username() == "" {
+		return nil, fmt.Errorf("invalid Sentry DSN")
+	}
+	project := path.Base(u.Path)
+	prefix := strings.TrimSuffix(path.Dir(u.Path), "/")
+	if project == "" || project == "." || project == "/" {
+		return nil, fmt.Errorf("invalid Sentry DSN: no project")
+	}
+
+	return &sentryTransport{
+		dsn:      dsn,
+		endpoint: fmt.Sprintf("%s://%s%s/api/%s/envelope/", u.Scheme, u.Host, prefix, project),
+		auth: fmt.Sprintf("Sentry sentry_version=7, sentry_client=%s, sentry_key=%s",
+			sentryClient, u.User.Username()),
+		client: &http.Client{},
+	}, nil
+}
+
+// sentryEvent is the subset of Sentry's event schema syncguard fills in
+type sentryEvent struct {
+	EventID     string                 `json:"event_id"`
+	Timestamp   time.Time              `json:"timestamp"`
+	Platform    string                 `json:"platform"`
+	Level       string                 `json:"level"`
+	Logger      string                 `json:"logger,omitempty"`
+	ServerName  string                 `json:"server_name"`
+	Environment string                 `json:"environment,omitempty"`
+	Message     map[string]string      `json:"message"`
+	Tags        map[string]string      `json:"tags"`
+	Extra       map[string]interface{} `json:"extra,omitempty"`
+	Exception   *sentryExceptions      `json:"exception,omitempty"`
+}
+
+type sentryExceptions struct {
+	Values []sentryException `json:"values"`
+}
+
+type sentryException struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// toSentry maps an event onto Sentry's schema: node and cluster become
+// tags, so one issue shows which validators it affects
+func toSentry(event Event) sentryEvent {
+	se := sentryEvent{
+		EventID:     event.ID,
+		Timestamp:   event.Time,
+		Platform:    "go",
+		Level:       event.Level,
+		Logger:      event.Fields["module"],
+		ServerName:  event.Node,
+		Environment: event.Environment,
+		Message:     map[string]string{"formatted": event.Message},
+		Tags:        map[string]string{"node": event.Node},
+		Extra:       map[string]interface{}{},
+	}
+	if event.Cluster != "" {
+		se.Tags["cluster"] = event.Cluster
+	}
+	for k, v := range event.Fields {
+		se.Extra[k] = v
+	}
+	for _, tag := range sentryTags {
+		if v, ok := event.Fields[tag]; ok {
+			se.Tags[tag] = v
+			delete(se.Extra, tag)
+		}
+	}
+	delete(se.Extra, "node")
+	if event.Panic {
+		se.Exception = &sentryExceptions{Values: []sentryException{{Type: "panic", Value: event.Message}}}
+		se.Extra["stack"] = event.Stack
+	}
+	return se
+}
+
+// send posts the event as a one-item envelope
+func (s *sentryTransport) send(ctx context.Context, event Event) error {
+	payload, err := json.Marshal(toSentry(event))
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+	header, _ := json.Marshal(map[string]string{
+		"event_id": event.ID,
+		"sent_at":  time.Now().UTC().Format(time.RFC3339),
+		"dsn":      s.dsn,
+	})
+	item, _ := json.Marshal(map[string]interface{}{"type": "event", "length": len(payload)})
+
+	var body bytes.Buffer
+	for _, part := range [][]byte{header, item, payload} {
+		body.Write(part)
+		body.WriteByte('\n')
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, &body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", s.auth)
+	return post(s.client, req)
+}
+
+// webhookTransport posts events as JSON, for trackers other than Sentry
+type webhookTransport struct {
+	url    string
+	client *http.Client
+}
+
+func newWebhookTransport(url string) *webhookTransport {
+	return &webhookTransport{url: url, client: &http.Client{}}
+}
+
+// send posts the event as is
+func (w *webhookTransport) send(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return post(w.client, req)
+}
+
+// post sends a request and checks for a 2xx answer
+func post(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("error tracker returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+// Package failback decides when the primary site may reclaim the active
+// role. The primary must be continuously healthy for a while and caught up
+// with the chain, and each failed attempt doubles the wait before the next.
+// In sticky mode failback only happens on operator request.
+package failback
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+)
+
+// Policy tracks the primary's stability and failed failback attempts
+type Policy struct {
+	stableFor  time.Duration
+	maxLag     int64
+	holdOff    time.Duration
+	maxHoldOff time.Duration
+	sticky     bool
+
+	mu           sync.Mutex
+	healthySince time.Time
+	failures     int
+	holdUntil    time.Time
+}
+
+// Status is a snapshot of the policy for operators
+type Status struct {
+	HealthySince time.Time `json:"healthy_since,omitempty"`
+	StableFor    float64   `json:"stable_seconds"`
+	Failures     int       `json:"failures"`
+	HoldUntil    time.Time `json:"hold_until,omitempty"`
+	// Manual is set in sticky mode, where only an operator fails back
+	Manual bool `json:"manual"`
+}
+
+// New creates a policy from the failover configuration
+func New(cfg *config.Config) *Policy {
+	return &Policy{
+		stableFor:  cfg.Failover.GracePeriod.Duration(),
+		maxLag:     cfg.Failover.FailbackMaxLag,
+		holdOff:    cfg.Failover.FailbackHoldOff.Duration(),
+		maxHoldOff: cfg.Failover.FailbackMaxHoldOff.Duration(),
+		sticky:     cfg.Failover.StickyActive,
+	}
+}
+
+// Automatic reports whether the primary fails back on its own
+func (p *Policy) Automatic() bool {
+	return !p.sticky
+}
+
+// Observe records a health check result; any failure restarts the
+// stability period
+func (p *Policy) Observe(healthy bool, now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	switch {
+	case !healthy:
+		p.healthySince = time.Time{}
+	case p.healthySince.IsZero():
+		p.healthySince = now
+	}
+}
+
+// Stable reports whether the node has been healthy long enough and is not
+// holding off after failed attempts. The reason explains a false answer.
+func (p *Policy) Stable(now time.Time) (bool, string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.healthySince.IsZero() {
+		return false, "not healthy"
+	}
+	if healthy := now.Sub(p.healthySince); healthy < p.stableFor {
+		return false, fmt.Sprintf("healthy for %s of %s", healthy.Round(time.Second), p.stableFor)
+	}
+	if now.Before(p.holdUntil) {
+		return false, fmt.Sprintf("holding off until %s after %d failed failbacks",
+			p.holdUntil.Format(time.RFC3339), p.failures)
+	}
+	return true, ""
+}
+
+// CaughtUp reports whether our height is within the allowed lag of the tip
+func (p *Policy) CaughtUp(height, tip int64) (bool, string) {
+	if lag := tip - height; lag > p.maxLag {
+		return false, fmt.Sprintf("%d blocks behind the tip, at most %d allowed", lag, p.maxLag)
+	}
+	return true, ""
+}
+
+// Failed records a failed failback and returns how long to hold off: the
+// hold-off doubles with each consecutive failure, up to the maximum
+func (p *Policy) Failed(now time.Time) time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.failures++
+	wait := p.holdOff
+	for i := 1; i < p.failures && wait < p.maxHoldOff; i++ {
+		wait *= 2
+	}
+	if wait > p.maxHoldOff {
+		wait = p.maxHoldOff
+	}
+	p.holdUntil = now.Add(wait)
+	return wait
+}
+
+// Succeeded clears the failure count after a completed failback
+func (p *Policy) Succeeded() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.failures = 0
+	p.holdUntil = time.Time{}
+}
+
+// Status returns a snapshot of the policy
+func (p *Policy) Status(now time.Time) Status {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	st := Status{HealthySince: p.healthySince, Failures: p.failures, Manual: p.sticky}
+	if !p.healthySince.IsZero() {
+		st.StableFor = now.Sub(p.healthySince).Seconds()
+	}
+	if now.Before(p.holdUntil) {
+		st.HoldUntil = p.holdUntil
+	}
+	return st
+}
@@ -0,0 +1,81 @@
+package failback
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+)
+
+func testPolicy() *Policy {
+	return New(&config.Config{Failover: config.FailoverConfig{
+		GracePeriod:        60,
+		FailbackMaxLag:     5,
+		FailbackHoldOff:    60,
+		FailbackMaxHoldOff: 300,
+	}})
+}
+
+func TestPolicy_RequiresContinuousHealth(t *testing.T) {
+	p := testPolicy()
+	start := time.Unix(1_700_000_000, 0)
+
+	p.Observe(true, start)
+	if ok, _ := p.Stable(start.Add(30 * time.Second)); ok {
+		t.Fatal("expected not stable after 30s")
+	}
+
+	// A single failed check restarts the period
+	p.Observe(false, start.Add(40*time.Second))
+	p.Observe(true, start.Add(45*time.Second))
+	if ok, _ := p.Stable(start.Add(70 * time.Second)); ok {
+		t.Fatal("expected the failure to restart the stability period")
+	}
+	if ok, reason := p.Stable(start.Add(105 * time.Second)); !ok {
+		t.Fatalf("expected stable after 60s of health: %s", reason)
+	}
+}
+
+func TestPolicy_CaughtUp(t *testing.T) {
+	p := testPolicy()
+	if ok, _ := p.CaughtUp(95, 100); !ok {
+		t.Error("expected 5 blocks behind to be allowed")
+	}
+	if ok, _ := p.CaughtUp(94, 100); ok {
+		t.Error("expected 6 blocks behind to be refused")
+	}
+}
+
+func TestPolicy_ExponentialHoldOff(t *testing.T) {
+	p := testPolicy()
+	now := time.Unix(1_700_000_000, 0)
+	p.Observe(true, now.Add(-time.Hour))
+
+	for i, want := range []time.Duration{60 * time.Second, 120 * time.Second, 240 * time.Second, 300 * time.Second, 300 * time.Second} {
+		if got := p.Failed(now); got != want {
+			t.Errorf("failure %d: hold-off %s, want %s", i+1, got, want)
+		}
+	}
+	if ok, _ := p.Stable(now.Add(299 * time.Second)); ok {
+		t.Error("expected to hold off")
+	}
+	if ok, reason := p.Stable(now.Add(300 * time.Second)); !ok {
+		t.Errorf("expected the hold-off to end: %s", reason)
+	}
+
+	p.Succeeded()
+	if got := p.Failed(now); got != 60*time.Second {
+		t.Errorf("expected the hold-off to reset after success, got %s", got)
+	}
+}
+
+func TestPolicy_Sticky(t *testing.T) {
+	if p := testPolicy(); !p.Automatic() || p.Status(time.Now()).Manual {
+		t.Fatal("expected automatic failback by default")
+	}
+
+	p := New(&config.Config{Failover: config.FailoverConfig{StickyActive: true}})
+	if p.Automatic() || !p.Status(time.Now()).Manual {
+		t.Fatal("expected sticky mode to require manual failback")
+	}
+}
@@ -0,0 +1,138 @@
+package group
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/pkg/client"
+)
+
+// Outcome of a cascade step
+const (
+	OutcomeHandedOff = "handed_off"
+	OutcomeSkipped   = "skipped"
+	OutcomeFailed    = "failed"
+	OutcomeNotRun    = "not_run"
+)
+
+// Result is what happened to one member during a cascade
+type Result struct {
+	Member  string  `json:"member"`
+	Outcome string  `json:"outcome"`
+	Seconds float64 `json:"seconds"`
+	Error   string  `json:"error,omitempty"`
+}
+
+// MemberStatus is a member's admin status, or why it could not be read
+type MemberStatus struct {
+	ID     string             `json:"id"`
+	Status *client.NodeStatus `json:"status,omitempty"`
+	Error  string             `json:"error,omitempty"`
+}
+
+// member is a linked instance and the client that reaches it
+type member struct {
+	id     string
+	node   client.Node
+	client *client.ClusterClient
+}
+
+// Group is this instance's set of linked consumer-chain instances
+type Group struct {
+	name    string
+	stopOn  bool
+	timeout time.Duration
+	members []member
+}
+
+// New builds the group from configuration; it returns nil without members
+func New(cfg *config.Config) (*Group, error) {
+	if len(cfg.Group.Members) == 0 {
+		return nil, nil
+	}
+
+	g := &Group{
+		name:    cfg.Group.Name,
+		stopOn:  cfg.Group.OnError == "stop",
+		timeout: cfg.Group.Timeout.Duration(),
+	}
+	for _, m := range cfg.Group.Members {
+		node := client.Node{ID: m.ID, URL: m.AdminURL}
+		c, err := client.New(client.Config{
+			Nodes:   []client.Node{node},
+			Token:   m.Token,
+			Timeout: g.timeout,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("group member %s: %w", m.ID, err)
+		}
+		g.members = append(g.members, member{id: m.ID, node: node, client: c})
+	}
+	return g, nil
+}
+
+// Name returns the group name
+func (g *Group) Name() string {
+	return g.name
+}
+
+// Status reads every member's admin status
+func (g *Group) Status(ctx context.Context) []MemberStatus {
+	statuses := make([]MemberStatus, 0, len(g.members))
+	for _, m := range g.members {
+		st := MemberStatus{ID: m.id}
+		status, err := m.client.NodeStatus(ctx, m.node)
+		if err != nil {
+			st.Error = err.Error()
+		} else {
+			st.Status = status
+		}
+		statuses = append(statuses, st)
+	}
+	return statuses
+}
+
+// Cascade hands off every member that is active, one at a time in the
+// configured order, so consumer chains follow the provider to the standby
+// site. Members that are already passive are skipped. With on_error
+// "stop" the first failure leaves the remaining members untouched.
+func (g *Group) Cascade(ctx context.Context) []Result {
+	results := make([]Result, 0, len(g.members))
+	failed := false
+
+	for _, m := range g.members {
+		if failed && g.stopOn {
+			results = append(results, Result{Member: m.id, Outcome: OutcomeNotRun})
+			continue
+		}
+
+		start := time.Now()
+		result := g.handOff(ctx, m)
+		result.Seconds = time.Since(start).Seconds()
+		if result.Outcome == OutcomeFailed {
+			failed = true
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// handOff hands off one member if it is active
+func (g *Group) handOff(ctx context.Context, m member) Result {
+	ctx, cancel := context.WithTimeout(ctx, g.timeout)
+	defer cancel()
+
+	status, err := m.client.NodeStatus(ctx, m.node)
+	if err != nil {
+		return Result{Member: m.id, Outcome: OutcomeFailed, Error: err.Error()}
+	}
+	if !status.Active {
+		return Result{Member: m.id, Outcome: OutcomeSkipped}
+	}
+	if _, err := m.client.Handoff(ctx); err != nil {
+		return Result{Member: m.id, Outcome: OutcomeFailed, Error: err.Error()}
+	}
+	return Result{Member: m.id, Outcome: OutcomeHandedOff}
+}
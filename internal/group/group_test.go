@@ -0,0 +1,96 @@
+package group_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/group"
+	"github.com/aldebaranode/syncguard/pkg/client"
+)
+
+// handoffLog records the order in which members were handed off
+type handoffLog struct {
+	mu    sync.Mutex
+	order []string
+}
+
+func (l *handoffLog) add(id string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.order = append(l.order, id)
+}
+
+// fakeMember serves an admin API; failHandoff makes handoffs fail
+func fakeMember(t *testing.T, id string, active, failHandoff bool, log *handoffLog) string {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case client.PathStatus:
+			json.NewEncoder(w).Encode(client.NodeStatus{NodeID: id, Active: active, Healthy: true})
+		case client.PathHandoff:
+			if failHandoff {
+				http.Error(w, "standby unhealthy", http.StatusPreconditionFailed)
+				return
+			}
+			log.add(id)
+			active = false
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func newGroup(t *testing.T, onError string, members ...config.GroupMemberConfig) *group.Group {
+	g, err := group.New(&config.Config{Group: config.GroupConfig{
+		Name: "hub", Cascade: true, OnError: onError, Timeout: 5, Members: members,
+	}})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	return g
+}
+
+func TestGroup_CascadeInOrder(t *testing.T) {
+	log := &handoffLog{}
+	g := newGroup(t, "stop",
+		config.GroupMemberConfig{ID: "neutron", AdminURL: fakeMember(t, "neutron", true, false, log)},
+		config.GroupMemberConfig{ID: "stride", AdminURL: fakeMember(t, "stride", false, false, log)},
+		config.GroupMemberConfig{ID: "noble", AdminURL: fakeMember(t, "noble", true, false, log)},
+	)
+
+	results := g.Cascade(context.Background())
+	want := []string{group.OutcomeHandedOff, group.OutcomeSkipped, group.OutcomeHandedOff}
+	for i, r := range results {
+		if r.Outcome != want[i] {
+			t.Errorf("member %s: outcome %s, want %s (%s)", r.Member, r.Outcome, want[i], r.Error)
+		}
+	}
+	if len(log.order) != 2 || log.order[0] != "neutron" || log.order[1] != "noble" {
+		t.Errorf("unexpected handoff order %v", log.order)
+	}
+}
+
+func TestGroup_CascadeStopsOnError(t *testing.T) {
+	log := &handoffLog{}
+	members := []config.GroupMemberConfig{
+		{ID: "neutron", AdminURL: fakeMember(t, "neutron", true, true, log)},
+		{ID: "noble", AdminURL: fakeMember(t, "noble", true, false, log)},
+	}
+
+	results := newGroup(t, "stop", members...).Cascade(context.Background())
+	if results[0].Outcome != group.OutcomeFailed || results[1].Outcome != group.OutcomeNotRun {
+		t.Errorf("expected failed then not_run, got %+v", results)
+	}
+	if len(log.order) != 0 {
+		t.Errorf("expected no handoff after the failure, got %v", log.order)
+	}
+
+	results = newGroup(t, "continue", members...).Cascade(context.Background())
+	if results[0].Outcome != group.OutcomeFailed || results[1].Outcome != group.OutcomeHandedOff {
+		t.Errorf("expected failed then handed_off, got %+v", results)
+	}
+}
@@ -5,10 +5,25 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/constants"
 	"github.com/aldebaranode/syncguard/internal/logger"
+	"github.com/aldebaranode/syncguard/internal/metrics"
+	"github.com/aldebaranode/syncguard/internal/policy"
+	"golang.org/x/sync/singleflight"
+)
+
+// latencySmoothing is the weight of a new sample in the latency average
+const latencySmoothing = 0.3
+
+var probeLatency = metrics.NewHistogram(
+	"syncguard_rpc_probe_latency_seconds",
+	"Latency of CometBFT RPC health probes",
+	nil,
+	"probe", "result",
 )
 
 // NodeHealth represents the health status of a CometBFT node
@@ -17,7 +32,16 @@ type NodeHealth struct {
 	IsSyncing    bool
 	LatestHeight int64
 	PeerCount    int
-	LastCheck    time.Time
+	// StatusError is why the CometBFT /status probe failed
+	StatusError string
+	// ExecutionError is why the paired execution client is unhealthy
+	ExecutionError string
+	// Command is the health command's result; nil when none is configured
+	Command *CommandResult
+	// SigningUnknown is set when /status answered without the validator
+	// key the node signs with, so whether it can sign is undeterminable
+	SigningUnknown bool
+	LastCheck      time.Time
 }
 
 // CometBFTStatus represents the response from CometBFT status endpoint
@@ -30,83 +54,275 @@ type CometBFTStatus struct {
 		NodeInfo struct {
 			Network string `json:"network"`
 			Version string `json:"version"`
+			Moniker string `json:"moniker"`
 		} `json:"node_info"`
+		ValidatorInfo struct {
+			Address string `json:"address"`
+			PubKey  struct {
+				Value string `json:"value"`
+			} `json:"pub_key"`
+		} `json:"validator_info"`
 	} `json:"result"`
 }
 
-// Checker checks the health of CometBFT nodes
+// Checker checks the health of CometBFT nodes.
+// Concurrent probes of the same kind share one RPC call, and the check
+// interval backs off while the RPC is slow so probing does not add load to
+// an already struggling node.
 type Checker struct {
 	cfg         *config.Config
 	cometRPCURL string
 	client      *http.Client
 	logger      *logger.Logger
-	lastHealth  *NodeHealth
+	flight      singleflight.Group
+
+	mu         sync.RWMutex
+	lastHealth *NodeHealth
+	prevHealth *NodeHealth
+	latency    map[string]time.Duration
+	backoff    float64
+	samples    map[string]*Ring
+	tip        int64
+
+	healthyPolicy  *policy.Expr
+	takeoverPolicy *policy.Expr
+}
+
+// statusResult is the parsed outcome of a /status probe
+type statusResult struct {
+	healthy bool
+	height  int64
+	syncing bool
+	// signingKnown is false when /status carried no validator key
+	signingKnown bool
 }
 
 // NewChecker creates a new health checker
 func NewChecker(cfg *config.Config, cometRPCURL string) *Checker {
-	newLogger := logger.NewLogger(cfg)
-	newLogger.WithModule("health")
+	newLogger := logger.New(cfg, "health")
 
-	return &Checker{
+	c := &Checker{
 		cfg:         cfg,
 		cometRPCURL: cometRPCURL,
 		client: &http.Client{
-			Timeout: time.Duration(cfg.Health.Timeout * float64(time.Second)),
+			Timeout: cfg.Health.Timeout.Duration(),
 		},
-		logger: newLogger,
+		logger:  newLogger,
+		latency: make(map[string]time.Duration),
+		backoff: 1,
+		samples: make(map[string]*Ring),
+	}
+	c.compilePolicies()
+	return c
+}
+
+// probe runs an RPC probe, sharing the call with concurrent callers of the
+// same probe and recording its latency
+func (c *Checker) probe(name string, fn func() (interface{}, error)) (interface{}, error) {
+	v, err, _ := c.flight.Do(name, func() (interface{}, error) {
+		start := time.Now()
+		v, err := fn()
+		c.recordLatency(name, time.Since(start), err)
+		return v, err
+	})
+	return v, err
+}
+
+// recordLatency updates the latency metric and smoothed average.
+// Only successful probes feed the average: a hung or dead node must still
+// be detected at the normal pace, backoff is for slow-but-alive RPCs.
+func (c *Checker) recordLatency(name string, elapsed time.Duration, err error) {
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	probeLatency.Observe(elapsed.Seconds(), name, result)
+
+	if err != nil {
+		return
+	}
+	c.recordSample(latencyCheck(name), elapsed.Seconds())
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if prev, ok := c.latency[name]; ok {
+		elapsed = time.Duration(latencySmoothing*float64(elapsed) + (1-latencySmoothing)*float64(prev))
+	}
+	c.latency[name] = elapsed
+}
+
+// adjustBackoff doubles the interval multiplier while probes are slow and
+// halves it again once they are fast
+func (c *Checker) adjustBackoff() {
+	slow := c.cfg.Health.SlowLatency.Duration()
+	if slow <= 0 || c.cfg.Health.Interval <= 0 {
+		return
+	}
+	maxFactor := float64(c.cfg.Health.MaxInterval / c.cfg.Health.Interval)
+	if maxFactor < 1 {
+		maxFactor = 1
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var worst time.Duration
+	for _, l := range c.latency {
+		if l > worst {
+			worst = l
+		}
+	}
+
+	previous := c.backoff
+	switch {
+	case worst > slow:
+		c.backoff = min(c.backoff*2, maxFactor)
+	case worst < slow/2:
+		c.backoff = max(c.backoff/2, 1)
 	}
+	if c.backoff != previous {
+		c.logger.Warn("CometBFT RPC latency %v, health check interval now %.0fx", worst, c.backoff)
+	}
+}
+
+// NextInterval returns how long to wait before the next health check
+func (c *Checker) NextInterval() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return time.Duration(float64(c.cfg.Health.Interval.Duration()) * c.backoff)
 }
 
 // CheckStatus checks the CometBFT status endpoint
 func (c *Checker) CheckStatus() (bool, int64, bool, error) {
+	result, err := c.checkStatus()
+	if err != nil {
+		return false, 0, false, err
+	}
+	return result.healthy, result.height, result.syncing, nil
+}
+
+// checkStatus runs the shared /status probe
+func (c *Checker) checkStatus() (statusResult, error) {
+	v, err := c.probe("status", c.queryStatus)
+	if err != nil {
+		return statusResult{}, err
+	}
+	return v.(statusResult), nil
+}
+
+// NodeStatus is the part of the node's CometBFT /status peers may see:
+// nothing that identifies the validator key or the node's network address
+type NodeStatus struct {
+	Height     int64  `json:"height"`
+	CatchingUp bool   `json:"catching_up"`
+	Network    string `json:"network"`
+	Moniker    string `json:"moniker"`
+}
+
+// NodeStatus reads the node's /status, sharing the call with concurrent
+// callers
+func (c *Checker) NodeStatus() (*NodeStatus, error) {
+	v, err := c.probe("node_status", func() (interface{}, error) {
+		status, err := c.fetchStatus()
+		if err != nil {
+			return nil, err
+		}
+		var height int64
+		fmt.Sscanf(status.Result.SyncInfo.LatestBlockHeight, "%d", &height)
+		return &NodeStatus{
+			Height:     height,
+			CatchingUp: status.Result.SyncInfo.CatchingUp,
+			Network:    status.Result.NodeInfo.Network,
+			Moniker:    status.Result.NodeInfo.Moniker,
+		}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*NodeStatus), nil
+}
+
+// SigningAddress returns the address of the key the node loaded, from its
+// /status. It bypasses the shared probe so a reload shows up at once.
+func (c *Checker) SigningAddress() (string, error) {
+	status, err := c.fetchStatus()
+	if err != nil {
+		return "", err
+	}
+	return status.Result.ValidatorInfo.Address, nil
+}
+
+// queryStatus performs the /status RPC call
+func (c *Checker) queryStatus() (interface{}, error) {
+	status, err := c.fetchStatus()
+	if err != nil {
+		return nil, err
+	}
+
+	var height int64
+	fmt.Sscanf(status.Result.SyncInfo.LatestBlockHeight, "%d", &height)
+
+	return statusResult{
+		healthy: !status.Result.SyncInfo.CatchingUp,
+		height:  height,
+		syncing: status.Result.SyncInfo.CatchingUp,
+		signingKnown: status.Result.ValidatorInfo.Address != "" &&
+			status.Result.ValidatorInfo.PubKey.Value != "",
+	}, nil
+}
+
+// fetchStatus fetches and parses the CometBFT /status
+func (c *Checker) fetchStatus() (*CometBFTStatus, error) {
 	url := fmt.Sprintf("%s/status", c.cometRPCURL)
 
 	resp, err := c.client.Get(url)
 	if err != nil {
-		return false, 0, false, fmt.Errorf("failed to query CometBFT: %w", err)
+		return nil, fmt.Errorf("failed to query CometBFT: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return false, 0, false, fmt.Errorf("CometBFT returned status %d", resp.StatusCode)
+		return nil, fmt.Errorf("CometBFT returned status %d", resp.StatusCode)
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return false, 0, false, fmt.Errorf("failed to read response: %w", err)
+		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
 	var status CometBFTStatus
 	if err := json.Unmarshal(body, &status); err != nil {
-		return false, 0, false, fmt.Errorf("failed to parse status: %w", err)
+		return nil, fmt.Errorf("failed to parse status: %w", err)
 	}
-
-	var height int64
-	fmt.Sscanf(status.Result.SyncInfo.LatestBlockHeight, "%d", &height)
-
-	healthy := !status.Result.SyncInfo.CatchingUp
-
-	return healthy, height, status.Result.SyncInfo.CatchingUp, nil
+	return &status, nil
 }
 
 // CheckPeerCount checks the number of connected peers
 func (c *Checker) CheckPeerCount() (int, error) {
+	v, err := c.probe("net_info", c.queryPeerCount)
+	if err != nil {
+		return 0, err
+	}
+	return v.(int), nil
+}
+
+// queryPeerCount performs the /net_info RPC call
+func (c *Checker) queryPeerCount() (interface{}, error) {
 	url := fmt.Sprintf("%s/net_info", c.cometRPCURL)
 
 	resp, err := c.client.Get(url)
 	if err != nil {
-		return 0, fmt.Errorf("failed to query net_info: %w", err)
+		return nil, fmt.Errorf("failed to query net_info: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return 0, fmt.Errorf("net_info returned status %d", resp.StatusCode)
+		return nil, fmt.Errorf("net_info returned status %d", resp.StatusCode)
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return 0, fmt.Errorf("failed to read response: %w", err)
+		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
 	var netInfo struct {
@@ -116,7 +332,7 @@ func (c *Checker) CheckPeerCount() (int, error) {
 	}
 
 	if err := json.Unmarshal(body, &netInfo); err != nil {
-		return 0, fmt.Errorf("failed to parse net_info: %w", err)
+		return nil, fmt.Errorf("failed to parse net_info: %w", err)
 	}
 
 	var peers int
@@ -132,14 +348,31 @@ func (c *Checker) PerformHealthCheck() (*NodeHealth, error) {
 	}
 
 	// Check CometBFT status
-	healthy, height, isSyncing, err := c.CheckStatus()
+	status, err := c.checkStatus()
 	if err != nil {
 		c.logger.Error("CometBFT health check failed: %v", err)
 		nodeHealth.Healthy = false
+		nodeHealth.StatusError = err.Error()
 	} else {
-		nodeHealth.Healthy = healthy
-		nodeHealth.LatestHeight = height
-		nodeHealth.IsSyncing = isSyncing
+		nodeHealth.Healthy = status.healthy
+		nodeHealth.LatestHeight = status.height
+		nodeHealth.IsSyncing = status.syncing
+		nodeHealth.SigningUnknown = !status.signingKnown
+	}
+
+	if c.executionEnabled() {
+		if err := c.CheckExecution(); err != nil {
+			c.logger.Error("Execution client health check failed: %v", err)
+			nodeHealth.ExecutionError = err.Error()
+		}
+	}
+
+	if c.commandEnabled() {
+		nodeHealth.Command = c.RunCommand()
+		if !nodeHealth.Command.Passed() {
+			c.logger.Error("Health command failed (exit %d): %s %s",
+				nodeHealth.Command.ExitCode, nodeHealth.Command.Error, nodeHealth.Command.Output)
+		}
 	}
 
 	// Check peer count
@@ -148,6 +381,7 @@ func (c *Checker) PerformHealthCheck() (*NodeHealth, error) {
 		c.logger.Warn("Failed to get peer count: %v", err)
 	} else {
 		nodeHealth.PeerCount = peers
+		c.recordSample(peersCheck, float64(peers))
 	}
 
 	if c.cfg.Logging.Verbose {
@@ -155,28 +389,112 @@ func (c *Checker) PerformHealthCheck() (*NodeHealth, error) {
 			nodeHealth.Healthy, nodeHealth.IsSyncing, nodeHealth.LatestHeight, nodeHealth.PeerCount)
 	}
 
-	c.lastHealth = nodeHealth
+	c.adjustBackoff()
+	c.Record(nodeHealth)
 	return nodeHealth, nil
 }
 
-// IsHealthy returns true if the node is healthy and ready to sign
+// Record takes h as the latest health check result, which IsHealthy and
+// FailureReason then judge; simulations feed checks through it without
+// probing anything
+func (c *Checker) Record(h *NodeHealth) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.prevHealth = c.lastHealth
+	c.lastHealth = h
+}
+
+// FailureReason classifies the last failed check: the /status RPC did not
+// answer, the height did not advance since the previous check, the
+// signing status was unknown, or another check failed
+func (c *Checker) FailureReason() constants.Reason {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.state() == StateUnknown {
+		return constants.ReasonSigningUnknown
+	}
+	if c.lastHealth == nil || c.lastHealth.StatusError != "" {
+		return constants.ReasonRPCTimeout
+	}
+	if c.prevHealth != nil && c.prevHealth.LatestHeight > 0 &&
+		c.lastHealth.LatestHeight <= c.prevHealth.LatestHeight {
+		return constants.ReasonHeightStall
+	}
+	return constants.ReasonUnhealthy
+}
+
+// IsHealthy returns true if the node is healthy and ready to sign. An
+// unknown signing status is healthy only with health.unknown "pass".
 func (c *Checker) IsHealthy() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	switch c.state() {
+	case StateHealthy:
+		return true
+	case StateUnknown:
+		return c.cfg.Health.Unknown != "fail" && c.cfg.Health.Unknown != "hold"
+	}
+	return false
+}
+
+// State returns the verdict on the last check: unhealthy when a check
+// failed, unknown when all passed but the node's signing status could not
+// be determined, healthy otherwise
+func (c *Checker) State() State {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.state()
+}
+
+func (c *Checker) state() State {
+	if !c.passes() {
+		return StateUnhealthy
+	}
+	if c.lastHealth.SigningUnknown {
+		return StateUnknown
+	}
+	return StateHealthy
+}
+
+// passes reports whether the last check passed, ignoring the signing
+// status. health.policy.healthy, when set, replaces the built-in
+// condition; a policy that fails to evaluate counts as unhealthy.
+func (c *Checker) passes() bool {
 	if c.lastHealth == nil {
 		return false
 	}
 
-	minPeers := c.cfg.Health.MinPeers
-	if minPeers == 0 {
-		minPeers = 1
+	if c.healthyPolicy != nil {
+		ok, err := c.healthyPolicy.Eval(c.facts())
+		if err != nil {
+			c.logger.Error("Health policy: %v", err)
+			return false
+		}
+		return ok
 	}
 
 	return c.lastHealth.Healthy &&
 		!c.lastHealth.IsSyncing &&
-		c.lastHealth.PeerCount >= minPeers
+		c.lastHealth.ExecutionError == "" &&
+		(c.lastHealth.Command == nil || c.lastHealth.Command.Passed()) &&
+		c.lastHealth.PeerCount >= c.minPeers()
+}
+
+// minPeers is health.min_peers, at least 1
+func (c *Checker) minPeers() int {
+	if c.cfg.Health.MinPeers == 0 {
+		return 1
+	}
+	return c.cfg.Health.MinPeers
 }
 
 // GetLastHeight returns the last known block height
 func (c *Checker) GetLastHeight() int64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
 	if c.lastHealth == nil {
 		return 0
 	}
@@ -5,7 +5,10 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/aldebaranode/syncguard/internal/config"
 	"github.com/aldebaranode/syncguard/internal/constants"
@@ -32,6 +35,10 @@ func mockCometBFT(healthy bool, syncing bool, height int64, peers int) *httptest
 					"network": "test-network",
 					"version": "0.38.0",
 				},
+				"validator_info": map[string]interface{}{
+					"address": "A1B2C3",
+					"pub_key": map[string]interface{}{"type": "tendermint/PubKeyEd25519", "value": "c2lnbmVy"},
+				},
 			},
 		}
 
@@ -100,6 +107,43 @@ func TestChecker_HealthyNode(t *testing.T) {
 	if !checker.IsHealthy() {
 		t.Error("Checker.IsHealthy() should return true")
 	}
+	if state := checker.State(); state != health.StateHealthy {
+		t.Errorf("State() = %s, want healthy", state)
+	}
+}
+
+func TestChecker_UnknownSigning(t *testing.T) {
+	for _, tc := range []struct {
+		unknown string
+		healthy bool
+	}{
+		{"pass", true},
+		{"fail", false},
+		{"hold", false},
+	} {
+		t.Run(tc.unknown, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.Health.Unknown = tc.unknown
+			checker := health.NewChecker(cfg, "")
+
+			checker.Record(&health.NodeHealth{Healthy: true, LatestHeight: 10, PeerCount: 5, SigningUnknown: true})
+			if state := checker.State(); state != health.StateUnknown {
+				t.Errorf("State() = %s, want unknown", state)
+			}
+			if got := checker.IsHealthy(); got != tc.healthy {
+				t.Errorf("IsHealthy() = %v, want %v", got, tc.healthy)
+			}
+			if reason := checker.FailureReason(); reason != constants.ReasonSigningUnknown {
+				t.Errorf("FailureReason() = %s, want signing_unknown", reason)
+			}
+
+			// A failed check is unhealthy whether or not the signing status is known
+			checker.Record(&health.NodeHealth{Healthy: true, LatestHeight: 11, PeerCount: 1, SigningUnknown: true})
+			if state := checker.State(); state != health.StateUnhealthy {
+				t.Errorf("State() = %s, want unhealthy", state)
+			}
+		})
+	}
 }
 
 func TestChecker_SyncingNode(t *testing.T) {
@@ -175,3 +219,194 @@ func TestChecker_Unreachable(t *testing.T) {
 		t.Error("Unreachable node should not pass IsHealthy()")
 	}
 }
+
+func TestChecker_FailureReason(t *testing.T) {
+	cfg := testConfig()
+
+	unreachable := health.NewChecker(cfg, "http://localhost:99999")
+	unreachable.PerformHealthCheck()
+	if reason := unreachable.FailureReason(); reason != constants.ReasonRPCTimeout {
+		t.Errorf("expected rpc_timeout for an unreachable RPC, got %s", reason)
+	}
+
+	server := mockCometBFT(true, false, 1000, 2)
+	defer server.Close()
+	checker := health.NewChecker(cfg, server.URL)
+
+	checker.PerformHealthCheck()
+	if reason := checker.FailureReason(); reason != constants.ReasonUnhealthy {
+		t.Errorf("expected unhealthy for too few peers, got %s", reason)
+	}
+
+	// The same height on the next check is a stall
+	checker.PerformHealthCheck()
+	if reason := checker.FailureReason(); reason != constants.ReasonHeightStall {
+		t.Errorf("expected height_stall, got %s", reason)
+	}
+}
+
+func TestPinger_RateLimited(t *testing.T) {
+	var mu sync.Mutex
+	hits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits++
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	cfg := testConfig()
+	cfg.Ping = config.PingConfig{URL: server.URL, Interval: 60, Timeout: 1}
+	pinger := health.NewPinger(cfg)
+
+	pinger.Ping()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		done := hits == 1
+		mu.Unlock()
+		if done {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Second ping within the interval is suppressed
+	pinger.Ping()
+	time.Sleep(50 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if hits != 1 {
+		t.Errorf("Expected exactly 1 ping, got %d", hits)
+	}
+}
+
+func TestPinger_DisabledWithoutURL(t *testing.T) {
+	if health.NewPinger(testConfig()) != nil {
+		t.Error("NewPinger should return nil when no URL is configured")
+	}
+}
+
+// slowCometBFT serves /status and /net_info after a delay, counting calls
+func slowCometBFT(delay time.Duration, calls *atomic.Int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		time.Sleep(delay)
+		if r.URL.Path == "/net_info" {
+			fmt.Fprint(w, `{"result":{"n_peers":"5"}}`)
+			return
+		}
+		fmt.Fprint(w, `{"result":{"sync_info":{"latest_block_height":"10","catching_up":false}}}`)
+	}))
+}
+
+func TestChecker_SingleFlight(t *testing.T) {
+	var calls atomic.Int32
+	server := slowCometBFT(100*time.Millisecond, &calls)
+	defer server.Close()
+
+	checker := health.NewChecker(testConfig(), server.URL)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, height, _, err := checker.CheckStatus(); err != nil || height != 10 {
+				t.Errorf("CheckStatus = %d, %v", height, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("Expected concurrent probes to share one RPC call, got %d", got)
+	}
+}
+
+func TestChecker_AdaptiveInterval(t *testing.T) {
+	var calls atomic.Int32
+	server := slowCometBFT(60*time.Millisecond, &calls)
+	defer server.Close()
+
+	cfg := testConfig()
+	cfg.Health.Interval = 1
+	cfg.Health.SlowLatency = 0.05
+	cfg.Health.MaxInterval = 4
+	checker := health.NewChecker(cfg, server.URL)
+
+	if got := checker.NextInterval(); got != time.Second {
+		t.Fatalf("Initial interval = %v, want 1s", got)
+	}
+
+	checker.PerformHealthCheck()
+	if got := checker.NextInterval(); got != 2*time.Second {
+		t.Errorf("Interval after slow probe = %v, want 2s", got)
+	}
+
+	for i := 0; i < 3; i++ {
+		checker.PerformHealthCheck()
+	}
+	if got := checker.NextInterval(); got != 4*time.Second {
+		t.Errorf("Interval should cap at max_interval, got %v", got)
+	}
+}
+
+func TestChecker_HealthPolicy(t *testing.T) {
+	server := mockCometBFT(true, false, 1000, 2)
+	defer server.Close()
+
+	// Two peers fail the built-in min_peers of 3, but the policy accepts them
+	cfg := testConfig()
+	cfg.Health.Policy.Healthy = "peers >= 2 && height_lag < 5 && !catching_up"
+	checker := health.NewChecker(cfg, server.URL)
+
+	if _, err := checker.PerformHealthCheck(); err != nil {
+		t.Fatalf("Health check failed: %v", err)
+	}
+	if !checker.IsHealthy() {
+		t.Errorf("node should be healthy under the policy, facts %v", checker.Facts())
+	}
+
+	checker.ObserveTip(1010)
+	if checker.IsHealthy() {
+		t.Error("node 10 blocks behind the tip should fail the policy")
+	}
+}
+
+func TestChecker_TakeoverPolicy(t *testing.T) {
+	server := mockCometBFT(true, false, 1000, 5)
+	defer server.Close()
+
+	cfg := testConfig()
+	checker := health.NewChecker(cfg, server.URL)
+	if _, err := checker.PerformHealthCheck(); err != nil {
+		t.Fatalf("Health check failed: %v", err)
+	}
+	ready := health.Readiness{Score: 100, Ready: true}
+	if !checker.TakeoverAllowed(ready, true) {
+		t.Error("without a policy the readiness verdict should stand")
+	}
+
+	cfg.Health.Policy.Takeover = "ready && peers >= 10"
+	checker = health.NewChecker(cfg, server.URL)
+	if _, err := checker.PerformHealthCheck(); err != nil {
+		t.Fatalf("Health check failed: %v", err)
+	}
+	if checker.TakeoverAllowed(ready, true) {
+		t.Error("5 peers should fail a takeover policy asking for 10")
+	}
+}
+
+func TestChecker_FactsMatchConfig(t *testing.T) {
+	facts := health.NewChecker(testConfig(), "http://127.0.0.1:1").Facts()
+	if len(facts) != len(config.HealthPolicyFacts) {
+		t.Errorf("Facts has %d entries, config.HealthPolicyFacts %d", len(facts), len(config.HealthPolicyFacts))
+	}
+	for _, name := range config.HealthPolicyFacts {
+		if _, ok := facts[name]; !ok {
+			t.Errorf("fact %q is not collected", name)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package health
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// maxCommandOutput bounds the output kept from the health command
+const maxCommandOutput = 4096
+
+// CommandResult is the outcome of one run of the health command
+type CommandResult struct {
+	Command  string        `json:"command"`
+	ExitCode int           `json:"exit_code"`
+	Output   string        `json:"output,omitempty"`
+	Duration time.Duration `json:"duration"`
+	// Error is set when the command could not run or timed out
+	Error string `json:"error,omitempty"`
+}
+
+// Passed reports whether the command exited with status 0
+func (r *CommandResult) Passed() bool {
+	return r.Error == "" && r.ExitCode == 0
+}
+
+// commandEnabled reports whether a health command is configured
+func (c *Checker) commandEnabled() bool {
+	return c.cfg.Validator.HealthCmd != ""
+}
+
+// RunCommand runs the configured health command with a shell, killing it
+// after the configured timeout. Output is the combined stdout and stderr,
+// keeping the tail when it is long.
+func (c *Checker) RunCommand() *CommandResult {
+	command := c.cfg.Validator.HealthCmd
+	timeout := c.cfg.Validator.HealthCmdTimeout.Duration()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	// Kill the whole process group on timeout, not just the shell, so
+	// children holding the output pipe do not keep Run waiting
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = time.Second
+
+	start := time.Now()
+	err := cmd.Run()
+	result := &CommandResult{
+		Command:  command,
+		Duration: time.Since(start),
+		Output:   tail(strings.TrimSpace(out.String()), maxCommandOutput),
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		result.ExitCode = -1
+		result.Error = "timed out after " + timeout.String()
+	case errors.As(err, &exitErr):
+		result.ExitCode = exitErr.ExitCode()
+	case err != nil:
+		result.ExitCode = -1
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// tail returns the last n bytes of s
+func tail(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return "..." + s[len(s)-n:]
+}
@@ -0,0 +1,50 @@
+package health_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/health"
+)
+
+func TestChecker_HealthCommand(t *testing.T) {
+	comet := mockCometBFT(true, false, 1000, 5)
+	defer comet.Close()
+
+	tests := []struct {
+		name     string
+		command  string
+		timeout  config.Seconds
+		healthy  bool
+		exitCode int
+		output   string
+	}{
+		{"passes", "echo ok", 5, true, 0, "ok"},
+		{"fails", "echo 'not voting' >&2; exit 3", 5, false, 3, "not voting"},
+		{"times out", "sleep 5", 0.1, false, -1, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.Validator.HealthCmd = tt.command
+			cfg.Validator.HealthCmdTimeout = tt.timeout
+			checker := health.NewChecker(cfg, comet.URL)
+
+			nodeHealth, err := checker.PerformHealthCheck()
+			if err != nil {
+				t.Fatalf("Health check failed: %v", err)
+			}
+			if checker.IsHealthy() != tt.healthy {
+				t.Errorf("IsHealthy() = %v, want %v", checker.IsHealthy(), tt.healthy)
+			}
+			result := nodeHealth.Command
+			if result == nil {
+				t.Fatal("expected a command result")
+			}
+			if result.ExitCode != tt.exitCode || !strings.Contains(result.Output, tt.output) {
+				t.Errorf("unexpected result: %+v", result)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package health
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// executionEnabled reports whether an execution client is checked
+func (c *Checker) executionEnabled() bool {
+	return c.cfg.Execution.RPCURL != "" || c.cfg.Execution.EngineURL != ""
+}
+
+// CheckExecution checks the execution client: it must answer eth_syncing
+// with false and, when configured, accept an authenticated Engine API call
+func (c *Checker) CheckExecution() error {
+	if c.cfg.Execution.RPCURL != "" {
+		if _, err := c.probe("eth_syncing", c.queryExecutionSyncing); err != nil {
+			return err
+		}
+	}
+	if c.cfg.Execution.EngineURL != "" {
+		if _, err := c.probe("engine", c.queryEngine); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// queryExecutionSyncing performs the eth_syncing JSON-RPC call
+func (c *Checker) queryExecutionSyncing() (interface{}, error) {
+	var syncing json.RawMessage
+	if err := c.jsonRPC(c.cfg.Execution.RPCURL, "", "eth_syncing", []interface{}{}, &syncing); err != nil {
+		return nil, fmt.Errorf("execution client: %w", err)
+	}
+	// false when in sync, an object with progress otherwise
+	if strings.TrimSpace(string(syncing)) != "false" {
+		return nil, fmt.Errorf("execution client is syncing")
+	}
+	return true, nil
+}
+
+// queryEngine calls engine_exchangeCapabilities with a fresh JWT
+func (c *Checker) queryEngine() (interface{}, error) {
+	token, err := engineToken(c.cfg.Execution.JWTSecretPath, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	var capabilities []string
+	if err := c.jsonRPC(c.cfg.Execution.EngineURL, token, "engine_exchangeCapabilities",
+		[]interface{}{[]string{}}, &capabilities); err != nil {
+		return nil, fmt.Errorf("engine API: %w", err)
+	}
+	return true, nil
+}
+
+// jsonRPC performs a JSON-RPC 2.0 call, with a bearer token when set
+func (c *Checker) jsonRPC(url, token, method string, params interface{}, result interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call %s: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned status %d", method, resp.StatusCode)
+	}
+
+	var envelope struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return fmt.Errorf("failed to parse %s response: %w", method, err)
+	}
+	if envelope.Error != nil {
+		return fmt.Errorf("%s: %s", method, envelope.Error.Message)
+	}
+	return json.Unmarshal(envelope.Result, result)
+}
+
+// engineToken builds the HS256 JWT the Engine API expects, from the hex
+// secret shared with the consensus client
+func engineToken(secretPath string, now time.Time) (string, error) {
+	data, err := os.ReadFile(secretPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read JWT secret: %w", err)
+	}
+	secret, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(string(data)), "0x"))
+	if err != nil {
+		return "", fmt.Errorf("invalid JWT secret: %w", err)
+	}
+
+	enc := base64.RawURLEncoding
+	header := enc.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+	claims := enc.EncodeToString([]byte(fmt.Sprintf(`{"iat":%d}`, now.Unix())))
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(header + "." + claims))
+	return header + "." + claims + "." + enc.EncodeToString(mac.Sum(nil)), nil
+}
@@ -0,0 +1,77 @@
+package health_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aldebaranode/syncguard/internal/health"
+)
+
+// mockExecution serves eth_syncing and engine_exchangeCapabilities
+func mockExecution(syncing bool, wantAuth bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string `json:"method"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+
+		switch req.Method {
+		case "eth_syncing":
+			result := `false`
+			if syncing {
+				result = `{"currentBlock":"0x10","highestBlock":"0x20"}`
+			}
+			w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":` + result + `}`))
+		case "engine_exchangeCapabilities":
+			// header.claims.signature
+			if wantAuth && strings.Count(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), ".") != 2 {
+				http.Error(w, "missing token", http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":["engine_newPayloadV3"]}`))
+		}
+	}))
+}
+
+func TestChecker_ExecutionClient(t *testing.T) {
+	comet := mockCometBFT(true, false, 1000, 5)
+	defer comet.Close()
+
+	secret := filepath.Join(t.TempDir(), "jwt.hex")
+	os.WriteFile(secret, []byte("0x"+strings.Repeat("ab", 32)), 0600)
+
+	tests := []struct {
+		name    string
+		syncing bool
+		healthy bool
+	}{
+		{"in sync", false, true},
+		{"syncing", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			execution := mockExecution(tt.syncing, true)
+			defer execution.Close()
+
+			cfg := testConfig()
+			cfg.Execution.RPCURL = execution.URL
+			cfg.Execution.EngineURL = execution.URL
+			cfg.Execution.JWTSecretPath = secret
+			checker := health.NewChecker(cfg, comet.URL)
+
+			nodeHealth, err := checker.PerformHealthCheck()
+			if err != nil {
+				t.Fatalf("Health check failed: %v", err)
+			}
+			if checker.IsHealthy() != tt.healthy {
+				t.Errorf("IsHealthy() = %v, want %v (execution error %q)",
+					checker.IsHealthy(), tt.healthy, nodeHealth.ExecutionError)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package health
+
+import (
+	"fmt"
+	"time"
+)
+
+// Report is a node's own view of its health. The active node pushes one
+// to its passives with every health check.
+type Report struct {
+	NodeID         string    `json:"node_id"`
+	Healthy        bool      `json:"healthy"`
+	Syncing        bool      `json:"syncing"`
+	Height         int64     `json:"height"`
+	Peers          int       `json:"peers"`
+	StatusError    string    `json:"status_error,omitempty"`
+	ExecutionError string    `json:"execution_error,omitempty"`
+	Time           time.Time `json:"time"`
+	// Progress is the sender's failover countdown; nil from older nodes
+	Progress *Progress `json:"progress,omitempty"`
+	// Transitions sums up the sender's changes of role; nil from older
+	// nodes
+	Transitions *Transitions `json:"transitions,omitempty"`
+}
+
+// NewReport builds the report for a health check result
+func NewReport(nodeID string, h *NodeHealth) Report {
+	return Report{
+		NodeID:         nodeID,
+		Healthy:        h.Healthy,
+		Syncing:        h.IsSyncing,
+		Height:         h.LatestHeight,
+		Peers:          h.PeerCount,
+		StatusError:    h.StatusError,
+		ExecutionError: h.ExecutionError,
+		Time:           h.LastCheck.UTC(),
+	}
+}
+
+// Disagreement compares a node's report with the chain tip another node
+// observes. A node that reports healthy while more than maxLag blocks
+// behind the tip, or unhealthy while at the tip with a working RPC, does
+// not see itself the way its peer does. It returns "" when they agree.
+func Disagreement(report Report, tip, maxLag int64) string {
+	lag := tip - report.Height
+	switch {
+	case report.Healthy && lag > maxLag:
+		return fmt.Sprintf("reports healthy but is %d blocks behind the observed tip", lag)
+	case !report.Healthy && report.StatusError == "" && report.Height > 0 && lag <= 0:
+		return "reports unhealthy but is at the observed tip"
+	}
+	return ""
+}
+
+// HeartbeatStatus is what a node knows about the health reports exchanged
+// with its peers
+type HeartbeatStatus struct {
+	// Last is the latest report received from the active peer
+	Last       *Report   `json:"last,omitempty"`
+	ReceivedAt time.Time `json:"received_at,omitempty"`
+	Stale      bool      `json:"stale"`
+	// Restored is set while the last report is one saved before a restart
+	Restored bool `json:"restored,omitempty"`
+	// Observed is how the last report disagrees with this node's view
+	Observed string `json:"observed,omitempty"`
+	// Disputed is how a passive peer disagrees with this node's report
+	Disputed string `json:"disputed,omitempty"`
+	// PeerProgress is the peer's failover countdown, from its last report
+	// or, on the active node, from a passive's last answer
+	PeerProgress *Progress `json:"peer_progress,omitempty"`
+	// PeerTransitions sums up the peer's changes of role, from the same
+	// report or answer
+	PeerTransitions *Transitions `json:"peer_transitions,omitempty"`
+}
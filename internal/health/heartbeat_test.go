@@ -0,0 +1,54 @@
+package health_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/health"
+)
+
+func TestNewReport(t *testing.T) {
+	now := time.Now()
+	report := health.NewReport("validator-1", &health.NodeHealth{
+		Healthy:      true,
+		LatestHeight: 1200,
+		PeerCount:    7,
+		LastCheck:    now,
+	})
+
+	if report.NodeID != "validator-1" || !report.Healthy || report.Height != 1200 || report.Peers != 7 {
+		t.Errorf("unexpected report %+v", report)
+	}
+	if !report.Time.Equal(now) {
+		t.Errorf("Time = %v, want %v", report.Time, now)
+	}
+}
+
+func TestDisagreement(t *testing.T) {
+	tests := []struct {
+		name   string
+		report health.Report
+		tip    int64
+		want   string
+	}{
+		{"healthy at tip", health.Report{Healthy: true, Height: 100}, 100, ""},
+		{"healthy within lag", health.Report{Healthy: true, Height: 96}, 100, ""},
+		{"healthy but lagging", health.Report{Healthy: true, Height: 90}, 100, "10 blocks behind"},
+		{"unhealthy and lagging", health.Report{Healthy: false, Height: 90}, 100, ""},
+		{"unhealthy at tip", health.Report{Healthy: false, Height: 100}, 100, "reports unhealthy"},
+		{"unhealthy without RPC", health.Report{Healthy: false, Height: 100, StatusError: "timeout"}, 100, ""},
+		{"unhealthy without height", health.Report{Healthy: false}, 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := health.Disagreement(tt.report, tt.tip, 5)
+			if tt.want == "" && got != "" {
+				t.Errorf("expected agreement, got %q", got)
+			}
+			if tt.want != "" && !strings.Contains(got, tt.want) {
+				t.Errorf("Disagreement = %q, want it to mention %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,339 @@
+package health
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/metrics"
+)
+
+var hostGauge = metrics.NewGauge(
+	"syncguard_host_pressure",
+	"Host resource use by signal: load per CPU, memory used, swap pages per second, iowait",
+	"signal",
+)
+
+// Host signals, as named in degradations and metrics
+const (
+	HostLoad   = "host:load"
+	HostMemory = "host:memory"
+	HostSwap   = "host:swap"
+	HostIOWait = "host:iowait"
+)
+
+// sustainedSamples is how many of the latest samples must all be at a
+// limit before the host counts as exhausted, so a single spike does not
+// raise an alert
+const sustainedSamples = 3
+
+// HostCounters are the raw /proc readings behind a host sample. Swap and
+// CPU time are counters since boot; rates come from two readings.
+type HostCounters struct {
+	Time        time.Time
+	Load1       float64
+	CPUs        int
+	MemTotalKB  uint64
+	MemAvailKB  uint64
+	SwapIn      uint64
+	SwapOut     uint64
+	CPUTotal    uint64
+	CPUIOWait   uint64
+	HasMemory   bool
+	HasSwap     bool
+	HasCPUTimes bool
+}
+
+// HostUsage is the host's resource use between two readings
+type HostUsage struct {
+	Time time.Time `json:"time"`
+	// LoadPerCPU is the 1-minute load average divided by the CPU count
+	LoadPerCPU float64 `json:"load_per_cpu"`
+	// MemoryUsed is the share of memory not available to new work,
+	// page cache and reclaimable memory excluded
+	MemoryUsed float64 `json:"memory_used"`
+	// SwapRate is pages swapped in and out per second
+	SwapRate float64 `json:"swap_pages_per_second"`
+	// IOWait is the share of CPU time spent waiting for I/O
+	IOWait float64 `json:"iowait"`
+}
+
+// ReadHostCounters reads load, memory, swap and CPU time from a /proc
+// filesystem at root. Missing files leave their readings out; only a
+// missing load average is an error.
+func ReadHostCounters(root string) (HostCounters, error) {
+	c := HostCounters{Time: time.Now()}
+
+	data, err := os.ReadFile(filepath.Join(root, "loadavg"))
+	if err != nil {
+		return c, fmt.Errorf("failed to read load average: %w", err)
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return c, fmt.Errorf("empty load average")
+	}
+	if c.Load1, err = strconv.ParseFloat(fields[0], 64); err != nil {
+		return c, fmt.Errorf("invalid load average %q", fields[0])
+	}
+
+	readKeyed(filepath.Join(root, "meminfo"), func(key string, value uint64) {
+		switch key {
+		case "MemTotal:":
+			c.MemTotalKB = value
+		case "MemAvailable:":
+			c.MemAvailKB = value
+			c.HasMemory = true
+		}
+	})
+	readKeyed(filepath.Join(root, "vmstat"), func(key string, value uint64) {
+		switch key {
+		case "pswpin":
+			c.SwapIn = value
+			c.HasSwap = true
+		case "pswpout":
+			c.SwapOut = value
+		}
+	})
+	readCPUTimes(filepath.Join(root, "stat"), &c)
+	if c.CPUs == 0 {
+		c.CPUs = runtime.NumCPU()
+	}
+	return c, nil
+}
+
+// readKeyed calls fn with the first two columns of each line of a
+// "key value" file; unreadable files and lines are skipped
+func readKeyed(path string, fn func(key string, value uint64)) {
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		if value, err := strconv.ParseUint(fields[1], 10, 64); err == nil {
+			fn(fields[0], value)
+		}
+	}
+}
+
+// readCPUTimes reads the aggregate CPU times and counts the CPUs in
+// /proc/stat: user nice system idle iowait irq softirq steal
+func readCPUTimes(path string, c *HostCounters) {
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || !strings.HasPrefix(fields[0], "cpu") {
+			continue
+		}
+		if fields[0] != "cpu" {
+			c.CPUs++
+			continue
+		}
+		if len(fields) < 6 {
+			continue
+		}
+		var total uint64
+		for i, field := range fields[1:min(len(fields), 9)] {
+			value, err := strconv.ParseUint(field, 10, 64)
+			if err != nil {
+				return
+			}
+			total += value
+			if i == 4 {
+				c.CPUIOWait = value
+			}
+		}
+		c.CPUTotal = total
+		c.HasCPUTimes = true
+	}
+}
+
+// HostMonitor samples the host's resources on each health check and
+// reports those at, or trending toward, their configured limit
+type HostMonitor struct {
+	cfg   config.HostConfig
+	trend config.TrendConfig
+	root  string
+
+	mu      sync.Mutex
+	prev    *HostCounters
+	last    *HostUsage
+	samples map[string]*Ring
+}
+
+// NewHostMonitor creates a monitor reading /proc
+func NewHostMonitor(cfg *config.Config) *HostMonitor {
+	return &HostMonitor{
+		cfg:     cfg.Health.Host,
+		trend:   cfg.Health.Trend,
+		root:    "/proc",
+		samples: make(map[string]*Ring),
+	}
+}
+
+// Sample reads the host's counters and records the resulting usage
+func (m *HostMonitor) Sample() (HostUsage, error) {
+	counters, err := ReadHostCounters(m.root)
+	if err != nil {
+		return HostUsage{}, err
+	}
+	return m.Record(counters), nil
+}
+
+// Record turns a reading into usage against the previous one and keeps it
+// for Degradations. Swap and iowait need two readings, so the first only
+// records load and memory.
+func (m *HostMonitor) Record(c HostCounters) HostUsage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	usage := HostUsage{Time: c.Time}
+	if c.CPUs > 0 {
+		usage.LoadPerCPU = c.Load1 / float64(c.CPUs)
+		m.add(HostLoad, c.Time, usage.LoadPerCPU)
+	}
+	if c.HasMemory && c.MemTotalKB > 0 && c.MemAvailKB <= c.MemTotalKB {
+		usage.MemoryUsed = 1 - float64(c.MemAvailKB)/float64(c.MemTotalKB)
+		m.add(HostMemory, c.Time, usage.MemoryUsed)
+	}
+	if p := m.prev; p != nil {
+		elapsed := c.Time.Sub(p.Time).Seconds()
+		if c.HasSwap && p.HasSwap && elapsed > 0 && c.SwapIn+c.SwapOut >= p.SwapIn+p.SwapOut {
+			usage.SwapRate = float64(c.SwapIn+c.SwapOut-p.SwapIn-p.SwapOut) / elapsed
+			m.add(HostSwap, c.Time, usage.SwapRate)
+		}
+		if c.HasCPUTimes && p.HasCPUTimes && c.CPUTotal > p.CPUTotal && c.CPUIOWait >= p.CPUIOWait {
+			usage.IOWait = float64(c.CPUIOWait-p.CPUIOWait) / float64(c.CPUTotal-p.CPUTotal)
+			m.add(HostIOWait, c.Time, usage.IOWait)
+		}
+	}
+	m.prev = &c
+	m.last = &usage
+	return usage
+}
+
+// add records one sample of a signal and publishes it; caller holds m.mu
+func (m *HostMonitor) add(signal string, at time.Time, value float64) {
+	ring, ok := m.samples[signal]
+	if !ok {
+		size := m.trend.Samples
+		if size < sustainedSamples {
+			size = 30
+		}
+		ring = NewRing(size)
+		m.samples[signal] = ring
+	}
+	ring.Add(Sample{Time: at, Value: value})
+	hostGauge.Set(value, strings.TrimPrefix(signal, "host:"))
+}
+
+// Usage returns the last recorded usage; false before the first sample
+func (m *HostMonitor) Usage() (HostUsage, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.last == nil {
+		return HostUsage{}, false
+	}
+	return *m.last, true
+}
+
+// hostLimit describes how a signal is reported
+type hostLimit struct {
+	signal string
+	limit  float64
+	format func(float64) string
+	what   string
+}
+
+// Degradations reports host signals whose last few samples are all at
+// their limit, and, with health.trend.enabled, those whose fitted line
+// crosses it within health.trend.horizon. A limit of zero or less turns
+// its signal off.
+func (m *HostMonitor) Degradations() []Degradation {
+	percent := func(v float64) string { return fmt.Sprintf("%.0f%%", v*100) }
+	limits := []hostLimit{
+		{HostLoad, m.cfg.MaxLoad, func(v float64) string { return fmt.Sprintf("%.2f per CPU", v) }, "Load"},
+		{HostMemory, m.cfg.MaxMemoryUsed, percent, "Memory use"},
+		{HostSwap, m.cfg.MaxSwapRate, func(v float64) string { return fmt.Sprintf("%.0f pages/s", v) }, "Swapping"},
+		{HostIOWait, m.cfg.MaxIOWait, percent, "I/O wait"},
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var out []Degradation
+	for _, l := range limits {
+		ring, ok := m.samples[l.signal]
+		if l.limit <= 0 || !ok {
+			continue
+		}
+		samples := ring.Samples()
+		if d, ok := exhausted(l, samples); ok {
+			out = append(out, d)
+			continue
+		}
+		if !m.trend.Enabled || len(samples) < (m.trend.Samples+1)/2 {
+			continue
+		}
+		slope, current, ok := Trend(samples)
+		if !ok {
+			continue
+		}
+		predicted := current + slope*float64(m.trend.Horizon)
+		if slope > 0 && current < l.limit && predicted >= l.limit {
+			out = append(out, Degradation{
+				Check:     l.signal,
+				Message:   fmt.Sprintf("%s rising, reaches %s within %s", l.what, l.format(l.limit), m.trend.Horizon.Duration()),
+				Current:   current,
+				Predicted: predicted,
+				Threshold: l.limit,
+			})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Check < out[j].Check })
+	return out
+}
+
+// exhausted reports a signal whose latest sustainedSamples samples are all
+// at or above its limit
+func exhausted(l hostLimit, samples []Sample) (Degradation, bool) {
+	if len(samples) < sustainedSamples {
+		return Degradation{}, false
+	}
+	latest := samples[len(samples)-sustainedSamples:]
+	sum := 0.0
+	for _, s := range latest {
+		if s.Value < l.limit {
+			return Degradation{}, false
+		}
+		sum += s.Value
+	}
+	current := latest[len(latest)-1].Value
+	return Degradation{
+		Check:     l.signal,
+		Message:   fmt.Sprintf("%s at %s, limit %s", l.what, l.format(sum/sustainedSamples), l.format(l.limit)),
+		Current:   current,
+		Predicted: current,
+		Threshold: l.limit,
+	}, true
+}
@@ -0,0 +1,118 @@
+package health_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/health"
+)
+
+func TestReadHostCounters(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"loadavg": "3.20 2.10 1.50 2/512 4242\n",
+		"meminfo": "MemTotal:       16000000 kB\nMemFree:          800000 kB\nMemAvailable:    4000000 kB\n",
+		"vmstat":  "nr_free_pages 200000\npswpin 120\npswpout 80\n",
+		"stat":    "cpu  100 0 50 800 40 5 5 0 0 0\ncpu0 50 0 25 400 20 2 3 0 0 0\ncpu1 50 0 25 400 20 3 2 0 0 0\nintr 1\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c, err := health.ReadHostCounters(root)
+	if err != nil {
+		t.Fatalf("ReadHostCounters: %v", err)
+	}
+	if c.Load1 != 3.2 || c.CPUs != 2 {
+		t.Errorf("load = %v over %d CPUs, want 3.2 over 2", c.Load1, c.CPUs)
+	}
+	if !c.HasMemory || c.MemTotalKB != 16000000 || c.MemAvailKB != 4000000 {
+		t.Errorf("memory = %d of %d kB available", c.MemAvailKB, c.MemTotalKB)
+	}
+	if !c.HasSwap || c.SwapIn != 120 || c.SwapOut != 80 {
+		t.Errorf("swap = %d in, %d out", c.SwapIn, c.SwapOut)
+	}
+	if !c.HasCPUTimes || c.CPUTotal != 1000 || c.CPUIOWait != 40 {
+		t.Errorf("cpu = %d total, %d iowait", c.CPUTotal, c.CPUIOWait)
+	}
+
+	if _, err := health.ReadHostCounters(t.TempDir()); err == nil {
+		t.Error("expected an error without a load average")
+	}
+}
+
+func hostMonitor(trend bool) *health.HostMonitor {
+	cfg := &config.Config{}
+	cfg.Health.Host = config.HostConfig{Enabled: true, MaxLoad: 2, MaxMemoryUsed: 0.9, MaxSwapRate: 100, MaxIOWait: -1}
+	cfg.Health.Trend = config.TrendConfig{Enabled: trend, Samples: 10, Horizon: 300}
+	return health.NewHostMonitor(cfg)
+}
+
+func TestHostMonitor_SustainedExhaustion(t *testing.T) {
+	m := hostMonitor(false)
+	start := time.Now()
+	counters := func(i int, load float64, swapped uint64) health.HostCounters {
+		return health.HostCounters{
+			Time: start.Add(time.Duration(i) * 10 * time.Second), Load1: load, CPUs: 4,
+			MemTotalKB: 1000, MemAvailKB: 500, HasMemory: true,
+			SwapIn: swapped, HasSwap: true,
+			CPUTotal: uint64(i) * 1000, CPUIOWait: uint64(i) * 900, HasCPUTimes: true,
+		}
+	}
+
+	usage := m.Record(counters(0, 10, 0))
+	if usage.LoadPerCPU != 2.5 || usage.MemoryUsed != 0.5 {
+		t.Errorf("usage = %+v", usage)
+	}
+	m.Record(counters(1, 10, 5000))
+	if got := m.Degradations(); len(got) != 0 {
+		t.Fatalf("expected nothing after two samples, got %+v", got)
+	}
+
+	// A dip below the limit resets the run for load; swapping stays high
+	m.Record(counters(2, 4, 10000))
+	m.Record(counters(3, 10, 15000))
+	got := m.Degradations()
+	if len(got) != 1 || got[0].Check != health.HostSwap {
+		t.Fatalf("expected only swapping, got %+v", got)
+	}
+	if got[0].Current != 500 || got[0].Threshold != 100 {
+		t.Errorf("swap degradation = %+v", got[0])
+	}
+
+	m.Record(counters(4, 10, 15000))
+	m.Record(counters(5, 10, 15000))
+	got = m.Degradations()
+	if len(got) != 1 || got[0].Check != health.HostLoad {
+		t.Fatalf("expected only load once swapping stopped, got %+v", got)
+	}
+}
+
+func TestHostMonitor_TrendTowardLimit(t *testing.T) {
+	m := hostMonitor(true)
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		// Available memory falls 2% of total every 10s, from 50% used
+		m.Record(health.HostCounters{
+			Time: start.Add(time.Duration(i) * 10 * time.Second), Load1: 1, CPUs: 4,
+			MemTotalKB: 1000, MemAvailKB: uint64(500 - 20*i), HasMemory: true,
+		})
+	}
+
+	got := m.Degradations()
+	if len(got) != 1 || got[0].Check != health.HostMemory {
+		t.Fatalf("expected memory trending to its limit, got %+v", got)
+	}
+	if got[0].Current >= 0.9 || got[0].Predicted < 0.9 {
+		t.Errorf("memory degradation = %+v", got[0])
+	}
+
+	if got := hostMonitor(false); len(got.Degradations()) != 0 {
+		t.Error("expected no degradations before any sample")
+	}
+}
@@ -0,0 +1,86 @@
+package health
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/logger"
+)
+
+// Pinger reports liveness to an external dead man's switch
+// (healthchecks.io, Dead Man's Snitch, ...). The external service alerts when
+// pings stop, which catches the case where SyncGuard or its host is dead and
+// therefore cannot alert by itself.
+type Pinger struct {
+	url         string
+	minInterval time.Duration
+	client      *http.Client
+	logger      *logger.Logger
+
+	mu       sync.Mutex
+	lastPing time.Time
+	inFlight bool
+}
+
+// NewPinger creates a pinger from config, or nil when no URL is configured
+func NewPinger(cfg *config.Config) *Pinger {
+	if cfg.Ping.URL == "" {
+		return nil
+	}
+
+	newLogger := logger.New(cfg, "ping")
+
+	return &Pinger{
+		url:         cfg.Ping.URL,
+		minInterval: cfg.Ping.Interval.Duration(),
+		client: &http.Client{
+			Timeout: cfg.Ping.Timeout.Duration(),
+		},
+		logger: newLogger,
+	}
+}
+
+// Ping sends a ping in the background, at most once per interval.
+// It never blocks the monitoring loop; a slow external service only
+// delays the next ping.
+func (p *Pinger) Ping() {
+	p.mu.Lock()
+	if p.inFlight || time.Since(p.lastPing) < p.minInterval {
+		p.mu.Unlock()
+		return
+	}
+	p.inFlight = true
+	p.mu.Unlock()
+
+	go func() {
+		err := p.send()
+
+		p.mu.Lock()
+		p.inFlight = false
+		if err == nil {
+			p.lastPing = time.Now()
+		}
+		p.mu.Unlock()
+
+		if err != nil {
+			p.logger.Warn("External heartbeat ping failed: %v", err)
+		}
+	}()
+}
+
+// send performs a single ping request
+func (p *Pinger) send() error {
+	resp, err := p.client.Get(p.url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("ping endpoint returned status %d", resp.StatusCode)
+	}
+	return nil
+}
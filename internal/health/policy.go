@@ -0,0 +1,87 @@
+package health
+
+import (
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/policy"
+)
+
+// Facts are the values health.policy expressions see, named as in
+// config.HealthPolicyFacts. height_lag is how far the node trails tip, the
+// highest height seen from a peer; both are 0 while no tip is known.
+// latency is the slowest smoothed CometBFT RPC latency in seconds.
+func (c *Checker) Facts() policy.Facts {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.facts()
+}
+
+// facts builds Facts; the caller holds c.mu
+func (c *Checker) facts() policy.Facts {
+	h := c.lastHealth
+	if h == nil {
+		h = &NodeHealth{StatusError: "no health check yet"}
+	}
+	var lag int64
+	if c.tip > h.LatestHeight {
+		lag = c.tip - h.LatestHeight
+	}
+	var worst float64
+	for _, l := range c.latency {
+		worst = max(worst, l.Seconds())
+	}
+	return policy.Facts{
+		"healthy":      h.Healthy,
+		"catching_up":  h.IsSyncing,
+		"height":       float64(h.LatestHeight),
+		"tip":          float64(c.tip),
+		"height_lag":   float64(lag),
+		"peers":        float64(h.PeerCount),
+		"min_peers":    float64(c.minPeers()),
+		"rpc_ok":       h.StatusError == "",
+		"execution_ok": h.ExecutionError == "",
+		"command_ok":   h.Command == nil || h.Command.Passed(),
+		"latency":      worst,
+	}
+}
+
+// ObserveTip records the chain tip as a peer reports it, for height_lag
+func (c *Checker) ObserveTip(height int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tip = height
+}
+
+// TakeoverAllowed evaluates health.policy.takeover against the health facts
+// and the standby's readiness; without a takeover policy it is the
+// readiness verdict. A policy that fails to evaluate denies the takeover.
+func (c *Checker) TakeoverAllowed(r Readiness, running bool) bool {
+	if c.takeoverPolicy == nil {
+		return r.Ready
+	}
+	facts := c.Facts()
+	facts["running"] = running
+	facts["score"] = float64(r.Score)
+	facts["ready"] = r.Ready
+	ok, err := c.takeoverPolicy.Eval(facts)
+	if err != nil {
+		c.logger.Error("Takeover policy: %v", err)
+		return false
+	}
+	return ok
+}
+
+// compilePolicies compiles health.policy; config validation has already
+// rejected expressions that do not compile
+func (c *Checker) compilePolicies() {
+	var err error
+	if src := c.cfg.Health.Policy.Healthy; src != "" {
+		if c.healthyPolicy, err = policy.Compile(src, config.HealthPolicyFacts); err != nil {
+			c.logger.Error("Ignoring health.policy.healthy: %v", err)
+		}
+	}
+	if src := c.cfg.Health.Policy.Takeover; src != "" {
+		if c.takeoverPolicy, err = policy.Compile(src, config.TakeoverPolicyFacts); err != nil {
+			c.logger.Error("Ignoring health.policy.takeover: %v", err)
+		}
+	}
+}
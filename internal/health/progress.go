@@ -0,0 +1,33 @@
+package health
+
+import "time"
+
+// Progress shows how close a node is to acting on its health checks: the
+// consecutive failures counted against the configured thresholds, how long
+// the node has held its current role and health, and when it checks next.
+// Heartbeats carry it between peers.
+type Progress struct {
+	Role string `json:"role"`
+	// Since is when the node last changed role or health
+	Since          time.Time `json:"since"`
+	InStateSeconds float64   `json:"in_state_seconds"`
+	FailureCount   int       `json:"failure_count"`
+	// FailoverAfter is failover.retry_attempts: the consecutive failures
+	// that end an active node's duties
+	FailoverAfter int `json:"failover_after"`
+	// FailbackAfterSeconds is failover.grace_period: how long a recovered
+	// primary must stay healthy before it reclaims duties
+	FailbackAfterSeconds float64   `json:"failback_after_seconds"`
+	NextCheck            time.Time `json:"next_check,omitempty"`
+	// NextStateSync is when a passive node next pulls the validator state
+	NextStateSync *time.Time `json:"next_state_sync,omitempty"`
+}
+
+// FailuresLeft is how many more consecutive failures the node tolerates
+// before failover
+func (p *Progress) FailuresLeft() int {
+	if left := p.FailoverAfter - p.FailureCount; left > 0 {
+		return left
+	}
+	return 0
+}
@@ -0,0 +1,77 @@
+package health
+
+import (
+	"fmt"
+
+	"github.com/aldebaranode/syncguard/internal/metrics"
+)
+
+var (
+	readinessGauge = metrics.NewGauge(
+		"syncguard_standby_readiness",
+		"Readiness score of this passive node to take over, from 0 to 100",
+	)
+	syncLagGauge = metrics.NewGauge(
+		"syncguard_standby_sync_lag_blocks",
+		"Blocks this passive node's consensus client is behind the active node",
+	)
+)
+
+// Readiness scores how quickly a standby could take over: 100 when its
+// node is running, healthy and within MaxLag blocks of the active node, so
+// a takeover is only a key swap and a restart
+type Readiness struct {
+	Score   int      `json:"score"`
+	Ready   bool     `json:"ready"`
+	Height  int64    `json:"height"`
+	Tip     int64    `json:"tip,omitempty"`
+	Lag     int64    `json:"lag"`
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// ReadinessInput is what a standby knows about itself and the active node.
+// Tip is the active node's height, 0 when it is unknown.
+type ReadinessInput struct {
+	Running bool
+	Healthy bool
+	Syncing bool
+	Height  int64
+	Tip     int64
+	MaxLag  int64
+}
+
+// ScoreReadiness scores a standby. A stopped node scores 0, as taking over
+// needs a cold start; an unhealthy node, one still catching up and one
+// lagging the active node each cost part of the score.
+func ScoreReadiness(in ReadinessInput) Readiness {
+	r := Readiness{Score: 100, Height: in.Height, Tip: in.Tip}
+	if in.Tip > in.Height {
+		r.Lag = in.Tip - in.Height
+	}
+
+	if !in.Running {
+		r.Score = 0
+		r.Reasons = append(r.Reasons, "node is not running, taking over needs a cold start")
+		return r
+	}
+	if !in.Healthy {
+		r.Score -= 50
+		r.Reasons = append(r.Reasons, "node is unhealthy")
+	}
+	if in.Syncing {
+		r.Score -= 25
+		r.Reasons = append(r.Reasons, "node is catching up")
+	}
+	if r.Lag > in.MaxLag {
+		r.Score -= 25
+		r.Reasons = append(r.Reasons, fmt.Sprintf("%d blocks behind the active node", r.Lag))
+	}
+	r.Ready = len(r.Reasons) == 0
+	return r
+}
+
+// Publish exports the score and sync lag as metrics
+func (r Readiness) Publish() {
+	readinessGauge.Set(float64(r.Score))
+	syncLagGauge.Set(float64(r.Lag))
+}
@@ -0,0 +1,41 @@
+package health_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aldebaranode/syncguard/internal/health"
+)
+
+func TestScoreReadiness(t *testing.T) {
+	tests := []struct {
+		name   string
+		in     health.ReadinessInput
+		score  int
+		lag    int64
+		reason string
+	}{
+		{"synced", health.ReadinessInput{Running: true, Healthy: true, Height: 98, Tip: 100}, 100, 2, ""},
+		{"tip unknown", health.ReadinessInput{Running: true, Healthy: true, Height: 98}, 100, 0, ""},
+		{"ahead of the active", health.ReadinessInput{Running: true, Healthy: true, Height: 101, Tip: 100}, 100, 0, ""},
+		{"lagging", health.ReadinessInput{Running: true, Healthy: true, Height: 80, Tip: 100}, 75, 20, "20 blocks behind"},
+		{"catching up", health.ReadinessInput{Running: true, Healthy: false, Syncing: true, Height: 80, Tip: 100}, 0, 20, "catching up"},
+		{"unhealthy", health.ReadinessInput{Running: true, Height: 100, Tip: 100}, 50, 0, "unhealthy"},
+		{"stopped", health.ReadinessInput{Healthy: true, Height: 100, Tip: 100}, 0, 0, "cold start"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.in.MaxLag = 5
+			got := health.ScoreReadiness(tt.in)
+			if got.Score != tt.score || got.Lag != tt.lag {
+				t.Errorf("score = %d, lag = %d; want %d, %d", got.Score, got.Lag, tt.score, tt.lag)
+			}
+			if got.Ready != (tt.reason == "") {
+				t.Errorf("Ready = %v with reasons %v", got.Ready, got.Reasons)
+			}
+			if tt.reason != "" && !strings.Contains(strings.Join(got.Reasons, "; "), tt.reason) {
+				t.Errorf("reasons %v do not mention %q", got.Reasons, tt.reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package health
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/metrics"
+)
+
+// leakWindow is how many consecutive samples of goroutine growth are
+// required before a leak is suspected
+const leakWindow = 10
+
+// leakMinGrowth is the minimum goroutine increase across the leak window
+const leakMinGrowth = 20
+
+var (
+	memoryGauge     = metrics.NewGauge("syncguard_process_heap_bytes", "Heap memory in use by syncguard")
+	goroutinesGauge = metrics.NewGauge("syncguard_process_goroutines", "Goroutines running in syncguard")
+	openFDsGauge    = metrics.NewGauge("syncguard_process_open_fds", "File descriptors held by syncguard")
+)
+
+// ResourceUsage is a snapshot of the daemon's own resource consumption
+type ResourceUsage struct {
+	HeapBytes  uint64 `json:"heap_bytes"`
+	SysBytes   uint64 `json:"sys_bytes"`
+	Goroutines int    `json:"goroutines"`
+	OpenFDs    int    `json:"open_fds"` // -1 when unavailable on this platform
+}
+
+// ReadResourceUsage samples memory, goroutines and file descriptors
+func ReadResourceUsage() ResourceUsage {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	return ResourceUsage{
+		HeapBytes:  mem.HeapAlloc,
+		SysBytes:   mem.Sys,
+		Goroutines: runtime.NumGoroutine(),
+		OpenFDs:    countOpenFDs(),
+	}
+}
+
+// countOpenFDs counts entries in /proc/self/fd, or returns -1
+func countOpenFDs() int {
+	entries, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		return -1
+	}
+	// The directory handle used for reading is itself listed
+	return len(entries) - 1
+}
+
+// SelfIssue describes a resource that crossed its threshold
+type SelfIssue struct {
+	Resource string
+	Message  string
+	Value    string
+}
+
+// SelfMonitor watches syncguard's own resource usage. The failover manager
+// has to be more reliable than the node it guards, so a leaking or bloated
+// daemon is reported before it falls over.
+type SelfMonitor struct {
+	cfg     config.SelfMonitorConfig
+	history []int
+}
+
+// NewSelfMonitor creates a self monitor from config
+func NewSelfMonitor(cfg *config.Config) *SelfMonitor {
+	return &SelfMonitor{cfg: cfg.SelfMonitor}
+}
+
+// Check samples resource usage, updates metrics and returns any issues
+func (m *SelfMonitor) Check() (ResourceUsage, []SelfIssue) {
+	usage := ReadResourceUsage()
+	return usage, m.evaluate(usage)
+}
+
+// evaluate compares a sample against thresholds and recent history
+func (m *SelfMonitor) evaluate(usage ResourceUsage) []SelfIssue {
+	memoryGauge.Set(float64(usage.HeapBytes))
+	goroutinesGauge.Set(float64(usage.Goroutines))
+	if usage.OpenFDs >= 0 {
+		openFDsGauge.Set(float64(usage.OpenFDs))
+	}
+
+	var issues []SelfIssue
+
+	heap := config.Size(usage.HeapBytes)
+	if m.cfg.MaxMemory > 0 && heap > m.cfg.MaxMemory {
+		issues = append(issues, SelfIssue{
+			Resource: "memory",
+			Message:  "Heap usage above threshold",
+			Value:    fmt.Sprintf("%.0fMB > %s", float64(heap)/float64(config.Megabyte), m.cfg.MaxMemory),
+		})
+	}
+
+	if m.cfg.MaxGoroutines > 0 && usage.Goroutines > m.cfg.MaxGoroutines {
+		issues = append(issues, SelfIssue{
+			Resource: "goroutines",
+			Message:  "Goroutine count above threshold",
+			Value:    fmt.Sprintf("%d > %d", usage.Goroutines, m.cfg.MaxGoroutines),
+		})
+	}
+
+	if m.cfg.MaxOpenFDs > 0 && usage.OpenFDs > m.cfg.MaxOpenFDs {
+		issues = append(issues, SelfIssue{
+			Resource: "fds",
+			Message:  "Open file descriptors above threshold",
+			Value:    fmt.Sprintf("%d > %d", usage.OpenFDs, m.cfg.MaxOpenFDs),
+		})
+	}
+
+	if growth, leaking := m.trackGoroutines(usage.Goroutines); leaking {
+		issues = append(issues, SelfIssue{
+			Resource: "goroutines",
+			Message:  "Possible goroutine leak",
+			Value:    fmt.Sprintf("+%d over %d samples", growth, leakWindow),
+		})
+	}
+
+	return issues
+}
+
+// trackGoroutines records a sample and reports steady growth: the count
+// rose on every one of the last leakWindow samples by leakMinGrowth in total
+func (m *SelfMonitor) trackGoroutines(count int) (int, bool) {
+	m.history = append(m.history, count)
+	if len(m.history) > leakWindow+1 {
+		m.history = m.history[1:]
+	}
+	if len(m.history) <= leakWindow {
+		return 0, false
+	}
+
+	for i := 1; i < len(m.history); i++ {
+		if m.history[i] <= m.history[i-1] {
+			return 0, false
+		}
+	}
+
+	growth := m.history[len(m.history)-1] - m.history[0]
+	return growth, growth >= leakMinGrowth
+}
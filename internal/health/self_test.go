@@ -0,0 +1,45 @@
+package health_test
+
+import (
+	"testing"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/health"
+)
+
+func TestSelfMonitor_Thresholds(t *testing.T) {
+	cfg := &config.Config{SelfMonitor: config.SelfMonitorConfig{MaxGoroutines: 1}}
+	monitor := health.NewSelfMonitor(cfg)
+
+	usage, issues := monitor.Check()
+	if usage.Goroutines < 1 {
+		t.Errorf("Expected goroutine count, got %d", usage.Goroutines)
+	}
+	if len(issues) != 1 || issues[0].Resource != "goroutines" {
+		t.Errorf("Expected goroutine threshold issue, got %+v", issues)
+	}
+
+	monitor = health.NewSelfMonitor(&config.Config{})
+	if _, issues := monitor.Check(); len(issues) != 0 {
+		t.Errorf("Zero thresholds should disable checks, got %+v", issues)
+	}
+}
+
+func TestSelfMonitor_GoroutineLeak(t *testing.T) {
+	monitor := health.NewSelfMonitor(&config.Config{})
+	block := make(chan struct{})
+	defer close(block)
+
+	var leak []health.SelfIssue
+	for i := 0; i <= 10; i++ {
+		_, issues := monitor.Check()
+		leak = issues
+		for j := 0; j < 5; j++ {
+			go func() { <-block }()
+		}
+	}
+
+	if len(leak) != 1 || leak[0].Message != "Possible goroutine leak" {
+		t.Errorf("Expected leak after steady growth, got %+v", leak)
+	}
+}
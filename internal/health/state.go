@@ -0,0 +1,15 @@
+package health
+
+// State is the verdict on a health check
+type State string
+
+const (
+	StateHealthy   State = "healthy"
+	StateUnhealthy State = "unhealthy"
+	// StateUnknown: every check passed, but the RPC did not report the
+	// validator key the node signs with
+	StateUnknown State = "unknown"
+)
+
+// States lists every state, for metrics that report all of them
+var States = []State{StateHealthy, StateUnhealthy, StateUnknown}
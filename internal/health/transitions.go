@@ -0,0 +1,13 @@
+package health
+
+import "time"
+
+// Transitions sums up a node's changes of role so peers and dashboards can
+// spot churn without reading the history: the epoch counts role changes,
+// Last is when each type of transition last happened and Reasons counts
+// them by reason code. /health and heartbeats carry it.
+type Transitions struct {
+	Epoch   int                  `json:"epoch"`
+	Last    map[string]time.Time `json:"last,omitempty"`
+	Reasons map[string]int       `json:"reasons,omitempty"`
+}
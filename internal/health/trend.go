@@ -0,0 +1,162 @@
+package health
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Sample is one observation of a health check
+type Sample struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+// Ring keeps the most recent samples of one check
+type Ring struct {
+	samples []Sample
+	next    int
+	full    bool
+}
+
+// NewRing creates a ring holding up to size samples
+func NewRing(size int) *Ring {
+	return &Ring{samples: make([]Sample, size)}
+}
+
+// Add records a sample, overwriting the oldest once full
+func (r *Ring) Add(s Sample) {
+	r.samples[r.next] = s
+	r.next = (r.next + 1) % len(r.samples)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// Samples returns the samples oldest first
+func (r *Ring) Samples() []Sample {
+	if !r.full {
+		return append([]Sample(nil), r.samples[:r.next]...)
+	}
+	return append(append([]Sample(nil), r.samples[r.next:]...), r.samples[:r.next]...)
+}
+
+// Trend fits a least-squares line through the samples and returns its
+// slope per second and its value at the last sample. ok is false with
+// fewer than three samples or no time spread.
+func Trend(samples []Sample) (slope, last float64, ok bool) {
+	if len(samples) < 3 {
+		return 0, 0, false
+	}
+	t0 := samples[0].Time
+	n := float64(len(samples))
+	var sumX, sumY, sumXY, sumXX float64
+	for _, s := range samples {
+		x := s.Time.Sub(t0).Seconds()
+		sumX += x
+		sumY += s.Value
+		sumXY += x * s.Value
+		sumXX += x * x
+	}
+	denom := n*sumXX - sumX*sumX
+	if denom == 0 {
+		return 0, 0, false
+	}
+	slope = (n*sumXY - sumX*sumY) / denom
+	intercept := (sumY - slope*sumX) / n
+	lastX := samples[len(samples)-1].Time.Sub(t0).Seconds()
+	return slope, intercept + slope*lastX, true
+}
+
+// Degradation is a check that still passes but is trending toward failure
+type Degradation struct {
+	Check     string  `json:"check"`
+	Message   string  `json:"message"`
+	Current   float64 `json:"current"`
+	Predicted float64 `json:"predicted"`
+	Threshold float64 `json:"threshold"`
+}
+
+// peersCheck names the peer count samples
+const peersCheck = "peers"
+
+// latencyCheck names the latency samples of a probe
+func latencyCheck(probe string) string {
+	return "latency:" + probe
+}
+
+// recordSample adds a sample for a check when trends are enabled
+func (c *Checker) recordSample(check string, value float64) {
+	if !c.cfg.Health.Trend.Enabled {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ring, ok := c.samples[check]
+	if !ok {
+		ring = NewRing(c.cfg.Health.Trend.Samples)
+		c.samples[check] = ring
+	}
+	ring.Add(Sample{Time: time.Now(), Value: value})
+}
+
+// Samples returns the recent samples of every check, oldest first
+func (c *Checker) Samples() map[string][]Sample {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	out := make(map[string][]Sample, len(c.samples))
+	for check, ring := range c.samples {
+		out[check] = ring.Samples()
+	}
+	return out
+}
+
+// Degradations reports checks whose trend crosses their failure threshold
+// within the configured horizon: RPC latency rising toward the probe
+// timeout, or the peer count falling below min_peers. At least half the
+// window must be filled before a trend is trusted.
+func (c *Checker) Degradations() []Degradation {
+	trend := c.cfg.Health.Trend
+	if !trend.Enabled {
+		return nil
+	}
+
+	var out []Degradation
+	for check, samples := range c.Samples() {
+		if len(samples) < (trend.Samples+1)/2 {
+			continue
+		}
+		slope, current, ok := Trend(samples)
+		if !ok {
+			continue
+		}
+		predicted := current + slope*float64(trend.Horizon)
+
+		if check == peersCheck {
+			threshold := float64(c.cfg.Health.MinPeers)
+			if slope < 0 && current >= threshold && predicted < threshold {
+				out = append(out, Degradation{
+					Check:     check,
+					Message:   fmt.Sprintf("Peer count falling, below %d within %.0fs", c.cfg.Health.MinPeers, trend.Horizon),
+					Current:   current,
+					Predicted: predicted,
+					Threshold: threshold,
+				})
+			}
+			continue
+		}
+
+		threshold := float64(c.cfg.Health.Timeout)
+		if slope > 0 && current < threshold && predicted >= threshold {
+			out = append(out, Degradation{
+				Check:     check,
+				Message:   fmt.Sprintf("RPC latency rising, reaches the %.0fs timeout within %.0fs", threshold, trend.Horizon),
+				Current:   current,
+				Predicted: predicted,
+				Threshold: threshold,
+			})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Check < out[j].Check })
+	return out
+}
@@ -0,0 +1,98 @@
+package health_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/health"
+)
+
+func TestRing_Wraps(t *testing.T) {
+	r := health.NewRing(3)
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		r.Add(health.Sample{Time: start.Add(time.Duration(i) * time.Second), Value: float64(i)})
+	}
+
+	samples := r.Samples()
+	if len(samples) != 3 {
+		t.Fatalf("expected 3 samples, got %d", len(samples))
+	}
+	for i, want := range []float64{2, 3, 4} {
+		if samples[i].Value != want {
+			t.Errorf("samples[%d] = %v, want %v", i, samples[i].Value, want)
+		}
+	}
+}
+
+func TestTrend(t *testing.T) {
+	start := time.Now()
+	var samples []health.Sample
+	for i := 0; i < 10; i++ {
+		samples = append(samples, health.Sample{Time: start.Add(time.Duration(i) * 10 * time.Second), Value: 0.1 + 0.01*float64(i)})
+	}
+
+	slope, last, ok := health.Trend(samples)
+	if !ok {
+		t.Fatal("expected a trend")
+	}
+	if slope < 0.00099 || slope > 0.00101 || last < 0.189 || last > 0.191 {
+		t.Errorf("slope = %v, last = %v", slope, last)
+	}
+
+	if _, _, ok := health.Trend(samples[:2]); ok {
+		t.Error("expected no trend from two samples")
+	}
+}
+
+func TestChecker_Degradations(t *testing.T) {
+	// Peer count drops by one on every check
+	var peers int64 = 12
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"result":{"sync_info":{"latest_block_height":"100","catching_up":false}}}`)
+	})
+	mux.HandleFunc("/net_info", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"result":{"n_peers":"%d"}}`, atomic.AddInt64(&peers, -1))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	cfg := testConfig()
+	cfg.Health.Trend.Samples = 6
+	cfg.Health.Trend.Horizon = 300
+	checker := health.NewChecker(cfg, server.URL)
+
+	// Disabled by default
+	checker.PerformHealthCheck()
+	if d := checker.Degradations(); len(d) != 0 {
+		t.Fatalf("expected no degradations while disabled, got %+v", d)
+	}
+
+	cfg.Health.Trend.Enabled = true
+	for i := 0; i < 5; i++ {
+		checker.PerformHealthCheck()
+		time.Sleep(5 * time.Millisecond)
+	}
+	if !checker.IsHealthy() {
+		t.Fatal("expected the node to still be healthy")
+	}
+
+	degradations := checker.Degradations()
+	found := false
+	for _, d := range degradations {
+		if d.Check == "peers" {
+			found = true
+			if d.Predicted >= d.Threshold || d.Current < d.Threshold {
+				t.Errorf("unexpected degradation: %+v", d)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("expected falling peer count to be reported, got %+v", degradations)
+	}
+}
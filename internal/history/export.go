@@ -0,0 +1,106 @@
+package history
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ExportOptions selects which entries are exported and how
+type ExportOptions struct {
+	// Format is "jsonl" or "csv"
+	Format string
+	// Since drops entries older than this time
+	Since time.Time
+	// Kinds limits the export to these kinds; empty exports all
+	Kinds []Kind
+}
+
+// csvHeader lists the CSV columns; fields are a JSON object
+var csvHeader = []string{"time", "kind", "type", "node_id", "severity", "message", "fields", "reason"}
+
+// Export streams matching entries to w, one at a time, so arbitrarily
+// large ranges are written without loading the history into memory.
+// It returns the number of entries written.
+func Export(store *Store, w io.Writer, opts ExportOptions) (int, error) {
+	kinds := make(map[Kind]bool, len(opts.Kinds))
+	for _, k := range opts.Kinds {
+		kinds[k] = true
+	}
+
+	buf := bufio.NewWriter(w)
+	var write func(Entry) error
+	flush := buf.Flush
+
+	switch opts.Format {
+	case "", "jsonl":
+		enc := json.NewEncoder(buf)
+		write = func(e Entry) error { return enc.Encode(e) }
+	case "csv":
+		cw := csv.NewWriter(buf)
+		if err := cw.Write(csvHeader); err != nil {
+			return 0, err
+		}
+		write = func(e Entry) error {
+			fields := ""
+			if len(e.Fields) > 0 {
+				data, err := json.Marshal(e.Fields)
+				if err != nil {
+					return err
+				}
+				fields = string(data)
+			}
+			cw.Write([]string{
+				e.Time.UTC().Format(time.RFC3339Nano),
+				string(e.Kind), e.Type, e.NodeID, e.Severity, e.Message, fields, e.Reason,
+			})
+			return cw.Error()
+		}
+		flush = func() error {
+			cw.Flush()
+			if err := cw.Error(); err != nil {
+				return err
+			}
+			return buf.Flush()
+		}
+	default:
+		return 0, fmt.Errorf("unknown export format %q (accepted: jsonl, csv)", opts.Format)
+	}
+
+	count := 0
+	err := store.Read(opts.Since, func(e Entry) error {
+		if len(kinds) > 0 && !kinds[e.Kind] {
+			return nil
+		}
+		if err := write(e); err != nil {
+			return fmt.Errorf("failed to write entry: %w", err)
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		return count, err
+	}
+	return count, flush()
+}
+
+// ParseSince parses an export start: an RFC 3339 time, a date
+// (2006-01-02, UTC) or a duration back from now (72h). Empty means all.
+func ParseSince(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q (use RFC 3339, YYYY-MM-DD or a duration like 72h)", value)
+}
@@ -0,0 +1,145 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Kind classifies history entries
+type Kind string
+
+const (
+	// KindEvent is anything that was alerted on
+	KindEvent Kind = "event"
+	// KindDecision records why the failover manager acted (or did not)
+	KindDecision Kind = "decision"
+	// KindAudit records operator actions
+	KindAudit Kind = "audit"
+)
+
+// Entry is one line of the history file
+type Entry struct {
+	Time     time.Time         `json:"time"`
+	Kind     Kind              `json:"kind"`
+	Type     string            `json:"type"`
+	NodeID   string            `json:"node_id"`
+	Severity string            `json:"severity,omitempty"`
+	Message  string            `json:"message"`
+	Reason   string            `json:"reason,omitempty"`
+	Fields   map[string]string `json:"fields,omitempty"`
+}
+
+// Store is an append-only JSON Lines history file.
+// When the file grows past maxSize it is rotated to <path>.1, keeping one
+// previous generation; readers see both in chronological order.
+type Store struct {
+	path    string
+	maxSize int64
+	mu      sync.Mutex
+}
+
+// NewStore creates a store at path; maxSize of 0 disables rotation
+func NewStore(path string, maxSize int64) *Store {
+	return &Store{path: path, maxSize: maxSize}
+}
+
+// Path returns the current history file
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Append writes an entry, stamping the time if unset
+func (s *Store) Append(entry Entry) error {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now().UTC()
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal history entry: %w", err)
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+	if err := s.rotateLocked(); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(s.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open history: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(line); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return nil
+}
+
+// rotateLocked moves a full history file aside; caller holds s.mu
+func (s *Store) rotateLocked() error {
+	if s.maxSize <= 0 {
+		return nil
+	}
+	info, err := os.Stat(s.path)
+	if err != nil || info.Size() < s.maxSize {
+		return nil
+	}
+	if err := os.Rename(s.path, s.path+".1"); err != nil {
+		return fmt.Errorf("failed to rotate history: %w", err)
+	}
+	return nil
+}
+
+// Read streams entries at or after since, oldest first.
+// Malformed lines (e.g. a torn write after a crash) are skipped.
+func (s *Store) Read(since time.Time, fn func(Entry) error) error {
+	for _, path := range []string{s.path + ".1", s.path} {
+		if err := readFile(path, since, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readFile streams matching entries from one history file
+func readFile(path string, since time.Time, fn func(Entry) error) error {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open history: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		if entry.Time.Before(since) {
+			continue
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read history: %w", err)
+	}
+	return nil
+}
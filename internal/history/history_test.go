@@ -0,0 +1,133 @@
+package history
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStore_AppendRead(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "history.jsonl"), 0)
+
+	start := time.Now().UTC()
+	for _, typ := range []string{"failover", "failback"} {
+		if err := store.Append(Entry{Kind: KindEvent, Type: typ, NodeID: "node-a"}); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+
+	var got []string
+	if err := store.Read(time.Time{}, func(e Entry) error {
+		got = append(got, e.Type)
+		return nil
+	}); err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if len(got) != 2 || got[0] != "failover" || got[1] != "failback" {
+		t.Errorf("Read = %v", got)
+	}
+
+	count := 0
+	store.Read(start.Add(time.Hour), func(Entry) error { count++; return nil })
+	if count != 0 {
+		t.Errorf("Expected no entries after since, got %d", count)
+	}
+}
+
+func TestStore_RotationAndTornLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	store := NewStore(path, 100)
+
+	for i := 0; i < 3; i++ {
+		if err := store.Append(Entry{Kind: KindAudit, Type: "drain", Message: "operator drained node"}); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+	if _, err := os.Stat(path + ".1"); err != nil {
+		t.Fatalf("Expected rotated file: %v", err)
+	}
+
+	f, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	f.WriteString(`{"time":"2024-`)
+	f.Close()
+
+	count := 0
+	if err := store.Read(time.Time{}, func(Entry) error { count++; return nil }); err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if count < 2 {
+		t.Errorf("Expected entries from both generations, got %d", count)
+	}
+}
+
+func TestExport(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "history.jsonl"), 0)
+	store.Append(Entry{Kind: KindEvent, Type: "failover", NodeID: "node-a", Severity: "critical", Message: "Failover, complete",
+		Reason: "height_stall"})
+	store.Append(Entry{Kind: KindDecision, Type: "drill", NodeID: "node-a", Message: "Drill completed",
+		Fields: map[string]string{"handoff_seconds": "4.200"}})
+	store.Append(Entry{Kind: KindAudit, Type: "revoke", NodeID: "node-a", Message: "Revoked node-c"})
+
+	var out bytes.Buffer
+	n, err := Export(store, &out, ExportOptions{Format: "csv", Kinds: []Kind{KindEvent, KindDecision}})
+	if err != nil {
+		t.Fatalf("Export csv: %v", err)
+	}
+	records, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatalf("Exported CSV is invalid: %v", err)
+	}
+	if n != 2 || len(records) != 3 {
+		t.Fatalf("Expected header + 2 rows, got %d rows (n=%d)", len(records), n)
+	}
+	if records[1][5] != "Failover, complete" || records[1][7] != "height_stall" ||
+		records[2][6] != `{"handoff_seconds":"4.200"}` {
+		t.Errorf("Unexpected CSV rows: %v", records[1:])
+	}
+
+	out.Reset()
+	n, err = Export(store, &out, ExportOptions{Format: "jsonl"})
+	if err != nil {
+		t.Fatalf("Export jsonl: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if n != 3 || len(lines) != 3 {
+		t.Fatalf("Expected 3 JSON lines, got %d (n=%d)", len(lines), n)
+	}
+	var last Entry
+	if err := json.Unmarshal([]byte(lines[2]), &last); err != nil || last.Kind != KindAudit {
+		t.Errorf("Last line = %+v, %v", last, err)
+	}
+
+	if _, err := Export(store, &out, ExportOptions{Format: "xml"}); err == nil {
+		t.Error("Unknown format should fail")
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"", time.Time{}},
+		{"2026-10-01T08:00:00Z", time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)},
+		{"2026-10-01", time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)},
+		{"72h", now.Add(-72 * time.Hour)},
+	}
+	for _, tt := range tests {
+		got, err := ParseSince(tt.value, now)
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("ParseSince(%q) = %v, %v; want %v", tt.value, got, err, tt.want)
+		}
+	}
+	if _, err := ParseSince("last week", now); err == nil {
+		t.Error("ParseSince should reject free text")
+	}
+}
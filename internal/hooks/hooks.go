@@ -0,0 +1,153 @@
+// Package hooks runs the operator's executables before and after a change
+// of active role, e.g. to flip DNS, firewall rules or sentries along with
+// the key. Hooks learn about the transition from environment variables.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+)
+
+// Phases a hook runs in
+const (
+	PhasePre  = "pre"
+	PhasePost = "post"
+)
+
+// maxOutput bounds the output kept from a hook
+const maxOutput = 4096
+
+// Transition describes the change of role hooks run around
+type Transition struct {
+	Phase string
+	// Event is "failover" or "failback"
+	Event string
+	// Kind is the transition kind, "acquire" or "release"
+	Kind   string
+	Reason string
+	Source string
+	NodeID string
+	Peers  []string
+	// Active is the node's role when the hook runs
+	Active bool
+	// Err is the transition's error, for post hooks
+	Err error
+}
+
+// Env returns the variables passed to each hook
+func (t Transition) Env() []string {
+	env := []string{
+		"SYNCGUARD_HOOK_PHASE=" + t.Phase,
+		"SYNCGUARD_TRANSITION=" + t.Event,
+		"SYNCGUARD_KIND=" + t.Kind,
+		"SYNCGUARD_REASON=" + t.Reason,
+		"SYNCGUARD_SOURCE=" + t.Source,
+		"SYNCGUARD_NODE_ID=" + t.NodeID,
+		"SYNCGUARD_PEERS=" + strings.Join(t.Peers, ","),
+		"SYNCGUARD_ACTIVE=" + strconv.FormatBool(t.Active),
+	}
+	if t.Phase == PhasePost {
+		if t.Err != nil {
+			env = append(env, "SYNCGUARD_RESULT=failure", "SYNCGUARD_ERROR="+t.Err.Error())
+		} else {
+			env = append(env, "SYNCGUARD_RESULT=success")
+		}
+	}
+	return env
+}
+
+// Result is the outcome of one hook
+type Result struct {
+	Name     string        `json:"name"`
+	ExitCode int           `json:"exit_code"`
+	Output   string        `json:"output,omitempty"`
+	Duration time.Duration `json:"duration"`
+	// Error is set when the hook could not run or timed out
+	Error string `json:"error,omitempty"`
+}
+
+// Passed reports whether the hook exited with status 0
+func (r *Result) Passed() bool {
+	return r.Error == "" && r.ExitCode == 0
+}
+
+// Name returns the hook's name, or its executable's base name
+func Name(h config.HookConfig) string {
+	if h.Name != "" {
+		return h.Name
+	}
+	if len(h.Command) == 0 {
+		return ""
+	}
+	return filepath.Base(h.Command[0])
+}
+
+// Run runs the hooks one after the other. A failing hook does not stop
+// the ones after it.
+func Run(hooks []config.HookConfig, t Transition) []Result {
+	env := append(os.Environ(), t.Env()...)
+	results := make([]Result, 0, len(hooks))
+	for _, h := range hooks {
+		results = append(results, run(h, env))
+	}
+	return results
+}
+
+// run executes one hook without a shell, killing its process group after
+// the hook's timeout. Output is the combined stdout and stderr, keeping
+// the tail when it is long.
+func run(h config.HookConfig, env []string) Result {
+	timeout := h.Timeout.Duration()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, h.Command[0], h.Command[1:]...)
+	cmd.Env = env
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = time.Second
+
+	start := time.Now()
+	err := cmd.Run()
+	result := Result{
+		Name:     Name(h),
+		Duration: time.Since(start),
+		Output:   tail(strings.TrimSpace(out.String()), maxOutput),
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		result.ExitCode = -1
+		result.Error = "timed out after " + timeout.String()
+	case errors.As(err, &exitErr):
+		result.ExitCode = exitErr.ExitCode()
+	case err != nil:
+		result.ExitCode = -1
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// tail returns the last n bytes of s
+func tail(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return "..." + s[len(s)-n:]
+}
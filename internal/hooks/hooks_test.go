@@ -0,0 +1,41 @@
+package hooks
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+)
+
+func TestRun_PassesTransition(t *testing.T) {
+	hooks := []config.HookConfig{
+		{Name: "env", Command: []string{"sh", "-c", `echo "$SYNCGUARD_HOOK_PHASE $SYNCGUARD_TRANSITION $SYNCGUARD_KIND $SYNCGUARD_REASON $SYNCGUARD_RESULT"`}, Timeout: 5},
+		{Command: []string{"false"}, Timeout: 5},
+		{Command: []string{"sleep", "5"}, Timeout: 1},
+		{Command: []string{"/nonexistent/hook"}, Timeout: 5},
+	}
+	results := Run(hooks, Transition{
+		Phase:  PhasePost,
+		Event:  "failover",
+		Kind:   "release",
+		Reason: "health_check_failed",
+		Err:    errors.New("key transfer failed"),
+	})
+	if len(results) != 4 {
+		t.Fatalf("got %d results, want one per hook", len(results))
+	}
+
+	if !results[0].Passed() || results[0].Output != "post failover release health_check_failed failure" {
+		t.Errorf("env hook: %+v", results[0])
+	}
+	if results[1].Passed() || results[1].ExitCode != 1 || results[1].Name != "false" {
+		t.Errorf("failing hook: %+v", results[1])
+	}
+	if results[2].Passed() || !strings.Contains(results[2].Error, "timed out") {
+		t.Errorf("slow hook: %+v", results[2])
+	}
+	if results[3].Passed() || results[3].Error == "" {
+		t.Errorf("missing hook: %+v", results[3])
+	}
+}
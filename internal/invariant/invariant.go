@@ -0,0 +1,141 @@
+// Package invariant checks what must hold across the cluster at all times,
+// whatever the transitions in flight: one node holds the real key, that
+// node is the active one and holds the lock, and no validator state file
+// moves backwards. Point-in-time checks guard each transition; these run
+// continuously and catch what slipped past them.
+package invariant
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Codes of the violations Check reports
+const (
+	// MultipleKeys: more than one node has the real key in place
+	MultipleKeys = "multiple_keys"
+	// NoKey: every node answered and none has the real key
+	NoKey = "no_key"
+	// MultipleActive: more than one node claims the active role
+	MultipleActive = "multiple_active"
+	// KeyMismatch: a node's key does not match its role
+	KeyMismatch = "key_mismatch"
+	// LockMismatch: a node's hold on a shared lock does not match its role
+	LockMismatch = "lock_mismatch"
+	// StateAhead: a passive node's state is ahead of the active node's
+	StateAhead = "state_ahead"
+	// StateRegressed: a node's state height went backwards
+	StateRegressed = "state_regressed"
+)
+
+// Node is one node's answer: its role and what it holds
+type Node struct {
+	ID          string
+	Active      bool
+	KeyPresent  bool
+	LockHeld    bool
+	StateHeight int64
+}
+
+// Violation is an invariant that does not hold and the nodes involved
+type Violation struct {
+	Code    string
+	Message string
+	Nodes   []string
+}
+
+// Checker evaluates the invariants, remembering each node's state height
+// between checks. It is not safe for concurrent use.
+type Checker struct {
+	heights map[string]int64
+}
+
+// NewChecker creates a checker that has seen no state yet
+func NewChecker() *Checker {
+	return &Checker{heights: make(map[string]int64)}
+}
+
+// Check evaluates the invariants over the nodes that answered. complete
+// tells whether every node did: only then is a missing key a violation.
+// sharedLock tells whether the lock excludes every node, so that the
+// active node must hold it.
+func (c *Checker) Check(nodes []Node, complete, sharedLock bool) []Violation {
+	var violations []Violation
+	add := func(code string, ids []string, format string, args ...any) {
+		sort.Strings(ids)
+		violations = append(violations, Violation{Code: code, Message: fmt.Sprintf(format, args...), Nodes: ids})
+	}
+
+	var withKey, active []string
+	for _, n := range nodes {
+		if n.KeyPresent {
+			withKey = append(withKey, n.ID)
+		}
+		if n.Active {
+			active = append(active, n.ID)
+		}
+	}
+	switch {
+	case len(withKey) > 1:
+		add(MultipleKeys, withKey, "The real key is in place on %s", strings.Join(withKey, ", "))
+	case len(withKey) == 0 && complete && len(nodes) > 0:
+		add(NoKey, nil, "No node has the real key in place")
+	}
+	if len(active) > 1 {
+		add(MultipleActive, active, "%s all claim the active role", strings.Join(active, ", "))
+	}
+
+	for _, n := range nodes {
+		if n.Active != n.KeyPresent {
+			add(KeyMismatch, []string{n.ID}, "%s is %s but %s", n.ID, role(n.Active), keyState(n.KeyPresent))
+		}
+		if sharedLock && n.Active != n.LockHeld {
+			add(LockMismatch, []string{n.ID}, "%s is %s but %s", n.ID, role(n.Active), lockState(n.LockHeld))
+		}
+	}
+
+	for _, a := range nodes {
+		if !a.Active {
+			continue
+		}
+		for _, p := range nodes {
+			if !p.Active && p.StateHeight > a.StateHeight {
+				add(StateAhead, []string{a.ID, p.ID}, "Passive %s has state at height %d, ahead of active %s at %d",
+					p.ID, p.StateHeight, a.ID, a.StateHeight)
+			}
+		}
+	}
+
+	for _, n := range nodes {
+		if n.StateHeight == 0 {
+			continue
+		}
+		if last := c.heights[n.ID]; n.StateHeight < last {
+			add(StateRegressed, []string{n.ID}, "%s's state went back from height %d to %d", n.ID, last, n.StateHeight)
+		}
+		c.heights[n.ID] = n.StateHeight
+	}
+	return violations
+}
+
+func role(active bool) string {
+	if active {
+		return "active"
+	}
+	return "passive"
+}
+
+func keyState(present bool) string {
+	if present {
+		return "has the real key in place"
+	}
+	return "has the mock key in place"
+}
+
+func lockState(held bool) string {
+	if held {
+		return "holds the lock"
+	}
+	return "does not hold the lock"
+}
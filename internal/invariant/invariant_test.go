@@ -0,0 +1,89 @@
+package invariant
+
+import (
+	"reflect"
+	"testing"
+)
+
+func codes(violations []Violation) []string {
+	var out []string
+	for _, v := range violations {
+		out = append(out, v.Code)
+	}
+	return out
+}
+
+func TestChecker_Check(t *testing.T) {
+	active := Node{ID: "a", Active: true, KeyPresent: true, LockHeld: true, StateHeight: 100}
+	passive := Node{ID: "b", StateHeight: 99}
+
+	tests := []struct {
+		name       string
+		nodes      []Node
+		complete   bool
+		sharedLock bool
+		want       []string
+	}{
+		{name: "consistent", nodes: []Node{active, passive}, complete: true, sharedLock: true},
+		{
+			name:  "split key",
+			nodes: []Node{active, {ID: "b", KeyPresent: true, StateHeight: 99}},
+			want:  []string{MultipleKeys, KeyMismatch},
+		},
+		{
+			name:     "no key",
+			nodes:    []Node{{ID: "a", StateHeight: 100}, passive},
+			complete: true,
+			want:     []string{NoKey},
+		},
+		{
+			name:  "no key, peer unknown",
+			nodes: []Node{{ID: "a", StateHeight: 100}},
+		},
+		{
+			name:  "two active",
+			nodes: []Node{active, {ID: "b", Active: true, KeyPresent: true, StateHeight: 100}},
+			want:  []string{MultipleKeys, MultipleActive},
+		},
+		{
+			name:       "active without the shared lock",
+			nodes:      []Node{{ID: "a", Active: true, KeyPresent: true, StateHeight: 100}, passive},
+			sharedLock: true,
+			want:       []string{LockMismatch},
+		},
+		{
+			name:  "lock ignored unless shared",
+			nodes: []Node{{ID: "a", Active: true, KeyPresent: true, StateHeight: 100}, passive},
+		},
+		{
+			name:  "passive ahead",
+			nodes: []Node{active, {ID: "b", StateHeight: 101}},
+			want:  []string{StateAhead},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := codes(NewChecker().Check(tt.nodes, tt.complete, tt.sharedLock))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Check() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChecker_StateRegressed(t *testing.T) {
+	c := NewChecker()
+	c.Check([]Node{{ID: "a", Active: true, KeyPresent: true, StateHeight: 100}}, false, false)
+
+	// A node without a readable state does not count as going back
+	if got := codes(c.Check([]Node{{ID: "a", Active: true, KeyPresent: true}}, false, false)); got != nil {
+		t.Errorf("unknown height: %v", got)
+	}
+	got := c.Check([]Node{{ID: "a", Active: true, KeyPresent: true, StateHeight: 90}}, false, false)
+	if len(got) != 1 || got[0].Code != StateRegressed || got[0].Message != "a's state went back from height 100 to 90" {
+		t.Errorf("regression: %+v", got)
+	}
+	if got := c.Check([]Node{{ID: "a", Active: true, KeyPresent: true, StateHeight: 91}}, false, false); got != nil {
+		t.Errorf("reported again once advancing: %+v", got)
+	}
+}
@@ -10,90 +10,87 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
-// Logger is a structured logger with module and caller context.
+// Logger is a leveled, structured logger. Every module logs through its own
+// child of the node's logger, created with New, which carries the node and
+// module fields; With adds more fields to a further child. A logger is never
+// modified after creation, so children can be shared between goroutines.
+//
+// Lines logged while a transition is in progress also carry its
+// correlation ID (see StartTransition), whichever module logs them.
 type Logger struct {
-	entry *log.Entry
-	cfg   *config.Config
+	entry   *log.Entry
+	verbose bool
 }
 
-// NewLogger creates a new logger instance with the provided configuration.
-func NewLogger(cfg *config.Config) *Logger {
-	logger := log.WithFields(log.Fields{
-		"node": cfg.Node.ID,
-	})
-	return &Logger{entry: logger, cfg: cfg}
+// New creates the logger for a module of this node
+func New(cfg *config.Config, module string) *Logger {
+	return &Logger{
+		entry:   log.WithFields(log.Fields{"node": cfg.Node.ID, "module": module}),
+		verbose: cfg.Logging.Verbose,
+	}
 }
 
-// WithModule adds a module field to the logger.
-func (l *Logger) WithModule(module string) {
-	l.entry = l.entry.WithFields(log.Fields{
-		"module": module,
-	})
+// WithModule returns a child logger for a sub-module
+func (l *Logger) WithModule(module string) *Logger {
+	return l.With("module", module)
 }
 
-// WithCaller adds a caller field to the logger.
-func (l *Logger) WithCaller(caller string) {
-	l.entry = l.entry.WithFields(log.Fields{
-		"v-caller": caller,
-	})
+// With returns a child logger that adds a field to every line
+func (l *Logger) With(key string, value interface{}) *Logger {
+	return &Logger{entry: l.entry.WithField(key, value), verbose: l.verbose}
 }
 
-// Info logs an info-level message with caller context.
+// Info logs an info-level message.
 func (l *Logger) Info(message string, format ...interface{}) {
-	if l.cfg.Logging.Verbose {
-		l.WithCaller(getCallerInfo(2))
-	}
-	if len(format) > 0 {
-		l.entry.Infof(message, format...)
-	} else {
-		l.entry.Info(message)
-	}
+	l.log(log.InfoLevel, message, format)
 }
 
-// Warn logs a warning-level message with caller context.
+// Warn logs a warning-level message.
 func (l *Logger) Warn(message string, format ...interface{}) {
-	if l.cfg.Logging.Verbose {
-		l.WithCaller(getCallerInfo(2))
-	}
-	if len(format) > 0 {
-		l.entry.Warnf(message, format...)
-	} else {
-		l.entry.Warn(message)
-	}
+	l.log(log.WarnLevel, message, format)
 }
 
-// Error logs an error-level message with caller context.
+// Error logs an error-level message.
 func (l *Logger) Error(message string, format ...interface{}) {
-	if l.cfg.Logging.Verbose {
-		l.WithCaller(getCallerInfo(2))
-	}
-	if len(format) > 0 {
-		l.entry.Errorf(message, format...)
-	} else {
-		l.entry.Error(message)
-	}
+	l.log(log.ErrorLevel, message, format)
 }
 
-// Debug logs a debug-level message with caller context.
+// Debug logs a debug-level message.
 func (l *Logger) Debug(message string, format ...interface{}) {
-	if l.cfg.Logging.Verbose {
-		l.WithCaller(getCallerInfo(2))
+	l.log(log.DebugLevel, message, format)
+}
+
+// log writes one line. The message is a format string only when arguments
+// are given. It must be called directly by the level methods above so the
+// caller of those is always callerDepth frames up.
+func (l *Logger) log(level log.Level, message string, format []interface{}) {
+	if !l.entry.Logger.IsLevelEnabled(level) {
+		return
+	}
+
+	entry := l.entry
+	if id := TransitionID(); id != "" {
+		entry = entry.WithField("transition_id", id)
+	}
+	if l.verbose {
+		entry = entry.WithField("caller", callerInfo(callerDepth))
 	}
 	if len(format) > 0 {
-		l.entry.Debugf(message, format...)
-	} else {
-		l.entry.Debug(message)
+		message = fmt.Sprintf(message, format...)
 	}
+	entry.Log(level, message)
 }
 
-// getCallerInfo retrieves the file, line, and function of the caller.
-func getCallerInfo(depth int) string {
+// callerDepth skips callerInfo, log and the level method
+const callerDepth = 3
+
+// callerInfo describes the file, line and function of a caller
+func callerInfo(depth int) string {
 	pc, file, line, ok := runtime.Caller(depth)
 	if !ok {
 		return "unknown:0 [unknown]"
 	}
 	fn := runtime.FuncForPC(pc).Name()
-	filename := filepath.Base(file)
-	module := strings.Split(fn, "/")
-	return fmt.Sprintf("%s:%d [%s]", filename, line, module[len(module)-1])
+	parts := strings.Split(fn, "/")
+	return fmt.Sprintf("%s:%d [%s]", filepath.Base(file), line, parts[len(parts)-1])
 }
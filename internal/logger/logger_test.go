@@ -0,0 +1,144 @@
+package logger_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/logger"
+	log "github.com/sirupsen/logrus"
+)
+
+// capture sends the global logger's output to a buffer as JSON lines
+func capture(t *testing.T, level log.Level) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	out, formatter, previous := log.StandardLogger().Out, log.StandardLogger().Formatter, log.GetLevel()
+	log.SetOutput(&buf)
+	log.SetFormatter(&log.JSONFormatter{})
+	log.SetLevel(level)
+	t.Cleanup(func() {
+		log.SetOutput(out)
+		log.SetFormatter(formatter)
+		log.SetLevel(previous)
+	})
+	return &buf
+}
+
+// lines decodes the captured JSON lines
+func lines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var out []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var fields map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &fields); err != nil {
+			t.Fatalf("invalid log line %q: %v", line, err)
+		}
+		out = append(out, fields)
+	}
+	return out
+}
+
+func testConfig(verbose bool) *config.Config {
+	return &config.Config{
+		Node:    config.NodeConfig{ID: "validator-1"},
+		Logging: config.LoggingConfig{Verbose: verbose},
+	}
+}
+
+func TestLogger_ModuleChildren(t *testing.T) {
+	buf := capture(t, log.InfoLevel)
+
+	parent := logger.New(testConfig(false), "failover")
+	child := parent.WithModule("key-state").With("peer", "validator-2")
+	parent.Info("from parent")
+	child.Info("from child %d", 2)
+
+	got := lines(t, buf)
+	if len(got) != 2 {
+		t.Fatalf("expected 2 lines, got %d: %s", len(got), buf.String())
+	}
+	if got[0]["node"] != "validator-1" || got[0]["module"] != "failover" || got[0]["peer"] != nil {
+		t.Errorf("parent line has wrong fields: %v", got[0])
+	}
+	if got[1]["module"] != "key-state" || got[1]["peer"] != "validator-2" || got[1]["msg"] != "from child 2" {
+		t.Errorf("child line has wrong fields: %v", got[1])
+	}
+}
+
+func TestLogger_Levels(t *testing.T) {
+	buf := capture(t, log.WarnLevel)
+
+	l := logger.New(testConfig(false), "health")
+	l.Debug("hidden")
+	l.Info("hidden")
+	l.Warn("shown")
+	l.Error("shown %s", "too")
+
+	got := lines(t, buf)
+	if len(got) != 2 || got[0]["level"] != "warning" || got[1]["level"] != "error" {
+		t.Errorf("unexpected lines: %s", buf.String())
+	}
+}
+
+func TestLogger_Message(t *testing.T) {
+	buf := capture(t, log.InfoLevel)
+
+	// Without arguments the message is not a format string
+	logger.New(testConfig(false), "health").Info("100% synced")
+
+	if got := lines(t, buf); len(got) != 1 || got[0]["msg"] != "100% synced" {
+		t.Errorf("unexpected lines: %s", buf.String())
+	}
+}
+
+func TestLogger_Caller(t *testing.T) {
+	buf := capture(t, log.InfoLevel)
+
+	l := logger.New(testConfig(true), "health")
+	l.Info("first")
+	l.Warn("second")
+
+	for _, line := range lines(t, buf) {
+		caller, _ := line["caller"].(string)
+		if !strings.HasPrefix(caller, "logger_test.go:") || !strings.Contains(caller, "TestLogger_Caller") {
+			t.Errorf("caller = %q, want this test", caller)
+		}
+	}
+}
+
+func TestLogger_TransitionID(t *testing.T) {
+	buf := capture(t, log.InfoLevel)
+	l := logger.New(testConfig(false), "failover")
+
+	id := logger.StartTransition()
+	if len(id) != 12 || logger.TransitionID() != id {
+		t.Fatalf("StartTransition returned %q, current %q", id, logger.TransitionID())
+	}
+	l.Info("during")
+
+	// A transition joined later is not ended by the earlier one
+	joined := logger.JoinTransition("peer-id")
+	logger.EndTransition(id)
+	if logger.TransitionID() != joined {
+		t.Errorf("TransitionID = %q, want %q", logger.TransitionID(), joined)
+	}
+	logger.EndTransition(joined)
+	l.Info("after")
+
+	got := lines(t, buf)
+	if len(got) != 2 {
+		t.Fatalf("expected 2 lines, got %s", buf.String())
+	}
+	if got[0]["transition_id"] != id {
+		t.Errorf("transition_id = %v, want %s", got[0]["transition_id"], id)
+	}
+	if _, ok := got[1]["transition_id"]; ok {
+		t.Errorf("transition_id should be gone after the transition: %v", got[1])
+	}
+}
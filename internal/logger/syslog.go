@@ -0,0 +1,344 @@
+package logger
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// syslogQueueSize bounds the lines waiting to be sent; more are dropped so
+// a slow or unreachable collector never blocks the failover loop
+const syslogQueueSize = 1000
+
+// syslogDialTimeout limits each connection attempt to the collector
+const syslogDialTimeout = 5 * time.Second
+
+// sdID names the structured data element carrying the log fields. 32473 is
+// the private enterprise number reserved for documentation (RFC 5612).
+const sdID = "syncguard@32473"
+
+// Syslog ships log lines to a syslog collector as RFC 5424 messages, one
+// UDP datagram each, or octet-counted (RFC 6587) over TCP and TLS. The
+// module becomes the MSGID, and the other fields (node, transition_id,
+// ...) structured data. Lines are queued and sent in the background; a
+// broken stream connection is dialed again for the next line.
+type Syslog struct {
+	cfg      config.SyslogConfig
+	network  string
+	address  string
+	tlsCfg   *tls.Config
+	facility int
+	levels   []log.Level
+	hostname string
+
+	queue   chan []byte
+	closeMu sync.RWMutex
+	closed  bool
+	wg      sync.WaitGroup
+
+	conn    net.Conn
+	failing bool
+}
+
+// NewSyslog creates a syslog output sending lines at level or above; an
+// empty level sends every line the logger writes
+func NewSyslog(cfg config.SyslogConfig, level string) (*Syslog, error) {
+	facility := slices.Index(config.SyslogFacilities, cfg.Facility)
+	if facility < 0 {
+		return nil, fmt.Errorf("unknown syslog facility %q", cfg.Facility)
+	}
+
+	s := &Syslog{
+		cfg:      cfg,
+		network:  cfg.Network,
+		address:  cfg.Address,
+		facility: facility,
+		levels:   log.AllLevels,
+		queue:    make(chan []byte, syslogQueueSize),
+	}
+	if level != "" {
+		min, err := log.ParseLevel(level)
+		if err != nil {
+			return nil, err
+		}
+		s.levels = nil
+		for _, l := range log.AllLevels {
+			if l <= min {
+				s.levels = append(s.levels, l)
+			}
+		}
+	}
+
+	port := "514"
+	if cfg.Network == "tls" {
+		port = "6514"
+		tlsCfg, err := syslogTLS(cfg)
+		if err != nil {
+			return nil, err
+		}
+		s.network = "tcp"
+		s.tlsCfg = tlsCfg
+	}
+	if _, _, err := net.SplitHostPort(s.address); err != nil {
+		s.address = net.JoinHostPort(s.address, port)
+	}
+
+	s.hostname, _ = os.Hostname()
+	if s.hostname == "" {
+		s.hostname = "-"
+	}
+
+	s.wg.Add(1)
+	go s.run()
+	return s, nil
+}
+
+// syslogTLS verifies the collector with ca_file, or the system roots, and
+// presents cert_file when set
+func syslogTLS(cfg config.SyslogConfig) (*tls.Config, error) {
+	host, _, err := net.SplitHostPort(cfg.Address)
+	if err != nil {
+		host = cfg.Address
+	}
+	tlsCfg := &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read syslog ca_file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("syslog ca_file %s holds no PEM certificate", cfg.CAFile)
+		}
+		tlsCfg.RootCAs = pool
+	}
+	if cfg.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load syslog client certificate: %w", err)
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+	return tlsCfg, nil
+}
+
+// Levels returns the levels that are sent
+func (s *Syslog) Levels() []log.Level {
+	return s.levels
+}
+
+// Fire queues a log line; it is dropped when the queue is full or the
+// output is closed
+func (s *Syslog) Fire(entry *log.Entry) error {
+	s.closeMu.RLock()
+	defer s.closeMu.RUnlock()
+	if s.closed {
+		return nil
+	}
+	select {
+	case s.queue <- s.format(entry):
+	default:
+	}
+	return nil
+}
+
+// Close sends the queued lines and closes the connection
+func (s *Syslog) Close() {
+	s.closeMu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.queue)
+	}
+	s.closeMu.Unlock()
+	s.wg.Wait()
+}
+
+// run sends queued lines until the output is closed
+func (s *Syslog) run() {
+	defer s.wg.Done()
+	for msg := range s.queue {
+		s.send(msg)
+	}
+	if s.conn != nil {
+		s.conn.Close()
+	}
+}
+
+// send writes one message, dialing first when there is no connection. A
+// failure drops the message. It is reported on stderr, not through the
+// logger, which would only queue another line for the same collector.
+func (s *Syslog) send(msg []byte) {
+	if s.network != "udp" {
+		msg = append([]byte(strconv.Itoa(len(msg))+" "), msg...)
+	}
+	err := s.write(msg)
+	if err != nil && s.network != "udp" && s.conn != nil {
+		// The collector may have closed an idle stream; try a new one once
+		s.conn.Close()
+		s.conn = nil
+		err = s.write(msg)
+	}
+	if err != nil {
+		if s.conn != nil {
+			s.conn.Close()
+			s.conn = nil
+		}
+		if !s.failing {
+			fmt.Fprintf(os.Stderr, "syslog output %s: %v; dropping log lines until it recovers\n", s.address, err)
+		}
+		s.failing = true
+		return
+	}
+	if s.failing {
+		fmt.Fprintf(os.Stderr, "syslog output %s recovered\n", s.address)
+	}
+	s.failing = false
+}
+
+// write dials when needed and writes msg
+func (s *Syslog) write(msg []byte) error {
+	if s.conn == nil {
+		dialer := &net.Dialer{Timeout: syslogDialTimeout}
+		var conn net.Conn
+		var err error
+		if s.tlsCfg != nil {
+			conn, err = tls.DialWithDialer(dialer, s.network, s.address, s.tlsCfg)
+		} else {
+			conn, err = dialer.Dial(s.network, s.address)
+		}
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+	}
+	s.conn.SetWriteDeadline(time.Now().Add(syslogDialTimeout))
+	_, err := s.conn.Write(msg)
+	return err
+}
+
+// format renders an entry as an RFC 5424 message:
+//
+//	<PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID [SD] MSG
+func (s *Syslog) format(entry *log.Entry) []byte {
+	msgID := "-"
+	params := make([]string, 0, len(entry.Data))
+	for k, v := range entry.Data {
+		if k == "module" {
+			msgID = headerField(fmt.Sprint(v), 32)
+			continue
+		}
+		params = append(params, fmt.Sprintf(`%s="%s"`, sdName(k), sdEscape(fmt.Sprint(v))))
+	}
+	sort.Strings(params)
+	sd := "-"
+	if len(params) > 0 {
+		sd = "[" + sdID + " " + strings.Join(params, " ") + "]"
+	}
+
+	return []byte(fmt.Sprintf("<%d>1 %s %s %s %d %s %s %s",
+		s.facility*8+syslogSeverity(entry.Level),
+		entry.Time.UTC().Format(time.RFC3339Nano),
+		headerField(s.hostname, 255),
+		headerField(s.cfg.AppName, 48),
+		os.Getpid(),
+		msgID,
+		sd,
+		entry.Message))
+}
+
+// syslogSeverity maps a log level to its RFC 5424 severity
+func syslogSeverity(level log.Level) int {
+	switch level {
+	case log.PanicLevel:
+		return 1 // alert
+	case log.FatalLevel:
+		return 2 // critical
+	case log.ErrorLevel:
+		return 3
+	case log.WarnLevel:
+		return 4
+	case log.InfoLevel:
+		return 6
+	}
+	return 7 // debug
+}
+
+// headerField makes s a valid header field: printable ASCII without
+// spaces, at most max characters, "-" when empty
+func headerField(s string, max int) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r > 32 && r < 127 {
+			b.WriteRune(r)
+		}
+	}
+	out := b.String()
+	if len(out) > max {
+		out = out[:max]
+	}
+	if out == "" {
+		return "-"
+	}
+	return out
+}
+
+// sdName makes k a valid structured data parameter name
+func sdName(k string) string {
+	var b strings.Builder
+	for _, r := range k {
+		if r > 32 && r < 127 && r != '=' && r != ']' && r != '"' {
+			b.WriteRune(r)
+		}
+	}
+	out := b.String()
+	if len(out) > 32 {
+		out = out[:32]
+	}
+	if out == "" {
+		return "_"
+	}
+	return out
+}
+
+// sdEscape escapes a structured data parameter value
+func sdEscape(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(v)
+}
+
+// StartOutputs adds the outputs configured under logging.outputs to the
+// global logger; the returned function sends what is still queued
+func StartOutputs(cfg *config.Config) (func(), error) {
+	var closers []func()
+	closeAll := func() {
+		for _, c := range closers {
+			c()
+		}
+	}
+	for i, out := range cfg.Logging.Outputs {
+		switch out.Type {
+		case "syslog":
+			s, err := NewSyslog(out.Syslog, out.Level)
+			if err != nil {
+				closeAll()
+				return nil, fmt.Errorf("logging.outputs[%d]: %w", i, err)
+			}
+			log.AddHook(s)
+			closers = append(closers, s.Close)
+		default:
+			closeAll()
+			return nil, fmt.Errorf("logging.outputs[%d]: unknown type %q", i, out.Type)
+		}
+	}
+	return closeAll, nil
+}
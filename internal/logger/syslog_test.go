@@ -0,0 +1,140 @@
+package logger_test
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/logger"
+	log "github.com/sirupsen/logrus"
+)
+
+// rfc5424 matches a message with structured data
+var rfc5424 = regexp.MustCompile(`^<(\d+)>1 \S+ \S+ syncguard \d+ (\S+) (\[.*?[^\\]\]|-) (.*)$`)
+
+// syslogLogger returns a logger whose lines go to a syslog output only
+func syslogLogger(t *testing.T, cfg config.SyslogConfig, level string) (*log.Logger, *logger.Syslog) {
+	t.Helper()
+	cfg.AppName = "syncguard"
+	if cfg.Facility == "" {
+		cfg.Facility = "local0"
+	}
+	s, err := logger.NewSyslog(cfg, level)
+	if err != nil {
+		t.Fatalf("NewSyslog: %v", err)
+	}
+	l := log.New()
+	l.SetOutput(io.Discard)
+	l.SetLevel(log.DebugLevel)
+	l.AddHook(s)
+	return l, s
+}
+
+func TestSyslog_UDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	l, s := syslogLogger(t, config.SyslogConfig{Network: "udp", Address: pc.LocalAddr().String()}, "warn")
+	l.WithFields(log.Fields{"node": "validator-1", "module": "failover", "note": `say "hi" ]`}).Info("not sent")
+	l.WithFields(log.Fields{"node": "validator-1", "module": "failover", "note": `say "hi" ]`}).Warn("Peer unreachable")
+	s.Close()
+
+	buf := make([]byte, 2048)
+	pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("no datagram received: %v", err)
+	}
+	m := rfc5424.FindStringSubmatch(string(buf[:n]))
+	if m == nil {
+		t.Fatalf("not an RFC 5424 message: %q", buf[:n])
+	}
+	if m[1] != strconv.Itoa(16*8+4) {
+		t.Errorf("PRI = %s, want local0.warning (%d)", m[1], 16*8+4)
+	}
+	if m[2] != "failover" {
+		t.Errorf("MSGID = %s, want the module", m[2])
+	}
+	if want := `[syncguard@32473 node="validator-1" note="say \"hi\" \]"]`; m[3] != want {
+		t.Errorf("structured data = %s, want %s", m[3], want)
+	}
+	if m[4] != "Peer unreachable" {
+		t.Errorf("message = %q", m[4])
+	}
+
+	pc.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	if _, _, err := pc.ReadFrom(buf); err == nil {
+		t.Error("info line should be filtered out by level warn")
+	}
+}
+
+func TestSyslog_TCPOctetCounting(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		var msgs []string
+		for {
+			length, err := r.ReadString(' ')
+			if err != nil {
+				break
+			}
+			n, _ := strconv.Atoi(strings.TrimSpace(length))
+			msg := make([]byte, n)
+			if _, err := io.ReadFull(r, msg); err != nil {
+				break
+			}
+			msgs = append(msgs, string(msg))
+		}
+		received <- msgs
+	}()
+
+	l, s := syslogLogger(t, config.SyslogConfig{Network: "tcp", Address: ln.Addr().String()}, "")
+	l.Error("first")
+	l.WithField("module", "health").Debug("second\nline")
+	s.Close()
+
+	var msgs []string
+	select {
+	case msgs = <-received:
+	case <-time.After(2 * time.Second):
+		t.Fatal("collector received nothing")
+	}
+	if len(msgs) != 2 {
+		t.Fatalf("received %d messages, want 2: %q", len(msgs), msgs)
+	}
+	if !strings.HasPrefix(msgs[0], "<131>1 ") || !strings.HasSuffix(msgs[0], " - - first") {
+		t.Errorf("first message = %q", msgs[0])
+	}
+	if !strings.HasPrefix(msgs[1], "<135>1 ") || !strings.HasSuffix(msgs[1], " health - second\nline") {
+		t.Errorf("second message = %q", msgs[1])
+	}
+}
+
+func TestNewSyslog_Invalid(t *testing.T) {
+	if _, err := logger.NewSyslog(config.SyslogConfig{Network: "udp", Address: "127.0.0.1", Facility: "nope"}, ""); err == nil {
+		t.Error("unknown facility should fail")
+	}
+	if _, err := logger.NewSyslog(config.SyslogConfig{Network: "tls", Address: "127.0.0.1", Facility: "daemon", CAFile: "/nonexistent"}, ""); err == nil {
+		t.Error("missing ca_file should fail")
+	}
+}
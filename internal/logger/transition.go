@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+)
+
+// A transition (failover, failback, takeover or release) spans several
+// modules and both nodes. While one is in progress every log line carries
+// its correlation ID, and peer requests pass the ID on so the peer's lines
+// carry it too. Only one transition runs at a time on a node.
+var (
+	transitionMu sync.RWMutex
+	transitionID string
+)
+
+// StartTransition starts tagging log lines with a new correlation ID and
+// returns it
+func StartTransition() string {
+	return JoinTransition("")
+}
+
+// JoinTransition tags log lines with a correlation ID received from the
+// peer; an empty id starts a new one. It returns the ID in use.
+func JoinTransition(id string) string {
+	if id == "" {
+		id = newTransitionID()
+	}
+	transitionMu.Lock()
+	transitionID = id
+	transitionMu.Unlock()
+	return id
+}
+
+// EndTransition stops tagging log lines with id. A transition that was
+// started after id is left alone.
+func EndTransition(id string) {
+	transitionMu.Lock()
+	defer transitionMu.Unlock()
+	if transitionID == id {
+		transitionID = ""
+	}
+}
+
+// TransitionID returns the correlation ID of the transition in progress,
+// or ""
+func TransitionID() string {
+	transitionMu.RLock()
+	defer transitionMu.RUnlock()
+	return transitionID
+}
+
+// newTransitionID returns a random 12-character ID
+func newTransitionID() string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,273 @@
+// Package maintenance reads the flags other tooling sets to take a host
+// out of service, so a node under maintenance does not become active:
+// a file on disk, Consul node maintenance mode, or a cordon or annotation
+// on the Kubernetes node.
+package maintenance
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/metrics"
+)
+
+var flagGauge = metrics.NewGauge(
+	"syncguard_maintenance",
+	"1 while a maintenance flag marks this host, by source",
+	"source",
+)
+
+// ErrUnderMaintenance means a flag marks this host under maintenance, or,
+// with on_error: refuse, that a flag could not be read
+var ErrUnderMaintenance = errors.New("host under maintenance")
+
+// Source is one place a maintenance flag can be set
+type Source interface {
+	Name() string
+	// Check reports whether the flag is set, with the reason given for it
+	Check() (flagged bool, reason string, err error)
+}
+
+// Checker consults every configured source
+type Checker struct {
+	sources []Source
+	refuse  bool
+}
+
+// New returns a checker for the configured sources, or nil when none is
+// configured
+func New(cfg config.MaintenanceConfig) (*Checker, error) {
+	c := &Checker{refuse: cfg.OnError == "refuse"}
+	if cfg.File != "" {
+		c.sources = append(c.sources, fileSource{path: cfg.File})
+	}
+	client := &http.Client{Timeout: cfg.Timeout.Duration()}
+	if cfg.Consul.Enabled {
+		node := cfg.Consul.Node
+		if node == "" {
+			hostname, err := os.Hostname()
+			if err != nil {
+				return nil, fmt.Errorf("failed to get hostname for maintenance.consul.node: %w", err)
+			}
+			node = hostname
+		}
+		c.sources = append(c.sources, &consulSource{
+			address: strings.TrimRight(cfg.Consul.Address, "/"),
+			node:    node,
+			token:   cfg.Consul.Token,
+			client:  client,
+		})
+	}
+	if cfg.Kubernetes.Enabled {
+		k, err := newKubernetesSource(cfg.Kubernetes, cfg.Timeout.Duration())
+		if err != nil {
+			return nil, err
+		}
+		c.sources = append(c.sources, k)
+	}
+	if len(c.sources) == 0 {
+		return nil, nil
+	}
+	return c, nil
+}
+
+// Check returns an error wrapping ErrUnderMaintenance when any source
+// marks the host. A source that cannot be read is skipped, unless the
+// checker refuses on errors.
+func (c *Checker) Check() error {
+	var unreadable []string
+	for _, source := range c.sources {
+		flagged, reason, err := source.Check()
+		if err != nil {
+			unreadable = append(unreadable, fmt.Sprintf("%s: %v", source.Name(), err))
+			continue
+		}
+		if !flagged {
+			flagGauge.Set(0, source.Name())
+			continue
+		}
+		flagGauge.Set(1, source.Name())
+		if reason == "" {
+			return fmt.Errorf("%w (%s)", ErrUnderMaintenance, source.Name())
+		}
+		return fmt.Errorf("%w (%s: %s)", ErrUnderMaintenance, source.Name(), reason)
+	}
+	if len(unreadable) > 0 && c.refuse {
+		return fmt.Errorf("%w: cannot read %s", ErrUnderMaintenance, strings.Join(unreadable, "; "))
+	}
+	return nil
+}
+
+// Sources names the configured sources
+func (c *Checker) Sources() []string {
+	names := make([]string, 0, len(c.sources))
+	for _, source := range c.sources {
+		names = append(names, source.Name())
+	}
+	return names
+}
+
+// fileSource is set while a file exists; its first line is the reason
+type fileSource struct {
+	path string
+}
+
+func (f fileSource) Name() string { return "file" }
+
+func (f fileSource) Check() (bool, string, error) {
+	data, err := os.ReadFile(f.path)
+	if os.IsNotExist(err) {
+		return false, "", nil
+	}
+	if err != nil {
+		return false, "", err
+	}
+	reason, _, _ := strings.Cut(strings.TrimSpace(string(data)), "\n")
+	return true, reason, nil
+}
+
+// consulMaintenanceCheck is the check Consul registers on a node in
+// maintenance mode
+const consulMaintenanceCheck = "_node_maintenance"
+
+// consulSource is set while the Consul node is in maintenance mode
+type consulSource struct {
+	address string
+	node    string
+	token   string
+	client  *http.Client
+}
+
+func (c *consulSource) Name() string { return "consul" }
+
+func (c *consulSource) Check() (bool, string, error) {
+	req, err := http.NewRequest(http.MethodGet, c.address+"/v1/health/node/"+url.PathEscape(c.node), nil)
+	if err != nil {
+		return false, "", err
+	}
+	if c.token != "" {
+		req.Header.Set("X-Consul-Token", c.token)
+	}
+	var checks []struct {
+		CheckID string `json:"CheckID"`
+		Status  string `json:"Status"`
+		Notes   string `json:"Notes"`
+	}
+	if err := getJSON(c.client, req, &checks); err != nil {
+		return false, "", err
+	}
+	for _, check := range checks {
+		if check.CheckID == consulMaintenanceCheck && check.Status == "critical" {
+			return true, check.Notes, nil
+		}
+	}
+	return false, "", nil
+}
+
+// kubernetesSource is set while the Kubernetes node carries the
+// annotation, or, with cordon, is unschedulable
+type kubernetesSource struct {
+	apiURL     string
+	node       string
+	annotation string
+	cordon     bool
+	tokenFile  string
+	client     *http.Client
+}
+
+func newKubernetesSource(cfg config.KubernetesMaintenanceConfig, timeout time.Duration) (*kubernetesSource, error) {
+	apiURL := cfg.APIURL
+	if apiURL == "" {
+		host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+		if host == "" || port == "" {
+			return nil, fmt.Errorf("maintenance.kubernetes.api_url is required outside a cluster")
+		}
+		apiURL = "https://" + net.JoinHostPort(host, port)
+	}
+	node := cfg.Node
+	if node == "" {
+		node = os.Getenv("NODE_NAME")
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if pem, err := os.ReadFile(cfg.CAFile); err == nil {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("maintenance.kubernetes.ca_file %s holds no PEM certificate", cfg.CAFile)
+		}
+		transport.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12, RootCAs: pool}
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read maintenance.kubernetes.ca_file: %w", err)
+	}
+
+	return &kubernetesSource{
+		apiURL:     strings.TrimRight(apiURL, "/"),
+		node:       node,
+		annotation: cfg.Annotation,
+		cordon:     cfg.Cordon,
+		tokenFile:  cfg.TokenFile,
+		client:     &http.Client{Timeout: timeout, Transport: transport},
+	}, nil
+}
+
+func (k *kubernetesSource) Name() string { return "kubernetes" }
+
+func (k *kubernetesSource) Check() (bool, string, error) {
+	req, err := http.NewRequest(http.MethodGet, k.apiURL+"/api/v1/nodes/"+url.PathEscape(k.node), nil)
+	if err != nil {
+		return false, "", err
+	}
+	// Projected service account tokens rotate, so the file is read each time
+	if token, err := os.ReadFile(k.tokenFile); err == nil {
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+	var node struct {
+		Metadata struct {
+			Annotations map[string]string `json:"annotations"`
+		} `json:"metadata"`
+		Spec struct {
+			Unschedulable bool `json:"unschedulable"`
+		} `json:"spec"`
+	}
+	if err := getJSON(k.client, req, &node); err != nil {
+		return false, "", err
+	}
+	if node.Metadata.Annotations[k.annotation] == "true" {
+		return true, "node annotated " + k.annotation, nil
+	}
+	if k.cordon && node.Spec.Unschedulable {
+		return true, "node " + k.node + " cordoned", nil
+	}
+	return false, "", nil
+}
+
+// getJSON sends req and decodes a 200 response into v
+func getJSON(client *http.Client, req *http.Request, v interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %d", req.URL.Path, resp.StatusCode)
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", req.URL.Path, err)
+	}
+	return nil
+}
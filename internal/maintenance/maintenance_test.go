@@ -0,0 +1,130 @@
+package maintenance
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+)
+
+func TestChecker_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "maintenance")
+	checker, err := New(config.MaintenanceConfig{File: path, OnError: "allow"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := checker.Check(); err != nil {
+		t.Fatalf("no flag file: %v", err)
+	}
+	if err := os.WriteFile(path, []byte("kernel upgrade\nticket OPS-12\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err = checker.Check()
+	if !errors.Is(err, ErrUnderMaintenance) || !strings.Contains(err.Error(), "kernel upgrade") {
+		t.Fatalf("flag file: got %v", err)
+	}
+}
+
+func TestChecker_Consul(t *testing.T) {
+	checks := `[{"CheckID":"serfHealth","Status":"passing"}]`
+	consul := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/health/node/validator-1" || r.Header.Get("X-Consul-Token") != "secret" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(checks))
+	}))
+	defer consul.Close()
+
+	checker, err := New(config.MaintenanceConfig{
+		Consul:  config.ConsulMaintenanceConfig{Enabled: true, Address: consul.URL, Node: "validator-1", Token: "secret"},
+		OnError: "allow",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := checker.Check(); err != nil {
+		t.Fatalf("healthy node: %v", err)
+	}
+
+	checks = `[{"CheckID":"serfHealth","Status":"passing"},{"CheckID":"_node_maintenance","Status":"critical","Notes":"disk swap"}]`
+	err = checker.Check()
+	if !errors.Is(err, ErrUnderMaintenance) || !strings.Contains(err.Error(), "disk swap") {
+		t.Fatalf("maintenance mode: got %v", err)
+	}
+}
+
+func TestChecker_Kubernetes(t *testing.T) {
+	node := `{"metadata":{"annotations":{}},"spec":{}}`
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/nodes/worker-3" || r.Header.Get("Authorization") != "Bearer sa-token" {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(node))
+	}))
+	defer api.Close()
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("sa-token\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	checker, err := New(config.MaintenanceConfig{
+		Kubernetes: config.KubernetesMaintenanceConfig{
+			Enabled:    true,
+			APIURL:     api.URL,
+			Node:       "worker-3",
+			Annotation: "syncguard.io/maintenance",
+			Cordon:     true,
+			TokenFile:  tokenFile,
+		},
+		OnError: "allow",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		node    string
+		flagged bool
+	}{
+		{"schedulable", `{"metadata":{"annotations":{"other":"true"}},"spec":{}}`, false},
+		{"annotated", `{"metadata":{"annotations":{"syncguard.io/maintenance":"true"}},"spec":{}}`, true},
+		{"cordoned", `{"metadata":{},"spec":{"unschedulable":true}}`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node = tt.node
+			err := checker.Check()
+			if got := errors.Is(err, ErrUnderMaintenance); got != tt.flagged {
+				t.Fatalf("flagged = %v, want %v (err %v)", got, tt.flagged, err)
+			}
+		})
+	}
+}
+
+func TestChecker_OnError(t *testing.T) {
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	for _, onError := range []string{"allow", "refuse"} {
+		checker, err := New(config.MaintenanceConfig{
+			Consul:  config.ConsulMaintenanceConfig{Enabled: true, Address: down.URL, Node: "n"},
+			OnError: onError,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = checker.Check()
+		if refused := errors.Is(err, ErrUnderMaintenance); refused != (onError == "refuse") {
+			t.Errorf("on_error %s: got %v", onError, err)
+		}
+	}
+}
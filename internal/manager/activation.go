@@ -0,0 +1,108 @@
+package manager
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/metrics"
+	"github.com/aldebaranode/syncguard/internal/node"
+)
+
+// activationPoll is how often the node's /status is read while waiting
+// for it to report a reloaded key
+const activationPoll = 250 * time.Millisecond
+
+var activationCounter = metrics.NewCounter(
+	"syncguard_key_activations_total",
+	"Key swaps applied to the node, by activation method and outcome (restarted, reloaded, fallback)",
+	"method", "outcome",
+)
+
+// activateKey makes the node pick up the key now on disk. With the default
+// activation method the node is restarted. The faster methods reload the
+// key in place, and any failure of theirs falls back to the restart, so a
+// takeover is never left half done. With the remote signer the node does
+// not read the key file, so there is nothing to activate.
+func (fm *FailoverManager) activateKey() error {
+	if fm.signer != nil {
+		return nil
+	}
+	method := fm.cfg.Validator.Activation.Method
+	if method == "restart" {
+		activationCounter.Inc(method, "restarted")
+		return fm.nodeManager.Restart()
+	}
+
+	start := time.Now()
+	if err := fm.reloadKey(method); err != nil {
+		fm.logger.Warn("Key activation via %s failed, restarting the node: %v", method, err)
+		activationCounter.Inc(method, "fallback")
+		return fm.nodeManager.Restart()
+	}
+	fm.logger.Info("Node picked up the key via %s in %s", method, time.Since(start).Round(time.Millisecond))
+	activationCounter.Inc(method, "reloaded")
+	return nil
+}
+
+// reloadKey asks the node to use the key on disk without restarting it.
+// After a signal the node must report that key in its /status. A remote
+// signer's key is not reported there, so restarting the signer is enough.
+func (fm *FailoverManager) reloadKey(method string) error {
+	activation := fm.cfg.Validator.Activation
+	switch method {
+	case "signal":
+		key, err := fm.keyManager.LoadKey()
+		if err != nil {
+			return fmt.Errorf("failed to load key: %w", err)
+		}
+		signaler, ok := fm.nodeManager.(node.Signaler)
+		if !ok {
+			return fmt.Errorf("the node manager cannot send signals")
+		}
+		if err := signaler.Signal(activation.Signal); err != nil {
+			return err
+		}
+		return fm.waitForKey(key.Address, activation.Timeout.Duration())
+
+	case "service":
+		stack, ok := fm.nodeManager.(*node.StackManager)
+		if !ok {
+			return fmt.Errorf("no validator services are managed")
+		}
+		return stack.RestartService(activation.Service)
+	}
+	return fmt.Errorf("unknown activation method %q", method)
+}
+
+// waitForKey waits until the node reports signing with address
+func (fm *FailoverManager) waitForKey(address string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	reported := ""
+	for {
+		signing, err := fm.healthChecker.SigningAddress()
+		if err == nil && strings.EqualFold(signing, address) {
+			return nil
+		}
+		if err == nil {
+			reported = signing
+		}
+		if time.Now().After(deadline) {
+			if reported == "" {
+				return fmt.Errorf("node did not report its key within %s", timeout)
+			}
+			return fmt.Errorf("node still reports key %s after %s", reported, timeout)
+		}
+		time.Sleep(activationPoll)
+	}
+}
+
+// keyActivator lets the peer server activate a key received from the peer
+// the same way the manager does
+type keyActivator struct {
+	fm *FailoverManager
+}
+
+func (a keyActivator) Restart() error {
+	return a.fm.activateKey()
+}
@@ -0,0 +1,161 @@
+package manager
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/approval"
+	"github.com/aldebaranode/syncguard/internal/constants"
+	"github.com/aldebaranode/syncguard/internal/notify"
+)
+
+// awaitApproval replaces automatic failover in approval mode. The first
+// call of an outage prepares the failover and asks for approval; later
+// calls fail over once the request is approved or auto-approved.
+func (fm *FailoverManager) awaitApproval(reason constants.Reason) {
+	now := time.Now()
+	req, created := fm.approval.Open(string(reason), now)
+	if created {
+		fm.requestApproval(req)
+		return
+	}
+	if fm.approval.Expire(now) {
+		fm.logger.Warn("Failover request %s auto-approved after %s without a decision",
+			req.ID, fm.cfg.Failover.Approval.AutoApproveAfter.Duration())
+		fm.audit("approve-failover", fmt.Sprintf("Failover request %s auto-approved", req.ID))
+	}
+	fm.failOverApproved()
+}
+
+// requestApproval prepares the failover and alerts operators
+func (fm *FailoverManager) requestApproval(req approval.Request) {
+	checks, problems := fm.prepareFailover()
+	fm.approval.Prepared(req.ID, checks, problems)
+
+	fm.logger.Error("Maximum failures reached (%s); failover %s awaiting operator approval", req.Reason, req.ID)
+	fields := map[string]string{
+		"request_id": req.ID,
+		"reason":     req.Reason,
+		"approve":    "syncguard cluster approve " + req.ID,
+		"reject":     "syncguard cluster reject " + req.ID,
+	}
+	if req.AutoApproveAt != nil {
+		fields["auto_approve_at"] = req.AutoApproveAt.Format(time.RFC3339)
+	}
+	if link := fm.cfg.Failover.Approval.ApproveURL; link != "" {
+		fields["approve_url"] = strings.ReplaceAll(link, "{id}", req.ID)
+	}
+	if len(problems) > 0 {
+		fields["problems"] = strings.Join(problems, "; ")
+	}
+	fm.alert(notify.EventFailoverApproval, notify.SeverityCritical,
+		fmt.Sprintf("Validator node failing - failover %s awaits approval", req.ID), fields)
+}
+
+// prepareFailover runs the checks a failover depends on, so the operator
+// approves with the standby's readiness in front of them
+func (fm *FailoverManager) prepareFailover() (checks, problems []string) {
+	if _, err := fm.keyManager.EncryptKeyToBytes(fm.secrets.Current()); err != nil {
+		problems = append(problems, fmt.Sprintf("validator key not transferable: %v", err))
+	} else {
+		checks = append(checks, "validator key readable and sealed for transfer")
+	}
+
+	if current, err := fm.stateManager.LoadState(); err != nil {
+		problems = append(problems, fmt.Sprintf("validator state unreadable: %v", err))
+	} else {
+		checks = append(checks, fmt.Sprintf("validator state at %d/%d/%d", current.Height, current.Round, current.Step))
+	}
+
+	if len(fm.cfg.Peers) == 0 {
+		return checks, append(problems, "no peer configured")
+	}
+	target := fm.standbyPeer()
+	id := target.ID
+	peer, err := fm.client.FetchHealth(target.Address)
+	switch {
+	case err != nil:
+		problems = append(problems, fmt.Sprintf("standby %s unreachable: %v", id, err))
+	case peer.Active:
+		problems = append(problems, fmt.Sprintf("standby %s reports itself active", id))
+	default:
+		if err := standbyReady(id, peer); err != nil {
+			problems = append(problems, err.Error())
+		} else {
+			checks = append(checks, fmt.Sprintf("standby %s ready at height %d", id, peer.Height))
+		}
+	}
+	if fm.cfg.Failover.LinkCheck.Enabled && err == nil {
+		if err := fm.checkLink(target); err != nil {
+			problems = append(problems, err.Error())
+		} else {
+			checks = append(checks, fmt.Sprintf("link to %s fast enough for the handover", id))
+		}
+	}
+	return checks, problems
+}
+
+// failOverApproved carries out an approved request, if there is one
+func (fm *FailoverManager) failOverApproved() {
+	req, ok := fm.approval.Take()
+	if !ok {
+		return
+	}
+	reason := constants.Reason(req.Reason)
+	fm.logger.Error("Failover %s approved by %s, initiating failover (%s)", req.ID, req.DecidedBy, reason)
+	fm.failOver(reason, "approval")
+}
+
+// withdrawApproval drops the request of an outage that ended
+func (fm *FailoverManager) withdrawApproval() {
+	if fm.approval == nil {
+		return
+	}
+	if req, ok := fm.approval.Withdraw(); ok {
+		fm.logger.Info("Node recovered; failover request %s withdrawn", req.ID)
+		fm.alert(notify.EventFailoverApproval, notify.SeverityInfo,
+			fmt.Sprintf("Node recovered - failover request %s withdrawn", req.ID),
+			map[string]string{"request_id": req.ID})
+	}
+}
+
+// ApproveFailover fails over on an operator's approval. An empty id
+// approves whichever request is pending.
+func (fm *FailoverManager) ApproveFailover(id string) error {
+	if fm.approval == nil {
+		return fmt.Errorf("failover approval is not enabled")
+	}
+	req, err := fm.approval.Decide(id, true, time.Now())
+	if err != nil {
+		return err
+	}
+	fm.audit("approve-failover", fmt.Sprintf("Operator approved failover request %s", req.ID))
+	fm.failOverApproved()
+	if fm.IsActive() {
+		return fmt.Errorf("node is still active, see logs")
+	}
+	return nil
+}
+
+// RejectFailover keeps this node active for the rest of the outage
+func (fm *FailoverManager) RejectFailover(id string) error {
+	if fm.approval == nil {
+		return fmt.Errorf("failover approval is not enabled")
+	}
+	req, err := fm.approval.Decide(id, false, time.Now())
+	if err != nil {
+		return err
+	}
+	fm.logger.Warn("Failover request %s rejected by operator; staying active until the node recovers", req.ID)
+	fm.audit("reject-failover", fmt.Sprintf("Operator rejected failover request %s", req.ID))
+	return nil
+}
+
+// Approval reports the failover request in place; nil when there is none
+func (fm *FailoverManager) Approval() *approval.Request {
+	if fm.approval == nil {
+		return nil
+	}
+	return fm.approval.Current()
+}
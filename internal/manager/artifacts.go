@@ -0,0 +1,98 @@
+package manager
+
+import (
+	"github.com/aldebaranode/syncguard/internal/communication"
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/crypto"
+	"github.com/aldebaranode/syncguard/internal/notify"
+	"github.com/aldebaranode/syncguard/internal/state"
+)
+
+// newArtifacts returns the files configured to be handed over with the
+// key, or nil when there are none
+func newArtifacts(cfg *config.Config) *state.Artifacts {
+	if len(cfg.CometBFT.Artifacts) == 0 {
+		return nil
+	}
+	artifacts := make([]state.Artifact, 0, len(cfg.CometBFT.Artifacts))
+	for _, a := range cfg.CometBFT.Artifacts {
+		artifacts = append(artifacts, state.Artifact{Name: a.Name, Path: a.Path, Encrypt: a.Encrypt})
+	}
+	return state.NewArtifacts(artifacts)
+}
+
+// transferArtifactsToPeer sends each artifact to the peer taking over.
+// An artifact that does not arrive leaves the peer with its own copy,
+// which costs connectivity rather than safety, so failover goes on.
+func (fm *FailoverManager) transferArtifactsToPeer(target config.PeerConfig) {
+	if fm.artifacts == nil || target.Address == "" {
+		return
+	}
+	if !fm.client.Supports(target.Address, communication.FeatureArtifacts) {
+		fm.logger.Warn("Not transferring artifacts: %s does not support them", target.ID)
+		return
+	}
+	for _, artifact := range fm.artifacts.List() {
+		err := fm.sendArtifact(target.Address, artifact)
+		if err != nil {
+			fm.logger.Error("Failed to transfer artifact %s to peer: %v", artifact.Name, err)
+			fm.alert(notify.EventKeyTransfer, notify.SeverityWarning, "Failed to transfer "+artifact.Name+" to peer during failover",
+				map[string]string{"artifact": artifact.Name, "error": err.Error()})
+			continue
+		}
+		fm.logger.Info("Transferred artifact %s to peer", artifact.Name)
+	}
+}
+
+// sendArtifact reads an artifact and sends it, sealed if configured so
+func (fm *FailoverManager) sendArtifact(addr string, artifact state.Artifact) error {
+	data, err := fm.artifacts.Read(artifact.Name)
+	if err != nil {
+		return err
+	}
+	transfer := communication.ArtifactTransfer{Name: artifact.Name, Data: data}
+	if artifact.Encrypt {
+		if transfer.Data, err = crypto.Encrypt(data, fm.secrets.Current()); err != nil {
+			return err
+		}
+		transfer.Encrypted = true
+	}
+	return fm.client.SendArtifact(addr, transfer)
+}
+
+// fetchArtifactsFromPeer takes each artifact from the active peer before
+// failback restarts the node; like the transfer, it does not stop failback
+func (fm *FailoverManager) fetchArtifactsFromPeer(source config.PeerConfig) {
+	if fm.artifacts == nil || source.Address == "" {
+		return
+	}
+	if !fm.client.Supports(source.Address, communication.FeatureArtifacts) {
+		fm.logger.Warn("Not fetching artifacts: %s does not support them", source.ID)
+		return
+	}
+	for _, artifact := range fm.artifacts.List() {
+		err := fm.fetchArtifact(source.Address, artifact)
+		if err != nil {
+			fm.logger.Error("Failed to fetch artifact %s from peer: %v", artifact.Name, err)
+			fm.alert(notify.EventKeyTransfer, notify.SeverityWarning, "Failed to fetch "+artifact.Name+" from peer during failback",
+				map[string]string{"artifact": artifact.Name, "error": err.Error()})
+			continue
+		}
+		fm.logger.Info("Fetched artifact %s from peer", artifact.Name)
+	}
+}
+
+// fetchArtifact fetches an artifact and writes it in place
+func (fm *FailoverManager) fetchArtifact(addr string, artifact state.Artifact) error {
+	transfer, err := fm.client.FetchArtifact(addr, artifact.Name)
+	if err != nil {
+		return err
+	}
+	data := transfer.Data
+	if transfer.Encrypted {
+		if data, err = fm.secrets.Decrypt(data); err != nil {
+			return err
+		}
+	}
+	return fm.artifacts.Write(artifact.Name, data)
+}
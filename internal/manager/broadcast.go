@@ -0,0 +1,71 @@
+package manager
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aldebaranode/syncguard/internal/communication"
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/notify"
+)
+
+// broadcastState keeps the last outcome of each kind of message sent to
+// every peer, so peers that missed one can be reconciled
+type broadcastState struct {
+	mu   sync.Mutex
+	last map[string]*communication.Broadcast
+}
+
+// broadcast sends a message to every peer at once under
+// peer_api.broadcast_quorum. Missing the quorum raises an alert once, and
+// so does reaching it again.
+func (fm *FailoverManager) broadcast(message string, send func(peer config.PeerConfig) error) *communication.Broadcast {
+	b := communication.FanOut(message, fm.cfg.Peers, communication.Quorum(fm.cfg.PeerAPI.BroadcastQuorum), send)
+
+	fm.broadcasts.mu.Lock()
+	if fm.broadcasts.last == nil {
+		fm.broadcasts.last = make(map[string]*communication.Broadcast)
+	}
+	previous := fm.broadcasts.last[message]
+	fm.broadcasts.last[message] = b
+	fm.broadcasts.mu.Unlock()
+
+	var missed []string
+	for _, r := range b.Missed() {
+		missed = append(missed, r.PeerID)
+		fm.logger.Debug("Peer %s missed %s: %v", r.PeerID, message, r.Error)
+	}
+	fields := map[string]string{
+		"message": message,
+		"quorum":  string(b.Quorum),
+		"reached": fmt.Sprintf("%d/%d", b.Reached, len(b.Results)),
+		"missed":  strings.Join(missed, ","),
+	}
+	switch wasMet := previous == nil || previous.Met(); {
+	case !b.Met() && wasMet:
+		text := fmt.Sprintf("%s reached %d of %d peers, %s needs %d; missed: %s",
+			message, b.Reached, len(b.Results), b.Quorum, b.Required, strings.Join(missed, ", "))
+		fm.logger.Warn("%s", text)
+		fm.alert(notify.EventBroadcast, notify.SeverityWarning, text, fields)
+	case b.Met() && !wasMet:
+		fm.alert(notify.EventBroadcast, notify.SeverityInfo,
+			fmt.Sprintf("%s reaches %d of %d peers again", message, b.Reached, len(b.Results)), fields)
+	}
+	return b
+}
+
+// Broadcasts reports the last outcome of each kind of message sent to
+// every peer, by message
+func (fm *FailoverManager) Broadcasts() []communication.Broadcast {
+	fm.broadcasts.mu.Lock()
+	defer fm.broadcasts.mu.Unlock()
+
+	list := make([]communication.Broadcast, 0, len(fm.broadcasts.last))
+	for _, b := range fm.broadcasts.last {
+		list = append(list, *b)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Message < list[j].Message })
+	return list
+}
@@ -0,0 +1,207 @@
+package manager
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/chain"
+	"github.com/aldebaranode/syncguard/internal/history"
+	"github.com/aldebaranode/syncguard/internal/notify"
+)
+
+// monitorChain discovers our validator's on-chain metadata at startup and
+// refreshes it every chain.refresh_interval
+func (fm *FailoverManager) monitorChain() {
+	ticker := time.NewTicker(fm.cfg.Chain.RefreshInterval.Duration())
+	defer ticker.Stop()
+
+	var lastProblems string
+	for {
+		if info, err := fm.discoverValidator(); err != nil {
+			fm.logger.Warn("Validator discovery failed: %v", err)
+		} else if problems := strings.Join(info.Problems, "; "); problems != lastProblems {
+			lastProblems = problems
+			// A passive node without the real key has nothing to verify
+			if problems != "" && fm.IsActive() {
+				fm.logger.Error("Validator verification: %s", problems)
+			}
+		}
+		fm.checkDowntimeBudget()
+
+		select {
+		case <-ticker.C:
+		case <-fm.stopCh:
+			return
+		}
+	}
+}
+
+// discoverValidator looks up the key on disk on chain
+func (fm *FailoverManager) discoverValidator() (*chain.ValidatorInfo, error) {
+	key, err := fm.keyManager.LoadRealKey()
+	if err != nil {
+		return nil, err
+	}
+	info, err := fm.chain.Discover(key)
+	if err != nil {
+		return nil, err
+	}
+	if fm.cfg.Logging.Verbose {
+		fm.logger.Info("Validator %s: in set %v, voting power %d/%d, operator %q, jailed %v",
+			info.Address, info.InValidatorSet, info.VotingPower, info.TotalVotingPower,
+			info.OperatorAddress, info.Jailed)
+	}
+	return info, nil
+}
+
+// ValidatorInfo returns the cached on-chain metadata of our validator
+func (fm *FailoverManager) ValidatorInfo() (chain.ValidatorInfo, bool) {
+	return fm.chain.Info()
+}
+
+// assessDowntimeRisk alerts while the active node is failing health checks,
+// escalating to critical as the chain's missed-block window fills. High
+// voting power validators escalate earlier.
+func (fm *FailoverManager) assessDowntimeRisk() {
+	info, ok := fm.chain.Info()
+	if !ok || info.Slashing == nil || !fm.IsActive() {
+		return
+	}
+
+	now := time.Now()
+	fm.mu.Lock()
+	if fm.outageStart.IsZero() {
+		fm.outageStart = now
+		fm.outageBaseline = info.MissedBlocks
+	}
+	elapsed := now.Sub(fm.outageStart)
+	baseline := fm.outageBaseline
+	fm.mu.Unlock()
+
+	// The LCD may be served by the node that is down; estimate from block time
+	missed, err := fm.chain.MissedBlocks()
+	estimated := err != nil
+	if estimated {
+		missed = baseline + int64(elapsed.Seconds()/float64(fm.cfg.Chain.BlockTime))
+	}
+	risk := chain.AssessRisk(*info.Slashing, missed)
+
+	share := info.VotingPowerShare()
+	threshold := fm.cfg.Chain.EscalateFill
+	if share >= fm.cfg.Chain.HighPowerShare {
+		threshold /= 2
+	}
+	severity := notify.SeverityWarning
+	if risk.WindowFill >= threshold {
+		severity = notify.SeverityCritical
+	}
+
+	fm.mu.Lock()
+	if fm.riskAlerted && severity <= fm.riskSeverity {
+		fm.mu.Unlock()
+		return
+	}
+	fm.riskAlerted = true
+	fm.riskSeverity = severity
+	fm.mu.Unlock()
+
+	fm.alert(notify.EventDowntimeRisk, severity,
+		fmt.Sprintf("Validator not signing: %d blocks until downtime jail (%.0f%% of allowed misses used)",
+			risk.BlocksUntilJail, risk.WindowFill*100),
+		map[string]string{
+			"missed_blocks":      fmt.Sprintf("%d", risk.MissedBlocks),
+			"max_missed":         fmt.Sprintf("%d", risk.MaxMissed),
+			"blocks_until_jail":  fmt.Sprintf("%d", risk.BlocksUntilJail),
+			"window_fill":        fmt.Sprintf("%.2f", risk.WindowFill),
+			"voting_power_share": fmt.Sprintf("%.4f", share),
+			"estimated":          fmt.Sprintf("%v", estimated),
+		})
+}
+
+// downtimeEntryType is the history entry type of a not-signing interval
+const downtimeEntryType = "downtime"
+
+// loadDowntime restores the last week's not-signing intervals from history
+func (fm *FailoverManager) loadDowntime() {
+	err := fm.history.Read(time.Now().Add(-7*24*time.Hour), func(e history.Entry) error {
+		if e.Type != downtimeEntryType {
+			return nil
+		}
+		start, err1 := time.Parse(time.RFC3339Nano, e.Fields["start"])
+		end, err2 := time.Parse(time.RFC3339Nano, e.Fields["end"])
+		if err1 == nil && err2 == nil {
+			fm.downtime.Add(chain.Interval{Start: start, End: end})
+		}
+		return nil
+	})
+	if err != nil {
+		fm.logger.Warn("Failed to load downtime history: %v", err)
+	}
+}
+
+// endDowntime closes an open not-signing interval and records it
+func (fm *FailoverManager) endDowntime() {
+	iv, ok := fm.downtime.End(time.Now())
+	if !ok {
+		return
+	}
+	seconds := iv.End.Sub(iv.Start).Seconds()
+	fields := map[string]string{
+		"start":   iv.Start.UTC().Format(time.RFC3339Nano),
+		"end":     iv.End.UTC().Format(time.RFC3339Nano),
+		"seconds": fmt.Sprintf("%.1f", seconds),
+	}
+	if chainID := fm.chainID(); chainID != "" {
+		fields["chain_id"] = chainID
+	}
+	if err := fm.history.Append(history.Entry{
+		Kind:    history.KindEvent,
+		Type:    downtimeEntryType,
+		NodeID:  fm.cfg.Node.ID,
+		Message: fmt.Sprintf("Validator not signing for %.0fs", seconds),
+		Fields:  fields,
+	}); err != nil {
+		fm.logger.Warn("Failed to record downtime history: %v", err)
+	}
+}
+
+// DowntimeBudget returns our not-signing time over the last day and week,
+// with the chain's missed-block window when known
+func (fm *FailoverManager) DowntimeBudget() chain.Budget {
+	budget := fm.downtime.Budget(time.Now())
+	if info, ok := fm.chain.Info(); ok && info.Slashing != nil {
+		risk := chain.AssessRisk(*info.Slashing, info.MissedBlocks)
+		budget.Window = &risk
+	}
+	return budget
+}
+
+// checkDowntimeBudget warns once when the chain's missed-block window
+// passes chain.budget_alert_fill, and again after it has drained
+func (fm *FailoverManager) checkDowntimeBudget() {
+	budget := fm.DowntimeBudget()
+	if budget.Window == nil {
+		return
+	}
+
+	over := budget.Window.WindowFill >= fm.cfg.Chain.BudgetAlertFill
+	fm.mu.Lock()
+	changed := over != fm.budgetAlerted
+	fm.budgetAlerted = over
+	fm.mu.Unlock()
+	if !changed || !over {
+		return
+	}
+
+	fm.alert(notify.EventDowntimeRisk, notify.SeverityWarning,
+		fmt.Sprintf("Downtime budget %.0f%% used: %d blocks until downtime jail",
+			budget.Window.WindowFill*100, budget.Window.BlocksUntilJail),
+		map[string]string{
+			"missed_blocks":     fmt.Sprintf("%d", budget.Window.MissedBlocks),
+			"max_missed":        fmt.Sprintf("%d", budget.Window.MaxMissed),
+			"blocks_until_jail": fmt.Sprintf("%d", budget.Window.BlocksUntilJail),
+			"day_seconds":       fmt.Sprintf("%.0f", budget.DaySeconds),
+			"week_seconds":      fmt.Sprintf("%.0f", budget.WeekSeconds),
+		})
+}
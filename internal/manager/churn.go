@@ -0,0 +1,129 @@
+package manager
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/metrics"
+	"github.com/aldebaranode/syncguard/internal/notify"
+)
+
+var (
+	stateWritesGauge = metrics.NewGauge(
+		"syncguard_state_writes_per_minute",
+		"Writes of the validator state file per minute over health.state_churn.window",
+	)
+	stateHeightsGauge = metrics.NewGauge(
+		"syncguard_state_heights_per_minute",
+		"Heights the validator state file advanced per minute over health.state_churn.window",
+	)
+)
+
+// churnWarmup is how many windows of height rate the active node learns
+// before a drop below it counts
+const churnWarmup = 3
+
+// churnState is the active node's usual state height rate and whether the
+// current one dropped below it
+type churnState struct {
+	mu       sync.Mutex
+	baseline float64
+	windows  int
+	folded   time.Time
+	dropped  bool
+}
+
+// monitorStateChurn samples the validator state file every
+// health.state_churn.interval
+func (fm *FailoverManager) monitorStateChurn() {
+	ticker := time.NewTicker(fm.cfg.Health.StateChurn.Interval.Duration())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			fm.checkStateChurn(time.Now())
+		case <-fm.stopCh:
+			return
+		}
+	}
+}
+
+// checkStateChurn publishes the churn rates and, while active, compares
+// the height rate with the one learned since the node became active. A
+// rate below health.state_churn.drop_ratio of it alerts once, and once
+// more when it recovers. Only windows without a drop are learned, so a
+// slow decline does not become the new normal.
+func (fm *FailoverManager) checkStateChurn(now time.Time) {
+	if err := fm.churn.Sample(now); err != nil {
+		fm.logger.Debug("State churn sample: %v", err)
+		return
+	}
+	rates := fm.churn.Rates()
+	stateWritesGauge.Set(rates.WritesPerMinute)
+	stateHeightsGauge.Set(rates.HeightsPerMinute)
+
+	cfg := fm.cfg.Health.StateChurn
+	window := cfg.Window.Duration()
+	active := fm.IsActive()
+	cs := &fm.churnState
+	cs.mu.Lock()
+	if !active {
+		cs.baseline, cs.windows, cs.folded, cs.dropped = 0, 0, time.Time{}, false
+		cs.mu.Unlock()
+		return
+	}
+	if cs.folded.IsZero() {
+		// Learning starts once a window holds only samples taken while active
+		cs.folded = now
+	}
+	due := now.Sub(cs.folded) >= window
+	if cs.windows == 0 && !due {
+		cs.mu.Unlock()
+		return
+	}
+	dropped := cs.windows >= churnWarmup && rates.HeightsPerMinute < cs.baseline*cfg.DropRatio
+	if !dropped && due {
+		if cs.windows == 0 {
+			cs.baseline = rates.HeightsPerMinute
+		} else {
+			cs.baseline = 0.8*cs.baseline + 0.2*rates.HeightsPerMinute
+		}
+		cs.windows++
+		cs.folded = now
+	}
+	changed := dropped != cs.dropped
+	cs.dropped = dropped
+	baseline := cs.baseline
+	cs.mu.Unlock()
+	if !changed {
+		return
+	}
+
+	fields := map[string]string{
+		"heights_per_minute": fmt.Sprintf("%.1f", rates.HeightsPerMinute),
+		"writes_per_minute":  fmt.Sprintf("%.1f", rates.WritesPerMinute),
+		"usual":              fmt.Sprintf("%.1f", baseline),
+	}
+	if dropped {
+		message := fmt.Sprintf("Validator state advances at %.1f heights/min, down from %.1f: the signer may be stuck",
+			rates.HeightsPerMinute, baseline)
+		fm.logger.Error("%s", message)
+		fm.alert(notify.EventStateChurn, notify.SeverityCritical, message, fields)
+		return
+	}
+	fm.logger.Info("Validator state advances at its usual rate again")
+	fm.alert(notify.EventStateChurn, notify.SeverityInfo, "Validator state advances at its usual rate again", fields)
+}
+
+// stateChurnDropped reports a drop in the state height rate when
+// health.state_churn.enforce makes it count as a failed check
+func (fm *FailoverManager) stateChurnDropped() bool {
+	if fm.churn == nil || !fm.cfg.Health.StateChurn.Enforce || !fm.IsActive() {
+		return false
+	}
+	fm.churnState.mu.Lock()
+	defer fm.churnState.mu.Unlock()
+	return fm.churnState.dropped
+}
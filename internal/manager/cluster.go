@@ -0,0 +1,92 @@
+package manager
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/aldebaranode/syncguard/internal/communication"
+	"github.com/aldebaranode/syncguard/internal/config"
+)
+
+// peerView is a peer and what it reported about itself; health is nil
+// when it did not answer
+type peerView struct {
+	peer   config.PeerConfig
+	health *communication.PeerHealth
+}
+
+// rankedPeers returns the peers by promotion priority, highest first;
+// peers of equal priority keep their configured order
+func (fm *FailoverManager) rankedPeers() []config.PeerConfig {
+	peers := append([]config.PeerConfig(nil), fm.cfg.Peers...)
+	sort.SliceStable(peers, func(i, j int) bool { return peers[i].Priority > peers[j].Priority })
+	return peers
+}
+
+// surveyPeers asks every peer for its health at once, in rank order
+func (fm *FailoverManager) surveyPeers() []peerView {
+	peers := fm.rankedPeers()
+	views := make([]peerView, len(peers))
+	var wg sync.WaitGroup
+	for i, peer := range peers {
+		views[i].peer = peer
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if h, err := fm.client.FetchHealth(views[i].peer.Address); err == nil {
+				views[i].health = h
+			}
+		}(i)
+	}
+	wg.Wait()
+	return views
+}
+
+// standbyPeer picks the peer to hand validator duties to: the highest
+// priority passive peer that is healthy, ready and not drained, else one
+// that is healthy and not drained, else one that answers, else the highest priority peer. A peer
+// that is not healthy still refuses the takeover itself. It is the zero
+// PeerConfig when no peer is configured.
+func (fm *FailoverManager) standbyPeer() config.PeerConfig {
+	if len(fm.cfg.Peers) < 2 {
+		return fm.onlyPeer()
+	}
+	views := fm.surveyPeers()
+	for _, usable := range []func(*communication.PeerHealth) bool{
+		func(h *communication.PeerHealth) bool { return h.Healthy && !h.Draining && standbyReady("", h) == nil },
+		func(h *communication.PeerHealth) bool { return h.Healthy && !h.Draining },
+		func(h *communication.PeerHealth) bool { return true },
+	} {
+		for _, view := range views {
+			if view.health != nil && !view.health.Active && usable(view.health) {
+				return view.peer
+			}
+		}
+	}
+	return views[0].peer
+}
+
+// sourcePeer is the peer to take the key and state from: the one that
+// reports itself active, else the highest priority peer. It is the zero
+// PeerConfig when no peer is configured.
+func (fm *FailoverManager) sourcePeer() config.PeerConfig {
+	if len(fm.cfg.Peers) < 2 {
+		return fm.onlyPeer()
+	}
+	views := fm.surveyPeers()
+	for _, view := range views {
+		if view.health != nil && view.health.Active {
+			return view.peer
+		}
+	}
+	return views[0].peer
+}
+
+// onlyPeer is the single configured peer, which needs no survey, or the
+// zero PeerConfig when there is none
+func (fm *FailoverManager) onlyPeer() config.PeerConfig {
+	if len(fm.cfg.Peers) == 0 {
+		return config.PeerConfig{}
+	}
+	return fm.cfg.Peers[0]
+}
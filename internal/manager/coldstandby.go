@@ -0,0 +1,76 @@
+package manager
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/coldstandby"
+	"github.com/aldebaranode/syncguard/internal/notify"
+)
+
+// shipToStandby keeps the cold standby's copy of state and key current
+// while this node signs
+func (fm *FailoverManager) shipToStandby() {
+	ticker := time.NewTicker(fm.cfg.ColdStandby.Interval.Duration())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			fm.shipOnce()
+		case <-fm.stopCh:
+			return
+		}
+	}
+}
+
+// shipOnce ships a changed state or key, alerting on the first failure of
+// a run and when shipping works again. A node whose key was released (by
+// activate-standby) has nothing to ship.
+func (fm *FailoverManager) shipOnce() {
+	if !fm.IsActive() || fm.keyManager.IsDisabled() {
+		return
+	}
+
+	err := fm.coldStandby.Ship()
+	status := fm.coldStandby.Status()
+	switch {
+	case err != nil && status.Failures == 1:
+		fm.logger.Error("Failed to ship to cold standby: %v", err)
+		fm.alert(notify.EventColdStandby, notify.SeverityWarning, "Failed to ship validator state to the cold standby",
+			map[string]string{"error": err.Error()})
+	case err != nil:
+		fm.logger.Debug("Cold standby shipping still failing (%d attempts): %v", status.Failures, err)
+	case fm.shipFailed:
+		fm.logger.Info("Shipping to cold standby recovered at height %d", status.Height)
+		fm.alert(notify.EventColdStandby, notify.SeverityInfo, "Shipping to the cold standby recovered",
+			map[string]string{"height": fmt.Sprintf("%d", status.Height)})
+	}
+	fm.shipFailed = err != nil
+}
+
+// advisePromotion replaces automatic failover when the standby runs no
+// SyncGuard: only an operator can promote it
+func (fm *FailoverManager) advisePromotion() {
+	if fm.promotionAdvised {
+		return
+	}
+	fm.promotionAdvised = true
+	status := fm.coldStandby.Status()
+	fm.logger.Error("Validator node failing; promote the cold standby with 'syncguard activate-standby'")
+	fm.alert(notify.EventColdStandby, notify.SeverityCritical,
+		"Validator node failing - promote the cold standby with 'syncguard activate-standby'",
+		map[string]string{
+			"shipped_height": fmt.Sprintf("%d", status.Height),
+			"last_shipped":   status.LastShipped.Format(time.RFC3339),
+		})
+}
+
+// ColdStandby reports shipping to the cold standby; nil without one
+func (fm *FailoverManager) ColdStandby() *coldstandby.Status {
+	if fm.coldStandby == nil {
+		return nil
+	}
+	status := fm.coldStandby.Status()
+	return &status
+}
@@ -0,0 +1,93 @@
+package manager
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/notify"
+)
+
+// errDrained refuses the active role to a node an operator drained
+var errDrained = errors.New("node drained: run syncguard undrain to let it take over")
+
+// drainPath marks a drained node, so the drain survives the reboot it is
+// usually for
+func (fm *FailoverManager) drainPath() string {
+	return filepath.Join(fm.cfg.Node.DataDir, "draining")
+}
+
+// loadDrain restores a drain from before a restart
+func (fm *FailoverManager) loadDrain() {
+	if _, err := os.Stat(fm.drainPath()); err == nil {
+		fm.draining.Store(true)
+		fm.logger.Warn("Node is drained; it will not take validator duties until undrained")
+	}
+}
+
+// IsDraining reports whether an operator drained this node
+func (fm *FailoverManager) IsDraining() bool {
+	return fm.draining.Load()
+}
+
+// setDraining records the drain on disk first, so the flag never claims
+// more than a restart would keep
+func (fm *FailoverManager) setDraining(draining bool) error {
+	if draining {
+		stamp := []byte(time.Now().UTC().Format(time.RFC3339) + "\n")
+		if err := os.WriteFile(fm.drainPath(), stamp, 0600); err != nil {
+			return fmt.Errorf("failed to record drain: %w", err)
+		}
+	} else if err := os.Remove(fm.drainPath()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to clear drain: %w", err)
+	}
+	fm.draining.Store(draining)
+	return nil
+}
+
+// Drain takes this node out of service: an active node hands its key and
+// state to the standby, and the node refuses the active role, from peers,
+// failback and first-boot confirmation alike, until undrained. If the
+// handoff fails the node is not drained.
+func (fm *FailoverManager) Drain() error {
+	if !fm.automated() {
+		return errMonitorOnly
+	}
+	if fm.IsDraining() {
+		return nil
+	}
+	if err := fm.setDraining(true); err != nil {
+		return err
+	}
+	if fm.IsActive() {
+		if err := fm.Handoff(); err != nil {
+			if undo := fm.setDraining(false); undo != nil {
+				fm.logger.Error("Failed to undo drain: %v", undo)
+			}
+			return fmt.Errorf("not drained: %w", err)
+		}
+	}
+
+	fm.audit("drain", "Node drained")
+	fm.logger.Warn("Node drained; it will not take validator duties until undrained")
+	fm.alert(notify.EventMaintenance, notify.SeverityWarning, "Node drained by operator", nil)
+	return nil
+}
+
+// Undrain lets a drained node take the active role again. It does not
+// take it by itself; a primary fails back as usual.
+func (fm *FailoverManager) Undrain() error {
+	if !fm.IsDraining() {
+		return fmt.Errorf("this node is not drained")
+	}
+	if err := fm.setDraining(false); err != nil {
+		return err
+	}
+
+	fm.audit("undrain", "Node undrained")
+	fm.logger.Info("Node undrained")
+	fm.alert(notify.EventMaintenance, notify.SeverityInfo, "Node undrained by operator", nil)
+	return nil
+}
@@ -0,0 +1,93 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aldebaranode/syncguard/internal/constants"
+	"github.com/aldebaranode/syncguard/internal/drill"
+)
+
+// Preflight checks that a failover drill can start on this node: it is
+// active and healthy, and every peer is healthy
+func (fm *FailoverManager) Preflight() error {
+	if !fm.automated() {
+		return errMonitorOnly
+	}
+	if len(fm.cfg.Peers) == 0 {
+		return fmt.Errorf("no peer configured")
+	}
+
+	fm.mu.RLock()
+	isActive := fm.isActive
+	lockDown := fm.lockUnreachable()
+	fm.mu.RUnlock()
+
+	if !isActive {
+		return drill.ErrNotActive
+	}
+	if lockDown {
+		return fmt.Errorf("lock backend is unreachable")
+	}
+	if fm.IsPaused() {
+		return fmt.Errorf("automatic failover is paused")
+	}
+	if !fm.healthChecker.IsHealthy() {
+		return fmt.Errorf("this node is unhealthy")
+	}
+
+	// Every node must be healthy, and the standby must be passive
+	for i, p := range fm.cfg.Peers {
+		peer, err := fm.client.FetchHealth(p.Address)
+		if err != nil {
+			return fmt.Errorf("peer %s unreachable: %w", p.ID, err)
+		}
+		if !peer.Healthy {
+			return fmt.Errorf("peer %s is unhealthy", p.ID)
+		}
+		if i == 0 && peer.Active {
+			return fmt.Errorf("standby %s is already active", p.ID)
+		}
+		if i == 0 {
+			if err := standbyReady(p.ID, peer); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// HandOver fails over to the standby for a drill
+func (fm *FailoverManager) HandOver() error {
+	if err := fm.gateOnLink(fm.standbyPeer()); err != nil {
+		return err
+	}
+	if err := fm.initiateFailover(context.Background(), constants.ReasonDrill, "drill"); err != nil {
+		return err
+	}
+	if fm.IsActive() {
+		return fmt.Errorf("node is still active")
+	}
+	return nil
+}
+
+// PeerActive reports whether a standby has taken over
+func (fm *FailoverManager) PeerActive() (bool, error) {
+	source := fm.sourcePeer()
+	peer, err := fm.client.FetchHealth(source.Address)
+	if err != nil {
+		return false, err
+	}
+	return peer.Active, nil
+}
+
+// TakeBack fails back to this node at the end of a drill
+func (fm *FailoverManager) TakeBack() error {
+	if err := fm.initiateFailback(constants.ReasonDrill, "drill"); err != nil {
+		return err
+	}
+	if !fm.IsActive() {
+		return fmt.Errorf("node did not become active, see logs")
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package manager
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/constants"
+	"github.com/aldebaranode/syncguard/internal/election"
+	"github.com/aldebaranode/syncguard/internal/notify"
+	"github.com/aldebaranode/syncguard/internal/state"
+)
+
+// newElector sets up the election, and makes winning it part of taking
+// the lock
+func (fm *FailoverManager) newElector(cfg *config.Config, lock state.LockBackend) error {
+	peers := make([]string, 0, len(cfg.Peers))
+	for _, peer := range cfg.Peers {
+		peers = append(peers, peer.Address)
+	}
+	elector, err := election.New(cfg.Node.ID, filepath.Join(cfg.Node.DataDir, "election.json"),
+		peers, cfg.Election.LeaseTTL.Duration(), fm.client)
+	if err != nil {
+		return err
+	}
+	fm.elector = elector
+	fm.stateManager.SetLockBackend(election.NewLock(elector, lock))
+	return nil
+}
+
+// maintainLease renews the active node's lease. Once it runs out without
+// a majority renewing it, the node stops signing: the majority may already
+// be electing another node.
+func (fm *FailoverManager) maintainLease() {
+	interval := fm.cfg.Election.RenewInterval.Duration()
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			timer.Reset(fm.renewLease(interval))
+		case <-fm.stopCh:
+			return
+		}
+	}
+}
+
+// renewLease renews the lease while active and returns when to try next:
+// after interval, or when the node must start to stop signing if that is
+// sooner. That is the stop margin before the lease runs out, so the node
+// has stopped by the time it does.
+func (fm *FailoverManager) renewLease(interval time.Duration) time.Duration {
+	if !fm.IsActive() {
+		return interval
+	}
+	err := fm.elector.Renew()
+	if err != nil {
+		fm.logger.Warn("Failed to renew election lease: %v", err)
+	}
+	if fm.elector.Leader() {
+		left := time.Until(fm.elector.Status().LeaseUntil) - fm.stopMargin()
+		if left > interval {
+			return interval
+		}
+		if left > 0 {
+			return left
+		}
+	}
+
+	message := "Election lease lost: stopping signing"
+	fields := map[string]string{}
+	if err != nil {
+		fields["error"] = err.Error()
+	}
+	fm.logger.Error(message)
+	fm.alert(notify.EventElection, notify.SeverityCritical, message, fields)
+	fm.initiateFailover(context.Background(), constants.ReasonLeaseLost, "election")
+	return interval
+}
+
+// stopMargin is how long stopping signing can take: stopping the node, up
+// to validator.stop_timeout. A remote signer stops at once.
+func (fm *FailoverManager) stopMargin() time.Duration {
+	if fm.signer != nil {
+		return 0
+	}
+	return fm.cfg.Validator.StopTimeout.Duration()
+}
+
+// resignLease gives the lease back after a takeover that won it did not
+// get as far as signing
+func (fm *FailoverManager) resignLease() {
+	if fm.elector != nil {
+		fm.elector.Resign()
+	}
+}
+
+// ElectionStatus is this node's view of the election; nil when disabled
+func (fm *FailoverManager) ElectionStatus() *election.Status {
+	if fm.elector == nil {
+		return nil
+	}
+	status := fm.elector.Status()
+	return &status
+}
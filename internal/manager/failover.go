@@ -1,37 +1,129 @@
 package manager
 
 import (
-	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/aldebaranode/syncguard/internal/approval"
+	"github.com/aldebaranode/syncguard/internal/chain"
+	"github.com/aldebaranode/syncguard/internal/coldstandby"
+	"github.com/aldebaranode/syncguard/internal/communication"
 	"github.com/aldebaranode/syncguard/internal/config"
 	"github.com/aldebaranode/syncguard/internal/constants"
 	"github.com/aldebaranode/syncguard/internal/crypto"
+	"github.com/aldebaranode/syncguard/internal/deprecation"
+	"github.com/aldebaranode/syncguard/internal/drill"
+	"github.com/aldebaranode/syncguard/internal/election"
+	"github.com/aldebaranode/syncguard/internal/failback"
+	"github.com/aldebaranode/syncguard/internal/group"
 	"github.com/aldebaranode/syncguard/internal/health"
+	"github.com/aldebaranode/syncguard/internal/history"
 	"github.com/aldebaranode/syncguard/internal/logger"
+	"github.com/aldebaranode/syncguard/internal/maintenance"
+	"github.com/aldebaranode/syncguard/internal/metrics"
 	"github.com/aldebaranode/syncguard/internal/node"
+	"github.com/aldebaranode/syncguard/internal/notify"
 	"github.com/aldebaranode/syncguard/internal/server"
+	"github.com/aldebaranode/syncguard/internal/signer"
 	"github.com/aldebaranode/syncguard/internal/state"
+	"github.com/aldebaranode/syncguard/internal/supervise"
+	"github.com/aldebaranode/syncguard/internal/transition"
+	"github.com/aldebaranode/syncguard/internal/witness"
 )
 
+// handshakeInterval is how often reach-back handshakes are repeated
+const handshakeInterval = 5 * time.Minute
+
+// peerStateInterval is how often what is known about peers is saved
+const peerStateInterval = 15 * time.Second
+
 // FailoverManager manages the failover process for validator nodes
 type FailoverManager struct {
 	cfg                *config.Config
 	stateManager       *state.Manager
 	keyManager         *state.KeyManager
 	healthChecker      *health.Checker
+	pinger             *health.Pinger
+	selfMonitor        *health.SelfMonitor
+	hostMonitor        *health.HostMonitor
+	alerts             *notify.Dispatcher
+	history            *history.Store
 	nodeManager        node.Manager
 	server             *server.Server
+	adminServer        *server.AdminServer
+	drills             *drill.Runner
+	drillScheduler     *drill.Scheduler
+	chain              *chain.Discoverer
+	downtime           *chain.Ledger
+	signing            *chain.SigningFeed
+	signGuard          *chain.SignGuard
+	signatures         *state.DoubleSignProtector
+	signer             *signer.Signer
+	tlsConfig          *tls.Config
+	signingState       signingState
+	group              *group.Group
+	client             *communication.Client
+	handoff            keyHandoffClient
+	peerStore          *communication.PeerStore
+	identity           *crypto.Identity
+	keyring            *crypto.Keyring
+	secrets            *crypto.SecretRing
+	provenance         state.Tracker
+	watermark          *state.WatermarkWriter
 	isActive           bool
 	isPrimarySite      bool
 	failbackInProgress bool
 	failureCount       int
+	wasHealthy         bool
+	wasUnknown         bool
+	lockDownSince      time.Time
+	lockGraceExpired   bool
+	paused             bool
+	draining           atomic.Bool
+	stateStreaming     atomic.Bool
+	firstBootConfirmed bool
+	awaitingConfirm    bool
+	bootID             string
+	churn              *state.ChurnMeter
+	churnState         churnState
+	invariants         invariantState
+	outageStart        time.Time
+	outageBaseline     int64
+	riskAlerted        bool
+	riskSeverity       notify.Severity
+	budgetAlerted      bool
+	lastCommand        *health.CommandResult
+	journal            *state.Journal
+	elector            *election.Elector
+	artifacts          *state.Artifacts
+	maintenance        *maintenance.Checker
+	witness            *witness.Client
+	failback           *failback.Policy
+	degrading          map[string]bool
+	heartbeats         heartbeatState
+	broadcasts         broadcastState
+	progress           progressState
+	readiness          *health.Readiness
+	coldStandby        *coldstandby.Shipper
+	shipFailed         bool
+	promotionAdvised   bool
+	approval           *approval.Gate
+	transitions        *transition.Executor
+	lastTransition     *history.Entry
+	transitionMu       sync.Mutex
+	roleStore          *state.RoleStore
+	role               state.RoleRecord
+	roleMu             sync.Mutex
 	mu                 sync.RWMutex
 	logger             *logger.Logger
 	stopCh             chan struct{}
@@ -49,20 +141,37 @@ func (fm *FailoverManager) IsPrimary() bool {
 	return fm.isPrimarySite
 }
 
-// SetActive sets the active state of this node
-func (fm *FailoverManager) SetActive(active bool) {
+// SetActive sets the active state of this node at the peer's request;
+// peerReason is the reason code the peer sent, if any
+func (fm *FailoverManager) SetActive(active bool, peerReason string) {
 	fm.mu.Lock()
 	defer fm.mu.Unlock()
+	changed := fm.isActive != active
 	fm.isActive = active
+	fm.updateWatermark(active)
+	if !changed {
+		return
+	}
+
+	var fields map[string]string
+	if peerReason != "" {
+		fields = map[string]string{"peer_reason": peerReason}
+	}
+	if active {
+		fm.transitionAlert(notify.EventTakeover, notify.SeverityWarning, "Peer handed over validator duties - node is now active",
+			constants.ReasonPeerRequest, fields)
+	} else {
+		fm.recordSignature()
+		fm.transitionAlert(notify.EventRelease, notify.SeverityWarning, "Peer took over validator duties - node is now passive",
+			constants.ReasonPeerRequest, fields)
+	}
 }
 
 // NewFailoverManager creates a new failover manager
-func NewFailoverManager(cfg *config.Config) *FailoverManager {
-	newLogger := logger.NewLogger(cfg)
-	newLogger.WithModule("failover")
-
-	keyLogger := logger.NewLogger(cfg)
-	keyLogger.WithModule("key-state")
+func NewFailoverManager(cfg *config.Config) (*FailoverManager, error) {
+	newLogger := logger.New(cfg, "failover")
+	keyLogger := logger.New(cfg, "key-state")
+	metrics.SetInstance(cfg.Node.Instance)
 
 	fm := &FailoverManager{
 		cfg:          cfg,
@@ -73,39 +182,167 @@ func NewFailoverManager(cfg *config.Config) *FailoverManager {
 			keyLogger,
 		),
 		healthChecker: health.NewChecker(cfg, cfg.CometBFT.RPCURL),
+		pinger:        health.NewPinger(cfg),
+		selfMonitor:   health.NewSelfMonitor(cfg),
+		history:       history.NewStore(cfg.History.Path, int64(cfg.History.MaxSize)),
+		journal:       state.NewJournal(filepath.Join(cfg.Node.DataDir, "transitions.journal")),
+		peerStore:     communication.NewPeerStore(filepath.Join(cfg.Node.DataDir, "peers.json")),
+		roleStore:     state.NewRoleStore(rolePath(cfg.Node.DataDir)),
+		failback:      failback.New(cfg),
+		transitions:   transition.NewExecutor(),
+		secrets:       crypto.NewSecretRing(cfg.Secret, cfg.PreviousSecret),
 		isPrimarySite: cfg.Node.IsPrimary,
 		isActive:      cfg.Node.Role == constants.NodeStatusActive,
+		wasHealthy:    true,
 		logger:        newLogger,
 		stopCh:        make(chan struct{}),
 	}
 
+	owner, err := state.ResolveOwner(cfg.CometBFT.KeyOwner)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cometbft.key_owner: %w", err)
+	}
+	fm.keyManager.SetKeyFilePolicy(cfg.CometBFT.InsecureKeyFiles, owner)
+
+	codec, err := state.NewStateCodec(cfg.CometBFT.StateFormat)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cometbft.state_format: %w", err)
+	}
+	fm.stateManager.SetCodec(codec)
+	if cfg.Health.StateChurn.Enabled {
+		fm.churn = state.NewChurnMeter(cfg.CometBFT.StatePath, codec, cfg.Health.StateChurn.Window.Duration())
+	}
+
+	if cfg.Identity.Enabled {
+		identity, err := crypto.LoadOrCreateIdentity(cfg.Identity.KeyPath, cfg.Node.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load node identity: %w", err)
+		}
+		keyring, err := crypto.NewKeyring(cfg.Identity.KeyringPath, cfg.PinnedPeerKeys())
+		if err != nil {
+			return nil, fmt.Errorf("failed to load keyring: %w", err)
+		}
+		keyring.OnReloadError(func(err error) {
+			fm.logger.Error("Keyring file changed but could not be loaded, still trusting the keys loaded before: %v", err)
+		})
+		fm.identity = identity
+		fm.keyring = keyring
+	}
+	fm.client = communication.NewClient(cfg, fm.identity)
+	fm.handoff = fm.client
+	if cfg.TLS.Enabled {
+		tlsConfig, err := crypto.LoadTLSConfig(cfg.TLS.CAFile, cfg.TLS.CertFile, cfg.TLS.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load peer TLS: %w", err)
+		}
+		fm.client.UseTLS(tlsConfig)
+		fm.tlsConfig = tlsConfig
+	}
+
+	alerts, err := notify.NewDispatcher(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure alerts: %w", err)
+	}
+	fm.alerts = alerts
+
+	fm.chain = chain.NewDiscoverer(cfg)
+	fm.downtime = chain.NewLedger()
+	if cfg.Chain.Signing.Enabled {
+		fm.signing = chain.NewSigningFeed(cfg)
+	}
+	if cfg.Chain.DoubleSignGuard.Enabled {
+		fm.signGuard = chain.NewSignGuard(cfg)
+	}
+	signatures, err := state.LoadDoubleSignProtector(filepath.Join(cfg.Node.DataDir, "signatures.json"))
+	if err != nil {
+		return nil, err
+	}
+	fm.signatures = signatures
+	if cfg.Health.Host.Enabled {
+		fm.hostMonitor = health.NewHostMonitor(cfg)
+	}
+	lock, err := NewLockBackend(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up lock backend: %w", err)
+	}
+	if cfg.Election.Enabled {
+		if err := fm.newElector(cfg, lock); err != nil {
+			return nil, fmt.Errorf("failed to set up election: %w", err)
+		}
+	} else {
+		fm.stateManager.SetLockBackend(lock)
+	}
+	linked, err := group.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	fm.group = linked
+	fm.drills = drill.NewRunner(cfg, fm, fm.history)
+	drillScheduler, err := drill.NewScheduler(cfg, fm.drills)
+	if err != nil {
+		return nil, err
+	}
+	fm.drillScheduler = drillScheduler
+
+	if cfg.ColdStandby.Enabled {
+		target, err := coldstandby.NewTarget(cfg.ColdStandby)
+		if err != nil {
+			return nil, err
+		}
+		fm.coldStandby = coldstandby.NewShipper(cfg, target, fm.keyManager)
+	}
+
+	if cfg.Failover.Approval.Enabled {
+		fm.approval = approval.New(cfg)
+	}
+
+	if cfg.Witness.Address != "" {
+		fm.witness = witness.NewClient(cfg.Witness.Address, cfg.Node.ID, fm.secrets, cfg.Witness.Timeout.Duration(), fm.tlsConfig)
+	}
+
+	checker, err := maintenance.New(cfg.Maintenance)
+	if err != nil {
+		return nil, err
+	}
+	fm.maintenance = checker
+
+	// A monitor-only node writes no signing gate and leaves the validator
+	// process alone
+	if cfg.Gatekeeper.Enabled && fm.automated() {
+		fm.watermark = state.NewWatermarkWriter(cfg.Gatekeeper.Path, cfg.Node.ID)
+	}
+
 	// Initialize node manager if enabled
-	if cfg.Validator.Enabled {
-		nodeLogger := logger.NewLogger(cfg)
-		nodeLogger.WithModule("node")
-		fm.nodeManager = node.NewManager(node.Config{
-			Mode:         cfg.Validator.Mode,
-			Binary:       cfg.Validator.Binary,
-			Args:         cfg.Validator.Args,
-			Container:    cfg.Validator.Container,
-			ComposeFile:  cfg.Validator.ComposeFile,
-			Service:      cfg.Validator.Service,
-			StopTimeout:  time.Duration(cfg.Validator.StopTimeout * float64(time.Second)),
-			RestartDelay: time.Duration(cfg.Validator.RestartDelay * float64(time.Second)),
-		}, nodeLogger)
+	if cfg.Validator.Enabled && fm.automated() {
+		nodeManager, err := newNodeManager(cfg)
+		if err != nil {
+			return nil, err
+		}
+		fm.nodeManager = nodeManager
 	}
 
-	return fm
+	return fm, nil
 }
 
 // Start begins the failover monitoring process
 func (fm *FailoverManager) Start() error {
 	fm.logger.Info("Starting failover manager - Primary: %v, Active: %v",
 		fm.isPrimarySite, fm.isActive)
-
-	// Initialize key
-	if err := fm.keyManager.InitializeKey(); err != nil {
-		return fmt.Errorf("failed to initialize key: %w", err)
+	fm.transitions.SetObserver(transitionObserver{fm})
+	supervise.Go(fm.logger, "transitions", fm.stopCh, func() { fm.transitions.Run(fm.stopCh) })
+	restored := fm.restorePeerState()
+	fm.loadDrain()
+
+	if fm.automated() {
+		if err := fm.prepareKey(restored); err != nil {
+			return err
+		}
+		fm.saveRole("", roleStartup)
+		if err := fm.startSigner(); err != nil {
+			return err
+		}
+	} else {
+		fm.logger.Warn("Monitor-only mode: watching and alerting only, the key, node and role are left as they are")
 	}
 
 	// Start the validator node if wrapper is enabled
@@ -125,47 +362,203 @@ func (fm *FailoverManager) Start() error {
 	if _, err := fm.stateManager.LoadState(); err != nil {
 		return fmt.Errorf("failed to load validator state: %w", err)
 	}
+	fm.updateWatermark(fm.isActive)
+	fm.loadDowntime()
+
+	// Start health monitoring. A panic in a loop is reported and the loop
+	// restarted, rather than leaving the node unmonitored.
+	supervise.Go(fm.logger, "health-monitor", fm.stopCh, fm.monitorHealth)
+	supervise.Go(fm.logger, "self-monitor", fm.stopCh, fm.monitorSelf)
+	if fm.automated() {
+		supervise.Go(fm.logger, "lock-monitor", fm.stopCh, fm.monitorLock)
+	}
+	supervise.Go(fm.logger, "chain-monitor", fm.stopCh, fm.monitorChain)
+	supervise.Go(fm.logger, "signatures", fm.stopCh, fm.recordSignatures)
+	if fm.elector != nil && fm.automated() {
+		supervise.Go(fm.logger, "election", fm.stopCh, fm.maintainLease)
+	}
+	if fm.signing != nil {
+		supervise.Go(fm.logger, "signing-feed", fm.stopCh, fm.monitorSigning)
+	}
+	if fm.churn != nil {
+		supervise.Go(fm.logger, "state-churn", fm.stopCh, fm.monitorStateChurn)
+	}
+	if fm.cfg.Invariants.Enabled {
+		supervise.Go(fm.logger, "invariants", fm.stopCh, fm.monitorInvariants)
+	}
+	if fm.drillScheduler != nil {
+		supervise.Go(fm.logger, "drill-scheduler", fm.stopCh, func() { fm.drillScheduler.Run(fm.stopCh) })
+	}
 
-	// Start health monitoring
-	go fm.monitorHealth()
+	if fm.coldStandby != nil && fm.automated() {
+		supervise.Go(fm.logger, "cold-standby", fm.stopCh, fm.shipToStandby)
+	}
 
 	// Start state synchronization if we're passive
-	if !fm.isActive {
-		go fm.syncValidatorState()
+	if !fm.isActive && fm.automated() {
+		supervise.Go(fm.logger, "state-sync", fm.stopCh, fm.syncValidatorState)
+		if fm.cfg.PeerAPI.Protocol == communication.ProtocolGRPC {
+			supervise.Go(fm.logger, "state-watch", fm.stopCh, fm.watchValidatorState)
+		}
 	}
 
 	// Create and start peer communication server
-	fm.server = server.NewServer(fm.cfg, fm.stateManager, fm.keyManager, fm.healthChecker, fm, fm.nodeManager)
+	// The server restarts the node through the configured key activation
+	var restarter server.NodeRestarter
+	if fm.nodeManager != nil {
+		restarter = keyActivator{fm}
+	}
+	fm.server = server.NewServer(fm.cfg, fm.stateManager, fm.keyManager, fm.healthChecker, fm, restarter, fm.keyring, fm.secrets, fm.client, fm.journal, fm)
+	fm.server.SetNodeStatusSource(fm.healthChecker)
+	if fm.tlsConfig != nil {
+		fm.server.SetTLS(fm.tlsConfig)
+	}
+	if fm.elector != nil {
+		fm.server.SetLeaseVoter(fm.elector)
+	}
+	if fm.artifacts = newArtifacts(fm.cfg); fm.artifacts != nil {
+		fm.server.SetArtifacts(fm.artifacts)
+	}
+	fm.server.SetActivationGuard(fm)
 	go func() {
 		if err := fm.server.Start(); err != nil {
 			fm.logger.Error("Server error: %v", err)
 		}
 	}()
 
+	if fm.cfg.Admin.Listen != "" {
+		fm.adminServer = server.NewAdminServer(fm.cfg, fm.healthChecker, fm, fm.client, fm.drills, fm, fm)
+		go func() {
+			if err := fm.adminServer.Start(); err != nil {
+				fm.logger.Error("Admin server error: %v", err)
+			}
+		}()
+	}
+
+	if fm.cfg.Health.ProbePeersOnStart {
+		supervise.Once(fm.logger, "peer-probe", fm.probePeers)
+	}
+	supervise.Go(fm.logger, "peer-handshake", fm.stopCh, fm.handshakeWithPeers)
+	supervise.Go(fm.logger, "peer-state", fm.stopCh, fm.savePeerStates)
+	if fm.cfg.PeerAPI.DNSRefresh > 0 {
+		supervise.Go(fm.logger, "peer-dns", fm.stopCh, fm.resolvePeers)
+	}
+
+	if fm.identity != nil {
+		fm.logger.Info("Node identity public key: %s", fm.identity.PublicKey())
+		supervise.Once(fm.logger, "peer-enroll", fm.enrollWithPeers)
+	}
+
 	return nil
 }
 
-// Stop gracefully stops the failover manager
+// prepareKey puts the key files, the lock and the standby node in the
+// state the configured role needs before the node starts
+func (fm *FailoverManager) prepareKey(restored bool) error {
+	// Reconcile a key swap a crash interrupted, before a missing key file
+	// makes InitializeKey generate a new key
+	action, err := fm.keyManager.RecoverKeySwap()
+	if err != nil {
+		return fmt.Errorf("failed to recover interrupted key swap: %w", err)
+	}
+	if action != "" {
+		fm.logger.Warn("Key files: %s", action)
+		fm.alert(notify.EventRecovery, notify.SeverityWarning, "Key files: "+action, nil)
+	}
+
+	// Initialize key
+	if err := fm.keyManager.InitializeKey(); err != nil {
+		return fmt.Errorf("failed to initialize key: %w", err)
+	}
+	if err := fm.keyManager.SecureKeyFiles(); err != nil {
+		return err
+	}
+	if err := fm.restoreRole(); err != nil {
+		return err
+	}
+
+	// Finish or roll back a transition a crash interrupted, before the
+	// node starts with whatever key is on disk
+	if err := fm.recoverTransition(); err != nil {
+		return fmt.Errorf("failed to recover interrupted transition: %w", err)
+	}
+	if err := fm.guardFirstBoot(restored); err != nil {
+		return err
+	}
+	if err := fm.guardReboot(); err != nil {
+		return err
+	}
+	if err := fm.holdForMaintenance(); err != nil {
+		return err
+	}
+	if err := fm.claimLock(); err != nil {
+		return err
+	}
+	return fm.preheat()
+}
+
+// Stop gracefully stops the failover manager, once the transition in
+// flight has finished
 func (fm *FailoverManager) Stop() {
 	close(fm.stopCh)
-	fm.stateManager.ReleaseLock()
+	fm.transitions.Stop()
+	fm.drills.Stop()
+	fm.savePeerState()
+	fm.summarizeSigning(true)
+	fm.signatures.Stop()
 	// Stop the validator node if wrapper is enabled
 	if fm.nodeManager != nil {
 		if err := fm.nodeManager.Stop(); err != nil {
 			fm.logger.Error("Failed to stop validator node: %v", err)
 		}
 	}
+	if fm.signer != nil {
+		fm.signer.Stop()
+	}
+	// Only a node that stopped signing may give up the lock, and with it
+	// the election lease
+	fm.stateManager.ReleaseLock()
+	fm.alerts.Close()
 }
 
 // monitorHealth continuously monitors node health
 func (fm *FailoverManager) monitorHealth() {
-	ticker := time.NewTicker(time.Duration(fm.cfg.Health.Interval * float64(time.Second)))
+	interval := fm.healthChecker.NextInterval()
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+	fm.scheduleCheck(interval)
+
+	for {
+		select {
+		case <-timer.C:
+			fm.performHealthCheck()
+			fm.Progress()
+			fm.DowntimeBudget().Publish()
+			deprecation.Remind()
+			// The interval stretches while the CometBFT RPC is slow
+			interval = fm.healthChecker.NextInterval()
+			timer.Reset(interval)
+			fm.scheduleCheck(interval)
+		case <-fm.stopCh:
+			return
+		}
+	}
+}
+
+// monitorSelf periodically checks syncguard's own resource usage
+func (fm *FailoverManager) monitorSelf() {
+	ticker := time.NewTicker(fm.cfg.SelfMonitor.Interval.Duration())
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ticker.C:
-			fm.performHealthCheck()
+			_, issues := fm.selfMonitor.Check()
+			for _, issue := range issues {
+				fm.logger.Warn("Self-monitor: %s (%s)", issue.Message, issue.Value)
+				fm.alert(notify.EventSelfDegraded, notify.SeverityWarning, issue.Message,
+					map[string]string{"resource": issue.Resource, "value": issue.Value})
+			}
 		case <-fm.stopCh:
 			return
 		}
@@ -174,9 +567,11 @@ func (fm *FailoverManager) monitorHealth() {
 
 // performHealthCheck executes health check and handles failures
 func (fm *FailoverManager) performHealthCheck() {
+	fm.keepStandbyRunning()
 	nodeHealth, err := fm.healthChecker.PerformHealthCheck()
 	if err != nil {
 		fm.logger.Error("Health check error: %v", err)
+		fm.assessStandby(nil)
 		fm.handleHealthCheckFailure()
 		return
 	}
@@ -186,10 +581,53 @@ func (fm *FailoverManager) performHealthCheck() {
 	if fm.isActive {
 		role = constants.NodeStatusActive
 	}
-	fm.logger.Info("[%s] height=%d peers=%d healthy=%v",
-		role, nodeHealth.LatestHeight, nodeHealth.PeerCount, fm.healthChecker.IsHealthy())
+	state := fm.healthChecker.State()
+	fm.logger.Info("[%s] height=%d peers=%d health=%s",
+		role, nodeHealth.LatestHeight, nodeHealth.PeerCount, state)
+
+	// An unknown signing status has its own alert, whatever it counts as
+	fm.trackHealth(state != health.StateUnhealthy, nodeHealth.LatestHeight, nodeHealth.PeerCount)
+	fm.trackUnknown(state)
+	fm.recordHealthCommand(nodeHealth.Command)
+	fm.assessStandby(nodeHealth)
+	fm.sampleHost()
+	fm.checkTrends()
+	if fm.cfg.Health.Heartbeat.Enabled && fm.IsActive() {
+		report := health.NewReport(fm.cfg.Node.ID, nodeHealth)
+		report.Progress = fm.Progress()
+		report.Transitions = fm.TransitionSummary()
+		supervise.Once(fm.logger, "heartbeat", func() { fm.sendHeartbeats(report) })
+	}
+	fm.checkHeartbeat()
+
+	// Blocks without our precommits, or a passive that sees this node
+	// lagging, override a passing check
+	if missed := fm.notSigning(); missed > 0 && fm.healthChecker.IsHealthy() {
+		fm.logger.Warn("Health check passed but the last %d blocks lack our precommit, counting it as failed", missed)
+		fm.handleHealthCheckFailure()
+		return
+	}
+	if disputed := fm.disputedHealth(); disputed != "" && fm.healthChecker.IsHealthy() {
+		fm.logger.Warn("Health check passed but a passive peer disputes it, counting it as failed: %s", disputed)
+		fm.handleHealthCheckFailure()
+		return
+	}
+	if fm.stateChurnDropped() && fm.healthChecker.IsHealthy() {
+		fm.logger.Warn("Health check passed but the validator state advances far slower than usual, counting it as failed")
+		fm.handleHealthCheckFailure()
+		return
+	}
+
+	if state == health.StateUnknown && fm.cfg.Health.Unknown == "hold" {
+		fm.logger.Warn("Health check passed but the signing status is unknown, holding the failure count")
+		return
+	}
 
 	if fm.healthChecker.IsHealthy() {
+		// Only the signing node pings: if it dies, the external service notices
+		if fm.pinger != nil && fm.IsActive() {
+			fm.pinger.Ping()
+		}
 		fm.handleHealthCheckSuccess()
 	} else {
 		fm.logger.Warn("Node unhealthy - Syncing: %v, Height: %d, Peers: %d",
@@ -202,18 +640,38 @@ func (fm *FailoverManager) performHealthCheck() {
 func (fm *FailoverManager) handleHealthCheckSuccess() {
 	fm.mu.Lock()
 	fm.failureCount = 0
+	fm.outageStart = time.Time{}
+	fm.riskAlerted = false
+	fm.promotionAdvised = false
 	fm.mu.Unlock()
+	fm.endDowntime()
+	fm.withdrawApproval()
+	fm.failback.Observe(true, time.Now())
 
 	// If we're primary site and not active, consider failback (only start one goroutine)
 	fm.mu.RLock()
 	alreadyInProgress := fm.failbackInProgress
 	fm.mu.RUnlock()
 
+	// A drill fails back on its own schedule
+	if !fm.automated() || fm.drills.Running() || fm.IsPaused() || fm.AwaitingConfirmation() || fm.IsDraining() {
+		return
+	}
+
 	if fm.isPrimarySite && !fm.isActive && !alreadyInProgress {
+		// In sticky mode the standby keeps the role until an operator fails back
+		if !fm.failback.Automatic() {
+			fm.logger.Debug("Failback not automatic: sticky_active is set")
+			return
+		}
+		if ok, reason := fm.failback.Stable(time.Now()); !ok {
+			fm.logger.Debug("Failback not yet: %s", reason)
+			return
+		}
 		fm.mu.Lock()
 		fm.failbackInProgress = true
 		fm.mu.Unlock()
-		go fm.considerFailback()
+		supervise.Once(fm.logger, "failback", fm.considerFailback)
 	}
 }
 
@@ -223,17 +681,79 @@ func (fm *FailoverManager) handleHealthCheckFailure() {
 	fm.failureCount++
 	failureCount := fm.failureCount
 	fm.mu.Unlock()
+	fm.failback.Observe(false, time.Now())
+
+	// Repeated failures are folded into one summary by the dispatcher
+	fm.alert(notify.EventHealthCheckFailed, notify.SeverityInfo, "Health check failed",
+		map[string]string{"consecutive_failures": fmt.Sprintf("%d", failureCount)})
+	if fm.IsActive() {
+		fm.downtime.Begin(time.Now())
+	}
+	fm.assessDowntimeRisk()
 
 	if failureCount >= fm.cfg.Failover.RetryAttempts {
+		if !fm.automated() {
+			fm.reportWouldFailOver(failureCount)
+			return
+		}
+		if fm.IsPaused() {
+			fm.logger.Warn("Maximum failures reached, but automatic failover is paused")
+			return
+		}
+		if fm.isActive && fm.coldStandby != nil {
+			fm.advisePromotion()
+			return
+		}
 		if fm.isActive {
-			fm.logger.Error("Maximum failures reached, initiating failover")
-			fm.initiateFailover()
+			reason := fm.failureReason()
+			if fm.approval != nil {
+				fm.awaitApproval(reason)
+				return
+			}
+			fm.logger.Error("Maximum failures reached, initiating failover (%s)", reason)
+			fm.failOver(reason, "health")
 		}
 	}
 }
 
-// initiateFailover handles the failover from active to passive
-func (fm *FailoverManager) initiateFailover() {
+// failOver releases validator duties after a failure and lets the linked
+// instances follow
+func (fm *FailoverManager) failOver(reason constants.Reason, source string) {
+	fm.initiateFailover(context.Background(), reason, source)
+	// The standby signs from here on
+	if !fm.IsActive() {
+		fm.endDowntime()
+		supervise.Once(fm.logger, "cascade", func() { fm.cascadeFailover(reason) })
+	}
+}
+
+// initiateFailover hands validator duties to the peer. It runs on the
+// transition executor, which joins it with a failover already in flight
+// and refuses it during a failback; source names the requester. Once ctx
+// is done the key handoff is called off, unless the standby already
+// holds the key and has been told so.
+func (fm *FailoverManager) initiateFailover(ctx context.Context, reason constants.Reason, source string) error {
+	if !fm.automated() {
+		fm.logger.Warn("Failover (%s) requested by %s not carried out: %v", reason, source, errMonitorOnly)
+		return errMonitorOnly
+	}
+	err := fm.transitions.Submit(transition.Request{
+		Kind:   transition.KindRelease,
+		Reason: string(reason),
+		Source: source,
+		Run: func() error {
+			fm.releaseDuties(ctx, reason)
+			return nil
+		},
+	})
+	if err != nil {
+		fm.logger.Warn("Failover (%s) requested by %s not carried out: %v", reason, source, err)
+	}
+	return err
+}
+
+// releaseDuties handles the failover from active to passive
+func (fm *FailoverManager) releaseDuties(ctx context.Context, reason constants.Reason) {
 	fm.mu.Lock()
 	defer fm.mu.Unlock()
 
@@ -241,39 +761,85 @@ func (fm *FailoverManager) initiateFailover() {
 		return
 	}
 
-	fm.logger.Info("Initiating failover - releasing validator duties")
+	transition := logger.StartTransition()
+	defer logger.EndTransition(transition)
+	fm.logger.Info("Initiating failover (%s) - releasing validator duties", reason)
 
-	// Transfer key to peer before releasing
-	if err := fm.transferKeyToPeer(); err != nil {
-		fm.logger.Error("Failed to transfer key to peer: %v", err)
-		// Continue with failover anyway
+	// Giving up signing is safe without a journal, so failover goes ahead
+	if err := fm.journal.Begin(state.TransitionRelease); err != nil {
+		fm.logger.Error("Failed to journal failover: %v", err)
 	}
 
-	// Disable local key
-	if err := fm.keyManager.DeleteKey(); err != nil {
-		fm.logger.Error("Failed to disable local key: %v", err)
+	// With the lock or lease gone another node may sign once it runs out,
+	// and the handoff can take longer than that: stop signing first
+	stopped := mustStopSigning(reason)
+	if stopped {
+		fm.stopSigning()
 	}
 
-	// Restart node to pick up disabled key
-	if fm.nodeManager != nil {
-		if err := fm.nodeManager.Restart(); err != nil {
-			fm.logger.Error("Failed to restart node: %v", err)
+	// Transfer key to the standby before releasing
+	target := fm.standbyPeer()
+	if target.ID != "" {
+		fm.logger.Info("Handing over to %s", target.ID)
+	}
+	if err := fm.journal.Release(state.StepTransferKey, func() error { return fm.transferKeyToPeer(ctx, target) }); err != nil {
+		fm.logger.Error("Failed to transfer key to peer: %v", err)
+		// Without a standby confirmed to hold the key, releasing would
+		// leave no node able to sign. Only a lost lock or lease, where
+		// another node may already sign, releases regardless.
+		if target.Address != "" && !mustStopSigning(reason) {
+			fm.endTransition()
+			fm.alert(notify.EventKeyTransfer, notify.SeverityCritical,
+				"Failover aborted: the standby did not confirm it holds the validator key - node stays active",
+				map[string]string{"peer": target.ID, "error": err.Error()})
+			return
+		}
+		fm.alert(notify.EventKeyTransfer, notify.SeverityCritical, "Failed to transfer validator key to peer during failover",
+			map[string]string{"error": err.Error()})
+	}
+	fm.transferArtifactsToPeer(target)
+
+	switch {
+	case stopped && fm.nodeManager != nil && fm.signer == nil:
+		// Start the stopped node again on the disabled key
+		if err := fm.journal.Release(state.StepRestartNode, fm.nodeManager.Start); err != nil {
+			fm.logger.Error("Failed to start node: %v", err)
+		}
+	case !stopped:
+		// Disable local key
+		if err := fm.journal.Release(state.StepDisableKey, fm.keyManager.DeleteKey); err != nil {
+			fm.logger.Error("Failed to disable local key: %v", err)
+		}
+
+		// Have the node pick up the disabled key
+		if fm.nodeManager != nil {
+			if err := fm.journal.Release(state.StepRestartNode, fm.activateKey); err != nil {
+				fm.logger.Error("Failed to restart node: %v", err)
+			}
 		}
 	}
+	fm.recordSignature()
 
-	if err := fm.stateManager.ReleaseLock(); err != nil {
+	if err := fm.journal.Release(state.StepReleaseLock, fm.stateManager.ReleaseLock); err != nil {
 		fm.logger.Error("Failed to release state lock: %v", err)
 	}
 
-	fm.notifyPeerOfFailover()
+	fm.journal.Release(state.StepNotifyPeer, func() error {
+		fm.notifyPeerOfFailover(target, reason)
+		return nil
+	})
+	fm.endTransition()
 
 	fm.isActive = false
+	fm.updateWatermark(false)
 	fm.failureCount = 0
 
 	fm.logger.Info("Failover complete - node is now passive")
+	fm.transitionAlert(notify.EventFailover, notify.SeverityCritical, "Failover complete - node is now passive", reason, nil)
 }
 
-// considerFailback evaluates whether to fail back to primary
+// considerFailback fails back once the primary is stable and caught up
+// with the chain; a failed attempt holds off the next one
 func (fm *FailoverManager) considerFailback() {
 	defer func() {
 		fm.mu.Lock()
@@ -281,82 +847,218 @@ func (fm *FailoverManager) considerFailback() {
 		fm.mu.Unlock()
 	}()
 
-	fm.mu.RLock()
-	isActive := fm.isActive
-	fm.mu.RUnlock()
+	if fm.IsActive() {
+		return
+	}
 
-	if isActive {
+	if ok, reason := fm.caughtUp(); !ok {
+		fm.logger.Info("Failback deferred: %s", reason)
 		return
 	}
 
-	time.Sleep(time.Duration(fm.cfg.Failover.GracePeriod * float64(time.Second)))
+	fm.logger.Info("Primary node stable, initiating failback")
+	if err := fm.initiateFailback(constants.ReasonPrimaryRecovered, "failback"); err != nil {
+		wait := fm.failback.Failed(time.Now())
+		fm.logger.Warn("Failback failed, next attempt in %s: %v", wait, err)
+		return
+	}
+	fm.failback.Succeeded()
+}
 
-	if fm.healthChecker.IsHealthy() {
-		fm.logger.Info("Primary node healthy, initiating failback")
-		fm.initiateFailback()
+// caughtUp reports whether this node is close enough to the chain tip to
+// fail back; the highest height a peer reports is the best view of the tip
+func (fm *FailoverManager) caughtUp() (bool, string) {
+	height := fm.healthChecker.GetLastHeight()
+	tip := height
+	for _, view := range fm.surveyPeers() {
+		if view.health != nil && view.health.Height > tip {
+			tip = view.health.Height
+		}
 	}
+	return fm.failback.CaughtUp(height, tip)
 }
 
-// initiateFailback handles failing back to primary node
-func (fm *FailoverManager) initiateFailback() {
+// initiateFailback takes validator duties back from the peer on the
+// transition executor; source names the requester
+func (fm *FailoverManager) initiateFailback(reason constants.Reason, source string) error {
+	if !fm.automated() {
+		fm.logger.Warn("Failback (%s) requested by %s not carried out: %v", reason, source, errMonitorOnly)
+		return errMonitorOnly
+	}
+	err := fm.transitions.Submit(transition.Request{
+		Kind:   transition.KindAcquire,
+		Reason: string(reason),
+		Source: source,
+		Run:    func() error { return fm.acquireDuties(reason) },
+	})
+	if errors.Is(err, transition.ErrContradictory) || errors.Is(err, transition.ErrStopped) {
+		fm.logger.Warn("Failback (%s) requested by %s not carried out: %v", reason, source, err)
+	}
+	return err
+}
+
+// acquireDuties handles failing back to primary node
+func (fm *FailoverManager) acquireDuties(reason constants.Reason) error {
 	fm.mu.Lock()
 	defer fm.mu.Unlock()
 
 	if fm.isActive {
-		return
+		return nil
 	}
 
-	fm.logger.Info("Initiating failback to primary")
+	if fm.lockUnreachable() {
+		fm.logger.Warn("Lock backend unreachable, refusing failback")
+		return fmt.Errorf("lock backend unreachable")
+	}
+	if err := fm.checkMaintenance(); err != nil {
+		fm.logger.Warn("Refusing failback: %v", err)
+		return err
+	}
+	if err := fm.witnessAgrees(fm.sourcePeer().ID); err != nil {
+		fm.logger.Warn("Refusing failback: %v", err)
+		return err
+	}
+
+	transition := logger.StartTransition()
+	defer logger.EndTransition(transition)
+	fm.logger.Info("Initiating failback to primary (%s)", reason)
+
+	if err := fm.journal.Begin(state.TransitionAcquire); err != nil {
+		fm.logger.Error("Failed to journal failback, not failing back: %v", err)
+		return err
+	}
 
-	// Request key from peer (current active) before we take over
-	if err := fm.requestKeyFromPeer(); err != nil {
+	// Request key from the active peer before we take over
+	source := fm.sourcePeer()
+	if err := fm.journal.Step(state.StepFetchKey, func() error { return fm.requestKeyFromPeer(source) }); err != nil {
 		fm.logger.Error("Failed to get key from peer: %v", err)
-		return
+		fm.alert(notify.EventKeyTransfer, notify.SeverityWarning, "Failback aborted: could not get validator key from peer",
+			map[string]string{"error": err.Error()})
+		fm.endTransition()
+		return err
 	}
+	fm.fetchArtifactsFromPeer(source)
 
-	if err := fm.stateManager.AcquireLock(); err != nil {
+	peerNotified := false
+	if err := fm.journal.Step(state.StepAcquireLock, func() (err error) {
+		peerNotified, err = fm.acquireLock(reason)
+		return err
+	}); err != nil {
 		fm.logger.Error("Failed to acquire state lock: %v", err)
-		return
+		fm.rollBackAcquire(true, false)
+		return err
 	}
 
-	if err := fm.syncStateFromPeer(); err != nil {
+	if err := fm.journal.Step(state.StepSyncState, func() error { return fm.syncStateFromPeer(true) }); err != nil {
 		fm.logger.Error("Failed to sync state from peer: %v", err)
-		fm.stateManager.ReleaseLock()
-		return
+		fm.rollBackAcquire(true, true)
+		return err
+	}
+	if err := fm.checkChainSignatures(); err != nil {
+		fm.logger.Error("Refusing failback: %v", err)
+		fm.rollBackAcquire(true, true)
+		return err
+	}
+	if err := fm.checkSignedState(); err != nil {
+		fm.logger.Error("Refusing failback: %v", err)
+		fm.rollBackAcquire(true, true)
+		return err
 	}
 
-	// Restart node to pick up the new key
+	// Have the node pick up the new key
 	if fm.nodeManager != nil {
-		if err := fm.nodeManager.Restart(); err != nil {
+		if err := fm.journal.Step(state.StepRestartNode, fm.activateKey); err != nil {
 			fm.logger.Error("Failed to restart node: %v", err)
-			fm.stateManager.ReleaseLock()
-			return
+			fm.rollBackAcquire(true, true)
+			return err
 		}
 	}
 
 	// Notify peer to release (they will swap their key to mock)
-	fm.notifyPeerOfFailback()
+	fm.journal.Step(state.StepNotifyPeer, func() error {
+		if !peerNotified {
+			fm.notifyPeerOfFailback(reason)
+		}
+		return nil
+	})
+	fm.endTransition()
 
 	fm.isActive = true
+	fm.updateWatermark(true)
 	fm.failureCount = 0
 
 	fm.logger.Info("Failback complete - node is now active")
+	fm.transitionAlert(notify.EventFailback, notify.SeverityWarning, "Failback complete - node is now active", reason, nil)
+	return nil
 }
 
-// syncValidatorState periodically syncs validator state when passive
+// trackHealth alerts when the node's health changes
+func (fm *FailoverManager) trackHealth(healthy bool, height int64, peers int) {
+	fm.mu.Lock()
+	changed := healthy != fm.wasHealthy
+	fm.wasHealthy = healthy
+	fm.mu.Unlock()
+
+	if !changed {
+		return
+	}
+
+	fields := map[string]string{
+		"height": fmt.Sprintf("%d", height),
+		"peers":  fmt.Sprintf("%d", peers),
+	}
+	if healthy {
+		fm.alert(notify.EventHealthChanged, notify.SeverityInfo, "Node recovered and is healthy", fields)
+	} else {
+		fm.alert(notify.EventHealthChanged, notify.SeverityWarning, "Node became unhealthy", fields)
+	}
+}
+
+// alert records an event in the history and emits it to the alert sinks
+func (fm *FailoverManager) alert(eventType notify.EventType, severity notify.Severity, message string, fields map[string]string) {
+	fm.emit(history.Entry{
+		Kind:     history.KindEvent,
+		Type:     string(eventType),
+		NodeID:   fm.cfg.Node.ID,
+		Severity: severity.String(),
+		Message:  message,
+		Fields:   fields,
+	}, severity)
+}
+
+// emit records an event entry in the history and sends it to the alert sinks
+func (fm *FailoverManager) emit(entry history.Entry, severity notify.Severity) {
+	if err := fm.history.Append(entry); err != nil {
+		fm.logger.Warn("Failed to record event history: %v", err)
+	}
+
+	fm.alerts.Emit(notify.Event{
+		Type:     notify.EventType(entry.Type),
+		Severity: severity,
+		Message:  entry.Message,
+		Reason:   entry.Reason,
+		Fields:   entry.Fields,
+	})
+}
+
+// syncValidatorState periodically syncs validator state when passive,
+// unless the state streams in from the peer
 func (fm *FailoverManager) syncValidatorState() {
-	ticker := time.NewTicker(time.Duration(fm.cfg.Failover.StateSyncInterval * float64(time.Second)))
+	interval := fm.cfg.Failover.StateSyncInterval.Duration()
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
+	fm.scheduleSync(interval)
 
 	for {
 		select {
 		case <-ticker.C:
+			fm.scheduleSync(interval)
 			fm.mu.RLock()
 			isActive := fm.isActive
 			fm.mu.RUnlock()
 
-			if !isActive {
-				if err := fm.syncStateFromPeer(); err != nil {
+			if !isActive && !fm.stateStreaming.Load() {
+				if err := fm.syncStateFromPeer(false); err != nil {
 					fm.logger.Error("State sync error: %v", err)
 				}
 			}
@@ -366,138 +1068,331 @@ func (fm *FailoverManager) syncValidatorState() {
 	}
 }
 
-// syncStateFromPeer fetches and syncs validator state from peer
-func (fm *FailoverManager) syncStateFromPeer() error {
+// syncStateFromPeer fetches and syncs validator state from peer.
+// Transitions pass fresh to bypass the peer's response cache.
+func (fm *FailoverManager) syncStateFromPeer(fresh bool) error {
 	if len(fm.cfg.Peers) == 0 {
 		return fmt.Errorf("no peer configured")
 	}
 
-	peerAddr := fm.cfg.Peers[0].Address
-	url := fmt.Sprintf("http://%s/validator_state", peerAddr)
-
-	resp, err := http.Get(url)
+	remoteState, link, err := fm.client.FetchState(fm.sourcePeer().Address, fresh)
 	if err != nil {
-		return fmt.Errorf("failed to fetch state from peer: %w", err)
+		return err
 	}
-	defer resp.Body.Close()
+	return fm.applyRemoteState(remoteState, link, fresh)
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("peer returned status %d", resp.StatusCode)
+// applyRemoteState takes the peer's validator state once its provenance
+// checks out. A state fetched through the cache that needs a resync is
+// fetched again fresh.
+func (fm *FailoverManager) applyRemoteState(remoteState *state.ValidatorState, link *state.Link, fresh bool) error {
+	accept, resync := fm.checkProvenance(link, remoteState)
+	if resync && !fresh {
+		return fm.syncStateFromPeer(true)
 	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read response: %w", err)
+	if !accept {
+		return fmt.Errorf("state provenance check failed")
 	}
 
-	var remoteState state.ValidatorState
-	if err := json.Unmarshal(body, &remoteState); err != nil {
-		return fmt.Errorf("failed to parse remote state: %w", err)
+	if err := fm.stateManager.SyncFromRemote(remoteState); err != nil {
+		return err
 	}
 
-	return fm.stateManager.SyncFromRemote(&remoteState)
+	fm.updateWatermark(false)
+	return nil
 }
 
-// notifyPeerOfFailover notifies the peer node that we're failing over
-func (fm *FailoverManager) notifyPeerOfFailover() {
-	if len(fm.cfg.Peers) == 0 {
+// updateWatermark publishes the signing gate consulted by the node shim.
+// The minimum height follows the replicated validator state so a node that
+// takes over can never sign below what the cluster already signed.
+func (fm *FailoverManager) updateWatermark(active bool) {
+	if fm.watermark == nil {
 		return
 	}
 
-	peerAddr := fm.cfg.Peers[0].Address
-	url := fmt.Sprintf("http://%s/failover_notify", peerAddr)
-
-	req, _ := http.NewRequest(http.MethodPost, url, nil)
-	client := &http.Client{Timeout: 5 * time.Second}
+	var minHeight int64
+	if current := fm.stateManager.GetCurrentState(); current != nil {
+		minHeight = current.Height
+	}
 
-	if _, err := client.Do(req); err != nil {
-		fm.logger.Error("Failed to notify peer of failover: %v", err)
+	if err := fm.watermark.Update(active, minHeight); err != nil {
+		fm.logger.Error("Failed to update signing watermark: %v", err)
 	}
 }
 
-// notifyPeerOfFailback notifies the peer node that we're failing back
-func (fm *FailoverManager) notifyPeerOfFailback() {
-	if len(fm.cfg.Peers) == 0 {
+// notifyPeerOfFailover tells the standby to take over
+func (fm *FailoverManager) notifyPeerOfFailover(target config.PeerConfig, reason constants.Reason) {
+	if target.Address == "" {
 		return
 	}
 
-	peerAddr := fm.cfg.Peers[0].Address
-	url := fmt.Sprintf("http://%s/failback_notify", peerAddr)
+	if err := fm.client.Notify(target.Address, communication.PathFailoverNotify, string(reason)); err != nil {
+		fm.logger.Error("Failed to notify %s of failover: %v", target.ID, err)
+	}
+}
 
-	req, _ := http.NewRequest(http.MethodPost, url, nil)
-	client := &http.Client{Timeout: 5 * time.Second}
+// notifyPeerOfFailback tells the active peer to release, as we fail back
+func (fm *FailoverManager) notifyPeerOfFailback(reason constants.Reason) {
+	source := fm.sourcePeer()
+	if source.Address == "" {
+		return
+	}
 
-	if _, err := client.Do(req); err != nil {
-		fm.logger.Error("Failed to notify peer of failback: %v", err)
+	if err := fm.client.Notify(source.Address, communication.PathFailbackNotify, string(reason)); err != nil {
+		fm.logger.Error("Failed to notify %s of failback: %v", source.ID, err)
 	}
 }
 
-// transferKeyToPeer sends the validator key to the peer node
-func (fm *FailoverManager) transferKeyToPeer() error {
-	if len(fm.cfg.Peers) == 0 {
+// transferKeyToPeer hands the validator key to the standby in two
+// phases: the standby stages the key and echoes its digest, then writes
+// it and reads back its address. Any other answer aborts the handoff, so
+// the standby drops the key again. A standby that predates the handoff
+// gets the key in a single request. Once ctx is done the handoff is
+// aborted instead of committed, or after the commit.
+func (fm *FailoverManager) transferKeyToPeer(ctx context.Context, target config.PeerConfig) error {
+	// Every node keeps the key for the remote signer
+	if fm.signer != nil {
+		return nil
+	}
+	if target.Address == "" {
 		return fmt.Errorf("no peer configured")
 	}
 
-	signature := crypto.Sign(constants.AuthPayloadValidatorKey, fm.cfg.Secret)
-	fm.logger.Info("Sending validator key to peer with signature: %s", signature)
-
-	keyData, err := fm.keyManager.EncryptKeyToBytes(fm.cfg.Secret)
+	keyData, err := fm.keyManager.RealKeyToBytes()
+	if err != nil {
+		return fmt.Errorf("failed to read key: %w", err)
+	}
+	sealed, err := crypto.Encrypt(keyData, fm.secrets.Current())
 	if err != nil {
 		return fmt.Errorf("failed to encrypt key: %w", err)
 	}
+	if !fm.handoff.Supports(target.Address, communication.FeatureKeyHandoff) {
+		return fm.sendKey(target, sealed)
+	}
 
-	peerAddr := fm.cfg.Peers[0].Address
-	url := fmt.Sprintf("http://%s/validator_key", peerAddr)
-
-	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(keyData))
+	id := logger.TransitionID()
+	if id == "" {
+		id = strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	digest := communication.KeyDigest(keyData)
+	ack, err := fm.handoff.PrepareKeyHandoff(target.Address, communication.KeyHandoff{ID: id, SealedKey: sealed, Digest: digest})
+	if communication.StatusCode(err) == http.StatusNotFound {
+		return fm.sendKey(target, sealed)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return fmt.Errorf("standby did not take the key: %w", err)
+	}
+	if ack.Digest != digest {
+		fm.abortKeyHandoff(target, id)
+		return fmt.Errorf("standby staged a key with digest %s, sent %s", ack.Digest, digest)
 	}
-	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+	var key state.ValidatorKey
+	if err := json.Unmarshal(keyData, &key); err != nil {
+		fm.abortKeyHandoff(target, id)
+		return fmt.Errorf("failed to parse key: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		fm.abortKeyHandoff(target, id)
+		return fmt.Errorf("handoff called off before the commit: %w", err)
+	}
+	ack, err = fm.handoff.CommitKeyHandoff(target.Address, id)
 	if err != nil {
-		return fmt.Errorf("failed to send key: %w", err)
+		fm.abortKeyHandoff(target, id)
+		return fmt.Errorf("standby did not confirm it wrote the key: %w", err)
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("peer returned status %d", resp.StatusCode)
+	if !ack.Written || ack.Address != key.Address {
+		fm.abortKeyHandoff(target, id)
+		return fmt.Errorf("standby wrote key %q, sent %s", ack.Address, key.Address)
+	}
+	if err := ctx.Err(); err != nil {
+		fm.abortKeyHandoff(target, id)
+		return fmt.Errorf("handoff called off after the commit: %w", err)
 	}
 
-	fm.logger.Info("Successfully transferred validator key to peer")
+	fm.logger.Info("Standby %s confirmed it holds validator key %s", target.ID, key.Address)
 	return nil
 }
 
-// requestKeyFromPeer requests the validator key from peer during failback
-func (fm *FailoverManager) requestKeyFromPeer() error {
-	if len(fm.cfg.Peers) == 0 {
-		return fmt.Errorf("no peer configured")
+// keyHandoffClient is the part of the peer client that hands the key to
+// the standby
+type keyHandoffClient interface {
+	Supports(addr, feature string) bool
+	SendKey(addr string, keyData []byte) error
+	PrepareKeyHandoff(addr string, h communication.KeyHandoff) (*communication.KeyHandoffAck, error)
+	CommitKeyHandoff(addr, id string) (*communication.KeyHandoffAck, error)
+	AbortKeyHandoff(addr, id string) error
+}
+
+// sendKey posts the sealed key in a single request, to a standby that
+// predates the two-phase handoff
+func (fm *FailoverManager) sendKey(target config.PeerConfig, sealed []byte) error {
+	if err := fm.handoff.SendKey(target.Address, sealed); err != nil {
+		return err
 	}
+	fm.logger.Info("Successfully transferred validator key to %s", target.ID)
+	return nil
+}
 
-	peerAddr := fm.cfg.Peers[0].Address
-	url := fmt.Sprintf("http://%s/validator_key", peerAddr)
+// abortKeyHandoff has the standby drop a key it did not confirm. When it
+// cannot be reached it may keep the key on disk, still unused by its node
+// until a takeover; the invariant checker reports it.
+func (fm *FailoverManager) abortKeyHandoff(target config.PeerConfig, id string) {
+	if err := fm.handoff.AbortKeyHandoff(target.Address, id); err != nil {
+		fm.logger.Error("Failed to abort key handoff %s with %s: %v", id, target.ID, err)
+	}
+}
 
-	resp, err := http.Get(url)
-	if err != nil {
-		return fmt.Errorf("failed to request key from peer: %w", err)
+// stopSigning disables the local key and stops the node without waiting
+// on any peer, for a release that must not outlast the lock or lease. A
+// remote signer stops signing as soon as the release holds fm.mu, and its
+// node never had the key.
+func (fm *FailoverManager) stopSigning() {
+	fm.updateWatermark(false)
+	if err := fm.journal.Release(state.StepDisableKey, fm.keyManager.DeleteKey); err != nil {
+		fm.logger.Error("Failed to disable local key: %v", err)
 	}
-	defer resp.Body.Close()
+	if fm.nodeManager == nil || fm.signer != nil {
+		return
+	}
+	if err := fm.nodeManager.Stop(); err != nil {
+		fm.logger.Error("Failed to stop node: %v", err)
+	}
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("peer returned status %d", resp.StatusCode)
+// mustStopSigning reports whether a release goes ahead even when the
+// standby did not confirm it holds the key: the lock or lease is gone,
+// so another node may already sign
+func mustStopSigning(reason constants.Reason) bool {
+	return reason == constants.ReasonWatchdog || reason == constants.ReasonLeaseLost
+}
+
+// requestKeyFromPeer requests the validator key from the active peer
+// during failback
+func (fm *FailoverManager) requestKeyFromPeer(source config.PeerConfig) error {
+	// The remote signer's key never left this node
+	if fm.signer != nil {
+		if fm.keyManager.IsDisabled() {
+			return fm.keyManager.RestoreKey()
+		}
+		return nil
+	}
+	if source.Address == "" {
+		return fmt.Errorf("no peer configured")
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := fm.client.FetchKey(source.Address)
 	if err != nil {
-		return fmt.Errorf("failed to read key: %w", err)
+		return err
 	}
 
-	if err := fm.keyManager.DecryptKeyFromBytes(body, fm.cfg.Secret); err != nil {
-		return fmt.Errorf("failed to decrypt key: %w", err)
+	keyData, plaintext, err := communication.UnsealKey(fm.secrets, body, fm.cfg.Failover.AllowPlaintextKey)
+	if err != nil {
+		return fmt.Errorf("failed to take key from %s: %w", source.ID, err)
+	}
+	if plaintext {
+		fm.logger.Warn("Took a validator key from %s sent in the clear (failover.allow_plaintext_key)", source.ID)
+	}
+	if err := fm.keyManager.KeyFromBytes(keyData); err != nil {
+		return err
 	}
 
-	fm.logger.Info("Successfully retrieved validator key from peer")
+	fm.logger.Info("Successfully retrieved validator key from %s", source.ID)
 	return nil
 }
+
+// probePeers checks every peer once at startup and explains failures
+func (fm *FailoverManager) probePeers() {
+	for _, peer := range fm.cfg.Peers {
+		if err := fm.client.Probe(peer.Address); err != nil {
+			kind := communication.ClassifyError(err)
+			fm.logger.Warn("Peer %s (%s) unreachable [%s]: %v - %s",
+				peer.ID, peer.Address, kind, err, communication.DescribeFailure(kind))
+			continue
+		}
+		fm.logger.Info("Peer %s (%s) reachable", peer.ID, peer.Address)
+	}
+}
+
+// handshakeWithPeers periodically asks each peer to call this node back,
+// logging when a peer cannot reach us (NAT, wrong advertised address)
+func (fm *FailoverManager) handshakeWithPeers() {
+	ticker := time.NewTicker(handshakeInterval)
+	defer ticker.Stop()
+
+	reported := make(map[string]string)
+	for {
+		for _, peer := range fm.cfg.Peers {
+			resp, err := fm.client.Handshake(peer.Address)
+			if err != nil {
+				continue
+			}
+			problems := strings.Join(communication.HandshakeProblems(resp, fm.cfg.Node), "; ")
+			if problems == reported[peer.Address] {
+				continue
+			}
+			reported[peer.Address] = problems
+			if problems == "" {
+				fm.logger.Info("Peer %s can reach this node back", peer.ID)
+			} else {
+				fm.logger.Error("Configuration error: %s", problems)
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-fm.stopCh:
+			return
+		}
+	}
+}
+
+// resolvePeers re-resolves peer hostnames every peer_api.dns_refresh, so a
+// peer that moved to another address is not called on the old one
+func (fm *FailoverManager) resolvePeers() {
+	ticker := time.NewTicker(fm.cfg.PeerAPI.DNSRefresh.Duration())
+	defer ticker.Stop()
+
+	for {
+		fm.client.ResolvePeers(context.Background())
+		select {
+		case <-ticker.C:
+		case <-fm.stopCh:
+			return
+		}
+	}
+}
+
+// enrollWithPeers registers our identity key with every peer so they can
+// verify our signed requests. Peers that are unreachable are retried until
+// they accept the key or the manager stops.
+func (fm *FailoverManager) enrollWithPeers() {
+	pending := make(map[string]config.PeerConfig)
+	for _, peer := range fm.cfg.Peers {
+		pending[peer.ID] = peer
+	}
+
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		for id, peer := range pending {
+			if err := fm.client.Enroll(peer.Address); err != nil {
+				fm.logger.Warn("Failed to enroll identity with peer %s: %v", id, err)
+				continue
+			}
+			fm.logger.Info("Enrolled identity with peer %s", id)
+			delete(pending, id)
+		}
+
+		if len(pending) == 0 {
+			return
+		}
+
+		select {
+		case <-ticker.C:
+		case <-fm.stopCh:
+			return
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/aldebaranode/syncguard/internal/communication"
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/constants"
+	"github.com/aldebaranode/syncguard/internal/crypto"
+	"github.com/aldebaranode/syncguard/internal/history"
+	"github.com/aldebaranode/syncguard/internal/logger"
+	"github.com/aldebaranode/syncguard/internal/notify"
+	"github.com/aldebaranode/syncguard/internal/state"
+)
+
+// fakeHandoff is a standby that stages what prepare sends and answers
+// commit with commitAddress
+type fakeHandoff struct {
+	prepareErr    error
+	commitAddress string
+	committed     []string
+	aborted       []string
+}
+
+func (f *fakeHandoff) Supports(addr, feature string) bool { return true }
+
+func (f *fakeHandoff) SendKey(addr string, keyData []byte) error {
+	return errors.New("unexpected single-request key transfer")
+}
+
+func (f *fakeHandoff) PrepareKeyHandoff(addr string, h communication.KeyHandoff) (*communication.KeyHandoffAck, error) {
+	if f.prepareErr != nil {
+		return nil, f.prepareErr
+	}
+	return &communication.KeyHandoffAck{ID: h.ID, Digest: h.Digest}, nil
+}
+
+func (f *fakeHandoff) CommitKeyHandoff(addr, id string) (*communication.KeyHandoffAck, error) {
+	f.committed = append(f.committed, id)
+	return &communication.KeyHandoffAck{ID: id, Written: true, Address: f.commitAddress}, nil
+}
+
+func (f *fakeHandoff) AbortKeyHandoff(addr, id string) error {
+	f.aborted = append(f.aborted, id)
+	return nil
+}
+
+// newReleasingManager is an active node holding key ABCD, with a single
+// standby reached through handoff
+func newReleasingManager(t *testing.T, handoff keyHandoffClient) *FailoverManager {
+	t.Helper()
+	dir := t.TempDir()
+	cfg := &config.Config{}
+	cfg.Node.ID = "primary"
+	cfg.Secret = "secret"
+	cfg.Peers = []config.PeerConfig{{ID: "standby", Address: "127.0.0.1:1"}}
+	cfg.Logging = config.LoggingConfig{Level: "error", File: "/dev/null"}
+
+	keys := state.NewKeyManager(filepath.Join(dir, "priv_validator_key.json"), dir, logger.New(cfg, "test"))
+	key := &state.ValidatorKey{Address: "ABCD", PubKey: json.RawMessage(`{}`), PrivKey: json.RawMessage(`{}`)}
+	if err := keys.SaveKey(key); err != nil {
+		t.Fatal(err)
+	}
+	alerts, err := notify.NewDispatcher(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signatures, err := state.LoadDoubleSignProtector(filepath.Join(dir, "signatures.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &FailoverManager{
+		cfg:          cfg,
+		stateManager: state.NewManager(filepath.Join(dir, "priv_validator_state.json"), dir),
+		keyManager:   keys,
+		history:      history.NewStore(filepath.Join(dir, "history.jsonl"), 0),
+		journal:      state.NewJournal(filepath.Join(dir, "transitions.journal")),
+		alerts:       alerts,
+		signatures:   signatures,
+		secrets:      crypto.NewSecretRing(cfg.Secret, ""),
+		client:       communication.NewClient(cfg, nil),
+		handoff:      handoff,
+		isActive:     true,
+		logger:       logger.New(cfg, "failover"),
+		stopCh:       make(chan struct{}),
+	}
+}
+
+func TestReleaseDuties_PrepareFails(t *testing.T) {
+	handoff := &fakeHandoff{prepareErr: errors.New("connection refused")}
+	fm := newReleasingManager(t, handoff)
+
+	fm.releaseDuties(context.Background(), constants.ReasonUnhealthy)
+	if !fm.IsActive() {
+		t.Error("node released although the standby never staged the key")
+	}
+	if fm.keyManager.IsDisabled() {
+		t.Error("key disabled although the node stays active")
+	}
+	if len(handoff.committed) != 0 {
+		t.Errorf("committed %v after a failed prepare", handoff.committed)
+	}
+}
+
+func TestReleaseDuties_CommitWrongAddress(t *testing.T) {
+	handoff := &fakeHandoff{commitAddress: "EF01"}
+	fm := newReleasingManager(t, handoff)
+
+	fm.releaseDuties(context.Background(), constants.ReasonUnhealthy)
+	if len(handoff.committed) != 1 || len(handoff.aborted) != 1 || handoff.aborted[0] != handoff.committed[0] {
+		t.Fatalf("committed %v, aborted %v; want the commit aborted", handoff.committed, handoff.aborted)
+	}
+	if !fm.IsActive() {
+		t.Error("node released although the standby wrote another key")
+	}
+	if fm.keyManager.IsDisabled() {
+		t.Error("key disabled although the node stays active")
+	}
+}
+
+func TestReleaseDuties_LeaseLost(t *testing.T) {
+	handoff := &fakeHandoff{prepareErr: errors.New("connection refused")}
+	fm := newReleasingManager(t, handoff)
+
+	// Another node may already sign, so the release goes ahead
+	fm.releaseDuties(context.Background(), constants.ReasonLeaseLost)
+	if fm.IsActive() {
+		t.Error("node still active after losing its lease")
+	}
+	if !fm.keyManager.IsDisabled() {
+		t.Error("key still enabled after losing the lease")
+	}
+}
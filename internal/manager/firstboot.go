@@ -0,0 +1,130 @@
+package manager
+
+import (
+	"fmt"
+
+	"github.com/aldebaranode/syncguard/internal/constants"
+	"github.com/aldebaranode/syncguard/internal/notify"
+	"github.com/aldebaranode/syncguard/internal/transition"
+)
+
+// ConfirmFirstBoot lets a node configured active sign on a first boot or
+// after a reboot without waiting for confirmation (start --confirm-active).
+// Call it before Start.
+func (fm *FailoverManager) ConfirmFirstBoot() {
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+	fm.firstBootConfirmed = true
+}
+
+// AwaitingConfirmation reports whether the node was configured active but
+// is held passive until an operator confirms it
+func (fm *FailoverManager) AwaitingConfirmation() bool {
+	fm.mu.RLock()
+	defer fm.mu.RUnlock()
+	return fm.awaitingConfirm
+}
+
+// guardFirstBoot holds a node configured active on the mock key when it
+// looks like a first boot: no earlier run saved state in node.data_dir and
+// no peer answers. That is also what a copy of the active node's config
+// on a new machine looks like, and signing there would double-sign. The
+// node stays passive until an operator confirms it.
+func (fm *FailoverManager) guardFirstBoot(restored bool) error {
+	fm.mu.RLock()
+	skip := !fm.isActive || fm.firstBootConfirmed
+	fm.mu.RUnlock()
+	if skip || restored {
+		return nil
+	}
+	for _, peer := range fm.cfg.Peers {
+		if err := fm.client.Probe(peer.Address); err == nil {
+			return nil
+		}
+	}
+
+	if !fm.keyManager.IsDisabled() {
+		if err := fm.keyManager.DeleteKey(); err != nil {
+			return fmt.Errorf("failed to swap to the mock key on first boot: %w", err)
+		}
+	}
+	fm.mu.Lock()
+	fm.isActive = false
+	fm.awaitingConfirm = true
+	fm.mu.Unlock()
+
+	message := "First boot as active with no saved state and no reachable peer: not signing until confirmed"
+	fm.logger.Error("%s. If no other node signs for this validator, run `syncguard cluster confirm-active` "+
+		"or restart with --confirm-active", message)
+	fm.alert(notify.EventFirstBoot, notify.SeverityCritical, message, map[string]string{
+		"confirm": "syncguard cluster confirm-active",
+	})
+	return nil
+}
+
+// ConfirmActive takes the validator role on a node held on first boot or
+// after a reboot.
+// It refuses while a peer reports itself active.
+func (fm *FailoverManager) ConfirmActive() error {
+	if !fm.AwaitingConfirmation() {
+		return fmt.Errorf("this node is not awaiting confirmation")
+	}
+	for _, peer := range fm.cfg.Peers {
+		if h, err := fm.client.FetchHealth(peer.Address); err == nil && h.Active {
+			return fmt.Errorf("peer %s is active", peer.ID)
+		}
+	}
+
+	fm.audit("confirm-active", "Operator confirmed first boot as active")
+	err := fm.transitions.Submit(transition.Request{
+		Kind:   transition.KindAcquire,
+		Reason: string(constants.ReasonOperatorManual),
+		Source: "operator",
+		Run:    fm.activateConfirmed,
+	})
+	if err != nil {
+		return err
+	}
+	if !fm.IsActive() {
+		return fmt.Errorf("node did not become active, see logs")
+	}
+	return nil
+}
+
+// activateConfirmed restores the validator key held back on first boot
+// or after a reboot
+func (fm *FailoverManager) activateConfirmed() error {
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+
+	if !fm.awaitingConfirm {
+		return nil
+	}
+	if err := fm.checkMaintenance(); err != nil {
+		return err
+	}
+	if fm.elector != nil {
+		if err := fm.elector.Campaign(); err != nil {
+			return fmt.Errorf("lost the election: %w", err)
+		}
+	}
+	if err := fm.keyManager.RestoreKey(); err != nil {
+		fm.resignLease()
+		return fmt.Errorf("failed to restore validator key: %w", err)
+	}
+	if fm.nodeManager != nil {
+		if err := fm.activateKey(); err != nil {
+			return fmt.Errorf("failed to restart node: %w", err)
+		}
+	}
+	fm.awaitingConfirm = false
+	fm.isActive = true
+	fm.updateWatermark(true)
+	fm.failureCount = 0
+	fm.recordBoot()
+
+	fm.logger.Info("Activation confirmed - node is now active")
+	fm.transitionAlert(notify.EventTakeover, notify.SeverityWarning, "Activation confirmed - node is now active",
+		constants.ReasonOperatorManual, nil)
+	return nil
+}
@@ -0,0 +1,59 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aldebaranode/syncguard/internal/constants"
+	"github.com/aldebaranode/syncguard/internal/history"
+	"github.com/aldebaranode/syncguard/internal/notify"
+)
+
+// cascadeFailover hands off the linked consumer-chain instances after this
+// node released validator duties. Drills do not cascade.
+func (fm *FailoverManager) cascadeFailover(reason constants.Reason) {
+	if fm.group == nil || !fm.cfg.Group.Cascade {
+		return
+	}
+
+	fm.logger.Info("Cascading %s to %d linked instances", reason, len(fm.cfg.Group.Members))
+	results := fm.group.Cascade(context.Background())
+
+	var failed []string
+	for i, r := range results {
+		fields := map[string]string{
+			"group":   fm.group.Name(),
+			"member":  r.Member,
+			"order":   fmt.Sprintf("%d", i+1),
+			"outcome": r.Outcome,
+			"seconds": fmt.Sprintf("%.1f", r.Seconds),
+		}
+		if r.Error != "" {
+			fields["error"] = r.Error
+			failed = append(failed, r.Member)
+		}
+		if err := fm.history.Append(history.Entry{
+			Kind:    history.KindDecision,
+			Type:    "cascade",
+			NodeID:  fm.cfg.Node.ID,
+			Message: fmt.Sprintf("Cascade to %s: %s", r.Member, r.Outcome),
+			Reason:  string(reason),
+			Fields:  fields,
+		}); err != nil {
+			fm.logger.Warn("Failed to record cascade history: %v", err)
+		}
+		fm.logger.Info("Cascade to %s: %s %s", r.Member, r.Outcome, r.Error)
+	}
+
+	if len(failed) > 0 {
+		fm.emit(history.Entry{
+			Kind:     history.KindEvent,
+			Type:     string(notify.EventCascade),
+			NodeID:   fm.cfg.Node.ID,
+			Severity: notify.SeverityCritical.String(),
+			Message:  fmt.Sprintf("Cascaded failover failed for %v", failed),
+			Reason:   string(reason),
+			Fields:   map[string]string{"group": fm.group.Name()},
+		}, notify.SeverityCritical)
+	}
+}
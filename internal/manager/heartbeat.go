@@ -0,0 +1,220 @@
+package manager
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/communication"
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/constants"
+	"github.com/aldebaranode/syncguard/internal/health"
+	"github.com/aldebaranode/syncguard/internal/metrics"
+	"github.com/aldebaranode/syncguard/internal/notify"
+)
+
+var disagreementGauge = metrics.NewGauge(
+	"syncguard_health_disagreement",
+	"1 while the active node's health report disagrees with what this passive observes",
+	"peer",
+)
+
+// heartbeatState tracks the health reports exchanged with peers: as a
+// passive, the active's last report and our verdict on it; as the active,
+// a passive's verdict on ours
+type heartbeatState struct {
+	mu       sync.Mutex
+	sending  bool
+	last     *health.Report
+	received time.Time
+	stale    bool
+	restored bool
+	observed string
+	disputed string
+	// peerProgress is the peer's countdown from its report or answer
+	peerProgress *health.Progress
+	// peerTransitions sums up the peer's changes of role, likewise
+	peerTransitions *health.Transitions
+}
+
+// sendHeartbeats pushes this node's health report to every peer at once
+// and keeps the first disagreement they answer with, in peer order. A
+// round still waiting on a slow peer makes the next one a no-op.
+func (fm *FailoverManager) sendHeartbeats(report health.Report) {
+	fm.heartbeats.mu.Lock()
+	if fm.heartbeats.sending {
+		fm.heartbeats.mu.Unlock()
+		return
+	}
+	fm.heartbeats.sending = true
+	fm.heartbeats.mu.Unlock()
+
+	var acksMu sync.Mutex
+	acks := make(map[string]*communication.HeartbeatAck)
+	fm.broadcast("heartbeat", func(peer config.PeerConfig) error {
+		ack, err := fm.client.SendHeartbeat(peer.Address, report)
+		if err != nil {
+			return err
+		}
+		acksMu.Lock()
+		acks[peer.ID] = ack
+		acksMu.Unlock()
+		return nil
+	})
+
+	disputed := ""
+	var peerProgress *health.Progress
+	var peerTransitions *health.Transitions
+	for _, peer := range fm.cfg.Peers {
+		ack, ok := acks[peer.ID]
+		if !ok {
+			continue
+		}
+		if ack.Disagreement != "" && disputed == "" {
+			disputed = fmt.Sprintf("%s (node %s at height %d)", ack.Disagreement, ack.NodeID, ack.Height)
+		}
+		if peerProgress == nil {
+			peerProgress = ack.Progress
+		}
+		if peerTransitions == nil {
+			peerTransitions = ack.Transitions
+		}
+	}
+
+	fm.heartbeats.mu.Lock()
+	previous := fm.heartbeats.disputed
+	fm.heartbeats.sending = false
+	fm.heartbeats.disputed = disputed
+	fm.heartbeats.peerProgress = peerProgress
+	fm.heartbeats.peerTransitions = peerTransitions
+	// Reports received while passive are stale once we sign
+	if fm.heartbeats.last != nil && fm.heartbeats.observed != "" {
+		disagreementGauge.Set(0, fm.heartbeats.last.NodeID)
+	}
+	fm.heartbeats.last = nil
+	fm.heartbeats.received = time.Time{}
+	fm.heartbeats.stale = false
+	fm.heartbeats.restored = false
+	fm.heartbeats.observed = ""
+	fm.heartbeats.mu.Unlock()
+
+	switch {
+	case disputed != "" && previous == "":
+		fm.logger.Warn("Passive peer disputes our health report: this node %s", disputed)
+	case disputed == "" && previous != "":
+		fm.logger.Info("Passive peers agree with our health report again")
+	}
+}
+
+// ReceiveHeartbeat records the active peer's health report and compares it
+// with the chain tip this node sees. The first report that disagrees
+// raises an alert, and so does the first that agrees again.
+func (fm *FailoverManager) ReceiveHeartbeat(report health.Report) communication.HeartbeatAck {
+	healthy := fm.healthChecker.IsHealthy()
+	tip := fm.healthChecker.GetLastHeight()
+	ack := communication.HeartbeatAck{NodeID: fm.cfg.Node.ID, Healthy: healthy, Height: tip,
+		Progress: fm.Progress(), Transitions: fm.TransitionSummary()}
+	// An unhealthy observer has no view of the tip worth comparing with
+	if healthy {
+		ack.Disagreement = health.Disagreement(report, tip, fm.cfg.Health.Heartbeat.MaxLag)
+	}
+
+	fm.heartbeats.mu.Lock()
+	previous := fm.heartbeats.observed
+	fm.heartbeats.last = &report
+	fm.heartbeats.received = time.Now()
+	fm.heartbeats.stale = false
+	fm.heartbeats.restored = false
+	fm.heartbeats.observed = ack.Disagreement
+	fm.heartbeats.peerProgress = report.Progress
+	fm.heartbeats.peerTransitions = report.Transitions
+	fm.heartbeats.mu.Unlock()
+
+	fields := map[string]string{
+		"peer":             report.NodeID,
+		"reported_healthy": fmt.Sprintf("%v", report.Healthy),
+		"reported_height":  fmt.Sprintf("%d", report.Height),
+		"observed_height":  fmt.Sprintf("%d", tip),
+	}
+	switch {
+	case ack.Disagreement != "" && previous == "":
+		disagreementGauge.Set(1, report.NodeID)
+		message := fmt.Sprintf("Active node %s %s", report.NodeID, ack.Disagreement)
+		fm.logger.Warn("%s", message)
+		fm.alert(notify.EventDisagreement, notify.SeverityWarning, message, fields)
+	case ack.Disagreement == "" && previous != "":
+		disagreementGauge.Set(0, report.NodeID)
+		fm.alert(notify.EventDisagreement, notify.SeverityInfo,
+			fmt.Sprintf("Active node %s health report agrees with this node again", report.NodeID), fields)
+	}
+	return ack
+}
+
+// checkHeartbeat alerts once when the active peer's reports stop arriving
+func (fm *FailoverManager) checkHeartbeat() {
+	if !fm.cfg.Health.Heartbeat.Enabled || fm.IsActive() {
+		return
+	}
+
+	fm.heartbeats.mu.Lock()
+	received := fm.heartbeats.received
+	overdue := !received.IsZero() && time.Since(received) > fm.cfg.Health.Heartbeat.StaleAfter.Duration()
+	newlyStale := overdue && !fm.heartbeats.stale
+	if overdue {
+		fm.heartbeats.stale = true
+	}
+	fm.heartbeats.mu.Unlock()
+
+	if newlyStale {
+		fm.logger.Warn("No heartbeat from the active node since %s", received.Format(time.RFC3339))
+		fm.alert(notify.EventHeartbeatMissed, notify.SeverityWarning, "Active node stopped sending health reports",
+			map[string]string{"last_heartbeat": received.UTC().Format(time.RFC3339)})
+	}
+}
+
+// disputedHealth returns a passive's disagreement with our health report
+// when health.heartbeat.enforce makes it count as a failed check
+func (fm *FailoverManager) disputedHealth() string {
+	if !fm.cfg.Health.Heartbeat.Enforce || !fm.IsActive() {
+		return ""
+	}
+	fm.heartbeats.mu.Lock()
+	defer fm.heartbeats.mu.Unlock()
+	return fm.heartbeats.disputed
+}
+
+// failureReason is the reason code of a failover triggered by the current
+// run of failed checks
+func (fm *FailoverManager) failureReason() constants.Reason {
+	if fm.healthChecker.IsHealthy() && fm.notSigning() > 0 {
+		return constants.ReasonNotSigning
+	}
+	if fm.healthChecker.IsHealthy() && fm.disputedHealth() != "" {
+		return constants.ReasonHealthDisputed
+	}
+	if fm.healthChecker.IsHealthy() && fm.stateChurnDropped() {
+		return constants.ReasonStateChurn
+	}
+	return fm.healthChecker.FailureReason()
+}
+
+// Heartbeat reports the health reports exchanged with peers; nil when
+// heartbeats are disabled
+func (fm *FailoverManager) Heartbeat() *health.HeartbeatStatus {
+	if !fm.cfg.Health.Heartbeat.Enabled {
+		return nil
+	}
+
+	fm.heartbeats.mu.Lock()
+	defer fm.heartbeats.mu.Unlock()
+	return &health.HeartbeatStatus{
+		Last:            fm.heartbeats.last,
+		ReceivedAt:      fm.heartbeats.received,
+		Stale:           fm.heartbeats.stale,
+		Restored:        fm.heartbeats.restored,
+		Observed:        fm.heartbeats.observed,
+		Disputed:        fm.heartbeats.disputed,
+		PeerProgress:    fm.heartbeats.peerProgress,
+		PeerTransitions: fm.heartbeats.peerTransitions,
+	}
+}
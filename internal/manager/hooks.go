@@ -0,0 +1,84 @@
+package manager
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/hooks"
+	"github.com/aldebaranode/syncguard/internal/notify"
+	"github.com/aldebaranode/syncguard/internal/transition"
+)
+
+// transitionObserver keeps the role record in step with the transition
+// executor and runs the operator's hooks around each transition
+type transitionObserver struct {
+	fm *FailoverManager
+}
+
+func (o transitionObserver) Started(req transition.Request) {
+	o.fm.saveRole(req.Kind, "")
+	o.fm.runHooks(hooks.PhasePre, req, nil)
+}
+
+func (o transitionObserver) Finished(req transition.Request, err error) {
+	o.fm.saveRole("", req.Reason)
+	o.fm.runHooks(hooks.PhasePost, req, err)
+}
+
+// runHooks runs failover.pre_hooks or post_hooks for a transition. Hooks
+// run on the executor's goroutine, so the transition waits for pre hooks
+// and is reported done after post hooks. A failing hook raises an alert
+// but never stops the transition: a validator that cannot switch costs
+// more than a stale DNS record.
+func (fm *FailoverManager) runHooks(phase string, req transition.Request, err error) {
+	list := fm.cfg.Failover.PreHooks
+	if phase == hooks.PhasePost {
+		list = fm.cfg.Failover.PostHooks
+	}
+	if len(list) == 0 {
+		return
+	}
+
+	t := hooks.Transition{
+		Phase:  phase,
+		Event:  fm.transitionEvent(req.Kind),
+		Kind:   req.Kind,
+		Reason: req.Reason,
+		Source: req.Source,
+		NodeID: fm.cfg.Node.ID,
+		Active: fm.IsActive(),
+		Err:    err,
+	}
+	for _, peer := range fm.cfg.Peers {
+		t.Peers = append(t.Peers, peer.ID)
+	}
+
+	for _, r := range hooks.Run(list, t) {
+		if r.Passed() {
+			fm.logger.Info("%s-%s hook %s passed in %s", phase, t.Event, r.Name, r.Duration.Round(time.Millisecond))
+			continue
+		}
+		detail := r.Error
+		if detail == "" {
+			detail = "exit code " + strconv.Itoa(r.ExitCode)
+		}
+		message := fmt.Sprintf("%s-%s hook %s failed: %s", phase, t.Event, r.Name, detail)
+		fm.logger.Warn("%s; output: %s", message, r.Output)
+		fm.alert(notify.EventHook, notify.SeverityWarning, message, map[string]string{
+			"hook":       r.Name,
+			"phase":      phase,
+			"transition": t.Event,
+			"output":     r.Output,
+		})
+	}
+}
+
+// transitionEvent names a transition kind as operators do: the primary
+// releasing or the standby acquiring is a failover, the reverse a failback
+func (fm *FailoverManager) transitionEvent(kind string) string {
+	if (kind == transition.KindRelease) == fm.isPrimarySite {
+		return "failover"
+	}
+	return "failback"
+}
@@ -0,0 +1,191 @@
+package manager
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/communication"
+	"github.com/aldebaranode/syncguard/internal/constants"
+	"github.com/aldebaranode/syncguard/internal/invariant"
+	"github.com/aldebaranode/syncguard/internal/metrics"
+	"github.com/aldebaranode/syncguard/internal/notify"
+	"github.com/aldebaranode/syncguard/internal/state"
+	"github.com/aldebaranode/syncguard/internal/transition"
+)
+
+var invariantGauge = metrics.NewGauge(
+	"syncguard_invariant_violations",
+	"Cluster invariant violations seen on enough checks in a row to alert",
+)
+
+// invariantState is what the invariant loop remembers between checks. It
+// is only used from that loop.
+type invariantState struct {
+	checker *invariant.Checker
+	// seen counts the checks in a row each violation was reported on
+	seen map[string]int
+}
+
+// KeyCustody reports whether this node has the real key in place and
+// holds the lock, and the height of its validator state
+func (fm *FailoverManager) KeyCustody() communication.KeyCustody {
+	custody := communication.KeyCustody{LockHeld: fm.stateManager.LockHeld()}
+	if key, err := fm.keyManager.LoadKey(); err == nil {
+		custody.KeyPresent = !state.IsMockKey(key)
+	}
+	if current, err := fm.stateManager.LoadState(); err == nil {
+		custody.StateHeight = current.Height
+	}
+	return custody
+}
+
+// monitorInvariants checks the cluster invariants every
+// invariants.interval
+func (fm *FailoverManager) monitorInvariants() {
+	fm.invariants = invariantState{checker: invariant.NewChecker(), seen: make(map[string]int)}
+	ticker := time.NewTicker(fm.cfg.Invariants.Interval.Duration())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			fm.checkInvariants()
+		case <-fm.stopCh:
+			return
+		}
+	}
+}
+
+// checkInvariants asks every peer what it holds and checks the answers
+// with this node's own. Checks are skipped while a transition is in
+// flight here, and a violation only alerts once seen on
+// invariants.confirmations checks in a row, so a peer caught halfway
+// through a transition does not. A state that went backwards alerts at
+// once. Each violation alerts once, and once more when it clears.
+func (fm *FailoverManager) checkInvariants() {
+	if st := fm.transitions.Status(); st.Running != nil || len(st.Queued) > 0 {
+		return
+	}
+
+	custody := fm.KeyCustody()
+	self := invariant.Node{
+		ID:          fm.cfg.Node.ID,
+		Active:      fm.IsActive(),
+		KeyPresent:  custody.KeyPresent,
+		LockHeld:    custody.LockHeld,
+		StateHeight: custody.StateHeight,
+	}
+	nodes := []invariant.Node{self}
+	complete := true
+	for _, view := range fm.surveyPeers() {
+		if view.health == nil || view.health.Custody == nil {
+			complete = false
+			continue
+		}
+		c := view.health.Custody
+		nodes = append(nodes, invariant.Node{
+			ID:          view.peer.ID,
+			Active:      view.health.Active,
+			KeyPresent:  c.KeyPresent,
+			LockHeld:    c.LockHeld,
+			StateHeight: c.StateHeight,
+		})
+	}
+
+	violations := fm.invariants.checker.Check(nodes, complete, fm.sharedLock())
+	seen := make(map[string]int, len(violations))
+	var confirmed []invariant.Violation
+	for _, v := range violations {
+		id := v.Code + ":" + strings.Join(v.Nodes, ",")
+		seen[id] = fm.invariants.seen[id] + 1
+		if seen[id] == fm.cfg.Invariants.Confirmations || v.Code == invariant.StateRegressed {
+			fm.logger.Error("Invariant violated: %s", v.Message)
+			fm.alert(notify.EventInvariant, notify.SeverityCritical, v.Message, map[string]string{
+				"invariant": v.Code,
+				"nodes":     strings.Join(v.Nodes, ","),
+			})
+		}
+		if seen[id] >= fm.cfg.Invariants.Confirmations || v.Code == invariant.StateRegressed {
+			confirmed = append(confirmed, v)
+		}
+	}
+	for id, count := range fm.invariants.seen {
+		if _, still := seen[id]; !still && count >= fm.cfg.Invariants.Confirmations {
+			code, _, _ := strings.Cut(id, ":")
+			message := fmt.Sprintf("Invariant %s holds again", code)
+			fm.logger.Info("%s", message)
+			fm.alert(notify.EventInvariant, notify.SeverityInfo, message, map[string]string{"invariant": code})
+		}
+	}
+	fm.invariants.seen = seen
+	invariantGauge.Set(float64(len(confirmed)))
+
+	if fm.cfg.Invariants.Fence && len(confirmed) > 0 {
+		fm.fence(self, confirmed)
+	}
+}
+
+// fence swaps this node to the mock key when a confirmed violation shows
+// it has the real key but should not sign. With a shared lock the node
+// holding the lock keeps signing; without one, the active node of the
+// primary site does. A paused node is left alone, as an operator may be
+// moving the key by hand.
+func (fm *FailoverManager) fence(self invariant.Node, violations []invariant.Violation) {
+	if !self.KeyPresent || !fm.automated() || fm.IsPaused() {
+		return
+	}
+	involved := false
+	for _, v := range violations {
+		switch v.Code {
+		case invariant.MultipleKeys, invariant.MultipleActive, invariant.KeyMismatch, invariant.LockMismatch:
+			for _, id := range v.Nodes {
+				involved = involved || id == self.ID
+			}
+		}
+	}
+	keeps := self.Active && fm.isPrimarySite
+	if fm.sharedLock() {
+		keeps = self.LockHeld
+	}
+	if !involved || keeps {
+		return
+	}
+
+	fm.logger.Error("Fencing: swapping to the mock key after an invariant violation")
+	err := fm.transitions.Submit(transition.Request{
+		Kind:   transition.KindRelease,
+		Reason: string(constants.ReasonInvariant),
+		Source: "invariants",
+		Run:    fm.fenceKey,
+	})
+	if err != nil {
+		fm.logger.Error("Fencing failed: %v", err)
+		fm.alert(notify.EventInvariant, notify.SeverityCritical, "Fencing failed - this node may still sign",
+			map[string]string{"error": err.Error()})
+		return
+	}
+	fm.transitionAlert(notify.EventInvariant, notify.SeverityCritical, "Fenced - swapped to the mock key and stopped signing",
+		constants.ReasonInvariant, nil)
+}
+
+// fenceKey swaps in the mock key and has the node pick it up, without
+// handing anything to the peer, which may already sign
+func (fm *FailoverManager) fenceKey() error {
+	if err := fm.keyManager.DeleteKey(); err != nil {
+		return fmt.Errorf("failed to swap to the mock key: %w", err)
+	}
+	if fm.nodeManager != nil {
+		if err := fm.activateKey(); err != nil {
+			return fmt.Errorf("failed to restart node: %w", err)
+		}
+	}
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+	if fm.isActive {
+		fm.stateManager.ReleaseLock()
+		fm.isActive = false
+		fm.updateWatermark(false)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package manager
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aldebaranode/syncguard/internal/constants"
+	"github.com/aldebaranode/syncguard/internal/history"
+	"github.com/aldebaranode/syncguard/internal/logger"
+	"github.com/aldebaranode/syncguard/internal/notify"
+	"github.com/aldebaranode/syncguard/internal/state"
+)
+
+// endTransition closes the journaled transition
+func (fm *FailoverManager) endTransition() {
+	if err := fm.journal.End(); err != nil {
+		fm.logger.Error("Failed to close transition journal: %v", err)
+	}
+}
+
+// rollBackAcquire undoes a takeover that did not finish: a fetched key is
+// disabled and an acquired lock released, so the node cannot sign without
+// a completed handover. The journal is kept when the key cannot be
+// disabled, so the next start retries.
+func (fm *FailoverManager) rollBackAcquire(keyFetched, lockAcquired bool) error {
+	if keyFetched && !fm.keyManager.IsDisabled() {
+		if err := fm.keyManager.DeleteKey(); err != nil {
+			fm.logger.Error("Failed to disable fetched key: %v", err)
+			return fmt.Errorf("failed to disable key while rolling back takeover: %w", err)
+		}
+	}
+	if lockAcquired {
+		if err := fm.stateManager.ReleaseLock(); err != nil {
+			fm.logger.Error("Failed to release state lock: %v", err)
+		}
+	}
+	fm.endTransition()
+	return nil
+}
+
+// recoverTransition finishes or rolls back a transition interrupted by a
+// crash, before the node is started:
+//   - a release that never reached disable_key changed nothing; the node
+//     keeps signing
+//   - a release past disable_key is finished: key disabled, lock released,
+//     peer told to take over
+//   - an acquire is always rolled back: key disabled, lock released
+//
+// Every outcome except the first leaves this node passive.
+func (fm *FailoverManager) recoverTransition() error {
+	inc, err := fm.journal.Incomplete()
+	if err != nil {
+		return err
+	}
+	if inc == nil {
+		return nil
+	}
+
+	transition := logger.StartTransition()
+	defer logger.EndTransition(transition)
+	fm.logger.Warn("Found interrupted %s transition from %s (done: %s, in flight: %s)",
+		inc.Transition, inc.Started.Format("2006-01-02T15:04:05Z"), strings.Join(inc.Done, ","), inc.InFlight)
+
+	var outcome, message string
+	switch {
+	case inc.Transition == state.TransitionRelease && !inc.Reached(state.StepDisableKey):
+		fm.isActive = true
+		outcome = "rolled_back"
+		message = "Interrupted failover rolled back before the key was disabled; node stays active"
+		fm.endTransition()
+
+	case inc.Transition == state.TransitionRelease:
+		if !fm.keyManager.IsDisabled() {
+			if err := fm.keyManager.DeleteKey(); err != nil {
+				return fmt.Errorf("failed to disable key while finishing failover: %w", err)
+			}
+		}
+		if err := fm.stateManager.ReleaseLock(); err != nil {
+			fm.logger.Error("Failed to release state lock: %v", err)
+		}
+		if !inc.Reached(state.StepNotifyPeer) {
+			fm.notifyPeerOfFailover(fm.standbyPeer(), constants.ReasonRecovery)
+		}
+		fm.isActive = false
+		outcome = "completed"
+		message = "Interrupted failover completed; node is passive"
+		fm.endTransition()
+
+	default:
+		if err := fm.rollBackAcquire(true, true); err != nil {
+			return err
+		}
+		fm.isActive = false
+		outcome = "rolled_back"
+		message = "Interrupted takeover rolled back; node is passive, check that a node is signing"
+	}
+
+	fields := map[string]string{
+		"transition": inc.Transition,
+		"outcome":    outcome,
+		"done":       strings.Join(inc.Done, ","),
+		"in_flight":  inc.InFlight,
+	}
+	if err := fm.history.Append(history.Entry{
+		Kind:    history.KindDecision,
+		Type:    "recovery",
+		NodeID:  fm.cfg.Node.ID,
+		Message: message,
+		Fields:  fields,
+	}); err != nil {
+		fm.logger.Warn("Failed to record recovery history: %v", err)
+	}
+	fm.logger.Warn("%s", message)
+	fm.alert(notify.EventRecovery, notify.SeverityCritical, message, fields)
+	return nil
+}
@@ -0,0 +1,104 @@
+package manager
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/communication"
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/metrics"
+	"github.com/aldebaranode/syncguard/internal/notify"
+)
+
+var linkThroughputGauge = metrics.NewGauge(
+	"syncguard_link_throughput_bytes",
+	"Upload throughput to a peer measured by the last link check, in bytes per second",
+	"peer",
+)
+
+// payload is one transfer of a handover and the timeout it must fit in
+type payload struct {
+	name    string
+	size    int64
+	timeout time.Duration
+}
+
+// handoverPayloads sizes what crosses the link during a handover: the
+// sealed key, each artifact as JSON-encoded bytes, and the validator state
+func (fm *FailoverManager) handoverPayloads() ([]payload, error) {
+	timeouts := fm.cfg.PeerAPI.Timeouts
+	key, err := fm.keyManager.EncryptKeyToBytes(fm.secrets.Current())
+	if err != nil {
+		return nil, fmt.Errorf("failed to seal validator key: %w", err)
+	}
+	payloads := []payload{{name: "validator key", size: int64(len(key)), timeout: timeouts.Key.Duration()}}
+	if fm.artifacts != nil {
+		for _, artifact := range fm.artifacts.List() {
+			data, err := fm.artifacts.Read(artifact.Name)
+			if err != nil {
+				continue
+			}
+			// Base64 in the JSON transfer grows the data by a third
+			size := int64(len(data)+2) / 3 * 4
+			payloads = append(payloads, payload{name: artifact.Name, size: size, timeout: timeouts.Key.Duration()})
+		}
+	}
+	if info, err := os.Stat(fm.cfg.CometBFT.StatePath); err == nil {
+		payloads = append(payloads, payload{name: "validator state", size: info.Size(), timeout: timeouts.State.Duration()})
+	}
+	return payloads, nil
+}
+
+// checkLink probes the link to the peer taking over and returns an error
+// when a handover transfer would not fit in its timeout
+func (fm *FailoverManager) checkLink(target config.PeerConfig) error {
+	link, err := fm.client.ProbeLink(target.Address, int(fm.cfg.Failover.LinkCheck.ProbeBytes))
+	if err != nil {
+		return fmt.Errorf("link check to %s failed: %w", target.ID, err)
+	}
+	linkThroughputGauge.Set(link.Throughput, target.ID)
+	fm.logger.Info("Link to %s: round trip %s, %.0f KiB/s", target.ID, link.RTT.Round(time.Millisecond), link.Throughput/1024)
+
+	payloads, err := fm.handoverPayloads()
+	if err != nil {
+		return err
+	}
+	var slow []string
+	for _, p := range payloads {
+		if estimate := link.TransferTime(p.size); estimate > p.timeout {
+			slow = append(slow, fmt.Sprintf("%s (%d bytes) needs about %s, timeout %s",
+				p.name, p.size, estimate.Round(time.Millisecond), p.timeout))
+		}
+	}
+	if len(slow) > 0 {
+		return fmt.Errorf("link to %s too slow: %s", target.ID, strings.Join(slow, "; "))
+	}
+	return nil
+}
+
+// gateOnLink runs the link check before a planned handover. A link that
+// fails it raises an alert, and with action abort refuses the handover.
+func (fm *FailoverManager) gateOnLink(target config.PeerConfig) error {
+	if !fm.cfg.Failover.LinkCheck.Enabled || target.Address == "" {
+		return nil
+	}
+	if !fm.client.Supports(target.Address, communication.FeatureLinkProbe) {
+		fm.logger.Warn("Skipping the link check: %s does not support link probes", target.ID)
+		return nil
+	}
+	err := fm.checkLink(target)
+	if err == nil {
+		return nil
+	}
+	fields := map[string]string{"peer": target.ID, "error": err.Error()}
+	if fm.cfg.Failover.LinkCheck.Action == "abort" {
+		fm.logger.Error("Refusing handover: %v", err)
+		fm.alert(notify.EventLinkCheck, notify.SeverityCritical, "Handover refused: link to "+target.ID+" failed its check", fields)
+		return err
+	}
+	fm.logger.Warn("Handing over despite the link check: %v", err)
+	fm.alert(notify.EventLinkCheck, notify.SeverityWarning, "Handing over on a link that failed its check", fields)
+	return nil
+}
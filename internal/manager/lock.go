@@ -0,0 +1,189 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/constants"
+	"github.com/aldebaranode/syncguard/internal/notify"
+	"github.com/aldebaranode/syncguard/internal/state"
+)
+
+// NewLockBackend returns the lock backend selected by lock.backend
+func NewLockBackend(cfg *config.Config) (state.LockBackend, error) {
+	return state.NewLockBackend(cfg.Lock.Backend, state.LockPath(cfg.CometBFT.StatePath, cfg.Node.Instance),
+		state.RemoteLockOptions{
+			Endpoints:   cfg.Lock.Endpoints,
+			Key:         cfg.Lock.Key,
+			Owner:       cfg.Node.ID,
+			TTL:         cfg.Lock.TTL.Duration(),
+			Token:       cfg.Lock.Token,
+			Username:    cfg.Lock.Username,
+			Password:    cfg.Lock.Password,
+			CAFile:      cfg.Lock.CAFile,
+			CertFile:    cfg.Lock.CertFile,
+			KeyFile:     cfg.Lock.KeyFile,
+			SessionFile: filepath.Join(cfg.Node.DataDir, "lock-session"),
+		})
+}
+
+// sharedLock reports whether the peer takes the same lock: an etcd or
+// Consul lock, or the election lease. A lock file only excludes nodes on
+// the same filesystem.
+func (fm *FailoverManager) sharedLock() bool {
+	return fm.cfg.Lock.Backend != "file" || fm.elector != nil
+}
+
+// claimLock has a node configured active take a shared lock before it
+// signs. One that cannot, because the peer holds it or the backend does
+// not answer, starts passive on the mock key.
+func (fm *FailoverManager) claimLock() error {
+	if !fm.sharedLock() || !fm.IsActive() {
+		return nil
+	}
+	var err error
+	if fm.cfg.Lock.Backend != "file" {
+		// Under election this campaigns too
+		err = fm.stateManager.AcquireLock()
+	} else {
+		err = fm.elector.Campaign()
+	}
+	if err == nil {
+		fm.logger.Info("Took the %s lock", fm.stateManager.LockName())
+		return nil
+	}
+
+	if !fm.keyManager.IsDisabled() {
+		if err := fm.keyManager.DeleteKey(); err != nil {
+			return fmt.Errorf("failed to swap to the mock key after failing to take the lock: %w", err)
+		}
+	}
+	fm.mu.Lock()
+	fm.isActive = false
+	fm.mu.Unlock()
+
+	event := notify.EventLockUnavailable
+	if errors.Is(err, state.ErrLockHeld) {
+		event = notify.EventLockConflict
+	}
+	message := "Configured active but could not take the lock: starting passive"
+	fm.logger.Error("%s: %v", message, err)
+	fm.alert(event, notify.SeverityCritical, message, map[string]string{"error": err.Error()})
+	return nil
+}
+
+// acquireLock takes the state lock for a failback. The active peer holds a
+// shared lock until it releases, so it is asked to release first;
+// notified reports that it was.
+func (fm *FailoverManager) acquireLock(reason constants.Reason) (notified bool, err error) {
+	err = fm.stateManager.AcquireLock()
+	if !fm.sharedLock() || !errors.Is(err, state.ErrLockHeld) {
+		return false, err
+	}
+	fm.logger.Info("Peer holds the lock, asking it to release before failing back")
+	fm.notifyPeerOfFailback(reason)
+	return true, fm.stateManager.AcquireLock()
+}
+
+// monitorLock periodically checks the lock backend and applies the
+// unreachable policy
+func (fm *FailoverManager) monitorLock() {
+	ticker := time.NewTicker(fm.cfg.Lock.CheckInterval.Duration())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			fm.checkLock()
+		case <-fm.stopCh:
+			return
+		}
+	}
+}
+
+// checkLock tracks lock backend reachability.
+// While it is down the active node keeps signing for the grace TTL and then
+// follows lock.on_grace_expired; passive nodes refuse to take over.
+func (fm *FailoverManager) checkLock() {
+	err := fm.stateManager.CheckLock()
+	if errors.Is(err, state.ErrLockLost) {
+		fm.loseLock(err)
+		return
+	}
+
+	fm.mu.Lock()
+	if err == nil {
+		recovered := !fm.lockDownSince.IsZero()
+		fm.lockDownSince = time.Time{}
+		fm.lockGraceExpired = false
+		fm.mu.Unlock()
+		if recovered {
+			fm.logger.Info("Lock backend reachable again")
+			fm.alert(notify.EventLockUnavailable, notify.SeverityInfo, "Lock backend reachable again", nil)
+		}
+		return
+	}
+
+	now := time.Now()
+	firstFailure := fm.lockDownSince.IsZero()
+	if firstFailure {
+		fm.lockDownSince = now
+	}
+	grace := fm.cfg.Lock.GraceTTL.Duration()
+	expired := fm.isActive && !fm.lockGraceExpired && now.Sub(fm.lockDownSince) >= grace
+	if expired {
+		fm.lockGraceExpired = true
+	}
+	isActive := fm.isActive
+	fm.mu.Unlock()
+
+	fields := map[string]string{"backend": fm.cfg.Lock.Backend, "error": err.Error()}
+	if firstFailure {
+		fm.logger.Error("Lock backend unreachable: %v", err)
+		if isActive {
+			fm.alert(notify.EventLockUnavailable, notify.SeverityCritical,
+				"Lock backend unreachable - still signing within the grace TTL", fields)
+		} else {
+			fm.alert(notify.EventLockUnavailable, notify.SeverityCritical,
+				"Lock backend unreachable - takeover refused until it recovers", fields)
+		}
+	}
+
+	if !expired {
+		return
+	}
+
+	if fm.cfg.Lock.OnGraceExpired == "keep_signing" {
+		fm.logger.Warn("Lock grace TTL expired, continuing to sign per lock.on_grace_expired")
+		fm.alert(notify.EventLockUnavailable, notify.SeverityCritical,
+			"Lock grace TTL expired - still signing without a lock", fields)
+		return
+	}
+
+	fm.logger.Error("Lock grace TTL expired, stopping signing")
+	fm.alert(notify.EventLockUnavailable, notify.SeverityCritical,
+		"Lock grace TTL expired - stopping signing", fields)
+	fm.initiateFailover(context.Background(), constants.ReasonWatchdog, "watchdog")
+}
+
+// lockUnreachable reports whether the lock backend is known to be down.
+// Callers must hold fm.mu.
+func (fm *FailoverManager) lockUnreachable() bool {
+	return !fm.lockDownSince.IsZero()
+}
+
+// loseLock stops signing at once when the backend took the lock away: the
+// peer may already hold it
+func (fm *FailoverManager) loseLock(err error) {
+	if !fm.IsActive() {
+		return
+	}
+	fields := map[string]string{"backend": fm.cfg.Lock.Backend, "error": err.Error()}
+	fm.logger.Error("Lock lost, stopping signing: %v", err)
+	fm.alert(notify.EventLockConflict, notify.SeverityCritical, "Lock lost - stopping signing", fields)
+	fm.initiateFailover(context.Background(), constants.ReasonWatchdog, "watchdog")
+}
@@ -0,0 +1,46 @@
+package manager
+
+import (
+	"fmt"
+
+	"github.com/aldebaranode/syncguard/internal/notify"
+)
+
+// checkMaintenance returns an error while the node is drained or a
+// maintenance flag marks this host; every path to the active role asks it
+// first
+func (fm *FailoverManager) checkMaintenance() error {
+	if fm.IsDraining() {
+		return errDrained
+	}
+	if fm.maintenance == nil {
+		return nil
+	}
+	return fm.maintenance.Check()
+}
+
+// holdForMaintenance starts a node configured active passive, on the mock
+// key, while its host is under maintenance
+func (fm *FailoverManager) holdForMaintenance() error {
+	if !fm.IsActive() {
+		return nil
+	}
+	err := fm.checkMaintenance()
+	if err == nil {
+		return nil
+	}
+
+	if !fm.keyManager.IsDisabled() {
+		if err := fm.keyManager.DeleteKey(); err != nil {
+			return fmt.Errorf("failed to swap to the mock key under maintenance: %w", err)
+		}
+	}
+	fm.mu.Lock()
+	fm.isActive = false
+	fm.mu.Unlock()
+
+	message := "Configured active but the host is under maintenance: starting passive"
+	fm.logger.Error("%s: %v", message, err)
+	fm.alert(notify.EventMaintenance, notify.SeverityCritical, message, map[string]string{"error": err.Error()})
+	return nil
+}
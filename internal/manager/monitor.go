@@ -0,0 +1,31 @@
+package manager
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aldebaranode/syncguard/internal/notify"
+)
+
+// errMonitorOnly refuses an action that would change this node while
+// automation is off
+var errMonitorOnly = errors.New("monitor-only mode: automation is disabled (node.monitor_only)")
+
+// automated reports whether syncguard acts on what it observes. It is the
+// one switch every mutating path consults: with node.monitor_only set the
+// node only watches, scores readiness and alerts.
+func (fm *FailoverManager) automated() bool {
+	return !fm.cfg.Node.MonitorOnly
+}
+
+// reportWouldFailOver alerts, once per outage, where an automated active
+// node would have failed over
+func (fm *FailoverManager) reportWouldFailOver(failureCount int) {
+	if failureCount != fm.cfg.Failover.RetryAttempts || !fm.IsActive() {
+		return
+	}
+	reason := fm.failureReason()
+	fm.logger.Error("Maximum failures reached (%s); monitor-only mode, not failing over", reason)
+	fm.alert(notify.EventHealthCheckFailed, notify.SeverityCritical, "Validator node failing - monitor-only mode, not failing over",
+		map[string]string{"consecutive_failures": fmt.Sprintf("%d", failureCount), "reason": string(reason)})
+}
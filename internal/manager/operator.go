@@ -0,0 +1,164 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/constants"
+	"github.com/aldebaranode/syncguard/internal/crypto"
+	"github.com/aldebaranode/syncguard/internal/failback"
+	"github.com/aldebaranode/syncguard/internal/history"
+	"github.com/aldebaranode/syncguard/internal/supervise"
+)
+
+// Pause suspends automatic failover, failback and drills, e.g. during
+// maintenance. Manual handoff still works.
+func (fm *FailoverManager) Pause() {
+	fm.mu.Lock()
+	changed := !fm.paused
+	fm.paused = true
+	fm.mu.Unlock()
+
+	if changed {
+		fm.logger.Warn("Automatic failover paused by operator")
+		fm.audit("pause", "Automatic failover paused")
+	}
+}
+
+// Resume re-enables automatic failover after Pause
+func (fm *FailoverManager) Resume() {
+	fm.mu.Lock()
+	changed := fm.paused
+	fm.paused = false
+	fm.mu.Unlock()
+
+	if changed {
+		fm.logger.Info("Automatic failover resumed by operator")
+		fm.audit("resume", "Automatic failover resumed")
+	}
+}
+
+// IsPaused reports whether automatic failover is paused
+func (fm *FailoverManager) IsPaused() bool {
+	fm.mu.RLock()
+	defer fm.mu.RUnlock()
+	return fm.paused
+}
+
+// SecretStatus reports the cluster secrets this node accepts
+func (fm *FailoverManager) SecretStatus() crypto.SecretStatus {
+	return fm.secrets.Status()
+}
+
+// RetireSecret stops accepting the previous cluster secret once every node
+// has switched to the current one
+func (fm *FailoverManager) RetireSecret() error {
+	if err := fm.secrets.Retire(); err != nil {
+		return err
+	}
+	fm.logger.Warn("Previous cluster secret retired by operator")
+	fm.audit("retire-secret", "Previous cluster secret retired")
+	return nil
+}
+
+// Handoff hands validator duties to the standby on operator request.
+// Unlike automatic failover it requires a healthy, passive standby.
+func (fm *FailoverManager) Handoff() error {
+	return fm.handOff(context.Background(), constants.ReasonOperatorManual, "operator", "Operator handoff")
+}
+
+// handOff carries out a planned handover to a healthy, passive standby;
+// what names it in the audit entry, and ctx bounds the key handoff
+func (fm *FailoverManager) handOff(ctx context.Context, reason constants.Reason, source, what string) error {
+	if !fm.automated() {
+		return errMonitorOnly
+	}
+	if !fm.IsActive() {
+		return fmt.Errorf("this node is not active")
+	}
+	if len(fm.cfg.Peers) == 0 {
+		return fmt.Errorf("no peer configured")
+	}
+
+	target := fm.standbyPeer()
+	peer, err := fm.client.FetchHealth(target.Address)
+	if err != nil {
+		return fmt.Errorf("standby %s unreachable: %w", target.ID, err)
+	}
+	if !peer.Healthy || peer.Active {
+		return fmt.Errorf("standby %s must be healthy and passive (healthy=%v, active=%v)", target.ID, peer.Healthy, peer.Active)
+	}
+	if peer.Draining {
+		return fmt.Errorf("standby %s is drained", target.ID)
+	}
+	if err := standbyReady(target.ID, peer); err != nil {
+		return err
+	}
+	// A planned handover can afford a handshake, so the steps after it
+	// know what the standby supports
+	if _, err := fm.client.Negotiate(target.Address); err != nil {
+		fm.logger.Warn("Could not negotiate capabilities with %s: %v", target.ID, err)
+	}
+	if err := fm.gateOnLink(target); err != nil {
+		return err
+	}
+
+	fm.audit("handoff", fmt.Sprintf("%s to %s", what, target.ID))
+	if err := fm.initiateFailover(ctx, reason, source); err != nil {
+		return err
+	}
+	if fm.IsActive() {
+		return fmt.Errorf("node is still active, see logs")
+	}
+	supervise.Once(fm.logger, "cascade", func() { fm.cascadeFailover(reason) })
+	return nil
+}
+
+// Failback reclaims validator duties for the primary on operator request,
+// the only way back in sticky mode. It skips the stability period and
+// hold-off but still requires a healthy node that is caught up.
+func (fm *FailoverManager) Failback() error {
+	if !fm.automated() {
+		return errMonitorOnly
+	}
+	if !fm.isPrimarySite {
+		return fmt.Errorf("only the primary site fails back")
+	}
+	if fm.IsActive() {
+		return fmt.Errorf("this node is already active")
+	}
+	if !fm.healthChecker.IsHealthy() {
+		return fmt.Errorf("this node is unhealthy")
+	}
+	if ok, reason := fm.caughtUp(); !ok {
+		return fmt.Errorf("not caught up: %s", reason)
+	}
+
+	fm.audit("failback", "Operator failback to primary")
+	if err := fm.initiateFailback(constants.ReasonOperatorManual, "operator"); err != nil {
+		return err
+	}
+	if !fm.IsActive() {
+		return fmt.Errorf("node did not become active, see logs")
+	}
+	fm.failback.Succeeded()
+	return nil
+}
+
+// audit records an operator action in the history
+func (fm *FailoverManager) audit(action, message string) {
+	if err := fm.history.Append(history.Entry{
+		Kind:    history.KindAudit,
+		Type:    action,
+		NodeID:  fm.cfg.Node.ID,
+		Message: message,
+	}); err != nil {
+		fm.logger.Warn("Failed to record audit history: %v", err)
+	}
+}
+
+// FailbackStatus reports the primary's progress toward failback
+func (fm *FailoverManager) FailbackStatus() failback.Status {
+	return fm.failback.Status(time.Now())
+}
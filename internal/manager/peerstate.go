@@ -0,0 +1,76 @@
+package manager
+
+import (
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/communication"
+)
+
+// restorePeerState seeds peer reachability and the active peer's last
+// heartbeat from before a restart, so the first minutes after it are not
+// judged from a blank slate. A heartbeat older than
+// health.heartbeat.stale_after is left out: it says nothing current about
+// the active node. It reports whether an earlier run saved any state.
+func (fm *FailoverManager) restorePeerState() bool {
+	snap, err := fm.peerStore.Load()
+	if err != nil {
+		fm.logger.Warn("Failed to load saved peer state: %v", err)
+		return false
+	}
+	if snap == nil {
+		return false
+	}
+
+	restored := fm.client.RestorePeerStatuses(snap.Peers)
+	heartbeat := snap.Heartbeat != nil && !fm.isActive && fm.cfg.Health.Heartbeat.Enabled &&
+		time.Since(snap.ReceivedAt) <= fm.cfg.Health.Heartbeat.StaleAfter.Duration()
+	if heartbeat {
+		fm.heartbeats.mu.Lock()
+		fm.heartbeats.last = snap.Heartbeat
+		fm.heartbeats.received = snap.ReceivedAt
+		fm.heartbeats.restored = true
+		fm.heartbeats.peerProgress = snap.PeerProgress
+		fm.heartbeats.mu.Unlock()
+	}
+	fm.logger.Info("Restored state of %d peer(s) saved at %s (heartbeat restored: %v)",
+		restored, snap.SavedAt.Format(time.RFC3339), heartbeat)
+	return true
+}
+
+// savePeerState writes what is known about peers to node.data_dir. A node
+// held on first boot saves nothing, so a restart is held again.
+func (fm *FailoverManager) savePeerState() {
+	if fm.AwaitingConfirmation() {
+		return
+	}
+	snap := communication.PeerSnapshot{
+		SavedAt: time.Now().UTC(),
+		Peers:   fm.client.PeerStatuses(),
+	}
+	fm.heartbeats.mu.Lock()
+	if fm.heartbeats.last != nil {
+		snap.Heartbeat = fm.heartbeats.last
+		snap.ReceivedAt = fm.heartbeats.received.UTC()
+		snap.PeerProgress = fm.heartbeats.peerProgress
+	}
+	fm.heartbeats.mu.Unlock()
+
+	if err := fm.peerStore.Save(snap); err != nil {
+		fm.logger.Warn("Failed to save peer state: %v", err)
+	}
+}
+
+// savePeerStates saves peer state periodically; Stop saves it a last time
+func (fm *FailoverManager) savePeerStates() {
+	ticker := time.NewTicker(peerStateInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			fm.savePeerState()
+		case <-fm.stopCh:
+			return
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package manager
+
+import (
+	"sync"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/constants"
+	"github.com/aldebaranode/syncguard/internal/health"
+)
+
+// progressState tracks the timing half of the failover countdown: since
+// when the node has held its role and health, and when its loops run next
+type progressState struct {
+	mu        sync.Mutex
+	state     string
+	since     time.Time
+	nextCheck time.Time
+	nextSync  time.Time
+}
+
+// scheduleCheck records when the next health check runs
+func (fm *FailoverManager) scheduleCheck(in time.Duration) {
+	fm.progress.mu.Lock()
+	fm.progress.nextCheck = time.Now().Add(in)
+	fm.progress.mu.Unlock()
+}
+
+// scheduleSync records when the next state sync runs
+func (fm *FailoverManager) scheduleSync(in time.Duration) {
+	fm.progress.mu.Lock()
+	fm.progress.nextSync = time.Now().Add(in)
+	fm.progress.mu.Unlock()
+}
+
+// Progress reports how close this node is to failover or failback. Role
+// and health changes are picked up when it is called, which happens after
+// every health check.
+func (fm *FailoverManager) Progress() *health.Progress {
+	fm.mu.RLock()
+	active := fm.isActive
+	failures := fm.failureCount
+	fm.mu.RUnlock()
+	healthy := fm.healthChecker.IsHealthy()
+
+	role := constants.NodeStatusPassive
+	if active {
+		role = constants.NodeStatusActive
+	}
+	state := string(role) + "/unhealthy"
+	if healthy {
+		state = string(role) + "/healthy"
+	}
+
+	now := time.Now()
+	fm.progress.mu.Lock()
+	defer fm.progress.mu.Unlock()
+	if state != fm.progress.state {
+		fm.progress.state = state
+		fm.progress.since = now
+	}
+
+	p := &health.Progress{
+		Role:                 string(role),
+		Since:                fm.progress.since.UTC(),
+		InStateSeconds:       now.Sub(fm.progress.since).Seconds(),
+		FailureCount:         failures,
+		FailoverAfter:        fm.cfg.Failover.RetryAttempts,
+		FailbackAfterSeconds: float64(fm.cfg.Failover.GracePeriod),
+	}
+	if !fm.progress.nextCheck.IsZero() {
+		p.NextCheck = fm.progress.nextCheck.UTC()
+	}
+	if !active && !fm.progress.nextSync.IsZero() {
+		next := fm.progress.nextSync.UTC()
+		p.NextStateSync = &next
+	}
+	return p
+}
+
+// PeerProgress is the peer's failover countdown as last exchanged over
+// heartbeats; nil without one
+func (fm *FailoverManager) PeerProgress() *health.Progress {
+	fm.heartbeats.mu.Lock()
+	defer fm.heartbeats.mu.Unlock()
+	return fm.heartbeats.peerProgress
+}
+
+// PeerTransitions sums up the peer's changes of role as last exchanged
+// over heartbeats; nil without one
+func (fm *FailoverManager) PeerTransitions() *health.Transitions {
+	fm.heartbeats.mu.Lock()
+	defer fm.heartbeats.mu.Unlock()
+	return fm.heartbeats.peerTransitions
+}
@@ -0,0 +1,58 @@
+package manager
+
+import (
+	"fmt"
+
+	"github.com/aldebaranode/syncguard/internal/metrics"
+	"github.com/aldebaranode/syncguard/internal/notify"
+	"github.com/aldebaranode/syncguard/internal/state"
+)
+
+var provenanceCounter = metrics.NewCounter(
+	"syncguard_state_provenance_anomalies_total",
+	"Replicated validator states whose provenance link was out of sequence or did not verify",
+	"kind",
+)
+
+// checkProvenance verifies the link of a state fetched from the active
+// peer and records anomalies. It reports whether the state may be synced
+// and whether a full resync is needed because updates went missing.
+func (fm *FailoverManager) checkProvenance(link *state.Link, remote *state.ValidatorState) (accept, resync bool) {
+	if link == nil {
+		return true, false
+	}
+
+	verdict := fm.provenance.Check(*link, remote, fm.secrets.Verify)
+	fields := map[string]string{
+		"epoch":  link.Epoch,
+		"seq":    fmt.Sprintf("%d", link.Seq),
+		"height": fmt.Sprintf("%d", remote.Height),
+	}
+	switch verdict {
+	case state.VerdictOK:
+		return true, false
+	case state.VerdictBaseline:
+		fm.logger.Info("Following validator state chain %s from update %d", link.Epoch, link.Seq)
+		return true, false
+	case state.VerdictGap:
+		provenanceCounter.Inc(string(verdict))
+		fm.logger.Warn("Missed validator state updates before update %d, resyncing", link.Seq)
+		fm.alert(notify.EventStateProvenance, notify.SeverityWarning,
+			"Missed validator state updates from the active node; resyncing", fields)
+		return true, true
+	case state.VerdictTampered:
+		provenanceCounter.Inc(string(verdict))
+		fm.logger.Error("Rejected validator state at height %d: provenance hash does not verify", remote.Height)
+		fm.alert(notify.EventStateProvenance, notify.SeverityCritical,
+			"Rejected a validator state whose provenance hash does not verify - possible tampering", fields)
+		return false, false
+	default:
+		provenanceCounter.Inc(string(verdict))
+		fm.logger.Error("Rejected validator state update %d: %s", link.Seq, verdict)
+		fm.alert(notify.EventStateProvenance, notify.SeverityCritical,
+			fmt.Sprintf("Rejected a validator state out of sequence (%s)", verdict), fields)
+		// Follow the chain from what the active node serves now
+		fm.provenance.Reset()
+		return false, true
+	}
+}
@@ -0,0 +1,158 @@
+package manager
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aldebaranode/syncguard/internal/notify"
+	"github.com/aldebaranode/syncguard/internal/state"
+)
+
+// bootIDPath is where Linux publishes an ID that changes on every boot
+const bootIDPath = "/proc/sys/kernel/random/boot_id"
+
+// bootPath records the boot ID of the host the node last started on
+func (fm *FailoverManager) bootPath() string {
+	return filepath.Join(fm.cfg.Node.DataDir, "boot_id")
+}
+
+// readBootID returns the host's boot ID, or "" where there is none
+func readBootID() string {
+	data, err := os.ReadFile(bootIDPath)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// recordBoot saves the boot ID the node started under, so the next start
+// can tell a restart from a reboot
+func (fm *FailoverManager) recordBoot() {
+	if fm.bootID == "" {
+		return
+	}
+	if err := os.WriteFile(fm.bootPath(), []byte(fm.bootID+"\n"), 0600); err != nil {
+		fm.logger.Warn("Failed to record boot ID: %v", err)
+	}
+}
+
+// rebooted reports whether the host booted since the last recorded start.
+// Hosts without a boot ID and the first start that records one never
+// count as rebooted.
+func (fm *FailoverManager) rebooted() bool {
+	data, err := os.ReadFile(fm.bootPath())
+	if err != nil || fm.bootID == "" {
+		return false
+	}
+	return strings.TrimSpace(string(data)) != fm.bootID
+}
+
+// guardReboot reconciles a node configured active whose host rebooted
+// since its last start. A power loss can leave the validator state or key
+// damaged, and the standby has likely taken over in the meantime, so the
+// node starts passive unless its data checks out and a peer answers
+// without holding the role. A node held here records nothing, so a restart
+// is held again.
+func (fm *FailoverManager) guardReboot() error {
+	fm.bootID = readBootID()
+	fm.mu.RLock()
+	held, skip := fm.awaitingConfirm, !fm.isActive || fm.firstBootConfirmed
+	fm.mu.RUnlock()
+	if held {
+		return nil
+	}
+	if skip || fm.cfg.Node.AfterReboot == "trust" || !fm.rebooted() {
+		fm.recordBoot()
+		return nil
+	}
+
+	fm.logger.Warn("Host rebooted since the last start: reconciling before signing")
+	local, err := fm.checkLocalData()
+	if err != nil {
+		return fm.holdAfterReboot(true, "Host rebooted and the local validator data failed its checks",
+			map[string]string{"error": err.Error()})
+	}
+	peer, answered := fm.peerTookOver(local)
+	if peer != "" {
+		return fm.holdAfterReboot(false, fmt.Sprintf("Host rebooted and %s took over meanwhile", peer),
+			map[string]string{"peer": peer})
+	}
+	if !answered && len(fm.cfg.Peers) > 0 {
+		return fm.holdAfterReboot(true, "Host rebooted and no peer answered to confirm this node", nil)
+	}
+
+	fm.logger.Info("Reboot reconciled: local data checks out and no peer holds the role")
+	fm.recordBoot()
+	return nil
+}
+
+// checkLocalData looks for what a crash can leave behind: a validator
+// state or key file that does not parse, or a state that went back below
+// the signing watermark
+func (fm *FailoverManager) checkLocalData() (*state.ValidatorState, error) {
+	local, err := fm.stateManager.LoadState()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fm.keyManager.LoadRealKey(); err != nil {
+		return nil, err
+	}
+	if fm.watermark != nil {
+		if floor := fm.watermark.Current().MinHeight; local.Height < floor {
+			return nil, fmt.Errorf("validator state height %d is below the signing watermark %d", local.Height, floor)
+		}
+	}
+	return local, nil
+}
+
+// peerTookOver returns the first peer that reports itself active or
+// signed past the local state; answered reports whether any peer did
+func (fm *FailoverManager) peerTookOver(local *state.ValidatorState) (peer string, answered bool) {
+	for _, p := range fm.cfg.Peers {
+		h, err := fm.client.FetchHealth(p.Address)
+		if err != nil {
+			continue
+		}
+		answered = true
+		if h.Active {
+			return p.ID, true
+		}
+		remote, _, err := fm.client.FetchState(p.Address, true)
+		if err == nil && remote.Height > local.Height {
+			return p.ID, true
+		}
+	}
+	return "", answered
+}
+
+// holdAfterReboot starts the node passive on the mock key. With confirm
+// set it waits for an operator, as on first boot; otherwise it is a
+// standby to the peer that took over.
+func (fm *FailoverManager) holdAfterReboot(confirm bool, message string, fields map[string]string) error {
+	if !fm.keyManager.IsDisabled() {
+		if err := fm.keyManager.DeleteKey(); err != nil {
+			return fmt.Errorf("failed to swap to the mock key after reboot: %w", err)
+		}
+	}
+	fm.mu.Lock()
+	fm.isActive = false
+	fm.awaitingConfirm = confirm
+	fm.mu.Unlock()
+
+	if !confirm {
+		fm.recordBoot()
+		fm.logger.Warn("%s: starting passive", message)
+		fm.alert(notify.EventReboot, notify.SeverityWarning, message+": starting passive", fields)
+		return nil
+	}
+	message += ": not signing until confirmed"
+	fm.logger.Error("%s. Run `syncguard cluster confirm-active` once no other node signs for this validator", message)
+	if fields == nil {
+		fields = map[string]string{}
+	}
+	fields["confirm"] = "syncguard cluster confirm-active"
+	fm.alert(notify.EventReboot, notify.SeverityCritical, message, fields)
+	return nil
+}
@@ -0,0 +1,135 @@
+package manager
+
+import (
+	"fmt"
+	"maps"
+	"path/filepath"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/health"
+	"github.com/aldebaranode/syncguard/internal/notify"
+	"github.com/aldebaranode/syncguard/internal/state"
+)
+
+// roleStartup is the reason recorded when the node starts in another role
+// than the record says, e.g. held on the mock key
+const roleStartup = "startup"
+
+// rolePath is the role record in node.data_dir
+func rolePath(dataDir string) string {
+	return filepath.Join(dataDir, "role.json")
+}
+
+// saveRole records the current role, the transition in progress if any,
+// and reason when the role changed. A node held on first boot or after a
+// reboot records nothing, so a restart is held again.
+func (fm *FailoverManager) saveRole(inProgress, reason string) {
+	if fm.AwaitingConfirmation() {
+		return
+	}
+	active := fm.IsActive()
+
+	fm.roleMu.Lock()
+	defer fm.roleMu.Unlock()
+	rec := fm.role
+	rec.Transition = inProgress
+	if rec.Active != active || rec.Changed.IsZero() {
+		if !rec.Changed.IsZero() {
+			rec.Transitions++
+		}
+		rec.Active = active
+		rec.Changed = time.Now().UTC()
+		rec.Reason = reason
+	}
+	if err := fm.roleStore.Save(rec); err != nil {
+		fm.logger.Error("Failed to save role record: %v", err)
+		return
+	}
+	fm.role = rec
+}
+
+// TransitionSummary sums up this node's changes of role from its role
+// record, so they survive restarts
+func (fm *FailoverManager) TransitionSummary() *health.Transitions {
+	fm.roleMu.Lock()
+	defer fm.roleMu.Unlock()
+	return &health.Transitions{
+		Epoch:   fm.role.Transitions,
+		Last:    maps.Clone(fm.role.Last),
+		Reasons: maps.Clone(fm.role.Reasons),
+	}
+}
+
+// restoreRole resumes the role recorded before the restart instead of
+// node.role: a node that failed over stays passive, and one that took
+// over stays active as long as the validator key is still in place.
+// Either way, a node about to start active starts passive while a peer
+// reports itself active.
+func (fm *FailoverManager) restoreRole() error {
+	rec, err := fm.roleStore.Load()
+	if err != nil {
+		fm.logger.Warn("Failed to load role record, starting as node.role: %v", err)
+	}
+	if rec != nil {
+		fm.roleMu.Lock()
+		fm.role = *rec
+		fm.roleMu.Unlock()
+		if rec.Transition != "" {
+			fm.logger.Warn("A %s transition was in progress when syncguard stopped", rec.Transition)
+		}
+		if err := fm.resumeRole(rec); err != nil {
+			return err
+		}
+	}
+
+	if !fm.IsActive() {
+		return nil
+	}
+	for _, peer := range fm.cfg.Peers {
+		if h, err := fm.client.FetchHealth(peer.Address); err == nil && h.Active {
+			if err := fm.startPassive(); err != nil {
+				return err
+			}
+			message := fmt.Sprintf("Peer %s is active: starting passive", peer.ID)
+			fm.logger.Warn("%s", message)
+			fm.alert(notify.EventRecovery, notify.SeverityWarning, message, map[string]string{"peer": peer.ID})
+			return nil
+		}
+	}
+	return nil
+}
+
+// resumeRole takes the recorded role when it differs from node.role
+func (fm *FailoverManager) resumeRole(rec *state.RoleRecord) error {
+	if rec.Active == fm.IsActive() {
+		return nil
+	}
+	since := rec.Changed.Format(time.RFC3339)
+	if !rec.Active {
+		fm.logger.Warn("Resuming the passive role held since %s (%s), not node.role", since, rec.Reason)
+		return fm.startPassive()
+	}
+	if fm.keyManager.IsDisabled() {
+		fm.logger.Warn("Recorded active since %s, but the validator key is disabled: starting passive as node.role says", since)
+		return nil
+	}
+	fm.logger.Warn("Resuming the active role held since %s (%s), not node.role", since, rec.Reason)
+	fm.mu.Lock()
+	fm.isActive = true
+	fm.mu.Unlock()
+	return nil
+}
+
+// startPassive swaps to the mock key and makes the node passive before
+// its node starts
+func (fm *FailoverManager) startPassive() error {
+	if !fm.keyManager.IsDisabled() {
+		if err := fm.keyManager.DeleteKey(); err != nil {
+			return fmt.Errorf("failed to swap to the mock key: %w", err)
+		}
+	}
+	fm.mu.Lock()
+	fm.isActive = false
+	fm.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,142 @@
+package manager
+
+import (
+	"fmt"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/health"
+	"github.com/aldebaranode/syncguard/internal/history"
+	"github.com/aldebaranode/syncguard/internal/logger"
+	"github.com/aldebaranode/syncguard/internal/node"
+	"github.com/aldebaranode/syncguard/internal/notify"
+)
+
+// newNodeManager builds the manager for the validator's processes. A lone
+// consensus client is managed directly; with a managed execution client or
+// extra services, all of them are managed as one ordered stack.
+func newNodeManager(cfg *config.Config) (node.Manager, error) {
+	stopTimeout := cfg.Validator.StopTimeout.Duration()
+	restartDelay := cfg.Validator.RestartDelay.Duration()
+
+	newManager := func(module string, nc node.Config) node.Manager {
+		log := logger.New(cfg, module)
+		nc.StopTimeout = stopTimeout
+		nc.RestartDelay = restartDelay
+		return node.NewManager(nc, log)
+	}
+
+	consensus := newManager("node", node.Config{
+		Mode:        cfg.Validator.Mode,
+		Binary:      cfg.Validator.Binary,
+		Args:        cfg.Validator.Args,
+		Container:   cfg.Validator.Container,
+		ComposeFile: cfg.Validator.ComposeFile,
+		Service:     cfg.Validator.Service,
+	})
+	if !cfg.Execution.Managed && len(cfg.Validator.Services) == 0 {
+		return consensus, nil
+	}
+
+	services := []node.Service{{
+		Name:      "consensus",
+		Manager:   consensus,
+		DependsOn: cfg.Validator.DependsOn,
+	}}
+
+	// The consensus client drives the execution client over the Engine
+	// API, so the execution client starts first and stops last
+	if cfg.Execution.Managed {
+		services[0].DependsOn = append([]string{"execution"}, services[0].DependsOn...)
+		services = append(services, node.Service{
+			Name: "execution",
+			Manager: newManager("execution", node.Config{
+				Mode:        cfg.Execution.Mode,
+				Binary:      cfg.Execution.Binary,
+				Args:        cfg.Execution.Args,
+				Container:   cfg.Execution.Container,
+				ComposeFile: cfg.Execution.ComposeFile,
+				Service:     cfg.Execution.Service,
+			}),
+		})
+	}
+
+	for _, svc := range cfg.Validator.Services {
+		services = append(services, node.Service{
+			Name: svc.Name,
+			Manager: newManager(svc.Name, node.Config{
+				Mode:        svc.Mode,
+				Binary:      svc.Binary,
+				Args:        svc.Args,
+				Container:   svc.Container,
+				ComposeFile: svc.ComposeFile,
+				Service:     svc.Service,
+			}),
+			DependsOn: svc.DependsOn,
+		})
+	}
+
+	stackLogger := logger.New(cfg, "node")
+	stack, err := node.NewStackManager(services, "consensus", restartDelay, stackLogger)
+	if err != nil {
+		return nil, fmt.Errorf("invalid validator services: %w", err)
+	}
+	return stack, nil
+}
+
+// Services reports the managed processes in start order
+func (fm *FailoverManager) Services() []node.ServiceStatus {
+	switch m := fm.nodeManager.(type) {
+	case nil:
+		return nil
+	case *node.StackManager:
+		return m.Status()
+	default:
+		return []node.ServiceStatus{{Name: "consensus", Running: m.IsRunning()}}
+	}
+}
+
+// healthCommandEntryType is the history type of health command results
+const healthCommandEntryType = "health_cmd"
+
+// recordHealthCommand stores the health command's output in the history
+// when its outcome changes, so the first failure and the recovery are kept
+// without an entry per check
+func (fm *FailoverManager) recordHealthCommand(result *health.CommandResult) {
+	if result == nil {
+		return
+	}
+
+	fm.mu.Lock()
+	last := fm.lastCommand
+	fm.lastCommand = result
+	fm.mu.Unlock()
+	if last != nil && last.ExitCode == result.ExitCode && last.Error == result.Error {
+		return
+	}
+
+	severity := notify.SeverityInfo
+	message := "Health command passed"
+	if !result.Passed() {
+		severity = notify.SeverityWarning
+		message = fmt.Sprintf("Health command failed with exit code %d", result.ExitCode)
+	}
+	fields := map[string]string{
+		"command":   result.Command,
+		"exit_code": fmt.Sprintf("%d", result.ExitCode),
+		"duration":  result.Duration.String(),
+		"output":    result.Output,
+	}
+	if result.Error != "" {
+		fields["error"] = result.Error
+	}
+	if err := fm.history.Append(history.Entry{
+		Kind:     history.KindEvent,
+		Type:     healthCommandEntryType,
+		NodeID:   fm.cfg.Node.ID,
+		Severity: severity.String(),
+		Message:  message,
+		Fields:   fields,
+	}); err != nil {
+		fm.logger.Warn("Failed to record health command result: %v", err)
+	}
+}
@@ -0,0 +1,51 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aldebaranode/syncguard/internal/constants"
+	"github.com/aldebaranode/syncguard/internal/notify"
+	"github.com/aldebaranode/syncguard/internal/supervise"
+)
+
+// HandOverOnShutdown hands validator duties to the best standby when the
+// process is asked to stop on the active node, so the cluster is not left
+// without a signer. It waits at most failover.shutdown_handoff.deadline;
+// when the handoff is refused or runs past it, the node stops as it would
+// without: a handoff past the deadline is aborted, and Stop waits for that.
+// A paused node stops without handing over, e.g. to upgrade syncguard
+// itself.
+func (fm *FailoverManager) HandOverOnShutdown() {
+	sh := fm.cfg.Failover.ShutdownHandoff
+	if !sh.Enabled || !fm.IsActive() {
+		return
+	}
+	if fm.IsPaused() {
+		fm.logger.Warn("Stopping while active and paused: not handing over")
+		return
+	}
+
+	deadline := sh.Deadline.Duration()
+	fm.logger.Info("Stopping while active: handing over to the standby first (deadline %s)", deadline)
+	ctx, cancel := context.WithTimeout(context.Background(), deadline)
+	defer cancel()
+	done := make(chan error, 1)
+	supervise.Once(fm.logger, "shutdown-handoff", func() {
+		done <- fm.handOff(ctx, constants.ReasonShutdown, "shutdown", "Shutdown handoff")
+	})
+
+	var err error
+	select {
+	case err = <-done:
+		if err == nil {
+			fm.logger.Info("Handed over before stopping")
+			return
+		}
+	case <-ctx.Done():
+		err = fmt.Errorf("no handoff within %s", deadline)
+	}
+	fm.logger.Error("Stopping without handing over: %v", err)
+	fm.alert(notify.EventFailover, notify.SeverityCritical, "Active node stopping without handing over",
+		map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,36 @@
+package manager
+
+import (
+	"fmt"
+
+	"github.com/aldebaranode/syncguard/internal/signer"
+)
+
+// startSigner serves the node's signing requests with signer.enabled. It
+// starts before the node, which waits for its signer at startup.
+func (fm *FailoverManager) startSigner() error {
+	if !fm.cfg.Signer.Enabled || !fm.automated() {
+		return nil
+	}
+	s, err := signer.New(fm.cfg, fm.whileActive, fm.logger.WithModule("signer"))
+	if err != nil {
+		return fmt.Errorf("failed to set up remote signer: %w", err)
+	}
+	if err := s.Start(); err != nil {
+		return err
+	}
+	fm.signer = s
+	return nil
+}
+
+// whileActive runs sign only while this node holds the validator role. A
+// transition waits for sign to return before it changes the role, so no
+// signature is made while the peer takes over.
+func (fm *FailoverManager) whileActive(sign func() error) error {
+	fm.mu.RLock()
+	defer fm.mu.RUnlock()
+	if !fm.isActive {
+		return signer.ErrNotActive
+	}
+	return sign()
+}
@@ -0,0 +1,105 @@
+package manager
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/notify"
+)
+
+// guardTakeover refreshes the validator state from the peer handing over
+// before the chain is checked against it: the periodic sync may be a few
+// blocks behind what the peer signed before it stopped
+func (fm *FailoverManager) guardTakeover() error {
+	if fm.signGuard == nil {
+		return nil
+	}
+	if err := fm.syncStateFromPeer(true); err != nil {
+		fm.logger.Warn("Double-sign guard could not refresh the validator state from the peer: %v", err)
+	}
+	return fm.checkChainSignatures()
+}
+
+// checkChainSignatures refuses to sign while one of the latest
+// chain.double_sign_guard.depth blocks carries our validator's precommit
+// above the local validator state: another node signed past what this
+// one would resume from. An RPC that cannot tell refuses too.
+func (fm *FailoverManager) checkChainSignatures() error {
+	if fm.signGuard == nil {
+		return nil
+	}
+	info, ok := fm.chain.Info()
+	if !ok {
+		return fmt.Errorf("double-sign guard: validator address not discovered yet")
+	}
+	current, err := fm.stateManager.LoadState()
+	if err != nil {
+		return fmt.Errorf("double-sign guard: %w", err)
+	}
+
+	height, err := fm.signGuard.SignedAbove(info.Address, current.Height)
+	if err != nil {
+		return fmt.Errorf("double-sign guard could not read the chain: %w", err)
+	}
+	if height == 0 {
+		return nil
+	}
+	message := fmt.Sprintf("Validator signed block %d on chain, above the local validator state at %d", height, current.Height)
+	fm.alert(notify.EventDoubleSignGuard, notify.SeverityCritical, message+" - refusing to sign", map[string]string{
+		"address":       info.Address,
+		"signed_height": fmt.Sprintf("%d", height),
+		"local_state":   fmt.Sprintf("%d", current.Height),
+	})
+	return fmt.Errorf("%s", message)
+}
+
+// recordSignatures records the validator state the signer writes while
+// this node is active, every health.interval, so a later takeover can
+// tell whether the state it would resume from is behind what this node
+// signed, even across restarts
+func (fm *FailoverManager) recordSignatures() {
+	ticker := time.NewTicker(fm.cfg.Health.Interval.Duration())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if fm.IsActive() {
+				fm.recordSignature()
+			}
+		case <-fm.stopCh:
+			return
+		}
+	}
+}
+
+// recordSignature records the current validator state
+func (fm *FailoverManager) recordSignature() {
+	current, err := fm.stateManager.LoadState()
+	if err != nil {
+		fm.logger.Warn("Failed to read the validator state to record: %v", err)
+		return
+	}
+	if err := fm.signatures.ObserveState(current); err != nil {
+		fm.logger.Warn("Failed to record signature: %v", err)
+	}
+}
+
+// checkSignedState refuses to sign from a local validator state behind
+// the last signature this node recorded: a state restored from a backup,
+// or synced from a peer that lost its own, would let the signer sign
+// again where it already did
+func (fm *FailoverManager) checkSignedState() error {
+	current, err := fm.stateManager.LoadState()
+	if err != nil {
+		return fmt.Errorf("failed to read the validator state: %w", err)
+	}
+	if err := fm.signatures.CheckState(current); err != nil {
+		fm.alert(notify.EventDoubleSignGuard, notify.SeverityCritical, "Refusing to sign: "+err.Error(), map[string]string{
+			"local_state":   fmt.Sprintf("%d", current.Height),
+			"signed_height": fmt.Sprintf("%d", fm.signatures.GetLastSignedHeight()),
+		})
+		return err
+	}
+	return nil
+}
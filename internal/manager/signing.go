@@ -0,0 +1,200 @@
+package manager
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/chain"
+	"github.com/aldebaranode/syncguard/internal/history"
+	"github.com/aldebaranode/syncguard/internal/notify"
+)
+
+// signingState is the watchdog's view of the signing activity feed
+type signingState struct {
+	mu sync.Mutex
+	// activeSince is when this node was first seen active by the feed;
+	// blocks before it were another node's to sign
+	activeSince time.Time
+	alerted     bool
+
+	// recorded is the last block counted into a history summary, and
+	// summary the blocks counted since the last one was written
+	recorded int64
+	summary  signingSummary
+}
+
+// signingSummary counts examined blocks between two history entries
+type signingSummary struct {
+	start, end time.Time
+	from, to   int64
+	signed     int
+	missed     int
+}
+
+// signingEntryType is the history entry type of a signing summary
+const signingEntryType = "signing"
+
+// signingSummaryInterval is the block time one history signing summary
+// spans
+const signingSummaryInterval = time.Hour
+
+// monitorSigning polls the signing activity feed every
+// chain.signing.interval for the validator found by discovery
+func (fm *FailoverManager) monitorSigning() {
+	ticker := time.NewTicker(fm.cfg.Chain.Signing.Interval.Duration())
+	defer ticker.Stop()
+
+	for {
+		if info, ok := fm.chain.Info(); ok {
+			if _, err := fm.signing.Poll(info.Address); err != nil {
+				fm.logger.Warn("Signing activity feed: %v", err)
+			}
+			fm.checkSigning()
+			fm.summarizeSigning(false)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-fm.stopCh:
+			return
+		}
+	}
+}
+
+// checkSigning alerts once while the active node's precommits are missing
+// from chain.signing.missed_blocks blocks in a row, and once when they
+// are back
+func (fm *FailoverManager) checkSigning() {
+	active := fm.IsActive()
+	fm.signingState.mu.Lock()
+	switch {
+	case !active:
+		fm.signingState.activeSince = time.Time{}
+	case fm.signingState.activeSince.IsZero():
+		fm.signingState.activeSince = time.Now()
+	}
+	since := fm.signingState.activeSince
+	fm.signingState.mu.Unlock()
+	if !active {
+		return
+	}
+
+	missed := fm.signing.MissedSince(since)
+	activity, _ := fm.signing.Activity()
+	failing := missed >= fm.cfg.Chain.Signing.MissedBlocks
+
+	fm.signingState.mu.Lock()
+	changed := failing != fm.signingState.alerted
+	fm.signingState.alerted = failing
+	fm.signingState.mu.Unlock()
+	if !changed {
+		return
+	}
+
+	fields := map[string]string{
+		"address":            activity.Address,
+		"height":             fmt.Sprintf("%d", activity.Height),
+		"consecutive_missed": fmt.Sprintf("%d", missed),
+		"last_signed_height": fmt.Sprintf("%d", activity.LastSignedHeight),
+	}
+	if failing {
+		message := fmt.Sprintf("Active node is not signing: the last %d blocks lack its precommit", missed)
+		fm.logger.Error("%s", message)
+		fm.alert(notify.EventNotSigning, notify.SeverityCritical, message, fields)
+		return
+	}
+	fm.logger.Info("Precommits from this node are in blocks again")
+	fm.alert(notify.EventNotSigning, notify.SeverityInfo, "Active node is signing again", fields)
+}
+
+// summarizeSigning counts the blocks examined since the last call and
+// records them in history once they span signingSummaryInterval, or
+// whatever there is when flush is set. Reports read these entries; the
+// feed itself only keeps a window of blocks.
+func (fm *FailoverManager) summarizeSigning(flush bool) {
+	if fm.signing == nil {
+		return
+	}
+	activity, ok := fm.signing.Activity()
+	if !ok {
+		return
+	}
+
+	fm.signingState.mu.Lock()
+	sum := &fm.signingState.summary
+	for _, b := range fm.signing.Blocks(time.Time{}) {
+		if b.Height <= fm.signingState.recorded {
+			continue
+		}
+		fm.signingState.recorded = b.Height
+		if sum.from == 0 {
+			sum.from, sum.start = b.Height, b.Time
+		}
+		sum.to, sum.end = b.Height, b.Time
+		if b.Signed {
+			sum.signed++
+		} else {
+			sum.missed++
+		}
+	}
+	due := sum.from != 0 && (flush || sum.end.Sub(sum.start) >= signingSummaryInterval)
+	done := *sum
+	if due {
+		*sum = signingSummary{}
+	}
+	fm.signingState.mu.Unlock()
+	if !due {
+		return
+	}
+
+	if err := fm.history.Append(history.Entry{
+		Kind:   history.KindEvent,
+		Type:   signingEntryType,
+		NodeID: fm.cfg.Node.ID,
+		Message: fmt.Sprintf("Validator signed %d of %d blocks (%d-%d)",
+			done.signed, done.signed+done.missed, done.from, done.to),
+		Fields: map[string]string{
+			"chain_id":    activity.ChainID,
+			"address":     activity.Address,
+			"from_height": fmt.Sprintf("%d", done.from),
+			"to_height":   fmt.Sprintf("%d", done.to),
+			"start":       done.start.UTC().Format(time.RFC3339Nano),
+			"end":         done.end.UTC().Format(time.RFC3339Nano),
+			"signed":      fmt.Sprintf("%d", done.signed),
+			"missed":      fmt.Sprintf("%d", done.missed),
+		},
+	}); err != nil {
+		fm.logger.Warn("Failed to record signing history: %v", err)
+	}
+}
+
+// notSigning returns the blocks in a row without our precommit when
+// chain.signing.enforce makes them count as a failed check; 0 otherwise
+func (fm *FailoverManager) notSigning() int {
+	if fm.signing == nil || !fm.cfg.Chain.Signing.Enforce || !fm.IsActive() {
+		return 0
+	}
+	fm.signingState.mu.Lock()
+	alerted, since := fm.signingState.alerted, fm.signingState.activeSince
+	fm.signingState.mu.Unlock()
+	if !alerted {
+		return 0
+	}
+	return fm.signing.MissedSince(since)
+}
+
+// chainID returns the chain ID the signing feed saw; "" while unknown
+func (fm *FailoverManager) chainID() string {
+	activity, _ := fm.SigningActivity()
+	return activity.ChainID
+}
+
+// SigningActivity reports our validator's precommits in recent blocks;
+// false while the feed is disabled or has not polled yet
+func (fm *FailoverManager) SigningActivity() (chain.SigningActivity, bool) {
+	if fm.signing == nil {
+		return chain.SigningActivity{}, false
+	}
+	return fm.signing.Activity()
+}
@@ -0,0 +1,128 @@
+package manager
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aldebaranode/syncguard/internal/communication"
+	"github.com/aldebaranode/syncguard/internal/health"
+)
+
+// preheat swaps a passive node to the mock key before its node starts, so
+// a preheated standby stays synced without ever being able to sign
+func (fm *FailoverManager) preheat() error {
+	if !fm.cfg.Failover.Preheat || fm.isActive || fm.keyManager.IsDisabled() {
+		return nil
+	}
+	fm.logger.Info("Preheat: swapping to the mock key before starting the standby node")
+	if err := fm.keyManager.DeleteKey(); err != nil {
+		return fmt.Errorf("failed to swap to the mock key: %w", err)
+	}
+	return nil
+}
+
+// keepStandbyRunning starts a preheated standby's node again when it has
+// stopped, so a takeover never needs a cold start. It only does so on the
+// mock key: a real key on a passive node means a takeover is under way.
+func (fm *FailoverManager) keepStandbyRunning() {
+	if !fm.cfg.Failover.Preheat || fm.nodeManager == nil || fm.IsActive() || fm.nodeManager.IsRunning() {
+		return
+	}
+	if !fm.keyManager.IsDisabled() {
+		fm.logger.Warn("Standby node is not running, but the validator key is in place; not starting it")
+		return
+	}
+	fm.logger.Warn("Standby node stopped, starting it again on the mock key")
+	if err := fm.nodeManager.Start(); err != nil {
+		fm.logger.Error("Failed to start standby node: %v", err)
+	}
+}
+
+// assessStandby scores this passive node's readiness to take over after a
+// health check; nodeHealth is nil when the check failed. A change between
+// ready and not ready is logged.
+func (fm *FailoverManager) assessStandby(nodeHealth *health.NodeHealth) {
+	if fm.IsActive() {
+		return
+	}
+
+	tip := fm.activeHeight()
+	if tip > 0 {
+		fm.healthChecker.ObserveTip(tip)
+	}
+	in := health.ReadinessInput{
+		Running: fm.nodeManager == nil || fm.nodeManager.IsRunning(),
+		Healthy: fm.healthChecker.IsHealthy(),
+		Height:  fm.healthChecker.GetLastHeight(),
+		Tip:     tip,
+		MaxLag:  fm.cfg.Failover.StandbyMaxLag,
+	}
+	if nodeHealth != nil {
+		in.Syncing = nodeHealth.IsSyncing
+		in.Height = nodeHealth.LatestHeight
+	} else {
+		in.Healthy = false
+	}
+	readiness := health.ScoreReadiness(in)
+	if fm.cfg.Health.Policy.Takeover != "" {
+		readiness.Ready = fm.healthChecker.TakeoverAllowed(readiness, in.Running)
+		if !readiness.Ready {
+			readiness.Reasons = append(readiness.Reasons, "takeover policy not met: "+fm.cfg.Health.Policy.Takeover)
+		}
+	}
+	readiness.Publish()
+
+	fm.mu.Lock()
+	previous := fm.readiness
+	fm.readiness = &readiness
+	fm.mu.Unlock()
+
+	switch {
+	case !readiness.Ready && (previous == nil || previous.Ready):
+		fm.logger.Warn("Standby not ready to take over (score %d): %s",
+			readiness.Score, strings.Join(readiness.Reasons, "; "))
+	case readiness.Ready && previous != nil && !previous.Ready:
+		fm.logger.Info("Standby ready to take over again")
+	}
+}
+
+// activeHeight is the active node's height: from its last heartbeat while
+// those arrive, otherwise asked from the peer; 0 when unknown
+func (fm *FailoverManager) activeHeight() int64 {
+	fm.heartbeats.mu.Lock()
+	last, stale := fm.heartbeats.last, fm.heartbeats.stale
+	fm.heartbeats.mu.Unlock()
+	if last != nil && !stale {
+		return last.Height
+	}
+
+	if len(fm.cfg.Peers) == 0 {
+		return 0
+	}
+	peer, err := fm.client.FetchHealth(fm.sourcePeer().Address)
+	if err != nil || !peer.Active {
+		return 0
+	}
+	return peer.Height
+}
+
+// Readiness scores this node's readiness to take over; nil while active or
+// before the first health check
+func (fm *FailoverManager) Readiness() *health.Readiness {
+	fm.mu.RLock()
+	defer fm.mu.RUnlock()
+	if fm.isActive {
+		return nil
+	}
+	return fm.readiness
+}
+
+// standbyReady explains why a standby cannot take over quickly; nil when
+// it can, or runs a version that does not report its readiness
+func standbyReady(id string, peer *communication.PeerHealth) error {
+	if peer.Readiness == nil || peer.Readiness.Ready {
+		return nil
+	}
+	return fmt.Errorf("standby %s is not ready to take over (score %d): %s",
+		id, peer.Readiness.Score, strings.Join(peer.Readiness.Reasons, "; "))
+}
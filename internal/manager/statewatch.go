@@ -0,0 +1,61 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/state"
+)
+
+// errBecameActive ends the state stream once this node took over
+var errBecameActive = errors.New("node became active")
+
+// watchValidatorState follows the source peer's validator state over a
+// gRPC stream while passive (peer_api.protocol: grpc), taking each state
+// as it changes rather than every state_sync_interval. The periodic sync
+// stands in whenever the stream is down.
+func (fm *FailoverManager) watchValidatorState() {
+	retry := fm.cfg.Failover.StateSyncInterval.Duration()
+	for {
+		if !fm.IsActive() && len(fm.cfg.Peers) > 0 {
+			fm.watchStateOnce()
+		}
+		select {
+		case <-time.After(retry):
+		case <-fm.stopCh:
+			return
+		}
+	}
+}
+
+// watchStateOnce streams the state from the source peer until the stream
+// ends, this node takes over or the manager stops
+func (fm *FailoverManager) watchStateOnce() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-fm.stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	source := fm.sourcePeer()
+	err := fm.client.WatchState(ctx, source.Address, func(remoteState *state.ValidatorState, link *state.Link) error {
+		if fm.IsActive() {
+			return errBecameActive
+		}
+		if err := fm.applyRemoteState(remoteState, link, false); err != nil {
+			fm.logger.Error("State sync error: %v", err)
+			return nil
+		}
+		fm.stateStreaming.Store(true)
+		return nil
+	})
+	fm.stateStreaming.Store(false)
+	if err != nil && !errors.Is(err, errBecameActive) && ctx.Err() == nil {
+		fm.logger.Debug("State stream from %s ended: %v", source.ID, err)
+	}
+}
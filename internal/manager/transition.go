@@ -0,0 +1,81 @@
+package manager
+
+import (
+	"errors"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/constants"
+	"github.com/aldebaranode/syncguard/internal/history"
+	"github.com/aldebaranode/syncguard/internal/logger"
+	"github.com/aldebaranode/syncguard/internal/metrics"
+	"github.com/aldebaranode/syncguard/internal/notify"
+	"github.com/aldebaranode/syncguard/internal/transition"
+)
+
+var transitionCounter = metrics.NewCounter(
+	"syncguard_transitions_total",
+	"Changes of active role on this node, by type and reason code",
+	"type", "reason",
+)
+
+// transitionAlert reports a change of active role with its reason code:
+// it is counted, kept as the last transition and sent like any alert.
+// It does not take fm.mu, so callers may hold it.
+func (fm *FailoverManager) transitionAlert(eventType notify.EventType, severity notify.Severity,
+	message string, reason constants.Reason, fields map[string]string) {
+	transitionCounter.Inc(string(eventType), string(reason))
+	if id := logger.TransitionID(); id != "" {
+		tagged := map[string]string{"transition_id": id}
+		for k, v := range fields {
+			tagged[k] = v
+		}
+		fields = tagged
+	}
+
+	entry := history.Entry{
+		Time:     time.Now().UTC(),
+		Kind:     history.KindEvent,
+		Type:     string(eventType),
+		NodeID:   fm.cfg.Node.ID,
+		Severity: severity.String(),
+		Message:  message,
+		Reason:   string(reason),
+		Fields:   fields,
+	}
+	fm.transitionMu.Lock()
+	fm.lastTransition = &entry
+	fm.transitionMu.Unlock()
+	// saveRole persists the counts with the role they led to
+	fm.roleMu.Lock()
+	fm.role.Count(string(eventType), string(reason), entry.Time)
+	fm.roleMu.Unlock()
+
+	fm.emit(entry, severity)
+}
+
+// LastTransition returns the most recent change of role since start, or nil
+func (fm *FailoverManager) LastTransition() *history.Entry {
+	fm.transitionMu.Lock()
+	defer fm.transitionMu.Unlock()
+	return fm.lastTransition
+}
+
+// RunTransition carries out a transition the peer requested on the
+// transition executor, serialized with this node's own. A monitor-only
+// node refuses it.
+func (fm *FailoverManager) RunTransition(kind, reason string, run func() error) error {
+	if !fm.automated() {
+		fm.logger.Warn("Refused peer-requested %s: %v", kind, errMonitorOnly)
+		return errMonitorOnly
+	}
+	err := fm.transitions.Submit(transition.Request{Kind: kind, Reason: reason, Source: "peer", Run: run})
+	if errors.Is(err, transition.ErrContradictory) {
+		fm.logger.Warn("Refused peer-requested %s: %v", kind, err)
+	}
+	return err
+}
+
+// Transitions reports the transitions in flight
+func (fm *FailoverManager) Transitions() transition.Status {
+	return fm.transitions.Status()
+}
@@ -0,0 +1,62 @@
+package manager
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aldebaranode/syncguard/internal/notify"
+)
+
+// handoffRecommendation is attached to host pre-alerts: unlike a slow RPC,
+// an exhausted host rarely recovers on its own before blocks are missed
+const handoffRecommendation = "plan a handoff to a standby: syncguard cluster handoff"
+
+// checkTrends sends a "degrading" pre-alert when a passing check is
+// trending toward failure, once per check until the trend clears, so the
+// operator can plan a handoff before an emergency failover
+func (fm *FailoverManager) checkTrends() {
+	degradations := fm.healthChecker.Degradations()
+	if fm.hostMonitor != nil && fm.IsActive() {
+		degradations = append(degradations, fm.hostMonitor.Degradations()...)
+	}
+
+	current := make(map[string]bool)
+	for _, d := range degradations {
+		current[d.Check] = true
+		if fm.degrading[d.Check] {
+			continue
+		}
+		fm.logger.Warn("Degrading: %s (now %.2f, predicted %.2f)", d.Message, d.Current, d.Predicted)
+		fields := map[string]string{
+			"check":     d.Check,
+			"current":   fmt.Sprintf("%.2f", d.Current),
+			"predicted": fmt.Sprintf("%.2f", d.Predicted),
+			"threshold": fmt.Sprintf("%.2f", d.Threshold),
+		}
+		if strings.HasPrefix(d.Check, "host:") && len(fm.cfg.Peers) > 0 {
+			fields["recommendation"] = handoffRecommendation
+		}
+		fm.alert(notify.EventDegrading, notify.SeverityWarning, d.Message, fields)
+	}
+	for check := range fm.degrading {
+		if !current[check] {
+			fm.logger.Info("Trend cleared for %s", check)
+		}
+	}
+	fm.degrading = current
+}
+
+// sampleHost records the host's resource use for its pre-alerts. Every
+// node samples, so a passive that takes over already has a history.
+func (fm *FailoverManager) sampleHost() {
+	if fm.hostMonitor == nil {
+		return
+	}
+	usage, err := fm.hostMonitor.Sample()
+	if err != nil {
+		fm.logger.Debug("Failed to sample host resources: %v", err)
+		return
+	}
+	fm.logger.Debug("Host: load=%.2f/cpu memory=%.0f%% swap=%.0f/s iowait=%.0f%%",
+		usage.LoadPerCPU, usage.MemoryUsed*100, usage.SwapRate, usage.IOWait*100)
+}
@@ -0,0 +1,51 @@
+package manager
+
+import (
+	"github.com/aldebaranode/syncguard/internal/health"
+	"github.com/aldebaranode/syncguard/internal/metrics"
+	"github.com/aldebaranode/syncguard/internal/notify"
+)
+
+var (
+	healthStateGauge = metrics.NewGauge(
+		"syncguard_health_state",
+		"1 for the verdict on the last health check: healthy, unhealthy or unknown",
+		"state",
+	)
+	healthChecksCounter = metrics.NewCounter(
+		"syncguard_health_checks_total",
+		"Health checks by verdict: healthy, unhealthy or unknown",
+		"state",
+	)
+)
+
+// trackUnknown publishes the verdict on a health check and alerts when
+// the node's signing status becomes unknown, or known again. It is kept
+// apart from unhealthy, which has its own alert.
+func (fm *FailoverManager) trackUnknown(state health.State) {
+	for _, s := range health.States {
+		value := 0.0
+		if s == state {
+			value = 1
+		}
+		healthStateGauge.Set(value, string(s))
+	}
+	healthChecksCounter.Inc(string(state))
+
+	unknown := state == health.StateUnknown
+	fm.mu.Lock()
+	changed := unknown != fm.wasUnknown
+	fm.wasUnknown = unknown
+	fm.mu.Unlock()
+	if !changed {
+		return
+	}
+
+	fields := map[string]string{"counts_as": fm.cfg.Health.Unknown}
+	if unknown {
+		fm.alert(notify.EventHealthUnknown, notify.SeverityWarning,
+			"The node answers but its signing status is unknown: /status reports no validator key", fields)
+	} else {
+		fm.alert(notify.EventHealthUnknown, notify.SeverityInfo, "The node's signing status is known again", fields)
+	}
+}
@@ -0,0 +1,39 @@
+package manager
+
+import "fmt"
+
+// CheckActivation vetoes a takeover the peer from asked for while this
+// host is under maintenance, the witness does not agree, or the chain or
+// this node's own signature records hold signatures the local validator
+// state is missing; it satisfies server.ActivationGuard
+func (fm *FailoverManager) CheckActivation(from string) error {
+	if err := fm.checkMaintenance(); err != nil {
+		return err
+	}
+	if err := fm.witnessAgrees(from); err != nil {
+		return err
+	}
+	if err := fm.guardTakeover(); err != nil {
+		return err
+	}
+	return fm.checkSignedState()
+}
+
+// witnessAgrees asks the witness for its vote. The witness grants it only
+// if it can reach this node and sees no other node active and healthy but
+// from, the peer handing over, so a node that is itself cut off cannot
+// take over. Without an answer there is no agreement.
+func (fm *FailoverManager) witnessAgrees(from string) error {
+	if fm.witness == nil {
+		return nil
+	}
+	vote, err := fm.witness.RequestVote(from)
+	if err != nil {
+		return fmt.Errorf("witness unreachable: %w", err)
+	}
+	if !vote.Granted {
+		return fmt.Errorf("witness denied takeover: %s", vote.Reason)
+	}
+	fm.logger.Info("Witness granted takeover: %s", vote.Reason)
+	return nil
+}
@@ -0,0 +1,336 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Registry holds metric families and renders them in the Prometheus text format
+type Registry struct {
+	mu       sync.RWMutex
+	families map[string]*family
+	// constNames and constValues are labels rendered on every series,
+	// ahead of its own
+	constNames  []string
+	constValues []string
+}
+
+// family is a named metric with a fixed set of label names
+type family struct {
+	name       string
+	help       string
+	kind       string
+	labelNames []string
+	buckets    []float64
+	mu         sync.Mutex
+	values     map[string]float64
+	hists      map[string]*histValues
+}
+
+// histValues holds the observations of one histogram series
+type histValues struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// Default is the process-wide registry exposed on /metrics
+var Default = NewRegistry()
+
+// InstanceLabel names the syncguard process (node.instance) on every series
+// when several run on one host. It is not "instance", which Prometheus sets
+// to the scrape target.
+const InstanceLabel = "syncguard_instance"
+
+// SetInstance labels every series of the default registry with instance;
+// "" leaves series unlabeled
+func SetInstance(instance string) {
+	Default.SetConstLabel(InstanceLabel, instance)
+}
+
+// SetConstLabel adds a label rendered on every series; an empty value
+// removes it
+func (r *Registry) SetConstLabel(name, value string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var names, values []string
+	for i, n := range r.constNames {
+		if n != name {
+			names = append(names, n)
+			values = append(values, r.constValues[i])
+		}
+	}
+	if value != "" {
+		names = append(names, name)
+		values = append(values, value)
+	}
+	r.constNames, r.constValues = names, values
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]*family)}
+}
+
+// register returns the family with the given name, creating it if needed.
+// Registering the same name twice returns the existing family so package-level
+// metrics can be declared independently without init-order concerns.
+func (r *Registry) register(name, help, kind string, labelNames []string) *family {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if f, ok := r.families[name]; ok {
+		return f
+	}
+	f := &family{
+		name:       name,
+		help:       help,
+		kind:       kind,
+		labelNames: labelNames,
+		values:     make(map[string]float64),
+		hists:      make(map[string]*histValues),
+	}
+	r.families[name] = f
+	return f
+}
+
+// labelKey encodes label values into a map key
+func (f *family) labelKey(labelValues []string) string {
+	if len(labelValues) != len(f.labelNames) {
+		panic(fmt.Sprintf("metric %s: expected %d label values, got %d",
+			f.name, len(f.labelNames), len(labelValues)))
+	}
+	return strings.Join(labelValues, "\x00")
+}
+
+func (f *family) add(delta float64, labelValues []string) {
+	key := f.labelKey(labelValues)
+	f.mu.Lock()
+	f.values[key] += delta
+	f.mu.Unlock()
+}
+
+func (f *family) set(value float64, labelValues []string) {
+	key := f.labelKey(labelValues)
+	f.mu.Lock()
+	f.values[key] = value
+	f.mu.Unlock()
+}
+
+func (f *family) get(labelValues []string) float64 {
+	key := f.labelKey(labelValues)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.values[key]
+}
+
+func (f *family) observe(value float64, labelValues []string) {
+	key := f.labelKey(labelValues)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	h, ok := f.hists[key]
+	if !ok {
+		h = &histValues{counts: make([]uint64, len(f.buckets))}
+		f.hists[key] = h
+	}
+	for i, upper := range f.buckets {
+		if value <= upper {
+			h.counts[i]++
+		}
+	}
+	h.sum += value
+	h.count++
+}
+
+// Counter is a monotonically increasing metric
+type Counter struct{ f *family }
+
+// NewCounter registers a counter in the default registry
+func NewCounter(name, help string, labelNames ...string) *Counter {
+	return Default.NewCounter(name, help, labelNames...)
+}
+
+// NewCounter registers a counter in this registry
+func (r *Registry) NewCounter(name, help string, labelNames ...string) *Counter {
+	return &Counter{f: r.register(name, help, "counter", labelNames)}
+}
+
+// Inc increments the counter by one
+func (c *Counter) Inc(labelValues ...string) { c.f.add(1, labelValues) }
+
+// Add increments the counter by delta (must be non-negative)
+func (c *Counter) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		return
+	}
+	c.f.add(delta, labelValues)
+}
+
+// Value returns the current counter value
+func (c *Counter) Value(labelValues ...string) float64 { return c.f.get(labelValues) }
+
+// Gauge is a metric that can go up and down
+type Gauge struct{ f *family }
+
+// NewGauge registers a gauge in the default registry
+func NewGauge(name, help string, labelNames ...string) *Gauge {
+	return Default.NewGauge(name, help, labelNames...)
+}
+
+// NewGauge registers a gauge in this registry
+func (r *Registry) NewGauge(name, help string, labelNames ...string) *Gauge {
+	return &Gauge{f: r.register(name, help, "gauge", labelNames)}
+}
+
+// Set sets the gauge value
+func (g *Gauge) Set(value float64, labelValues ...string) { g.f.set(value, labelValues) }
+
+// Add adds delta to the gauge value
+func (g *Gauge) Add(delta float64, labelValues ...string) { g.f.add(delta, labelValues) }
+
+// Value returns the current gauge value
+func (g *Gauge) Value(labelValues ...string) float64 { return g.f.get(labelValues) }
+
+// DefBuckets are latency buckets in seconds suited to RPC calls
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Histogram samples observations into cumulative buckets
+type Histogram struct{ f *family }
+
+// NewHistogram registers a histogram in the default registry
+func NewHistogram(name, help string, buckets []float64, labelNames ...string) *Histogram {
+	return Default.NewHistogram(name, help, buckets, labelNames...)
+}
+
+// NewHistogram registers a histogram in this registry; nil buckets uses DefBuckets
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labelNames ...string) *Histogram {
+	if buckets == nil {
+		buckets = DefBuckets
+	}
+	f := r.register(name, help, "histogram", labelNames)
+	f.mu.Lock()
+	if f.buckets == nil {
+		f.buckets = append([]float64(nil), buckets...)
+		sort.Float64s(f.buckets)
+	}
+	f.mu.Unlock()
+	return &Histogram{f: f}
+}
+
+// Observe records one value
+func (h *Histogram) Observe(value float64, labelValues ...string) { h.f.observe(value, labelValues) }
+
+// Count returns the number of observations
+func (h *Histogram) Count(labelValues ...string) uint64 {
+	key := h.f.labelKey(labelValues)
+	h.f.mu.Lock()
+	defer h.f.mu.Unlock()
+	if v, ok := h.f.hists[key]; ok {
+		return v.count
+	}
+	return 0
+}
+
+// WriteTo renders all metrics in the Prometheus text exposition format
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.RLock()
+	names := make([]string, 0, len(r.families))
+	for name := range r.families {
+		names = append(names, name)
+	}
+	families := make([]*family, 0, len(names))
+	sort.Strings(names)
+	for _, name := range names {
+		families = append(families, r.families[name])
+	}
+	constNames, constValues := r.constNames, r.constValues
+	r.mu.RUnlock()
+	labels := func(names []string, key string) string {
+		if len(constNames) == 0 {
+			return formatLabels(names, key)
+		}
+		if len(names) == 0 {
+			return formatLabels(constNames, strings.Join(constValues, "\x00"))
+		}
+		return formatLabels(append(append([]string(nil), constNames...), names...),
+			strings.Join(constValues, "\x00")+"\x00"+key)
+	}
+
+	var sb strings.Builder
+	for _, f := range families {
+		fmt.Fprintf(&sb, "# HELP %s %s\n", f.name, f.help)
+		fmt.Fprintf(&sb, "# TYPE %s %s\n", f.name, f.kind)
+
+		f.mu.Lock()
+		if f.kind == "histogram" {
+			writeHistogram(&sb, f, labels)
+			f.mu.Unlock()
+			continue
+		}
+		keys := make([]string, 0, len(f.values))
+		for key := range f.values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(&sb, "%s%s %g\n", f.name, labels(f.labelNames, key), f.values[key])
+		}
+		f.mu.Unlock()
+	}
+
+	n, err := io.WriteString(w, sb.String())
+	return int64(n), err
+}
+
+// writeHistogram renders bucket, sum and count series; caller holds f.mu
+func writeHistogram(sb *strings.Builder, f *family, labels func(names []string, key string) string) {
+	keys := make([]string, 0, len(f.hists))
+	for key := range f.hists {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	bucketNames := append(append([]string(nil), f.labelNames...), "le")
+	for _, key := range keys {
+		h := f.hists[key]
+		prefix := key
+		if len(f.labelNames) > 0 {
+			prefix += "\x00"
+		}
+		for i, upper := range f.buckets {
+			fmt.Fprintf(sb, "%s_bucket%s %d\n", f.name,
+				labels(bucketNames, prefix+fmt.Sprintf("%g", upper)), h.counts[i])
+		}
+		fmt.Fprintf(sb, "%s_bucket%s %d\n", f.name, labels(bucketNames, prefix+"+Inf"), h.count)
+		fmt.Fprintf(sb, "%s_sum%s %g\n", f.name, labels(f.labelNames, key), h.sum)
+		fmt.Fprintf(sb, "%s_count%s %d\n", f.name, labels(f.labelNames, key), h.count)
+	}
+}
+
+// formatLabels renders {name="value",...} for a label key
+func formatLabels(names []string, key string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	values := strings.Split(key, "\x00")
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		value := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(values[i])
+		pairs[i] = fmt.Sprintf(`%s="%s"`, name, value)
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// Handler serves the default registry
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		Default.WriteTo(w)
+	})
+}
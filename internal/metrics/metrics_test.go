@@ -0,0 +1,115 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegistry_CounterAndGauge(t *testing.T) {
+	r := NewRegistry()
+	requests := r.NewCounter("test_requests_total", "Requests served", "endpoint")
+	height := r.NewGauge("test_height", "Latest height")
+
+	requests.Inc("/health")
+	requests.Add(2, "/health")
+	requests.Inc("/validator_state")
+	requests.Add(-5, "/health") // ignored: counters never decrease
+	height.Set(1000)
+
+	if got := requests.Value("/health"); got != 3 {
+		t.Errorf("Counter /health = %v, want 3", got)
+	}
+
+	var sb strings.Builder
+	if _, err := r.WriteTo(&sb); err != nil {
+		t.Fatalf("WriteTo failed: %v", err)
+	}
+	out := sb.String()
+
+	for _, want := range []string{
+		"# TYPE test_requests_total counter",
+		`test_requests_total{endpoint="/health"} 3`,
+		`test_requests_total{endpoint="/validator_state"} 1`,
+		"# TYPE test_height gauge",
+		"test_height 1000",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestRegistry_ConstLabel(t *testing.T) {
+	r := NewRegistry()
+	requests := r.NewCounter("test_requests_total", "Requests served", "endpoint")
+	height := r.NewGauge("test_height", "Latest height")
+	latency := r.NewHistogram("test_latency_seconds", "Probe latency", []float64{1})
+	requests.Inc("/health")
+	height.Set(1000)
+	latency.Observe(0.5)
+
+	r.SetConstLabel(InstanceLabel, "chain-a")
+	var sb strings.Builder
+	r.WriteTo(&sb)
+	out := sb.String()
+	for _, want := range []string{
+		`test_requests_total{syncguard_instance="chain-a",endpoint="/health"} 1`,
+		`test_height{syncguard_instance="chain-a"} 1000`,
+		`test_latency_seconds_bucket{syncguard_instance="chain-a",le="1"} 1`,
+		`test_latency_seconds_count{syncguard_instance="chain-a"} 1`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Output missing %q:\n%s", want, out)
+		}
+	}
+
+	r.SetConstLabel(InstanceLabel, "")
+	sb.Reset()
+	r.WriteTo(&sb)
+	if strings.Contains(sb.String(), InstanceLabel) || !strings.Contains(sb.String(), "test_height 1000") {
+		t.Errorf("expected the label removed:\n%s", sb.String())
+	}
+}
+
+func TestRegistry_RegisterTwiceSharesFamily(t *testing.T) {
+	r := NewRegistry()
+	a := r.NewCounter("test_shared_total", "Shared")
+	b := r.NewCounter("test_shared_total", "Shared")
+
+	a.Inc()
+	b.Inc()
+
+	if got := a.Value(); got != 2 {
+		t.Errorf("Shared counter = %v, want 2", got)
+	}
+}
+
+func TestRegistry_Histogram(t *testing.T) {
+	r := NewRegistry()
+	latency := r.NewHistogram("test_latency_seconds", "Probe latency", []float64{0.1, 1}, "probe")
+
+	latency.Observe(0.05, "status")
+	latency.Observe(0.5, "status")
+	latency.Observe(3, "status")
+
+	if got := latency.Count("status"); got != 3 {
+		t.Errorf("Count = %d, want 3", got)
+	}
+
+	var sb strings.Builder
+	r.WriteTo(&sb)
+	out := sb.String()
+
+	for _, want := range []string{
+		"# TYPE test_latency_seconds histogram",
+		`test_latency_seconds_bucket{probe="status",le="0.1"} 1`,
+		`test_latency_seconds_bucket{probe="status",le="1"} 2`,
+		`test_latency_seconds_bucket{probe="status",le="+Inf"} 3`,
+		`test_latency_seconds_sum{probe="status"} 3.55`,
+		`test_latency_seconds_count{probe="status"} 3`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -116,6 +116,22 @@ func (m *BinaryManager) Restart() error {
 	return nil
 }
 
+// Signal sends the named signal to the node process
+func (m *BinaryManager) Signal(name string) error {
+	sig, err := signalByName(name)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.running || m.cmd == nil || m.cmd.Process == nil {
+		return fmt.Errorf("node not running")
+	}
+	m.logger.Info("Sending %s to validator node (PID %d)", name, m.cmd.Process.Pid)
+	return m.cmd.Process.Signal(sig)
+}
+
 func (m *BinaryManager) IsRunning() bool {
 	m.mu.Lock()
 	defer m.mu.Unlock()
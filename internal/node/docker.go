@@ -72,6 +72,16 @@ func (m *DockerManager) Restart() error {
 	return nil
 }
 
+// Signal sends the named signal to the container's main process
+func (m *DockerManager) Signal(name string) error {
+	m.logger.Info("Sending %s to container: %s", name, m.containerID)
+
+	if err := m.client.ContainerKill(context.Background(), m.containerID, name); err != nil {
+		return fmt.Errorf("failed to signal container: %w", err)
+	}
+	return nil
+}
+
 func (m *DockerManager) IsRunning() bool {
 	ctx := context.Background()
 	info, err := m.client.ContainerInspect(ctx, m.containerID)
@@ -75,6 +75,20 @@ func (m *DockerComposeManager) Restart() error {
 	return nil
 }
 
+// Signal sends the named signal to the service's containers
+func (m *DockerComposeManager) Signal(name string) error {
+	m.logger.Info("Sending %s to docker-compose service %s", name, m.service)
+
+	cmd := exec.Command("docker", "compose", "-f", m.composeFile, "kill", "-s", name, m.service)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("docker-compose kill failed: %w", err)
+	}
+	return nil
+}
+
 func (m *DockerComposeManager) IsRunning() bool {
 	cmd := exec.Command("docker", "compose", "-f", m.composeFile, "ps", "-q", m.service)
 	output, err := cmd.Output()
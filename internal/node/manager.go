@@ -17,6 +17,12 @@ type Manager interface {
 	WaitHealthy(ctx context.Context, healthCheck func() bool) error
 }
 
+// Signaler is implemented by managers that can signal the running node,
+// for nodes that reload their key on a signal instead of a restart
+type Signaler interface {
+	Signal(name string) error
+}
+
 // Config holds node manager configuration
 type Config struct {
 	Mode         constants.NodeManagerType
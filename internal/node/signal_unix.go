@@ -0,0 +1,22 @@
+//go:build !windows
+
+package node
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// signalByName maps the signals a node may reload on to their values
+func signalByName(name string) (os.Signal, error) {
+	switch name {
+	case "SIGHUP":
+		return syscall.SIGHUP, nil
+	case "SIGUSR1":
+		return syscall.SIGUSR1, nil
+	case "SIGUSR2":
+		return syscall.SIGUSR2, nil
+	}
+	return nil, fmt.Errorf("unsupported signal %q", name)
+}
@@ -0,0 +1,11 @@
+package node
+
+import (
+	"fmt"
+	"os"
+)
+
+// signalByName fails: Windows processes cannot be sent reload signals
+func signalByName(name string) (os.Signal, error) {
+	return nil, fmt.Errorf("signal %s is not supported on Windows", name)
+}
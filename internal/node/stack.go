@@ -0,0 +1,215 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/logger"
+)
+
+// Service is one managed process of a validator instance
+type Service struct {
+	Name      string
+	Manager   Manager
+	DependsOn []string
+}
+
+// ServiceStatus reports whether a service is running
+type ServiceStatus struct {
+	Name    string `json:"name"`
+	Running bool   `json:"running"`
+}
+
+// StackManager manages the services of one validator instance (consensus
+// client, execution client, signer sidecar...) as a unit. Services start
+// after their dependencies and stop before them. A failed start stops
+// whatever was started, so a takeover never leaves half a stack running.
+type StackManager struct {
+	services     []Service // in start order
+	primary      string
+	restartDelay time.Duration
+	logger       *logger.Logger
+}
+
+// NewStackManager orders services by their dependencies. The primary
+// service is the one whose health WaitHealthy waits for.
+func NewStackManager(services []Service, primary string, restartDelay time.Duration, log *logger.Logger) (*StackManager, error) {
+	ordered, err := orderServices(services)
+	if err != nil {
+		return nil, err
+	}
+	found := false
+	for _, s := range ordered {
+		found = found || s.Name == primary
+	}
+	if !found {
+		return nil, fmt.Errorf("primary service %q is not defined", primary)
+	}
+	return &StackManager{
+		services:     ordered,
+		primary:      primary,
+		restartDelay: restartDelay,
+		logger:       log,
+	}, nil
+}
+
+// orderServices sorts services so each comes after its dependencies
+func orderServices(services []Service) ([]Service, error) {
+	byName := make(map[string]Service, len(services))
+	for _, s := range services {
+		if _, dup := byName[s.Name]; dup {
+			return nil, fmt.Errorf("service %q is defined twice", s.Name)
+		}
+		byName[s.Name] = s
+	}
+
+	const (
+		visiting = 1
+		done     = 2
+	)
+	marks := make(map[string]int, len(services))
+	ordered := make([]Service, 0, len(services))
+
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch marks[name] {
+		case done:
+			return nil
+		case visiting:
+			return fmt.Errorf("service dependency cycle: %s", strings.Join(append(path, name), " -> "))
+		}
+		s, ok := byName[name]
+		if !ok {
+			return fmt.Errorf("service %q depends on unknown service %q", path[len(path)-1], name)
+		}
+		marks[name] = visiting
+		for _, dep := range s.DependsOn {
+			if err := visit(dep, append(path, name)); err != nil {
+				return err
+			}
+		}
+		marks[name] = done
+		ordered = append(ordered, s)
+		return nil
+	}
+
+	// Declaration order breaks ties between independent services
+	for _, s := range services {
+		if err := visit(s.Name, nil); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// Order returns the service names in start order
+func (m *StackManager) Order() []string {
+	names := make([]string, len(m.services))
+	for i, s := range m.services {
+		names[i] = s.Name
+	}
+	return names
+}
+
+func (m *StackManager) Start() error {
+	for i, s := range m.services {
+		if s.Manager.IsRunning() {
+			continue
+		}
+		m.logger.Info("Starting service %s", s.Name)
+		if err := s.Manager.Start(); err != nil {
+			m.logger.Error("Service %s failed to start, stopping the services started before it: %v", s.Name, err)
+			m.stop(m.services[:i])
+			return fmt.Errorf("failed to start %s: %w", s.Name, err)
+		}
+	}
+	return nil
+}
+
+func (m *StackManager) Stop() error {
+	return m.stop(m.services)
+}
+
+// stop stops services in reverse start order, carrying on past errors
+func (m *StackManager) stop(services []Service) error {
+	var firstErr error
+	for i := len(services) - 1; i >= 0; i-- {
+		s := services[i]
+		m.logger.Info("Stopping service %s", s.Name)
+		if err := s.Manager.Stop(); err != nil {
+			m.logger.Error("Failed to stop service %s: %v", s.Name, err)
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to stop %s: %w", s.Name, err)
+			}
+		}
+	}
+	return firstErr
+}
+
+func (m *StackManager) Restart() error {
+	m.logger.Info("Restarting services: %s", strings.Join(m.Order(), ", "))
+
+	if err := m.Stop(); err != nil {
+		return err
+	}
+
+	time.Sleep(m.restartDelay)
+
+	return m.Start()
+}
+
+// Signal sends the named signal to the primary service
+func (m *StackManager) Signal(name string) error {
+	for _, s := range m.services {
+		if s.Name != m.primary {
+			continue
+		}
+		signaler, ok := s.Manager.(Signaler)
+		if !ok {
+			return fmt.Errorf("service %s cannot be signaled", s.Name)
+		}
+		return signaler.Signal(name)
+	}
+	return fmt.Errorf("no service %s", m.primary)
+}
+
+// RestartService restarts one service and leaves the others running, for
+// a sidecar such as a remote signer the node reconnects to on its own
+func (m *StackManager) RestartService(name string) error {
+	for _, s := range m.services {
+		if s.Name == name {
+			m.logger.Info("Restarting service %s", name)
+			return s.Manager.Restart()
+		}
+	}
+	return fmt.Errorf("no service %s", name)
+}
+
+func (m *StackManager) IsRunning() bool {
+	for _, s := range m.services {
+		if !s.Manager.IsRunning() {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *StackManager) WaitHealthy(ctx context.Context, healthCheck func() bool) error {
+	for _, s := range m.services {
+		if s.Name == m.primary {
+			return s.Manager.WaitHealthy(ctx, healthCheck)
+		}
+	}
+	return nil
+}
+
+// Status reports each service in start order
+func (m *StackManager) Status() []ServiceStatus {
+	statuses := make([]ServiceStatus, len(m.services))
+	for i, s := range m.services {
+		statuses[i] = ServiceStatus{Name: s.Name, Running: s.Manager.IsRunning()}
+	}
+	return statuses
+}
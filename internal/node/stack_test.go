@@ -0,0 +1,150 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/logger"
+)
+
+// fakeManager records lifecycle calls into a shared log
+type fakeManager struct {
+	name     string
+	log      *[]string
+	running  bool
+	startErr error
+}
+
+func (f *fakeManager) Start() error {
+	*f.log = append(*f.log, "start "+f.name)
+	if f.startErr != nil {
+		return f.startErr
+	}
+	f.running = true
+	return nil
+}
+
+func (f *fakeManager) Stop() error {
+	*f.log = append(*f.log, "stop "+f.name)
+	f.running = false
+	return nil
+}
+
+func (f *fakeManager) Restart() error {
+	*f.log = append(*f.log, "restart "+f.name)
+	return nil
+}
+
+func (f *fakeManager) IsRunning() bool                                { return f.running }
+func (f *fakeManager) WaitHealthy(context.Context, func() bool) error { return nil }
+
+func testLogger() *logger.Logger {
+	return logger.New(&config.Config{Logging: config.LoggingConfig{Level: "error", File: "/dev/null"}}, "test")
+}
+
+func TestStackManager_Order(t *testing.T) {
+	var log []string
+	fake := func(name string) *fakeManager { return &fakeManager{name: name, log: &log} }
+	m, err := NewStackManager([]Service{
+		{Name: "consensus", Manager: fake("consensus"), DependsOn: []string{"execution", "signer"}},
+		{Name: "execution", Manager: fake("execution")},
+		{Name: "signer", Manager: fake("signer")},
+	}, "consensus", 0, testLogger())
+	if err != nil {
+		t.Fatalf("NewStackManager failed: %v", err)
+	}
+
+	if got := strings.Join(m.Order(), ","); got != "execution,signer,consensus" {
+		t.Errorf("start order = %s", got)
+	}
+	if err := m.Restart(); err != nil {
+		t.Fatalf("Restart failed: %v", err)
+	}
+	want := "stop consensus,stop signer,stop execution,start execution,start signer,start consensus"
+	if got := strings.Join(log, ","); got != want {
+		t.Errorf("lifecycle order = %s, want %s", got, want)
+	}
+	if !m.IsRunning() {
+		t.Error("expected the stack to be running")
+	}
+}
+
+func TestStackManager_FailedStartStopsStarted(t *testing.T) {
+	var log []string
+	execution := &fakeManager{name: "execution", log: &log}
+	consensus := &fakeManager{name: "consensus", log: &log, startErr: fmt.Errorf("boom")}
+	m, err := NewStackManager([]Service{
+		{Name: "consensus", Manager: consensus, DependsOn: []string{"execution"}},
+		{Name: "execution", Manager: execution},
+	}, "consensus", 0, testLogger())
+	if err != nil {
+		t.Fatalf("NewStackManager failed: %v", err)
+	}
+
+	if err := m.Start(); err == nil {
+		t.Fatal("expected Start to fail")
+	}
+	if execution.running || m.IsRunning() {
+		t.Error("expected the execution client to be stopped after the consensus client failed")
+	}
+}
+
+func TestStackManager_RestartService(t *testing.T) {
+	var log []string
+	fake := func(name string) *fakeManager { return &fakeManager{name: name, log: &log} }
+	m, err := NewStackManager([]Service{
+		{Name: "consensus", Manager: fake("consensus"), DependsOn: []string{"signer"}},
+		{Name: "signer", Manager: fake("signer")},
+	}, "consensus", 0, testLogger())
+	if err != nil {
+		t.Fatalf("NewStackManager failed: %v", err)
+	}
+
+	if err := m.RestartService("signer"); err != nil {
+		t.Fatalf("RestartService failed: %v", err)
+	}
+	if got := strings.Join(log, ","); got != "restart signer" {
+		t.Errorf("lifecycle = %s, want only the signer restarted", got)
+	}
+	if err := m.RestartService("missing"); err == nil {
+		t.Error("expected an unknown service to fail")
+	}
+	// The fake cannot be signaled, so a reload signal has to fall back
+	if err := m.Signal("SIGHUP"); err == nil || !strings.Contains(err.Error(), "cannot be signaled") {
+		t.Errorf("expected a signal error, got %v", err)
+	}
+}
+
+func TestStackManager_InvalidDependencies(t *testing.T) {
+	var log []string
+	fake := func(name string) *fakeManager { return &fakeManager{name: name, log: &log} }
+
+	tests := []struct {
+		name     string
+		services []Service
+		want     string
+	}{
+		{"cycle", []Service{
+			{Name: "consensus", Manager: fake("a"), DependsOn: []string{"signer"}},
+			{Name: "signer", Manager: fake("b"), DependsOn: []string{"consensus"}},
+		}, "cycle"},
+		{"unknown", []Service{
+			{Name: "consensus", Manager: fake("a"), DependsOn: []string{"missing"}},
+		}, "unknown service"},
+		{"duplicate", []Service{
+			{Name: "consensus", Manager: fake("a")},
+			{Name: "consensus", Manager: fake("b")},
+		}, "defined twice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewStackManager(tt.services, "consensus", 0, testLogger())
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+)
+
+// Longest messages the chat services accept; longer text is cut short
+const (
+	slackMaxText    = 40000
+	discordMaxText  = 2000
+	telegramMaxText = 4096
+)
+
+// ChatSink posts events as rendered text to a chat service: a Slack or
+// Discord incoming webhook, or a Telegram bot
+type ChatSink struct {
+	name    string
+	url     string
+	tmpl    *template.Template
+	maxText int
+	// payload wraps the text in the service's message
+	payload func(text string) interface{}
+	client  *http.Client
+}
+
+// NewSlackSink creates a sink posting to a Slack incoming webhook
+func NewSlackSink(name, url string, tmpl *template.Template) *ChatSink {
+	return &ChatSink{name: name, url: url, tmpl: tmpl, maxText: slackMaxText, client: &http.Client{},
+		payload: func(text string) interface{} { return map[string]string{"text": text} }}
+}
+
+// NewDiscordSink creates a sink posting to a Discord webhook
+func NewDiscordSink(name, url string, tmpl *template.Template) *ChatSink {
+	return &ChatSink{name: name, url: url, tmpl: tmpl, maxText: discordMaxText, client: &http.Client{},
+		payload: func(text string) interface{} { return map[string]string{"content": text} }}
+}
+
+// NewTelegramSink creates a sink sending through a Telegram bot to a chat
+func NewTelegramSink(name string, cfg config.TelegramConfig, tmpl *template.Template) *ChatSink {
+	endpoint := fmt.Sprintf("%s/bot%s/sendMessage", strings.TrimSuffix(cfg.APIURL, "/"), cfg.BotToken)
+	return &ChatSink{name: name, url: endpoint, tmpl: tmpl, maxText: telegramMaxText, client: &http.Client{},
+		payload: func(text string) interface{} {
+			return map[string]string{"chat_id": cfg.ChatID, "text": text}
+		}}
+}
+
+// Name returns the sink name
+func (s *ChatSink) Name() string { return s.name }
+
+// Send posts the event's rendered text
+func (s *ChatSink) Send(ctx context.Context, event Event) error {
+	text, err := Render(s.tmpl, event)
+	if err != nil {
+		return err
+	}
+	if len(text) > s.maxText {
+		text = strings.ToValidUTF8(text[:s.maxText-3], "") + "..."
+	}
+
+	body, err := json.Marshal(s.payload(text))
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post message: %w", withoutURL(err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("chat service returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// withoutURL drops the URL from a request error: webhook URLs and bot
+// tokens are credentials, and delivery errors are logged
+func withoutURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
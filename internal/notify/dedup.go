@@ -0,0 +1,120 @@
+package notify
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// aggregator suppresses repeats of an event within a window and replaces
+// them with a single summary once the window closes. A storm of identical
+// events therefore produces one alert when it starts and at most one summary
+// per window while it lasts.
+type aggregator struct {
+	window time.Duration
+	flush  func(Event)
+
+	mu      sync.Mutex
+	entries map[string]*aggEntry
+}
+
+// aggEntry tracks one open dedup window
+type aggEntry struct {
+	first      Event
+	suppressed int
+	timer      *time.Timer
+}
+
+// newAggregator creates an aggregator; a zero window disables it
+func newAggregator(window time.Duration, flush func(Event)) *aggregator {
+	return &aggregator{window: window, flush: flush, entries: make(map[string]*aggEntry)}
+}
+
+// dedupKey identifies repeats of the same event
+func dedupKey(event Event) string {
+	return fmt.Sprintf("%s|%s|%s|%s", event.NodeID, event.Type, event.Severity, event.Message)
+}
+
+// admit reports whether the event should be delivered now
+func (a *aggregator) admit(event Event) bool {
+	if a.window <= 0 {
+		return true
+	}
+
+	key := dedupKey(event)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if entry, ok := a.entries[key]; ok {
+		entry.suppressed++
+		suppressedCounter.Inc(string(event.Type))
+		return false
+	}
+
+	a.openLocked(key, event)
+	return true
+}
+
+// openLocked starts a dedup window for key; caller holds a.mu
+func (a *aggregator) openLocked(key string, event Event) {
+	a.entries[key] = &aggEntry{
+		first: event,
+		timer: time.AfterFunc(a.window, func() { a.expire(key) }),
+	}
+}
+
+// expire closes a window, emitting a summary if anything was suppressed.
+// While repeats keep arriving the window is re-armed so the storm keeps
+// being aggregated instead of alerting again on the next occurrence.
+func (a *aggregator) expire(key string) {
+	a.mu.Lock()
+	entry, ok := a.entries[key]
+	if !ok {
+		a.mu.Unlock()
+		return
+	}
+	delete(a.entries, key)
+
+	if entry.suppressed == 0 {
+		a.mu.Unlock()
+		return
+	}
+
+	summary := a.summarize(entry)
+	a.openLocked(key, entry.first)
+	a.mu.Unlock()
+
+	a.flush(summary)
+}
+
+// summarize builds the aggregate alert for a window
+func (a *aggregator) summarize(entry *aggEntry) Event {
+	summary := entry.first
+	summary.Message = fmt.Sprintf("%s (repeated %d times in the last %s)",
+		entry.first.Message, entry.suppressed, a.window)
+	summary.Time = time.Now().UTC()
+
+	summary.Fields = make(map[string]string, len(entry.first.Fields)+1)
+	for k, v := range entry.first.Fields {
+		summary.Fields[k] = v
+	}
+	summary.Fields["repeated"] = fmt.Sprintf("%d", entry.suppressed)
+	return summary
+}
+
+// close stops all windows and returns pending summaries
+func (a *aggregator) close() []Event {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var pending []Event
+	for key, entry := range a.entries {
+		entry.timer.Stop()
+		if entry.suppressed > 0 {
+			pending = append(pending, a.summarize(entry))
+		}
+		delete(a.entries, key)
+	}
+	return pending
+}
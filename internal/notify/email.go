@@ -0,0 +1,123 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+)
+
+// defaultSubject is used when the sink has no subject template
+const defaultSubject = `[SyncGuard {{.Severity}}] {{.NodeID}}: {{.Type}}`
+
+// EmailSink delivers events over SMTP.
+// STARTTLS is used whenever the server offers it.
+type EmailSink struct {
+	name    string
+	cfg     config.SMTPConfig
+	body    *template.Template
+	subject *template.Template
+}
+
+// NewEmailSink creates an SMTP sink
+func NewEmailSink(name string, cfg config.SMTPConfig, body *template.Template) (*EmailSink, error) {
+	subjectText := cfg.Subject
+	if subjectText == "" {
+		subjectText = defaultSubject
+	}
+	subject, err := template.New(name + "-subject").Parse(subjectText)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subject template for %s: %w", name, err)
+	}
+	return &EmailSink{name: name, cfg: cfg, body: body, subject: subject}, nil
+}
+
+// Name returns the sink name
+func (s *EmailSink) Name() string { return s.name }
+
+// Send renders the event and mails it to all recipients
+func (s *EmailSink) Send(ctx context.Context, event Event) error {
+	subject, err := Render(s.subject, event)
+	if err != nil {
+		return err
+	}
+	body, err := Render(s.body, event)
+	if err != nil {
+		return err
+	}
+
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: s.cfg.Host}); err != nil {
+			return fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+	if s.cfg.Username != "" {
+		auth := smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("SMTP authentication failed: %w", err)
+		}
+	}
+
+	if err := client.Mail(s.cfg.From); err != nil {
+		return fmt.Errorf("MAIL FROM rejected: %w", err)
+	}
+	for _, to := range s.cfg.To {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("RCPT TO %s rejected: %w", to, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("DATA rejected: %w", err)
+	}
+	if _, err := w.Write(buildMessage(s.cfg.From, s.cfg.To, subject, body, event.Time)); err != nil {
+		w.Close()
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	return client.Quit()
+}
+
+// buildMessage assembles an RFC 5322 plain-text message
+func buildMessage(from string, to []string, subject, body string, date time.Time) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", strings.ReplaceAll(subject, "\n", " "))
+	fmt.Fprintf(&buf, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	buf.WriteString("\r\n")
+	return buf.Bytes()
+}
@@ -0,0 +1,135 @@
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Severity classifies how urgent an event is
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityCritical
+)
+
+// String returns the lowercase severity name
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	case SeverityCritical:
+		return "critical"
+	default:
+		return "info"
+	}
+}
+
+// MarshalText encodes the severity by name for JSON payloads
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// ParseSeverity parses a severity name; empty means info
+func ParseSeverity(name string) (Severity, error) {
+	switch strings.ToLower(name) {
+	case "", "info":
+		return SeverityInfo, nil
+	case "warning", "warn":
+		return SeverityWarning, nil
+	case "critical":
+		return SeverityCritical, nil
+	default:
+		return SeverityInfo, fmt.Errorf("unknown severity %q (accepted: info, warning, critical)", name)
+	}
+}
+
+// EventType identifies what happened
+type EventType string
+
+const (
+	EventFailover          EventType = "failover"
+	EventFailback          EventType = "failback"
+	EventTakeover          EventType = "takeover"
+	EventRelease           EventType = "release"
+	EventKeyTransfer       EventType = "key_transfer"
+	EventHealthChanged     EventType = "health_changed"
+	EventHealthCheckFailed EventType = "health_check_failed"
+	EventLockConflict      EventType = "lock_conflict"
+	EventLockUnavailable   EventType = "lock_unavailable"
+	EventSelfDegraded      EventType = "self_degraded"
+	EventDowntimeRisk      EventType = "downtime_risk"
+	EventCascade           EventType = "cascade"
+	EventRecovery          EventType = "recovery"
+	EventDegrading         EventType = "degrading"
+	EventDisagreement      EventType = "health_disagreement"
+	EventHeartbeatMissed   EventType = "heartbeat_missed"
+	EventColdStandby       EventType = "cold_standby"
+	EventStateProvenance   EventType = "state_provenance"
+	EventFailoverApproval  EventType = "failover_approval"
+	EventNotSigning        EventType = "not_signing"
+	EventFirstBoot         EventType = "first_boot"
+	EventElection          EventType = "election"
+	EventMaintenance       EventType = "maintenance"
+	EventLinkCheck         EventType = "link_check"
+	EventReboot            EventType = "reboot"
+	EventStateChurn        EventType = "state_churn"
+	EventHook              EventType = "hook"
+	EventInvariant         EventType = "invariant"
+	EventDoubleSignGuard   EventType = "double_sign_guard"
+	EventBroadcast         EventType = "broadcast"
+	EventHealthUnknown     EventType = "health_unknown"
+)
+
+// eventTypes lists the event types a sink's events filter can name
+var eventTypes = map[EventType]bool{
+	EventFailover: true, EventFailback: true, EventTakeover: true, EventRelease: true,
+	EventKeyTransfer: true, EventHealthChanged: true, EventHealthCheckFailed: true,
+	EventLockConflict: true, EventLockUnavailable: true, EventSelfDegraded: true,
+	EventDowntimeRisk: true, EventCascade: true, EventRecovery: true, EventDegrading: true,
+	EventDisagreement: true, EventHeartbeatMissed: true, EventColdStandby: true,
+	EventStateProvenance: true, EventFailoverApproval: true, EventNotSigning: true,
+	EventFirstBoot: true, EventElection: true, EventMaintenance: true, EventLinkCheck: true,
+	EventReboot: true, EventStateChurn: true, EventHook: true, EventInvariant: true,
+	EventDoubleSignGuard: true, EventBroadcast: true, EventHealthUnknown: true,
+}
+
+// Event is a notification emitted by SyncGuard
+type Event struct {
+	Type     EventType         `json:"type"`
+	Severity Severity          `json:"severity"`
+	NodeID   string            `json:"node_id"`
+	Message  string            `json:"message"`
+	Reason   string            `json:"reason,omitempty"` // reason code of a change of role
+	Fields   map[string]string `json:"fields,omitempty"`
+	Time     time.Time         `json:"time"`
+}
+
+// DefaultTemplate renders a one-line summary of an event
+const DefaultTemplate = `[{{.Severity}}] {{.NodeID}} {{.Type}}: {{.Message}}{{if .Reason}} (reason: {{.Reason}}){{end}}`
+
+// Render executes a text/template against the event.
+// An empty template uses DefaultTemplate.
+func Render(tmpl *template.Template, event Event) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, event); err != nil {
+		return "", fmt.Errorf("failed to render template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// ParseTemplate compiles a sink template, falling back to DefaultTemplate
+func ParseTemplate(name, text string) (*template.Template, error) {
+	if text == "" {
+		text = DefaultTemplate
+	}
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template for %s: %w", name, err)
+	}
+	return tmpl, nil
+}
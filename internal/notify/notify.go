@@ -0,0 +1,183 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/logger"
+	"github.com/aldebaranode/syncguard/internal/metrics"
+)
+
+// sendTimeout bounds a single delivery to one sink
+const sendTimeout = 15 * time.Second
+
+var deliveryCounter = metrics.NewCounter(
+	"syncguard_alerts_sent_total",
+	"Alerts delivered per sink and result",
+	"sink", "result",
+)
+
+var suppressedCounter = metrics.NewCounter(
+	"syncguard_alerts_suppressed_total",
+	"Alerts folded into a summary by deduplication",
+	"type",
+)
+
+// Sink delivers events to an external system
+type Sink interface {
+	Name() string
+	Send(ctx context.Context, event Event) error
+}
+
+// filteredSink pairs a sink with its minimum severity and, when set, the
+// event types it takes
+type filteredSink struct {
+	sink        Sink
+	minSeverity Severity
+	events      map[EventType]bool
+}
+
+// accepts reports whether the sink takes event
+func (fs filteredSink) accepts(event Event) bool {
+	if event.Severity < fs.minSeverity {
+		return false
+	}
+	return len(fs.events) == 0 || fs.events[event.Type]
+}
+
+// Dispatcher fans events out to all configured sinks.
+// Delivery is asynchronous so a slow mail server or webhook can never
+// stall the failover path that emitted the event. Repeats within the dedup
+// window are aggregated into summary alerts.
+type Dispatcher struct {
+	nodeID     string
+	sinks      []filteredSink
+	aggregator *aggregator
+	logger     *logger.Logger
+	wg         sync.WaitGroup
+}
+
+// NewDispatcher builds sinks from config
+func NewDispatcher(cfg *config.Config) (*Dispatcher, error) {
+	newLogger := logger.New(cfg, "notify")
+
+	d := &Dispatcher{nodeID: cfg.Node.ID, logger: newLogger}
+	d.aggregator = newAggregator(cfg.Alerts.DedupWindow.Duration(), d.deliver)
+
+	for i, sinkCfg := range cfg.Alerts.Sinks {
+		name := sinkCfg.Name
+		if name == "" {
+			name = fmt.Sprintf("%s-%d", sinkCfg.Type, i)
+		}
+
+		minSeverity, err := ParseSeverity(sinkCfg.MinSeverity)
+		if err != nil {
+			return nil, fmt.Errorf("alerts.sinks[%d]: %w", i, err)
+		}
+
+		tmpl, err := ParseTemplate(name, sinkCfg.Template)
+		if err != nil {
+			return nil, fmt.Errorf("alerts.sinks[%d]: %w", i, err)
+		}
+
+		events := make(map[EventType]bool, len(sinkCfg.Events))
+		for _, eventType := range sinkCfg.Events {
+			if !eventTypes[EventType(eventType)] {
+				return nil, fmt.Errorf("alerts.sinks[%d].events: unknown event type %q", i, eventType)
+			}
+			events[EventType(eventType)] = true
+		}
+
+		var sink Sink
+		switch sinkCfg.Type {
+		case "webhook":
+			sink = NewWebhookSink(name, sinkCfg.URL, tmpl)
+		case "slack":
+			sink = NewSlackSink(name, sinkCfg.URL, tmpl)
+		case "discord":
+			sink = NewDiscordSink(name, sinkCfg.URL, tmpl)
+		case "telegram":
+			sink = NewTelegramSink(name, sinkCfg.Telegram, tmpl)
+		case "email":
+			sink, err = NewEmailSink(name, sinkCfg.SMTP, tmpl)
+		case "snmp":
+			sink, err = NewSNMPSink(name, sinkCfg.SNMP, tmpl)
+		case "status_page":
+			sink, err = NewStatusPageSink(name, sinkCfg.URL, sinkCfg.StatusPage, tmpl)
+		default:
+			err = fmt.Errorf("unknown type %q", sinkCfg.Type)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("alerts.sinks[%d]: %w", i, err)
+		}
+
+		d.sinks = append(d.sinks, filteredSink{sink: sink, minSeverity: minSeverity, events: events})
+	}
+
+	return d, nil
+}
+
+// Emit sends an event to every sink whose filters accept it,
+// unless it repeats an event already sent within the dedup window
+func (d *Dispatcher) Emit(event Event) {
+	if d == nil {
+		return
+	}
+	if event.NodeID == "" {
+		event.NodeID = d.nodeID
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+
+	if !d.aggregator.admit(event) {
+		return
+	}
+	d.deliver(event)
+}
+
+// deliver hands an event to the sinks without deduplication
+func (d *Dispatcher) deliver(event Event) {
+	for _, fs := range d.sinks {
+		if !fs.accepts(event) {
+			continue
+		}
+
+		d.wg.Add(1)
+		go func(sink Sink) {
+			defer d.wg.Done()
+
+			ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+			defer cancel()
+
+			if err := sink.Send(ctx, event); err != nil {
+				deliveryCounter.Inc(sink.Name(), "error")
+				d.logger.Warn("Failed to deliver %s alert via %s: %v", event.Type, sink.Name(), err)
+				return
+			}
+			deliveryCounter.Inc(sink.Name(), "ok")
+		}(fs.sink)
+	}
+}
+
+// Wait blocks until in-flight deliveries finish
+func (d *Dispatcher) Wait() {
+	if d == nil {
+		return
+	}
+	d.wg.Wait()
+}
+
+// Close sends pending summaries and waits for delivery (used on shutdown)
+func (d *Dispatcher) Close() {
+	if d == nil {
+		return
+	}
+	for _, summary := range d.aggregator.close() {
+		d.deliver(summary)
+	}
+	d.wg.Wait()
+}
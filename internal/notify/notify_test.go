@@ -0,0 +1,388 @@
+package notify_test
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/notify"
+)
+
+func testConfig(sinks ...config.AlertSinkConfig) *config.Config {
+	return &config.Config{
+		Node:    config.NodeConfig{ID: "node-a"},
+		Alerts:  config.AlertsConfig{Sinks: sinks},
+		Logging: config.LoggingConfig{Level: "error"},
+	}
+}
+
+func TestRender(t *testing.T) {
+	tmpl, err := notify.ParseTemplate("test", `{{.Type}} on {{.NodeID}} at height {{index .Fields "height"}}`)
+	if err != nil {
+		t.Fatalf("ParseTemplate: %v", err)
+	}
+
+	text, err := notify.Render(tmpl, notify.Event{
+		Type:   notify.EventFailover,
+		NodeID: "node-a",
+		Fields: map[string]string{"height": "42"},
+	})
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if text != "failover on node-a at height 42" {
+		t.Errorf("Render = %q", text)
+	}
+
+	// The default template appends the reason code when there is one
+	tmpl, _ = notify.ParseTemplate("default", "")
+	text, _ = notify.Render(tmpl, notify.Event{
+		Type:     notify.EventFailover,
+		Severity: notify.SeverityCritical,
+		NodeID:   "node-a",
+		Message:  "Failover complete",
+		Reason:   "rpc_timeout",
+	})
+	if text != "[critical] node-a failover: Failover complete (reason: rpc_timeout)" {
+		t.Errorf("Render default = %q", text)
+	}
+
+	if _, err := notify.ParseTemplate("bad", "{{.Type"); err == nil {
+		t.Error("Expected error for malformed template")
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    notify.Severity
+		wantErr bool
+	}{
+		{"", notify.SeverityInfo, false},
+		{"info", notify.SeverityInfo, false},
+		{"WARNING", notify.SeverityWarning, false},
+		{"critical", notify.SeverityCritical, false},
+		{"urgent", notify.SeverityInfo, true},
+	}
+
+	for _, tt := range tests {
+		got, err := notify.ParseSeverity(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseSeverity(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("ParseSeverity(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDispatcher_SeverityFilter(t *testing.T) {
+	var mu sync.Mutex
+	var received []map[string]any
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]any
+		json.NewDecoder(r.Body).Decode(&payload)
+		mu.Lock()
+		received = append(received, payload)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	d, err := notify.NewDispatcher(testConfig(config.AlertSinkConfig{
+		Type:        "webhook",
+		URL:         srv.URL,
+		MinSeverity: "warning",
+	}))
+	if err != nil {
+		t.Fatalf("NewDispatcher: %v", err)
+	}
+
+	d.Emit(notify.Event{Type: notify.EventHealthChanged, Severity: notify.SeverityInfo, Message: "recovered"})
+	d.Emit(notify.Event{Type: notify.EventFailover, Severity: notify.SeverityCritical, Message: "failed over"})
+	d.Wait()
+
+	if len(received) != 1 {
+		t.Fatalf("Expected 1 delivery, got %d", len(received))
+	}
+	if received[0]["type"] != "failover" || received[0]["severity"] != "critical" {
+		t.Errorf("Unexpected payload: %v", received[0])
+	}
+	if received[0]["text"] != "[critical] node-a failover: failed over" {
+		t.Errorf("Unexpected text: %v", received[0]["text"])
+	}
+}
+
+func TestDispatcher_InvalidSink(t *testing.T) {
+	_, err := notify.NewDispatcher(testConfig(config.AlertSinkConfig{
+		Type: "snmp",
+		SNMP: config.SNMPConfig{Target: "127.0.0.1:162", EnterpriseOID: "not.an.oid"},
+	}))
+	if err == nil || !strings.Contains(err.Error(), "enterprise_oid") {
+		t.Errorf("Expected enterprise_oid error, got %v", err)
+	}
+}
+
+func TestSNMPSink_SendsTrap(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer conn.Close()
+
+	d, err := notify.NewDispatcher(testConfig(config.AlertSinkConfig{
+		Type: "snmp",
+		SNMP: config.SNMPConfig{
+			Target:        conn.LocalAddr().String(),
+			Community:     "noc",
+			EnterpriseOID: "1.3.6.1.4.1.8072.9999.1",
+		},
+	}))
+	if err != nil {
+		t.Fatalf("NewDispatcher: %v", err)
+	}
+
+	d.Emit(notify.Event{Type: notify.EventFailover, Severity: notify.SeverityCritical, Message: "failed over"})
+	d.Wait()
+
+	buf := make([]byte, 1500)
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("No trap received: %v", err)
+	}
+	packet := buf[:n]
+
+	// SEQUENCE, then version INTEGER 1 (v2c), then the community string
+	if packet[0] != 0x30 {
+		t.Fatalf("Expected SEQUENCE, got 0x%x", packet[0])
+	}
+	body := packet[2:]
+	if packet[1]&0x80 != 0 {
+		body = packet[2+int(packet[1]&0x7f):]
+	}
+	if string(body[:3]) != "\x02\x01\x01" {
+		t.Errorf("Expected version v2c, got % x", body[:3])
+	}
+	if string(body[3:8]) != "\x04\x03noc" {
+		t.Errorf("Expected community 'noc', got % x", body[3:8])
+	}
+	if body[8] != 0xa7 {
+		t.Errorf("Expected SNMPv2-Trap PDU, got 0x%x", body[8])
+	}
+	if !strings.Contains(string(packet), "[critical] node-a failover: failed over") {
+		t.Error("Trap should carry the rendered message")
+	}
+}
+
+func TestDispatcher_AggregatesRepeats(t *testing.T) {
+	var mu sync.Mutex
+	var texts []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]any
+		json.NewDecoder(r.Body).Decode(&payload)
+		mu.Lock()
+		texts = append(texts, payload["text"].(string))
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	cfg := testConfig(config.AlertSinkConfig{Type: "webhook", URL: srv.URL})
+	cfg.Alerts.DedupWindow = 0.1
+	d, err := notify.NewDispatcher(cfg)
+	if err != nil {
+		t.Fatalf("NewDispatcher: %v", err)
+	}
+
+	for i := 0; i < 50; i++ {
+		d.Emit(notify.Event{Type: notify.EventHealthCheckFailed, Message: "Health check failed"})
+	}
+	d.Emit(notify.Event{Type: notify.EventFailover, Severity: notify.SeverityCritical, Message: "failed over"})
+	d.Wait()
+
+	mu.Lock()
+	if len(texts) != 2 {
+		t.Fatalf("Expected first occurrence and the distinct event, got %v", texts)
+	}
+	mu.Unlock()
+
+	time.Sleep(200 * time.Millisecond)
+	d.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(texts) != 3 {
+		t.Fatalf("Expected a summary after the window, got %v", texts)
+	}
+	if !strings.Contains(texts[2], "repeated 49 times") {
+		t.Errorf("Unexpected summary: %q", texts[2])
+	}
+}
+
+func TestDispatcher_CloseFlushesSummaries(t *testing.T) {
+	var mu sync.Mutex
+	count := 0
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		count++
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	cfg := testConfig(config.AlertSinkConfig{Type: "webhook", URL: srv.URL})
+	cfg.Alerts.DedupWindow = 3600
+	d, err := notify.NewDispatcher(cfg)
+	if err != nil {
+		t.Fatalf("NewDispatcher: %v", err)
+	}
+
+	d.Emit(notify.Event{Type: notify.EventHealthCheckFailed, Message: "Health check failed"})
+	d.Emit(notify.Event{Type: notify.EventHealthCheckFailed, Message: "Health check failed"})
+	d.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if count != 2 {
+		t.Errorf("Expected first alert plus flushed summary, got %d", count)
+	}
+}
+
+func TestStatusPageSink_OpensAndResolvesIncident(t *testing.T) {
+	type request struct {
+		method, path, auth string
+		body               map[string]map[string]interface{}
+	}
+	var mu sync.Mutex
+	var requests []request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		requests = append(requests, request{r.Method, r.URL.Path, r.Header.Get("Authorization"), body})
+		mu.Unlock()
+		w.Write([]byte(`{"id":"inc-1"}`))
+	}))
+	defer srv.Close()
+
+	d, err := notify.NewDispatcher(testConfig(config.AlertSinkConfig{
+		Type: "status_page",
+		StatusPage: config.StatusPageConfig{
+			Provider: "statuspage", APIURL: srv.URL, APIKey: "key", PageID: "page", ComponentID: "validator",
+		},
+	}))
+	if err != nil {
+		t.Fatalf("NewDispatcher: %v", err)
+	}
+
+	// Events the page does not show are not posted
+	d.Emit(notify.Event{Type: notify.EventHealthChanged, Severity: notify.SeverityWarning, Message: "unhealthy"})
+	d.Wait()
+	d.Emit(notify.Event{Type: notify.EventNotSigning, Severity: notify.SeverityCritical, Message: "not signing"})
+	d.Wait()
+	d.Emit(notify.Event{Type: notify.EventFailover, Severity: notify.SeverityCritical, Message: "failed over"})
+	d.Wait()
+	d.Emit(notify.Event{Type: notify.EventFailback, Severity: notify.SeverityWarning, Message: "failed back"})
+	d.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(requests) != 3 {
+		t.Fatalf("Expected 3 requests, got %d: %+v", len(requests), requests)
+	}
+	open, resolve, notice := requests[0], requests[1], requests[2]
+	if open.method != http.MethodPost || open.path != "/pages/page/incidents" || open.auth != "OAuth key" {
+		t.Errorf("Unexpected open request: %+v", open)
+	}
+	if open.body["incident"]["status"] != "investigating" ||
+		open.body["incident"]["components"].(map[string]interface{})["validator"] != "major_outage" {
+		t.Errorf("Outage not opened as major_outage: %+v", open.body)
+	}
+	if resolve.method != http.MethodPatch || resolve.path != "/pages/page/incidents/inc-1" ||
+		resolve.body["incident"]["status"] != "resolved" {
+		t.Errorf("Failover should resolve the open incident: %+v", resolve)
+	}
+	// Without an open incident, a failback is posted already resolved
+	if notice.method != http.MethodPost || notice.body["incident"]["status"] != "resolved" ||
+		notice.body["incident"]["name"] != "Validator failed back to its primary node" {
+		t.Errorf("Unexpected failback notice: %+v", notice)
+	}
+}
+
+func TestStatusPageSink_Webhook(t *testing.T) {
+	received := make(chan map[string]interface{}, 2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		received <- body
+	}))
+	defer srv.Close()
+
+	d, err := notify.NewDispatcher(testConfig(config.AlertSinkConfig{
+		Type:       "status_page",
+		URL:        srv.URL,
+		StatusPage: config.StatusPageConfig{Provider: "webhook"},
+	}))
+	if err != nil {
+		t.Fatalf("NewDispatcher: %v", err)
+	}
+	d.Emit(notify.Event{Type: notify.EventDowntimeRisk, Severity: notify.SeverityWarning, Message: "at risk"})
+	d.Wait()
+	d.Emit(notify.Event{Type: notify.EventNotSigning, Severity: notify.SeverityInfo, Message: "signing again"})
+	d.Wait()
+
+	opened, resolved := <-received, <-received
+	if opened["status"] != "degraded_performance" || opened["resolved"] != false {
+		t.Errorf("Unexpected opening update: %v", opened)
+	}
+	if resolved["incident"] != opened["incident"] || resolved["status"] != "operational" || resolved["resolved"] != true {
+		t.Errorf("Unexpected resolving update: %v", resolved)
+	}
+}
+
+func TestChatSinks_EventFilter(t *testing.T) {
+	var mu sync.Mutex
+	received := make(map[string]map[string]string)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		received[r.URL.Path] = body
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	d, err := notify.NewDispatcher(testConfig(
+		config.AlertSinkConfig{Type: "slack", URL: srv.URL + "/slack", Events: []string{"failover", "lock_conflict"}},
+		config.AlertSinkConfig{Type: "discord", URL: srv.URL + "/discord", Events: []string{"key_transfer"}},
+		config.AlertSinkConfig{Type: "telegram", Telegram: config.TelegramConfig{
+			BotToken: "123:abc", ChatID: "-100", APIURL: srv.URL}},
+	))
+	if err != nil {
+		t.Fatalf("NewDispatcher: %v", err)
+	}
+	d.Emit(notify.Event{Type: notify.EventFailover, Severity: notify.SeverityCritical, Message: "failed over"})
+	d.Wait()
+
+	if got := received["/slack"]["text"]; got != "[critical] node-a failover: failed over" {
+		t.Errorf("Slack text = %q", got)
+	}
+	if _, ok := received["/discord"]; ok {
+		t.Error("Discord sink got an event type it does not take")
+	}
+	if got := received["/bot123:abc/sendMessage"]; got["chat_id"] != "-100" || got["text"] == "" {
+		t.Errorf("Telegram message = %v", got)
+	}
+
+	_, err = notify.NewDispatcher(testConfig(config.AlertSinkConfig{Type: "slack", URL: srv.URL, Events: []string{"failovr"}}))
+	if err == nil || !strings.Contains(err.Error(), "unknown event type") {
+		t.Errorf("Expected unknown event type error, got %v", err)
+	}
+}
@@ -0,0 +1,216 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+)
+
+// Well-known OIDs used in every SNMPv2 trap
+const (
+	oidSysUpTime   = "1.3.6.1.2.1.1.3.0"
+	oidSnmpTrapOID = "1.3.6.1.6.3.1.1.4.1.0"
+)
+
+// BER tags used by SNMPv2c
+const (
+	tagInteger     = 0x02
+	tagOctetString = 0x04
+	tagOID         = 0x06
+	tagSequence    = 0x30
+	tagTimeTicks   = 0x43
+	tagTrapV2PDU   = 0xa7
+)
+
+// startTime anchors sysUpTime in outgoing traps
+var startTime = time.Now()
+
+// SNMPSink sends events as SNMPv2c traps over UDP.
+//
+// The trap OID is the configured enterprise OID, and the event is carried
+// in varbinds beneath it:
+//
+//	<enterprise>.1  rendered message (OCTET STRING)
+//	<enterprise>.2  node ID          (OCTET STRING)
+//	<enterprise>.3  event type       (OCTET STRING)
+//	<enterprise>.4  severity         (INTEGER: 0 info, 1 warning, 2 critical)
+type SNMPSink struct {
+	name string
+	cfg  config.SNMPConfig
+	tmpl *template.Template
+}
+
+// NewSNMPSink creates an SNMP trap sink
+func NewSNMPSink(name string, cfg config.SNMPConfig, tmpl *template.Template) (*SNMPSink, error) {
+	if _, err := encodeOID(cfg.EnterpriseOID); err != nil {
+		return nil, fmt.Errorf("invalid enterprise_oid: %w", err)
+	}
+	return &SNMPSink{name: name, cfg: cfg, tmpl: tmpl}, nil
+}
+
+// Name returns the sink name
+func (s *SNMPSink) Name() string { return s.name }
+
+// Send encodes the event as a trap and sends it to the target
+func (s *SNMPSink) Send(ctx context.Context, event Event) error {
+	text, err := Render(s.tmpl, event)
+	if err != nil {
+		return err
+	}
+
+	packet, err := buildTrap(s.cfg.Community, s.cfg.EnterpriseOID, rand.Int31(), text, event)
+	if err != nil {
+		return err
+	}
+
+	target := s.cfg.Target
+	if _, _, err := net.SplitHostPort(target); err != nil {
+		target = net.JoinHostPort(target, "162")
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", target)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s: %w", target, err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write(packet); err != nil {
+		return fmt.Errorf("failed to send trap: %w", err)
+	}
+	return nil
+}
+
+// buildTrap encodes a complete SNMPv2c trap message
+func buildTrap(community, enterprise string, requestID int32, text string, event Event) ([]byte, error) {
+	trapOID, err := encodeOID(enterprise)
+	if err != nil {
+		return nil, err
+	}
+
+	varbinds := [][]byte{
+		varbind(oidSysUpTime, encodeTLV(tagTimeTicks, encodeUint(uint64(time.Since(startTime)/(10*time.Millisecond))))),
+		varbind(oidSnmpTrapOID, encodeTLV(tagOID, trapOID)),
+		varbind(enterprise+".1", encodeTLV(tagOctetString, []byte(text))),
+		varbind(enterprise+".2", encodeTLV(tagOctetString, []byte(event.NodeID))),
+		varbind(enterprise+".3", encodeTLV(tagOctetString, []byte(event.Type))),
+		varbind(enterprise+".4", encodeTLV(tagInteger, encodeInt(int64(event.Severity)))),
+		varbind(enterprise+".5", encodeTLV(tagOctetString, []byte(event.Reason))),
+	}
+
+	pdu := encodeTLV(tagTrapV2PDU, concat(
+		encodeTLV(tagInteger, encodeInt(int64(requestID))),
+		encodeTLV(tagInteger, encodeInt(0)), // error-status
+		encodeTLV(tagInteger, encodeInt(0)), // error-index
+		encodeTLV(tagSequence, concat(varbinds...)),
+	))
+
+	return encodeTLV(tagSequence, concat(
+		encodeTLV(tagInteger, encodeInt(1)), // version: v2c
+		encodeTLV(tagOctetString, []byte(community)),
+		pdu,
+	)), nil
+}
+
+// varbind encodes an OID/value pair; oid is known to be valid
+func varbind(oid string, value []byte) []byte {
+	encoded, _ := encodeOID(oid)
+	return encodeTLV(tagSequence, concat(encodeTLV(tagOID, encoded), value))
+}
+
+// encodeTLV wraps content in a BER tag and length
+func encodeTLV(tag byte, content []byte) []byte {
+	out := []byte{tag}
+	out = append(out, encodeLength(len(content))...)
+	return append(out, content...)
+}
+
+// encodeLength encodes a BER definite length
+func encodeLength(n int) []byte {
+	if n < 0x80 {
+		return []byte{byte(n)}
+	}
+	var digits []byte
+	for v := n; v > 0; v >>= 8 {
+		digits = append([]byte{byte(v)}, digits...)
+	}
+	return append([]byte{0x80 | byte(len(digits))}, digits...)
+}
+
+// encodeInt encodes a two's complement integer in the fewest bytes
+func encodeInt(v int64) []byte {
+	out := []byte{byte(v)}
+	for v > 127 || v < -128 {
+		v >>= 8
+		out = append([]byte{byte(v)}, out...)
+	}
+	return out
+}
+
+// encodeUint encodes an unsigned value, adding a leading zero if the
+// high bit would otherwise make it negative
+func encodeUint(v uint64) []byte {
+	var out []byte
+	for {
+		out = append([]byte{byte(v)}, out...)
+		v >>= 8
+		if v == 0 {
+			break
+		}
+	}
+	if out[0]&0x80 != 0 {
+		out = append([]byte{0}, out...)
+	}
+	return out
+}
+
+// encodeOID encodes a dotted OID string
+func encodeOID(oid string) ([]byte, error) {
+	parts := strings.Split(strings.TrimPrefix(oid, "."), ".")
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("OID %q needs at least two arcs", oid)
+	}
+
+	arcs := make([]uint64, len(parts))
+	for i, p := range parts {
+		v, err := strconv.ParseUint(p, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("OID %q: invalid arc %q", oid, p)
+		}
+		arcs[i] = v
+	}
+	if arcs[0] > 2 || (arcs[0] < 2 && arcs[1] > 39) {
+		return nil, fmt.Errorf("OID %q: invalid leading arcs", oid)
+	}
+
+	out := encodeBase128(arcs[0]*40 + arcs[1])
+	for _, arc := range arcs[2:] {
+		out = append(out, encodeBase128(arc)...)
+	}
+	return out, nil
+}
+
+// encodeBase128 encodes one OID arc
+func encodeBase128(v uint64) []byte {
+	out := []byte{byte(v & 0x7f)}
+	for v >>= 7; v > 0; v >>= 7 {
+		out = append([]byte{byte(v&0x7f) | 0x80}, out...)
+	}
+	return out
+}
+
+// concat joins byte slices
+func concat(parts ...[]byte) []byte {
+	var out []byte
+	for _, p := range parts {
+		out = append(out, p...)
+	}
+	return out
+}
@@ -0,0 +1,325 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+)
+
+// ComponentStatus is the state of the validator's component on a status
+// page, in Statuspage's terms
+type ComponentStatus string
+
+const (
+	StatusOperational   ComponentStatus = "operational"
+	StatusDegraded      ComponentStatus = "degraded_performance"
+	StatusPartialOutage ComponentStatus = "partial_outage"
+	StatusMajorOutage   ComponentStatus = "major_outage"
+)
+
+// incidentUpdate is one change posted to a status page
+type incidentUpdate struct {
+	Title    string
+	Message  string
+	Status   ComponentStatus
+	Resolved bool
+	Time     time.Time
+}
+
+// statusProvider opens and updates incidents on one kind of status page
+type statusProvider interface {
+	open(ctx context.Context, u incidentUpdate) (string, error)
+	update(ctx context.Context, id string, u incidentUpdate) error
+}
+
+// StatusPageSink keeps the validator's component on a public status page
+// in step with what delegators care about: an incident is opened while
+// the validator is not signing or at risk of downtime, and resolved once
+// it signs again or has failed over. A failover or failback without an
+// open incident is posted as an incident that is already resolved. Other
+// events are not posted.
+type StatusPageSink struct {
+	name     string
+	tmpl     *template.Template
+	provider statusProvider
+
+	// mu serializes posts, so updates reach the page in order
+	mu       sync.Mutex
+	incident string
+}
+
+// NewStatusPageSink creates a status page sink for the configured
+// provider; url is the sink URL the webhook provider posts to
+func NewStatusPageSink(name, url string, cfg config.StatusPageConfig, tmpl *template.Template) (*StatusPageSink, error) {
+	client := &http.Client{}
+	var provider statusProvider
+	switch cfg.Provider {
+	case "statuspage":
+		provider = &statuspageProvider{cfg: cfg, client: client}
+	case "instatus":
+		provider = &instatusProvider{cfg: cfg, client: client}
+	case "webhook":
+		provider = &statusWebhookProvider{url: url, client: client}
+	default:
+		return nil, fmt.Errorf("unknown status page provider %q", cfg.Provider)
+	}
+	return &StatusPageSink{name: name, tmpl: tmpl, provider: provider}, nil
+}
+
+// Name returns the sink name
+func (s *StatusPageSink) Name() string { return s.name }
+
+// Send opens, updates or resolves the incident the event calls for
+func (s *StatusPageSink) Send(ctx context.Context, event Event) error {
+	status, ok := componentStatus(event)
+	if !ok {
+		return nil
+	}
+	text, err := Render(s.tmpl, event)
+	if err != nil {
+		return err
+	}
+	u := incidentUpdate{
+		Title:    incidentTitle(event, status),
+		Message:  text,
+		Status:   status,
+		Resolved: status == StatusOperational,
+		Time:     event.Time,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case s.incident != "":
+		if err := s.provider.update(ctx, s.incident, u); err != nil {
+			return err
+		}
+		if u.Resolved {
+			s.incident = ""
+		}
+		return nil
+	case !u.Resolved:
+		id, err := s.provider.open(ctx, u)
+		if err != nil {
+			return err
+		}
+		s.incident = id
+		return nil
+	case event.Type == EventFailover || event.Type == EventFailback:
+		_, err := s.provider.open(ctx, u)
+		return err
+	}
+	return nil
+}
+
+// componentStatus maps an event to the component status it stands for;
+// false for events the status page does not show
+func componentStatus(event Event) (ComponentStatus, bool) {
+	switch event.Type {
+	case EventFailover, EventFailback:
+		return StatusOperational, true
+	case EventDowntimeRisk:
+		if event.Severity == SeverityCritical {
+			return StatusMajorOutage, true
+		}
+		return StatusDegraded, true
+	case EventNotSigning:
+		if event.Severity == SeverityCritical {
+			return StatusMajorOutage, true
+		}
+		return StatusOperational, true
+	}
+	return "", false
+}
+
+// incidentTitle names the incident an event opens
+func incidentTitle(event Event, status ComponentStatus) string {
+	switch {
+	case event.Type == EventFailover:
+		return "Validator failed over to a standby node"
+	case event.Type == EventFailback:
+		return "Validator failed back to its primary node"
+	case status == StatusMajorOutage:
+		return "Validator not signing blocks"
+	default:
+		return "Validator at risk of downtime"
+	}
+}
+
+// statuspageProvider posts incidents through the Atlassian Statuspage API
+type statuspageProvider struct {
+	cfg    config.StatusPageConfig
+	client *http.Client
+}
+
+type statuspageIncident struct {
+	Name         string                     `json:"name,omitempty"`
+	Status       string                     `json:"status"`
+	Body         string                     `json:"body"`
+	ComponentIDs []string                   `json:"component_ids"`
+	Components   map[string]ComponentStatus `json:"components"`
+}
+
+func (p *statuspageProvider) incident(u incidentUpdate, status string) map[string]statuspageIncident {
+	if u.Resolved {
+		status = "resolved"
+	}
+	return map[string]statuspageIncident{"incident": {
+		Status:       status,
+		Body:         u.Message,
+		ComponentIDs: []string{p.cfg.ComponentID},
+		Components:   map[string]ComponentStatus{p.cfg.ComponentID: u.Status},
+	}}
+}
+
+func (p *statuspageProvider) open(ctx context.Context, u incidentUpdate) (string, error) {
+	body := p.incident(u, "investigating")
+	inc := body["incident"]
+	inc.Name = u.Title
+	body["incident"] = inc
+
+	var created struct {
+		ID string `json:"id"`
+	}
+	url := fmt.Sprintf("%s/pages/%s/incidents", p.cfg.APIURL, p.cfg.PageID)
+	if err := postStatus(ctx, p.client, http.MethodPost, url, "OAuth "+p.cfg.APIKey, body, &created); err != nil {
+		return "", err
+	}
+	return created.ID, nil
+}
+
+func (p *statuspageProvider) update(ctx context.Context, id string, u incidentUpdate) error {
+	url := fmt.Sprintf("%s/pages/%s/incidents/%s", p.cfg.APIURL, p.cfg.PageID, id)
+	return postStatus(ctx, p.client, http.MethodPatch, url, "OAuth "+p.cfg.APIKey, p.incident(u, "identified"), nil)
+}
+
+// instatusProvider posts incidents through the Instatus API
+type instatusProvider struct {
+	cfg    config.StatusPageConfig
+	client *http.Client
+}
+
+type instatusComponent struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+type instatusIncident struct {
+	Name       string              `json:"name,omitempty"`
+	Message    string              `json:"message"`
+	Components []string            `json:"components"`
+	Started    string              `json:"started"`
+	Status     string              `json:"status"`
+	Notify     bool                `json:"notify"`
+	Statuses   []instatusComponent `json:"statuses"`
+}
+
+func (p *instatusProvider) incident(u incidentUpdate, status string) instatusIncident {
+	if u.Resolved {
+		status = "RESOLVED"
+	}
+	return instatusIncident{
+		Message:    u.Message,
+		Components: []string{p.cfg.ComponentID},
+		Started:    u.Time.UTC().Format(time.RFC3339),
+		Status:     status,
+		Notify:     true,
+		Statuses: []instatusComponent{{
+			ID:     p.cfg.ComponentID,
+			Status: strings.ToUpper(strings.ReplaceAll(string(u.Status), "_", "")),
+		}},
+	}
+}
+
+func (p *instatusProvider) open(ctx context.Context, u incidentUpdate) (string, error) {
+	body := p.incident(u, "INVESTIGATING")
+	body.Name = u.Title
+
+	var created struct {
+		ID string `json:"id"`
+	}
+	url := fmt.Sprintf("%s/%s/incidents", p.cfg.APIURL, p.cfg.PageID)
+	if err := postStatus(ctx, p.client, http.MethodPost, url, "Bearer "+p.cfg.APIKey, body, &created); err != nil {
+		return "", err
+	}
+	return created.ID, nil
+}
+
+func (p *instatusProvider) update(ctx context.Context, id string, u incidentUpdate) error {
+	url := fmt.Sprintf("%s/%s/incidents/%s/incident-updates", p.cfg.APIURL, p.cfg.PageID, id)
+	return postStatus(ctx, p.client, http.MethodPost, url, "Bearer "+p.cfg.APIKey, p.incident(u, "IDENTIFIED"), nil)
+}
+
+// statusWebhookProvider posts every incident update as JSON to a URL,
+// for status pages without a provider of their own
+type statusWebhookProvider struct {
+	url    string
+	client *http.Client
+}
+
+// statusWebhookPayload is the JSON body sent to the status webhook
+type statusWebhookPayload struct {
+	Incident string          `json:"incident"`
+	Title    string          `json:"title"`
+	Message  string          `json:"message"`
+	Status   ComponentStatus `json:"status"`
+	Resolved bool            `json:"resolved"`
+	Time     time.Time       `json:"time"`
+}
+
+func (p *statusWebhookProvider) open(ctx context.Context, u incidentUpdate) (string, error) {
+	id := fmt.Sprintf("%d", u.Time.UnixNano())
+	return id, p.update(ctx, id, u)
+}
+
+func (p *statusWebhookProvider) update(ctx context.Context, id string, u incidentUpdate) error {
+	return postStatus(ctx, p.client, http.MethodPost, p.url, "", statusWebhookPayload{
+		Incident: id,
+		Title:    u.Title,
+		Message:  u.Message,
+		Status:   u.Status,
+		Resolved: u.Resolved,
+		Time:     u.Time,
+	}, nil)
+}
+
+// postStatus sends body as JSON and decodes the answer into out, if given
+func postStatus(ctx context.Context, client *http.Client, method, url, auth string, body, out interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal incident: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("status page returned status %d", resp.StatusCode)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode status page answer: %w", err)
+	}
+	return nil
+}
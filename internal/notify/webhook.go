@@ -0,0 +1,62 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"text/template"
+)
+
+// WebhookSink posts events as JSON to an HTTP endpoint
+type WebhookSink struct {
+	name   string
+	url    string
+	tmpl   *template.Template
+	client *http.Client
+}
+
+// webhookPayload is the JSON body sent to webhooks
+type webhookPayload struct {
+	Event
+	Text string `json:"text"`
+}
+
+// NewWebhookSink creates a webhook sink
+func NewWebhookSink(name, url string, tmpl *template.Template) *WebhookSink {
+	return &WebhookSink{name: name, url: url, tmpl: tmpl, client: &http.Client{}}
+}
+
+// Name returns the sink name
+func (s *WebhookSink) Name() string { return s.name }
+
+// Send posts the event with its rendered text
+func (s *WebhookSink) Send(ctx context.Context, event Event) error {
+	text, err := Render(s.tmpl, event)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(webhookPayload{Event: event, Text: text})
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package peerproto_test
+
+import (
+	"testing"
+
+	"github.com/aldebaranode/syncguard/internal/peerproto"
+)
+
+// FuzzUnmarshal feeds arbitrary bytes to every message type. Decoding must
+// not panic, and whatever decodes must survive a canonical round trip.
+func FuzzUnmarshal(f *testing.F) {
+	for _, m := range []peerproto.Message{
+		&peerproto.Heartbeat{NodeID: "node-1", Healthy: true, Height: 100, Progress: peerproto.Progress{Role: "active"}},
+		&peerproto.HeartbeatAck{NodeID: "node-2", Height: 99, Disagreement: "lagging"},
+		&peerproto.ValidatorState{Height: 42, Round: 1, Step: 3, Signature: "sig"},
+		&peerproto.KeyTransfer{SealedKey: []byte("sealed")},
+		&peerproto.Transition{Kind: "failover", Reason: "drill"},
+	} {
+		f.Add(peerproto.Marshal(m))
+	}
+	f.Add([]byte{})
+	f.Add([]byte{0x08, 0x01, 0x1a, 0xff, 0xff, 0xff, 0xff, 0x0f})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, newMessage := range []func() peerproto.Message{
+			func() peerproto.Message { return &peerproto.Heartbeat{} },
+			func() peerproto.Message { return &peerproto.HeartbeatAck{} },
+			func() peerproto.Message { return &peerproto.ValidatorState{} },
+			func() peerproto.Message { return &peerproto.KeyTransfer{} },
+			func() peerproto.Message { return &peerproto.Transition{} },
+		} {
+			m := newMessage()
+			if err := peerproto.Unmarshal(data, m); err != nil {
+				continue
+			}
+			again := newMessage()
+			if err := peerproto.Unmarshal(peerproto.Marshal(m), again); err != nil {
+				t.Fatalf("re-encoded %T does not decode: %v", m, err)
+			}
+		}
+	})
+}
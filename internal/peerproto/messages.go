@@ -0,0 +1,154 @@
+package peerproto
+
+import (
+	"fmt"
+	"slices"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Heartbeat is the active node's health report
+type Heartbeat struct {
+	NodeID         string
+	Healthy        bool
+	Syncing        bool
+	Height         int64
+	Peers          int64
+	StatusError    string
+	ExecutionError string
+	TimeUnixNano   int64
+	Progress       Progress
+	Transitions    Transitions
+}
+
+func (*Heartbeat) messageType() string { return TypeHeartbeat }
+
+func (m *Heartbeat) fields() []field {
+	return append([]field{
+		{1, &m.NodeID}, {2, &m.Healthy}, {3, &m.Syncing}, {4, &m.Height},
+		{5, &m.Peers}, {6, &m.StatusError}, {7, &m.ExecutionError}, {8, &m.TimeUnixNano},
+	}, append(m.Progress.fields(9), m.Transitions.fields(16)...)...)
+}
+
+// HeartbeatAck is a passive node's view of a heartbeat
+type HeartbeatAck struct {
+	NodeID       string
+	Healthy      bool
+	Height       int64
+	Disagreement string
+	Progress     Progress
+	Transitions  Transitions
+}
+
+func (*HeartbeatAck) messageType() string { return TypeHeartbeatAck }
+
+func (m *HeartbeatAck) fields() []field {
+	return append([]field{{1, &m.NodeID}, {2, &m.Healthy}, {3, &m.Height}, {4, &m.Disagreement}},
+		append(m.Progress.fields(5), m.Transitions.fields(12)...)...)
+}
+
+// Progress is a node's failover countdown. It is carried inline by
+// Heartbeat and HeartbeatAck, numbered from the first field after theirs;
+// an empty Role means the sender did not report it.
+type Progress struct {
+	Role                  string
+	FailureCount          int64
+	FailoverAfter         int64
+	FailbackAfterSeconds  int64
+	SinceUnixNano         int64
+	NextCheckUnixNano     int64
+	NextStateSyncUnixNano int64
+}
+
+// Transitions sums up a node's changes of role. It is carried inline like
+// Progress; Last and Reasons are map<string, int64> entries, encoded with
+// EncodeCounts.
+type Transitions struct {
+	Epoch int64
+	// Last is when each type of transition last happened, in unix nanos
+	Last    [][]byte
+	Reasons [][]byte
+}
+
+func (m *Transitions) fields(first protowire.Number) []field {
+	return []field{{first, &m.Epoch}, {first + 1, &m.Last}, {first + 2, &m.Reasons}}
+}
+
+// EncodeCounts encodes a map as map entries, in key order
+func EncodeCounts(counts map[string]int64) [][]byte {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	entries := make([][]byte, 0, len(keys))
+	for _, key := range keys {
+		value := counts[key]
+		entries = append(entries, encodeFields([]field{{1, &key}, {2, &value}}))
+	}
+	return entries
+}
+
+// DecodeCounts collects map entries into a map; nil when there are none
+func DecodeCounts(entries [][]byte) (map[string]int64, error) {
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	counts := make(map[string]int64, len(entries))
+	for _, entry := range entries {
+		var key string
+		var value int64
+		if err := decodeFields(entry, []field{{1, &key}, {2, &value}}); err != nil {
+			return nil, fmt.Errorf("invalid map entry: %w", err)
+		}
+		counts[key] = value
+	}
+	return counts, nil
+}
+
+func (m *Progress) fields(first protowire.Number) []field {
+	return []field{
+		{first, &m.Role}, {first + 1, &m.FailureCount}, {first + 2, &m.FailoverAfter},
+		{first + 3, &m.FailbackAfterSeconds}, {first + 4, &m.SinceUnixNano},
+		{first + 5, &m.NextCheckUnixNano}, {first + 6, &m.NextStateSyncUnixNano},
+	}
+}
+
+// ValidatorState is the CometBFT double-sign state
+type ValidatorState struct {
+	Height    int64
+	Round     int32
+	Step      int32
+	Signature string
+	SignBytes string
+}
+
+func (*ValidatorState) messageType() string { return TypeValidatorState }
+
+func (m *ValidatorState) fields() []field {
+	return []field{{1, &m.Height}, {2, &m.Round}, {3, &m.Step}, {4, &m.Signature}, {5, &m.SignBytes}}
+}
+
+// KeyTransfer carries the encrypted validator key
+type KeyTransfer struct {
+	SealedKey []byte
+}
+
+func (*KeyTransfer) messageType() string { return TypeKeyTransfer }
+
+func (m *KeyTransfer) fields() []field {
+	return []field{{1, &m.SealedKey}}
+}
+
+// Transition asks the peer to take over or release
+type Transition struct {
+	Kind   string
+	Reason string
+}
+
+func (*Transition) messageType() string { return TypeTransition }
+
+func (m *Transition) fields() []field {
+	return []field{{1, &m.Kind}, {2, &m.Reason}}
+}
@@ -0,0 +1,203 @@
+// Package peerproto encodes peer messages as protobuf, following the schema
+// in peer.proto. Messages are hand-encoded with protowire rather than
+// generated, and always canonically: fields in field number order, zero
+// values left out. The same message therefore always has the same bytes,
+// which is what request signatures cover.
+package peerproto
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Version is the peer protocol version this node speaks
+const Version = 1
+
+// ContentType is the media type of an encoded envelope
+const ContentType = "application/x-protobuf"
+
+// Message types named in the envelope
+const (
+	TypeHeartbeat      = "heartbeat"
+	TypeHeartbeatAck   = "heartbeat_ack"
+	TypeValidatorState = "validator_state"
+	TypeKeyTransfer    = "key_transfer"
+	TypeTransition     = "transition"
+)
+
+// ErrUnsupportedVersion is returned for envelopes from a newer protocol
+var ErrUnsupportedVersion = errors.New("unsupported peer protocol version")
+
+// Message is a peer message that can travel in an envelope
+type Message interface {
+	messageType() string
+	fields() []field
+}
+
+// field binds a field number to a Go value: *string, *[]byte, *bool,
+// *int32, *int64, or *[][]byte for a repeated bytes or message field
+type field struct {
+	num protowire.Number
+	ptr interface{}
+}
+
+// Marshal encodes m in a versioned envelope
+func Marshal(m Message) []byte {
+	var b []byte
+	b = appendField(b, field{1, ptrUint32(Version)})
+	b = appendField(b, field{2, ptrString(m.messageType())})
+	payload := encodeFields(m.fields())
+	b = appendField(b, field{3, &payload})
+	return b
+}
+
+// Unmarshal decodes an envelope into m. It fails for another message type
+// and for a version newer than Version.
+func Unmarshal(data []byte, m Message) error {
+	var version int64
+	var msgType string
+	var payload []byte
+	if err := decodeFields(data, []field{{1, &version}, {2, &msgType}, {3, &payload}}); err != nil {
+		return fmt.Errorf("invalid envelope: %w", err)
+	}
+	if version == 0 {
+		return fmt.Errorf("invalid envelope: no version")
+	}
+	if version > Version {
+		return fmt.Errorf("%w %d, this node speaks up to %d", ErrUnsupportedVersion, version, Version)
+	}
+	if msgType != m.messageType() {
+		return fmt.Errorf("expected a %s message, got %q", m.messageType(), msgType)
+	}
+	if err := decodeFields(payload, m.fields()); err != nil {
+		return fmt.Errorf("invalid %s message: %w", msgType, err)
+	}
+	return nil
+}
+
+// encodeFields appends fields in field number order, leaving out zero values
+func encodeFields(fields []field) []byte {
+	var b []byte
+	for _, f := range fields {
+		b = appendField(b, f)
+	}
+	return b
+}
+
+func appendField(b []byte, f field) []byte {
+	switch v := f.ptr.(type) {
+	case *string:
+		if *v != "" {
+			b = protowire.AppendTag(b, f.num, protowire.BytesType)
+			b = protowire.AppendString(b, *v)
+		}
+	case *[]byte:
+		if len(*v) > 0 {
+			b = protowire.AppendTag(b, f.num, protowire.BytesType)
+			b = protowire.AppendBytes(b, *v)
+		}
+	case *bool:
+		if *v {
+			b = protowire.AppendTag(b, f.num, protowire.VarintType)
+			b = protowire.AppendVarint(b, 1)
+		}
+	case *int32:
+		if *v != 0 {
+			b = protowire.AppendTag(b, f.num, protowire.VarintType)
+			b = protowire.AppendVarint(b, uint64(int64(*v)))
+		}
+	case *int64:
+		if *v != 0 {
+			b = protowire.AppendTag(b, f.num, protowire.VarintType)
+			b = protowire.AppendVarint(b, uint64(*v))
+		}
+	case *[][]byte:
+		for _, item := range *v {
+			b = protowire.AppendTag(b, f.num, protowire.BytesType)
+			b = protowire.AppendBytes(b, item)
+		}
+	}
+	return b
+}
+
+// decodeFields sets the fields found in b. Unknown fields, added by newer
+// nodes within the same version, are skipped.
+func decodeFields(b []byte, fields []field) error {
+	byNum := make(map[protowire.Number]interface{}, len(fields))
+	for _, f := range fields {
+		byNum[f.num] = f.ptr
+	}
+
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		ptr, known := byNum[num]
+		if !known {
+			n = protowire.ConsumeFieldValue(num, typ, b)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			b = b[n:]
+			continue
+		}
+
+		switch v := ptr.(type) {
+		case *string, *[]byte, *[][]byte:
+			if typ != protowire.BytesType {
+				return fmt.Errorf("field %d: wire type %d, want bytes", num, typ)
+			}
+			raw, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			switch v := v.(type) {
+			case *string:
+				*v = string(raw)
+			case *[]byte:
+				*v = append([]byte(nil), raw...)
+			case *[][]byte:
+				*v = append(*v, append([]byte(nil), raw...))
+			}
+			b = b[n:]
+		default:
+			if typ != protowire.VarintType {
+				return fmt.Errorf("field %d: wire type %d, want varint", num, typ)
+			}
+			x, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			switch v := v.(type) {
+			case *bool:
+				*v = x != 0
+			case *int32:
+				// A negative int32 is sign-extended to 64 bits on the wire;
+				// anything outside its range would wrap
+				if n := int64(x); n < math.MinInt32 || n > math.MaxInt32 {
+					return fmt.Errorf("field %d: %d overflows int32", num, n)
+				}
+				*v = int32(x)
+			case *int64:
+				*v = int64(x)
+			}
+			b = b[n:]
+		}
+	}
+	return nil
+}
+
+func ptrString(s string) *string { return &s }
+
+// ptrUint32 encodes a uint32 field, which shares the varint encoding of
+// a non-negative int64
+func ptrUint32(v uint32) *int64 {
+	x := int64(v)
+	return &x
+}
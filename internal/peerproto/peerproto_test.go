@@ -0,0 +1,167 @@
+package peerproto_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/aldebaranode/syncguard/internal/peerproto"
+)
+
+func TestMarshal_RoundTrip(t *testing.T) {
+	in := &peerproto.Heartbeat{
+		NodeID:       "validator-1",
+		Healthy:      true,
+		Height:       1200,
+		Peers:        7,
+		StatusError:  "",
+		TimeUnixNano: 1760000000000000000,
+		Progress:     peerproto.Progress{Role: "active", FailureCount: 2, FailoverAfter: 3, SinceUnixNano: 1759999000000000000},
+		Transitions: peerproto.Transitions{Epoch: 4,
+			Reasons: peerproto.EncodeCounts(map[string]int64{"node_unhealthy": 3, "manual": 1})},
+	}
+	data := peerproto.Marshal(in)
+
+	out := &peerproto.Heartbeat{}
+	if err := peerproto.Unmarshal(data, out); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("round trip = %+v, want %+v", out, in)
+	}
+	if reasons, err := peerproto.DecodeCounts(out.Transitions.Reasons); err != nil || reasons["node_unhealthy"] != 3 || len(reasons) != 2 {
+		t.Errorf("reason counts = %v, %v", reasons, err)
+	}
+	if !bytes.Equal(peerproto.Marshal(out), data) {
+		t.Error("encoding is not canonical")
+	}
+
+	state := &peerproto.ValidatorState{Height: 42, Round: -1, Step: 3}
+	decoded := &peerproto.ValidatorState{}
+	if err := peerproto.Unmarshal(peerproto.Marshal(state), decoded); err != nil || *decoded != *state {
+		t.Errorf("state round trip = %+v, %v", decoded, err)
+	}
+}
+
+func TestUnmarshal_Rejects(t *testing.T) {
+	data := peerproto.Marshal(&peerproto.Transition{Kind: "failover", Reason: "drill"})
+
+	if err := peerproto.Unmarshal(data, &peerproto.Heartbeat{}); err == nil || !strings.Contains(err.Error(), "expected a heartbeat") {
+		t.Errorf("expected a type mismatch, got %v", err)
+	}
+
+	// Version is field 1, a varint: bump it past what this node speaks
+	newer := append([]byte{0x08, peerproto.Version + 1}, data[2:]...)
+	if err := peerproto.Unmarshal(newer, &peerproto.Transition{}); !errors.Is(err, peerproto.ErrUnsupportedVersion) {
+		t.Errorf("expected an unsupported version, got %v", err)
+	}
+
+	if err := peerproto.Unmarshal([]byte(`{"height":"1"}`), &peerproto.ValidatorState{}); err == nil {
+		t.Error("expected JSON to be rejected")
+	}
+}
+
+// TestMarshal_MatchesProtobuf checks the hand encoding against the protobuf
+// library encoding the same schema
+func TestMarshal_MatchesProtobuf(t *testing.T) {
+	scalar := func(name string, num int32, typ descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(name),
+			Number:   proto.Int32(num),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     typ.Enum(),
+		}
+	}
+	file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("peer.proto"),
+		Package: proto.String("syncguard.peer.v1"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: proto.String("Envelope"), Field: []*descriptorpb.FieldDescriptorProto{
+				scalar("version", 1, descriptorpb.FieldDescriptorProto_TYPE_UINT32),
+				scalar("type", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				scalar("payload", 3, descriptorpb.FieldDescriptorProto_TYPE_BYTES),
+			}},
+			{Name: proto.String("ValidatorState"), Field: []*descriptorpb.FieldDescriptorProto{
+				scalar("height", 1, descriptorpb.FieldDescriptorProto_TYPE_INT64),
+				scalar("round", 2, descriptorpb.FieldDescriptorProto_TYPE_INT32),
+				scalar("step", 3, descriptorpb.FieldDescriptorProto_TYPE_INT32),
+				scalar("signature", 4, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				scalar("sign_bytes", 5, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+			}},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("invalid descriptor: %v", err)
+	}
+
+	stateDesc := file.Messages().ByName("ValidatorState")
+	state := dynamicpb.NewMessage(stateDesc)
+	state.Set(stateDesc.Fields().ByName("height"), protoreflect.ValueOfInt64(42))
+	state.Set(stateDesc.Fields().ByName("round"), protoreflect.ValueOfInt32(-1))
+	state.Set(stateDesc.Fields().ByName("signature"), protoreflect.ValueOfString("c2ln"))
+	// Deterministic orders fields by number; dynamic messages otherwise
+	// come out in any order
+	canonical := proto.MarshalOptions{Deterministic: true}
+	payload, err := canonical.Marshal(state)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	envDesc := file.Messages().ByName("Envelope")
+	env := dynamicpb.NewMessage(envDesc)
+	env.Set(envDesc.Fields().ByName("version"), protoreflect.ValueOfUint32(peerproto.Version))
+	env.Set(envDesc.Fields().ByName("type"), protoreflect.ValueOfString(peerproto.TypeValidatorState))
+	env.Set(envDesc.Fields().ByName("payload"), protoreflect.ValueOfBytes(payload))
+	want, err := canonical.Marshal(env)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	got := peerproto.Marshal(&peerproto.ValidatorState{Height: 42, Round: -1, Signature: "c2ln"})
+	if !bytes.Equal(got, want) {
+		t.Errorf("encoding = %x, protobuf encodes %x", got, want)
+	}
+}
+
+func TestCodec_RoundTrip(t *testing.T) {
+	codec := peerproto.Codec{}
+	in := &peerproto.Call{
+		Method: "POST",
+		Path:   "/failover_notify",
+		Header: http.Header{"X-Syncguard-Reason": {"drill"}, "Accept": {"a", "b"}},
+		Body:   []byte(`{}`),
+	}
+	data, err := codec.Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	out := &peerproto.Call{}
+	if err := codec.Unmarshal(data, out); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("round trip = %+v, want %+v", out, in)
+	}
+
+	reply := &peerproto.Reply{Status: 404}
+	data, _ = codec.Marshal(reply)
+	decoded := &peerproto.Reply{}
+	if err := codec.Unmarshal(data, decoded); err != nil || decoded.Status != 404 || len(decoded.Header) != 0 {
+		t.Errorf("reply round trip = %+v, %v", decoded, err)
+	}
+
+	if _, err := codec.Marshal(&peerproto.Heartbeat{}); err == nil {
+		t.Error("expected envelope messages to be refused")
+	}
+}
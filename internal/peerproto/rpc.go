@@ -0,0 +1,102 @@
+package peerproto
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+)
+
+// The Peer gRPC service (peer_api.protocol: grpc)
+const (
+	ServiceName      = "syncguard.peer.v1.Peer"
+	MethodCall       = "/" + ServiceName + "/Call"
+	MethodWatchState = "/" + ServiceName + "/WatchState"
+)
+
+// Call is a peer API request carried by the Peer service: the same
+// method, path, headers and body as over HTTP
+type Call struct {
+	Method string
+	Path   string
+	Header http.Header
+	Body   []byte
+}
+
+// Reply is the peer API's answer to a Call
+type Reply struct {
+	Status int32
+	Header http.Header
+	Body   []byte
+}
+
+// Codec marshals Call and Reply for gRPC; it satisfies grpc's
+// encoding.Codec. Calls and replies are not wrapped in an envelope: the
+// bodies they carry are.
+type Codec struct{}
+
+// Name is the codec gRPC names in the content type
+func (Codec) Name() string { return "proto" }
+
+// Marshal encodes a *Call or *Reply
+func (Codec) Marshal(v interface{}) ([]byte, error) {
+	switch m := v.(type) {
+	case *Call:
+		header := encodeHeader(m.Header)
+		return encodeFields([]field{{1, &m.Method}, {2, &m.Path}, {3, &header}, {4, &m.Body}}), nil
+	case *Reply:
+		header := encodeHeader(m.Header)
+		return encodeFields([]field{{1, &m.Status}, {2, &header}, {3, &m.Body}}), nil
+	}
+	return nil, fmt.Errorf("cannot marshal %T", v)
+}
+
+// Unmarshal decodes a *Call or *Reply
+func (Codec) Unmarshal(data []byte, v interface{}) error {
+	var header [][]byte
+	var err error
+	switch m := v.(type) {
+	case *Call:
+		if err := decodeFields(data, []field{{1, &m.Method}, {2, &m.Path}, {3, &header}, {4, &m.Body}}); err != nil {
+			return fmt.Errorf("invalid call: %w", err)
+		}
+		m.Header, err = decodeHeader(header)
+	case *Reply:
+		if err := decodeFields(data, []field{{1, &m.Status}, {2, &header}, {3, &m.Body}}); err != nil {
+			return fmt.Errorf("invalid reply: %w", err)
+		}
+		m.Header, err = decodeHeader(header)
+	default:
+		return fmt.Errorf("cannot unmarshal into %T", v)
+	}
+	return err
+}
+
+// encodeHeader encodes each header value as a Header message, in name order
+func encodeHeader(h http.Header) [][]byte {
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var items [][]byte
+	for _, name := range names {
+		for _, value := range h[name] {
+			items = append(items, encodeFields([]field{{1, &name}, {2, &value}}))
+		}
+	}
+	return items
+}
+
+// decodeHeader collects Header messages into a header
+func decodeHeader(items [][]byte) (http.Header, error) {
+	h := make(http.Header, len(items))
+	for _, item := range items {
+		var name, value string
+		if err := decodeFields(item, []field{{1, &name}, {2, &value}}); err != nil {
+			return nil, fmt.Errorf("invalid header: %w", err)
+		}
+		h.Add(name, value)
+	}
+	return h, nil
+}
@@ -0,0 +1,448 @@
+package policy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Expr is a compiled policy expression, such as
+//
+//	height_lag < 5 && peers >= 3 && !catching_up
+//
+// Expressions combine facts (identifiers), numbers, strings in double
+// quotes and true/false with:
+//
+//	||  or      &&  and      !  not
+//	==  !=  <  <=  >  >=
+//	+  -  *  /   and parentheses
+//
+// Comparisons other than == and != take numbers. The whole expression must
+// evaluate to a boolean.
+type Expr struct {
+	src  string
+	root node
+}
+
+// Facts are the values an expression is evaluated against: float64, bool
+// or string, by name
+type Facts map[string]interface{}
+
+// Compile parses src. With known set, identifiers outside it are rejected,
+// so a typo fails at config load rather than at the first health check.
+func Compile(src string, known []string) (*Expr, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, fmt.Errorf("policy %q: %w", src, err)
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err == nil && p.peek().kind != tokEOF {
+		err = fmt.Errorf("unexpected %q at offset %d", p.peek().text, p.peek().pos)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("policy %q: %w", src, err)
+	}
+	if known != nil {
+		for _, name := range identifiers(root) {
+			if !contains(known, name) {
+				return nil, fmt.Errorf("policy %q: unknown fact %q (known: %s)", src, name, strings.Join(known, ", "))
+			}
+		}
+	}
+	return &Expr{src: src, root: root}, nil
+}
+
+// String returns the expression's source
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Eval evaluates the expression; a missing fact or a type mismatch is an
+// error
+func (e *Expr) Eval(facts Facts) (bool, error) {
+	v, err := e.root.eval(facts)
+	if err != nil {
+		return false, fmt.Errorf("policy %q: %w", e.src, err)
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("policy %q: result is %s, not a boolean", e.src, typeName(v))
+	}
+	return b, nil
+}
+
+// Tokens
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokString
+	tokIdent
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// operators, longest first so "<=" is not read as "<"
+var operators = []string{"||", "&&", "==", "!=", "<=", ">=", "<", ">", "!", "+", "-", "*", "/", "(", ")"}
+
+// lex splits src into tokens; "and", "or" and "not" read as &&, || and !
+func lex(src string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(src); {
+		c := rune(src[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c >= '0' && c <= '9' || c == '.':
+			start := i
+			for i < len(src) && (src[i] >= '0' && src[i] <= '9' || src[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{tokNumber, src[start:i], start})
+		case c == '"':
+			start := i
+			i++
+			for i < len(src) && src[i] != '"' {
+				i++
+			}
+			if i == len(src) {
+				return nil, fmt.Errorf("unterminated string at offset %d", start)
+			}
+			i++
+			tokens = append(tokens, token{tokString, src[start+1 : i-1], start})
+		case c == '_' || unicode.IsLetter(c):
+			start := i
+			for i < len(src) && (src[i] == '_' || unicode.IsLetter(rune(src[i])) || unicode.IsDigit(rune(src[i]))) {
+				i++
+			}
+			word := src[start:i]
+			switch word {
+			case "and":
+				tokens = append(tokens, token{tokOp, "&&", start})
+			case "or":
+				tokens = append(tokens, token{tokOp, "||", start})
+			case "not":
+				tokens = append(tokens, token{tokOp, "!", start})
+			default:
+				tokens = append(tokens, token{tokIdent, word, start})
+			}
+		default:
+			matched := false
+			for _, op := range operators {
+				if strings.HasPrefix(src[i:], op) {
+					tokens = append(tokens, token{tokOp, op, i})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected %q at offset %d", c, i)
+			}
+		}
+	}
+	return append(tokens, token{kind: tokEOF, pos: len(src)}), nil
+}
+
+// Parser: precedence climbs from || to unary operators
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) accept(ops ...string) (string, bool) {
+	t := p.peek()
+	if t.kind != tokOp {
+		return "", false
+	}
+	for _, op := range ops {
+		if t.text == op {
+			p.pos++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+// binaryLevel parses operands joined by any of ops, left to right
+func (p *parser) binaryLevel(next func() (node, error), ops ...string) (node, error) {
+	left, err := next()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.accept(ops...)
+		if !ok {
+			return left, nil
+		}
+		right, err := next()
+		if err != nil {
+			return nil, err
+		}
+		left = &binary{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) parseOr() (node, error) {
+	return p.binaryLevel(p.parseAnd, "||")
+}
+
+func (p *parser) parseAnd() (node, error) {
+	return p.binaryLevel(p.parseComparison, "&&")
+}
+
+// parseComparison does not chain: "a < b < c" is an error
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	op, ok := p.accept("==", "!=", "<=", ">=", "<", ">")
+	if !ok {
+		return left, nil
+	}
+	right, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	return &binary{op: op, left: left, right: right}, nil
+}
+
+func (p *parser) parseSum() (node, error) {
+	return p.binaryLevel(p.parseProduct, "+", "-")
+}
+
+func (p *parser) parseProduct() (node, error) {
+	return p.binaryLevel(p.parseUnary, "*", "/")
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if op, ok := p.accept("!", "-"); ok {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &unary{op: op, operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.peek()
+	switch t.kind {
+	case tokNumber:
+		p.pos++
+		n, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at offset %d", t.text, t.pos)
+		}
+		return literal{n}, nil
+	case tokString:
+		p.pos++
+		return literal{t.text}, nil
+	case tokIdent:
+		p.pos++
+		switch t.text {
+		case "true":
+			return literal{true}, nil
+		case "false":
+			return literal{false}, nil
+		}
+		return ident(t.text), nil
+	case tokOp:
+		if t.text == "(" {
+			p.pos++
+			inner, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := p.accept(")"); !ok {
+				return nil, fmt.Errorf("missing ) at offset %d", p.peek().pos)
+			}
+			return inner, nil
+		}
+	case tokEOF:
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	return nil, fmt.Errorf("unexpected %q at offset %d", t.text, t.pos)
+}
+
+// Evaluation
+
+type node interface {
+	eval(facts Facts) (interface{}, error)
+}
+
+type literal struct{ value interface{} }
+
+func (l literal) eval(Facts) (interface{}, error) {
+	return l.value, nil
+}
+
+type ident string
+
+func (i ident) eval(facts Facts) (interface{}, error) {
+	v, ok := facts[string(i)]
+	if !ok {
+		return nil, fmt.Errorf("fact %q is not available", string(i))
+	}
+	switch n := v.(type) {
+	case int:
+		return float64(n), nil
+	case int64:
+		return float64(n), nil
+	}
+	return v, nil
+}
+
+type unary struct {
+	op      string
+	operand node
+}
+
+func (u *unary) eval(facts Facts) (interface{}, error) {
+	v, err := u.operand.eval(facts)
+	if err != nil {
+		return nil, err
+	}
+	if u.op == "!" {
+		b, ok := v.(bool)
+		if !ok {
+			return nil, fmt.Errorf("! needs a boolean, got %s", typeName(v))
+		}
+		return !b, nil
+	}
+	n, ok := v.(float64)
+	if !ok {
+		return nil, fmt.Errorf("- needs a number, got %s", typeName(v))
+	}
+	return -n, nil
+}
+
+type binary struct {
+	op          string
+	left, right node
+}
+
+func (b *binary) eval(facts Facts) (interface{}, error) {
+	left, err := b.left.eval(facts)
+	if err != nil {
+		return nil, err
+	}
+
+	// && and || short-circuit, so a guard can skip a missing fact
+	if b.op == "&&" || b.op == "||" {
+		l, ok := left.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%s needs booleans, got %s", b.op, typeName(left))
+		}
+		if (b.op == "&&" && !l) || (b.op == "||" && l) {
+			return l, nil
+		}
+		right, err := b.right.eval(facts)
+		if err != nil {
+			return nil, err
+		}
+		r, ok := right.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%s needs booleans, got %s", b.op, typeName(right))
+		}
+		return r, nil
+	}
+
+	right, err := b.right.eval(facts)
+	if err != nil {
+		return nil, err
+	}
+	switch b.op {
+	case "==":
+		return left == right, sameType(left, right, b.op)
+	case "!=":
+		return left != right, sameType(left, right, b.op)
+	}
+
+	l, lok := left.(float64)
+	r, rok := right.(float64)
+	if !lok || !rok {
+		return nil, fmt.Errorf("%s needs numbers, got %s and %s", b.op, typeName(left), typeName(right))
+	}
+	switch b.op {
+	case "<":
+		return l < r, nil
+	case "<=":
+		return l <= r, nil
+	case ">":
+		return l > r, nil
+	case ">=":
+		return l >= r, nil
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	case "/":
+		if r == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return l / r, nil
+	}
+	return nil, fmt.Errorf("unknown operator %s", b.op)
+}
+
+// sameType rejects == and != across types, which are always false or true
+// and most likely a mistake
+func sameType(left, right interface{}, op string) error {
+	if typeName(left) != typeName(right) {
+		return fmt.Errorf("%s compares %s with %s", op, typeName(left), typeName(right))
+	}
+	return nil
+}
+
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// identifiers lists the facts an expression refers to
+func identifiers(n node) []string {
+	switch n := n.(type) {
+	case ident:
+		return []string{string(n)}
+	case *unary:
+		return identifiers(n.operand)
+	case *binary:
+		return append(identifiers(n.left), identifiers(n.right)...)
+	}
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package policy
+
+import (
+	"strings"
+	"testing"
+)
+
+var known = []string{"height_lag", "peers", "catching_up", "network"}
+
+func TestCompile_Invalid(t *testing.T) {
+	for _, src := range []string{
+		"",
+		"peers >=",
+		"(peers > 3",
+		"peers > 3)",
+		"peers > 1 < 3",
+		"peers ~ 3",
+		`network == "mainnet`,
+		"peer > 3",
+	} {
+		if _, err := Compile(src, known); err == nil {
+			t.Errorf("Compile(%q) should fail", src)
+		}
+	}
+}
+
+func TestExpr_Eval(t *testing.T) {
+	facts := Facts{
+		"height_lag":  2.0,
+		"peers":       4,
+		"catching_up": false,
+		"network":     "mainnet",
+	}
+
+	tests := []struct {
+		src  string
+		want bool
+	}{
+		{"height_lag < 5 && peers >= 3 && !catching_up", true},
+		{"height_lag < 2", false},
+		{"height_lag <= 2 and not catching_up", true},
+		{"catching_up or peers > 10", false},
+		{"!(peers == 4)", false},
+		{"peers - height_lag * 2 == 0", true},
+		{"peers / 2 >= 2", true},
+		{"-height_lag < 0", true},
+		{`network == "mainnet"`, true},
+		{`network != "testnet" && true`, true},
+	}
+	for _, tt := range tests {
+		expr, err := Compile(tt.src, known)
+		if err != nil {
+			t.Fatalf("Compile(%q): %v", tt.src, err)
+		}
+		got, err := expr.Eval(facts)
+		if err != nil {
+			t.Fatalf("Eval(%q): %v", tt.src, err)
+		}
+		if got != tt.want {
+			t.Errorf("Eval(%q) = %v, want %v", tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestExpr_EvalErrors(t *testing.T) {
+	facts := Facts{"peers": 4.0, "catching_up": false, "network": "mainnet"}
+
+	tests := []struct {
+		src     string
+		wantErr string
+	}{
+		{"peers", "not a boolean"},
+		{"peers > 3 && height_lag < 5", `fact "height_lag" is not available`},
+		{"peers > catching_up", "needs numbers"},
+		{`network == 1`, "compares string with number"},
+		{"peers / 0 > 1", "division by zero"},
+		{"!peers", "needs a boolean"},
+	}
+	for _, tt := range tests {
+		expr, err := Compile(tt.src, nil)
+		if err != nil {
+			t.Fatalf("Compile(%q): %v", tt.src, err)
+		}
+		_, err = expr.Eval(facts)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("Eval(%q) error = %v, want %q", tt.src, err, tt.wantErr)
+		}
+	}
+
+	// && short-circuits past a fact that is not available
+	expr, _ := Compile("catching_up && height_lag < 5", nil)
+	if ok, err := expr.Eval(facts); ok || err != nil {
+		t.Errorf("short-circuit Eval = %v, %v; want false, nil", ok, err)
+	}
+}
@@ -0,0 +1,303 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/history"
+)
+
+// Entry types the report reads; they match what the failover manager
+// records in history
+const (
+	entryDowntime   = "downtime"
+	entrySigning    = "signing"
+	entryNotSigning = "not_signing"
+)
+
+// transitionTypes are the history events counted as changes of role
+var transitionTypes = []string{"failover", "failback", "takeover", "release"}
+
+// Options selects the period a report covers
+type Options struct {
+	Since time.Time
+	Until time.Time
+}
+
+// Report is uptime and signing performance over a period, per chain
+type Report struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	Since       time.Time `json:"since"`
+	Until       time.Time `json:"until"`
+	Chains      []*Chain  `json:"chains"`
+}
+
+// Chain is one chain's share of a report
+type Chain struct {
+	// ChainID is "" for entries recorded before the chain was known
+	ChainID   string   `json:"chain_id"`
+	Addresses []string `json:"addresses,omitempty"`
+
+	// Uptime is the percentage of the period not spent in recorded
+	// downtime intervals
+	Uptime            float64 `json:"uptime_percent"`
+	DowntimeSeconds   float64 `json:"downtime_seconds"`
+	DowntimeIntervals int     `json:"downtime_intervals"`
+
+	// Signing covers the blocks the signing activity feed examined; SignedRate
+	// is a percentage and 0 when no blocks were examined
+	Blocks     int     `json:"blocks"`
+	Signed     int     `json:"signed"`
+	Missed     int     `json:"missed"`
+	SignedRate float64 `json:"signed_percent"`
+	FromHeight int64   `json:"from_height,omitempty"`
+	ToHeight   int64   `json:"to_height,omitempty"`
+
+	// Transitions counts changes of role by event type
+	Transitions map[string]int `json:"transitions"`
+	// NotSigningAlerts counts critical not_signing alerts
+	NotSigningAlerts int `json:"not_signing_alerts"`
+}
+
+// Build reads history entries in the period and aggregates them per chain.
+// Entries without a chain ID are folded into the only chain when there is
+// one, and reported under an empty chain ID otherwise.
+func Build(store *history.Store, opts Options) (*Report, error) {
+	if opts.Until.IsZero() {
+		opts.Until = time.Now().UTC()
+	}
+	if !opts.Since.Before(opts.Until) {
+		return nil, fmt.Errorf("report period is empty: %s to %s",
+			opts.Since.Format(time.RFC3339), opts.Until.Format(time.RFC3339))
+	}
+
+	chains := make(map[string]*Chain)
+	chainFor := func(id string) *Chain {
+		c, ok := chains[id]
+		if !ok {
+			c = &Chain{ChainID: id, Transitions: make(map[string]int)}
+			chains[id] = c
+		}
+		return c
+	}
+
+	// Downtime entries are written when an interval ends, so one that
+	// started before the period is found by reading further back and
+	// clipped below
+	readFrom := opts.Since.Add(-7 * 24 * time.Hour)
+	err := store.Read(readFrom, func(e history.Entry) error {
+		if e.Kind != history.KindEvent || !e.Time.Before(opts.Until) {
+			return nil
+		}
+		chainID := e.Fields["chain_id"]
+		if e.Type == entryDowntime {
+			start, err1 := time.Parse(time.RFC3339Nano, e.Fields["start"])
+			end, err2 := time.Parse(time.RFC3339Nano, e.Fields["end"])
+			if err1 != nil || err2 != nil {
+				return nil
+			}
+			if overlap := clip(start, end, opts.Since, opts.Until); overlap > 0 {
+				c := chainFor(chainID)
+				c.DowntimeSeconds += overlap.Seconds()
+				c.DowntimeIntervals++
+			}
+			return nil
+		}
+
+		if e.Time.Before(opts.Since) {
+			return nil
+		}
+		switch {
+		case e.Type == entrySigning:
+			chainFor(chainID).addSigning(e.Fields)
+		case e.Type == entryNotSigning && e.Severity == "critical":
+			chainFor(chainID).NotSigningAlerts++
+		case isTransition(e.Type):
+			chainFor(chainID).Transitions[e.Type]++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if unlabeled, ok := chains[""]; ok && len(chains) == 2 {
+		delete(chains, "")
+		for _, c := range chains {
+			c.merge(unlabeled)
+		}
+	}
+
+	period := opts.Until.Sub(opts.Since).Seconds()
+	report := &Report{GeneratedAt: time.Now().UTC(), Since: opts.Since.UTC(), Until: opts.Until.UTC()}
+	for _, c := range chains {
+		c.Uptime = 100 * (1 - c.DowntimeSeconds/period)
+		if c.Blocks > 0 {
+			c.SignedRate = 100 * float64(c.Signed) / float64(c.Blocks)
+		}
+		sort.Strings(c.Addresses)
+		report.Chains = append(report.Chains, c)
+	}
+	sort.Slice(report.Chains, func(i, j int) bool { return report.Chains[i].ChainID < report.Chains[j].ChainID })
+	return report, nil
+}
+
+// addSigning adds one signing summary entry
+func (c *Chain) addSigning(fields map[string]string) {
+	signed, _ := strconv.Atoi(fields["signed"])
+	missed, _ := strconv.Atoi(fields["missed"])
+	from, _ := strconv.ParseInt(fields["from_height"], 10, 64)
+	to, _ := strconv.ParseInt(fields["to_height"], 10, 64)
+
+	c.Signed += signed
+	c.Missed += missed
+	c.Blocks += signed + missed
+	if from > 0 && (c.FromHeight == 0 || from < c.FromHeight) {
+		c.FromHeight = from
+	}
+	if to > c.ToHeight {
+		c.ToHeight = to
+	}
+	c.addAddress(fields["address"])
+}
+
+func (c *Chain) addAddress(address string) {
+	if address == "" {
+		return
+	}
+	for _, a := range c.Addresses {
+		if a == address {
+			return
+		}
+	}
+	c.Addresses = append(c.Addresses, address)
+}
+
+// merge folds other's counts into c
+func (c *Chain) merge(other *Chain) {
+	c.DowntimeSeconds += other.DowntimeSeconds
+	c.DowntimeIntervals += other.DowntimeIntervals
+	c.Blocks += other.Blocks
+	c.Signed += other.Signed
+	c.Missed += other.Missed
+	if other.FromHeight > 0 && (c.FromHeight == 0 || other.FromHeight < c.FromHeight) {
+		c.FromHeight = other.FromHeight
+	}
+	if other.ToHeight > c.ToHeight {
+		c.ToHeight = other.ToHeight
+	}
+	for _, a := range other.Addresses {
+		c.addAddress(a)
+	}
+	for k, v := range other.Transitions {
+		c.Transitions[k] += v
+	}
+	c.NotSigningAlerts += other.NotSigningAlerts
+}
+
+func isTransition(eventType string) bool {
+	for _, t := range transitionTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// clip returns how much of [start, end) falls within [from, to)
+func clip(start, end, from, to time.Time) time.Duration {
+	if start.Before(from) {
+		start = from
+	}
+	if end.After(to) {
+		end = to
+	}
+	if !end.After(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
+
+// Write renders the report as "json" or "markdown"
+func Write(w io.Writer, r *Report, format string) error {
+	switch format {
+	case "", "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	case "markdown", "md":
+		_, err := io.WriteString(w, r.Markdown())
+		return err
+	default:
+		return fmt.Errorf("unknown report format %q (accepted: json, markdown)", format)
+	}
+}
+
+// Markdown renders the report for delegator updates and SLO reviews
+func (r *Report) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Validator uptime report\n\n")
+	fmt.Fprintf(&b, "Period: %s to %s (%s)\n\n",
+		r.Since.Format(time.RFC3339), r.Until.Format(time.RFC3339), formatPeriod(r.Until.Sub(r.Since)))
+
+	if len(r.Chains) == 0 {
+		b.WriteString("No history was recorded in this period.\n")
+		return b.String()
+	}
+
+	for _, c := range r.Chains {
+		name := c.ChainID
+		if name == "" {
+			name = "unknown chain"
+		}
+		fmt.Fprintf(&b, "## %s\n\n", name)
+		if len(c.Addresses) > 0 {
+			fmt.Fprintf(&b, "Validator: `%s`\n\n", strings.Join(c.Addresses, "`, `"))
+		}
+		b.WriteString("| Metric | Value |\n|---|---|\n")
+		fmt.Fprintf(&b, "| Uptime | %.3f%% |\n", c.Uptime)
+		fmt.Fprintf(&b, "| Downtime | %s in %d interval(s) |\n",
+			time.Duration(c.DowntimeSeconds*float64(time.Second)).Round(time.Second), c.DowntimeIntervals)
+		if c.Blocks > 0 {
+			fmt.Fprintf(&b, "| Blocks signed | %d of %d (%.3f%%) |\n", c.Signed, c.Blocks, c.SignedRate)
+			fmt.Fprintf(&b, "| Blocks missed | %d |\n", c.Missed)
+			fmt.Fprintf(&b, "| Heights | %d to %d |\n", c.FromHeight, c.ToHeight)
+		} else {
+			b.WriteString("| Blocks signed | not tracked (chain.signing disabled) |\n")
+		}
+		for _, t := range transitionTypes {
+			if n := c.Transitions[t]; n > 0 {
+				fmt.Fprintf(&b, "| %s%s | %d |\n", strings.ToUpper(t[:1]), t[1:]+"s", n)
+			}
+		}
+		fmt.Fprintf(&b, "| Not-signing alerts | %d |\n\n", c.NotSigningAlerts)
+	}
+	return b.String()
+}
+
+// formatPeriod prints whole days as "30d" and anything else as a duration
+func formatPeriod(d time.Duration) string {
+	if d%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	}
+	return d.Round(time.Second).String()
+}
+
+// ParsePeriod parses a report period: a number of days ("30d") or a
+// duration ("72h")
+func ParsePeriod(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err == nil && n > 0 {
+			return time.Duration(n) * 24 * time.Hour, nil
+		}
+	} else if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return d, nil
+	}
+	return 0, fmt.Errorf("invalid --period %q (use days like 30d or a duration like 72h)", value)
+}
@@ -0,0 +1,130 @@
+package report
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/history"
+)
+
+func TestBuild(t *testing.T) {
+	store := history.NewStore(filepath.Join(t.TempDir(), "history.jsonl"), 0)
+	until := time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)
+	since := until.Add(-10 * 24 * time.Hour)
+	at := func(d time.Duration) string { return since.Add(d).Format(time.RFC3339Nano) }
+
+	entries := []history.Entry{
+		// Started before the period: only the hour inside it counts
+		{Time: since.Add(time.Hour), Kind: history.KindEvent, Type: "downtime",
+			Fields: map[string]string{"start": at(-time.Hour), "end": at(time.Hour), "chain_id": "test-1"}},
+		{Time: since.Add(2 * time.Hour), Kind: history.KindEvent, Type: "signing",
+			Fields: map[string]string{"chain_id": "test-1", "address": "ABCD", "from_height": "100",
+				"to_height": "699", "signed": "590", "missed": "10"}},
+		{Time: since.Add(3 * time.Hour), Kind: history.KindEvent, Type: "signing",
+			Fields: map[string]string{"chain_id": "test-1", "address": "ABCD", "from_height": "700",
+				"to_height": "1099", "signed": "400", "missed": "0"}},
+		// Recorded before the chain was known; folded into the only chain
+		{Time: since.Add(4 * time.Hour), Kind: history.KindEvent, Type: "failover", Severity: "critical"},
+		{Time: since.Add(5 * time.Hour), Kind: history.KindEvent, Type: "not_signing", Severity: "critical",
+			Fields: map[string]string{"chain_id": "test-1"}},
+		{Time: since.Add(6 * time.Hour), Kind: history.KindEvent, Type: "not_signing", Severity: "info",
+			Fields: map[string]string{"chain_id": "test-1"}},
+		// Operator actions and entries outside the period are left out
+		{Time: since.Add(7 * time.Hour), Kind: history.KindAudit, Type: "failover"},
+		{Time: since.Add(-time.Hour), Kind: history.KindEvent, Type: "failback"},
+		{Time: until.Add(time.Hour), Kind: history.KindEvent, Type: "signing",
+			Fields: map[string]string{"chain_id": "test-1", "signed": "5"}},
+	}
+	for _, e := range entries {
+		if err := store.Append(e); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+
+	r, err := Build(store, Options{Since: since, Until: until})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if len(r.Chains) != 1 {
+		t.Fatalf("expected one chain, got %+v", r.Chains)
+	}
+	c := r.Chains[0]
+	if c.ChainID != "test-1" || c.DowntimeSeconds != 3600 || c.DowntimeIntervals != 1 {
+		t.Errorf("unexpected downtime: %+v", c)
+	}
+	wantUptime := 100 * (1 - 3600.0/(10*24*3600))
+	if c.Uptime != wantUptime {
+		t.Errorf("Uptime = %f, want %f", c.Uptime, wantUptime)
+	}
+	if c.Blocks != 1000 || c.Signed != 990 || c.Missed != 10 || c.SignedRate != 99 {
+		t.Errorf("unexpected signing: %+v", c)
+	}
+	if c.FromHeight != 100 || c.ToHeight != 1099 || len(c.Addresses) != 1 {
+		t.Errorf("unexpected heights or addresses: %+v", c)
+	}
+	if c.Transitions["failover"] != 1 || c.Transitions["failback"] != 0 || c.NotSigningAlerts != 1 {
+		t.Errorf("unexpected transitions or alerts: %+v", c)
+	}
+
+	var md bytes.Buffer
+	if err := Write(&md, r, "markdown"); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	for _, want := range []string{"## test-1", "(10d)", "| Uptime | 99.583% |", "990 of 1000 (99.000%)", "| Failovers | 1 |"} {
+		if !strings.Contains(md.String(), want) {
+			t.Errorf("markdown lacks %q:\n%s", want, md.String())
+		}
+	}
+	if err := Write(&md, r, "xml"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestBuild_SeparateChains(t *testing.T) {
+	store := history.NewStore(filepath.Join(t.TempDir(), "history.jsonl"), 0)
+	now := time.Now().UTC()
+	for _, id := range []string{"b-1", "a-1", ""} {
+		store.Append(history.Entry{Time: now.Add(-time.Hour), Kind: history.KindEvent, Type: "signing",
+			Fields: map[string]string{"chain_id": id, "signed": "1"}})
+	}
+
+	r, err := Build(store, Options{Since: now.Add(-24 * time.Hour), Until: now})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	var ids []string
+	for _, c := range r.Chains {
+		ids = append(ids, c.ChainID)
+	}
+	if strings.Join(ids, ",") != ",a-1,b-1" {
+		t.Errorf("chains = %q", ids)
+	}
+}
+
+func TestParsePeriod(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"30d", 30 * 24 * time.Hour},
+		{"72h", 72 * time.Hour},
+		{"0d", 0},
+		{"-1h", 0},
+		{"month", 0},
+	}
+	for _, tt := range tests {
+		got, err := ParsePeriod(tt.value)
+		if tt.want == 0 {
+			if err == nil {
+				t.Errorf("ParsePeriod(%q) expected an error", tt.value)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParsePeriod(%q) = %v, %v; want %v", tt.value, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,527 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"strings"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/approval"
+	"github.com/aldebaranode/syncguard/internal/chain"
+	"github.com/aldebaranode/syncguard/internal/coldstandby"
+	"github.com/aldebaranode/syncguard/internal/communication"
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/crypto"
+	"github.com/aldebaranode/syncguard/internal/deprecation"
+	"github.com/aldebaranode/syncguard/internal/drill"
+	"github.com/aldebaranode/syncguard/internal/election"
+	"github.com/aldebaranode/syncguard/internal/failback"
+	"github.com/aldebaranode/syncguard/internal/health"
+	"github.com/aldebaranode/syncguard/internal/history"
+	"github.com/aldebaranode/syncguard/internal/logger"
+	"github.com/aldebaranode/syncguard/internal/node"
+	"github.com/aldebaranode/syncguard/internal/supervise"
+	"github.com/aldebaranode/syncguard/internal/transition"
+)
+
+// Admin API paths
+const (
+	PathAdminStatus   = "/admin/status"
+	PathAdminDrill    = "/admin/drill"
+	PathDrillRevert   = "/admin/drill/revert"
+	PathAdminPause    = "/admin/pause"
+	PathAdminResume   = "/admin/resume"
+	PathHandoff       = "/admin/handoff"
+	PathFailback      = "/admin/failback"
+	PathRetireSecret  = "/admin/secret/retire"
+	PathApprove       = "/admin/failover/approve"
+	PathReject        = "/admin/failover/reject"
+	PathConfirmActive = "/admin/confirm-active"
+	PathDrain         = "/admin/drain"
+	PathUndrain       = "/admin/undrain"
+	PathDebugPprof    = "/debug/pprof/"
+)
+
+// DrillController runs failover drills
+type DrillController interface {
+	Start(duration time.Duration) (drill.Status, error)
+	Revert() error
+	Status() (drill.Status, bool)
+}
+
+// PeerStatusProvider reports reachability of peers
+type PeerStatusProvider interface {
+	PeerStatuses() []communication.PeerStatus
+}
+
+// OperatorController performs operator actions on the failover manager
+type OperatorController interface {
+	Pause()
+	Resume()
+	IsPaused() bool
+	Handoff() error
+	Failback() error
+	// Services reports the managed processes; nil when none are managed
+	Services() []node.ServiceStatus
+	// FailbackStatus reports the primary's progress toward failback
+	FailbackStatus() failback.Status
+	// LastTransition is the most recent change of role, with its reason code
+	LastTransition() *history.Entry
+	// Heartbeat reports the health reports exchanged with peers; nil when
+	// heartbeats are disabled
+	Heartbeat() *health.HeartbeatStatus
+	// Broadcasts reports the last outcome of each kind of message sent to
+	// every peer, with the peers that missed it
+	Broadcasts() []communication.Broadcast
+	// ColdStandby reports shipping to a cold standby; nil without one
+	ColdStandby() *coldstandby.Status
+	// SecretStatus reports the cluster secrets accepted, by fingerprint
+	SecretStatus() crypto.SecretStatus
+	// RetireSecret stops accepting the previous cluster secret
+	RetireSecret() error
+	// Approval reports the failover awaiting or given approval; nil when
+	// there is none
+	Approval() *approval.Request
+	// ApproveFailover fails over on approval; an empty id approves the
+	// current request
+	ApproveFailover(id string) error
+	// RejectFailover keeps the node active for the rest of the outage
+	RejectFailover(id string) error
+	// Transitions reports the changes of role in flight
+	Transitions() transition.Status
+	// AwaitingConfirmation reports a node configured active but held
+	// passive on first boot or after a reboot
+	AwaitingConfirmation() bool
+	// ConfirmActive takes the validator role on a held node
+	ConfirmActive() error
+	// Drain hands over if active and keeps the node from taking the
+	// validator role until Undrain
+	Drain() error
+	Undrain() error
+	// ElectionStatus reports this node's view of the election; nil when
+	// election is disabled
+	ElectionStatus() *election.Status
+}
+
+// ChainStatusProvider reports our validator's on-chain metadata and its
+// downtime budget
+type ChainStatusProvider interface {
+	ValidatorInfo() (chain.ValidatorInfo, bool)
+	DowntimeBudget() chain.Budget
+	// SigningActivity reports our validator's precommits in recent blocks;
+	// false without the signing activity feed
+	SigningActivity() (chain.SigningActivity, bool)
+}
+
+// AdminServer serves operator endpoints on a separate, local listener.
+// It is never exposed to peers: requests must come from loopback, or carry
+// the configured bearer token when the listener is bound elsewhere.
+type AdminServer struct {
+	listen         string
+	token          string
+	pprof          bool
+	nodeID         string
+	instance       string
+	monitorOnly    bool
+	healthProvider HealthProvider
+	nodeStatus     NodeStatusProvider
+	peers          PeerStatusProvider
+	drills         DrillController
+	operator       OperatorController
+	chain          ChainStatusProvider
+	cache          *responseCache
+	logger         *logger.Logger
+	httpServer     *http.Server
+}
+
+// NewAdminServer creates the admin API server
+func NewAdminServer(
+	cfg *config.Config,
+	healthProvider HealthProvider,
+	nodeStatus NodeStatusProvider,
+	peers PeerStatusProvider,
+	drills DrillController,
+	operator OperatorController,
+	chainStatus ChainStatusProvider,
+) *AdminServer {
+	newLogger := logger.New(cfg, "admin")
+
+	return &AdminServer{
+		listen:         cfg.Admin.Listen,
+		token:          cfg.Admin.Token,
+		pprof:          cfg.SelfMonitor.Pprof,
+		nodeID:         cfg.Node.ID,
+		instance:       cfg.Node.Instance,
+		monitorOnly:    cfg.Node.MonitorOnly,
+		healthProvider: healthProvider,
+		nodeStatus:     nodeStatus,
+		peers:          peers,
+		drills:         drills,
+		operator:       operator,
+		chain:          chainStatus,
+		cache:          newResponseCache(cfg.PeerAPI.CacheTTL.Duration()),
+		logger:         newLogger,
+	}
+}
+
+// Start starts the admin HTTP server
+func (a *AdminServer) Start() error {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc(PathAdminStatus, a.cache.wrap(a.handleStatus))
+	mux.HandleFunc(PathAdminDrill, a.handleDrill)
+	mux.HandleFunc(PathDrillRevert, a.handleDrillRevert)
+	mux.HandleFunc(PathAdminPause, a.handlePause)
+	mux.HandleFunc(PathAdminResume, a.handleResume)
+	mux.HandleFunc(PathHandoff, a.handleHandoff)
+	mux.HandleFunc(PathFailback, a.handleFailback)
+	mux.HandleFunc(PathRetireSecret, a.handleRetireSecret)
+	mux.HandleFunc(PathApprove, a.handleApprove)
+	mux.HandleFunc(PathReject, a.handleReject)
+	mux.HandleFunc(PathConfirmActive, a.handleConfirmActive)
+	mux.HandleFunc(PathDrain, a.handleDrain)
+	mux.HandleFunc(PathUndrain, a.handleUndrain)
+	if a.pprof {
+		mux.HandleFunc(PathDebugPprof, pprof.Index)
+		mux.HandleFunc(PathDebugPprof+"cmdline", pprof.Cmdline)
+		mux.HandleFunc(PathDebugPprof+"profile", pprof.Profile)
+		mux.HandleFunc(PathDebugPprof+"symbol", pprof.Symbol)
+		mux.HandleFunc(PathDebugPprof+"trace", pprof.Trace)
+	}
+
+	a.httpServer = &http.Server{
+		Addr:    a.listen,
+		Handler: a.guard(a.cache.invalidateOnWrite(supervise.Handler(a.logger, "admin-api", namespaced(a.instance, mux)))),
+	}
+
+	a.logger.Info("Starting admin server on %s", a.listen)
+	return a.httpServer.ListenAndServe()
+}
+
+// guard admits loopback clients, or any client with the bearer token
+func (a *AdminServer) guard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if a.token != "" {
+			want := "Bearer " + a.token
+			if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte(want)) != 1 {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+		} else if !isLoopback(r.RemoteAddr) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// namespaced serves mux only under /<instance>, so a command meant for
+// another instance on the host, or behind the same proxy, is not found
+// rather than carried out here. Without an instance, routes are unchanged.
+func namespaced(instance string, mux http.Handler) http.Handler {
+	if instance == "" {
+		return mux
+	}
+	prefix := "/" + instance
+	strip := http.StripPrefix(prefix, mux)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, prefix+"/") {
+			http.NotFound(w, r)
+			return
+		}
+		strip.ServeHTTP(w, r)
+	})
+}
+
+// AdminURL turns the admin listen address into a URL for local clients,
+// under the instance's routes when node.instance is set
+func AdminURL(listen, instance string) string {
+	if listen == "" {
+		return ""
+	}
+	host, port, err := net.SplitHostPort(listen)
+	if err != nil {
+		return ""
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+	base := "http://" + net.JoinHostPort(host, port)
+	if instance != "" {
+		base += "/" + instance
+	}
+	return base
+}
+
+// isLoopback reports whether a remote address is on the local host
+func isLoopback(remoteAddr string) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// handleStatus returns a snapshot of this node for operators and bundles
+func (a *AdminServer) handleStatus(w http.ResponseWriter, r *http.Request) {
+	status := map[string]interface{}{
+		"node_id":            a.nodeID,
+		"instance":           a.instance,
+		"time":               time.Now().UTC(),
+		"healthy":            a.healthProvider.IsHealthy(),
+		"active":             a.nodeStatus.IsActive(),
+		"primary":            a.nodeStatus.IsPrimary(),
+		"paused":             a.operator.IsPaused(),
+		"height":             a.healthProvider.GetLastHeight(),
+		"process":            health.ReadResourceUsage(),
+		"peers":              a.peers.PeerStatuses(),
+		"progress":           a.nodeStatus.Progress(),
+		"transition_summary": a.nodeStatus.TransitionSummary(),
+	}
+	if info, ok := a.chain.ValidatorInfo(); ok {
+		status["validator"] = info
+	}
+	if signing, ok := a.chain.SigningActivity(); ok {
+		status["signing"] = signing
+	}
+	status["downtime"] = a.chain.DowntimeBudget()
+	status["failback"] = a.operator.FailbackStatus()
+	status["secret"] = a.operator.SecretStatus()
+	if last := a.operator.LastTransition(); last != nil {
+		status["last_transition"] = last
+	}
+	if heartbeat := a.operator.Heartbeat(); heartbeat != nil {
+		status["heartbeat"] = heartbeat
+	}
+	if broadcasts := a.operator.Broadcasts(); len(broadcasts) > 0 {
+		status["broadcasts"] = broadcasts
+	}
+	if readiness := a.nodeStatus.Readiness(); readiness != nil {
+		status["readiness"] = readiness
+	}
+	if peer := a.nodeStatus.PeerProgress(); peer != nil {
+		status["peer_progress"] = peer
+	}
+	if peer := a.nodeStatus.PeerTransitions(); peer != nil {
+		status["peer_transitions"] = peer
+	}
+	if inFlight := a.operator.Transitions(); inFlight.Running != nil || len(inFlight.Queued) > 0 {
+		status["transitions"] = inFlight
+	}
+	if req := a.operator.Approval(); req != nil {
+		status["approval"] = req
+	}
+	if a.operator.AwaitingConfirmation() {
+		status["awaiting_confirmation"] = true
+	}
+	if a.monitorOnly {
+		status["monitor_only"] = true
+	}
+	if a.nodeStatus.IsDraining() {
+		status["draining"] = true
+	}
+	if elected := a.operator.ElectionStatus(); elected != nil {
+		status["election"] = elected
+	}
+	if cold := a.operator.ColdStandby(); cold != nil {
+		status["cold_standby"] = cold
+	}
+	if services := a.operator.Services(); services != nil {
+		status["services"] = services
+	}
+	if usages := deprecation.Snapshot(); len(usages) > 0 {
+		status["deprecations"] = usages
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+// handleDrill starts a drill (POST ?duration=10m) or reports the last one (GET)
+func (a *AdminServer) handleDrill(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		status, ok := a.drills.Status()
+		if !ok {
+			http.Error(w, "No drill has run", http.StatusNotFound)
+			return
+		}
+		writeJSON(w, status)
+	case http.MethodPost:
+		duration, err := time.ParseDuration(r.URL.Query().Get("duration"))
+		if err != nil {
+			http.Error(w, "Invalid duration", http.StatusBadRequest)
+			return
+		}
+		status, err := a.drills.Start(duration)
+		if err != nil {
+			code := http.StatusPreconditionFailed
+			if errors.Is(err, drill.ErrRunning) {
+				code = http.StatusConflict
+			}
+			http.Error(w, err.Error(), code)
+			return
+		}
+		a.logger.Info("Failover drill %s started via admin API", status.ID)
+		writeJSON(w, status)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleDrillRevert fails back a running drill now
+func (a *AdminServer) handleDrillRevert(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := a.drills.Revert(); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	status, _ := a.drills.Status()
+	writeJSON(w, status)
+}
+
+// handlePause suspends automatic failover
+func (a *AdminServer) handlePause(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	a.operator.Pause()
+	writeJSON(w, map[string]bool{"paused": true})
+}
+
+// handleResume re-enables automatic failover
+func (a *AdminServer) handleResume(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	a.operator.Resume()
+	writeJSON(w, map[string]bool{"paused": false})
+}
+
+// handleHandoff hands validator duties to the standby
+func (a *AdminServer) handleHandoff(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := a.operator.Handoff(); err != nil {
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+		return
+	}
+	a.logger.Info("Handoff to standby completed via admin API")
+	writeJSON(w, map[string]bool{"active": false})
+}
+
+// handleFailback reclaims validator duties for the primary
+func (a *AdminServer) handleFailback(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := a.operator.Failback(); err != nil {
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+		return
+	}
+	a.logger.Info("Failback to primary completed via admin API")
+	writeJSON(w, map[string]bool{"active": true})
+}
+
+// handleRetireSecret stops accepting the previous cluster secret
+func (a *AdminServer) handleRetireSecret(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := a.operator.RetireSecret(); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	a.logger.Info("Previous cluster secret retired via admin API")
+	writeJSON(w, a.operator.SecretStatus())
+}
+
+// handleApprove approves the failover awaiting approval
+func (a *AdminServer) handleApprove(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := a.operator.ApproveFailover(r.URL.Query().Get("id")); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	a.logger.Info("Failover approved via admin API")
+	writeJSON(w, map[string]bool{"active": false})
+}
+
+// handleReject rejects the failover awaiting approval
+func (a *AdminServer) handleReject(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := a.operator.RejectFailover(r.URL.Query().Get("id")); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	a.logger.Warn("Failover rejected via admin API")
+	writeJSON(w, a.operator.Approval())
+}
+
+// handleConfirmActive takes the validator role on a held node
+func (a *AdminServer) handleConfirmActive(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := a.operator.ConfirmActive(); err != nil {
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+		return
+	}
+	a.logger.Warn("First boot confirmed via admin API")
+	writeJSON(w, map[string]bool{"active": true})
+}
+
+// handleDrain takes this node out of service, handing over if active
+func (a *AdminServer) handleDrain(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := a.operator.Drain(); err != nil {
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+		return
+	}
+	a.logger.Warn("Node drained via admin API")
+	writeJSON(w, map[string]bool{"draining": true})
+}
+
+// handleUndrain lets a drained node take the validator role again
+func (a *AdminServer) handleUndrain(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := a.operator.Undrain(); err != nil {
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+		return
+	}
+	a.logger.Info("Node undrained via admin API")
+	writeJSON(w, map[string]bool{"draining": false})
+}
+
+// writeJSON encodes v as the response body
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,46 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAdminURL(t *testing.T) {
+	tests := []struct {
+		listen, instance, want string
+	}{
+		{"", "", ""},
+		{"127.0.0.1:9090", "", "http://127.0.0.1:9090"},
+		{":9090", "", "http://127.0.0.1:9090"},
+		{"0.0.0.0:9090", "story-mainnet", "http://127.0.0.1:9090/story-mainnet"},
+	}
+	for _, tt := range tests {
+		if got := AdminURL(tt.listen, tt.instance); got != tt.want {
+			t.Errorf("AdminURL(%q, %q) = %q, want %q", tt.listen, tt.instance, got, tt.want)
+		}
+	}
+}
+
+func TestNamespaced(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc(PathAdminStatus, func(w http.ResponseWriter, r *http.Request) {})
+
+	tests := []struct {
+		instance, path string
+		want           int
+	}{
+		{"", PathAdminStatus, http.StatusOK},
+		{"chain-a", "/chain-a" + PathAdminStatus, http.StatusOK},
+		{"chain-a", PathAdminStatus, http.StatusNotFound},
+		{"chain-a", "/chain-b" + PathAdminStatus, http.StatusNotFound},
+		{"chain-a", "/chain-ab" + PathAdminStatus, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		namespaced(tt.instance, mux).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rec.Code != tt.want {
+			t.Errorf("instance %q, %s: status %d, want %d", tt.instance, tt.path, rec.Code, tt.want)
+		}
+	}
+}
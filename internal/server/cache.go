@@ -0,0 +1,126 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/communication"
+	"github.com/aldebaranode/syncguard/internal/metrics"
+)
+
+var cacheCounter = metrics.NewCounter(
+	"syncguard_http_cache_total",
+	"Read-only responses served from cache or recomputed",
+	"path", "result",
+)
+
+// responseCache keeps successful GET responses for a short TTL so dashboard
+// and monitoring load does not recompute status or re-read state files on
+// every request
+type responseCache struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]*cachedResponse
+}
+
+// cachedResponse is a captured response
+type cachedResponse struct {
+	header  http.Header
+	body    []byte
+	expires time.Time
+}
+
+// newResponseCache creates a cache; a non-positive TTL disables caching
+func newResponseCache(ttl time.Duration) *responseCache {
+	return &responseCache{ttl: ttl, entries: make(map[string]*cachedResponse)}
+}
+
+// wrap serves GET requests from cache while the entry is fresh
+func (c *responseCache) wrap(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if c.ttl <= 0 || r.Method != http.MethodGet {
+			next(w, r)
+			return
+		}
+
+		// Peers asking for protobuf get their own entry
+		key := r.URL.Path
+		if communication.AcceptsProto(r.Header.Get("Accept")) {
+			key += "#proto"
+		}
+		if !bypassRequested(r) {
+			c.mu.Lock()
+			entry, ok := c.entries[key]
+			c.mu.Unlock()
+			if ok && time.Now().Before(entry.expires) {
+				cacheCounter.Inc(r.URL.Path, "hit")
+				for k, v := range entry.header {
+					w.Header()[k] = v
+				}
+				w.Write(entry.body)
+				return
+			}
+		}
+
+		cacheCounter.Inc(r.URL.Path, "miss")
+		rec := &recorder{ResponseWriter: w, status: http.StatusOK}
+		next(rec, r)
+
+		if rec.status == http.StatusOK {
+			c.mu.Lock()
+			c.entries[key] = &cachedResponse{
+				header:  w.Header().Clone(),
+				body:    rec.body.Bytes(),
+				expires: time.Now().Add(c.ttl),
+			}
+			c.mu.Unlock()
+		}
+	}
+}
+
+// invalidate drops all cached responses
+func (c *responseCache) invalidate() {
+	c.mu.Lock()
+	c.entries = make(map[string]*cachedResponse)
+	c.mu.Unlock()
+}
+
+// invalidateOnWrite clears the cache after any mutating request, so a
+// takeover or key change is visible immediately. Heartbeats and link probes
+// change nothing that is cached; heartbeats would otherwise defeat the
+// cache on passives.
+func (c *responseCache) invalidateOnWrite(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r)
+		if r.Method != http.MethodGet && r.Method != http.MethodHead && r.URL.Path != communication.PathHeartbeat &&
+			r.URL.Path != communication.PathLinkProbe {
+			c.invalidate()
+		}
+	})
+}
+
+// bypassRequested reports whether the client asked for a fresh response.
+// Peers send Cache-Control: no-cache during transitions.
+func bypassRequested(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Cache-Control"), "no-cache")
+}
+
+// recorder passes a response through while keeping a copy of it
+type recorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *recorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
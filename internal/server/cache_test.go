@@ -0,0 +1,61 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestResponseCache(t *testing.T) {
+	cache := newResponseCache(time.Minute)
+	calls := 0
+	handler := cache.invalidateOnWrite(cache.wrap(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok":true}`))
+	}))
+
+	get := func(headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/health", nil)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	get(nil)
+	rec := get(nil)
+	if calls != 1 {
+		t.Errorf("Second GET should be served from cache, handler ran %d times", calls)
+	}
+	if rec.Body.String() != `{"ok":true}` || rec.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("Cached response differs: %q %v", rec.Body.String(), rec.Header())
+	}
+
+	get(map[string]string{"Cache-Control": "no-cache"})
+	if calls != 2 {
+		t.Errorf("no-cache should bypass the cache, handler ran %d times", calls)
+	}
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/failover_notify", nil))
+	get(nil)
+	if calls != 4 {
+		t.Errorf("POST should invalidate the cache, handler ran %d times", calls)
+	}
+}
+
+func TestResponseCache_Disabled(t *testing.T) {
+	cache := newResponseCache(0)
+	calls := 0
+	handler := cache.wrap(func(w http.ResponseWriter, r *http.Request) { calls++ })
+
+	for i := 0; i < 3; i++ {
+		handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+	}
+	if calls != 3 {
+		t.Errorf("Disabled cache should not serve cached responses, handler ran %d times", calls)
+	}
+}
@@ -0,0 +1,90 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/aldebaranode/syncguard/internal/communication"
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/crypto"
+	"github.com/aldebaranode/syncguard/internal/health"
+	"github.com/aldebaranode/syncguard/internal/peerproto"
+	"github.com/aldebaranode/syncguard/internal/state"
+)
+
+// fuzzNode is a passive, unhealthy node, so transition requests are parsed
+// but never carried out
+type fuzzNode struct{ loadNode }
+
+func (n *fuzzNode) IsActive() bool  { return false }
+func (n *fuzzNode) IsHealthy() bool { return false }
+
+// fuzzKeys accepts any key
+type fuzzKeys struct{}
+
+func (fuzzKeys) KeyToBytes() ([]byte, error)    { return []byte("sealed"), nil }
+func (fuzzKeys) KeyFromBytes(data []byte) error { return nil }
+func (fuzzKeys) DeleteKey() error               { return nil }
+
+// fuzzProber reaches no one
+type fuzzProber struct{}
+
+func (fuzzProber) Probe(addr string) error { return errors.New("unreachable") }
+
+// fuzzPaths are the peer API endpoints that read a request body
+var fuzzPaths = []string{
+	communication.PathHeartbeat,
+	communication.PathValidatorKey,
+	communication.PathFailoverNotify,
+	communication.PathFailbackNotify,
+	communication.PathHandshake,
+	communication.PathKeyHandoff + communication.HandoffPrepare,
+}
+
+// FuzzPeerHandlers posts arbitrary bodies, plain or gzip, as JSON or
+// protobuf, to every endpoint that reads one. A handler that panics is
+// answered with a 500 by the recovery middleware, so no body may get one.
+func FuzzPeerHandlers(f *testing.F) {
+	statePath := filepath.Join(f.TempDir(), "priv_validator_state.json")
+	cfg := &config.Config{}
+	cfg.Node.ID = "node-2"
+	cfg.PeerAPI.MaxRequestBytes = 64 * config.Kilobyte
+	cfg.Identity.MaxSkew = 30
+	cfg.Logging = config.LoggingConfig{Level: "error", File: "/dev/null"}
+	node := &fuzzNode{}
+	handler := NewServer(cfg, state.NewManager(statePath, ""), fuzzKeys{}, node, node, nil, nil,
+		crypto.NewSecretRing("secret", ""), fuzzProber{}, nil, node).Handler()
+
+	heartbeat, _ := communication.EncodeReport(true, health.Report{NodeID: "node-1", Healthy: true, Height: 100})
+	f.Add(uint8(0), true, false, heartbeat)
+	f.Add(uint8(0), false, false, []byte(`{"node_id":"node-1","height":100,"progress":{"role":"active"}}`))
+	f.Add(uint8(1), true, false, communication.EncodeKey(true, []byte("sealed")))
+	f.Add(uint8(2), true, false, communication.EncodeTransition(true, "failover", "drill"))
+	f.Add(uint8(3), true, false, peerproto.Marshal(&peerproto.Transition{Kind: "release"}))
+	f.Add(uint8(4), false, false, []byte(`{"node_id":"node-1","advertise_address":"203.0.113.7:8080"}`))
+	gzipped, _ := communication.Compress(bytes.Repeat([]byte("["), 1<<20))
+	f.Add(uint8(0), false, true, gzipped)
+
+	f.Fuzz(func(t *testing.T, path uint8, asProto, gzipped bool, body []byte) {
+		req := httptest.NewRequest(http.MethodPost, fuzzPaths[int(path)%len(fuzzPaths)], bytes.NewReader(body))
+		if asProto {
+			req.Header.Set("Content-Type", peerproto.ContentType)
+		} else {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if gzipped {
+			req.Header.Set("Content-Encoding", communication.EncodingGzip)
+		}
+		signRequest(req, body)
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code == http.StatusInternalServerError {
+			t.Fatalf("%s answered 500: %s", req.URL.Path, rec.Body.String())
+		}
+	})
+}
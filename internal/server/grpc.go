@@ -0,0 +1,161 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/aldebaranode/syncguard/internal/communication"
+	"github.com/aldebaranode/syncguard/internal/peerproto"
+)
+
+// watchInterval is how often a WatchState stream reads the validator
+// state, to send it on when it changed
+const watchInterval = 500 * time.Millisecond
+
+// watchMaxAge ends WatchState streams so the peer subscribes again with a
+// fresh signature: a peer whose key was revoked gets no state past it
+const watchMaxAge = 5 * time.Minute
+
+// peerServer answers the Peer gRPC service
+type peerServer interface {
+	call(ctx context.Context, call *peerproto.Call) (*peerproto.Reply, error)
+	watchState(stream grpc.ServerStream) error
+}
+
+var peerServiceDesc = grpc.ServiceDesc{
+	ServiceName: peerproto.ServiceName,
+	HandlerType: (*peerServer)(nil),
+	Methods: []grpc.MethodDesc{{
+		MethodName: "Call",
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+			var call peerproto.Call
+			if err := dec(&call); err != nil {
+				return nil, err
+			}
+			return srv.(peerServer).call(ctx, &call)
+		},
+	}},
+	Streams: []grpc.StreamDesc{{
+		StreamName:    "WatchState",
+		ServerStreams: true,
+		Handler: func(srv interface{}, stream grpc.ServerStream) error {
+			return srv.(peerServer).watchState(stream)
+		},
+	}},
+	Metadata: "internal/peerproto/peer.proto",
+}
+
+// peerService serves each call with the peer API handler, so
+// authentication, encodings and limits are the same as over HTTP
+type peerService struct {
+	server  *Server
+	handler http.Handler
+}
+
+// serveGRPC answers gRPC requests on the peer port with the Peer service
+// and leaves the rest to handler
+func (s *Server) serveGRPC(handler http.Handler) http.Handler {
+	grpcServer := grpc.NewServer(
+		grpc.ForceServerCodec(peerproto.Codec{}),
+		grpc.MaxRecvMsgSize(communication.GRPCMessageLimit(s.maxRequest)),
+	)
+	grpcServer.RegisterService(&peerServiceDesc, &peerService{server: s, handler: handler})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+			grpcServer.ServeHTTP(w, r)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// request rebuilds the peer API request a call carries
+func (p *peerService) request(ctx context.Context, call *peerproto.Call) (*http.Request, error) {
+	r, err := http.NewRequestWithContext(ctx, call.Method, call.Path, bytes.NewReader(call.Body))
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid call: %v", err)
+	}
+	r.RequestURI = call.Path
+	r.ContentLength = int64(len(call.Body))
+	if call.Header != nil {
+		r.Header = call.Header
+	}
+	if from, ok := peer.FromContext(ctx); ok {
+		r.RemoteAddr = from.Addr.String()
+		if info, ok := from.AuthInfo.(credentials.TLSInfo); ok {
+			r.TLS = &info.State
+		}
+	}
+	return r, nil
+}
+
+// serve runs r through handler and returns the answer as a reply
+func serve(handler http.Handler, r *http.Request) *peerproto.Reply {
+	buf := &bufferedResponse{header: make(http.Header), status: http.StatusOK}
+	handler.ServeHTTP(buf, r)
+	return &peerproto.Reply{Status: int32(buf.status), Header: buf.header, Body: buf.body.Bytes()}
+}
+
+// call serves one peer API request
+func (p *peerService) call(ctx context.Context, call *peerproto.Call) (*peerproto.Reply, error) {
+	r, err := p.request(ctx, call)
+	if err != nil {
+		return nil, err
+	}
+	return serve(p.handler, r), nil
+}
+
+// watchState answers the GET /validator_state a stream opens with, then
+// sends the state again each time it changes. Only the first answer goes
+// through authentication; the stream ends after watchMaxAge.
+func (p *peerService) watchState(stream grpc.ServerStream) error {
+	var call peerproto.Call
+	if err := stream.RecvMsg(&call); err != nil {
+		return err
+	}
+	if call.Method != http.MethodGet || call.Path != communication.PathValidatorState {
+		return status.Errorf(codes.InvalidArgument, "WatchState takes GET %s, not %s %s",
+			communication.PathValidatorState, call.Method, call.Path)
+	}
+	r, err := p.request(stream.Context(), &call)
+	if err != nil {
+		return err
+	}
+
+	reply := serve(p.handler, r)
+	if err := stream.SendMsg(reply); err != nil || reply.Status != http.StatusOK {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(stream.Context(), watchMaxAge)
+	defer cancel()
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+	last := reply.Body
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		reply := serve(http.HandlerFunc(p.server.handleValidatorState), r)
+		if reply.Status == http.StatusOK && bytes.Equal(reply.Body, last) {
+			continue
+		}
+		if err := stream.SendMsg(reply); err != nil || reply.Status != http.StatusOK {
+			return err
+		}
+		last = reply.Body
+	}
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/communication"
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/crypto"
+	"github.com/aldebaranode/syncguard/internal/state"
+)
+
+func TestGRPC_CallAndWatchState(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Node.ID = "node-1"
+	cfg.Secret = "secret"
+	cfg.Identity.MaxSkew = 30
+	cfg.PeerAPI.Protocol = communication.ProtocolGRPC
+	cfg.Logging = config.LoggingConfig{Level: "error", File: "/dev/null"}
+	node := &loadNode{height: 1}
+	states := state.NewManager(filepath.Join(t.TempDir(), "priv_validator_state.json"), "")
+	if err := states.SaveState(&state.ValidatorState{Height: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewUnstartedServer(NewServer(cfg, states, nil, node, node, nil, nil,
+		crypto.NewSecretRing("secret", ""), nil, nil, node).Handler())
+	srv.Config.Protocols = new(http.Protocols)
+	srv.Config.Protocols.SetHTTP1(true)
+	srv.Config.Protocols.SetUnencryptedHTTP2(true)
+	srv.Start()
+	defer srv.Close()
+
+	client := communication.NewClient(cfg, nil)
+	remote, _, err := client.FetchState(srv.URL, true)
+	if err != nil {
+		t.Fatalf("FetchState over gRPC failed: %v", err)
+	}
+	if remote.Height != 1 {
+		t.Errorf("height = %d, want 1", remote.Height)
+	}
+
+	wrong := *cfg
+	wrong.Secret = "other-secret"
+	if _, _, err := communication.NewClient(&wrong, nil).FetchState(srv.URL, true); communication.StatusCode(err) != http.StatusUnauthorized {
+		t.Errorf("wrong secret: got %v, want 401", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var heights []int64
+	done := errors.New("done")
+	err = client.WatchState(ctx, srv.URL, func(s *state.ValidatorState, link *state.Link) error {
+		heights = append(heights, s.Height)
+		if link == nil {
+			t.Error("streamed state lacks its provenance link")
+		}
+		if len(heights) == 1 {
+			return states.SaveState(&state.ValidatorState{Height: 2})
+		}
+		return done
+	})
+	if !errors.Is(err, done) {
+		t.Fatalf("WatchState = %v", err)
+	}
+	if len(heights) != 2 || heights[0] != 1 || heights[1] != 2 {
+		t.Errorf("streamed heights = %v, want [1 2]", heights)
+	}
+}
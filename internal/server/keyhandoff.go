@@ -0,0 +1,171 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/communication"
+	"github.com/aldebaranode/syncguard/internal/crypto"
+	"github.com/aldebaranode/syncguard/internal/state"
+)
+
+// stagedKeyTTL is how long a prepared key waits for its commit
+const stagedKeyTTL = 5 * time.Minute
+
+// keyHandoffState is the one key handoff a standby takes part in. A new
+// prepare replaces a handoff that never committed.
+type keyHandoffState struct {
+	mu        sync.Mutex
+	id        string
+	key       []byte
+	address   string
+	staged    time.Time
+	committed bool
+}
+
+// drop forgets the handoff and wipes the staged key. Callers hold mu.
+func (h *keyHandoffState) drop() {
+	for i := range h.key {
+		h.key[i] = 0
+	}
+	h.id, h.key, h.address, h.staged, h.committed = "", nil, "", time.Time{}, false
+}
+
+// handleKeyHandoff runs one phase of a two-phase key handoff from the
+// releasing peer. An active node refuses to prepare: it would end up
+// with two nodes holding the key.
+func (s *Server) handleKeyHandoff(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	phase := strings.TrimPrefix(r.URL.Path, communication.PathKeyHandoff)
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+	req, err := communication.DecodeKeyHandoff(body)
+	if err != nil {
+		s.logger.Warn("Rejected key handoff %s: %v", phase, err)
+		http.Error(w, "Invalid key handoff: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.handoff.mu.Lock()
+	defer s.handoff.mu.Unlock()
+
+	var ack communication.KeyHandoffAck
+	switch phase {
+	case communication.HandoffPrepare:
+		ack, err = s.prepareKeyHandoff(req, r.Header.Get(crypto.HeaderNodeID))
+	case communication.HandoffCommit:
+		ack, err = s.commitKeyHandoff(req)
+	case communication.HandoffAbort:
+		ack, err = s.abortKeyHandoff(req)
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	var herr *handoffError
+	if errors.As(err, &herr) {
+		s.logger.Warn("Key handoff %s %s failed: %s", req.ID, phase, herr.message)
+		http.Error(w, herr.message, herr.code)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ack)
+}
+
+// prepareKeyHandoff unseals the key and stages it, checking it arrived
+// whole. A takeover the guard vetoes is refused here, while the peer from
+// still signs and can stay active. Callers hold s.handoff.mu.
+func (s *Server) prepareKeyHandoff(req communication.KeyHandoff, from string) (communication.KeyHandoffAck, error) {
+	if s.nodeStatus.IsActive() {
+		return communication.KeyHandoffAck{}, &handoffError{http.StatusConflict, "this node is active"}
+	}
+	keyData, err := s.secrets.Decrypt(req.SealedKey)
+	if err != nil {
+		return communication.KeyHandoffAck{}, &handoffError{http.StatusBadRequest, "failed to unseal key"}
+	}
+	digest := communication.KeyDigest(keyData)
+	if digest != req.Digest {
+		return communication.KeyHandoffAck{}, &handoffError{http.StatusBadRequest, "key digest does not match"}
+	}
+	var key state.ValidatorKey
+	if err := json.Unmarshal(keyData, &key); err != nil || key.Address == "" || state.IsMockKey(&key) {
+		return communication.KeyHandoffAck{}, &handoffError{http.StatusBadRequest, "not a validator key"}
+	}
+	if s.guard != nil {
+		if err := s.guard.CheckActivation(from); err != nil {
+			return communication.KeyHandoffAck{}, &handoffError{http.StatusServiceUnavailable, "refusing takeover: " + err.Error()}
+		}
+	}
+
+	s.handoff.drop()
+	s.handoff.id, s.handoff.key, s.handoff.address, s.handoff.staged = req.ID, keyData, key.Address, time.Now()
+	s.logger.Info("Staged validator key %s for handoff %s", key.Address, req.ID)
+	return communication.KeyHandoffAck{ID: req.ID, Digest: digest}, nil
+}
+
+// commitKeyHandoff writes the staged key in place and reads it back.
+// Callers hold s.handoff.mu.
+func (s *Server) commitKeyHandoff(req communication.KeyHandoff) (communication.KeyHandoffAck, error) {
+	if s.handoff.id != req.ID {
+		return communication.KeyHandoffAck{}, &handoffError{http.StatusNotFound, "no such key handoff"}
+	}
+	if !s.handoff.committed {
+		if time.Since(s.handoff.staged) > stagedKeyTTL {
+			s.handoff.drop()
+			return communication.KeyHandoffAck{}, &handoffError{http.StatusGone, "staged key expired"}
+		}
+		if err := s.keyProvider.KeyFromBytes(s.handoff.key); err != nil {
+			s.logger.Error("Failed to write handed over key: %v", err)
+			return communication.KeyHandoffAck{}, &handoffError{http.StatusInternalServerError, "failed to write key"}
+		}
+		s.handoff.committed = true
+	}
+
+	written, err := s.keyProvider.KeyToBytes()
+	var key state.ValidatorKey
+	if err == nil {
+		err = json.Unmarshal(written, &key)
+	}
+	if err != nil || key.Address != s.handoff.address {
+		s.logger.Error("Handed over key did not read back: %v", err)
+		return communication.KeyHandoffAck{}, &handoffError{http.StatusInternalServerError, "key did not read back"}
+	}
+	s.logger.Info("Wrote handed over validator key %s (handoff %s)", key.Address, req.ID)
+	return communication.KeyHandoffAck{ID: req.ID, Written: true, Address: key.Address}, nil
+}
+
+// abortKeyHandoff drops the staged key, and swaps a committed one back
+// for the mock key. Aborting an unknown handoff succeeds, so the sender
+// can retry. Callers hold s.handoff.mu.
+func (s *Server) abortKeyHandoff(req communication.KeyHandoff) (communication.KeyHandoffAck, error) {
+	if s.handoff.id == req.ID {
+		if s.handoff.committed && !s.nodeStatus.IsActive() {
+			if err := s.keyProvider.DeleteKey(); err != nil {
+				s.logger.Error("Failed to swap back to the mock key: %v", err)
+				return communication.KeyHandoffAck{}, &handoffError{http.StatusInternalServerError, "failed to disable key"}
+			}
+		}
+		s.handoff.drop()
+		s.logger.Warn("Key handoff %s aborted by peer", req.ID)
+	}
+	return communication.KeyHandoffAck{ID: req.ID}, nil
+}
+
+// handoffError is a failed handoff phase and the status answered for it
+type handoffError struct {
+	code    int
+	message string
+}
+
+func (e *handoffError) Error() string { return e.message }
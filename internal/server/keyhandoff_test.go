@@ -0,0 +1,175 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/aldebaranode/syncguard/internal/communication"
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/crypto"
+	"github.com/aldebaranode/syncguard/internal/logger"
+	"github.com/aldebaranode/syncguard/internal/state"
+)
+
+// passiveNode is a standby, which may take the key
+type passiveNode struct{ loadNode }
+
+func (n *passiveNode) IsActive() bool { return false }
+
+func TestKeyHandoff(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Node.ID = "standby"
+	cfg.Secret = "secret"
+	cfg.Identity.MaxSkew = 30
+	cfg.Logging = config.LoggingConfig{Level: "error", File: "/dev/null"}
+	keys := state.NewKeyManager(filepath.Join(t.TempDir(), "priv_validator_key.json"), "", logger.New(cfg, "test"))
+	if err := keys.SaveKey(&state.ValidatorKey{Address: "0000000000000000000000000000000000000000"}); err != nil {
+		t.Fatal(err)
+	}
+	node := &passiveNode{}
+	srv := httptest.NewServer(NewServer(cfg, nil, keys, node, node, nil, nil,
+		crypto.NewSecretRing("secret", ""), nil, nil, node).Handler())
+	defer srv.Close()
+	client := communication.NewClient(cfg, nil)
+
+	keyData, _ := json.Marshal(state.ValidatorKey{Address: "ABCD", PubKey: json.RawMessage(`{}`), PrivKey: json.RawMessage(`{}`)})
+	sealed, err := crypto.Encrypt(keyData, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := communication.KeyDigest(keyData)
+
+	// A key that arrives damaged is not staged
+	if _, err := client.PrepareKeyHandoff(srv.URL, communication.KeyHandoff{ID: "t1", SealedKey: sealed, Digest: "beef"}); communication.StatusCode(err) != http.StatusBadRequest {
+		t.Fatalf("prepare with a wrong digest = %v, want 400", err)
+	}
+	if _, err := client.CommitKeyHandoff(srv.URL, "t1"); communication.StatusCode(err) != http.StatusNotFound {
+		t.Fatalf("commit without prepare = %v, want 404", err)
+	}
+
+	ack, err := client.PrepareKeyHandoff(srv.URL, communication.KeyHandoff{ID: "t1", SealedKey: sealed, Digest: digest})
+	if err != nil || ack.Digest != digest {
+		t.Fatalf("prepare = %+v, %v", ack, err)
+	}
+	if key, _ := keys.LoadKey(); key.Address == "ABCD" {
+		t.Fatal("prepare wrote the key")
+	}
+	ack, err = client.CommitKeyHandoff(srv.URL, "t1")
+	if err != nil || !ack.Written || ack.Address != "ABCD" {
+		t.Fatalf("commit = %+v, %v", ack, err)
+	}
+	if key, _ := keys.LoadKey(); key.Address != "ABCD" {
+		t.Fatalf("key on disk is %s after commit", key.Address)
+	}
+
+	// Aborting after the commit swaps the mock key back in
+	if err := client.AbortKeyHandoff(srv.URL, "t1"); err != nil {
+		t.Fatal(err)
+	}
+	if !keys.IsDisabled() {
+		t.Error("key still enabled after an aborted handoff")
+	}
+}
+
+func TestValidatorKey_Sealed(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Node.ID = "standby"
+	cfg.Secret = "secret"
+	cfg.Identity.MaxSkew = 30
+	cfg.Logging = config.LoggingConfig{Level: "error", File: "/dev/null"}
+	keys := state.NewKeyManager(filepath.Join(t.TempDir(), "priv_validator_key.json"), "", logger.New(cfg, "test"))
+	node := &passiveNode{}
+	newServer := func(allowPlaintext bool) *httptest.Server {
+		cfg := *cfg
+		cfg.Failover.AllowPlaintextKey = allowPlaintext
+		return httptest.NewServer(NewServer(&cfg, nil, keys, node, node, nil, nil,
+			crypto.NewSecretRing("secret", ""), nil, nil, node).Handler())
+	}
+	client := communication.NewClient(cfg, nil)
+
+	keyData, _ := json.Marshal(state.ValidatorKey{Address: "ABCD", PubKey: json.RawMessage(`{}`), PrivKey: json.RawMessage(`{}`)})
+	sealed, err := crypto.Encrypt(keyData, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := newServer(false)
+	defer srv.Close()
+	if err := client.SendKey(srv.URL, keyData); communication.StatusCode(err) != http.StatusBadRequest {
+		t.Fatalf("plaintext key = %v, want 400", err)
+	}
+	if err := client.SendKey(srv.URL, sealed); err != nil {
+		t.Fatalf("sealed key: %v", err)
+	}
+	if key, _ := keys.LoadKey(); key.Address != "ABCD" {
+		t.Fatalf("key on disk is %s", key.Address)
+	}
+
+	// The key is served sealed too
+	body, err := client.FetchKey(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := crypto.Decrypt(body, "secret")
+	if err != nil {
+		t.Fatalf("fetched key is not sealed: %v", err)
+	}
+	var fetched state.ValidatorKey
+	if err := json.Unmarshal(plain, &fetched); err != nil || fetched.Address != "ABCD" {
+		t.Errorf("fetched key = %+v, %v", fetched, err)
+	}
+
+	lenient := newServer(true)
+	defer lenient.Close()
+	if err := client.SendKey(lenient.URL, keyData); err != nil {
+		t.Errorf("plaintext key with allow_plaintext_key: %v", err)
+	}
+}
+
+// vetoGuard refuses every takeover, recording who handed over
+type vetoGuard struct{ from []string }
+
+func (g *vetoGuard) CheckActivation(from string) error {
+	g.from = append(g.from, from)
+	return errors.New("host under maintenance")
+}
+
+func TestKeyHandoff_Vetoed(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Node.ID = "standby"
+	cfg.Secret = "secret"
+	cfg.Identity.MaxSkew = 30
+	cfg.Logging = config.LoggingConfig{Level: "error", File: "/dev/null"}
+	keys := state.NewKeyManager(filepath.Join(t.TempDir(), "priv_validator_key.json"), "", logger.New(cfg, "test"))
+	node := &passiveNode{}
+	s := NewServer(cfg, nil, keys, node, node, nil, nil, crypto.NewSecretRing("secret", ""), nil, nil, node)
+	guard := &vetoGuard{}
+	s.SetActivationGuard(guard)
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+	activeCfg := *cfg
+	activeCfg.Node.ID = "active"
+	client := communication.NewClient(&activeCfg, nil)
+
+	keyData, _ := json.Marshal(state.ValidatorKey{Address: "ABCD", PubKey: json.RawMessage(`{}`), PrivKey: json.RawMessage(`{}`)})
+	sealed, err := crypto.Encrypt(keyData, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The veto comes before the active node gives anything up
+	_, err = client.PrepareKeyHandoff(srv.URL, communication.KeyHandoff{ID: "t1", SealedKey: sealed, Digest: communication.KeyDigest(keyData)})
+	if communication.StatusCode(err) != http.StatusServiceUnavailable {
+		t.Fatalf("vetoed prepare = %v, want 503", err)
+	}
+	if len(guard.from) != 1 || guard.from[0] != "active" {
+		t.Errorf("guard asked about handovers from %v, want [active]", guard.from)
+	}
+	if _, err := client.CommitKeyHandoff(srv.URL, "t1"); communication.StatusCode(err) != http.StatusNotFound {
+		t.Errorf("commit after a vetoed prepare = %v, want 404", err)
+	}
+}
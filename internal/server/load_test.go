@@ -0,0 +1,323 @@
+package server
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/communication"
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/crypto"
+	"github.com/aldebaranode/syncguard/internal/health"
+	"github.com/aldebaranode/syncguard/internal/state"
+)
+
+// loadNode stands in for the failover manager. Its lock is the one the
+// health loop holds while it checks, so requests that read node status
+// wait on it the way they do in production.
+type loadNode struct {
+	mu     sync.RWMutex
+	height int64
+}
+
+func (n *loadNode) IsHealthy() bool { return true }
+
+func (n *loadNode) GetLastHeight() int64 {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.height
+}
+
+func (n *loadNode) IsActive() bool                       { return true }
+func (n *loadNode) IsPrimary() bool                      { return true }
+func (n *loadNode) SetActive(active bool, reason string) {}
+func (n *loadNode) Readiness() *health.Readiness         { return nil }
+func (n *loadNode) PeerProgress() *health.Progress       { return nil }
+func (n *loadNode) PeerTransitions() *health.Transitions { return nil }
+func (n *loadNode) IsDraining() bool                     { return false }
+func (n *loadNode) KeyCustody() communication.KeyCustody { return communication.KeyCustody{} }
+
+func (n *loadNode) TransitionSummary() *health.Transitions {
+	return &health.Transitions{Epoch: 1, Reasons: map[string]int{"manual": 1}}
+}
+
+func (n *loadNode) Progress() *health.Progress {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return &health.Progress{Role: "active", FailoverAfter: 3}
+}
+
+func (n *loadNode) RunTransition(kind, reason string, run func() error) error { return run() }
+
+func (n *loadNode) ReceiveHeartbeat(report health.Report) communication.HeartbeatAck {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return communication.HeartbeatAck{NodeID: "node-2", Healthy: true, Height: n.height}
+}
+
+// check is one health check: it holds the node lock like the health loop
+func (n *loadNode) check() {
+	n.mu.Lock()
+	n.height++
+	n.mu.Unlock()
+}
+
+// loadFixture is a peer server on a real state file with the background
+// work that competes with it: the signer rewriting the state file and the
+// health and state sync loops
+type loadFixture struct {
+	server  *Server
+	states  *state.Manager
+	node    *loadNode
+	stop    chan struct{}
+	stopped sync.WaitGroup
+}
+
+func newLoadFixture(tb testing.TB) *loadFixture {
+	tb.Helper()
+	statePath := filepath.Join(tb.TempDir(), "priv_validator_state.json")
+	states := state.NewManager(statePath, "")
+	if err := states.SaveState(&state.ValidatorState{Height: 1}); err != nil {
+		tb.Fatalf("Failed to write state: %v", err)
+	}
+
+	cfg := &config.Config{}
+	cfg.Node.ID = "node-1"
+	cfg.PeerAPI.CacheTTL = 1
+	cfg.Identity.MaxSkew = 30
+	node := &loadNode{height: 1}
+	f := &loadFixture{
+		server: NewServer(cfg, states, nil, node, node, nil, nil,
+			crypto.NewSecretRing("secret", ""), nil, nil, node),
+		states: states,
+		node:   node,
+		stop:   make(chan struct{}),
+	}
+	tb.Cleanup(f.close)
+	return f
+}
+
+// background starts the loops that contend with the serving path
+func (f *loadFixture) background() {
+	every := func(interval time.Duration, fn func()) {
+		f.stopped.Add(1)
+		go func() {
+			defer f.stopped.Done()
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					fn()
+				case <-f.stop:
+					return
+				}
+			}
+		}()
+	}
+
+	// The signer writes the state file on every step of every block
+	height := int64(1)
+	every(5*time.Millisecond, func() {
+		height++
+		f.states.SaveState(&state.ValidatorState{Height: height, Step: 3})
+	})
+	every(50*time.Millisecond, func() { f.states.LoadState() })
+	every(100*time.Millisecond, f.node.check)
+}
+
+func (f *loadFixture) close() {
+	select {
+	case <-f.stop:
+	default:
+		close(f.stop)
+	}
+	f.stopped.Wait()
+}
+
+// loadTarget is one kind of request, sent by clients at a fixed rate each
+type loadTarget struct {
+	name    string
+	clients int
+	every   time.Duration
+	request func(base string) *http.Request
+	// slo is the 99th percentile latency the target must stay under
+	slo time.Duration
+}
+
+// loadResult holds the latencies and failures seen for a target
+type loadResult struct {
+	mu        sync.Mutex
+	latencies []time.Duration
+	failures  int
+}
+
+func (r *loadResult) add(latency time.Duration, ok bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.latencies = append(r.latencies, latency)
+	if !ok {
+		r.failures++
+	}
+}
+
+// percentile returns the latency under which p of the requests finished
+func (r *loadResult) percentile(p float64) time.Duration {
+	if len(r.latencies) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), r.latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[int(float64(len(sorted)-1)*p)]
+}
+
+// runLoad sends every target's requests to base for d
+func runLoad(base string, targets []loadTarget, d time.Duration) map[string]*loadResult {
+	results := make(map[string]*loadResult)
+	deadline := time.Now().Add(d)
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	var wg sync.WaitGroup
+	for _, target := range targets {
+		result := &loadResult{}
+		results[target.name] = result
+		for i := 0; i < target.clients; i++ {
+			wg.Add(1)
+			go func(target loadTarget) {
+				defer wg.Done()
+				ticker := time.NewTicker(target.every)
+				defer ticker.Stop()
+				for time.Now().Before(deadline) {
+					start := time.Now()
+					resp, err := client.Do(target.request(base))
+					ok := err == nil && resp.StatusCode == http.StatusOK
+					if err == nil {
+						io.Copy(io.Discard, resp.Body)
+						resp.Body.Close()
+					}
+					result.add(time.Since(start), ok)
+					<-ticker.C
+				}
+			}(target)
+		}
+	}
+	wg.Wait()
+	return results
+}
+
+func getRequest(path string, headers ...string) func(base string) *http.Request {
+	return func(base string) *http.Request {
+		req, _ := http.NewRequest(http.MethodGet, base+path, nil)
+		for i := 0; i+1 < len(headers); i += 2 {
+			req.Header.Set(headers[i], headers[i+1])
+		}
+		return signRequest(req, nil)
+	}
+}
+
+// signRequest signs req the way a peer without an identity does
+func signRequest(req *http.Request, body []byte) *http.Request {
+	for k, v := range crypto.SignRequestHMAC("secret", "node-2", req.Method, req.URL.Path, body, time.Now().Unix()) {
+		req.Header.Set(k, v)
+	}
+	return req
+}
+
+// heartbeatRequest sends reports stamped like the real ones, so no two
+// are the same request to the replay guard
+func heartbeatRequest(tb testing.TB) func(base string) *http.Request {
+	return func(base string) *http.Request {
+		body, err := communication.EncodeReport(false, health.Report{NodeID: "node-1", Healthy: true, Height: 100, Time: time.Now().UTC()})
+		if err != nil {
+			tb.Fatalf("Failed to encode heartbeat: %v", err)
+		}
+		req, _ := http.NewRequest(http.MethodPost, base+communication.PathHeartbeat, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		return signRequest(req, body)
+	}
+}
+
+// TestPeerServer_Load serves a dashboard and several peers at their usual
+// rates while the state file is rewritten and the loops run, and checks
+// that no endpoint leaves its latency budget
+func TestPeerServer_Load(t *testing.T) {
+	if testing.Short() {
+		t.Skip("load test skipped in short mode")
+	}
+
+	f := newLoadFixture(t)
+	f.background()
+	ts := httptest.NewServer(f.server.Handler())
+	defer ts.Close()
+
+	targets := []loadTarget{
+		// Dashboards and monitoring poll status, mostly from cache
+		{name: "health", clients: 4, every: 50 * time.Millisecond,
+			request: getRequest(communication.PathHealth), slo: 100 * time.Millisecond},
+		// Passive peers sync state; during transitions they bypass the cache
+		{name: "validator_state", clients: 3, every: 100 * time.Millisecond,
+			request: getRequest(communication.PathValidatorState), slo: 100 * time.Millisecond},
+		{name: "validator_state_fresh", clients: 3, every: 100 * time.Millisecond,
+			request: getRequest(communication.PathValidatorState, "Cache-Control", "no-cache"), slo: 100 * time.Millisecond},
+		{name: "heartbeat", clients: 3, every: 100 * time.Millisecond,
+			request: heartbeatRequest(t), slo: 100 * time.Millisecond},
+	}
+	results := runLoad(ts.URL, targets, 2*time.Second)
+
+	for _, target := range targets {
+		result := results[target.name]
+		p50, p99 := result.percentile(0.5), result.percentile(0.99)
+		t.Logf("%s: %d requests, p50 %s, p99 %s", target.name, len(result.latencies), p50, p99)
+		if result.failures > 0 {
+			t.Errorf("%s: %d of %d requests failed", target.name, result.failures, len(result.latencies))
+		}
+		if len(result.latencies) == 0 {
+			t.Errorf("%s: no requests completed", target.name)
+		}
+		if p99 > target.slo {
+			t.Errorf("%s: p99 latency %s exceeds %s", target.name, p99, target.slo)
+		}
+	}
+}
+
+// BenchmarkPeerServer measures the serving path of each endpoint under
+// parallel load, with the background loops running
+func BenchmarkPeerServer(b *testing.B) {
+	benchmarks := []struct {
+		name    string
+		request func(base string) *http.Request
+	}{
+		{"health", getRequest(communication.PathHealth)},
+		{"health_uncached", getRequest(communication.PathHealth, "Cache-Control", "no-cache")},
+		{"validator_state", getRequest(communication.PathValidatorState)},
+		{"validator_state_uncached", getRequest(communication.PathValidatorState, "Cache-Control", "no-cache")},
+		{"heartbeat", heartbeatRequest(b)},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			f := newLoadFixture(b)
+			f.background()
+			handler := f.server.Handler()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					rec := httptest.NewRecorder()
+					handler.ServeHTTP(rec, bm.request(""))
+					if rec.Code != http.StatusOK {
+						b.Errorf("%s answered %d", bm.name, rec.Code)
+						return
+					}
+				}
+			})
+		})
+	}
+}
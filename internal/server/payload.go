@@ -0,0 +1,100 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/aldebaranode/syncguard/internal/communication"
+	"github.com/aldebaranode/syncguard/internal/config"
+)
+
+// payloadCodec decodes gzip request bodies, refuses oversized ones and
+// gzips responses for clients that accept it. It wraps the whole peer API,
+// so authentication and the response cache see plain bodies.
+type payloadCodec struct {
+	compress    bool
+	compressMin int
+	maxRequest  int64
+}
+
+// newPayloadCodec creates a codec from the peer API configuration
+func newPayloadCodec(cfg config.PeerAPIConfig) *payloadCodec {
+	return &payloadCodec{
+		compress:    cfg.Compression,
+		compressMin: int(cfg.CompressMinBytes),
+		maxRequest:  int64(cfg.MaxRequestBytes),
+	}
+}
+
+// wrap applies the codec to every request
+func (p *payloadCodec) wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Body != nil && r.Body != http.NoBody {
+			body, _, err := communication.ReadBody(r.Body, r.Header.Get("Content-Encoding"), p.maxRequest)
+			r.Body.Close()
+			if errors.Is(err, communication.ErrPayloadTooLarge) {
+				http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			if err != nil {
+				http.Error(w, "Invalid request body", http.StatusBadRequest)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			r.ContentLength = int64(len(body))
+			r.Header.Del("Content-Encoding")
+		}
+
+		if !p.compress || !acceptsGzip(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		buf := &bufferedResponse{header: w.Header(), status: http.StatusOK}
+		next.ServeHTTP(buf, r)
+
+		w.Header().Add("Vary", "Accept-Encoding")
+		body := buf.body.Bytes()
+		if w.Header().Get("Content-Type") == "" && len(body) > 0 {
+			// Sniff before compressing, or the type would be gzip
+			w.Header().Set("Content-Type", http.DetectContentType(body))
+		}
+		if len(body) >= p.compressMin && w.Header().Get("Content-Encoding") == "" {
+			if compressed, err := communication.Compress(body); err == nil {
+				w.Header().Set("Content-Encoding", communication.EncodingGzip)
+				body = compressed
+			}
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		w.WriteHeader(buf.status)
+		w.Write(body)
+	})
+}
+
+// acceptsGzip reports whether the client accepts gzip responses
+func acceptsGzip(r *http.Request) bool {
+	for _, enc := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		if strings.TrimSpace(strings.SplitN(enc, ";", 2)[0]) == communication.EncodingGzip {
+			return true
+		}
+	}
+	return false
+}
+
+// bufferedResponse holds a response until it can be compressed, or sent
+// as a gRPC reply
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() http.Header { return b.header }
+
+func (b *bufferedResponse) WriteHeader(status int) { b.status = status }
+
+func (b *bufferedResponse) Write(p []byte) (int, error) { return b.body.Write(p) }
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aldebaranode/syncguard/internal/communication"
+	"github.com/aldebaranode/syncguard/internal/config"
+)
+
+func TestPayloadCodec(t *testing.T) {
+	codec := newPayloadCodec(config.PeerAPIConfig{Compression: true, CompressMinBytes: 64, MaxRequestBytes: 1024})
+	var received string
+	handler := codec.wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(strings.Repeat("x", 200)))
+	}))
+
+	post := func(body []byte, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/validator_key", bytes.NewReader(body))
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Gzip request bodies reach the handler decoded
+	compressed, _ := communication.Compress([]byte(`{"key":"abc"}`))
+	rec := post(compressed, map[string]string{"Content-Encoding": "gzip", "Accept-Encoding": "gzip"})
+	if received != `{"key":"abc"}` {
+		t.Errorf("handler received %q", received)
+	}
+	if rec.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatal("expected a gzip response for a client that accepts it")
+	}
+	body, _, err := communication.ReadBody(rec.Body, "gzip", 0)
+	if err != nil || string(body) != strings.Repeat("x", 200) {
+		t.Errorf("response did not decode: %v", err)
+	}
+
+	// Plain clients get plain responses
+	if rec := post(nil, nil); rec.Header().Get("Content-Encoding") != "" || rec.Body.Len() != 200 {
+		t.Errorf("expected an uncompressed response, got %v", rec.Header())
+	}
+
+	// Oversized bodies are refused, before or after decompression
+	if rec := post(bytes.Repeat([]byte("a"), 2048), nil); rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected 413 for a large body, got %d", rec.Code)
+	}
+	bomb, _ := communication.Compress(bytes.Repeat([]byte("a"), 64*1024))
+	if rec := post(bomb, map[string]string{"Content-Encoding": "gzip"}); rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected 413 for a body that inflates past the limit, got %d", rec.Code)
+	}
+}
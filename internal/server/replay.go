@@ -0,0 +1,63 @@
+package server
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/crypto"
+)
+
+// replayGuard remembers the signatures of the write requests accepted
+// while their timestamps are within the skew window, so a captured
+// /failover_notify or /failback_notify cannot be sent again in that time.
+// Reads are left alone: the same GET signed twice in one second is no
+// threat, and signatures only change by the second.
+type replayGuard struct {
+	mu      sync.Mutex
+	maxSkew time.Duration
+	// seen maps a signature to when its timestamp falls out of the window
+	seen  map[string]time.Time
+	swept time.Time
+}
+
+func newReplayGuard(maxSkew time.Duration) *replayGuard {
+	return &replayGuard{maxSkew: maxSkew, seen: make(map[string]time.Time)}
+}
+
+// fresh records the signature of a verified request and reports whether
+// it was not accepted before
+func (g *replayGuard) fresh(r *http.Request, timestamp int64) bool {
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return true
+	}
+	signature := r.Header.Get(crypto.HeaderSignature)
+	now := time.Now()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if now.Sub(g.swept) > g.maxSkew {
+		for sig, expires := range g.seen {
+			if now.After(expires) {
+				delete(g.seen, sig)
+			}
+		}
+		g.swept = now
+	}
+	if expires, ok := g.seen[signature]; ok && !now.After(expires) {
+		return false
+	}
+	g.seen[signature] = time.Unix(timestamp, 0).Add(g.maxSkew)
+	return true
+}
+
+// unreplayed passes r on to next unless it repeats a write request
+// already accepted
+func (s *Server) unreplayed(w http.ResponseWriter, r *http.Request, timestamp int64, next http.HandlerFunc) {
+	if !s.replays.fresh(r, timestamp) {
+		s.logger.Warn("Rejected replayed %s %s from %q", r.Method, r.URL.Path, r.Header.Get(crypto.HeaderNodeID))
+		http.Error(w, "Replayed request", http.StatusConflict)
+		return
+	}
+	next(w, r)
+}
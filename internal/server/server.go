@@ -1,14 +1,29 @@
 package server
 
 import (
+	"bytes"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/aldebaranode/syncguard/internal/communication"
 	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/crypto"
+	"github.com/aldebaranode/syncguard/internal/deprecation"
+	"github.com/aldebaranode/syncguard/internal/election"
+	"github.com/aldebaranode/syncguard/internal/health"
 	"github.com/aldebaranode/syncguard/internal/logger"
+	"github.com/aldebaranode/syncguard/internal/metrics"
+	"github.com/aldebaranode/syncguard/internal/peerproto"
 	"github.com/aldebaranode/syncguard/internal/state"
+	"github.com/aldebaranode/syncguard/internal/supervise"
+	"github.com/aldebaranode/syncguard/internal/transition"
 )
 
 // StateProvider provides access to validator state
@@ -35,7 +50,58 @@ type HealthProvider interface {
 type NodeStatusProvider interface {
 	IsActive() bool
 	IsPrimary() bool
-	SetActive(active bool)
+	// SetActive applies a role change the peer requested; peerReason is
+	// the reason code the peer sent
+	SetActive(active bool, peerReason string)
+	// Readiness scores a passive node's readiness to take over; nil while
+	// active
+	Readiness() *health.Readiness
+	// Progress is this node's failover countdown
+	Progress() *health.Progress
+	// PeerProgress is the peer's countdown as exchanged over heartbeats;
+	// nil without heartbeats
+	PeerProgress() *health.Progress
+	// TransitionSummary sums up this node's changes of role
+	TransitionSummary() *health.Transitions
+	// PeerTransitions is the peer's summary as exchanged over heartbeats;
+	// nil without heartbeats
+	PeerTransitions() *health.Transitions
+	// IsDraining reports whether an operator drained the node
+	IsDraining() bool
+	// KeyCustody reports whether the node holds the real key and the lock
+	KeyCustody() communication.KeyCustody
+	// RunTransition carries out a peer-requested transition of kind
+	// (transition.KindAcquire or KindRelease) on the node's transition
+	// executor, serialized with its own
+	RunTransition(kind, reason string, run func() error) error
+}
+
+// HeartbeatReceiver takes the active peer's health reports
+type HeartbeatReceiver interface {
+	ReceiveHeartbeat(report health.Report) communication.HeartbeatAck
+}
+
+// PeerProber checks whether a peer address answers
+type PeerProber interface {
+	Probe(addr string) error
+}
+
+// LeaseVoter answers election lease requests from peers
+type LeaseVoter interface {
+	Vote(req election.Request) election.Response
+}
+
+// ActivationGuard vetoes a takeover: a maintenance flag on this host, a
+// witness that does not agree, or signatures on chain or in this node's
+// records the local validator state is missing. from names the peer
+// handing over, when it is known.
+type ActivationGuard interface {
+	CheckActivation(from string) error
+}
+
+// NodeStatusSource reads the local node's CometBFT status
+type NodeStatusSource interface {
+	NodeStatus() (*health.NodeStatus, error)
 }
 
 // NodeRestarter restarts the validator node process
@@ -45,12 +111,33 @@ type NodeRestarter interface {
 
 // Server handles HTTP peer communication
 type Server struct {
+	nodeID         string
 	port           int
+	monitorOnly    bool
+	peers          []config.PeerConfig
+	prober         PeerProber
+	secrets        *crypto.SecretRing
+	provenance     *state.Chain
+	keyring        *crypto.Keyring
+	maxSkew        time.Duration
+	replays        *replayGuard
+	maxRequest     config.Size
+	plaintextKey   bool
+	cache          *responseCache
+	codec          *payloadCodec
 	stateProvider  StateProvider
 	keyProvider    KeyProvider
 	healthProvider HealthProvider
 	nodeStatus     NodeStatusProvider
 	nodeRestarter  NodeRestarter
+	heartbeats     HeartbeatReceiver
+	voter          LeaseVoter
+	artifacts      *state.Artifacts
+	guard          ActivationGuard
+	statusSource   NodeStatusSource
+	tlsConfig      *tls.Config
+	journal        *state.Journal
+	handoff        keyHandoffState
 	logger         *logger.Logger
 	httpServer     *http.Server
 }
@@ -63,40 +150,380 @@ func NewServer(
 	healthProvider HealthProvider,
 	nodeStatus NodeStatusProvider,
 	nodeRestarter NodeRestarter,
+	keyring *crypto.Keyring,
+	secrets *crypto.SecretRing,
+	prober PeerProber,
+	journal *state.Journal,
+	heartbeats HeartbeatReceiver,
 ) *Server {
-	newLogger := logger.NewLogger(cfg)
-	newLogger.WithModule("server")
+	newLogger := logger.New(cfg, "server")
 
 	return &Server{
+		nodeID:         cfg.Node.ID,
 		port:           cfg.Node.Port,
+		monitorOnly:    cfg.Node.MonitorOnly,
+		peers:          cfg.Peers,
+		prober:         prober,
+		secrets:        secrets,
+		provenance:     state.NewChain(),
+		keyring:        keyring,
+		maxSkew:        cfg.Identity.MaxSkew.Duration(),
+		replays:        newReplayGuard(cfg.Identity.MaxSkew.Duration()),
+		maxRequest:     cfg.PeerAPI.MaxRequestBytes,
+		plaintextKey:   cfg.Failover.AllowPlaintextKey,
+		cache:          newResponseCache(cfg.PeerAPI.CacheTTL.Duration()),
+		codec:          newPayloadCodec(cfg.PeerAPI),
 		stateProvider:  stateProvider,
 		keyProvider:    keyProvider,
 		healthProvider: healthProvider,
 		nodeStatus:     nodeStatus,
 		nodeRestarter:  nodeRestarter,
+		heartbeats:     heartbeats,
+		journal:        journal,
 		logger:         newLogger,
 	}
 }
 
-// Start starts the HTTP server
-func (s *Server) Start() error {
-	mux := http.NewServeMux()
+// SetLeaseVoter answers /election with voter; without one, election is
+// disabled and the endpoint is not found
+func (s *Server) SetLeaseVoter(voter LeaseVoter) {
+	s.voter = voter
+}
+
+// SetArtifacts serves and accepts the files handed over with the key;
+// without them the artifacts endpoint is not found
+func (s *Server) SetArtifacts(artifacts *state.Artifacts) {
+	s.artifacts = artifacts
+}
+
+// SetActivationGuard refuses a takeover the guard vetoes
+func (s *Server) SetActivationGuard(guard ActivationGuard) {
+	s.guard = guard
+}
+
+// SetNodeStatusSource serves /node_status from source; without one the
+// endpoint is not found
+func (s *Server) SetNodeStatusSource(source NodeStatusSource) {
+	s.statusSource = source
+}
 
-	mux.HandleFunc("/validator_state", s.handleValidatorState)
-	mux.HandleFunc("/validator_key", s.handleValidatorKey)
-	mux.HandleFunc("/failover_notify", s.handleFailoverNotify)
-	mux.HandleFunc("/failback_notify", s.handleFailbackNotify)
-	mux.HandleFunc("/health", s.handleHealth)
+// SetTLS serves the peer API over mutual TLS: peers must present a
+// certificate signed by the cluster CA
+func (s *Server) SetTLS(tlsConfig *tls.Config) {
+	s.tlsConfig = tlsConfig
+}
 
+// Start starts the HTTP server. It speaks HTTP/2 without TLS too, which
+// gRPC peers need.
+func (s *Server) Start() error {
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetHTTP2(true)
+	protocols.SetUnencryptedHTTP2(true)
 	s.httpServer = &http.Server{
-		Addr:    fmt.Sprintf(":%d", s.port),
-		Handler: mux,
+		Addr:      fmt.Sprintf(":%d", s.port),
+		Handler:   s.Handler(),
+		TLSConfig: s.tlsConfig,
+		Protocols: protocols,
 	}
 
+	if s.tlsConfig != nil {
+		s.logger.Info("Starting peer server on port %d with mutual TLS", s.port)
+		return s.httpServer.ListenAndServeTLS("", "")
+	}
 	s.logger.Info("Starting peer server on port %d", s.port)
 	return s.httpServer.ListenAndServe()
 }
 
+// Handler returns the peer API with its middleware. Peers may send the
+// same requests as calls of the Peer gRPC service over HTTP/2.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.Handle(communication.PathValidatorState, s.authenticate(s.cache.wrap(s.handleValidatorState)))
+	mux.Handle(communication.PathValidatorKey, s.authenticate(s.writable(
+		deprecated(http.MethodPost, communication.PathValidatorKey, communication.PathKeyHandoff, s.handleValidatorKey))))
+	mux.Handle(communication.PathKeyHandoff, s.authenticate(s.writable(s.handleKeyHandoff)))
+	mux.Handle(communication.PathFailoverNotify, s.authenticate(s.handleFailoverNotify))
+	mux.Handle(communication.PathFailbackNotify, s.authenticate(s.handleFailbackNotify))
+	mux.HandleFunc(communication.PathHealth, s.cache.wrap(s.handleHealth))
+	mux.Handle(communication.PathNodeStatus, s.authenticate(s.cache.wrap(s.handleNodeStatus)))
+	mux.Handle(communication.PathHeartbeat, s.authenticate(s.handleHeartbeat))
+	mux.Handle(communication.PathHandshake, s.authenticate(s.handleHandshake))
+	mux.Handle(communication.PathElection, s.authenticate(s.handleElection))
+	mux.Handle(communication.PathArtifacts, s.authenticate(s.writable(s.handleArtifact)))
+	mux.Handle(communication.PathLinkProbe, s.authenticate(s.handleLinkProbe))
+	mux.Handle("/metrics", metrics.Handler())
+	if s.keyring != nil {
+		mux.HandleFunc(communication.PathEnroll, s.handleEnroll)
+	}
+
+	return s.serveGRPC(s.codec.wrap(s.cache.invalidateOnWrite(correlate(supervise.Handler(s.logger, "peer-api", mux)))))
+}
+
+// correlate tags this node's log lines with the transition ID a peer sent
+// for as long as its request is handled
+func correlate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id := r.Header.Get(communication.HeaderTransition); id != "" {
+			logger.JoinTransition(id)
+			defer logger.EndTransition(id)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authenticate verifies the signature of a peer request: ed25519 against
+// the keyring with identity enabled, from configured peers only, otherwise a
+// timestamped HMAC under the cluster secret. Unsigned requests are rejected,
+// and so are writes that repeat one already accepted.
+func (s *Server) authenticate(next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.keyring == nil && s.secrets == nil {
+			next(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "Failed to read body", http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		nodeID := r.Header.Get(crypto.HeaderNodeID)
+		if s.keyring == nil {
+			s.authenticateHMAC(w, r, nodeID, body, next)
+			return
+		}
+		publicKey, ok := s.keyring.Lookup(nodeID)
+		if !ok || s.peer(nodeID) == nil {
+			s.logger.Warn("Rejected request from unknown or revoked node %q to %s", nodeID, r.URL.Path)
+			http.Error(w, "Unknown node", http.StatusUnauthorized)
+			return
+		}
+
+		timestamp, err := strconv.ParseInt(r.Header.Get(crypto.HeaderTimestamp), 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid timestamp", http.StatusUnauthorized)
+			return
+		}
+
+		if err := crypto.VerifyRequest(publicKey, nodeID, r.Method, r.URL.Path, body,
+			timestamp, r.Header.Get(crypto.HeaderSignature), s.maxSkew); err != nil {
+			s.logger.Warn("Rejected request to %s: %v", r.URL.Path, err)
+			http.Error(w, "Invalid signature", http.StatusUnauthorized)
+			return
+		}
+
+		s.unreplayed(w, r, timestamp, next)
+	})
+}
+
+// authenticateHMAC verifies a request signed with the cluster secret, the
+// current one or, while it is rotated, the previous one
+func (s *Server) authenticateHMAC(w http.ResponseWriter, r *http.Request, nodeID string, body []byte, next http.HandlerFunc) {
+	timestamp, err := strconv.ParseInt(r.Header.Get(crypto.HeaderTimestamp), 10, 64)
+	if err != nil {
+		s.logger.Warn("Rejected unsigned request from %q to %s", nodeID, r.URL.Path)
+		http.Error(w, "Invalid timestamp", http.StatusUnauthorized)
+		return
+	}
+	if err := s.secrets.VerifyRequest(nodeID, r.Method, r.URL.Path, body,
+		timestamp, r.Header.Get(crypto.HeaderSignature), s.maxSkew); err != nil {
+		s.logger.Warn("Rejected request to %s: %v", r.URL.Path, err)
+		http.Error(w, "Invalid signature", http.StatusUnauthorized)
+		return
+	}
+	s.unreplayed(w, r, timestamp, next)
+}
+
+// writable refuses a peer's attempt to write files on a monitor-only
+// node; reads still pass
+func (s *Server) writable(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.monitorOnly && r.Method != http.MethodGet {
+			s.logger.Warn("Refused %s %s: monitor-only mode", r.Method, r.URL.Path)
+			http.Error(w, "Monitor-only mode, not accepting transfers", http.StatusServiceUnavailable)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// handleEnroll pins a peer's identity key, authenticated by the cluster secret
+func (s *Server) handleEnroll(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+	req, err := communication.DecodeEnroll(body)
+	if err != nil {
+		s.logger.Warn("Rejected enrollment: %v", err)
+		http.Error(w, "Invalid enrollment", http.StatusBadRequest)
+		return
+	}
+
+	peer := s.peer(req.NodeID)
+	if peer == nil {
+		s.logger.Warn("Rejected enrollment for node %q: not a configured peer", req.NodeID)
+		http.Error(w, "Unknown node", http.StatusForbidden)
+		return
+	}
+
+	payload := communication.EnrollPayload(req.NodeID, req.PublicKey)
+	if !s.secrets.VerifyTimed(payload, req.Signature, req.Timestamp, s.maxSkew.Milliseconds()) {
+		s.logger.Warn("Rejected enrollment for node %q: bad signature", req.NodeID)
+		http.Error(w, "Invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	if err := s.keyring.Enroll(req.NodeID, req.PublicKey); err != nil {
+		s.logger.Warn("Rejected enrollment for node %q: %v", req.NodeID, err)
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	s.logger.Info("Enrolled identity key for node %s", req.NodeID)
+	if req.Address != "" && !communication.SameAddress(peer.Address, req.Address) {
+		s.logger.Warn("Node %s advertises %s but is configured here as %s", req.NodeID, req.Address, peer.Address)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// peer returns the configured peer with the given ID, nil for any other
+// node: the cluster secret alone does not make a node a member
+func (s *Server) peer(nodeID string) *config.PeerConfig {
+	for i := range s.peers {
+		if s.peers[i].ID == nodeID {
+			return &s.peers[i]
+		}
+	}
+	return nil
+}
+
+// handleElection answers a peer's lease request. With identity enabled the
+// request must come from the candidate it names, so one peer cannot ask
+// for, or give back, a lease on another's behalf.
+func (s *Server) handleElection(w http.ResponseWriter, r *http.Request) {
+	if s.voter == nil {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+	req, err := communication.DecodeLeaseRequest(body)
+	if err != nil {
+		s.logger.Warn("Rejected lease request: %v", err)
+		http.Error(w, "Invalid lease request", http.StatusBadRequest)
+		return
+	}
+	if s.keyring != nil && r.Header.Get(crypto.HeaderNodeID) != req.Candidate {
+		s.logger.Warn("Rejected lease request for %s signed by %s", req.Candidate, r.Header.Get(crypto.HeaderNodeID))
+		http.Error(w, "Candidate does not match signer", http.StatusForbidden)
+		return
+	}
+
+	resp := s.voter.Vote(req)
+	if !resp.Granted && !req.Resign {
+		s.logger.Info("Denied lease to %s in term %d: %s", req.Candidate, req.Term, resp.Reason)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleHandshake calls the requesting node back on the address configured
+// for it, so the caller learns whether failover traffic can reach it
+func (s *Server) handleHandshake(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+	req, err := communication.DecodeHandshake(body)
+	if err != nil {
+		s.logger.Warn("Rejected handshake: %v", err)
+		http.Error(w, "Invalid handshake", http.StatusBadRequest)
+		return
+	}
+
+	resp := communication.HandshakeResponse{
+		NodeID:    s.nodeID,
+		Protocol:  peerproto.Version,
+		Features:  communication.Features,
+		BootNonce: communication.BootNonce,
+	}
+	for _, peer := range s.peers {
+		if peer.ID == req.NodeID {
+			resp.YourAddress = peer.Address
+			break
+		}
+	}
+
+	if resp.YourAddress == "" {
+		s.logger.Warn("Handshake from %s: no peer with that ID is configured", req.NodeID)
+	} else if err := s.prober.Probe(resp.YourAddress); err != nil {
+		resp.ReachError = err.Error()
+		resp.FailureKind = communication.ClassifyError(err)
+		s.logger.Warn("Handshake from %s: cannot reach it back at %s: %v", req.NodeID, resp.YourAddress, err)
+	} else {
+		resp.ReachedBack = true
+	}
+
+	// Try the caller's advertised address when the configured one fails,
+	// so the caller learns which address to have configured here
+	if !resp.ReachedBack && req.AdvertiseAddress != "" && !communication.SameAddress(req.AdvertiseAddress, resp.YourAddress) {
+		resp.Advertised = req.AdvertiseAddress
+		if err := s.prober.Probe(req.AdvertiseAddress); err != nil {
+			s.logger.Warn("Handshake from %s: cannot reach its advertised address %s either: %v", req.NodeID, req.AdvertiseAddress, err)
+		} else {
+			resp.AdvertisedReached = true
+			s.logger.Warn("Handshake from %s: reached it at its advertised address %s; configure that as its address",
+				req.NodeID, req.AdvertiseAddress)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// deprecated wraps a handler for an endpoint scheduled for removal so every
+// call with method is recorded by the deprecation registry and flagged to
+// the caller; other methods pass untouched
+func deprecated(method, endpoint, replacement string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method {
+			next(w, r)
+			return
+		}
+		deprecation.Record(deprecation.KindEndpoint, method+" "+endpoint, replacement)
+		w.Header().Set("Deprecation", "true")
+		if replacement != "" {
+			w.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", replacement))
+		}
+		next(w, r)
+	}
+}
+
 // Stop gracefully stops the HTTP server
 func (s *Server) Stop() error {
 	if s.httpServer != nil {
@@ -113,11 +540,19 @@ func (s *Server) handleValidatorState(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(validatorState)
+	asProto := communication.AcceptsProto(r.Header.Get("Accept"))
+	body, err := communication.EncodeState(asProto, validatorState)
+	if err != nil {
+		http.Error(w, "Failed to encode state", http.StatusInternalServerError)
+		return
+	}
+	communication.WriteLink(w.Header(), s.provenance.Record(validatorState, s.secrets.Current()))
+	writeMessage(w, asProto, body)
 }
 
-// handleValidatorKey handles key transfer requests
+// handleValidatorKey handles key transfer requests. The key always
+// travels sealed with the cluster secret; a key posted in the clear is
+// refused unless failover.allow_plaintext_key is set.
 func (s *Server) handleValidatorKey(w http.ResponseWriter, r *http.Request) {
 	if r.Method == http.MethodGet {
 		keyData, err := s.keyProvider.KeyToBytes()
@@ -125,8 +560,13 @@ func (s *Server) handleValidatorKey(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "No key available", http.StatusNotFound)
 			return
 		}
-		w.Header().Set("Content-Type", "application/json")
-		w.Write(keyData)
+		sealed, err := crypto.Encrypt(keyData, s.secrets.Current())
+		if err != nil {
+			http.Error(w, "Failed to seal key", http.StatusInternalServerError)
+			return
+		}
+		asProto := communication.AcceptsProto(r.Header.Get("Accept"))
+		writeMessage(w, asProto, communication.EncodeKey(asProto, sealed))
 		return
 	}
 
@@ -138,8 +578,23 @@ func (s *Server) handleValidatorKey(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "Failed to read body", http.StatusBadRequest)
 			return
 		}
+		sealed, err := communication.DecodeKey(communication.IsProto(r.Header.Get("Content-Type")), body)
+		if err != nil {
+			s.logger.Warn("Rejected key transfer: %v", err)
+			http.Error(w, "Invalid key transfer: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		keyData, plaintext, err := communication.UnsealKey(s.secrets, sealed, s.plaintextKey)
+		if err != nil {
+			s.logger.Warn("Rejected key transfer: %v", err)
+			http.Error(w, "Invalid key transfer: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if plaintext {
+			s.logger.Warn("Accepted a validator key sent in the clear (failover.allow_plaintext_key)")
+		}
 
-		if err := s.keyProvider.KeyFromBytes(body); err != nil {
+		if err := s.keyProvider.KeyFromBytes(keyData); err != nil {
 			s.logger.Error("Failed to save received key: %v", err)
 			http.Error(w, "Failed to save key", http.StatusInternalServerError)
 			return
@@ -153,74 +608,360 @@ func (s *Server) handleValidatorKey(w http.ResponseWriter, r *http.Request) {
 	http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 }
 
-// handleFailoverNotify processes failover notification from peer
-func (s *Server) handleFailoverNotify(w http.ResponseWriter, r *http.Request) {
-	s.logger.Info("Received failover notification from peer")
+// handleLinkProbe reads and discards a link probe; the sender times it
+func (s *Server) handleLinkProbe(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if _, err := io.Copy(io.Discard, r.Body); err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
 
-	if !s.nodeStatus.IsActive() && s.healthProvider.IsHealthy() {
-		s.logger.Info("Taking over validator duties")
+// handleArtifact serves an artifact to a peer taking over, or saves one a
+// releasing peer sends. Artifacts configured with encrypt travel sealed
+// with the cluster secret, and plaintext is refused for them.
+func (s *Server) handleArtifact(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, communication.PathArtifacts)
+	if s.artifacts == nil {
+		http.NotFound(w, r)
+		return
+	}
+	artifact, ok := s.artifacts.Get(name)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
 
-		if err := s.stateProvider.AcquireLock(); err != nil {
-			s.logger.Error("Failed to acquire state lock: %v", err)
-			http.Error(w, "Failed to acquire lock", http.StatusInternalServerError)
+	switch r.Method {
+	case http.MethodGet:
+		data, err := s.artifacts.Read(name)
+		if err != nil {
+			s.logger.Warn("Failed to read artifact %s: %v", name, err)
+			http.Error(w, "Artifact not available", http.StatusNotFound)
 			return
 		}
+		transfer := communication.ArtifactTransfer{Name: name, Data: data}
+		if artifact.Encrypt {
+			if transfer.Data, err = crypto.Encrypt(data, s.secrets.Current()); err != nil {
+				s.logger.Error("Failed to encrypt artifact %s: %v", name, err)
+				http.Error(w, "Failed to encrypt artifact", http.StatusInternalServerError)
+				return
+			}
+			transfer.Encrypted = true
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(transfer)
 
-		// Restart node to pick up the new key (received earlier via POST /validator_key)
-		if s.nodeRestarter != nil {
-			if err := s.nodeRestarter.Restart(); err != nil {
-				s.logger.Error("Failed to restart node: %v", err)
-				http.Error(w, "Failed to restart node", http.StatusInternalServerError)
+	case http.MethodPost:
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "Failed to read body", http.StatusBadRequest)
+			return
+		}
+		transfer, err := communication.DecodeArtifact(body)
+		if err != nil || transfer.Name != name {
+			s.logger.Warn("Rejected artifact %s: %v", name, err)
+			http.Error(w, "Invalid artifact", http.StatusBadRequest)
+			return
+		}
+		data := transfer.Data
+		if transfer.Encrypted {
+			if data, err = s.secrets.Decrypt(data); err != nil {
+				s.logger.Warn("Rejected artifact %s: %v", name, err)
+				http.Error(w, "Failed to decrypt artifact", http.StatusBadRequest)
 				return
 			}
+		} else if artifact.Encrypt {
+			s.logger.Warn("Rejected artifact %s sent in plaintext", name)
+			http.Error(w, "Artifact must be encrypted", http.StatusBadRequest)
+			return
 		}
+		if err := s.artifacts.Write(name, data); err != nil {
+			s.logger.Error("Failed to save artifact %s: %v", name, err)
+			http.Error(w, "Failed to save artifact", http.StatusInternalServerError)
+			return
+		}
+		s.logger.Info("Received artifact %s from peer", name)
+		w.WriteHeader(http.StatusOK)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleFailoverNotify processes failover notification from peer
+func (s *Server) handleFailoverNotify(w http.ResponseWriter, r *http.Request) {
+	reason, err := transitionReason(r)
+	if err != nil {
+		s.logger.Warn("Rejected failover notification: %v", err)
+		http.Error(w, "Invalid transition: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.logger.Info("Received failover notification from peer (%s)", reason)
 
-		s.nodeStatus.SetActive(true)
-		s.logger.Info("Successfully took over as active validator")
+	if !s.nodeStatus.IsActive() && s.healthProvider.IsHealthy() {
+		err := s.nodeStatus.RunTransition(transition.KindAcquire, reason, func() error {
+			return s.takeOver(reason, r.Header.Get(crypto.HeaderNodeID))
+		})
+		if err != nil {
+			writeTransitionError(w, err)
+			return
+		}
 	}
 
 	w.WriteHeader(http.StatusOK)
 }
 
+// takeOver acquires validator duties at the request of the peer from. The
+// guard vetoed the handoff already if it would; it is asked again since
+// the peer may have signed more before it stopped.
+func (s *Server) takeOver(reason, from string) error {
+	// Another request may have made us active while this one waited
+	if s.nodeStatus.IsActive() {
+		return nil
+	}
+	if s.guard != nil {
+		if err := s.guard.CheckActivation(from); err != nil {
+			s.logger.Error("Refusing takeover: %v", err)
+			return &transitionError{http.StatusServiceUnavailable, "Refusing takeover: " + err.Error()}
+		}
+	}
+	s.logger.Info("Taking over validator duties")
+
+	if err := s.journal.Begin(state.TransitionAcquire); err != nil {
+		s.logger.Error("Refusing takeover, could not write the transition journal: %v", err)
+		return &transitionError{http.StatusInternalServerError, "Failed to journal takeover"}
+	}
+	defer s.endTransition()
+
+	if err := s.journal.Step(state.StepAcquireLock, s.stateProvider.AcquireLock); err != nil {
+		// Without the lock there is no proof the peer really stopped signing
+		if errors.Is(err, state.ErrLockUnreachable) {
+			s.logger.Error("Refusing takeover, lock backend unreachable: %v", err)
+			return &transitionError{http.StatusServiceUnavailable, "Lock backend unreachable, refusing takeover"}
+		}
+		s.logger.Error("Failed to acquire state lock: %v", err)
+		return &transitionError{http.StatusInternalServerError, "Failed to acquire lock"}
+	}
+
+	// Restart node to pick up the new key (received earlier via POST /validator_key)
+	if s.nodeRestarter != nil {
+		if err := s.journal.Step(state.StepRestartNode, s.nodeRestarter.Restart); err != nil {
+			s.logger.Error("Failed to restart node: %v", err)
+			return &transitionError{http.StatusInternalServerError, "Failed to restart node"}
+		}
+	}
+
+	s.nodeStatus.SetActive(true, reason)
+	s.logger.Info("Successfully took over as active validator")
+	return nil
+}
+
+// endTransition closes the journaled transition
+func (s *Server) endTransition() {
+	if err := s.journal.End(); err != nil {
+		s.logger.Error("Failed to close transition journal: %v", err)
+	}
+}
+
 // handleFailbackNotify processes failback notification from peer
 func (s *Server) handleFailbackNotify(w http.ResponseWriter, r *http.Request) {
-	s.logger.Info("Received failback notification from peer")
+	reason, err := transitionReason(r)
+	if err != nil {
+		s.logger.Warn("Rejected failback notification: %v", err)
+		http.Error(w, "Invalid transition: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.logger.Info("Received failback notification from peer (%s)", reason)
 
 	if s.nodeStatus.IsActive() {
-		s.logger.Info("Releasing validator duties for failback")
-
-		// Disable our key (swap to mock) before releasing
-		if err := s.keyProvider.DeleteKey(); err != nil {
-			s.logger.Error("Failed to disable key: %v", err)
+		err := s.nodeStatus.RunTransition(transition.KindRelease, reason, func() error {
+			s.release(reason)
+			return nil
+		})
+		if err != nil {
+			writeTransitionError(w, err)
+			return
 		}
+	}
 
-		// Restart node to pick up the disabled key
-		if s.nodeRestarter != nil {
-			if err := s.nodeRestarter.Restart(); err != nil {
-				s.logger.Error("Failed to restart node: %v", err)
-			}
-		}
+	w.WriteHeader(http.StatusOK)
+}
+
+// release gives up validator duties at the peer's request
+func (s *Server) release(reason string) {
+	// Another request may have made us passive while this one waited
+	if !s.nodeStatus.IsActive() {
+		return
+	}
+	s.logger.Info("Releasing validator duties for failback")
+
+	// Giving up signing is safe without a journal, so release goes ahead
+	if err := s.journal.Begin(state.TransitionRelease); err != nil {
+		s.logger.Error("Failed to journal release: %v", err)
+	}
 
-		if err := s.stateProvider.ReleaseLock(); err != nil {
-			s.logger.Error("Failed to release state lock: %v", err)
+	// Disable our key (swap to mock) before releasing
+	if err := s.journal.Release(state.StepDisableKey, s.keyProvider.DeleteKey); err != nil {
+		s.logger.Error("Failed to disable key: %v", err)
+	}
+
+	// Restart node to pick up the disabled key
+	if s.nodeRestarter != nil {
+		if err := s.journal.Release(state.StepRestartNode, s.nodeRestarter.Restart); err != nil {
+			s.logger.Error("Failed to restart node: %v", err)
 		}
+	}
 
-		s.nodeStatus.SetActive(false)
-		s.logger.Info("Successfully released validator duties")
+	if err := s.journal.Release(state.StepReleaseLock, s.stateProvider.ReleaseLock); err != nil {
+		s.logger.Error("Failed to release state lock: %v", err)
 	}
+	s.endTransition()
 
-	w.WriteHeader(http.StatusOK)
+	s.nodeStatus.SetActive(false, reason)
+	s.logger.Info("Successfully released validator duties")
+}
+
+// transitionError is a failed peer-requested transition and the status
+// to answer with
+type transitionError struct {
+	code    int
+	message string
+}
+
+func (e *transitionError) Error() string { return e.message }
+
+// writeTransitionError answers a peer whose transition did not happen
+func writeTransitionError(w http.ResponseWriter, err error) {
+	var terr *transitionError
+	switch {
+	case errors.As(err, &terr):
+		http.Error(w, terr.message, terr.code)
+	case errors.Is(err, transition.ErrContradictory):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+	}
 }
 
 // handleHealth returns health status for peer monitoring
 func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	status := map[string]interface{}{
-		"healthy": s.healthProvider.IsHealthy(),
-		"active":  s.nodeStatus.IsActive(),
-		"primary": s.nodeStatus.IsPrimary(),
-		"height":  s.healthProvider.GetLastHeight(),
+		"healthy":            s.healthProvider.IsHealthy(),
+		"active":             s.nodeStatus.IsActive(),
+		"primary":            s.nodeStatus.IsPrimary(),
+		"height":             s.healthProvider.GetLastHeight(),
+		"process":            health.ReadResourceUsage(),
+		"progress":           s.nodeStatus.Progress(),
+		"boot_nonce":         communication.BootNonce,
+		"custody":            s.nodeStatus.KeyCustody(),
+		"transition_summary": s.nodeStatus.TransitionSummary(),
+	}
+	if readiness := s.nodeStatus.Readiness(); readiness != nil {
+		status["readiness"] = readiness
+	}
+	if peer := s.nodeStatus.PeerProgress(); peer != nil {
+		status["peer_progress"] = peer
+	}
+	if peer := s.nodeStatus.PeerTransitions(); peer != nil {
+		status["peer_transitions"] = peer
+	}
+	if s.nodeStatus.IsDraining() {
+		status["draining"] = true
+	}
+	if usages := deprecation.Snapshot(); len(usages) > 0 {
+		status["deprecations"] = usages
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(status)
 }
+
+// handleNodeStatus proxies a sanitized subset of the local node's /status,
+// so peers and dashboards need no access to the node's RPC
+func (s *Server) handleNodeStatus(w http.ResponseWriter, r *http.Request) {
+	if s.statusSource == nil {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	status, err := s.statusSource.NodeStatus()
+	if err != nil {
+		s.logger.Warn("Failed to read node status for a peer: %v", err)
+		http.Error(w, "Node status unavailable", http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+// handleHeartbeat takes a health report from the active peer and answers
+// with this node's view of it
+func (s *Server) handleHeartbeat(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+	report, err := communication.DecodeReport(communication.IsProto(r.Header.Get("Content-Type")), body)
+	if err != nil {
+		s.logger.Warn("Rejected heartbeat: %v", err)
+		http.Error(w, "Invalid heartbeat: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	asProto := communication.AcceptsProto(r.Header.Get("Accept"))
+	ack, err := communication.EncodeAck(asProto, s.heartbeats.ReceiveHeartbeat(report))
+	if err != nil {
+		http.Error(w, "Failed to encode heartbeat ack", http.StatusInternalServerError)
+		return
+	}
+	writeMessage(w, asProto, ack)
+}
+
+// writeMessage answers with an encoded peer message
+func writeMessage(w http.ResponseWriter, asProto bool, body []byte) {
+	contentType := "application/json"
+	if asProto {
+		contentType = peerproto.ContentType
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Write(body)
+}
+
+// transitionReason returns the reason code of a takeover or release
+// request: from the header, or from a protobuf body without one
+func transitionReason(r *http.Request) (string, error) {
+	reason := r.Header.Get(communication.HeaderReason)
+	if err := communication.ValidateReason(reason); err != nil {
+		return "", err
+	}
+	if !communication.IsProto(r.Header.Get("Content-Type")) {
+		return reason, nil
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return "", err
+	}
+	_, bodyReason, err := communication.DecodeTransition(body)
+	if err != nil {
+		return "", err
+	}
+	if reason == "" {
+		reason = bodyReason
+	}
+	return reason, nil
+}
@@ -0,0 +1,239 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/communication"
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/crypto"
+	"github.com/aldebaranode/syncguard/internal/deprecation"
+	"github.com/aldebaranode/syncguard/internal/health"
+	"github.com/aldebaranode/syncguard/internal/state"
+)
+
+func TestNodeStatus(t *testing.T) {
+	comet := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"result":{
+			"node_info":{"network":"test-chain","moniker":"val-1","listen_addr":"tcp://10.0.0.5:26656"},
+			"sync_info":{"latest_block_height":"1234","catching_up":false},
+			"validator_info":{"address":"ABCDEF0123456789ABCDEF0123456789ABCDEF01"}}}`)
+	}))
+	defer comet.Close()
+
+	cfg := &config.Config{}
+	cfg.Node.ID = "node-1"
+	cfg.Health.Timeout = 5
+	cfg.Logging = config.LoggingConfig{Level: "error", File: "/dev/null"}
+	node := &loadNode{}
+	s := NewServer(cfg, nil, nil, node, node, nil, nil, nil, nil, nil, node)
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+	client := communication.NewClient(cfg, nil)
+
+	if _, err := client.FetchNodeStatus(srv.URL); communication.StatusCode(err) != http.StatusNotFound {
+		t.Fatalf("node status without a source = %v, want 404", err)
+	}
+
+	s.SetNodeStatusSource(health.NewChecker(cfg, comet.URL))
+	status, err := client.FetchNodeStatus(srv.URL)
+	if err != nil {
+		t.Fatalf("FetchNodeStatus() error = %v", err)
+	}
+	want := health.NodeStatus{Height: 1234, Network: "test-chain", Moniker: "val-1"}
+	if *status != want {
+		t.Errorf("node status = %+v, want %+v", *status, want)
+	}
+
+	resp, err := http.Get(srv.URL + communication.PathNodeStatus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	for _, hidden := range []string{"ABCDEF0123", "10.0.0.5"} {
+		if strings.Contains(string(body), hidden) {
+			t.Errorf("node status leaks %q: %s", hidden, body)
+		}
+	}
+}
+
+func TestAuthenticate_HMAC(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Node.ID = "node-1"
+	cfg.Secret = "new-secret"
+	cfg.Identity.MaxSkew = 30
+	cfg.Logging = config.LoggingConfig{Level: "error", File: "/dev/null"}
+	node := &loadNode{height: 1}
+	states := state.NewManager(filepath.Join(t.TempDir(), "priv_validator_state.json"), "")
+	if err := states.SaveState(&state.ValidatorState{Height: 1}); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(NewServer(cfg, states, nil, node, node, nil, nil,
+		crypto.NewSecretRing("new-secret", "old-secret"), nil, nil, node).Handler())
+	defer srv.Close()
+
+	get := func(sign func(req *http.Request)) int {
+		req, _ := http.NewRequest(http.MethodGet, srv.URL+communication.PathValidatorState, nil)
+		sign(req)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	signWith := func(secret string, at time.Time) func(req *http.Request) {
+		return func(req *http.Request) {
+			for k, v := range crypto.SignRequestHMAC(secret, "node-2", req.Method, req.URL.Path, nil, at.Unix()) {
+				req.Header.Set(k, v)
+			}
+		}
+	}
+
+	for _, tc := range []struct {
+		name string
+		sign func(req *http.Request)
+		want int
+	}{
+		{"unsigned", func(*http.Request) {}, http.StatusUnauthorized},
+		{"current secret", signWith("new-secret", time.Now()), http.StatusOK},
+		{"previous secret", signWith("old-secret", time.Now()), http.StatusOK},
+		{"wrong secret", signWith("other-secret", time.Now()), http.StatusUnauthorized},
+		{"replayed", signWith("new-secret", time.Now().Add(-time.Minute)), http.StatusUnauthorized},
+	} {
+		if got := get(tc.sign); got != tc.want {
+			t.Errorf("%s: status %d, want %d", tc.name, got, tc.want)
+		}
+	}
+
+	// A write request is accepted once, however fresh its timestamp
+	post := func(sign func(req *http.Request)) int {
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+communication.PathElection, nil)
+		sign(req)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	captured := signWith("new-secret", time.Now())
+	if got := post(captured); got == http.StatusConflict || got == http.StatusUnauthorized {
+		t.Errorf("first POST: status %d", got)
+	}
+	if got := post(captured); got != http.StatusConflict {
+		t.Errorf("replayed POST: status %d, want 409", got)
+	}
+
+	// The client signs on its own, and /health stays open to load balancers
+	if _, _, err := communication.NewClient(cfg, nil).FetchState(srv.URL, true); err != nil {
+		t.Errorf("signed client request failed: %v", err)
+	}
+	resp, err := http.Get(srv.URL + communication.PathHealth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusUnauthorized {
+		t.Error("/health requires a signature")
+	}
+}
+
+func TestDeprecated_ValidatorKeyPost(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Node.ID = "node-1"
+	cfg.Logging = config.LoggingConfig{Level: "error", File: "/dev/null"}
+	node := &loadNode{}
+	srv := httptest.NewServer(NewServer(cfg, nil, nil, node, node, nil, nil, nil, nil, nil, node).Handler())
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+communication.PathValidatorKey, "application/json", strings.NewReader(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.Header.Get("Deprecation") != "true" {
+		t.Errorf("POST %s lacks the Deprecation header", communication.PathValidatorKey)
+	}
+	if link := resp.Header.Get("Link"); !strings.Contains(link, communication.PathKeyHandoff) {
+		t.Errorf("Link = %q, want the key handoff as successor", link)
+	}
+
+	recorded := false
+	for _, usage := range deprecation.Snapshot() {
+		if usage.Kind == deprecation.KindEndpoint && usage.Name == "POST "+communication.PathValidatorKey {
+			recorded = usage.Replacement == communication.PathKeyHandoff && usage.Count > 0
+		}
+	}
+	if !recorded {
+		t.Errorf("POST %s not recorded: %+v", communication.PathValidatorKey, deprecation.Snapshot())
+	}
+}
+
+func TestEnroll_ConfiguredPeersOnly(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.Config{}
+	cfg.Node.ID = "node-1"
+	cfg.Secret = "cluster-secret"
+	cfg.Identity.MaxSkew = 30
+	cfg.Peers = []config.PeerConfig{{ID: "node-2", Address: "10.0.0.2:8080"}}
+	cfg.Logging = config.LoggingConfig{Level: "error", File: "/dev/null"}
+	node := &loadNode{height: 1}
+	states := state.NewManager(filepath.Join(dir, "priv_validator_state.json"), "")
+	if err := states.SaveState(&state.ValidatorState{Height: 1}); err != nil {
+		t.Fatal(err)
+	}
+	keyring, err := crypto.NewKeyring(filepath.Join(dir, "keyring.json"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(NewServer(cfg, states, nil, node, node, nil, keyring,
+		crypto.NewSecretRing(cfg.Secret, ""), nil, nil, node).Handler())
+	defer srv.Close()
+
+	client := func(nodeID string) *communication.Client {
+		identity, err := crypto.LoadOrCreateIdentity(filepath.Join(dir, nodeID+".key"), nodeID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		peerCfg := &config.Config{}
+		peerCfg.Node.ID = nodeID
+		peerCfg.Secret = cfg.Secret
+		peerCfg.Logging = cfg.Logging
+		return communication.NewClient(peerCfg, identity)
+	}
+	member, stranger := client("node-2"), client("node-3")
+
+	if err := member.Enroll(srv.URL); err != nil {
+		t.Fatalf("configured peer: Enroll() error = %v", err)
+	}
+	if _, _, err := member.FetchState(srv.URL, true); err != nil {
+		t.Errorf("configured peer: FetchState() error = %v", err)
+	}
+
+	// The cluster secret alone does not admit a node under a fresh ID
+	if err := stranger.Enroll(srv.URL); communication.StatusCode(err) != http.StatusForbidden {
+		t.Errorf("unconfigured node: Enroll() error = %v, want 403", err)
+	}
+	if _, ok := keyring.Lookup("node-3"); ok {
+		t.Error("unconfigured node was enrolled")
+	}
+
+	// Nor does a key pinned for it some other way
+	identity, err := crypto.LoadOrCreateIdentity(filepath.Join(dir, "node-3.key"), "node-3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := keyring.Enroll("node-3", identity.PublicKey()); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := stranger.FetchState(srv.URL, true); communication.StatusCode(err) != http.StatusUnauthorized {
+		t.Errorf("unconfigured node: FetchState() error = %v, want 401", err)
+	}
+}
@@ -0,0 +1,227 @@
+// Package signer serves CometBFT's remote signer protocol, so the node
+// signs through syncguard instead of reading priv_validator_key.json. The
+// node is started with priv_validator_laddr and the signer dials it. It
+// signs only while the manager lets it, which gives syncguard a veto over
+// every vote and proposal and leaves no key file to move on failover.
+package signer
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	cmtproto "github.com/cometbft/cometbft/api/cometbft/types/v1"
+	"github.com/cometbft/cometbft/crypto"
+	"github.com/cometbft/cometbft/crypto/ed25519"
+	cmtjson "github.com/cometbft/cometbft/libs/json"
+	cmtlog "github.com/cometbft/cometbft/libs/log"
+	"github.com/cometbft/cometbft/privval"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/logger"
+	"github.com/aldebaranode/syncguard/internal/metrics"
+	"github.com/aldebaranode/syncguard/internal/state"
+)
+
+var requestCounter = metrics.NewCounter(
+	"syncguard_signer_requests_total",
+	"Signing requests from the node by kind (vote, proposal, bytes) and result (signed, refused, failed)",
+	"kind", "result",
+)
+
+// ErrNotActive refuses a signature on a node that does not hold the
+// validator role
+var ErrNotActive = errors.New("syncguard: this node is not the active validator")
+
+// Gate runs sign only while this node may sign for the validator, and
+// returns ErrNotActive otherwise. It must keep the node from giving up
+// the role until sign returns.
+type Gate func(sign func() error) error
+
+// Signer answers the node's signing requests with the validator key
+type Signer struct {
+	cfg    config.SignerConfig
+	logger *logger.Logger
+	pv     *gatedValidator
+	server *privval.SignerServer
+}
+
+// New loads the validator key at cometbft.key_path for the signer, and
+// keeps the last signed state at cometbft.state_path. A passive node's key
+// file holds the mock key; the real key parked next to it is used then.
+func New(cfg *config.Config, gate Gate, log *logger.Logger) (*Signer, error) {
+	key, err := loadKey(cfg.CometBFT.KeyPath, log)
+	if err != nil {
+		return nil, err
+	}
+	pv := privval.NewFilePV(key, cfg.CometBFT.KeyPath, cfg.CometBFT.StatePath)
+	v := &gatedValidator{pv: pv, statePath: cfg.CometBFT.StatePath, gate: gate}
+	if err := v.loadState(); err != nil {
+		return nil, err
+	}
+	return &Signer{cfg: cfg.Signer, logger: log, pv: v}, nil
+}
+
+// loadKey reads the private key from a priv_validator_key.json
+func loadKey(path string, log *logger.Logger) (crypto.PrivKey, error) {
+	key, err := state.NewKeyManager(path, "", log).LoadRealKey()
+	if err != nil {
+		return nil, fmt.Errorf("signer needs the validator key: %w", err)
+	}
+	if state.IsMockKey(key) {
+		return nil, fmt.Errorf("signer needs the validator key, but %s holds the mock key", path)
+	}
+	var privKey crypto.PrivKey
+	if err := cmtjson.Unmarshal(key.PrivKey, &privKey); err != nil {
+		return nil, fmt.Errorf("failed to parse validator key: %w", err)
+	}
+	return privKey, nil
+}
+
+// Address returns the address of the validator key, uppercase hex
+func (s *Signer) Address() string {
+	return s.pv.pv.GetAddress().String()
+}
+
+// Start dials the node's priv_validator_laddr and serves its requests.
+// The node waits for its signer when it starts, so the signer starts
+// first and keeps redialing whenever the node goes away.
+func (s *Signer) Start() error {
+	var dialer privval.SocketDialer
+	timeout := s.cfg.Timeout.Duration()
+	switch {
+	case strings.HasPrefix(s.cfg.Address, "tcp://"):
+		// The node does not authenticate its signer, so a fresh
+		// connection key will do
+		dialer = privval.DialTCPFn(s.cfg.Address, timeout, ed25519.GenPrivKey())
+	case strings.HasPrefix(s.cfg.Address, "unix://"):
+		dialer = privval.DialUnixFn(strings.TrimPrefix(s.cfg.Address, "unix://"))
+	default:
+		return fmt.Errorf("signer.address must start with tcp:// or unix://")
+	}
+
+	endpoint := privval.NewSignerDialerEndpoint(cometLogger{s.logger}, dialer,
+		privval.SignerDialerEndpointTimeoutReadWrite(timeout),
+		privval.SignerDialerEndpointConnRetries(math.MaxInt),
+		privval.SignerDialerEndpointRetryWaitInterval(time.Second))
+	s.server = privval.NewSignerServer(endpoint, s.cfg.ChainID, s.pv)
+	if err := s.server.Start(); err != nil {
+		return fmt.Errorf("failed to start signer: %w", err)
+	}
+	s.logger.Info("Remote signer for %s dialing the node at %s", s.Address(), s.cfg.Address)
+	return nil
+}
+
+// Stop closes the connection to the node
+func (s *Signer) Stop() {
+	if s.server == nil {
+		return
+	}
+	if err := s.server.Stop(); err != nil {
+		s.logger.Warn("Failed to stop signer: %v", err)
+	}
+}
+
+// gatedValidator signs with the validator key behind the gate. The last
+// signed height, round and step are read from the state file before each
+// signature: a passive node syncs that file from the active one, so a
+// takeover continues from where the other node stopped.
+type gatedValidator struct {
+	mu        sync.Mutex
+	pv        *privval.FilePV
+	statePath string
+	gate      Gate
+}
+
+// loadState reads the last signed state, if the file exists yet
+func (v *gatedValidator) loadState() error {
+	data, err := os.ReadFile(v.statePath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read signer state: %w", err)
+	}
+	var last privval.FilePVLastSignState
+	if err := cmtjson.Unmarshal(data, &last); err != nil {
+		return fmt.Errorf("failed to parse signer state %s: %w", v.statePath, err)
+	}
+	lss := &v.pv.LastSignState
+	lss.Height, lss.Round, lss.Step = last.Height, last.Round, last.Step
+	lss.Signature, lss.SignBytes = last.Signature, last.SignBytes
+	return nil
+}
+
+// sign runs fn behind the gate, on the latest saved state
+func (v *gatedValidator) sign(kind string, fn func() error) error {
+	err := v.gate(func() error {
+		v.mu.Lock()
+		defer v.mu.Unlock()
+		if err := v.loadState(); err != nil {
+			return err
+		}
+		return fn()
+	})
+	switch {
+	case err == nil:
+		requestCounter.Inc(kind, "signed")
+	case errors.Is(err, ErrNotActive):
+		requestCounter.Inc(kind, "refused")
+	default:
+		requestCounter.Inc(kind, "failed")
+	}
+	return err
+}
+
+// GetPubKey answers even on a passive node, which the node needs to start
+func (v *gatedValidator) GetPubKey() (crypto.PubKey, error) {
+	return v.pv.GetPubKey()
+}
+
+func (v *gatedValidator) SignVote(chainID string, vote *cmtproto.Vote, signExtension bool) error {
+	return v.sign("vote", func() error { return v.pv.SignVote(chainID, vote, signExtension) })
+}
+
+func (v *gatedValidator) SignProposal(chainID string, proposal *cmtproto.Proposal) error {
+	return v.sign("proposal", func() error { return v.pv.SignProposal(chainID, proposal) })
+}
+
+func (v *gatedValidator) SignBytes(bytes []byte) ([]byte, error) {
+	var sig []byte
+	err := v.sign("bytes", func() (err error) {
+		sig, err = v.pv.SignBytes(bytes)
+		return err
+	})
+	return sig, err
+}
+
+// cometLogger writes the signer service's logs to the syncguard log
+type cometLogger struct {
+	l *logger.Logger
+}
+
+func (c cometLogger) Debug(msg string, keyvals ...any) { c.l.Debug("%s", formatKeyvals(msg, keyvals)) }
+func (c cometLogger) Info(msg string, keyvals ...any)  { c.l.Debug("%s", formatKeyvals(msg, keyvals)) }
+func (c cometLogger) Error(msg string, keyvals ...any) { c.l.Warn("%s", formatKeyvals(msg, keyvals)) }
+
+func (c cometLogger) With(keyvals ...any) cmtlog.Logger {
+	l := c.l
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		l = l.With(fmt.Sprint(keyvals[i]), keyvals[i+1])
+	}
+	return cometLogger{l}
+}
+
+// formatKeyvals appends key=value pairs to a message
+func formatKeyvals(msg string, keyvals []any) string {
+	var b strings.Builder
+	b.WriteString(msg)
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		fmt.Fprintf(&b, " %v=%v", keyvals[i], keyvals[i+1])
+	}
+	return b.String()
+}
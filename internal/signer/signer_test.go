@@ -0,0 +1,85 @@
+package signer
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	cmtproto "github.com/cometbft/cometbft/api/cometbft/types/v1"
+	"github.com/cometbft/cometbft/privval"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/logger"
+)
+
+func testSigner(t *testing.T, active *bool) (*Signer, *config.Config) {
+	t.Helper()
+	dir := t.TempDir()
+	cfg := &config.Config{}
+	cfg.CometBFT.KeyPath = filepath.Join(dir, "priv_validator_key.json")
+	cfg.CometBFT.StatePath = filepath.Join(dir, "priv_validator_state.json")
+	pv, err := privval.GenFilePV(cfg.CometBFT.KeyPath, cfg.CometBFT.StatePath, nil)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	pv.Save()
+
+	gate := func(sign func() error) error {
+		if !*active {
+			return ErrNotActive
+		}
+		return sign()
+	}
+	s, err := New(cfg, gate, logger.New(cfg, "signer"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return s, cfg
+}
+
+func prevote(height int64) *cmtproto.Vote {
+	return &cmtproto.Vote{Type: cmtproto.PrevoteType, Height: height}
+}
+
+func TestSigner_SignsOnlyWhileActive(t *testing.T) {
+	active := false
+	s, _ := testSigner(t, &active)
+
+	if _, err := s.pv.GetPubKey(); err != nil {
+		t.Errorf("GetPubKey() on a passive node error = %v, want the key", err)
+	}
+	vote := prevote(10)
+	if err := s.pv.SignVote("test-chain", vote, false); !errors.Is(err, ErrNotActive) {
+		t.Fatalf("SignVote() while passive error = %v, want ErrNotActive", err)
+	}
+	if len(vote.Signature) > 0 {
+		t.Error("vote signed while passive")
+	}
+
+	active = true
+	if err := s.pv.SignVote("test-chain", vote, false); err != nil {
+		t.Fatalf("SignVote() while active error = %v", err)
+	}
+	if len(vote.Signature) == 0 {
+		t.Error("vote not signed while active")
+	}
+}
+
+func TestSigner_ResumesFromSyncedState(t *testing.T) {
+	active := true
+	s, cfg := testSigner(t, &active)
+
+	// The peer signed up to height 20; its state was synced to this node
+	// after the signer loaded
+	peer := privval.LoadFilePV(cfg.CometBFT.KeyPath, cfg.CometBFT.StatePath)
+	if err := peer.SignVote("test-chain", prevote(20), false); err != nil {
+		t.Fatalf("Failed to sign as the peer: %v", err)
+	}
+
+	if err := s.pv.SignVote("test-chain", prevote(15), false); err == nil {
+		t.Error("SignVote() below the synced state succeeded, want a height regression error")
+	}
+	if err := s.pv.SignVote("test-chain", prevote(21), false); err != nil {
+		t.Errorf("SignVote() above the synced state error = %v", err)
+	}
+}
@@ -0,0 +1,208 @@
+package simulate
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/constants"
+	"github.com/aldebaranode/syncguard/internal/history"
+	"github.com/aldebaranode/syncguard/internal/notify"
+	"gopkg.in/yaml.v3"
+)
+
+// Scenario is a health timeline as one node saw it
+type Scenario struct {
+	// Role is the role the node starts in, node.role when unset
+	Role    constants.NodeStatus `yaml:"role"`
+	Samples []Sample             `yaml:"samples"`
+
+	// checks is the timeline with every sample at its time; actual the
+	// transitions a history export recorded, replay set for one
+	checks []check
+	actual []Decision
+	replay bool
+}
+
+// Sample is one health check, or a run of identical ones
+type Sample struct {
+	// At is the offset from the start of the timeline, e.g. "90s"; unset,
+	// the check follows the previous one by health.interval
+	At string `yaml:"at"`
+	// Repeat runs the check this many times, health.interval apart
+	Repeat int `yaml:"repeat"`
+
+	// Healthy false fails the check without further detail
+	Healthy        *bool  `yaml:"healthy"`
+	Syncing        bool   `yaml:"syncing"`
+	RPCError       string `yaml:"rpc_error"`
+	ExecutionError string `yaml:"execution_error"`
+	// Height unset continues from the previous check, one block further
+	Height int64 `yaml:"height"`
+	// Peers unset is health.min_peers
+	Peers *int `yaml:"peers"`
+	// Tip is the highest height a peer reports, seen by health.policy,
+	// heartbeat disputes and failback
+	Tip int64 `yaml:"tip"`
+	// Heartbeat false means no health report from the active node arrived
+	// since the previous check; only a passive node looks at it
+	Heartbeat *bool `yaml:"heartbeat"`
+}
+
+// check is one health check of the expanded timeline
+type check struct {
+	time      time.Time
+	healthy   bool
+	syncing   bool
+	rpcError  string
+	execError string
+	height    int64
+	peers     int
+	tip       int64
+	heartbeat bool
+}
+
+// start anchors scenario timelines, which only have offsets
+var start = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Load reads a scenario file: YAML samples, or a history export in JSON
+// Lines (.jsonl) to replay a past incident
+func Load(cfg *config.Config, path string) (*Scenario, error) {
+	if strings.HasSuffix(path, ".jsonl") {
+		return loadHistory(cfg, path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scenario: %w", err)
+	}
+	var s Scenario
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse scenario: %w", err)
+	}
+	switch s.Role {
+	case "", constants.NodeStatusActive, constants.NodeStatusPassive:
+	default:
+		return nil, fmt.Errorf("scenario role must be 'active' or 'passive'")
+	}
+	if err := s.expand(cfg); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// expand lays the samples out on the timeline
+func (s *Scenario) expand(cfg *config.Config) error {
+	interval := cfg.Health.Interval.Duration()
+	minPeers := max(cfg.Health.MinPeers, 1)
+	at := start.Add(-interval)
+	var height int64
+	for i, sample := range s.Samples {
+		next := at.Add(interval)
+		if sample.At != "" {
+			offset, err := time.ParseDuration(sample.At)
+			if err != nil {
+				return fmt.Errorf("samples[%d].at: %w", i, err)
+			}
+			next = start.Add(offset)
+			if next.Before(at) {
+				return fmt.Errorf("samples[%d].at goes back in time", i)
+			}
+		}
+		for n := 0; n < max(sample.Repeat, 1); n++ {
+			if n > 0 {
+				next = at.Add(interval)
+			}
+			at = next
+			height++
+			if sample.Height > 0 {
+				height = sample.Height
+			}
+			c := check{
+				time:      at,
+				healthy:   sample.Healthy == nil || *sample.Healthy,
+				syncing:   sample.Syncing,
+				rpcError:  sample.RPCError,
+				execError: sample.ExecutionError,
+				height:    height,
+				peers:     minPeers,
+				tip:       sample.Tip,
+				heartbeat: sample.Heartbeat == nil || *sample.Heartbeat,
+			}
+			if sample.Peers != nil {
+				c.peers = *sample.Peers
+			}
+			s.checks = append(s.checks, c)
+		}
+	}
+	if len(s.checks) == 0 {
+		return fmt.Errorf("scenario has no samples")
+	}
+	return nil
+}
+
+// loadHistory rebuilds the health timeline from the events of a history
+// export. Every failed check is recorded, so checks missing between two
+// entries passed; those are filled in health.interval apart. Transitions
+// the node actually made are kept to compare against.
+func loadHistory(cfg *config.Config, path string) (*Scenario, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history export: %w", err)
+	}
+	defer f.Close()
+
+	s := &Scenario{replay: true}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var e history.Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("history export line %d: %w", line, err)
+		}
+		if e.Kind != history.KindEvent {
+			continue
+		}
+		switch notify.EventType(e.Type) {
+		case notify.EventHealthCheckFailed:
+			s.addHistoryCheck(cfg, e.Time, false)
+		case notify.EventHealthChanged:
+			// Turning unhealthy comes with its own failed check
+			if e.Severity == notify.SeverityInfo.String() {
+				s.addHistoryCheck(cfg, e.Time, true)
+			}
+		case notify.EventFailover, notify.EventFailback, notify.EventTakeover, notify.EventRelease:
+			s.actual = append(s.actual, Decision{Time: e.Time, Action: e.Type, Reason: constants.Reason(e.Reason)})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history export: %w", err)
+	}
+	if len(s.checks) == 0 {
+		return nil, fmt.Errorf("history export has no health check events")
+	}
+	return s, nil
+}
+
+// addHistoryCheck appends a check at t, after the passing checks implied
+// since the previous one
+func (s *Scenario) addHistoryCheck(cfg *config.Config, t time.Time, healthy bool) {
+	interval := cfg.Health.Interval.Duration()
+	peers := max(cfg.Health.MinPeers, 1)
+	if n := len(s.checks); n > 0 {
+		last := s.checks[n-1]
+		if t.Before(last.time) {
+			return
+		}
+		for at := last.time.Add(interval); t.Sub(at) >= interval/2; at = at.Add(interval) {
+			s.checks = append(s.checks, check{time: at, healthy: true, peers: peers, heartbeat: true})
+		}
+	}
+	s.checks = append(s.checks, check{time: t, healthy: healthy, peers: peers, heartbeat: true})
+}
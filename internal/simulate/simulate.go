@@ -0,0 +1,144 @@
+// Package simulate replays a health timeline through the failover
+// decisions of a node, with the configured thresholds and without
+// touching the node, its peers or the validator key. Operators use it to
+// see when failover, failback and heartbeat alerts would have fired, and
+// tune failover and health settings against past incidents.
+package simulate
+
+import (
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/constants"
+	"github.com/aldebaranode/syncguard/internal/failback"
+	"github.com/aldebaranode/syncguard/internal/health"
+)
+
+// Actions a simulation reports
+const (
+	ActionFailover        = "failover"
+	ActionFailback        = "failback"
+	ActionHeartbeatMissed = "heartbeat_missed"
+)
+
+// Decision is an action the node took, or would have taken
+type Decision struct {
+	// Time is set when replaying a history export
+	Time time.Time `json:"time,omitzero"`
+	// Offset is the time since the first check
+	Offset        time.Duration    `json:"-"`
+	OffsetSeconds float64          `json:"offset_seconds"`
+	Action        string           `json:"action"`
+	Reason        constants.Reason `json:"reason,omitempty"`
+	Failures      int              `json:"consecutive_failures,omitempty"`
+}
+
+// Report is the outcome of a simulation
+type Report struct {
+	Checks int `json:"checks"`
+	Failed int `json:"failed"`
+	// MaxConsecutive is the longest run of failed checks
+	MaxConsecutive int        `json:"max_consecutive_failures"`
+	Decisions      []Decision `json:"decisions"`
+	// Actual lists the transitions a replayed history export recorded
+	Actual []Decision `json:"actual,omitempty"`
+	Active bool       `json:"ends_active"`
+}
+
+// Run replays the scenario. It follows the health monitor: each failed
+// check counts towards failover.retry_attempts while the node is active;
+// a passing one resets the count and, on the primary site, may fail back
+// once failover.grace_period has passed and the node is within
+// failover.failback_max_lag of the tip. Failover approval, pausing,
+// monitor-only mode and peers refusing a handoff are not simulated.
+func Run(cfg *config.Config, s *Scenario) *Report {
+	checker := health.NewChecker(cfg, cfg.CometBFT.RPCURL)
+	policy := failback.New(cfg)
+	active := s.Role == constants.NodeStatusActive ||
+		(s.Role == "" && cfg.Node.Role == constants.NodeStatusActive)
+
+	report := &Report{Checks: len(s.checks)}
+	first := s.checks[0].time
+	decide := func(c check, action string, reason constants.Reason, failures int) {
+		offset := c.time.Sub(first)
+		d := Decision{Offset: offset, OffsetSeconds: offset.Seconds(), Action: action, Reason: reason, Failures: failures}
+		if s.replay {
+			d.Time = c.time
+		}
+		report.Decisions = append(report.Decisions, d)
+	}
+
+	failures := 0
+	var heartbeat time.Time
+	stale := false
+	for _, c := range s.checks {
+		nodeHealth := &health.NodeHealth{
+			Healthy:        c.healthy && c.rpcError == "",
+			IsSyncing:      c.syncing,
+			LatestHeight:   c.height,
+			PeerCount:      c.peers,
+			StatusError:    c.rpcError,
+			ExecutionError: c.execError,
+			LastCheck:      c.time,
+		}
+		checker.Record(nodeHealth)
+		if c.tip > 0 {
+			checker.ObserveTip(c.tip)
+		}
+
+		// A passive only alerts when the active node's reports stop
+		if !active && cfg.Health.Heartbeat.Enabled {
+			if c.heartbeat || heartbeat.IsZero() {
+				heartbeat, stale = c.time, false
+			} else if !stale && c.time.Sub(heartbeat) > cfg.Health.Heartbeat.StaleAfter.Duration() {
+				stale = true
+				decide(c, ActionHeartbeatMissed, "", 0)
+			}
+		}
+
+		healthy := checker.IsHealthy()
+		reason := checker.FailureReason()
+		if healthy && active && cfg.Health.Heartbeat.Enabled && cfg.Health.Heartbeat.Enforce && c.tip > 0 &&
+			health.Disagreement(health.NewReport(cfg.Node.ID, nodeHealth), c.tip, cfg.Health.Heartbeat.MaxLag) != "" {
+			healthy, reason = false, constants.ReasonHealthDisputed
+		}
+
+		if !healthy {
+			report.Failed++
+			failures++
+			report.MaxConsecutive = max(report.MaxConsecutive, failures)
+			policy.Observe(false, c.time)
+			if active && failures >= cfg.Failover.RetryAttempts {
+				decide(c, ActionFailover, reason, failures)
+				active = false
+				failures = 0
+				heartbeat, stale = c.time, false
+			}
+			continue
+		}
+
+		failures = 0
+		policy.Observe(true, c.time)
+		if active || !cfg.Node.IsPrimary || !policy.Automatic() {
+			continue
+		}
+		if ok, _ := policy.Stable(c.time); !ok {
+			continue
+		}
+		tip := max(c.tip, c.height)
+		if ok, _ := policy.CaughtUp(c.height, tip); !ok {
+			continue
+		}
+		decide(c, ActionFailback, constants.ReasonPrimaryRecovered, 0)
+		policy.Succeeded()
+		active = true
+	}
+
+	for _, d := range s.actual {
+		d.Offset = d.Time.Sub(first)
+		d.OffsetSeconds = d.Offset.Seconds()
+		report.Actual = append(report.Actual, d)
+	}
+	report.Active = active
+	return report
+}
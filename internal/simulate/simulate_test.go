@@ -0,0 +1,150 @@
+package simulate_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/constants"
+	"github.com/aldebaranode/syncguard/internal/simulate"
+)
+
+func testConfig() *config.Config {
+	return &config.Config{
+		Node: config.NodeConfig{ID: "a", Role: constants.NodeStatusActive, IsPrimary: true},
+		Health: config.HealthConfig{
+			Interval: 10,
+			Heartbeat: config.HeartbeatConfig{
+				Enabled: true, Enforce: true, MaxLag: 5, StaleAfter: 30,
+			},
+		},
+		Failover: config.FailoverConfig{
+			RetryAttempts: 3, GracePeriod: 60, FailbackMaxLag: 10,
+			FailbackHoldOff: 60, FailbackMaxHoldOff: 600,
+		},
+		Logging: config.LoggingConfig{Level: "error"},
+	}
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRun_FailoverAndFailback(t *testing.T) {
+	path := writeFile(t, "scenario.yaml", `
+samples:
+  - repeat: 5
+  - rpc_error: "connection refused"
+    repeat: 2
+  - {}
+  - rpc_error: "connection refused"
+    repeat: 3
+  - repeat: 8
+`)
+	cfg := testConfig()
+	scenario, err := simulate.Load(cfg, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := simulate.Run(cfg, scenario)
+
+	if report.Checks != 19 || report.Failed != 5 || report.MaxConsecutive != 3 {
+		t.Errorf("unexpected counts: %+v", report)
+	}
+	if len(report.Decisions) != 2 {
+		t.Fatalf("expected a failover and a failback, got %+v", report.Decisions)
+	}
+	failover, failback := report.Decisions[0], report.Decisions[1]
+	if failover.Action != simulate.ActionFailover || failover.Offset != 100*time.Second ||
+		failover.Reason != constants.ReasonRPCTimeout || failover.Failures != 3 {
+		t.Errorf("unexpected failover: %+v", failover)
+	}
+	// Healthy from 110s, stable after the 60s grace period
+	if failback.Action != simulate.ActionFailback || failback.Offset != 170*time.Second {
+		t.Errorf("unexpected failback: %+v", failback)
+	}
+	if !report.Active {
+		t.Error("expected the node to end active")
+	}
+
+	// One more retry attempt rides the incident out
+	cfg.Failover.RetryAttempts = 4
+	if report := simulate.Run(cfg, scenario); len(report.Decisions) != 0 {
+		t.Errorf("expected no failover with 4 retry attempts, got %+v", report.Decisions)
+	}
+}
+
+func TestRun_HeartbeatDispute(t *testing.T) {
+	path := writeFile(t, "scenario.yaml", `
+samples:
+  - height: 100
+    tip: 120
+    repeat: 3
+`)
+	cfg := testConfig()
+	scenario, err := simulate.Load(cfg, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := simulate.Run(cfg, scenario)
+	if len(report.Decisions) != 1 || report.Decisions[0].Reason != constants.ReasonHealthDisputed {
+		t.Errorf("expected a failover for a disputed health report, got %+v", report.Decisions)
+	}
+}
+
+func TestRun_HeartbeatMissed(t *testing.T) {
+	path := writeFile(t, "scenario.yaml", `
+role: passive
+samples:
+  - repeat: 2
+  - heartbeat: false
+    repeat: 5
+`)
+	cfg := testConfig()
+	cfg.Node.IsPrimary = false
+	scenario, err := simulate.Load(cfg, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := simulate.Run(cfg, scenario)
+	if len(report.Decisions) != 1 || report.Decisions[0].Action != simulate.ActionHeartbeatMissed ||
+		report.Decisions[0].Offset != 50*time.Second {
+		t.Errorf("expected one heartbeat alert at 50s, got %+v", report.Decisions)
+	}
+}
+
+func TestLoad_HistoryExport(t *testing.T) {
+	path := writeFile(t, "history.jsonl", `
+{"time":"2026-03-01T10:00:00Z","kind":"event","type":"health_check_failed","severity":"info","message":"Health check failed"}
+{"time":"2026-03-01T10:00:10Z","kind":"event","type":"health_check_failed","severity":"info","message":"Health check failed"}
+{"time":"2026-03-01T10:00:20Z","kind":"event","type":"health_check_failed","severity":"info","message":"Health check failed"}
+{"time":"2026-03-01T10:00:21Z","kind":"event","type":"failover","severity":"critical","message":"Failover complete","reason":"rpc_timeout"}
+{"time":"2026-03-01T10:00:21Z","kind":"decision","type":"failover","message":"ignored"}
+{"time":"2026-03-01T10:01:00Z","kind":"event","type":"health_changed","severity":"info","message":"Node recovered and is healthy"}
+`)
+	cfg := testConfig()
+	cfg.Node.IsPrimary = false
+	scenario, err := simulate.Load(cfg, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := simulate.Run(cfg, scenario)
+	// Three failures, then the passing checks at 30s, 40s and 50s implied
+	// before the recovery at 60s
+	if report.Checks != 7 || report.Failed != 3 {
+		t.Errorf("unexpected counts: %+v", report)
+	}
+	if len(report.Decisions) != 1 || report.Decisions[0].Offset != 20*time.Second {
+		t.Errorf("expected a failover at 20s, got %+v", report.Decisions)
+	}
+	if len(report.Actual) != 1 || report.Actual[0].Reason != constants.ReasonRPCTimeout ||
+		report.Actual[0].Offset != 21*time.Second {
+		t.Errorf("unexpected actual transitions: %+v", report.Actual)
+	}
+}
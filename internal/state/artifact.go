@@ -0,0 +1,86 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Artifact is a file handed over with the validator key, such as the
+// node's p2p identity (node_key.json) or its address book
+type Artifact struct {
+	Name string
+	Path string
+	// Encrypt seals the file with the cluster secret in transit
+	Encrypt bool
+}
+
+// Artifacts reads and writes the configured artifacts by name
+type Artifacts struct {
+	byName map[string]Artifact
+	order  []string
+}
+
+// NewArtifacts indexes artifacts by name
+func NewArtifacts(artifacts []Artifact) *Artifacts {
+	a := &Artifacts{byName: make(map[string]Artifact, len(artifacts))}
+	for _, artifact := range artifacts {
+		a.byName[artifact.Name] = artifact
+		a.order = append(a.order, artifact.Name)
+	}
+	return a
+}
+
+// List returns the artifacts in configured order
+func (a *Artifacts) List() []Artifact {
+	list := make([]Artifact, 0, len(a.order))
+	for _, name := range a.order {
+		list = append(list, a.byName[name])
+	}
+	return list
+}
+
+// Get returns the artifact called name
+func (a *Artifacts) Get(name string) (Artifact, bool) {
+	artifact, ok := a.byName[name]
+	return artifact, ok
+}
+
+// Read returns the contents of the artifact called name
+func (a *Artifacts) Read(name string) ([]byte, error) {
+	artifact, ok := a.byName[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown artifact %q", name)
+	}
+	return os.ReadFile(artifact.Path)
+}
+
+// Write replaces the artifact called name atomically, keeping the file it
+// replaces as <path>.bak
+func (a *Artifacts) Write(name string, data []byte) error {
+	artifact, ok := a.byName[name]
+	if !ok {
+		return fmt.Errorf("unknown artifact %q", name)
+	}
+
+	mode := os.FileMode(0600)
+	if info, err := os.Stat(artifact.Path); err == nil {
+		mode = info.Mode().Perm()
+		if current, err := os.ReadFile(artifact.Path); err == nil {
+			if err := os.WriteFile(artifact.Path+".bak", current, 0600); err != nil {
+				return fmt.Errorf("failed to back up %s: %w", artifact.Path, err)
+			}
+		}
+	} else if err := os.MkdirAll(filepath.Dir(artifact.Path), 0700); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", artifact.Path, err)
+	}
+
+	tmp := artifact.Path + ".tmp"
+	if err := os.WriteFile(tmp, data, mode); err != nil {
+		return fmt.Errorf("failed to write %s: %w", artifact.Path, err)
+	}
+	if err := os.Rename(tmp, artifact.Path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", artifact.Path, err)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestArtifacts_Write(t *testing.T) {
+	dir := t.TempDir()
+	nodeKey := filepath.Join(dir, "config", "node_key.json")
+	addrbook := filepath.Join(dir, "config", "addrbook.json")
+	artifacts := NewArtifacts([]Artifact{
+		{Name: "node_key", Path: nodeKey, Encrypt: true},
+		{Name: "addrbook", Path: addrbook},
+	})
+
+	if err := artifacts.Write("node_key", []byte(`{"id":"new"}`)); err != nil {
+		t.Fatalf("Write() = %v", err)
+	}
+	if got, _ := artifacts.Read("node_key"); string(got) != `{"id":"new"}` {
+		t.Errorf("Read() = %s", got)
+	}
+	info, err := os.Stat(nodeKey)
+	if err != nil {
+		t.Fatalf("Stat() = %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("new artifact mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	os.WriteFile(addrbook, []byte("old"), 0644)
+	if err := artifacts.Write("addrbook", []byte("new")); err != nil {
+		t.Fatalf("Write() = %v", err)
+	}
+	if backup, _ := os.ReadFile(addrbook + ".bak"); string(backup) != "old" {
+		t.Errorf("backup = %q, want the replaced contents", backup)
+	}
+	if info, _ := os.Stat(addrbook); info.Mode().Perm() != 0644 {
+		t.Errorf("replaced artifact mode = %v, want it kept at 0644", info.Mode().Perm())
+	}
+
+	if err := artifacts.Write("genesis", nil); err == nil {
+		t.Error("Write() of an unconfigured artifact should fail")
+	}
+	if names := artifacts.List(); len(names) != 2 || names[0].Name != "node_key" {
+		t.Errorf("List() = %v, want configured order", names)
+	}
+}
@@ -0,0 +1,108 @@
+package state
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+// Churn is how busy the validator state file was over a window
+type Churn struct {
+	WritesPerMinute  float64
+	HeightsPerMinute float64
+	// Span is how much of the window the samples cover; rates over a
+	// short span are noisy
+	Span time.Duration
+}
+
+// churnSample is one look at the state file
+type churnSample struct {
+	at     time.Time
+	write  bool
+	height int64
+}
+
+// ChurnMeter measures how often the signer writes the validator state
+// file and how fast its height advances. The caller samples it; a write
+// is a change of the file's modification time or size since the previous
+// sample, so writes closer together than the sampling interval count once.
+type ChurnMeter struct {
+	path   string
+	codec  StateCodec
+	window time.Duration
+
+	mu       sync.Mutex
+	samples  []churnSample
+	lastMod  time.Time
+	lastSize int64
+}
+
+// NewChurnMeter creates a meter for the state file at path, in the given
+// format, keeping samples for window
+func NewChurnMeter(path string, codec StateCodec, window time.Duration) *ChurnMeter {
+	return &ChurnMeter{path: path, codec: codec, window: window}
+}
+
+// Sample looks at the state file, reading its height only when it changed
+func (c *ChurnMeter) Sample(now time.Time) error {
+	info, err := os.Stat(c.path)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	sample := churnSample{at: now}
+	changed := !info.ModTime().Equal(c.lastMod) || info.Size() != c.lastSize
+	if n := len(c.samples); n > 0 {
+		sample.height = c.samples[n-1].height
+		sample.write = changed
+	}
+	if changed || len(c.samples) == 0 {
+		data, err := os.ReadFile(c.path)
+		if err != nil {
+			return err
+		}
+		s, err := c.codec.Decode(data)
+		if err != nil {
+			return err
+		}
+		sample.height = s.Height
+		c.lastMod, c.lastSize = info.ModTime(), info.Size()
+	}
+
+	c.samples = append(c.samples, sample)
+	cutoff := now.Add(-c.window)
+	drop := 0
+	for drop < len(c.samples)-1 && c.samples[drop].at.Before(cutoff) {
+		drop++
+	}
+	c.samples = c.samples[drop:]
+	return nil
+}
+
+// Rates returns the churn over the samples in the window; zero until
+// there are two
+func (c *ChurnMeter) Rates() Churn {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.samples) < 2 {
+		return Churn{}
+	}
+	first, last := c.samples[0], c.samples[len(c.samples)-1]
+	span := last.at.Sub(first.at)
+	if span <= 0 {
+		return Churn{}
+	}
+	writes := 0
+	for _, s := range c.samples[1:] {
+		if s.write {
+			writes++
+		}
+	}
+	churn := Churn{WritesPerMinute: float64(writes) / span.Minutes(), Span: span}
+	if advanced := last.height - first.height; advanced > 0 {
+		churn.HeightsPerMinute = float64(advanced) / span.Minutes()
+	}
+	return churn
+}
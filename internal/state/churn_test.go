@@ -0,0 +1,61 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestChurnMeter_Rates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "priv_validator_state.json")
+	start := time.Now()
+	write := func(height int64, at time.Time) {
+		t.Helper()
+		data := fmt.Sprintf(`{"height":"%d","round":0,"step":3}`, height)
+		if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, at, at); err != nil {
+			t.Fatal(err)
+		}
+	}
+	meter := NewChurnMeter(path, cometBFTJSON{}, time.Minute)
+
+	// A block every 10 seconds, sampled every 5: one write per block
+	for i := 0; i <= 12; i++ {
+		at := start.Add(time.Duration(i) * 5 * time.Second)
+		if i%2 == 0 {
+			write(int64(100+i/2), at)
+		}
+		if err := meter.Sample(at); err != nil {
+			t.Fatal(err)
+		}
+	}
+	churn := meter.Rates()
+	if churn.Span != time.Minute || churn.WritesPerMinute != 6 || churn.HeightsPerMinute != 6 {
+		t.Fatalf("steady blocks: got %+v", churn)
+	}
+
+	// The signer stops: after a window without writes both rates are 0
+	for i := 13; i <= 24; i++ {
+		if err := meter.Sample(start.Add(time.Duration(i) * 5 * time.Second)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	churn = meter.Rates()
+	if churn.WritesPerMinute != 0 || churn.HeightsPerMinute != 0 {
+		t.Fatalf("stalled signer: got %+v", churn)
+	}
+}
+
+func TestChurnMeter_Empty(t *testing.T) {
+	meter := NewChurnMeter(filepath.Join(t.TempDir(), "missing.json"), cometBFTJSON{}, time.Minute)
+	if err := meter.Sample(time.Now()); err == nil {
+		t.Fatal("sampling a missing state file succeeded")
+	}
+	if churn := meter.Rates(); churn != (Churn{}) {
+		t.Fatalf("no samples: got %+v", churn)
+	}
+}
@@ -0,0 +1,118 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// State formats, as set in cometbft.state_format
+const (
+	CodecCometBFTJSON = "cometbft-json"
+	CodecTMKMSTOML    = "tmkms-toml"
+)
+
+// StateCodec reads and writes the last-sign state in the format the signer
+// keeps it in. Whatever the format, the state travels between peers as a
+// ValidatorState, so nodes only need to agree on height, round and step.
+type StateCodec interface {
+	// Name is the format's cometbft.state_format value
+	Name() string
+	// FileName is the file the signer conventionally keeps the state in
+	FileName() string
+	Decode(data []byte) (*ValidatorState, error)
+	Encode(s *ValidatorState) ([]byte, error)
+}
+
+// NewStateCodec returns the codec for a cometbft.state_format value; an
+// empty name is CometBFT's JSON
+func NewStateCodec(name string) (StateCodec, error) {
+	switch name {
+	case "", CodecCometBFTJSON:
+		return cometBFTJSON{}, nil
+	case CodecTMKMSTOML:
+		return tmkmsTOML{}, nil
+	}
+	return nil, fmt.Errorf("unknown state format %q", name)
+}
+
+// cometBFTJSON is priv_validator_state.json, with the height as a string
+type cometBFTJSON struct{}
+
+func (cometBFTJSON) Name() string     { return CodecCometBFTJSON }
+func (cometBFTJSON) FileName() string { return "priv_validator_state.json" }
+
+func (cometBFTJSON) Decode(data []byte) (*ValidatorState, error) {
+	var s ValidatorState
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (cometBFTJSON) Encode(s *ValidatorState) ([]byte, error) {
+	return json.MarshalIndent(s, "", "  ")
+}
+
+// tmkmsTOML is a TOML consensus state with height and round as strings or
+// integers, as Tendermint KMS style signers write them:
+//
+//	height = "1234"
+//	round = "0"
+//	step = 3
+//	block_id = "..."
+//
+// block_id is read but not replicated: a signer finding none refuses to
+// sign again at the same height, round and step, the safe direction.
+type tmkmsTOML struct{}
+
+// tmkmsState is the TOML document; numbers may be quoted
+type tmkmsState struct {
+	Height  interface{} `toml:"height"`
+	Round   interface{} `toml:"round"`
+	Step    int8        `toml:"step"`
+	BlockID string      `toml:"block_id,omitempty"`
+}
+
+func (tmkmsTOML) Name() string     { return CodecTMKMSTOML }
+func (tmkmsTOML) FileName() string { return "state.toml" }
+
+func (tmkmsTOML) Decode(data []byte) (*ValidatorState, error) {
+	var raw tmkmsState
+	if err := toml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	height, err := tomlInt(raw.Height)
+	if err != nil {
+		return nil, fmt.Errorf("invalid height: %w", err)
+	}
+	round, err := tomlInt(raw.Round)
+	if err != nil {
+		return nil, fmt.Errorf("invalid round: %w", err)
+	}
+	return &ValidatorState{Height: height, Round: int32(round), Step: raw.Step}, nil
+}
+
+func (tmkmsTOML) Encode(s *ValidatorState) ([]byte, error) {
+	return toml.Marshal(tmkmsState{
+		Height: strconv.FormatInt(s.Height, 10),
+		Round:  strconv.FormatInt(int64(s.Round), 10),
+		Step:   s.Step,
+	})
+}
+
+// tomlInt reads an integer written either as a TOML integer or a string;
+// missing is 0
+func tomlInt(v interface{}) (int64, error) {
+	switch n := v.(type) {
+	case nil:
+		return 0, nil
+	case int64:
+		return n, nil
+	case string:
+		return strconv.ParseInt(n, 10, 64)
+	}
+	return 0, fmt.Errorf("unexpected %T", v)
+}
@@ -0,0 +1,71 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStateCodec_TMKMSTOML(t *testing.T) {
+	codec, err := NewStateCodec(CodecTMKMSTOML)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, doc := range []string{
+		"height = \"1234\"\nround = \"2\"\nstep = 3\nblock_id = \"ABCD\"\n",
+		"height = 1234\nround = 2\nstep = 3\n",
+	} {
+		s, err := codec.Decode([]byte(doc))
+		if err != nil {
+			t.Fatalf("Decode(%q): %v", doc, err)
+		}
+		if s.Height != 1234 || s.Round != 2 || s.Step != 3 {
+			t.Errorf("Decode(%q) = h=%d r=%d s=%d, want 1234/2/3", doc, s.Height, s.Round, s.Step)
+		}
+	}
+
+	if _, err := codec.Decode([]byte(`height = "tall"`)); err == nil {
+		t.Error("a non-numeric height should fail")
+	}
+
+	data, err := codec.Encode(&ValidatorState{Height: 99, Round: 1, Step: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "height = '99'") {
+		t.Errorf("height should be written as a string:\n%s", data)
+	}
+	back, err := codec.Decode(data)
+	if err != nil || back.Height != 99 || back.Round != 1 || back.Step != 2 {
+		t.Errorf("round trip = %+v, %v", back, err)
+	}
+}
+
+func TestManager_Codec(t *testing.T) {
+	tmpDir := t.TempDir()
+	statePath := filepath.Join(tmpDir, "state.toml")
+	codec, _ := NewStateCodec(CodecTMKMSTOML)
+
+	mgr := NewManager(statePath, tmpDir)
+	mgr.SetCodec(codec)
+	if err := mgr.SaveState(&ValidatorState{Height: 500, Step: 1}); err != nil {
+		t.Fatalf("SaveState: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "state.toml.bak")); err != nil {
+		t.Errorf("backup should be named after the format's file: %v", err)
+	}
+	loaded, err := mgr.LoadState()
+	if err != nil || loaded.Height != 500 {
+		t.Fatalf("LoadState = %+v, %v", loaded, err)
+	}
+
+	// The default JSON codec cannot read it
+	if _, err := NewManager(statePath, "").LoadState(); err == nil {
+		t.Error("a TOML state file should not parse as CometBFT JSON")
+	}
+	if _, err := NewStateCodec("horcrux"); err == nil {
+		t.Error("an unknown format should be rejected")
+	}
+}
@@ -0,0 +1,190 @@
+package state
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ConsulLock holds the lock as a Consul key acquired with a session. The
+// session expires when this node stops renewing it, releasing the key for
+// another node; the key's lock index is the fencing token.
+type ConsulLock struct {
+	r *remoteLock
+}
+
+// NewConsulLock creates a lock on a key in Consul's KV store
+func NewConsulLock(opts RemoteLockOptions) (*ConsulLock, error) {
+	r, err := newRemoteLock(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &ConsulLock{r: r}, nil
+}
+
+// consulKV is an entry of a KV read
+type consulKV struct {
+	LockIndex uint64
+	Session   string
+	Value     string
+}
+
+// Name returns the backend name
+func (l *ConsulLock) Name() string {
+	return "consul"
+}
+
+// Held reports whether this node's session holds the key
+func (l *ConsulLock) Held() bool {
+	return l.r.held()
+}
+
+// Acquire creates a session and takes the key with it
+func (l *ConsulLock) Acquire() error {
+	if l.r.holding() {
+		return nil
+	}
+
+	session, err := l.createSession()
+	if err != nil {
+		return err
+	}
+	acquired, err := l.acquireKey(session)
+	if err == nil && !acquired {
+		// A session left by this node before a restart still holds the key
+		if previous := l.r.previousSession(); previous != "" {
+			if kv, err := l.readKey(); err == nil && kv != nil && kv.Session == previous {
+				l.destroySession(previous)
+				acquired, err = l.acquireKey(session)
+			}
+		}
+	}
+	if err != nil || !acquired {
+		l.destroySession(session)
+		if err != nil {
+			return err
+		}
+		holder := "another session"
+		if kv, err := l.readKey(); err == nil && kv != nil {
+			holder = fmt.Sprintf("%s (session %s)", decodeOwner(kv.Value), kv.Session)
+		}
+		return fmt.Errorf("%w: consul key %s is held by %s", ErrLockHeld, l.r.opts.Key, holder)
+	}
+
+	var token uint64
+	if kv, err := l.readKey(); err == nil && kv != nil {
+		token = kv.LockIndex
+	}
+	l.r.hold(session, token, l.renewSession)
+	return nil
+}
+
+// Release gives up the key, then ends the session
+func (l *ConsulLock) Release() error {
+	session := l.r.drop()
+	if session == "" {
+		return nil
+	}
+	status, err := l.r.call(http.MethodPut, "/v1/kv/"+l.r.opts.Key+"?release="+session, l.headers(), nil, nil)
+	if err == nil && status != http.StatusOK {
+		err = fmt.Errorf("consul answered %d releasing %s", status, l.r.opts.Key)
+	}
+	// A session that is not destroyed expires with its TTL
+	l.destroySession(session)
+	if err != nil {
+		return fmt.Errorf("failed to release lock: %w", err)
+	}
+	return nil
+}
+
+// Check reports a lost session, or whether a Consul server with a leader
+// answers
+func (l *ConsulLock) Check() error {
+	if err := l.r.checkHeld(); err != nil {
+		return err
+	}
+	var leader string
+	status, err := l.r.call(http.MethodGet, "/v1/status/leader", l.headers(), nil, &leader)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK || leader == "" {
+		return fmt.Errorf("%w: consul has no leader (status %d)", ErrLockUnreachable, status)
+	}
+	return nil
+}
+
+// createSession opens a session released with its keys when it expires
+func (l *ConsulLock) createSession() (string, error) {
+	var created struct{ ID string }
+	status, err := l.r.call(http.MethodPut, "/v1/session/create", l.headers(), map[string]string{
+		"Name":     "syncguard-" + l.r.opts.Owner,
+		"TTL":      l.r.opts.TTL.String(),
+		"Behavior": "release",
+	}, &created)
+	if err != nil {
+		return "", err
+	}
+	if status != http.StatusOK || created.ID == "" {
+		return "", fmt.Errorf("%w: consul answered %d creating a session", ErrLockUnreachable, status)
+	}
+	return created.ID, nil
+}
+
+// acquireKey takes the key for session; false when another session holds it
+func (l *ConsulLock) acquireKey(session string) (bool, error) {
+	var acquired bool
+	status, err := l.r.call(http.MethodPut, "/v1/kv/"+l.r.opts.Key+"?acquire="+session, l.headers(), l.r.opts.Owner, &acquired)
+	if err != nil {
+		return false, err
+	}
+	if status != http.StatusOK {
+		return false, fmt.Errorf("%w: consul answered %d acquiring %s", ErrLockUnreachable, status, l.r.opts.Key)
+	}
+	return acquired, nil
+}
+
+// readKey returns the key's entry; nil when it does not exist
+func (l *ConsulLock) readKey() (*consulKV, error) {
+	var entries []consulKV
+	status, err := l.r.call(http.MethodGet, "/v1/kv/"+l.r.opts.Key, l.headers(), nil, &entries)
+	if err != nil {
+		return nil, err
+	}
+	if status == http.StatusNotFound || len(entries) == 0 {
+		return nil, nil
+	}
+	return &entries[0], nil
+}
+
+// renewSession extends the session; false once Consul no longer knows it
+func (l *ConsulLock) renewSession(session string) (bool, error) {
+	status, err := l.r.call(http.MethodPut, "/v1/session/renew/"+session, l.headers(), nil, nil)
+	if err != nil {
+		return false, err
+	}
+	return status != http.StatusNotFound, nil
+}
+
+// destroySession ends a session, releasing any key it holds
+func (l *ConsulLock) destroySession(session string) {
+	l.r.call(http.MethodPut, "/v1/session/destroy/"+session, l.headers(), nil, nil)
+}
+
+func (l *ConsulLock) headers() map[string]string {
+	if l.r.opts.Token == "" {
+		return nil
+	}
+	return map[string]string{"X-Consul-Token": l.r.opts.Token}
+}
+
+// decodeOwner reads the owner out of a base64 value as Consul and etcd
+// return it
+func decodeOwner(value string) string {
+	owner, err := base64.StdEncoding.DecodeString(value)
+	if err != nil || len(owner) == 0 {
+		return "unknown owner"
+	}
+	return strings.Trim(string(owner), `"`)
+}
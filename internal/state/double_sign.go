@@ -1,17 +1,21 @@
 package state
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"sync"
 	"time"
 )
 
 // SignatureRecord tracks what we've signed to prevent double-signing
 type SignatureRecord struct {
-	Height    int64
-	Round     int32
-	Step      int8
-	Timestamp time.Time
+	Height    int64     `json:"height"`
+	Round     int32     `json:"round"`
+	Step      int8      `json:"step"`
+	Timestamp time.Time `json:"timestamp"`
 }
 
 // DoubleSignProtector prevents double-signing by tracking signed blocks
@@ -22,6 +26,8 @@ type DoubleSignProtector struct {
 	maxRecords      int
 	pruneInterval   time.Duration
 	stopCh          chan struct{}
+	// path keeps the records across restarts; "" keeps them in memory
+	path string
 }
 
 // NewDoubleSignProtector creates a new double-sign prevention mechanism
@@ -38,12 +44,42 @@ func NewDoubleSignProtector() *DoubleSignProtector {
 	return dsp
 }
 
+// LoadDoubleSignProtector creates a protector whose records are kept in
+// the given file, starting from those saved there
+func LoadDoubleSignProtector(path string) (*DoubleSignProtector, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read signature records: %w", err)
+	}
+	var records []*SignatureRecord
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &records); err != nil {
+			return nil, fmt.Errorf("failed to parse signature records: %w", err)
+		}
+	}
+
+	dsp := NewDoubleSignProtector()
+	dsp.path = path
+	for _, record := range records {
+		dsp.signedRecords[recordKey(record.Height, record.Round, record.Step)] = record
+		if record.Height > dsp.lastSignedBlock {
+			dsp.lastSignedBlock = record.Height
+		}
+	}
+	return dsp, nil
+}
+
+// recordKey identifies a signature by height, round and step
+func recordKey(height int64, round int32, step int8) string {
+	return fmt.Sprintf("%d:%d:%d", height, round, step)
+}
+
 // CanSign checks if it's safe to sign at the given height/round/step
 func (dsp *DoubleSignProtector) CanSign(height int64, round int32, step int8) (bool, error) {
 	dsp.mu.RLock()
 	defer dsp.mu.RUnlock()
 
-	key := fmt.Sprintf("%d:%d:%d", height, round, step)
+	key := recordKey(height, round, step)
 	if record, exists := dsp.signedRecords[key]; exists {
 		return false, fmt.Errorf("already signed at height %d, round %d, step %d at %v",
 			height, round, step, record.Timestamp)
@@ -73,10 +109,65 @@ func (dsp *DoubleSignProtector) RecordSignature(height int64, round int32, step
 	dsp.mu.Lock()
 	defer dsp.mu.Unlock()
 
-	key := fmt.Sprintf("%d:%d:%d", height, round, step)
+	key := recordKey(height, round, step)
 	if _, exists := dsp.signedRecords[key]; exists {
 		return fmt.Errorf("signature already recorded for %s", key)
 	}
+	return dsp.recordLocked(key, height, round, step)
+}
+
+// ObserveState records the last signature of a validator state read from
+// the signer, unless it is recorded already or behind the last one
+func (dsp *DoubleSignProtector) ObserveState(state *ValidatorState) error {
+	dsp.mu.Lock()
+	defer dsp.mu.Unlock()
+
+	key := recordKey(state.Height, state.Round, state.Step)
+	if _, exists := dsp.signedRecords[key]; exists || state.Height == 0 || state.Height < dsp.lastSignedBlock {
+		return nil
+	}
+	return dsp.recordLocked(key, state.Height, state.Round, state.Step)
+}
+
+// CheckState returns an error when a validator state, the one a signer
+// would resume from, is behind the last signature recorded: the signer
+// could then sign again where we already signed
+func (dsp *DoubleSignProtector) CheckState(state *ValidatorState) error {
+	dsp.mu.RLock()
+	defer dsp.mu.RUnlock()
+
+	last := dsp.lastRecordLocked()
+	if last == nil {
+		return nil
+	}
+	behind := state.Height < last.Height ||
+		(state.Height == last.Height && (state.Round < last.Round ||
+			(state.Round == last.Round && state.Step < last.Step)))
+	if behind {
+		return fmt.Errorf("validator state (h=%d,r=%d,s=%d) is behind the last recorded signature (h=%d,r=%d,s=%d) at %s",
+			state.Height, state.Round, state.Step, last.Height, last.Round, last.Step,
+			last.Timestamp.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// lastRecordLocked returns the highest signature recorded; the caller
+// holds dsp.mu
+func (dsp *DoubleSignProtector) lastRecordLocked() *SignatureRecord {
+	var last *SignatureRecord
+	for _, record := range dsp.signedRecords {
+		if last == nil || record.Height > last.Height ||
+			(record.Height == last.Height && (record.Round > last.Round ||
+				(record.Round == last.Round && record.Step > last.Step))) {
+			last = record
+		}
+	}
+	return last
+}
+
+// recordLocked adds a signature and saves the records; the caller holds
+// dsp.mu
+func (dsp *DoubleSignProtector) recordLocked(key string, height int64, round int32, step int8) error {
 
 	dsp.signedRecords[key] = &SignatureRecord{
 		Height:    height,
@@ -93,6 +184,49 @@ func (dsp *DoubleSignProtector) RecordSignature(height int64, round int32, step
 		dsp.pruneOldRecordsLocked()
 	}
 
+	return dsp.saveLocked()
+}
+
+// saveLocked writes the records within retainHeights of the last one to
+// the file, if any. It is synced before it replaces the old one, so a
+// power loss leaves one or the other. The caller holds dsp.mu.
+func (dsp *DoubleSignProtector) saveLocked() error {
+	if dsp.path == "" {
+		return nil
+	}
+	records := make([]*SignatureRecord, 0, len(dsp.signedRecords))
+	for _, record := range dsp.signedRecords {
+		if record.Height >= dsp.lastSignedBlock-retainHeights {
+			records = append(records, record)
+		}
+	}
+	data, err := json.Marshal(records)
+	if err != nil {
+		return fmt.Errorf("failed to marshal signature records: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(dsp.path), 0700); err != nil {
+		return fmt.Errorf("failed to create signature record directory: %w", err)
+	}
+
+	tmpFile := dsp.path + ".tmp"
+	f, err := os.OpenFile(tmpFile, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write temp signature records: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write temp signature records: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to sync temp signature records: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write temp signature records: %w", err)
+	}
+	if err := os.Rename(tmpFile, dsp.path); err != nil {
+		return fmt.Errorf("failed to rename signature records: %w", err)
+	}
 	return nil
 }
 
@@ -118,13 +252,16 @@ func (dsp *DoubleSignProtector) pruneOldRecords() {
 	}
 }
 
+// retainHeights is how far below the last signed height records are kept
+const retainHeights = 1000
+
 // pruneOldRecordsLocked removes records older than the retention window
 func (dsp *DoubleSignProtector) pruneOldRecordsLocked() {
 	if len(dsp.signedRecords) <= dsp.maxRecords/2 {
 		return
 	}
 
-	minHeight := dsp.lastSignedBlock - 1000
+	minHeight := dsp.lastSignedBlock - retainHeights
 	if minHeight < 0 {
 		minHeight = 0
 	}
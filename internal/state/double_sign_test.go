@@ -1,6 +1,7 @@
 package state
 
 import (
+	"path/filepath"
 	"testing"
 )
 
@@ -54,3 +55,42 @@ func TestDoubleSignProtector_ValidStepProgression(t *testing.T) {
 		t.Errorf("Valid step progression should be allowed: canSign=%v, err=%v", canSign, err)
 	}
 }
+
+func TestDoubleSignProtector_PersistsRecords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "signatures.json")
+	protector, err := LoadDoubleSignProtector(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []ValidatorState{{Height: 1000, Round: 0, Step: 3}, {Height: 1001, Round: 1, Step: 2}, {Height: 999}} {
+		if err := protector.ObserveState(&s); err != nil {
+			t.Fatalf("ObserveState(%+v): %v", s, err)
+		}
+	}
+	protector.Stop()
+
+	// A restart keeps the records
+	protector, err = LoadDoubleSignProtector(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer protector.Stop()
+	if got := protector.GetLastSignedHeight(); got != 1001 {
+		t.Errorf("last signed height %d after restart, want 1001", got)
+	}
+
+	for _, tc := range []struct {
+		state ValidatorState
+		ok    bool
+	}{
+		{ValidatorState{Height: 1001, Round: 1, Step: 2}, true},
+		{ValidatorState{Height: 1002}, true},
+		{ValidatorState{Height: 1001, Round: 1, Step: 1}, false},
+		{ValidatorState{Height: 1001, Round: 0, Step: 3}, false},
+		{ValidatorState{Height: 1000, Round: 5, Step: 3}, false},
+	} {
+		if err := protector.CheckState(&tc.state); (err == nil) != tc.ok {
+			t.Errorf("CheckState(%+v) = %v, want ok=%v", tc.state, err, tc.ok)
+		}
+	}
+}
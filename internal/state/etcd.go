@@ -0,0 +1,244 @@
+package state
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// EtcdLock holds the lock as an etcd key attached to a lease, through
+// etcd's JSON gateway (/v3). The key goes away with the lease when this
+// node stops renewing it; the revision that created the key is the
+// fencing token.
+type EtcdLock struct {
+	r *remoteLock
+}
+
+// NewEtcdLock creates a lock on a key in etcd
+func NewEtcdLock(opts RemoteLockOptions) (*EtcdLock, error) {
+	r, err := newRemoteLock(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &EtcdLock{r: r}, nil
+}
+
+// etcdInt is an int64 the gateway writes as a string
+type etcdInt int64
+
+func (n *etcdInt) UnmarshalJSON(data []byte) error {
+	v, err := strconv.ParseInt(strings.Trim(string(data), `"`), 10, 64)
+	if err != nil {
+		return err
+	}
+	*n = etcdInt(v)
+	return nil
+}
+
+// etcdKV is a key as a range returns it
+type etcdKV struct {
+	Value string  `json:"value"`
+	Lease etcdInt `json:"lease"`
+}
+
+// Name returns the backend name
+func (l *EtcdLock) Name() string {
+	return "etcd"
+}
+
+// Held reports whether this node's lease holds the key
+func (l *EtcdLock) Held() bool {
+	return l.r.held()
+}
+
+// Acquire grants a lease and creates the key with it, unless the key
+// exists
+func (l *EtcdLock) Acquire() error {
+	if l.r.holding() {
+		return nil
+	}
+	headers, err := l.headers()
+	if err != nil {
+		return err
+	}
+
+	var granted struct {
+		ID etcdInt `json:"ID"`
+	}
+	status, err := l.r.call(http.MethodPost, "/v3/lease/grant", headers,
+		map[string]int64{"TTL": int64(l.r.opts.TTL.Seconds())}, &granted)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK || granted.ID == 0 {
+		return fmt.Errorf("%w: etcd answered %d granting a lease", ErrLockUnreachable, status)
+	}
+	lease := strconv.FormatInt(int64(granted.ID), 10)
+
+	revision, holder, err := l.createKey(headers, lease)
+	if err == nil && revision == 0 && holder != nil {
+		// A lease left by this node before a restart still holds the key
+		if previous := l.r.previousSession(); previous != "" && strconv.FormatInt(int64(holder.Lease), 10) == previous {
+			l.revoke(headers, previous)
+			revision, holder, err = l.createKey(headers, lease)
+		}
+	}
+	if err != nil || revision == 0 {
+		l.revoke(headers, lease)
+		if err != nil {
+			return err
+		}
+		owner := "another node"
+		if holder != nil {
+			owner = fmt.Sprintf("%s (lease %d)", decodeOwner(holder.Value), holder.Lease)
+		}
+		return fmt.Errorf("%w: etcd key %s is held by %s", ErrLockHeld, l.r.opts.Key, owner)
+	}
+
+	l.r.hold(lease, uint64(revision), l.keepAlive)
+	return nil
+}
+
+// Release revokes the lease, which deletes the key
+func (l *EtcdLock) Release() error {
+	lease := l.r.drop()
+	if lease == "" {
+		return nil
+	}
+	headers, err := l.headers()
+	if err != nil {
+		return fmt.Errorf("failed to release lock: %w", err)
+	}
+	if err := l.revoke(headers, lease); err != nil {
+		return fmt.Errorf("failed to release lock: %w", err)
+	}
+	return nil
+}
+
+// Check reports a lost lease, or whether an etcd member answers
+func (l *EtcdLock) Check() error {
+	if err := l.r.checkHeld(); err != nil {
+		return err
+	}
+	headers, err := l.headers()
+	if err != nil {
+		return err
+	}
+	status, err := l.r.call(http.MethodPost, "/v3/maintenance/status", headers, struct{}{}, nil)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("%w: etcd answered %d to a status request", ErrLockUnreachable, status)
+	}
+	return nil
+}
+
+// createKey creates the key with lease if it does not exist. It returns
+// the revision that created it, or 0 and the key holding it.
+func (l *EtcdLock) createKey(headers map[string]string, lease string) (etcdInt, *etcdKV, error) {
+	key := base64.StdEncoding.EncodeToString([]byte(l.r.opts.Key))
+	txn := map[string]interface{}{
+		"compare": []map[string]string{{
+			"key": key, "result": "EQUAL", "target": "CREATE", "create_revision": "0",
+		}},
+		"success": []map[string]interface{}{{
+			"request_put": map[string]string{
+				"key": key, "value": base64.StdEncoding.EncodeToString([]byte(l.r.opts.Owner)), "lease": lease,
+			},
+		}},
+		"failure": []map[string]interface{}{{
+			"request_range": map[string]string{"key": key},
+		}},
+	}
+	var resp struct {
+		Header struct {
+			Revision etcdInt `json:"revision"`
+		} `json:"header"`
+		Succeeded bool `json:"succeeded"`
+		Responses []struct {
+			Range struct {
+				KVs []etcdKV `json:"kvs"`
+			} `json:"response_range"`
+		} `json:"responses"`
+	}
+	status, err := l.r.call(http.MethodPost, "/v3/kv/txn", headers, txn, &resp)
+	if err != nil {
+		return 0, nil, err
+	}
+	if status != http.StatusOK {
+		return 0, nil, fmt.Errorf("%w: etcd answered %d creating %s", ErrLockUnreachable, status, l.r.opts.Key)
+	}
+	if resp.Succeeded {
+		return resp.Header.Revision, nil, nil
+	}
+	if len(resp.Responses) > 0 && len(resp.Responses[0].Range.KVs) > 0 {
+		return 0, &resp.Responses[0].Range.KVs[0], nil
+	}
+	return 0, nil, nil
+}
+
+// keepAlive renews the lease; false once etcd no longer knows it
+func (l *EtcdLock) keepAlive(lease string) (bool, error) {
+	headers, err := l.headers()
+	if err != nil {
+		return false, err
+	}
+	var resp struct {
+		Result struct {
+			TTL etcdInt `json:"TTL"`
+		} `json:"result"`
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	status, err := l.r.call(http.MethodPost, "/v3/lease/keepalive", headers, map[string]string{"ID": lease}, &resp)
+	if err != nil {
+		return false, err
+	}
+	if resp.Error != nil {
+		if strings.Contains(resp.Error.Message, "not found") {
+			return false, nil
+		}
+		return false, fmt.Errorf("etcd keepalive: %s", resp.Error.Message)
+	}
+	if status != http.StatusOK {
+		return false, fmt.Errorf("etcd answered %d to a keepalive", status)
+	}
+	return resp.Result.TTL > 0, nil
+}
+
+// revoke ends a lease, deleting the keys attached to it
+func (l *EtcdLock) revoke(headers map[string]string, lease string) error {
+	status, err := l.r.call(http.MethodPost, "/v3/lease/revoke", headers, map[string]string{"ID": lease}, nil)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK && status != http.StatusNotFound {
+		return fmt.Errorf("etcd answered %d revoking lease %s", status, lease)
+	}
+	return nil
+}
+
+// headers authenticates when a username is set; etcd tokens are short
+// lived, so each call gets a fresh one
+func (l *EtcdLock) headers() (map[string]string, error) {
+	if l.r.opts.Username == "" {
+		return nil, nil
+	}
+	var auth struct {
+		Token string `json:"token"`
+	}
+	status, err := l.r.call(http.MethodPost, "/v3/auth/authenticate", nil, map[string]string{
+		"name": l.r.opts.Username, "password": l.r.opts.Password,
+	}, &auth)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK || auth.Token == "" {
+		return nil, fmt.Errorf("%w: etcd refused authentication (status %d)", ErrLockUnreachable, status)
+	}
+	return map[string]string{"Authorization": auth.Token}, nil
+}
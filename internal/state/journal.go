@@ -0,0 +1,214 @@
+package state
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Transitions recorded in the journal
+const (
+	// TransitionRelease gives up signing: disable the key, release the lock
+	TransitionRelease = "release"
+	// TransitionAcquire takes over signing: get the key, acquire the lock
+	TransitionAcquire = "acquire"
+)
+
+// Steps of a transition
+const (
+	StepTransferKey = "transfer_key"
+	StepFetchKey    = "fetch_key"
+	StepDisableKey  = "disable_key"
+	StepAcquireLock = "acquire_lock"
+	StepReleaseLock = "release_lock"
+	StepSyncState   = "sync_state"
+	StepRestartNode = "restart_node"
+	StepNotifyPeer  = "notify_peer"
+)
+
+// Journal record phases
+const (
+	phaseBegin  = "begin"
+	phaseIntent = "intent"
+	phaseDone   = "done"
+)
+
+// journalRecord is one line of the journal
+type journalRecord struct {
+	Phase      string    `json:"phase"`
+	Transition string    `json:"transition,omitempty"`
+	Step       string    `json:"step,omitempty"`
+	Time       time.Time `json:"time"`
+}
+
+// Incomplete is a transition that was interrupted before it ended
+type Incomplete struct {
+	Transition string
+	Started    time.Time
+	// Done lists the steps known to have completed, in order
+	Done []string
+	// InFlight is the step that was about to run or running, if any
+	InFlight string
+}
+
+// Reached reports whether a step was at least started
+func (i *Incomplete) Reached(step string) bool {
+	if i.InFlight == step {
+		return true
+	}
+	for _, done := range i.Done {
+		if done == step {
+			return true
+		}
+	}
+	return false
+}
+
+// Journal is a write-ahead log of failover transitions. Before each
+// side-effecting step an intent record is synced to disk, so after a crash
+// the node knows exactly how far a transition got. Only one transition is
+// in progress at a time; ending it removes the file.
+type Journal struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewJournal creates a journal at the given path
+func NewJournal(path string) *Journal {
+	return &Journal{path: path}
+}
+
+// Begin starts a transition, discarding any previous records
+func (j *Journal) Begin(transition string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(j.path), 0700); err != nil {
+		return fmt.Errorf("failed to create journal directory: %w", err)
+	}
+	if err := os.Remove(j.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to reset journal: %w", err)
+	}
+	return j.appendLocked(journalRecord{Phase: phaseBegin, Transition: transition})
+}
+
+// Intend records that a step is about to run
+func (j *Journal) Intend(step string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.appendLocked(journalRecord{Phase: phaseIntent, Step: step})
+}
+
+// Done records that a step completed
+func (j *Journal) Done(step string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.appendLocked(journalRecord{Phase: phaseDone, Step: step})
+}
+
+// Step records the intent to run a step, runs it and records that it
+// completed. The step does not run when its intent cannot be recorded.
+func (j *Journal) Step(step string, fn func() error) error {
+	if err := j.Intend(step); err != nil {
+		return err
+	}
+	if err := fn(); err != nil {
+		return err
+	}
+	return j.Done(step)
+}
+
+// Release runs a step that gives up signing. Unlike Step it runs even
+// when the intent cannot be recorded, since not signing is always safe;
+// a journal error is returned only when the step itself succeeded.
+func (j *Journal) Release(step string, fn func() error) error {
+	intentErr := j.Intend(step)
+	if err := fn(); err != nil {
+		return err
+	}
+	if intentErr != nil {
+		return intentErr
+	}
+	return j.Done(step)
+}
+
+// End closes the transition
+func (j *Journal) End() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err := os.Remove(j.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to clear journal: %w", err)
+	}
+	return nil
+}
+
+// appendLocked writes one record and syncs it to disk
+func (j *Journal) appendLocked(rec journalRecord) error {
+	rec.Time = time.Now().UTC()
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to marshal journal record: %w", err)
+	}
+
+	f, err := os.OpenFile(j.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		return fmt.Errorf("failed to sync journal: %w", err)
+	}
+	return nil
+}
+
+// Incomplete returns the interrupted transition, or nil when none is. A
+// torn last line, from a crash mid-write, is ignored.
+func (j *Journal) Incomplete() (*Incomplete, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	f, err := os.Open(j.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer f.Close()
+
+	var inc *Incomplete
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var rec journalRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			break
+		}
+		switch rec.Phase {
+		case phaseBegin:
+			inc = &Incomplete{Transition: rec.Transition, Started: rec.Time}
+		case phaseIntent:
+			if inc != nil {
+				inc.InFlight = rec.Step
+			}
+		case phaseDone:
+			if inc != nil {
+				inc.Done = append(inc.Done, rec.Step)
+				if inc.InFlight == rec.Step {
+					inc.InFlight = ""
+				}
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+	return inc, nil
+}
@@ -0,0 +1,81 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestJournal_Incomplete(t *testing.T) {
+	j := NewJournal(filepath.Join(t.TempDir(), "data", "transitions.journal"))
+
+	if inc, err := j.Incomplete(); err != nil || inc != nil {
+		t.Fatalf("expected no incomplete transition, got %+v, %v", inc, err)
+	}
+
+	j.Begin(TransitionRelease)
+	j.Intend(StepTransferKey)
+	j.Done(StepTransferKey)
+	j.Intend(StepDisableKey)
+
+	inc, err := j.Incomplete()
+	if err != nil {
+		t.Fatalf("Incomplete failed: %v", err)
+	}
+	if inc == nil || inc.Transition != TransitionRelease {
+		t.Fatalf("expected an incomplete release, got %+v", inc)
+	}
+	if !reflect.DeepEqual(inc.Done, []string{StepTransferKey}) || inc.InFlight != StepDisableKey {
+		t.Errorf("unexpected progress: %+v", inc)
+	}
+	if !inc.Reached(StepDisableKey) || inc.Reached(StepReleaseLock) {
+		t.Errorf("Reached is wrong for %+v", inc)
+	}
+
+	if err := j.End(); err != nil {
+		t.Fatalf("End failed: %v", err)
+	}
+	if inc, _ := j.Incomplete(); inc != nil {
+		t.Errorf("expected no incomplete transition after End, got %+v", inc)
+	}
+}
+
+func TestJournal_TornWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "transitions.journal")
+	j := NewJournal(path)
+
+	j.Begin(TransitionAcquire)
+	j.Intend(StepAcquireLock)
+	j.Done(StepAcquireLock)
+
+	// A crash in the middle of writing the next record
+	f, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	f.WriteString(`{"phase":"intent","st`)
+	f.Close()
+
+	inc, err := j.Incomplete()
+	if err != nil {
+		t.Fatalf("Incomplete failed: %v", err)
+	}
+	if inc == nil || inc.InFlight != "" || !inc.Reached(StepAcquireLock) {
+		t.Errorf("expected the torn record to be ignored, got %+v", inc)
+	}
+}
+
+func TestJournal_StepWithoutJournal(t *testing.T) {
+	// A directory in place of the journal file makes every write fail
+	path := filepath.Join(t.TempDir(), "transitions.journal")
+	os.MkdirAll(filepath.Join(path, "blocked"), 0700)
+	j := NewJournal(path)
+
+	ran := false
+	if err := j.Step(StepAcquireLock, func() error { ran = true; return nil }); err == nil || ran {
+		t.Error("Step must not act when its intent cannot be recorded")
+	}
+
+	ran = false
+	if err := j.Release(StepDisableKey, func() error { ran = true; return nil }); err == nil || !ran {
+		t.Error("Release must act, and report the journal error, when the intent cannot be recorded")
+	}
+}
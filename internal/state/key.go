@@ -26,19 +26,57 @@ type ValidatorKey struct {
 type KeyManager struct {
 	keyPath    string
 	backupPath string
+	owner      int
+	insecure   string
 	logger     *logger.Logger
 }
 
-// NewKeyManager creates a new key manager
+// NewKeyManager creates a new key manager. Key files it writes are mode
+// 0600; see SetKeyFilePolicy for the owner and for existing files.
 func NewKeyManager(keyPath string, backupPath string, logger *logger.Logger) *KeyManager {
 
 	return &KeyManager{
 		keyPath:    keyPath,
 		backupPath: backupPath,
+		owner:      -1,
+		insecure:   InsecureFix,
 		logger:     logger,
 	}
 }
 
+// SetKeyFilePolicy sets the uid key files must belong to (-1 for any) and
+// what SecureKeyFiles does with insecure ones
+func (km *KeyManager) SetKeyFilePolicy(insecure string, owner int) {
+	km.insecure = insecure
+	km.owner = owner
+}
+
+// keyFiles lists every file that may hold key material
+func (km *KeyManager) keyFiles() []string {
+	files := []string{km.keyPath, km.realPath(), km.keyPath + ".disabled", km.keyPath + ".tmp"}
+	if km.backupPath != "" {
+		files = append(files, km.backupFile())
+	}
+	return files
+}
+
+// backupFile is where BackupKey writes the key
+func (km *KeyManager) backupFile() string {
+	return km.backupPath + "/priv_validator_key.json.bak"
+}
+
+// SecureKeyFiles checks the mode and owner of every existing key file and
+// applies the policy; with InsecureRefuse the first insecure file is an
+// error
+func (km *KeyManager) SecureKeyFiles() error {
+	for _, path := range km.keyFiles() {
+		if err := km.secureKeyFile(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
 // LoadKey reads the validator key from disk
 func (km *KeyManager) LoadKey() (*ValidatorKey, error) {
 	data, err := os.ReadFile(km.keyPath)
@@ -54,6 +92,15 @@ func (km *KeyManager) LoadKey() (*ValidatorKey, error) {
 	return &key, nil
 }
 
+// LoadRealKey reads the validator key, looking past the mock key that
+// DeleteKey swaps in on a passive node
+func (km *KeyManager) LoadRealKey() (*ValidatorKey, error) {
+	if _, err := os.Stat(km.realPath()); err == nil {
+		return (&KeyManager{keyPath: km.realPath()}).LoadKey()
+	}
+	return km.LoadKey()
+}
+
 // SaveKey writes the validator key to disk
 func (km *KeyManager) SaveKey(key *ValidatorKey) error {
 	data, err := json.MarshalIndent(key, "", "  ")
@@ -63,7 +110,7 @@ func (km *KeyManager) SaveKey(key *ValidatorKey) error {
 
 	// Write to temp file first
 	tmpFile := km.keyPath + ".tmp"
-	if err := os.WriteFile(tmpFile, data, 0600); err != nil {
+	if err := km.writeKeyFile(tmpFile, data); err != nil {
 		return fmt.Errorf("failed to write temp key file: %w", err)
 	}
 
@@ -91,48 +138,63 @@ func (km *KeyManager) BackupKey() error {
 		return fmt.Errorf("failed to marshal key: %w", err)
 	}
 
-	backupFile := km.backupPath + "/priv_validator_key.json.bak"
-	if err := os.WriteFile(backupFile, data, 0600); err != nil {
+	if err := km.writeKeyFile(km.backupFile(), data); err != nil {
 		return fmt.Errorf("failed to write backup key: %w", err)
 	}
 
 	return nil
 }
 
-// DeleteKey disables signing by swapping real key with auto-generated mock key
-func (km *KeyManager) DeleteKey() error {
-	// Backup first
-	if err := km.BackupKey(); err != nil {
-		return fmt.Errorf("failed to backup before delete: %w", err)
-	}
+// mockKeyAddress is the address of the mock key DeleteKey swaps in
+const mockKeyAddress = "48DC218393FCEEF56A37D963B804FAB92C62CA9D"
 
-	// Save real key to .real
-	realKeyPath := km.keyPath + ".real"
-	if err := os.Rename(km.keyPath, realKeyPath); err != nil {
-		return fmt.Errorf("failed to save real key: %w", err)
-	}
+// IsDisabled reports whether the key on disk is the mock key
+func (km *KeyManager) IsDisabled() bool {
+	key, err := km.LoadKey()
+	return err == nil && IsMockKey(key)
+}
+
+// IsMockKey reports whether key is the mock key, which cannot sign
+func IsMockKey(key *ValidatorKey) bool {
+	return key.Address == mockKeyAddress
+}
 
-	// Generate mock key with dummy values (different address prevents signing)
-	mockKey := &ValidatorKey{
-		Address: "48DC218393FCEEF56A37D963B804FAB92C62CA9D",
+// mockKey is swapped in for the real key; its address differs from any
+// validator's, so the node cannot sign
+func mockKey() *ValidatorKey {
+	return &ValidatorKey{
+		Address: mockKeyAddress,
 		PubKey:  json.RawMessage(`{"type":"tendermint/PubKeySecp256k1","value":"AvLo+lkg0UWozoI+pJzv1a7upt+HaMxZCdWgRxvZ8Cb1"}`),
 		PrivKey: json.RawMessage(`{"type":"tendermint/PrivKeySecp256k1","value":"ansj9FenmlrmNrxi0BXgZ+YfJBSGZqy20i7/K7CdOiQ="}`),
 	}
+}
 
-	mockData, err := json.MarshalIndent(mockKey, "", "  ")
+// DeleteKey disables signing by parking the real key in .real and putting
+// the mock key in its place. The swap is journaled (see RecoverKeySwap).
+func (km *KeyManager) DeleteKey() error {
+	key, err := km.LoadKey()
 	if err != nil {
-		// Rollback
-		os.Rename(realKeyPath, km.keyPath)
-		return fmt.Errorf("failed to marshal mock key: %w", err)
+		return err
+	}
+	// Parking the mock key would overwrite the real one in .real
+	if key.Address == mockKeyAddress {
+		return nil
 	}
 
-	if err := os.WriteFile(km.keyPath, mockData, 0600); err != nil {
-		// Rollback
-		os.Rename(realKeyPath, km.keyPath)
-		return fmt.Errorf("failed to write mock key: %w", err)
+	// Backup first
+	if err := km.BackupKey(); err != nil {
+		return fmt.Errorf("failed to backup before delete: %w", err)
 	}
 
-	return nil
+	return km.swap(swapDisable, key.Address, func() error {
+		if err := os.Rename(km.keyPath, km.realPath()); err != nil {
+			return fmt.Errorf("failed to save real key: %w", err)
+		}
+		if err := km.SaveKey(mockKey()); err != nil {
+			return fmt.Errorf("failed to write mock key: %w", err)
+		}
+		return nil
+	})
 }
 
 func (km *KeyManager) InitializeKey() error {
@@ -174,17 +236,15 @@ func (km *KeyManager) InitializeKey() error {
 
 // RestoreKey restores the validator key from .real (mock swap) or .disabled
 func (km *KeyManager) RestoreKey() error {
-	// Try .real first (mock key swap was used)
-	realKeyPath := km.keyPath + ".real"
-	if _, err := os.Stat(realKeyPath); err == nil {
-		// Remove current mock key and restore real key
-		if err := os.Remove(km.keyPath); err != nil && !os.IsNotExist(err) {
-			return fmt.Errorf("failed to remove mock key: %w", err)
-		}
-		if err := os.Rename(realKeyPath, km.keyPath); err != nil {
-			return fmt.Errorf("failed to restore real key: %w", err)
-		}
-		return nil
+	// Try .real first (mock key swap was used). The rename replaces the
+	// mock key in one step, so there is no moment without a key file.
+	if real, err := (&KeyManager{keyPath: km.realPath()}).LoadKey(); err == nil {
+		return km.swap(swapRestore, real.Address, func() error {
+			if err := os.Rename(km.realPath(), km.keyPath); err != nil {
+				return fmt.Errorf("failed to restore real key: %w", err)
+			}
+			return nil
+		})
 	}
 
 	// Fallback: try .disabled
@@ -211,6 +271,15 @@ func (km *KeyManager) KeyToBytes() ([]byte, error) {
 	return os.ReadFile(km.keyPath)
 }
 
+// RealKeyToBytes serializes the key for transfer, also once DeleteKey has
+// parked it in .real
+func (km *KeyManager) RealKeyToBytes() ([]byte, error) {
+	if km.IsDisabled() {
+		return os.ReadFile(km.realPath())
+	}
+	return km.KeyToBytes()
+}
+
 // EncryptKeyToBytes encrypts the key for transfer
 func (km *KeyManager) EncryptKeyToBytes(secret string) ([]byte, error) {
 	keyData, err := km.KeyToBytes()
@@ -29,8 +29,7 @@ func newTestKeyManager(t *testing.T) *KeyManager {
 		Node:    config.NodeConfig{ID: "test-node"},
 		Logging: config.LoggingConfig{Verbose: false},
 	}
-	l := logger.NewLogger(cfg)
-	l.WithModule("test-key")
+	l := logger.New(cfg, "test-key")
 
 	return NewKeyManager(keyPath, backupPath, l)
 }
@@ -152,3 +151,27 @@ func TestEncryptedKeyTransferFailures(t *testing.T) {
 		t.Error("Expected error with corrupted data, got nil")
 	}
 }
+
+func TestKeyIsDisabled(t *testing.T) {
+	km := newTestKeyManager(t)
+	if err := km.InitializeKey(); err != nil {
+		t.Fatalf("Failed to init key: %v", err)
+	}
+	if km.IsDisabled() {
+		t.Fatal("a generated key should not be disabled")
+	}
+
+	if err := km.DeleteKey(); err != nil {
+		t.Fatalf("Failed to disable key: %v", err)
+	}
+	if !km.IsDisabled() {
+		t.Error("expected the mock key after DeleteKey")
+	}
+
+	if err := km.RestoreKey(); err != nil {
+		t.Fatalf("Failed to restore key: %v", err)
+	}
+	if km.IsDisabled() {
+		t.Error("expected the real key after RestoreKey")
+	}
+}
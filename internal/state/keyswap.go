@@ -0,0 +1,180 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Key swaps recorded in the swap journal
+const (
+	swapDisable = "disable"
+	swapRestore = "restore"
+)
+
+// swapRecord is the swap journal: which swap was in progress and the
+// address of the real key it moved, to recognize that key wherever the
+// interruption left it
+type swapRecord struct {
+	Op      string    `json:"op"`
+	Address string    `json:"address"`
+	Time    time.Time `json:"time"`
+}
+
+// realPath is where DeleteKey parks the real key
+func (km *KeyManager) realPath() string {
+	return km.keyPath + ".real"
+}
+
+// swapPath is the swap journal next to the key
+func (km *KeyManager) swapPath() string {
+	return km.keyPath + ".swap"
+}
+
+// swap runs the renames of a key swap between writing the journal and
+// removing it. Each rename is atomic, so an interruption leaves one of a
+// few known layouts, which RecoverKeySwap reconciles. A swap that fails
+// keeps its journal for the same reason.
+func (km *KeyManager) swap(op, address string, fn func() error) error {
+	data, err := json.Marshal(swapRecord{Op: op, Address: address, Time: time.Now().UTC()})
+	if err != nil {
+		return fmt.Errorf("failed to marshal swap journal: %w", err)
+	}
+	if err := writeSynced(km.swapPath(), data); err != nil {
+		return fmt.Errorf("failed to write swap journal: %w", err)
+	}
+
+	if err := fn(); err != nil {
+		return err
+	}
+	syncDir(filepath.Dir(km.keyPath))
+	if err := os.Remove(km.swapPath()); err != nil {
+		return fmt.Errorf("failed to clear swap journal: %w", err)
+	}
+	return nil
+}
+
+// RecoverKeySwap reconciles the key files after a swap was interrupted.
+// It runs before InitializeKey, which would otherwise generate a new key
+// when the interruption left no key file. It returns what it did, or ""
+// when the layout was consistent:
+//   - an interrupted disable is completed once the real key was parked,
+//     and rolled back before that
+//   - an interrupted restore is completed
+//   - without a journal, a parked real key next to a missing or torn key
+//     file gets the mock key back, so the node cannot sign
+//
+// A real key found only in the backup is put back where the swap was
+// taking it.
+func (km *KeyManager) RecoverKeySwap() (string, error) {
+	var rec *swapRecord
+	if data, err := os.ReadFile(km.swapPath()); err == nil {
+		rec = &swapRecord{}
+		// A torn journal still means a swap was in progress
+		if err := json.Unmarshal(data, rec); err != nil {
+			rec.Op = swapDisable
+		}
+	} else if !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read swap journal: %w", err)
+	}
+
+	current, _ := km.LoadKey()
+	parked, _ := (&KeyManager{keyPath: km.realPath()}).LoadKey()
+	isReal := func(key *ValidatorKey) bool {
+		return key != nil && key.Address != mockKeyAddress &&
+			(rec == nil || rec.Address == "" || key.Address == rec.Address)
+	}
+
+	var action string
+	switch {
+	case rec != nil && rec.Op == swapRestore && isReal(parked):
+		if err := os.Rename(km.realPath(), km.keyPath); err != nil {
+			return "", fmt.Errorf("failed to restore real key: %w", err)
+		}
+		action = "completed interrupted key restore"
+
+	case rec != nil && rec.Op == swapRestore && isReal(current):
+		action = "key restore had completed"
+
+	case isReal(parked) && !isReal(current) && (rec != nil || current == nil):
+		action = "key disable had completed"
+		if current == nil || current.Address != mockKeyAddress {
+			if err := km.SaveKey(mockKey()); err != nil {
+				return "", fmt.Errorf("failed to write mock key: %w", err)
+			}
+			action = "completed interrupted key disable"
+		}
+
+	case rec != nil && isReal(current):
+		action = "rolled back key disable that had not started"
+
+	case rec != nil:
+		restored, err := km.restoreFromBackup(rec)
+		if err != nil {
+			return "", err
+		}
+		action = restored
+
+	default:
+		return "", nil
+	}
+
+	syncDir(filepath.Dir(km.keyPath))
+	if err := os.Remove(km.swapPath()); err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to clear swap journal: %w", err)
+	}
+	return action, nil
+}
+
+// restoreFromBackup puts the backed-up real key where the interrupted swap
+// was taking it, when neither key file holds it any more
+func (km *KeyManager) restoreFromBackup(rec *swapRecord) (string, error) {
+	backup, err := (&KeyManager{keyPath: km.backupFile()}).LoadKey()
+	if km.backupPath == "" || err != nil || backup.Address == mockKeyAddress ||
+		(rec.Address != "" && backup.Address != rec.Address) {
+		return "", fmt.Errorf("interrupted key %s left no copy of key %s; restore %s from a backup",
+			rec.Op, rec.Address, km.keyPath)
+	}
+
+	if rec.Op == swapRestore {
+		if err := km.SaveKey(backup); err != nil {
+			return "", fmt.Errorf("failed to restore key from backup: %w", err)
+		}
+		return "restored key from backup to complete interrupted key restore", nil
+	}
+	if err := (&KeyManager{keyPath: km.realPath(), owner: km.owner}).SaveKey(backup); err != nil {
+		return "", fmt.Errorf("failed to park key from backup: %w", err)
+	}
+	if err := km.SaveKey(mockKey()); err != nil {
+		return "", fmt.Errorf("failed to write mock key: %w", err)
+	}
+	return "parked key from backup to complete interrupted key disable", nil
+}
+
+// writeSynced writes a small file and syncs it to disk
+func writeSynced(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// syncDir makes renames in dir durable. Not every platform can sync a
+// directory, so failures are ignored.
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
@@ -0,0 +1,165 @@
+package state
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+// realKey initializes the key and returns its address
+func realKey(t *testing.T, km *KeyManager) string {
+	t.Helper()
+	if err := km.InitializeKey(); err != nil {
+		t.Fatalf("InitializeKey failed: %v", err)
+	}
+	key, err := km.LoadKey()
+	if err != nil {
+		t.Fatalf("LoadKey failed: %v", err)
+	}
+	return key.Address
+}
+
+// writeSwapJournal leaves a journal as an interrupted swap would
+func writeSwapJournal(t *testing.T, km *KeyManager, op, address string) {
+	t.Helper()
+	data, _ := json.Marshal(swapRecord{Op: op, Address: address})
+	if err := os.WriteFile(km.swapPath(), data, 0600); err != nil {
+		t.Fatalf("failed to write journal: %v", err)
+	}
+}
+
+// assertLayout checks which key is in place and whether the real key is
+// parked, and that the journal is gone
+func assertLayout(t *testing.T, km *KeyManager, address string, disabled bool) {
+	t.Helper()
+	if km.IsDisabled() != disabled {
+		t.Errorf("IsDisabled = %v, want %v", km.IsDisabled(), disabled)
+	}
+	real, err := km.LoadRealKey()
+	if err != nil || real.Address != address {
+		t.Errorf("real key = %v, %v; want %s", real, err, address)
+	}
+	if _, err := os.Stat(km.swapPath()); !os.IsNotExist(err) {
+		t.Error("swap journal was not removed")
+	}
+}
+
+func TestRecoverKeySwap(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    func(t *testing.T, km *KeyManager, address string)
+		action   string
+		disabled bool
+	}{
+		{
+			name:  "consistent active layout",
+			setup: func(*testing.T, *KeyManager, string) {},
+		},
+		{
+			name: "consistent passive layout",
+			setup: func(t *testing.T, km *KeyManager, _ string) {
+				km.DeleteKey()
+			},
+			disabled: true,
+		},
+		{
+			name: "disable interrupted after parking the real key",
+			setup: func(t *testing.T, km *KeyManager, address string) {
+				writeSwapJournal(t, km, swapDisable, address)
+				os.Rename(km.keyPath, km.realPath())
+			},
+			action:   "completed interrupted key disable",
+			disabled: true,
+		},
+		{
+			name: "disable interrupted while writing the mock key",
+			setup: func(t *testing.T, km *KeyManager, address string) {
+				writeSwapJournal(t, km, swapDisable, address)
+				os.Rename(km.keyPath, km.realPath())
+				os.WriteFile(km.keyPath, []byte(`{"addr`), 0600)
+			},
+			action:   "completed interrupted key disable",
+			disabled: true,
+		},
+		{
+			name: "disable interrupted before parking the real key",
+			setup: func(t *testing.T, km *KeyManager, address string) {
+				writeSwapJournal(t, km, swapDisable, address)
+			},
+			action: "rolled back key disable that had not started",
+		},
+		{
+			name: "restore interrupted before the rename",
+			setup: func(t *testing.T, km *KeyManager, address string) {
+				km.DeleteKey()
+				writeSwapJournal(t, km, swapRestore, address)
+			},
+			action: "completed interrupted key restore",
+		},
+		{
+			name: "mock key without a journal",
+			setup: func(t *testing.T, km *KeyManager, _ string) {
+				os.Rename(km.keyPath, km.realPath())
+			},
+			action:   "completed interrupted key disable",
+			disabled: true,
+		},
+		{
+			name: "real key only in the backup",
+			setup: func(t *testing.T, km *KeyManager, address string) {
+				km.BackupKey()
+				writeSwapJournal(t, km, swapDisable, address)
+				os.Remove(km.keyPath)
+			},
+			action:   "parked key from backup to complete interrupted key disable",
+			disabled: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			km := newTestKeyManager(t)
+			address := realKey(t, km)
+			tt.setup(t, km, address)
+
+			action, err := km.RecoverKeySwap()
+			if err != nil {
+				t.Fatalf("RecoverKeySwap failed: %v", err)
+			}
+			if action != tt.action {
+				t.Errorf("action = %q, want %q", action, tt.action)
+			}
+			assertLayout(t, km, address, tt.disabled)
+		})
+	}
+}
+
+func TestRecoverKeySwap_KeyLost(t *testing.T) {
+	km := newTestKeyManager(t)
+	address := realKey(t, km)
+	writeSwapJournal(t, km, swapDisable, address)
+	os.Remove(km.keyPath)
+
+	_, err := km.RecoverKeySwap()
+	if err == nil || !strings.Contains(err.Error(), "left no copy of key "+address) {
+		t.Fatalf("expected a lost key error, got %v", err)
+	}
+	// The journal stays so the next start reports the same
+	if _, err := os.Stat(km.swapPath()); err != nil {
+		t.Errorf("swap journal was removed: %v", err)
+	}
+}
+
+func TestDeleteKey_Twice(t *testing.T) {
+	km := newTestKeyManager(t)
+	address := realKey(t, km)
+
+	for i := 0; i < 2; i++ {
+		if err := km.DeleteKey(); err != nil {
+			t.Fatalf("DeleteKey failed: %v", err)
+		}
+	}
+	// A second disable must not park the mock key over the real one
+	assertLayout(t, km, address, true)
+}
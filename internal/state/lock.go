@@ -0,0 +1,99 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+var (
+	// ErrLockHeld means another node holds the lock
+	ErrLockHeld = errors.New("state is already locked")
+	// ErrLockUnreachable means the lock backend could not be consulted
+	ErrLockUnreachable = errors.New("lock backend unreachable")
+)
+
+// LockBackend arbitrates which node may hold validator duties.
+// Errors caused by the backend itself being down must wrap
+// ErrLockUnreachable so callers can apply the unreachable policy.
+type LockBackend interface {
+	Name() string
+	Acquire() error
+	Release() error
+	// Check reports whether the backend can currently be reached
+	Check() error
+	// Held reports whether this node holds the lock
+	Held() bool
+}
+
+// LockPath is the lock file of the validator state at statePath. A
+// namespace (node.instance) is part of the name, so instances that share
+// a state directory do not take each other's lock.
+func LockPath(statePath, namespace string) string {
+	if namespace == "" {
+		return statePath + ".lock"
+	}
+	return statePath + "." + namespace + ".lock"
+}
+
+// FileLock is a lock file next to the validator state
+type FileLock struct {
+	path string
+	file *os.File
+}
+
+// NewFileLock creates a file lock at path
+func NewFileLock(path string) *FileLock {
+	return &FileLock{path: path}
+}
+
+// Name returns the backend name
+func (l *FileLock) Name() string {
+	return "file"
+}
+
+// Held reports whether this process created the lock file
+func (l *FileLock) Held() bool {
+	return l.file != nil
+}
+
+// Acquire creates the lock file, failing if it already exists
+func (l *FileLock) Acquire() error {
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		if os.IsExist(err) {
+			return ErrLockHeld
+		}
+		return fmt.Errorf("failed to acquire lock: %w: %v", ErrLockUnreachable, err)
+	}
+
+	l.file = file
+	pid := fmt.Sprintf("%d\n", os.Getpid())
+	file.WriteString(pid)
+
+	return nil
+}
+
+// Release removes the lock file if this process holds it
+func (l *FileLock) Release() error {
+	if l.file == nil {
+		return nil
+	}
+
+	l.file.Close()
+	if err := os.Remove(l.path); err != nil {
+		return fmt.Errorf("failed to remove lock file: %w", err)
+	}
+
+	l.file = nil
+	return nil
+}
+
+// Check verifies the lock directory is still accessible
+func (l *FileLock) Check() error {
+	if _, err := os.Stat(filepath.Dir(l.path)); err != nil {
+		return fmt.Errorf("%w: %v", ErrLockUnreachable, err)
+	}
+	return nil
+}
@@ -33,7 +33,8 @@ type Manager struct {
 	lastSync     time.Time
 	currentState *ValidatorState
 	mu           sync.RWMutex
-	lockFile     *os.File
+	lock         LockBackend
+	codec        StateCodec
 }
 
 // UnmarshalJSON handles CometBFT's string height format
@@ -75,9 +76,22 @@ func NewManager(statePath, backupPath string) *Manager {
 	return &Manager{
 		statePath:  statePath,
 		backupPath: backupPath,
+		lock:       NewFileLock(LockPath(statePath, "")),
+		codec:      cometBFTJSON{},
 	}
 }
 
+// SetCodec sets the format of the state file; the default is CometBFT's
+// JSON
+func (m *Manager) SetCodec(codec StateCodec) {
+	m.codec = codec
+}
+
+// SetLockBackend replaces the default lock file backend
+func (m *Manager) SetLockBackend(lock LockBackend) {
+	m.lock = lock
+}
+
 // LoadState reads the current validator state from disk
 func (m *Manager) LoadState() (*ValidatorState, error) {
 	m.mu.Lock()
@@ -88,13 +102,13 @@ func (m *Manager) LoadState() (*ValidatorState, error) {
 		return nil, fmt.Errorf("failed to read state file: %w", err)
 	}
 
-	var state ValidatorState
-	if err := json.Unmarshal(data, &state); err != nil {
-		return nil, fmt.Errorf("failed to parse state file: %w", err)
+	state, err := m.codec.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s state file: %w", m.codec.Name(), err)
 	}
 
-	m.currentState = &state
-	return &state, nil
+	m.currentState = state
+	return state, nil
 }
 
 // SaveState writes the validator state to disk
@@ -102,7 +116,7 @@ func (m *Manager) SaveState(state *ValidatorState) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	data, err := json.MarshalIndent(state, "", "  ")
+	data, err := m.codec.Encode(state)
 	if err != nil {
 		return fmt.Errorf("failed to marshal state: %w", err)
 	}
@@ -120,7 +134,7 @@ func (m *Manager) SaveState(state *ValidatorState) error {
 
 	// Backup the state
 	if m.backupPath != "" {
-		backupFile := m.backupPath + "/priv_validator_state.json.bak"
+		backupFile := m.backupPath + "/" + m.codec.FileName() + ".bak"
 		if err := os.WriteFile(backupFile, data, 0600); err != nil {
 			fmt.Printf("Warning: failed to write backup state: %v\n", err)
 		}
@@ -131,38 +145,29 @@ func (m *Manager) SaveState(state *ValidatorState) error {
 	return nil
 }
 
-// AcquireLock obtains an exclusive lock on the state file
+// AcquireLock obtains the exclusive validator lock from the lock backend
 func (m *Manager) AcquireLock() error {
-	lockPath := m.statePath + ".lock"
-	file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
-	if err != nil {
-		if os.IsExist(err) {
-			return fmt.Errorf("state is already locked")
-		}
-		return fmt.Errorf("failed to acquire lock: %w", err)
-	}
-
-	m.lockFile = file
-	pid := fmt.Sprintf("%d\n", os.Getpid())
-	file.WriteString(pid)
-
-	return nil
+	return m.lock.Acquire()
 }
 
-// ReleaseLock releases the exclusive lock on the state file
+// ReleaseLock releases the exclusive validator lock
 func (m *Manager) ReleaseLock() error {
-	if m.lockFile == nil {
-		return nil
-	}
+	return m.lock.Release()
+}
 
-	m.lockFile.Close()
-	lockPath := m.statePath + ".lock"
-	if err := os.Remove(lockPath); err != nil {
-		return fmt.Errorf("failed to remove lock file: %w", err)
-	}
+// LockName is the name of the lock backend
+func (m *Manager) LockName() string {
+	return m.lock.Name()
+}
 
-	m.lockFile = nil
-	return nil
+// LockHeld reports whether this node holds the lock
+func (m *Manager) LockHeld() bool {
+	return m.lock.Held()
+}
+
+// CheckLock reports whether the lock backend is reachable
+func (m *Manager) CheckLock() error {
+	return m.lock.Check()
 }
 
 // CompareStates checks if it's safe to take over signing duties
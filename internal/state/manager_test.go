@@ -1,6 +1,7 @@
 package state
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
@@ -75,6 +76,31 @@ func TestManager_Lock(t *testing.T) {
 	mgr2.ReleaseLock()
 }
 
+func TestFileLock_Unreachable(t *testing.T) {
+	tmpDir := t.TempDir()
+	lockPath := filepath.Join(tmpDir, "gone", "priv_validator_state.json.lock")
+	lock := NewFileLock(lockPath)
+
+	if err := lock.Check(); !errors.Is(err, ErrLockUnreachable) {
+		t.Errorf("Check() = %v, want ErrLockUnreachable", err)
+	}
+	if err := lock.Acquire(); !errors.Is(err, ErrLockUnreachable) {
+		t.Errorf("Acquire() = %v, want ErrLockUnreachable", err)
+	}
+
+	held := NewFileLock(filepath.Join(tmpDir, "state.lock"))
+	if err := held.Acquire(); err != nil {
+		t.Fatalf("Failed to acquire lock: %v", err)
+	}
+	defer held.Release()
+	if err := NewFileLock(filepath.Join(tmpDir, "state.lock")).Acquire(); !errors.Is(err, ErrLockHeld) {
+		t.Errorf("Second Acquire() = %v, want ErrLockHeld", err)
+	}
+	if err := held.Check(); err != nil {
+		t.Errorf("Check() on reachable lock = %v", err)
+	}
+}
+
 func TestManager_CompareStates(t *testing.T) {
 	tmpDir := t.TempDir()
 	statePath := filepath.Join(tmpDir, "priv_validator_state.json")
@@ -0,0 +1,119 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// keyFileMode is the only mode key material may have
+const keyFileMode = 0600
+
+// What to do with key files that other users can read or that belong to
+// another owner (cometbft.insecure_key_files)
+const (
+	InsecureFix    = "fix"
+	InsecureWarn   = "warn"
+	InsecureRefuse = "refuse"
+)
+
+// ErrInsecureKeyFile is returned when a key file is left insecure
+var ErrInsecureKeyFile = errors.New("insecure key file")
+
+// ResolveOwner returns the uid key files must belong to: the named user,
+// or the user syncguard runs as. It returns -1 where files have no uid
+// (Windows), which disables the ownership check.
+func ResolveOwner(name string) (int, error) {
+	if runtime.GOOS == "windows" {
+		return -1, nil
+	}
+	if name == "" {
+		return os.Getuid(), nil
+	}
+	u, err := user.Lookup(name)
+	if err != nil {
+		return -1, fmt.Errorf("unknown key owner %q: %w", name, err)
+	}
+	uid, err := strconv.Atoi(u.Uid)
+	if err != nil {
+		return -1, fmt.Errorf("key owner %q has no numeric uid", name)
+	}
+	return uid, nil
+}
+
+// KeyFileProblems lists why the key file at path is not private to owner:
+// permission bits beyond 0600 and, unless owner is -1, another owner.
+// Windows files have neither, so nothing is reported there.
+func KeyFileProblems(path string, owner int) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if runtime.GOOS == "windows" {
+		return nil, nil
+	}
+
+	var problems []string
+	if mode := info.Mode().Perm(); mode&^keyFileMode != 0 {
+		problems = append(problems, fmt.Sprintf("mode %04o allows other users to access it", mode))
+	}
+	if uid, ok := fileOwner(info); ok && owner >= 0 && uid != owner {
+		problems = append(problems, fmt.Sprintf("owned by uid %d instead of %d", uid, owner))
+	}
+	return problems, nil
+}
+
+// secureKeyFile applies the policy to one key file: problems are fixed
+// where possible (the mode always, the owner only as root), then the rest
+// is logged or, with InsecureRefuse, returned as an error
+func (km *KeyManager) secureKeyFile(path string) error {
+	problems, err := KeyFileProblems(path, km.owner)
+	if err != nil || len(problems) == 0 {
+		return err
+	}
+
+	if km.insecure == InsecureFix {
+		if err := fixKeyFile(path, km.owner); err != nil {
+			km.logger.Warn("Failed to secure key file %s: %v", path, err)
+		}
+		remaining, err := KeyFileProblems(path, km.owner)
+		if err != nil {
+			return err
+		}
+		if len(remaining) == 0 {
+			km.logger.Warn("Secured key file %s, it was %s", path, strings.Join(problems, ", "))
+			return nil
+		}
+		problems = remaining
+	}
+
+	msg := fmt.Sprintf("%s is %s", path, strings.Join(problems, ", "))
+	if km.insecure == InsecureRefuse {
+		return fmt.Errorf("%w: %s", ErrInsecureKeyFile, msg)
+	}
+	km.logger.Warn("Insecure key file: %s", msg)
+	return nil
+}
+
+// fixKeyFile sets the key file mode and, when running as root, its owner
+func fixKeyFile(path string, owner int) error {
+	if owner >= 0 && os.Geteuid() == 0 {
+		if err := os.Chown(path, owner, -1); err != nil {
+			return err
+		}
+	}
+	return os.Chmod(path, keyFileMode)
+}
+
+// writeKeyFile writes key material with mode 0600, also when the file
+// already exists with a wider mode, and hands it to the key owner
+func (km *KeyManager) writeKeyFile(path string, data []byte) error {
+	if err := os.WriteFile(path, data, keyFileMode); err != nil {
+		return err
+	}
+	return fixKeyFile(path, km.owner)
+}
@@ -0,0 +1,108 @@
+package state
+
+import (
+	"errors"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// writeInsecureKey creates a key file with a mode other users can read
+func writeInsecureKey(t *testing.T, km *KeyManager) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows files have no permission bits")
+	}
+	if err := km.InitializeKey(); err != nil {
+		t.Fatalf("InitializeKey failed: %v", err)
+	}
+	if err := os.Chmod(km.keyPath, 0644); err != nil {
+		t.Fatalf("chmod failed: %v", err)
+	}
+}
+
+func mode(t *testing.T, path string) os.FileMode {
+	t.Helper()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat failed: %v", err)
+	}
+	return info.Mode().Perm()
+}
+
+func TestSecureKeyFiles_Fix(t *testing.T) {
+	km := newTestKeyManager(t)
+	writeInsecureKey(t, km)
+	os.Rename(km.keyPath, km.keyPath+".real")
+	os.WriteFile(km.keyPath, []byte("{}"), 0640)
+	os.Chmod(km.keyPath, 0640)
+
+	if err := km.SecureKeyFiles(); err != nil {
+		t.Fatalf("SecureKeyFiles failed: %v", err)
+	}
+	for _, path := range []string{km.keyPath, km.keyPath + ".real"} {
+		if got := mode(t, path); got != 0600 {
+			t.Errorf("%s has mode %04o, want 0600", path, got)
+		}
+	}
+}
+
+func TestSecureKeyFiles_Warn(t *testing.T) {
+	km := newTestKeyManager(t)
+	writeInsecureKey(t, km)
+	km.SetKeyFilePolicy(InsecureWarn, -1)
+
+	if err := km.SecureKeyFiles(); err != nil {
+		t.Fatalf("SecureKeyFiles failed: %v", err)
+	}
+	if got := mode(t, km.keyPath); got != 0644 {
+		t.Errorf("warn policy changed the mode to %04o", got)
+	}
+}
+
+func TestSecureKeyFiles_Refuse(t *testing.T) {
+	km := newTestKeyManager(t)
+	writeInsecureKey(t, km)
+	km.SetKeyFilePolicy(InsecureRefuse, -1)
+
+	err := km.SecureKeyFiles()
+	if !errors.Is(err, ErrInsecureKeyFile) || !strings.Contains(err.Error(), "mode 0644") {
+		t.Fatalf("expected an insecure key file error, got %v", err)
+	}
+
+	// Another owner is refused too, once the mode is right
+	os.Chmod(km.keyPath, 0600)
+	km.SetKeyFilePolicy(InsecureRefuse, os.Getuid()+1)
+	if err := km.SecureKeyFiles(); err == nil || !strings.Contains(err.Error(), "owned by uid") {
+		t.Errorf("expected an ownership error, got %v", err)
+	}
+}
+
+func TestKeyWrites_TightenExistingFiles(t *testing.T) {
+	km := newTestKeyManager(t)
+	writeInsecureKey(t, km)
+	// A backup left behind by an older version with a wide mode
+	os.WriteFile(km.backupFile(), []byte("{}"), 0644)
+	os.Chmod(km.backupFile(), 0644)
+	os.WriteFile(km.keyPath+".tmp", []byte("{}"), 0644)
+	os.Chmod(km.keyPath+".tmp", 0644)
+
+	if err := km.BackupKey(); err != nil {
+		t.Fatalf("BackupKey failed: %v", err)
+	}
+	key, err := km.LoadKey()
+	if err != nil {
+		t.Fatalf("LoadKey failed: %v", err)
+	}
+	// A transferred key is saved through the same temp file
+	if err := km.SaveKey(key); err != nil {
+		t.Fatalf("SaveKey failed: %v", err)
+	}
+
+	for _, path := range []string{km.backupFile(), km.keyPath} {
+		if got := mode(t, path); got != 0600 {
+			t.Errorf("%s has mode %04o, want 0600", path, got)
+		}
+	}
+}
@@ -0,0 +1,8 @@
+package state
+
+import "os"
+
+// fileOwner is unknown on Windows, where files have no uid
+func fileOwner(info os.FileInfo) (int, bool) {
+	return 0, false
+}
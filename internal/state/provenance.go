@@ -0,0 +1,130 @@
+package state
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+
+	"github.com/aldebaranode/syncguard/internal/crypto"
+)
+
+// Link places a served validator state in the chain of updates an active
+// node serves. Seq counts the distinct states served since Epoch began (a
+// restart starts a new epoch); Hash is an HMAC under the cluster secret
+// over the link and the state, chained to the previous link through Prev.
+// A passive that checks every link notices missed updates, replays and
+// states altered in transit.
+type Link struct {
+	Epoch string
+	Seq   uint64
+	Prev  string
+	Hash  string
+}
+
+// payload is the string the link HMAC covers
+func (l Link) payload(s *ValidatorState) string {
+	return fmt.Sprintf("%s:%d:%s:%d/%d/%d:%s:%s",
+		l.Epoch, l.Seq, l.Prev, s.Height, s.Round, s.Step, s.Signature, s.SignBytes)
+}
+
+// Chain issues the links of the states this node serves
+type Chain struct {
+	mu    sync.Mutex
+	epoch string
+	head  Link
+	last  ValidatorState
+}
+
+// NewChain starts a chain with a random epoch
+func NewChain() *Chain {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return &Chain{epoch: hex.EncodeToString(b)}
+}
+
+// Record returns the link for s, advancing the chain when s differs from
+// the last state recorded
+func (c *Chain) Record(s *ValidatorState, secret string) Link {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.head.Seq > 0 && sameState(&c.last, s) {
+		return c.head
+	}
+	link := Link{Epoch: c.epoch, Seq: c.head.Seq + 1, Prev: c.head.Hash}
+	link.Hash = crypto.Sign(link.payload(s), secret)
+	c.head = link
+	c.last = *s
+	return link
+}
+
+// sameState compares the fields a link covers
+func sameState(a, b *ValidatorState) bool {
+	return a.Height == b.Height && a.Round == b.Round && a.Step == b.Step &&
+		a.Signature == b.Signature && a.SignBytes == b.SignBytes
+}
+
+// Verdict is the outcome of checking a link
+type Verdict string
+
+const (
+	// VerdictOK: the next link, or the one already seen
+	VerdictOK Verdict = "ok"
+	// VerdictBaseline: the first link seen, or the first of a new epoch
+	VerdictBaseline Verdict = "baseline"
+	// VerdictGap: updates were served that never arrived
+	VerdictGap Verdict = "gap"
+	// VerdictReplay: an older link of the current epoch
+	VerdictReplay Verdict = "replay"
+	// VerdictFork: the next sequence number, chained to another link
+	VerdictFork Verdict = "fork"
+	// VerdictTampered: the HMAC does not match the link and state
+	VerdictTampered Verdict = "tampered"
+)
+
+// Accepted reports whether the state may be synced
+func (v Verdict) Accepted() bool {
+	return v == VerdictOK || v == VerdictBaseline || v == VerdictGap
+}
+
+// Tracker follows the chain on the receiving side
+type Tracker struct {
+	mu   sync.Mutex
+	last *Link
+}
+
+// Check verifies link against s and the links seen before. verify checks
+// an HMAC against the cluster secret. Only accepted links become the new
+// reference.
+func (t *Tracker) Check(link Link, s *ValidatorState, verify func(data, signature string) bool) Verdict {
+	if !verify(link.payload(s), link.Hash) {
+		return VerdictTampered
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	verdict := VerdictOK
+	switch last := t.last; {
+	case last == nil || last.Epoch != link.Epoch:
+		verdict = VerdictBaseline
+	case link.Seq == last.Seq && link.Hash == last.Hash:
+		return VerdictOK
+	case link.Seq <= last.Seq:
+		return VerdictReplay
+	case link.Seq == last.Seq+1 && link.Prev != last.Hash:
+		return VerdictFork
+	case link.Seq > last.Seq+1:
+		verdict = VerdictGap
+	}
+	t.last = &link
+	return verdict
+}
+
+// Reset forgets the chain, so the next link checked is a new baseline
+func (t *Tracker) Reset() {
+	t.mu.Lock()
+	t.last = nil
+	t.mu.Unlock()
+}
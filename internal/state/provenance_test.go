@@ -0,0 +1,67 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/aldebaranode/syncguard/internal/crypto"
+)
+
+func TestProvenance_Verdicts(t *testing.T) {
+	const secret = "cluster-secret"
+	verify := func(data, signature string) bool { return crypto.Verify(data, signature, secret) }
+
+	chain := NewChain()
+	var tracker Tracker
+
+	s1 := &ValidatorState{Height: 100, Round: 0, Step: 3}
+	l1 := chain.Record(s1, secret)
+	if v := tracker.Check(l1, s1, verify); v != VerdictBaseline {
+		t.Errorf("first link = %s, want baseline", v)
+	}
+	// Served again unchanged, e.g. from cache
+	if again := chain.Record(s1, secret); again != l1 {
+		t.Errorf("unchanged state advanced the chain: %+v", again)
+	}
+	if v := tracker.Check(l1, s1, verify); v != VerdictOK {
+		t.Errorf("repeated link = %s, want ok", v)
+	}
+
+	s2 := &ValidatorState{Height: 101, Round: 0, Step: 1}
+	l2 := chain.Record(s2, secret)
+	if l2.Seq != 2 || l2.Prev != l1.Hash {
+		t.Errorf("second link = %+v", l2)
+	}
+
+	altered := *s2
+	altered.Height = 5000
+	if v := tracker.Check(l2, &altered, verify); v != VerdictTampered {
+		t.Errorf("altered state = %s, want tampered", v)
+	}
+	if v := tracker.Check(l2, s2, verify); v != VerdictOK {
+		t.Errorf("next link = %s, want ok", v)
+	}
+	if v := tracker.Check(l1, s1, verify); v != VerdictReplay {
+		t.Errorf("old link = %s, want replay", v)
+	}
+
+	// s3 is served to someone else and never arrives
+	chain.Record(&ValidatorState{Height: 102, Step: 1}, secret)
+	s4 := &ValidatorState{Height: 103, Step: 1}
+	l4 := chain.Record(s4, secret)
+	if v := tracker.Check(l4, s4, verify); v != VerdictGap || !v.Accepted() {
+		t.Errorf("link after a missed one = %s, want an accepted gap", v)
+	}
+
+	forged := Link{Epoch: l4.Epoch, Seq: l4.Seq + 1, Prev: "other"}
+	s5 := &ValidatorState{Height: 104, Step: 1}
+	forged.Hash = crypto.Sign(forged.payload(s5), secret)
+	if v := tracker.Check(forged, s5, verify); v != VerdictFork {
+		t.Errorf("link chained elsewhere = %s, want fork", v)
+	}
+
+	restarted := NewChain()
+	l := restarted.Record(s5, secret)
+	if v := tracker.Check(l, s5, verify); v != VerdictBaseline {
+		t.Errorf("new epoch = %s, want baseline", v)
+	}
+}
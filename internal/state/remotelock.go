@@ -0,0 +1,254 @@
+package state
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/metrics"
+)
+
+// ErrLockLost means a lock this node held was taken from it: its session
+// expired or was revoked, and another node may already hold the lock
+var ErrLockLost = errors.New("lock lost")
+
+var fencingGauge = metrics.NewGauge(
+	"syncguard_lock_fencing_token",
+	"Fencing token of the lock this node holds in etcd or Consul; 0 when not held",
+)
+
+// RemoteLockOptions configures a lock held in etcd or Consul
+type RemoteLockOptions struct {
+	// Endpoints are tried in order until one answers
+	Endpoints []string
+	// Key is the lock's key; every node of a cluster must use the same
+	Key string
+	// Owner names this node in the lock's value
+	Owner string
+	// TTL is how long the session outlives this node's last renewal
+	TTL time.Duration
+	// Token is a Consul ACL token
+	Token string
+	// Username and Password authenticate to etcd
+	Username string
+	Password string
+	// CAFile, CertFile and KeyFile secure HTTPS endpoints
+	CAFile   string
+	CertFile string
+	KeyFile  string
+	// SessionFile remembers the session holding the lock, so a node
+	// restarted within the TTL can take back its own lock
+	SessionFile string
+}
+
+// NewLockBackend returns the backend for a lock.backend value: the lock
+// file at path, or a lock in etcd or Consul
+func NewLockBackend(backend, path string, opts RemoteLockOptions) (LockBackend, error) {
+	switch backend {
+	case "", "file":
+		return NewFileLock(path), nil
+	case "etcd":
+		return NewEtcdLock(opts)
+	case "consul":
+		return NewConsulLock(opts)
+	}
+	return nil, fmt.Errorf("unknown lock backend %q", backend)
+}
+
+// remoteLock is what the etcd and Consul locks share: the HTTP client,
+// the session kept alive in the background and the session file
+type remoteLock struct {
+	opts   RemoteLockOptions
+	client *http.Client
+
+	mu      sync.Mutex
+	session string
+	stop    chan struct{}
+	done    chan struct{}
+	lost    bool
+}
+
+func newRemoteLock(opts RemoteLockOptions) (*remoteLock, error) {
+	if len(opts.Endpoints) == 0 {
+		return nil, fmt.Errorf("no lock endpoints")
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if opts.CAFile != "" || opts.CertFile != "" {
+		tlsCfg := &tls.Config{MinVersion: tls.VersionTLS12}
+		if opts.CAFile != "" {
+			pem, err := os.ReadFile(opts.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read lock ca_file: %w", err)
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("lock ca_file %s holds no PEM certificate", opts.CAFile)
+			}
+			tlsCfg.RootCAs = pool
+		}
+		if opts.CertFile != "" {
+			cert, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load lock client certificate: %w", err)
+			}
+			tlsCfg.Certificates = []tls.Certificate{cert}
+		}
+		transport.TLSClientConfig = tlsCfg
+	}
+	// A request must fail well within the TTL for the watchdog to act
+	timeout := opts.TTL / 3
+	switch {
+	case timeout > 10*time.Second:
+		timeout = 10 * time.Second
+	case timeout < time.Second:
+		timeout = time.Second
+	}
+	return &remoteLock{opts: opts, client: &http.Client{Transport: transport, Timeout: timeout}}, nil
+}
+
+// call sends a JSON request to the first endpoint that answers and decodes
+// the response into out. It returns the HTTP status; an error wraps
+// ErrLockUnreachable when no endpoint answered, or answered with a server
+// error.
+func (r *remoteLock) call(method, path string, headers map[string]string, in, out interface{}) (int, error) {
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return 0, err
+		}
+	}
+
+	var lastErr error
+	for _, endpoint := range r.opts.Endpoints {
+		req, err := http.NewRequest(method, strings.TrimSuffix(endpoint, "/")+path, bytes.NewReader(body))
+		if err != nil {
+			return 0, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		resp, err := r.client.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		resp.Body.Close()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if resp.StatusCode >= 500 {
+			lastErr = fmt.Errorf("%s answered %d: %s", endpoint, resp.StatusCode, strings.TrimSpace(string(data)))
+			continue
+		}
+		if out != nil && resp.StatusCode < 300 && len(data) > 0 {
+			if err := json.Unmarshal(data, out); err != nil {
+				return resp.StatusCode, fmt.Errorf("failed to parse %s response: %w", path, err)
+			}
+		}
+		return resp.StatusCode, nil
+	}
+	return 0, fmt.Errorf("%w: %v", ErrLockUnreachable, lastErr)
+}
+
+// hold records the session now holding the lock and renews it every
+// third of the TTL until release; renew reports false once the session
+// is gone
+func (r *remoteLock) hold(session string, token uint64, renew func(session string) (bool, error)) {
+	if err := os.MkdirAll(filepath.Dir(r.opts.SessionFile), 0700); err != nil {
+		fmt.Printf("Warning: failed to remember lock session: %v\n", err)
+	} else if err := os.WriteFile(r.opts.SessionFile, []byte(session), 0600); err != nil {
+		fmt.Printf("Warning: failed to remember lock session: %v\n", err)
+	}
+	fencingGauge.Set(float64(token))
+
+	stop, done := make(chan struct{}), make(chan struct{})
+	r.mu.Lock()
+	r.session, r.stop, r.done, r.lost = session, stop, done, false
+	r.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(r.opts.TTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				alive, err := renew(session)
+				if err == nil && !alive {
+					r.mu.Lock()
+					r.lost = true
+					r.mu.Unlock()
+					fencingGauge.Set(0)
+					return
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// holding reports whether this node holds the lock
+func (r *remoteLock) holding() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.session != ""
+}
+
+// held reports whether this node holds the lock with a live session
+func (r *remoteLock) held() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.session != "" && !r.lost
+}
+
+// drop stops renewing the session holding the lock and returns it; empty
+// when this node does not hold the lock
+func (r *remoteLock) drop() string {
+	r.mu.Lock()
+	session, stop, done := r.session, r.stop, r.done
+	r.session, r.stop, r.done, r.lost = "", nil, nil, false
+	r.mu.Unlock()
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+	os.Remove(r.opts.SessionFile)
+	fencingGauge.Set(0)
+	return session
+}
+
+// checkHeld fails with ErrLockLost once the session holding the lock is
+// gone
+func (r *remoteLock) checkHeld() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.lost {
+		return fmt.Errorf("%w: session %s expired or was revoked", ErrLockLost, r.session)
+	}
+	return nil
+}
+
+// previousSession is the session this node held the lock with before it
+// restarted, if it never released it
+func (r *remoteLock) previousSession() string {
+	data, err := os.ReadFile(r.opts.SessionFile)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,300 @@
+package state
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeConsul implements the session and KV calls ConsulLock makes
+type fakeConsul struct {
+	mu        sync.Mutex
+	sessions  map[string]bool
+	holder    string
+	value     string
+	lockIndex uint64
+	next      int
+}
+
+func (f *fakeConsul) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	path := r.URL.Path
+	switch {
+	case path == "/v1/status/leader":
+		json.NewEncoder(w).Encode("10.0.0.1:8300")
+	case path == "/v1/session/create":
+		f.next++
+		id := fmt.Sprintf("session-%d", f.next)
+		f.sessions[id] = true
+		json.NewEncoder(w).Encode(map[string]string{"ID": id})
+	case strings.HasPrefix(path, "/v1/session/renew/"):
+		if !f.sessions[strings.TrimPrefix(path, "/v1/session/renew/")] {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("[]"))
+	case strings.HasPrefix(path, "/v1/session/destroy/"):
+		f.expire(strings.TrimPrefix(path, "/v1/session/destroy/"))
+		w.Write([]byte("true"))
+	case strings.HasPrefix(path, "/v1/kv/") && r.Method == http.MethodGet:
+		if f.value == "" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode([]map[string]interface{}{{
+			"LockIndex": f.lockIndex, "Session": f.holder, "Value": f.value,
+		}})
+	case strings.HasPrefix(path, "/v1/kv/"):
+		if session := r.URL.Query().Get("acquire"); session != "" {
+			ok := f.sessions[session] && (f.holder == "" || f.holder == session)
+			if ok {
+				var owner string
+				json.NewDecoder(r.Body).Decode(&owner)
+				f.holder, f.value = session, base64.StdEncoding.EncodeToString([]byte(owner))
+				f.lockIndex++
+			}
+			json.NewEncoder(w).Encode(ok)
+			return
+		}
+		if session := r.URL.Query().Get("release"); session == f.holder {
+			f.holder = ""
+		}
+		w.Write([]byte("true"))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// expire invalidates a session, releasing its key
+func (f *fakeConsul) expire(session string) {
+	delete(f.sessions, session)
+	if f.holder == session {
+		f.holder = ""
+	}
+}
+
+// fakeEtcd implements the lease and txn calls EtcdLock makes
+type fakeEtcd struct {
+	mu       sync.Mutex
+	leases   map[int64]bool
+	key      *etcdKV
+	revision int64
+	next     int64
+}
+
+func (f *fakeEtcd) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var body map[string]json.RawMessage
+	json.NewDecoder(r.Body).Decode(&body)
+	leaseID := func() int64 {
+		var id etcdInt
+		json.Unmarshal(body["ID"], &id)
+		return int64(id)
+	}
+
+	switch r.URL.Path {
+	case "/v3/maintenance/status":
+		w.Write([]byte(`{"version":"3.5.0"}`))
+	case "/v3/lease/grant":
+		f.next++
+		f.leases[f.next] = true
+		fmt.Fprintf(w, `{"ID":"%d","TTL":"30"}`, f.next)
+	case "/v3/lease/keepalive":
+		if !f.leases[leaseID()] {
+			w.Write([]byte(`{"error":{"message":"etcdserver: requested lease not found"}}`))
+			return
+		}
+		w.Write([]byte(`{"result":{"TTL":"30"}}`))
+	case "/v3/lease/revoke":
+		f.revoke(leaseID())
+		w.Write([]byte(`{}`))
+	case "/v3/kv/txn":
+		var txn struct {
+			Success []struct {
+				Put struct {
+					Value string  `json:"value"`
+					Lease etcdInt `json:"lease"`
+				} `json:"request_put"`
+			} `json:"success"`
+		}
+		json.Unmarshal(mustMarshal(body), &txn)
+		if f.key != nil {
+			fmt.Fprintf(w, `{"header":{"revision":"%d"},"succeeded":false,"responses":[{"response_range":{"kvs":[{"value":"%s","lease":"%d"}]}}]}`,
+				f.revision, f.key.Value, f.key.Lease)
+			return
+		}
+		f.revision++
+		put := txn.Success[0].Put
+		f.key = &etcdKV{Value: put.Value, Lease: put.Lease}
+		fmt.Fprintf(w, `{"header":{"revision":"%d"},"succeeded":true}`, f.revision)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// revoke ends a lease, deleting the key attached to it
+func (f *fakeEtcd) revoke(id int64) {
+	delete(f.leases, id)
+	if f.key != nil && int64(f.key.Lease) == id {
+		f.key = nil
+	}
+}
+
+func mustMarshal(v interface{}) []byte {
+	data, _ := json.Marshal(v)
+	return data
+}
+
+func remoteOptions(t *testing.T, endpoint, owner string) RemoteLockOptions {
+	return RemoteLockOptions{
+		Endpoints:   []string{endpoint},
+		Key:         "syncguard/lock",
+		Owner:       owner,
+		TTL:         30 * time.Millisecond,
+		SessionFile: filepath.Join(t.TempDir(), "lock-session"),
+	}
+}
+
+func TestRemoteLocks(t *testing.T) {
+	consul := &fakeConsul{sessions: map[string]bool{}}
+	etcd := &fakeEtcd{leases: map[int64]bool{}}
+
+	backends := []struct {
+		name    string
+		handler http.Handler
+		newLock func(RemoteLockOptions) (LockBackend, error)
+		expire  func()
+	}{
+		{
+			name:    "consul",
+			handler: consul,
+			newLock: func(o RemoteLockOptions) (LockBackend, error) { return NewConsulLock(o) },
+			expire: func() {
+				consul.mu.Lock()
+				defer consul.mu.Unlock()
+				consul.expire(consul.holder)
+			},
+		},
+		{
+			name:    "etcd",
+			handler: etcd,
+			newLock: func(o RemoteLockOptions) (LockBackend, error) { return NewEtcdLock(o) },
+			expire: func() {
+				etcd.mu.Lock()
+				defer etcd.mu.Unlock()
+				etcd.revoke(int64(etcd.key.Lease))
+			},
+		},
+	}
+
+	for _, b := range backends {
+		t.Run(b.name, func(t *testing.T) {
+			srv := httptest.NewServer(b.handler)
+			defer srv.Close()
+
+			first, err := b.newLock(remoteOptions(t, srv.URL, "primary"))
+			if err != nil {
+				t.Fatalf("new lock: %v", err)
+			}
+			second, err := b.newLock(remoteOptions(t, srv.URL, "backup"))
+			if err != nil {
+				t.Fatalf("new lock: %v", err)
+			}
+
+			if err := first.Acquire(); err != nil {
+				t.Fatalf("Acquire() = %v", err)
+			}
+			err = second.Acquire()
+			if !errors.Is(err, ErrLockHeld) {
+				t.Fatalf("second Acquire() = %v, want ErrLockHeld", err)
+			}
+			if !strings.Contains(err.Error(), "primary") {
+				t.Errorf("error should name the holder: %v", err)
+			}
+			if err := first.Check(); err != nil {
+				t.Errorf("Check() = %v", err)
+			}
+			if !first.Held() || second.Held() {
+				t.Errorf("Held() = %v and %v, want only the first", first.Held(), second.Held())
+			}
+
+			if err := first.Release(); err != nil {
+				t.Fatalf("Release() = %v", err)
+			}
+			if err := second.Acquire(); err != nil {
+				t.Fatalf("Acquire() after release = %v", err)
+			}
+
+			// The backend drops the session; the next renewal notices
+			b.expire()
+			deadline := time.Now().Add(time.Second)
+			for second.Check() == nil && time.Now().Before(deadline) {
+				time.Sleep(5 * time.Millisecond)
+			}
+			if err := second.Check(); !errors.Is(err, ErrLockLost) {
+				t.Errorf("Check() after expiry = %v, want ErrLockLost", err)
+			}
+			if second.Held() {
+				t.Error("Held() after expiry")
+			}
+			second.Release()
+		})
+	}
+}
+
+func TestRemoteLocks_ReclaimAfterRestart(t *testing.T) {
+	srv := httptest.NewServer(&fakeEtcd{leases: map[int64]bool{}})
+	defer srv.Close()
+
+	opts := remoteOptions(t, srv.URL, "primary")
+	opts.TTL = time.Minute
+	before, err := NewEtcdLock(opts)
+	if err != nil {
+		t.Fatalf("NewEtcdLock() = %v", err)
+	}
+	if err := before.Acquire(); err != nil {
+		t.Fatalf("Acquire() = %v", err)
+	}
+
+	// A restarted process finds its own lease in the session file
+	after, _ := NewEtcdLock(opts)
+	if err := after.Acquire(); err != nil {
+		t.Fatalf("Acquire() after restart = %v", err)
+	}
+
+	// Another node with the same key but no session file is refused
+	other, _ := NewEtcdLock(remoteOptions(t, srv.URL, "primary"))
+	if err := other.Acquire(); !errors.Is(err, ErrLockHeld) {
+		t.Errorf("Acquire() from a copy = %v, want ErrLockHeld", err)
+	}
+	before.r.drop()
+	after.Release()
+}
+
+func TestRemoteLocks_Unreachable(t *testing.T) {
+	for _, newLock := range []func(RemoteLockOptions) (LockBackend, error){
+		func(o RemoteLockOptions) (LockBackend, error) { return NewConsulLock(o) },
+		func(o RemoteLockOptions) (LockBackend, error) { return NewEtcdLock(o) },
+	} {
+		lock, err := newLock(remoteOptions(t, "http://127.0.0.1:1", "primary"))
+		if err != nil {
+			t.Fatalf("new lock: %v", err)
+		}
+		if err := lock.Check(); !errors.Is(err, ErrLockUnreachable) {
+			t.Errorf("%s Check() = %v, want ErrLockUnreachable", lock.Name(), err)
+		}
+		if err := lock.Acquire(); !errors.Is(err, ErrLockUnreachable) {
+			t.Errorf("%s Acquire() = %v, want ErrLockUnreachable", lock.Name(), err)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// RoleRecord is the role a node last held and how it got there. It is
+// kept on disk so a restart resumes that role instead of node.role.
+type RoleRecord struct {
+	Active bool `json:"active"`
+	// Transition is the change of role in progress when the record was
+	// saved, "" between changes; the journal tells how far it got
+	Transition string `json:"transition,omitempty"`
+	// Reason is the reason code of the last change of role
+	Reason string `json:"reason,omitempty"`
+	// Changed is when the role last changed
+	Changed time.Time `json:"changed"`
+	// Transitions counts the changes of role recorded
+	Transitions int       `json:"transitions"`
+	SavedAt     time.Time `json:"saved_at"`
+	// Last is when each type of transition (failover, failback, takeover,
+	// release) last happened
+	Last map[string]time.Time `json:"last,omitempty"`
+	// Reasons counts the transitions by reason code
+	Reasons map[string]int `json:"reasons,omitempty"`
+}
+
+// Count records a transition of kind for reason at the given time
+func (r *RoleRecord) Count(kind, reason string, at time.Time) {
+	if r.Last == nil {
+		r.Last = make(map[string]time.Time)
+	}
+	r.Last[kind] = at
+	if reason == "" {
+		return
+	}
+	if r.Reasons == nil {
+		r.Reasons = make(map[string]int)
+	}
+	r.Reasons[reason]++
+}
+
+// RoleStore keeps the role record in a file
+type RoleStore struct {
+	path string
+}
+
+// NewRoleStore creates a store backed by the given file
+func NewRoleStore(path string) *RoleStore {
+	return &RoleStore{path: path}
+}
+
+// Save replaces the stored record. The file is synced before it replaces
+// the old one, so a power loss leaves one or the other.
+func (s *RoleStore) Save(rec RoleRecord) error {
+	rec.SavedAt = time.Now().UTC()
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal role record: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create role record directory: %w", err)
+	}
+
+	tmpFile := s.path + ".tmp"
+	f, err := os.OpenFile(tmpFile, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write temp role record: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write temp role record: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to sync temp role record: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write temp role record: %w", err)
+	}
+	if err := os.Rename(tmpFile, s.path); err != nil {
+		return fmt.Errorf("failed to rename role record: %w", err)
+	}
+	return nil
+}
+
+// Load returns the stored record; nil when none was saved yet
+func (s *RoleStore) Load() (*RoleRecord, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read role record: %w", err)
+	}
+
+	var rec RoleRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("failed to parse role record: %w", err)
+	}
+	return &rec, nil
+}
@@ -0,0 +1,36 @@
+package state
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRoleStore_SaveAndLoad(t *testing.T) {
+	store := NewRoleStore(filepath.Join(t.TempDir(), "data", "role.json"))
+
+	rec, err := store.Load()
+	if err != nil || rec != nil {
+		t.Fatalf("empty store: got %+v, %v", rec, err)
+	}
+
+	changed := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	want := RoleRecord{Active: true, Transition: "release", Reason: "health_check_failed", Changed: changed, Transitions: 2}
+	want.Count("failover", "health_check_failed", changed)
+	want.Count("release", "health_check_failed", changed)
+	if err := store.Save(want); err != nil {
+		t.Fatal(err)
+	}
+	rec, err = store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.SavedAt.IsZero() {
+		t.Error("saved record has no SavedAt")
+	}
+	rec.SavedAt = time.Time{}
+	if !reflect.DeepEqual(*rec, want) {
+		t.Errorf("loaded %+v, want %+v", *rec, want)
+	}
+}
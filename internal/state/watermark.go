@@ -0,0 +1,112 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Watermark is the signing gate shared with the validator node.
+// A thin shim in front of the signer reads this file and refuses to sign
+// when the node is not allowed to (passive) or when the requested height is
+// below the cluster watermark. It is defense-in-depth: even if the failover
+// state machine is wrong, the node cannot sign below what the cluster has
+// already signed.
+type Watermark struct {
+	NodeID    string    `json:"node_id"`
+	Active    bool      `json:"active"`
+	MinHeight int64     `json:"min_height"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Allows reports whether signing at the given height is permitted
+func (w *Watermark) Allows(height int64) bool {
+	return w.Active && height >= w.MinHeight
+}
+
+// WatermarkWriter maintains the watermark file on disk
+type WatermarkWriter struct {
+	path    string
+	nodeID  string
+	mu      sync.Mutex
+	current Watermark
+}
+
+// NewWatermarkWriter creates a writer for the given watermark file.
+// An existing file is loaded so the minimum height survives restarts.
+func NewWatermarkWriter(path, nodeID string) *WatermarkWriter {
+	ww := &WatermarkWriter{
+		path:   path,
+		nodeID: nodeID,
+	}
+	if wm, err := ReadWatermark(path); err == nil {
+		ww.current = *wm
+	}
+	return ww
+}
+
+// Update writes a new watermark. The minimum height never moves backwards,
+// so a stale update cannot reopen a height the cluster already signed.
+func (ww *WatermarkWriter) Update(active bool, minHeight int64) error {
+	ww.mu.Lock()
+	defer ww.mu.Unlock()
+
+	if minHeight < ww.current.MinHeight {
+		minHeight = ww.current.MinHeight
+	}
+
+	wm := Watermark{
+		NodeID:    ww.nodeID,
+		Active:    active,
+		MinHeight: minHeight,
+		UpdatedAt: time.Now().UTC(),
+	}
+
+	data, err := json.MarshalIndent(wm, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal watermark: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(ww.path), 0700); err != nil {
+		return fmt.Errorf("failed to create watermark directory: %w", err)
+	}
+
+	// Write to temporary file first so the shim never reads a partial file.
+	// The file is world-readable because the node usually runs as another user.
+	tmpFile := ww.path + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write temp watermark file: %w", err)
+	}
+
+	if err := os.Rename(tmpFile, ww.path); err != nil {
+		return fmt.Errorf("failed to rename watermark file: %w", err)
+	}
+
+	ww.current = wm
+	return nil
+}
+
+// Current returns the last written watermark
+func (ww *WatermarkWriter) Current() Watermark {
+	ww.mu.Lock()
+	defer ww.mu.Unlock()
+	return ww.current
+}
+
+// ReadWatermark loads a watermark file from disk
+func ReadWatermark(path string) (*Watermark, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read watermark file: %w", err)
+	}
+
+	var wm Watermark
+	if err := json.Unmarshal(data, &wm); err != nil {
+		return nil, fmt.Errorf("failed to parse watermark file: %w", err)
+	}
+
+	return &wm, nil
+}
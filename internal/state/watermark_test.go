@@ -0,0 +1,64 @@
+package state
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestWatermark_Allows(t *testing.T) {
+	tests := []struct {
+		name   string
+		wm     Watermark
+		height int64
+		want   bool
+	}{
+		{"passive never allowed", Watermark{Active: false, MinHeight: 10}, 20, false},
+		{"active above watermark", Watermark{Active: true, MinHeight: 10}, 11, true},
+		{"active at watermark", Watermark{Active: true, MinHeight: 10}, 10, true},
+		{"active below watermark", Watermark{Active: true, MinHeight: 10}, 9, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.wm.Allows(tt.height); got != tt.want {
+				t.Errorf("Allows(%d) = %v, want %v", tt.height, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWatermarkWriter_UpdateAndRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "watermark.json")
+	ww := NewWatermarkWriter(path, "node-1")
+
+	if err := ww.Update(true, 1000); err != nil {
+		t.Fatalf("Failed to update watermark: %v", err)
+	}
+
+	wm, err := ReadWatermark(path)
+	if err != nil {
+		t.Fatalf("Failed to read watermark: %v", err)
+	}
+	if !wm.Active || wm.MinHeight != 1000 || wm.NodeID != "node-1" {
+		t.Errorf("Unexpected watermark: %+v", wm)
+	}
+}
+
+func TestWatermarkWriter_NeverMovesBackwards(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "watermark.json")
+	ww := NewWatermarkWriter(path, "node-1")
+
+	ww.Update(false, 1000)
+	ww.Update(true, 900)
+
+	if got := ww.Current().MinHeight; got != 1000 {
+		t.Errorf("MinHeight = %d, want 1000", got)
+	}
+
+	// A fresh writer picks up the persisted watermark
+	reloaded := NewWatermarkWriter(path, "node-1")
+	reloaded.Update(true, 500)
+	if got := reloaded.Current().MinHeight; got != 1000 {
+		t.Errorf("MinHeight after reload = %d, want 1000", got)
+	}
+}
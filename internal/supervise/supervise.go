@@ -0,0 +1,103 @@
+// Package supervise keeps a panic in one handler or background loop from
+// silently stopping it or crashing the process: the panic is logged,
+// reported to the error tracker and counted, and loops are restarted.
+package supervise
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/errtrack"
+	"github.com/aldebaranode/syncguard/internal/logger"
+	"github.com/aldebaranode/syncguard/internal/metrics"
+)
+
+var panicCounter = metrics.NewCounter(
+	"syncguard_panics_total",
+	"Panics recovered in background loops and HTTP handlers, by component",
+	"component",
+)
+
+// Restart delays after a panic: the first restart waits minBackoff, each
+// further one twice as long up to maxBackoff. A loop that ran for
+// maxBackoff before panicking starts over at minBackoff.
+var (
+	minBackoff = time.Second
+	maxBackoff = time.Minute
+)
+
+// Go runs a loop in a goroutine and restarts it after a panic, until stop
+// is closed. A loop that returns is not restarted.
+func Go(l *logger.Logger, name string, stop <-chan struct{}, loop func()) {
+	go func() {
+		backoff := minBackoff
+		for {
+			started := time.Now()
+			if !run(l, name, loop) {
+				return
+			}
+			if time.Since(started) >= maxBackoff {
+				backoff = minBackoff
+			}
+
+			l.Warn("Restarting %s in %s", name, backoff)
+			select {
+			case <-time.After(backoff):
+			case <-stop:
+				return
+			}
+			backoff = min(backoff*2, maxBackoff)
+		}
+	}()
+}
+
+// Once runs fn in a goroutine and recovers a panic without running it
+// again, for one-off work such as a cascade that must not repeat
+func Once(l *logger.Logger, name string, fn func()) {
+	go run(l, name, fn)
+}
+
+// run calls fn and reports whether it panicked
+func run(l *logger.Logger, name string, fn func()) (panicked bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			recovered(l, name, r)
+			panicked = true
+		}
+	}()
+	fn()
+	return false
+}
+
+// Handler recovers a panic in an HTTP handler and answers 500. The server
+// keeps serving other requests either way, but without this the panic
+// would only be printed to stderr by net/http.
+func Handler(l *logger.Logger, name string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			// net/http uses this panic to abort a response on purpose
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+			recovered(l.With("path", r.URL.Path), name, rec)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// recovered logs, reports and counts a recovered panic
+func recovered(l *logger.Logger, name string, value interface{}) {
+	stack := debug.Stack()
+	panicCounter.Inc(name)
+	errtrack.CapturePanic(value, stack)
+	// The tracker already has the panic with its stack, so the log line
+	// is marked to keep it from being reported twice
+	l.With(errtrack.FieldPanic, fmt.Sprint(value)).Error("Recovered from panic in %s: %v\n%s", name, value, stack)
+}
@@ -0,0 +1,86 @@
+package supervise
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/logger"
+)
+
+func testLogger() *logger.Logger {
+	return logger.New(&config.Config{Node: config.NodeConfig{ID: "validator-1"}}, "test")
+}
+
+func TestGo_RestartsAfterPanic(t *testing.T) {
+	minBackoff, maxBackoff = time.Millisecond, 4*time.Millisecond
+	defer func() { minBackoff, maxBackoff = time.Second, time.Minute }()
+
+	var runs atomic.Int32
+	done := make(chan struct{})
+	stop := make(chan struct{})
+	defer close(stop)
+
+	before := panicCounter.Value("test-loop")
+	Go(testLogger(), "test-loop", stop, func() {
+		if runs.Add(1) < 3 {
+			panic("loop failed")
+		}
+		close(done)
+	})
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("loop not restarted, ran %d times", runs.Load())
+	}
+	if got := panicCounter.Value("test-loop") - before; got != 2 {
+		t.Errorf("counted %v panics, want 2", got)
+	}
+}
+
+func TestGo_StopsDuringBackoff(t *testing.T) {
+	var runs atomic.Int32
+	stop := make(chan struct{})
+	close(stop)
+
+	Go(testLogger(), "stopped-loop", stop, func() {
+		runs.Add(1)
+		panic("loop failed")
+	})
+
+	// The restart waits a full minBackoff, and stop is already closed
+	time.Sleep(50 * time.Millisecond)
+	if got := runs.Load(); got != 1 {
+		t.Errorf("loop ran %d times after stop, want 1", got)
+	}
+}
+
+func TestHandler_RecoversPanic(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/panic", func(http.ResponseWriter, *http.Request) { panic("handler failed") })
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
+	srv := httptest.NewServer(Handler(testLogger(), "test-api", mux))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/panic")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", resp.StatusCode)
+	}
+
+	resp, err = http.Get(srv.URL + "/ok")
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("server stopped serving after a panic: %v", err)
+	}
+	resp.Body.Close()
+	if got := panicCounter.Value("test-api"); got != 1 {
+		t.Errorf("counted %v panics, want 1", got)
+	}
+}
@@ -0,0 +1,246 @@
+// Package transition runs changes of active role one at a time. Failover
+// and failback are requested from the health loop, the peer's
+// notifications, the admin API, drills and the lock watchdog; all of them
+// go through one executor, which runs a single transition at a time in
+// request order. A request of the same kind as one already queued or
+// running joins it and shares its outcome. A request of the opposite kind
+// is rejected until that one has finished, rather than undoing it.
+package transition
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/aldebaranode/syncguard/internal/metrics"
+)
+
+// Kinds of transition, as recorded in the transition journal
+const (
+	KindRelease = "release"
+	KindAcquire = "acquire"
+)
+
+// Outcomes counted per request
+const (
+	outcomeExecuted  = "executed"
+	outcomeCoalesced = "coalesced"
+	outcomeRejected  = "rejected"
+)
+
+var requestCounter = metrics.NewCounter(
+	"syncguard_transition_requests_total",
+	"Requested changes of active role, by kind and by whether they ran, joined one in flight or were rejected",
+	"kind", "outcome",
+)
+
+// ErrContradictory is returned for a request opposite to one in flight
+var ErrContradictory = errors.New("contradictory transition")
+
+// ErrStopped is returned once the executor has stopped
+var ErrStopped = errors.New("transition executor stopped")
+
+// Request asks for a transition. Run carries it out on the executor's
+// goroutine; Reason and Source only describe it.
+type Request struct {
+	Kind   string
+	Reason string
+	// Source names the requester, e.g. "health", "peer" or "operator"
+	Source string
+	Run    func() error
+}
+
+// Status describes the transitions in flight
+type Status struct {
+	Running *Pending  `json:"running,omitempty"`
+	Queued  []Pending `json:"queued,omitempty"`
+}
+
+// Pending is a transition in flight and the requests it serves
+type Pending struct {
+	Kind    string   `json:"kind"`
+	Reason  string   `json:"reason"`
+	Sources []string `json:"sources"`
+}
+
+type job struct {
+	req     Request
+	sources []string
+	waiters []chan error
+}
+
+func (j *job) pending() Pending {
+	return Pending{Kind: j.req.Kind, Reason: j.req.Reason, Sources: append([]string(nil), j.sources...)}
+}
+
+// Observer is told when a transition starts and finishes, e.g. to keep a
+// record of it on disk. It is called on the executor's goroutine.
+type Observer interface {
+	Started(r Request)
+	Finished(r Request, err error)
+}
+
+// Executor serializes transitions
+type Executor struct {
+	mu       sync.Mutex
+	queue    []*job
+	running  *job
+	stopped  bool
+	idle     *sync.Cond
+	wake     chan struct{}
+	observer Observer
+}
+
+// NewExecutor creates an executor; Run must be started to execute anything
+func NewExecutor() *Executor {
+	e := &Executor{wake: make(chan struct{}, 1)}
+	e.idle = sync.NewCond(&e.mu)
+	return e
+}
+
+// SetObserver sets the observer of executed transitions; call it before Run
+func (e *Executor) SetObserver(o Observer) {
+	e.observer = o
+}
+
+// Submit queues r and waits for its outcome
+func (e *Executor) Submit(r Request) error {
+	done := make(chan error, 1)
+
+	e.mu.Lock()
+	if e.stopped {
+		e.mu.Unlock()
+		return ErrStopped
+	}
+	for _, j := range e.inFlight() {
+		if j.req.Kind == r.Kind {
+			j.sources = append(j.sources, r.Source)
+			j.waiters = append(j.waiters, done)
+			e.mu.Unlock()
+			requestCounter.Inc(r.Kind, outcomeCoalesced)
+			return <-done
+		}
+	}
+	for _, j := range e.inFlight() {
+		e.mu.Unlock()
+		requestCounter.Inc(r.Kind, outcomeRejected)
+		return fmt.Errorf("%w: cannot %s (%s, requested by %s) while %s (%s, requested by %s) is in flight",
+			ErrContradictory, r.Kind, r.Reason, r.Source, j.req.Kind, j.req.Reason, j.sources[0])
+	}
+	e.queue = append(e.queue, &job{req: r, sources: []string{r.Source}, waiters: []chan error{done}})
+	e.mu.Unlock()
+
+	select {
+	case e.wake <- struct{}{}:
+	default:
+	}
+	return <-done
+}
+
+// inFlight lists the running and queued jobs; e.mu must be held
+func (e *Executor) inFlight() []*job {
+	jobs := e.queue
+	if e.running != nil {
+		jobs = append([]*job{e.running}, jobs...)
+	}
+	return jobs
+}
+
+// Run executes queued transitions until stop is closed; requests still
+// queued then fail with ErrStopped
+func (e *Executor) Run(stop <-chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			e.stop()
+			return
+		case <-e.wake:
+		}
+		for e.next() {
+		}
+	}
+}
+
+// next runs the first queued job and reports whether there was one
+func (e *Executor) next() bool {
+	e.mu.Lock()
+	if len(e.queue) == 0 {
+		e.mu.Unlock()
+		return false
+	}
+	j := e.queue[0]
+	e.queue = e.queue[1:]
+	e.running = j
+	e.mu.Unlock()
+
+	requestCounter.Inc(j.req.Kind, outcomeExecuted)
+	if e.observer != nil {
+		e.observer.Started(j.req)
+	}
+	err := execute(j.req)
+	if e.observer != nil {
+		e.observer.Finished(j.req, err)
+	}
+
+	e.mu.Lock()
+	e.running = nil
+	waiters := j.waiters
+	e.idle.Broadcast()
+	e.mu.Unlock()
+	for _, w := range waiters {
+		w <- err
+	}
+	return true
+}
+
+// execute runs a request, turning a panic into its error so waiters are
+// always answered
+func execute(r Request) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = fmt.Errorf("%s transition panicked: %v", r.Kind, v)
+		}
+	}()
+	return r.Run()
+}
+
+func (e *Executor) stop() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.stopped = true
+	for _, j := range e.queue {
+		for _, w := range j.waiters {
+			w <- ErrStopped
+		}
+	}
+	e.queue = nil
+}
+
+// Stop fails queued requests with ErrStopped and refuses new ones, as
+// closing Run's stop channel does, then waits for the transition running,
+// if any, to finish: a process must not exit halfway through one
+func (e *Executor) Stop() {
+	e.stop()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for e.running != nil {
+		e.idle.Wait()
+	}
+}
+
+// Status returns the transitions in flight
+func (e *Executor) Status() Status {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var st Status
+	if e.running != nil {
+		running := e.running.pending()
+		st.Running = &running
+	}
+	for _, j := range e.queue {
+		st.Queued = append(st.Queued, j.pending())
+	}
+	return st
+}
@@ -0,0 +1,197 @@
+package transition
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// blocked starts a transition that runs until release is closed
+func blocked(t *testing.T, e *Executor, kind string, release <-chan struct{}, runs *int32) <-chan error {
+	t.Helper()
+	started := make(chan struct{})
+	result := make(chan error, 1)
+	go func() {
+		result <- e.Submit(Request{Kind: kind, Reason: "test", Source: "first", Run: func() error {
+			atomic.AddInt32(runs, 1)
+			close(started)
+			<-release
+			return nil
+		}})
+	}()
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("transition did not start")
+	}
+	return result
+}
+
+func TestExecutor_CoalescesDuplicates(t *testing.T) {
+	e := NewExecutor()
+	stop := make(chan struct{})
+	defer close(stop)
+	go e.Run(stop)
+
+	var runs int32
+	release := make(chan struct{})
+	first := blocked(t, e, KindRelease, release, &runs)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 3)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = e.Submit(Request{Kind: KindRelease, Source: "duplicate", Run: func() error {
+				atomic.AddInt32(&runs, 1)
+				return errors.New("duplicate ran")
+			}})
+		}(i)
+	}
+	// Wait until every duplicate has joined the running transition
+	for deadline := time.Now().Add(time.Second); ; {
+		if st := e.Status(); st.Running != nil && len(st.Running.Sources) == 4 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("duplicates did not join: %+v", e.Status())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	if err := <-first; err != nil {
+		t.Errorf("first request failed: %v", err)
+	}
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("duplicate %d failed: %v", i, err)
+		}
+	}
+	if runs != 1 {
+		t.Errorf("transition ran %d times, want once", runs)
+	}
+}
+
+func TestExecutor_RejectsContradiction(t *testing.T) {
+	e := NewExecutor()
+	stop := make(chan struct{})
+	defer close(stop)
+	go e.Run(stop)
+
+	var runs int32
+	release := make(chan struct{})
+	first := blocked(t, e, KindAcquire, release, &runs)
+
+	err := e.Submit(Request{Kind: KindRelease, Reason: "operator_manual", Source: "operator",
+		Run: func() error { return nil }})
+	if !errors.Is(err, ErrContradictory) {
+		t.Fatalf("opposite request = %v, want ErrContradictory", err)
+	}
+
+	close(release)
+	if err := <-first; err != nil {
+		t.Fatalf("first request failed: %v", err)
+	}
+	// Once the acquire finished, a release is a new transition
+	if err := e.Submit(Request{Kind: KindRelease, Source: "operator", Run: func() error { return nil }}); err != nil {
+		t.Errorf("release after acquire failed: %v", err)
+	}
+}
+
+func TestExecutor_PanicAndStop(t *testing.T) {
+	e := NewExecutor()
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		e.Run(stop)
+		close(done)
+	}()
+
+	err := e.Submit(Request{Kind: KindRelease, Run: func() error { panic("boom") }})
+	if err == nil {
+		t.Fatal("expected a panicking transition to fail")
+	}
+
+	close(stop)
+	<-done
+	if err := e.Submit(Request{Kind: KindRelease, Run: func() error { return nil }}); !errors.Is(err, ErrStopped) {
+		t.Errorf("submit after stop = %v, want ErrStopped", err)
+	}
+}
+
+func TestExecutor_StopWaitsForRunning(t *testing.T) {
+	e := NewExecutor()
+	stop := make(chan struct{})
+	defer close(stop)
+	go e.Run(stop)
+
+	var runs int32
+	release := make(chan struct{})
+	first := blocked(t, e, KindRelease, release, &runs)
+
+	stopped := make(chan struct{})
+	go func() {
+		e.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		t.Fatal("Stop returned while a transition was running")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Stop did not return once the transition finished")
+	}
+	if err := <-first; err != nil {
+		t.Errorf("running transition = %v", err)
+	}
+	if err := e.Submit(Request{Kind: KindAcquire, Run: func() error { return nil }}); !errors.Is(err, ErrStopped) {
+		t.Errorf("submit after Stop = %v, want ErrStopped", err)
+	}
+}
+
+// recorder is an Observer that logs what it is told
+type recorder struct {
+	events []string
+}
+
+func (r *recorder) Started(req Request) { r.events = append(r.events, "started "+req.Kind) }
+
+func (r *recorder) Finished(req Request, err error) {
+	outcome := "ok"
+	if err != nil {
+		outcome = err.Error()
+	}
+	r.events = append(r.events, "finished "+req.Kind+": "+outcome)
+}
+
+func TestExecutor_Observer(t *testing.T) {
+	e := NewExecutor()
+	rec := &recorder{}
+	e.SetObserver(rec)
+	stop := make(chan struct{})
+	defer close(stop)
+	go e.Run(stop)
+
+	e.Submit(Request{Kind: KindAcquire, Run: func() error { return nil }})
+	e.Submit(Request{Kind: KindRelease, Run: func() error { return errors.New("peer down") }})
+
+	want := []string{"started acquire", "finished acquire: ok", "started release", "finished release: peer down"}
+	if len(rec.events) != len(want) {
+		t.Fatalf("events = %v, want %v", rec.events, want)
+	}
+	for i := range want {
+		if rec.events[i] != want[i] {
+			t.Errorf("event %d = %q, want %q", i, rec.events[i], want[i])
+		}
+	}
+}
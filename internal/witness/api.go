@@ -0,0 +1,171 @@
+package witness
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/crypto"
+	"github.com/aldebaranode/syncguard/internal/metrics"
+	"github.com/aldebaranode/syncguard/internal/supervise"
+)
+
+// Witness API paths
+const (
+	PathVote   = "/witness/vote"
+	PathStatus = "/witness/status"
+	PathHealth = "/health"
+)
+
+// VoteRequest asks the witness to approve a takeover.
+// It is authenticated with the shared cluster secret. From names the
+// active node handing over to NodeID, if it is one.
+type VoteRequest struct {
+	NodeID    string `json:"node_id"`
+	From      string `json:"from,omitempty"`
+	Timestamp int64  `json:"timestamp"`
+	Signature string `json:"signature"`
+}
+
+// VoteResponse is the witness's answer to a takeover request
+type VoteResponse struct {
+	Granted bool   `json:"granted"`
+	Reason  string `json:"reason"`
+	Lease   *Lease `json:"lease,omitempty"`
+}
+
+// Status is the witness's view, served to signed requests only: it maps
+// the cluster
+type Status struct {
+	WitnessID string        `json:"witness_id"`
+	Time      time.Time     `json:"time"`
+	Peers     []Observation `json:"peers"`
+	Lease     *Lease        `json:"lease"`
+}
+
+// VotePayload is the string covered by the vote HMAC
+func VotePayload(nodeID, from string) string {
+	if from == "" {
+		return "vote:" + nodeID
+	}
+	return "vote:" + nodeID + ":from:" + from
+}
+
+// Handler returns the witness HTTP API
+func (w *Witness) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(PathVote, w.handleVote)
+	mux.HandleFunc(PathStatus, w.signed(w.handleStatus))
+	mux.HandleFunc(PathHealth, w.handleHealth)
+	mux.Handle("/metrics", metrics.Handler())
+	return supervise.Handler(w.logger, "witness-api", mux)
+}
+
+// Start observes the peers and serves the witness API until Stop. With
+// tls.enabled the peers are observed, and the API served, over mutual TLS.
+func (w *Witness) Start() error {
+	w.httpServer = &http.Server{
+		Addr:    w.cfg.Witness.Listen,
+		Handler: w.Handler(),
+	}
+	if w.cfg.TLS.Enabled {
+		tlsConfig, err := crypto.LoadTLSConfig(w.cfg.TLS.CAFile, w.cfg.TLS.CertFile, w.cfg.TLS.KeyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load peer TLS: %w", err)
+		}
+		w.client.UseTLS(tlsConfig)
+		w.httpServer.TLSConfig = tlsConfig
+	}
+	supervise.Go(w.logger, "witness-observer", w.stopCh, w.observeLoop)
+
+	var err error
+	if w.httpServer.TLSConfig != nil {
+		w.logger.Info("Starting witness %s on %s with mutual TLS", w.cfg.Node.ID, w.cfg.Witness.Listen)
+		err = w.httpServer.ListenAndServeTLS("", "")
+	} else {
+		w.logger.Info("Starting witness %s on %s", w.cfg.Node.ID, w.cfg.Witness.Listen)
+		err = w.httpServer.ListenAndServe()
+	}
+	if err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// Stop shuts the witness down
+func (w *Witness) Stop() {
+	close(w.stopCh)
+	if w.httpServer != nil {
+		w.httpServer.Close()
+	}
+}
+
+// handleVote answers a signed takeover vote request
+func (w *Witness) handleVote(rw http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(rw, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req VoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(rw, "Invalid vote request", http.StatusBadRequest)
+		return
+	}
+
+	maxAge := w.cfg.Identity.MaxSkew.Duration()
+	if !w.secrets.VerifyTimed(VotePayload(req.NodeID, req.From), req.Signature, req.Timestamp, maxAge.Milliseconds()) {
+		w.logger.Warn("Rejected vote request from %q: bad signature", req.NodeID)
+		http.Error(rw, "Invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(rw).Encode(w.Vote(req.NodeID, req.From))
+}
+
+// signed admits requests signed with the cluster secret, as peers sign
+// theirs
+func (w *Witness) signed(next http.HandlerFunc) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		nodeID := r.Header.Get(crypto.HeaderNodeID)
+		timestamp, err := strconv.ParseInt(r.Header.Get(crypto.HeaderTimestamp), 10, 64)
+		if err == nil {
+			err = w.secrets.VerifyRequest(nodeID, r.Method, r.URL.Path, nil, timestamp,
+				r.Header.Get(crypto.HeaderSignature), w.cfg.Identity.MaxSkew.Duration())
+		}
+		if err != nil {
+			w.logger.Warn("Rejected request from %q to %s: %v", nodeID, r.URL.Path, err)
+			http.Error(rw, "Invalid signature", http.StatusUnauthorized)
+			return
+		}
+		next(rw, r)
+	}
+}
+
+// handleStatus returns the witness's observations and current lease
+func (w *Witness) handleStatus(rw http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(rw, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.mu.Lock()
+	lease := w.lease
+	w.mu.Unlock()
+
+	rw.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(rw).Encode(Status{
+		WitnessID: w.cfg.Node.ID,
+		Time:      time.Now().UTC(),
+		Peers:     w.Observations(),
+		Lease:     lease,
+	})
+}
+
+// handleHealth reports liveness for container health checks
+func (w *Witness) handleHealth(rw http.ResponseWriter, r *http.Request) {
+	rw.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(rw).Encode(map[string]interface{}{"healthy": true, "witness": true})
+}
@@ -0,0 +1,114 @@
+package witness
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/crypto"
+)
+
+// Client asks a witness to approve this node's takeovers
+type Client struct {
+	url     string
+	nodeID  string
+	secrets *crypto.SecretRing
+	http    *http.Client
+}
+
+// NewClient creates a client for the witness at address, as host:port or
+// URL, voting for nodeID. With tlsConfig, the peer TLS configuration, it
+// speaks mutual TLS, and https to a host:port.
+func NewClient(address, nodeID string, secrets *crypto.SecretRing, timeout time.Duration, tlsConfig *tls.Config) *Client {
+	url := strings.TrimRight(address, "/")
+	httpClient := &http.Client{Timeout: timeout}
+	scheme := "http://"
+	if tlsConfig != nil {
+		scheme = "https://"
+		httpClient.Transport = &http.Transport{TLSClientConfig: tlsConfig.Clone()}
+	}
+	if !strings.Contains(url, "://") {
+		url = scheme + url
+	}
+	return &Client{
+		url:     url,
+		nodeID:  nodeID,
+		secrets: secrets,
+		http:    httpClient,
+	}
+}
+
+// RequestVote asks the witness whether this node may take over; from
+// names the active node handing over, empty when none is
+func (c *Client) RequestVote(from string) (*VoteResponse, error) {
+	ts := time.Now().Unix()
+	body, err := json.Marshal(VoteRequest{
+		NodeID:    c.nodeID,
+		From:      from,
+		Timestamp: ts,
+		Signature: crypto.SignWithTimestamp(VotePayload(c.nodeID, from), c.secrets.Current(), ts),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.http.Post(c.url+PathVote, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := readResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	var vote VoteResponse
+	if err := json.Unmarshal(respBody, &vote); err != nil {
+		return nil, fmt.Errorf("failed to parse witness vote: %w", err)
+	}
+	return &vote, nil
+}
+
+// Status fetches the witness's view of the cluster
+func (c *Client) Status() (*Status, error) {
+	req, err := http.NewRequest(http.MethodGet, c.url+PathStatus, nil)
+	if err != nil {
+		return nil, err
+	}
+	headers := crypto.SignRequestHMAC(c.secrets.Current(), c.nodeID, http.MethodGet, PathStatus, nil, time.Now().Unix())
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := readResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	var status Status
+	if err := json.Unmarshal(respBody, &status); err != nil {
+		return nil, fmt.Errorf("failed to parse witness status: %w", err)
+	}
+	return &status, nil
+}
+
+// readResponse reads a witness answer, an error unless it is 200 OK
+func readResponse(resp *http.Response) ([]byte, error) {
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("witness returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
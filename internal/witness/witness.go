@@ -0,0 +1,229 @@
+package witness
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/communication"
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/crypto"
+	"github.com/aldebaranode/syncguard/internal/history"
+	"github.com/aldebaranode/syncguard/internal/logger"
+	"github.com/aldebaranode/syncguard/internal/metrics"
+)
+
+var voteCounter = metrics.NewCounter(
+	"syncguard_witness_votes_total",
+	"Takeover votes answered by the witness",
+	"result",
+)
+
+// Observation is the witness's latest view of one node
+type Observation struct {
+	ID        string    `json:"id"`
+	Address   string    `json:"address"`
+	Reachable bool      `json:"reachable"`
+	Healthy   bool      `json:"healthy"`
+	Active    bool      `json:"active"`
+	Height    int64     `json:"height"`
+	LastSeen  time.Time `json:"last_seen,omitempty"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+// Lease is a granted takeover vote
+type Lease struct {
+	Holder  string    `json:"holder"`
+	Expires time.Time `json:"expires"`
+}
+
+// Witness is an arbiter that holds no key and runs no node.
+// It observes every peer from its own vantage point and grants a takeover
+// only to a node it can see, while no other node is active and healthy.
+// A granted vote is leased so two nodes can never both hold one.
+type Witness struct {
+	cfg        *config.Config
+	client     *communication.Client
+	secrets    *crypto.SecretRing
+	history    *history.Store
+	logger     *logger.Logger
+	staleAfter time.Duration
+	leaseTTL   time.Duration
+
+	mu           sync.Mutex
+	observations map[string]*Observation
+	lease        *Lease
+
+	httpServer *http.Server
+	stopCh     chan struct{}
+}
+
+// New creates a witness from a witness config
+func New(cfg *config.Config) *Witness {
+	newLogger := logger.New(cfg, "witness")
+
+	w := &Witness{
+		cfg:          cfg,
+		client:       communication.NewClient(cfg, nil),
+		secrets:      crypto.NewSecretRing(cfg.Secret, cfg.PreviousSecret),
+		history:      history.NewStore(cfg.History.Path, int64(cfg.History.MaxSize)),
+		logger:       newLogger,
+		staleAfter:   cfg.Witness.StaleAfter.Duration(),
+		leaseTTL:     cfg.Witness.LeaseTTL.Duration(),
+		observations: make(map[string]*Observation),
+		stopCh:       make(chan struct{}),
+	}
+	for _, peer := range cfg.Peers {
+		w.observations[peer.ID] = &Observation{ID: peer.ID, Address: peer.Address}
+	}
+	return w
+}
+
+// Observe polls every peer once and records what changed
+func (w *Witness) Observe() {
+	for _, peer := range w.cfg.Peers {
+		peerHealth, err := w.client.FetchHealth(peer.Address)
+
+		w.mu.Lock()
+		obs := w.observations[peer.ID]
+		before := *obs
+		if err != nil {
+			obs.Reachable = false
+			obs.LastError = err.Error()
+		} else {
+			obs.Reachable = true
+			obs.Healthy = peerHealth.Healthy
+			obs.Active = peerHealth.Active
+			obs.Height = peerHealth.Height
+			obs.LastSeen = time.Now().UTC()
+			obs.LastError = ""
+		}
+		after := *obs
+		w.mu.Unlock()
+
+		if before.Reachable != after.Reachable || before.Healthy != after.Healthy || before.Active != after.Active {
+			w.recordObservation(after)
+		}
+	}
+}
+
+// recordObservation writes a changed view of a peer to the history
+func (w *Witness) recordObservation(obs Observation) {
+	message := fmt.Sprintf("%s reachable=%v healthy=%v active=%v", obs.ID, obs.Reachable, obs.Healthy, obs.Active)
+	w.logger.Info("Observed %s", message)
+
+	fields := map[string]string{
+		"peer":   obs.ID,
+		"height": fmt.Sprintf("%d", obs.Height),
+	}
+	if obs.LastError != "" {
+		fields["error"] = obs.LastError
+	}
+	w.append(history.KindEvent, "observation", message, fields)
+}
+
+// Vote decides whether nodeID may take over validator duties. Votes are
+// rare, so the witness looks at every node again first rather than trust
+// what it saw up to observe_interval ago: a peer that just handed over
+// must not still count as active. A node handing over, from, does not
+// block the vote: it is still active while it asks its standby to be ready.
+func (w *Witness) Vote(nodeID, from string) VoteResponse {
+	w.Observe()
+	resp := w.decide(nodeID, from)
+
+	result := "denied"
+	if resp.Granted {
+		result = "granted"
+	}
+	voteCounter.Inc(result)
+	w.logger.Info("Takeover vote for %s %s: %s", nodeID, result, resp.Reason)
+	fields := map[string]string{
+		"requester": nodeID,
+		"result":    result,
+	}
+	if from != "" {
+		fields["from"] = from
+	}
+	w.append(history.KindDecision, "vote", resp.Reason, fields)
+	return resp
+}
+
+// decide applies the voting rules
+func (w *Witness) decide(nodeID, from string) VoteResponse {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := time.Now()
+	requester, ok := w.observations[nodeID]
+	if !ok {
+		return VoteResponse{Reason: fmt.Sprintf("%s is not a witnessed node", nodeID)}
+	}
+	if !w.fresh(requester, now) {
+		return VoteResponse{Reason: fmt.Sprintf("witness cannot reach %s", nodeID)}
+	}
+
+	for id, obs := range w.observations {
+		if id != nodeID && id != from && w.fresh(obs, now) && obs.Active && obs.Healthy {
+			return VoteResponse{Reason: fmt.Sprintf("%s is active and healthy", id)}
+		}
+	}
+
+	if w.lease != nil && w.lease.Holder != nodeID && w.lease.Holder != from && now.Before(w.lease.Expires) {
+		return VoteResponse{
+			Reason: fmt.Sprintf("vote already granted to %s", w.lease.Holder),
+			Lease:  w.lease,
+		}
+	}
+
+	w.lease = &Lease{Holder: nodeID, Expires: now.Add(w.leaseTTL).UTC()}
+	return VoteResponse{Granted: true, Reason: "no other node is active and healthy", Lease: w.lease}
+}
+
+// fresh reports whether an observation is recent enough to rely on
+func (w *Witness) fresh(obs *Observation, now time.Time) bool {
+	return obs.Reachable && now.Sub(obs.LastSeen) <= w.staleAfter
+}
+
+// Observations returns the current view of every peer, sorted by ID
+func (w *Witness) Observations() []Observation {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	list := make([]Observation, 0, len(w.observations))
+	for _, obs := range w.observations {
+		list = append(list, *obs)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+// append writes a history entry, logging failures
+func (w *Witness) append(kind history.Kind, entryType, message string, fields map[string]string) {
+	if err := w.history.Append(history.Entry{
+		Kind:    kind,
+		Type:    entryType,
+		NodeID:  w.cfg.Node.ID,
+		Message: message,
+		Fields:  fields,
+	}); err != nil {
+		w.logger.Warn("Failed to record witness history: %v", err)
+	}
+}
+
+// observeLoop polls peers until stopped
+func (w *Witness) observeLoop() {
+	ticker := time.NewTicker(w.cfg.Witness.ObserveInterval.Duration())
+	defer ticker.Stop()
+
+	w.Observe()
+	for {
+		select {
+		case <-ticker.C:
+			w.Observe()
+		case <-w.stopCh:
+			return
+		}
+	}
+}
@@ -0,0 +1,276 @@
+package witness_test
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/config"
+	"github.com/aldebaranode/syncguard/internal/crypto"
+	"github.com/aldebaranode/syncguard/internal/witness"
+)
+
+// mockNode serves a /health response with the given role and health
+func mockNode(healthy, active bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"healthy":%v,"active":%v,"height":100}`, healthy, active)
+	}))
+}
+
+func testConfig(t *testing.T, peers map[string]string) *config.Config {
+	cfg := &config.Config{
+		Secret: "test-secret",
+		Node:   config.NodeConfig{ID: "witness-1"},
+		Witness: config.WitnessConfig{
+			StaleAfter: 30,
+			LeaseTTL:   60,
+		},
+		Identity: config.IdentityConfig{MaxSkew: 30},
+		History:  config.HistoryConfig{Path: filepath.Join(t.TempDir(), "history.jsonl")},
+		Logging:  config.LoggingConfig{Level: "error", File: "/dev/null"},
+	}
+	for id, addr := range peers {
+		cfg.Peers = append(cfg.Peers, config.PeerConfig{ID: id, Address: addr})
+	}
+	return cfg
+}
+
+func TestWitness_Vote(t *testing.T) {
+	activeHealthy := mockNode(true, true)
+	defer activeHealthy.Close()
+	activeSick := mockNode(false, true)
+	defer activeSick.Close()
+	passive := mockNode(true, false)
+	defer passive.Close()
+	passive2 := mockNode(true, false)
+	defer passive2.Close()
+
+	tests := []struct {
+		name      string
+		peers     map[string]string
+		requester string
+		from      string
+		granted   bool
+	}{
+		{
+			name:      "active peer still healthy",
+			peers:     map[string]string{"primary": activeHealthy.URL, "backup": passive.URL},
+			requester: "backup",
+			granted:   false,
+		},
+		{
+			name:      "healthy active peer handing over",
+			peers:     map[string]string{"primary": activeHealthy.URL, "backup": passive.URL},
+			requester: "backup",
+			from:      "primary",
+			granted:   true,
+		},
+		{
+			name:      "active peer unhealthy",
+			peers:     map[string]string{"primary": activeSick.URL, "backup": passive.URL},
+			requester: "backup",
+			granted:   true,
+		},
+		{
+			name:      "active peer unreachable from witness",
+			peers:     map[string]string{"primary": "127.0.0.1:1", "backup": passive.URL},
+			requester: "backup",
+			granted:   true,
+		},
+		{
+			name:      "requester partitioned from witness",
+			peers:     map[string]string{"primary": activeHealthy.URL, "backup": "127.0.0.1:1"},
+			requester: "backup",
+			granted:   false,
+		},
+		{
+			name:      "unknown requester",
+			peers:     map[string]string{"primary": activeSick.URL, "backup": passive2.URL},
+			requester: "intruder",
+			granted:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := witness.New(testConfig(t, tt.peers))
+			w.Observe()
+
+			resp := w.Vote(tt.requester, tt.from)
+			if resp.Granted != tt.granted {
+				t.Errorf("Vote(%s) granted = %v, want %v (%s)", tt.requester, resp.Granted, tt.granted, resp.Reason)
+			}
+		})
+	}
+}
+
+func TestWitness_LeaseBlocksSecondGrant(t *testing.T) {
+	a := mockNode(true, false)
+	defer a.Close()
+	b := mockNode(true, false)
+	defer b.Close()
+
+	w := witness.New(testConfig(t, map[string]string{"a": a.URL, "b": b.URL}))
+	w.Observe()
+
+	if resp := w.Vote("a", ""); !resp.Granted {
+		t.Fatalf("First vote should be granted: %s", resp.Reason)
+	}
+	if resp := w.Vote("b", ""); resp.Granted {
+		t.Error("Second node must not be granted while the lease is held")
+	}
+	if resp := w.Vote("a", ""); !resp.Granted {
+		t.Errorf("Lease holder should be able to renew: %s", resp.Reason)
+	}
+	if resp := w.Vote("b", "a"); !resp.Granted || resp.Lease.Holder != "b" {
+		t.Errorf("Lease holder handing over should pass the lease on: %s", resp.Reason)
+	}
+}
+
+func TestWitness_VoteRequiresSignature(t *testing.T) {
+	node := mockNode(true, false)
+	defer node.Close()
+
+	cfg := testConfig(t, map[string]string{"backup": node.URL})
+	w := witness.New(cfg)
+	w.Observe()
+	server := httptest.NewServer(w.Handler())
+	defer server.Close()
+
+	post := func(req witness.VoteRequest) *http.Response {
+		body, _ := json.Marshal(req)
+		resp, err := http.Post(server.URL+witness.PathVote, "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("POST failed: %v", err)
+		}
+		return resp
+	}
+
+	ts := time.Now().Unix()
+	bad := post(witness.VoteRequest{NodeID: "backup", Timestamp: ts, Signature: crypto.SignWithTimestamp(witness.VotePayload("backup", ""), "wrong", ts)})
+	bad.Body.Close()
+	if bad.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Wrong secret: status %d, want 401", bad.StatusCode)
+	}
+
+	good := post(witness.VoteRequest{NodeID: "backup", Timestamp: ts, Signature: crypto.SignWithTimestamp(witness.VotePayload("backup", ""), cfg.Secret, ts)})
+	defer good.Body.Close()
+	var vote witness.VoteResponse
+	if err := json.NewDecoder(good.Body).Decode(&vote); err != nil {
+		t.Fatalf("Failed to decode vote: %v", err)
+	}
+	if !vote.Granted {
+		t.Errorf("Signed vote should be granted: %s", vote.Reason)
+	}
+}
+
+func TestClient_RequestVote(t *testing.T) {
+	node := mockNode(true, false)
+	defer node.Close()
+
+	cfg := testConfig(t, map[string]string{"backup": node.URL})
+	w := witness.New(cfg)
+	w.Observe()
+	server := httptest.NewServer(w.Handler())
+	defer server.Close()
+
+	vote, err := witness.NewClient(server.URL, "backup", crypto.NewSecretRing(cfg.Secret, ""), time.Second, nil).RequestVote("")
+	if err != nil {
+		t.Fatalf("RequestVote failed: %v", err)
+	}
+	if !vote.Granted {
+		t.Errorf("Vote should be granted: %s", vote.Reason)
+	}
+
+	_, err = witness.NewClient(server.URL, "backup", crypto.NewSecretRing("wrong", ""), time.Second, nil).RequestVote("")
+	if err == nil {
+		t.Error("A vote signed with the wrong secret should fail")
+	}
+}
+
+func TestClient_Status(t *testing.T) {
+	node := mockNode(true, false)
+	defer node.Close()
+
+	cfg := testConfig(t, map[string]string{"backup": node.URL})
+	w := witness.New(cfg)
+	w.Observe()
+	server := httptest.NewServer(w.Handler())
+	defer server.Close()
+
+	// The status maps the cluster, so it is not served to just anyone
+	resp, err := http.Get(server.URL + witness.PathStatus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Unsigned status request: status %d, want 401", resp.StatusCode)
+	}
+
+	status, err := witness.NewClient(server.URL, "backup", crypto.NewSecretRing(cfg.Secret, ""), time.Second, nil).Status()
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if status.WitnessID != "witness-1" || len(status.Peers) != 1 || status.Peers[0].ID != "backup" {
+		t.Errorf("Status = %+v", status)
+	}
+}
+
+func TestClient_MutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca, err := crypto.GenerateCA("test CA", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tlsConfig := func(name string) *tls.Config {
+		bundle, err := crypto.IssueCertificate(ca, name, []string{"127.0.0.1"}, time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		caFile, certFile, keyFile := filepath.Join(dir, "ca.pem"), filepath.Join(dir, name+".pem"), filepath.Join(dir, name+"-key.pem")
+		for path, data := range map[string][]byte{caFile: ca.CertPEM, certFile: bundle.CertPEM, keyFile: bundle.KeyPEM} {
+			if err := os.WriteFile(path, data, 0600); err != nil {
+				t.Fatal(err)
+			}
+		}
+		config, err := crypto.LoadTLSConfig(caFile, certFile, keyFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return config
+	}
+
+	node := mockNode(true, false)
+	defer node.Close()
+	cfg := testConfig(t, map[string]string{"backup": node.URL})
+	w := witness.New(cfg)
+	w.Observe()
+	server := httptest.NewUnstartedServer(w.Handler())
+	server.TLS = tlsConfig("witness-1")
+	server.StartTLS()
+	defer server.Close()
+	addr := server.Listener.Addr().String()
+
+	vote, err := witness.NewClient(addr, "backup", crypto.NewSecretRing(cfg.Secret, ""), time.Second, tlsConfig("backup")).RequestVote("")
+	if err != nil {
+		t.Fatalf("RequestVote over mutual TLS failed: %v", err)
+	}
+	if !vote.Granted {
+		t.Errorf("Vote should be granted: %s", vote.Reason)
+	}
+
+	// Without a certificate from the cluster CA the witness does not answer
+	_, err = witness.NewClient("https://"+addr, "backup", crypto.NewSecretRing(cfg.Secret, ""), time.Second,
+		&tls.Config{InsecureSkipVerify: true}).RequestVote("")
+	if err == nil {
+		t.Error("A vote without a client certificate should fail")
+	}
+}
@@ -0,0 +1,72 @@
+package wrapper
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"text/template"
+)
+
+var launchdTemplate = template.Must(template.New("plist").Funcs(template.FuncMap{
+	"xml": func(s string) (string, error) {
+		var buf bytes.Buffer
+		err := xml.EscapeText(&buf, []byte(s))
+		return buf.String(), err
+	},
+}).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>{{xml .Label}}</string>
+	<key>ProgramArguments</key>
+	<array>
+{{- range .Argv}}
+		<string>{{xml .}}</string>
+{{- end}}
+	</array>
+{{- if .WorkingDir}}
+	<key>WorkingDirectory</key>
+	<string>{{xml .WorkingDir}}</string>
+{{- end}}
+	<key>RunAtLoad</key>
+	<true/>
+	<key>KeepAlive</key>
+	<dict>
+		<key>SuccessfulExit</key>
+		<false/>
+	</dict>
+	<key>ThrottleInterval</key>
+	<integer>{{.Throttle}}</integer>
+	<key>ExitTimeOut</key>
+	<integer>{{.ExitTimeout}}</integer>
+	<key>StandardOutPath</key>
+	<string>{{xml .LogPath}}</string>
+	<key>StandardErrorPath</key>
+	<string>{{xml .LogPath}}</string>
+</dict>
+</plist>
+`))
+
+// LaunchdLabel is the job label for a service name
+func LaunchdLabel(name string) string {
+	return "io.aldebaranode." + name
+}
+
+// LaunchdPlist renders the launchd job for cfg. The job starts at load and
+// is restarted when it exits with an error, at most every RestartDelay.
+func LaunchdPlist(cfg Config, logPath string) (string, error) {
+	var buf bytes.Buffer
+	err := launchdTemplate.Execute(&buf, map[string]interface{}{
+		"Label":       LaunchdLabel(cfg.Name),
+		"Argv":        append([]string{cfg.Executable}, cfg.Args...),
+		"WorkingDir":  cfg.WorkingDir,
+		"Throttle":    int(RestartDelay.Seconds()),
+		"ExitTimeout": int(cfg.StopTimeout.Seconds()),
+		"LogPath":     logPath,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to render plist: %w", err)
+	}
+	return buf.String(), nil
+}
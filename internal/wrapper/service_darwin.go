@@ -0,0 +1,108 @@
+package wrapper
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// launchdService is a LaunchDaemon when installed as root and a LaunchAgent
+// of the current user otherwise
+type launchdService struct {
+	cfg  Config
+	dir  string
+	logs string
+}
+
+func newPlatformService(cfg Config) (Service, error) {
+	if os.Geteuid() == 0 {
+		return &launchdService{cfg: cfg, dir: "/Library/LaunchDaemons", logs: "/Library/Logs"}, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find home directory: %w", err)
+	}
+	return &launchdService{
+		cfg:  cfg,
+		dir:  filepath.Join(home, "Library", "LaunchAgents"),
+		logs: filepath.Join(home, "Library", "Logs"),
+	}, nil
+}
+
+func (s *launchdService) Path() string {
+	return filepath.Join(s.dir, LaunchdLabel(s.cfg.Name)+".plist")
+}
+
+func (s *launchdService) Install() error {
+	if _, err := os.Stat(s.Path()); err == nil {
+		return fmt.Errorf("%s already exists", s.Path())
+	}
+	plist, err := LaunchdPlist(s.cfg, filepath.Join(s.logs, s.cfg.Name+".log"))
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", s.dir, err)
+	}
+	if err := os.WriteFile(s.Path(), []byte(plist), 0644); err != nil {
+		return fmt.Errorf("failed to write plist: %w", err)
+	}
+	return nil
+}
+
+func (s *launchdService) Uninstall() error {
+	if _, err := os.Stat(s.Path()); os.IsNotExist(err) {
+		return ErrNotInstalled
+	}
+	if st, _ := s.Status(); st == StatusRunning {
+		if err := s.Stop(); err != nil {
+			return err
+		}
+	}
+	if err := os.Remove(s.Path()); err != nil {
+		return fmt.Errorf("failed to remove plist: %w", err)
+	}
+	return nil
+}
+
+// Start loads the job; with RunAtLoad that starts it
+func (s *launchdService) Start() error {
+	if _, err := os.Stat(s.Path()); os.IsNotExist(err) {
+		return ErrNotInstalled
+	}
+	return launchctl("load", "-w", s.Path())
+}
+
+// Stop unloads the job, since KeepAlive would restart a killed daemon
+func (s *launchdService) Stop() error {
+	if _, err := os.Stat(s.Path()); os.IsNotExist(err) {
+		return ErrNotInstalled
+	}
+	return launchctl("unload", "-w", s.Path())
+}
+
+func (s *launchdService) Status() (Status, error) {
+	if _, err := os.Stat(s.Path()); os.IsNotExist(err) {
+		return StatusNotInstalled, nil
+	}
+	out, err := exec.Command("launchctl", "list", LaunchdLabel(s.cfg.Name)).Output()
+	if err != nil {
+		// Not loaded
+		return StatusStopped, nil
+	}
+	if strings.Contains(string(out), `"PID" = `) {
+		return StatusRunning, nil
+	}
+	return StatusStopped, nil
+}
+
+// launchctl runs a launchctl subcommand, returning its output on failure
+func launchctl(args ...string) error {
+	out, err := exec.Command("launchctl", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("launchctl %s failed: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package wrapper
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// systemdDir is where system units are installed
+const systemdDir = "/etc/systemd/system"
+
+type systemdService struct {
+	cfg Config
+}
+
+func newPlatformService(cfg Config) (Service, error) {
+	return &systemdService{cfg: cfg}, nil
+}
+
+func (s *systemdService) Path() string {
+	return filepath.Join(systemdDir, s.cfg.Name+".service")
+}
+
+func (s *systemdService) Install() error {
+	if _, err := os.Stat(s.Path()); err == nil {
+		return fmt.Errorf("%s already exists", s.Path())
+	}
+	unit, err := SystemdUnit(s.cfg)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(s.Path(), []byte(unit), 0644); err != nil {
+		return fmt.Errorf("failed to write unit: %w", err)
+	}
+	if err := systemctl("daemon-reload"); err != nil {
+		return err
+	}
+	return systemctl("enable", s.cfg.Name)
+}
+
+func (s *systemdService) Uninstall() error {
+	if _, err := os.Stat(s.Path()); os.IsNotExist(err) {
+		return ErrNotInstalled
+	}
+	// Stopping a stopped unit is not an error
+	if err := systemctl("disable", "--now", s.cfg.Name); err != nil {
+		return err
+	}
+	if err := os.Remove(s.Path()); err != nil {
+		return fmt.Errorf("failed to remove unit: %w", err)
+	}
+	return systemctl("daemon-reload")
+}
+
+func (s *systemdService) Start() error {
+	if _, err := os.Stat(s.Path()); os.IsNotExist(err) {
+		return ErrNotInstalled
+	}
+	return systemctl("start", s.cfg.Name)
+}
+
+func (s *systemdService) Stop() error {
+	if _, err := os.Stat(s.Path()); os.IsNotExist(err) {
+		return ErrNotInstalled
+	}
+	return systemctl("stop", s.cfg.Name)
+}
+
+func (s *systemdService) Status() (Status, error) {
+	if _, err := os.Stat(s.Path()); os.IsNotExist(err) {
+		return StatusNotInstalled, nil
+	}
+	// is-active exits non-zero for anything but active, so read the output
+	out, _ := exec.Command("systemctl", "is-active", s.cfg.Name).Output()
+	switch strings.TrimSpace(string(out)) {
+	case "active", "activating", "reloading":
+		return StatusRunning, nil
+	case "inactive", "failed", "deactivating":
+		return StatusStopped, nil
+	default:
+		return "", fmt.Errorf("unexpected unit state %q", strings.TrimSpace(string(out)))
+	}
+}
+
+// systemctl runs a systemctl subcommand, returning its output on failure
+func systemctl(args ...string) error {
+	out, err := exec.Command("systemctl", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("systemctl %s failed: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
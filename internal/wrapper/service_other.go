@@ -0,0 +1,12 @@
+//go:build !linux && !darwin && !windows
+
+package wrapper
+
+import (
+	"fmt"
+	"runtime"
+)
+
+func newPlatformService(cfg Config) (Service, error) {
+	return nil, fmt.Errorf("service integration is not supported on %s", runtime.GOOS)
+}
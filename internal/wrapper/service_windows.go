@@ -0,0 +1,214 @@
+package wrapper
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+type windowsService struct {
+	cfg Config
+}
+
+func newPlatformService(cfg Config) (Service, error) {
+	return &windowsService{cfg: cfg}, nil
+}
+
+func (s *windowsService) Path() string {
+	return `HKLM\SYSTEM\CurrentControlSet\Services\` + s.cfg.Name
+}
+
+// open connects to the service manager and opens the service
+func (s *windowsService) open() (*mgr.Mgr, *mgr.Service, error) {
+	m, err := mgr.Connect()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to service manager: %w", err)
+	}
+	service, err := m.OpenService(s.cfg.Name)
+	if err != nil {
+		m.Disconnect()
+		if errors.Is(err, windows.ERROR_SERVICE_DOES_NOT_EXIST) {
+			return nil, nil, ErrNotInstalled
+		}
+		return nil, nil, fmt.Errorf("failed to open service: %w", err)
+	}
+	return m, service, nil
+}
+
+func (s *windowsService) Install() error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("failed to connect to service manager: %w", err)
+	}
+	defer m.Disconnect()
+
+	if existing, err := m.OpenService(s.cfg.Name); err == nil {
+		existing.Close()
+		return fmt.Errorf("service %s already exists", s.cfg.Name)
+	}
+
+	service, err := m.CreateService(s.cfg.Name, s.cfg.Executable, mgr.Config{
+		DisplayName: s.cfg.DisplayName,
+		Description: s.cfg.Description,
+		StartType:   mgr.StartAutomatic,
+	}, s.cfg.Args...)
+	if err != nil {
+		return fmt.Errorf("failed to create service: %w", err)
+	}
+	defer service.Close()
+
+	// Restart after every failure; the counter resets after a day up
+	restart := mgr.RecoveryAction{Type: mgr.ServiceRestart, Delay: RestartDelay}
+	if err := service.SetRecoveryActions([]mgr.RecoveryAction{restart, restart, restart}, 86400); err != nil {
+		return fmt.Errorf("failed to set recovery actions: %w", err)
+	}
+	// Failures include a non-zero exit after a clean stop report
+	if err := service.SetRecoveryActionsOnNonCrashFailures(true); err != nil {
+		return fmt.Errorf("failed to set recovery on errors: %w", err)
+	}
+	return nil
+}
+
+func (s *windowsService) Uninstall() error {
+	m, service, err := s.open()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+	defer service.Close()
+
+	if st, _ := s.Status(); st == StatusRunning {
+		if err := s.Stop(); err != nil {
+			return err
+		}
+	}
+	if err := service.Delete(); err != nil {
+		return fmt.Errorf("failed to delete service: %w", err)
+	}
+	return nil
+}
+
+func (s *windowsService) Start() error {
+	m, service, err := s.open()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+	defer service.Close()
+
+	if err := service.Start(); err != nil {
+		return fmt.Errorf("failed to start service: %w", err)
+	}
+	return nil
+}
+
+func (s *windowsService) Stop() error {
+	m, service, err := s.open()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+	defer service.Close()
+
+	status, err := service.Control(svc.Stop)
+	if err != nil {
+		return fmt.Errorf("failed to stop service: %w", err)
+	}
+	deadline := time.Now().Add(s.cfg.StopTimeout)
+	for status.State != svc.Stopped {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("service did not stop within %s", s.cfg.StopTimeout)
+		}
+		time.Sleep(500 * time.Millisecond)
+		if status, err = service.Query(); err != nil {
+			return fmt.Errorf("failed to query service: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *windowsService) Status() (Status, error) {
+	m, service, err := s.open()
+	if errors.Is(err, ErrNotInstalled) {
+		return StatusNotInstalled, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	defer m.Disconnect()
+	defer service.Close()
+
+	status, err := service.Query()
+	if err != nil {
+		return "", fmt.Errorf("failed to query service: %w", err)
+	}
+	if status.State == svc.Stopped {
+		return StatusStopped, nil
+	}
+	return StatusRunning, nil
+}
+
+// handler reports the stop request to WaitForStop and holds the service
+// in StopPending until the daemon has shut down
+type handler struct {
+	stopped chan string
+	done    chan struct{}
+}
+
+func (h *handler) Execute(args []string, requests <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	status <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+	for req := range requests {
+		switch req.Cmd {
+		case svc.Interrogate:
+			status <- req.CurrentStatus
+		case svc.Stop, svc.Shutdown:
+			status <- svc.Status{State: svc.StopPending, WaitHint: 60000}
+			reason := "stop"
+			if req.Cmd == svc.Shutdown {
+				reason = "shutdown"
+			}
+			h.stopped <- reason
+			<-h.done
+			return false, 0
+		}
+	}
+	return false, 0
+}
+
+// WaitForStop blocks until the daemon is asked to stop and returns why,
+// and a function to call once shutdown is complete. Under the service
+// manager the stop request arrives as a control code and the service
+// reports stopped only when done is called; run from a console, Ctrl+C
+// stops it.
+func WaitForStop(name string) (string, func()) {
+	isService, err := svc.IsWindowsService()
+	if err != nil || !isService {
+		signalChan := make(chan os.Signal, 1)
+		signal.Notify(signalChan, os.Interrupt)
+		return (<-signalChan).String(), func() {}
+	}
+
+	h := &handler{stopped: make(chan string, 1), done: make(chan struct{})}
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		if err := svc.Run(name, h); err != nil {
+			h.stopped <- err.Error()
+		}
+	}()
+	reason := <-h.stopped
+	return reason, func() {
+		close(h.done)
+		// Let the service manager see the stopped state before exiting
+		select {
+		case <-finished:
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package wrapper
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+var systemdTemplate = template.Must(template.New("unit").Parse(`[Unit]
+Description={{.Description}}
+After=network-online.target
+Wants=network-online.target
+StartLimitIntervalSec=0
+
+[Service]
+Type=simple
+ExecStart={{.ExecStart}}
+{{- if .WorkingDir}}
+WorkingDirectory={{.WorkingDir}}
+{{- end}}
+{{- if .User}}
+User={{.User}}
+{{- end}}
+Restart=on-failure
+RestartSec={{.RestartSec}}
+KillSignal=SIGTERM
+TimeoutStopSec={{.StopSec}}
+LimitNOFILE=65535
+
+[Install]
+WantedBy=multi-user.target
+`))
+
+// SystemdUnit renders the systemd unit for cfg. The daemon is restarted
+// after a failure, without a start limit, and given StopTimeout to release
+// the signing lock on shutdown.
+func SystemdUnit(cfg Config) (string, error) {
+	description := cfg.Description
+	if description == "" {
+		description = cfg.DisplayName
+	}
+
+	var buf bytes.Buffer
+	err := systemdTemplate.Execute(&buf, map[string]interface{}{
+		"Description": description,
+		"ExecStart":   systemdCommand(append([]string{cfg.Executable}, cfg.Args...)),
+		"WorkingDir":  cfg.WorkingDir,
+		"User":        cfg.User,
+		"RestartSec":  int(RestartDelay.Seconds()),
+		"StopSec":     int(cfg.StopTimeout.Seconds()),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to render unit: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// systemdCommand quotes a command line for ExecStart
+func systemdCommand(argv []string) string {
+	quoted := make([]string, len(argv))
+	for i, arg := range argv {
+		if arg != "" && !strings.ContainsAny(arg, " \t\"'\\$%") {
+			quoted[i] = arg
+			continue
+		}
+		r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `$$`, `%`, `%%`)
+		quoted[i] = `"` + r.Replace(arg) + `"`
+	}
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,18 @@
+//go:build !windows
+
+package wrapper
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// WaitForStop blocks until the daemon is asked to stop and returns why,
+// and a function to call once shutdown is complete. systemd and launchd
+// stop the daemon with SIGTERM.
+func WaitForStop(name string) (string, func()) {
+	signalChan := make(chan os.Signal, 1)
+	signal.Notify(signalChan, syscall.SIGINT, syscall.SIGTERM)
+	return (<-signalChan).String(), func() {}
+}
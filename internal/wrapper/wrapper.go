@@ -0,0 +1,67 @@
+// Package wrapper registers syncguard with the host's service manager:
+// a systemd unit on Linux, a launchd job on macOS and a Windows service.
+// Every platform restarts the daemon when it exits with an error, and
+// leaves it stopped after a clean shutdown.
+package wrapper
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// RestartDelay is how long the service manager waits before restarting
+// a failed daemon
+const RestartDelay = 5 * time.Second
+
+// ErrNotInstalled is returned when acting on a service that is not registered
+var ErrNotInstalled = errors.New("service is not installed")
+
+// Status of an installed service
+type Status string
+
+const (
+	StatusRunning      Status = "running"
+	StatusStopped      Status = "stopped"
+	StatusNotInstalled Status = "not installed"
+)
+
+// Config describes the service to register
+type Config struct {
+	Name        string // Unit, job label suffix or Windows service name
+	DisplayName string
+	Description string
+	Executable  string // Absolute path of the syncguard binary
+	Args        []string
+	WorkingDir  string
+	User        string // Account to run as (systemd only)
+	StopTimeout time.Duration
+}
+
+// Service is a daemon registered with the host's service manager
+type Service interface {
+	Install() error
+	Uninstall() error
+	Start() error
+	Stop() error
+	Status() (Status, error)
+	// Path is where the definition lives (unit file, plist, registry key)
+	Path() string
+}
+
+// New returns the service for this platform
+func New(cfg Config) (Service, error) {
+	if cfg.Name == "" {
+		return nil, fmt.Errorf("service name is required")
+	}
+	if cfg.Executable == "" {
+		return nil, fmt.Errorf("service executable is required")
+	}
+	if cfg.DisplayName == "" {
+		cfg.DisplayName = cfg.Name
+	}
+	if cfg.StopTimeout == 0 {
+		cfg.StopTimeout = 60 * time.Second
+	}
+	return newPlatformService(cfg)
+}
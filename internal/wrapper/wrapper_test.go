@@ -0,0 +1,81 @@
+package wrapper
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func testConfig() Config {
+	return Config{
+		Name:        "syncguard",
+		DisplayName: "SyncGuard",
+		Description: "SyncGuard validator failover daemon",
+		Executable:  "/usr/local/bin/syncguard",
+		Args:        []string{"--config", "/etc/syncguard/my config.yaml"},
+		WorkingDir:  "/etc/syncguard",
+		User:        "syncguard",
+		StopTimeout: time.Minute,
+	}
+}
+
+func TestSystemdUnit(t *testing.T) {
+	unit, err := SystemdUnit(testConfig())
+	if err != nil {
+		t.Fatalf("SystemdUnit failed: %v", err)
+	}
+	for _, want := range []string{
+		`ExecStart=/usr/local/bin/syncguard --config "/etc/syncguard/my config.yaml"`,
+		"WorkingDirectory=/etc/syncguard",
+		"User=syncguard",
+		"Restart=on-failure",
+		"RestartSec=5",
+		"TimeoutStopSec=60",
+		"StartLimitIntervalSec=0",
+	} {
+		if !strings.Contains(unit, want) {
+			t.Errorf("unit missing %q:\n%s", want, unit)
+		}
+	}
+}
+
+func TestSystemdUnit_NoUser(t *testing.T) {
+	cfg := testConfig()
+	cfg.User = ""
+	unit, err := SystemdUnit(cfg)
+	if err != nil {
+		t.Fatalf("SystemdUnit failed: %v", err)
+	}
+	if strings.Contains(unit, "User=") {
+		t.Errorf("expected no User= line:\n%s", unit)
+	}
+}
+
+func TestLaunchdPlist(t *testing.T) {
+	cfg := testConfig()
+	cfg.Args = append(cfg.Args, "<&>")
+	plist, err := LaunchdPlist(cfg, "/Library/Logs/syncguard.log")
+	if err != nil {
+		t.Fatalf("LaunchdPlist failed: %v", err)
+	}
+	for _, want := range []string{
+		"<string>io.aldebaranode.syncguard</string>",
+		"<string>/etc/syncguard/my config.yaml</string>",
+		"<string>&lt;&amp;&gt;</string>",
+		"<key>SuccessfulExit</key>\n\t\t<false/>",
+		"<key>ThrottleInterval</key>\n\t<integer>5</integer>",
+	} {
+		if !strings.Contains(plist, want) {
+			t.Errorf("plist missing %q:\n%s", want, plist)
+		}
+	}
+}
+
+func TestNew_Validation(t *testing.T) {
+	if _, err := New(Config{Executable: "/bin/true"}); err == nil {
+		t.Error("expected an error without a name")
+	}
+	if _, err := New(Config{Name: "syncguard"}); err == nil {
+		t.Error("expected an error without an executable")
+	}
+}
@@ -0,0 +1,575 @@
+// Package client is a typed client for the SyncGuard admin API. It queries
+// every node of a cluster concurrently, merges their views, and performs
+// operator actions with retries. The syncguard CLI uses it for its cluster
+// and drill commands, and it has no dependencies outside the standard
+// library so operator tooling can embed it.
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Admin API paths
+const (
+	PathStatus        = "/admin/status"
+	PathPause         = "/admin/pause"
+	PathResume        = "/admin/resume"
+	PathHandoff       = "/admin/handoff"
+	PathFailback      = "/admin/failback"
+	PathDrill         = "/admin/drill"
+	PathDrillRevert   = "/admin/drill/revert"
+	PathRetireSecret  = "/admin/secret/retire"
+	PathApprove       = "/admin/failover/approve"
+	PathReject        = "/admin/failover/reject"
+	PathConfirmActive = "/admin/confirm-active"
+	PathDrain         = "/admin/drain"
+	PathUndrain       = "/admin/undrain"
+)
+
+// ErrNoActiveNode is returned by Handoff when no node reports itself active
+var ErrNoActiveNode = errors.New("no active node in cluster")
+
+// ErrNoPrimaryNode is returned by Failback when no reachable node is the primary
+var ErrNoPrimaryNode = errors.New("no primary node in cluster")
+
+// ErrNoApproval is returned by ApproveFailover and RejectFailover when no
+// reachable node has a failover awaiting approval
+var ErrNoApproval = errors.New("no failover awaiting approval")
+
+// ErrNoFirstBoot is returned by ConfirmActive when no reachable node is
+// held on first boot
+var ErrNoFirstBoot = errors.New("no node awaiting first-boot confirmation")
+
+// Node is one SyncGuard instance reachable over its admin API
+type Node struct {
+	ID  string
+	URL string // e.g. http://10.0.0.1:9090
+}
+
+// Config configures a ClusterClient
+type Config struct {
+	Nodes []Node
+	// Token is sent as a bearer token; required for non-loopback nodes
+	Token string
+	// Timeout bounds each HTTP attempt (default 10s)
+	Timeout time.Duration
+	// Retries is the number of extra attempts after a transport error or
+	// 5xx response (default 2)
+	Retries int
+	// Backoff is the wait before the first retry, doubled after each (default 500ms)
+	Backoff time.Duration
+	// HTTPClient overrides the HTTP client, e.g. for custom TLS
+	HTTPClient *http.Client
+}
+
+// NodeStatus is the admin status of a single node
+type NodeStatus struct {
+	NodeID  string    `json:"node_id"`
+	Time    time.Time `json:"time"`
+	Healthy bool      `json:"healthy"`
+	Active  bool      `json:"active"`
+	Primary bool      `json:"primary"`
+	Paused  bool      `json:"paused"`
+	Height  int64     `json:"height"`
+	// Secret is absent on nodes that predate secret rotation
+	Secret *SecretStatus `json:"secret,omitempty"`
+	// Approval is set while a failover awaits approval, or after one was
+	// rejected for the rest of the outage
+	Approval *ApprovalRequest `json:"approval,omitempty"`
+	// Progress is absent on nodes that predate failover countdowns
+	Progress *Progress `json:"progress,omitempty"`
+	// PeerProgress is the peer's countdown as the node last heard it over
+	// heartbeats
+	PeerProgress *Progress `json:"peer_progress,omitempty"`
+	// Transitions is absent on nodes that predate transition summaries
+	Transitions *Transitions `json:"transition_summary,omitempty"`
+	// AwaitingConfirmation is set on a node configured active but held
+	// passive on first boot
+	AwaitingConfirmation bool `json:"awaiting_confirmation,omitempty"`
+	// MonitorOnly is set on a node that watches and alerts with
+	// automation off
+	MonitorOnly bool `json:"monitor_only,omitempty"`
+	// Draining is set on a node an operator drained
+	Draining bool `json:"draining,omitempty"`
+}
+
+// Progress shows how close a node is to failover or failback
+type Progress struct {
+	Role string `json:"role"`
+	// Since is when the node last changed role or health
+	Since          time.Time `json:"since"`
+	InStateSeconds float64   `json:"in_state_seconds"`
+	FailureCount   int       `json:"failure_count"`
+	// FailoverAfter is the consecutive failures that end an active node's duties
+	FailoverAfter int `json:"failover_after"`
+	// FailbackAfterSeconds is how long a recovered primary must stay healthy
+	FailbackAfterSeconds float64    `json:"failback_after_seconds"`
+	NextCheck            time.Time  `json:"next_check,omitempty"`
+	NextStateSync        *time.Time `json:"next_state_sync,omitempty"`
+}
+
+// Transitions sums up a node's changes of role: the epoch counts them,
+// Last is when each type (failover, failback, takeover, release) last
+// happened and Reasons counts them by reason code
+type Transitions struct {
+	Epoch   int                  `json:"epoch"`
+	Last    map[string]time.Time `json:"last,omitempty"`
+	Reasons map[string]int       `json:"reasons,omitempty"`
+}
+
+// ApprovalRequest is a failover held for an operator's approval
+type ApprovalRequest struct {
+	ID            string     `json:"id"`
+	Reason        string     `json:"reason"`
+	Requested     time.Time  `json:"requested"`
+	AutoApproveAt *time.Time `json:"auto_approve_at,omitempty"`
+	Checks        []string   `json:"checks,omitempty"`
+	Problems      []string   `json:"problems,omitempty"`
+	// Phase is pending, approved or rejected
+	Phase     string `json:"phase"`
+	DecidedBy string `json:"decided_by,omitempty"`
+}
+
+// Pending reports whether the request still awaits a decision
+func (r *ApprovalRequest) Pending() bool {
+	return r != nil && r.Phase == "pending"
+}
+
+// SecretStatus reports the cluster secrets a node accepts, by fingerprint
+type SecretStatus struct {
+	Fingerprint string `json:"fingerprint"`
+	// Previous is the fingerprint of the previous secret, while accepted
+	Previous string `json:"previous,omitempty"`
+	// PreviousUsed is when a peer last authenticated with the previous secret
+	PreviousUsed *time.Time `json:"previous_used,omitempty"`
+}
+
+// NodeView is one node's answer in a ClusterView
+type NodeView struct {
+	Node   Node
+	Status *NodeStatus
+	Err    error
+}
+
+// ClusterView merges the status of every node
+type ClusterView struct {
+	Nodes []NodeView
+	// Active lists the IDs of nodes reporting themselves active
+	Active []string
+	// MaxHeight is the highest block height reported
+	MaxHeight int64
+}
+
+// SplitBrain reports whether more than one node claims to be active
+func (v ClusterView) SplitBrain() bool {
+	return len(v.Active) > 1
+}
+
+// Reachable returns the number of nodes that answered
+func (v ClusterView) Reachable() int {
+	n := 0
+	for _, nv := range v.Nodes {
+		if nv.Err == nil {
+			n++
+		}
+	}
+	return n
+}
+
+// DrillStatus is the state of a failover drill
+type DrillStatus struct {
+	ID                  string    `json:"id"`
+	Trigger             string    `json:"trigger"`
+	Phase               string    `json:"phase"`
+	Started             time.Time `json:"started"`
+	RevertAt            time.Time `json:"revert_at"`
+	Finished            time.Time `json:"finished,omitempty"`
+	DetectionSeconds    float64   `json:"detection_seconds"`
+	ReleaseSeconds      float64   `json:"release_seconds"`
+	HandoffSeconds      float64   `json:"handoff_seconds"`
+	StandbySeconds      float64   `json:"standby_seconds"`
+	FailbackSeconds     float64   `json:"failback_seconds"`
+	EstimatedRTOSeconds float64   `json:"estimated_rto_seconds"`
+	Error               string    `json:"error,omitempty"`
+}
+
+// Done reports whether the drill has finished
+func (s DrillStatus) Done() bool {
+	return s.Phase == "completed" || s.Phase == "failed"
+}
+
+// APIError is a non-2xx answer from a node
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// ClusterClient talks to the admin API of every node in a cluster
+type ClusterClient struct {
+	nodes   []Node
+	token   string
+	retries int
+	backoff time.Duration
+	http    *http.Client
+}
+
+// New creates a ClusterClient
+func New(cfg Config) (*ClusterClient, error) {
+	if len(cfg.Nodes) == 0 {
+		return nil, fmt.Errorf("no nodes configured")
+	}
+	for _, n := range cfg.Nodes {
+		if _, err := url.Parse(n.URL); err != nil || n.URL == "" {
+			return nil, fmt.Errorf("node %s has invalid URL %q", n.ID, n.URL)
+		}
+	}
+
+	c := &ClusterClient{
+		nodes:   cfg.Nodes,
+		token:   cfg.Token,
+		retries: cfg.Retries,
+		backoff: cfg.Backoff,
+		http:    cfg.HTTPClient,
+	}
+	if c.retries == 0 {
+		c.retries = 2
+	} else if c.retries < 0 {
+		c.retries = 0
+	}
+	if c.backoff == 0 {
+		c.backoff = 500 * time.Millisecond
+	}
+	if c.http == nil {
+		timeout := cfg.Timeout
+		if timeout == 0 {
+			timeout = 10 * time.Second
+		}
+		c.http = &http.Client{Timeout: timeout}
+	}
+	return c, nil
+}
+
+// Nodes returns the configured nodes
+func (c *ClusterClient) Nodes() []Node {
+	return c.nodes
+}
+
+// Node returns the configured node with the given ID
+func (c *ClusterClient) Node(id string) (Node, error) {
+	for _, n := range c.nodes {
+		if n.ID == id {
+			return n, nil
+		}
+	}
+	return Node{}, fmt.Errorf("unknown node %q", id)
+}
+
+// Status queries every node concurrently and merges the answers. A node
+// that cannot be reached is reported in its NodeView, not as an error.
+func (c *ClusterClient) Status(ctx context.Context) ClusterView {
+	view := ClusterView{Nodes: make([]NodeView, len(c.nodes))}
+
+	var wg sync.WaitGroup
+	for i, n := range c.nodes {
+		wg.Add(1)
+		go func(i int, n Node) {
+			defer wg.Done()
+			status, err := c.NodeStatus(ctx, n)
+			view.Nodes[i] = NodeView{Node: n, Status: status, Err: err}
+		}(i, n)
+	}
+	wg.Wait()
+
+	for _, nv := range view.Nodes {
+		if nv.Status == nil {
+			continue
+		}
+		if nv.Status.Active {
+			view.Active = append(view.Active, nv.Node.ID)
+		}
+		if nv.Status.Height > view.MaxHeight {
+			view.MaxHeight = nv.Status.Height
+		}
+	}
+	return view
+}
+
+// NodeStatus fetches the status of a single node
+func (c *ClusterClient) NodeStatus(ctx context.Context, n Node) (*NodeStatus, error) {
+	var status NodeStatus
+	if err := c.do(ctx, n, http.MethodGet, PathStatus, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// Pause suspends automatic failover on every node. All nodes are tried;
+// the first error is returned.
+func (c *ClusterClient) Pause(ctx context.Context) error {
+	return c.each(ctx, PathPause)
+}
+
+// Resume re-enables automatic failover on every node
+func (c *ClusterClient) Resume(ctx context.Context) error {
+	return c.each(ctx, PathResume)
+}
+
+// Handoff asks the active node to hand validator duties to its standby.
+// It refuses while the cluster is split-brained.
+func (c *ClusterClient) Handoff(ctx context.Context) (Node, error) {
+	view := c.Status(ctx)
+	if view.SplitBrain() {
+		return Node{}, fmt.Errorf("split brain: %s all active", strings.Join(view.Active, ", "))
+	}
+	if len(view.Active) == 0 {
+		return Node{}, ErrNoActiveNode
+	}
+
+	active, err := c.Node(view.Active[0])
+	if err != nil {
+		return Node{}, err
+	}
+	// Not retried: a handoff that timed out may still have happened
+	return active, c.once(ctx, active, http.MethodPost, PathHandoff, nil)
+}
+
+// Failback asks the primary to reclaim validator duties from the standby.
+// It refuses while the cluster is split-brained or the primary is active.
+func (c *ClusterClient) Failback(ctx context.Context) (Node, error) {
+	view := c.Status(ctx)
+	if view.SplitBrain() {
+		return Node{}, fmt.Errorf("split brain: %s all active", strings.Join(view.Active, ", "))
+	}
+
+	for _, nv := range view.Nodes {
+		if nv.Status == nil || !nv.Status.Primary {
+			continue
+		}
+		if nv.Status.Active {
+			return Node{}, fmt.Errorf("primary %s is already active", nv.Node.ID)
+		}
+		// Not retried: a failback that timed out may still have happened
+		return nv.Node, c.once(ctx, nv.Node, http.MethodPost, PathFailback, nil)
+	}
+	return Node{}, ErrNoPrimaryNode
+}
+
+// RetireSecret has every node stop accepting the previous cluster secret.
+// It first confirms that all nodes answer and hold the same current secret;
+// otherwise nothing is retired. It returns the view it checked.
+func (c *ClusterClient) RetireSecret(ctx context.Context) (ClusterView, error) {
+	view := c.Status(ctx)
+
+	var current string
+	var retire []Node
+	for _, nv := range view.Nodes {
+		if nv.Err != nil {
+			return view, fmt.Errorf("%s unreachable: %w", nv.Node.ID, nv.Err)
+		}
+		secret := nv.Status.Secret
+		if secret == nil {
+			return view, fmt.Errorf("%s does not report its cluster secret; upgrade it first", nv.Node.ID)
+		}
+		if current == "" {
+			current = secret.Fingerprint
+		} else if secret.Fingerprint != current {
+			return view, fmt.Errorf("%s has not switched to the new secret (fingerprint %s, expected %s)",
+				nv.Node.ID, secret.Fingerprint, current)
+		}
+		if secret.Previous != "" {
+			retire = append(retire, nv.Node)
+		}
+	}
+	if len(retire) == 0 {
+		return view, fmt.Errorf("no node accepts a previous secret")
+	}
+
+	for _, n := range retire {
+		if err := c.do(ctx, n, http.MethodPost, PathRetireSecret, nil); err != nil {
+			return view, fmt.Errorf("%s: %w", n.ID, err)
+		}
+	}
+	return view, nil
+}
+
+// ApproveFailover approves the failover awaiting approval on the node that
+// requested it. An empty id approves whichever request is pending.
+func (c *ClusterClient) ApproveFailover(ctx context.Context, id string) (Node, error) {
+	return c.decide(ctx, id, PathApprove)
+}
+
+// RejectFailover rejects the failover awaiting approval; the node stays
+// active and does not ask again until it recovers
+func (c *ClusterClient) RejectFailover(ctx context.Context, id string) (Node, error) {
+	return c.decide(ctx, id, PathReject)
+}
+
+// decide posts a decision to the node with a pending request
+func (c *ClusterClient) decide(ctx context.Context, id, path string) (Node, error) {
+	view := c.Status(ctx)
+	for _, nv := range view.Nodes {
+		if nv.Status == nil || !nv.Status.Approval.Pending() {
+			continue
+		}
+		req := nv.Status.Approval
+		if id != "" && req.ID != id {
+			continue
+		}
+		// Not retried: an approved failover that timed out may still have happened
+		return nv.Node, c.once(ctx, nv.Node, http.MethodPost, path+"?id="+url.QueryEscape(req.ID), nil)
+	}
+	return Node{}, ErrNoApproval
+}
+
+// ConfirmActive has the node held on first boot take the validator role.
+// It refuses while another node is active.
+func (c *ClusterClient) ConfirmActive(ctx context.Context) (Node, error) {
+	view := c.Status(ctx)
+	if len(view.Active) > 0 {
+		return Node{}, fmt.Errorf("%s already active", strings.Join(view.Active, ", "))
+	}
+	for _, nv := range view.Nodes {
+		if nv.Status != nil && nv.Status.AwaitingConfirmation {
+			// Not retried: a confirmation that timed out may still have happened
+			return nv.Node, c.once(ctx, nv.Node, http.MethodPost, PathConfirmActive, nil)
+		}
+	}
+	return Node{}, ErrNoFirstBoot
+}
+
+// Drain takes node n out of service: if active it hands over to its
+// standby, and it refuses the validator role until undrained
+func (c *ClusterClient) Drain(ctx context.Context, n Node) error {
+	// Not retried: a drain that timed out may still have handed over
+	return c.once(ctx, n, http.MethodPost, PathDrain, nil)
+}
+
+// Undrain lets node n take the validator role again
+func (c *ClusterClient) Undrain(ctx context.Context, n Node) error {
+	return c.do(ctx, n, http.MethodPost, PathUndrain, nil)
+}
+
+// StartDrill starts a failover drill on node n
+func (c *ClusterClient) StartDrill(ctx context.Context, n Node, duration time.Duration) (DrillStatus, error) {
+	var status DrillStatus
+	path := PathDrill + "?duration=" + url.QueryEscape(duration.String())
+	err := c.once(ctx, n, http.MethodPost, path, &status)
+	return status, err
+}
+
+// DrillStatus returns the current or last drill on node n
+func (c *ClusterClient) DrillStatus(ctx context.Context, n Node) (DrillStatus, error) {
+	var status DrillStatus
+	err := c.do(ctx, n, http.MethodGet, PathDrill, &status)
+	return status, err
+}
+
+// RevertDrill fails back a running drill on node n now
+func (c *ClusterClient) RevertDrill(ctx context.Context, n Node) (DrillStatus, error) {
+	var status DrillStatus
+	err := c.do(ctx, n, http.MethodPost, PathDrillRevert, &status)
+	return status, err
+}
+
+// each POSTs path to every node concurrently
+func (c *ClusterClient) each(ctx context.Context, path string) error {
+	errs := make([]error, len(c.nodes))
+
+	var wg sync.WaitGroup
+	for i, n := range c.nodes {
+		wg.Add(1)
+		go func(i int, n Node) {
+			defer wg.Done()
+			if err := c.do(ctx, n, http.MethodPost, path, nil); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", n.ID, err)
+			}
+		}(i, n)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// do performs a request, retrying transport errors and 5xx answers
+func (c *ClusterClient) do(ctx context.Context, n Node, method, path string, out interface{}) error {
+	backoff := c.backoff
+	var err error
+	for attempt := 0; attempt <= c.retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+
+		err = c.once(ctx, n, method, path, out)
+		if !retryable(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// once performs a single request and decodes a JSON answer into out
+func (c *ClusterClient) once(ctx context.Context, n Node, method, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(n.URL, "/")+path, nil)
+	if err != nil {
+		return err
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &APIError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(body))}
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to parse response from %s: %w", n.ID, err)
+	}
+	return nil
+}
+
+// retryable reports whether a request may succeed if repeated
+func retryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= 500
+	}
+	return true
+}
@@ -0,0 +1,293 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeNode serves an admin status and records operator actions
+type fakeNode struct {
+	status    NodeStatus
+	failures  int32 // 503s to answer before succeeding
+	calls     int32
+	handoffs  int32
+	failbacks int32
+	approved  string
+	confirmed int32
+	drains    int32
+}
+
+func (f *fakeNode) serve(t *testing.T, token string) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&f.calls, 1)
+		if token != "" && r.Header.Get("Authorization") != "Bearer "+token {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if atomic.AddInt32(&f.failures, -1) >= 0 {
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+			return
+		}
+		switch r.URL.Path {
+		case PathStatus:
+			json.NewEncoder(w).Encode(f.status)
+		case PathPause:
+			f.status.Paused = true
+		case PathHandoff:
+			atomic.AddInt32(&f.handoffs, 1)
+		case PathFailback:
+			atomic.AddInt32(&f.failbacks, 1)
+		case PathRetireSecret:
+			f.status.Secret.Previous = ""
+		case PathApprove:
+			f.approved = r.URL.Query().Get("id")
+			f.status.Approval = nil
+		case PathConfirmActive:
+			atomic.AddInt32(&f.confirmed, 1)
+		case PathDrain:
+			atomic.AddInt32(&f.drains, 1)
+			f.status.Draining = true
+		case PathUndrain:
+			f.status.Draining = false
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newTestClient(t *testing.T, nodes ...Node) *ClusterClient {
+	c, err := New(Config{Nodes: nodes, Token: "secret", Backoff: time.Millisecond})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	return c
+}
+
+func TestClusterClient_Status(t *testing.T) {
+	a := &fakeNode{status: NodeStatus{NodeID: "a", Active: true, Healthy: true, Height: 100}}
+	b := &fakeNode{status: NodeStatus{NodeID: "b", Healthy: true, Height: 102}, failures: 1}
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	c := newTestClient(t,
+		Node{ID: "a", URL: a.serve(t, "secret").URL},
+		Node{ID: "b", URL: b.serve(t, "secret").URL},
+		Node{ID: "c", URL: down.URL},
+	)
+	view := c.Status(context.Background())
+
+	if view.Reachable() != 2 {
+		t.Errorf("expected 2 reachable nodes, got %d", view.Reachable())
+	}
+	if view.Nodes[2].Err == nil {
+		t.Error("expected an error for the unreachable node")
+	}
+	if len(view.Active) != 1 || view.Active[0] != "a" || view.SplitBrain() {
+		t.Errorf("expected only a active, got %v", view.Active)
+	}
+	if view.MaxHeight != 102 {
+		t.Errorf("expected max height 102, got %d", view.MaxHeight)
+	}
+	if atomic.LoadInt32(&b.calls) != 2 {
+		t.Errorf("expected the 503 to be retried once, got %d calls", b.calls)
+	}
+}
+
+func TestClusterClient_NoRetryOnClientError(t *testing.T) {
+	a := &fakeNode{}
+	srv := a.serve(t, "other-token")
+	c := newTestClient(t, Node{ID: "a", URL: srv.URL})
+
+	_, err := c.NodeStatus(context.Background(), c.Nodes()[0])
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 APIError, got %v", err)
+	}
+	if a.calls != 1 {
+		t.Errorf("expected a single attempt, got %d", a.calls)
+	}
+}
+
+func TestClusterClient_Pause(t *testing.T) {
+	a := &fakeNode{}
+	b := &fakeNode{}
+	c := newTestClient(t,
+		Node{ID: "a", URL: a.serve(t, "secret").URL},
+		Node{ID: "b", URL: b.serve(t, "secret").URL},
+	)
+
+	if err := c.Pause(context.Background()); err != nil {
+		t.Fatalf("Pause failed: %v", err)
+	}
+	if !a.status.Paused || !b.status.Paused {
+		t.Error("expected both nodes paused")
+	}
+}
+
+func TestClusterClient_Handoff(t *testing.T) {
+	a := &fakeNode{status: NodeStatus{NodeID: "a"}}
+	b := &fakeNode{status: NodeStatus{NodeID: "b", Active: true}}
+	c := newTestClient(t,
+		Node{ID: "a", URL: a.serve(t, "secret").URL},
+		Node{ID: "b", URL: b.serve(t, "secret").URL},
+	)
+
+	from, err := c.Handoff(context.Background())
+	if err != nil {
+		t.Fatalf("Handoff failed: %v", err)
+	}
+	if from.ID != "b" || b.handoffs != 1 || a.handoffs != 0 {
+		t.Errorf("expected handoff on b, got from=%s a=%d b=%d", from.ID, a.handoffs, b.handoffs)
+	}
+
+	// Refused while split-brained
+	a.status.Active = true
+	if _, err := c.Handoff(context.Background()); err == nil {
+		t.Error("expected handoff to be refused during split brain")
+	}
+
+	a.status.Active, b.status.Active = false, false
+	if _, err := c.Handoff(context.Background()); !errors.Is(err, ErrNoActiveNode) {
+		t.Errorf("expected ErrNoActiveNode, got %v", err)
+	}
+}
+
+func TestClusterClient_Failback(t *testing.T) {
+	a := &fakeNode{status: NodeStatus{NodeID: "a", Primary: true}}
+	b := &fakeNode{status: NodeStatus{NodeID: "b", Active: true}}
+	c := newTestClient(t,
+		Node{ID: "a", URL: a.serve(t, "secret").URL},
+		Node{ID: "b", URL: b.serve(t, "secret").URL},
+	)
+
+	to, err := c.Failback(context.Background())
+	if err != nil {
+		t.Fatalf("Failback failed: %v", err)
+	}
+	if to.ID != "a" || a.failbacks != 1 || b.failbacks != 0 {
+		t.Errorf("expected failback on a, got to=%s a=%d b=%d", to.ID, a.failbacks, b.failbacks)
+	}
+
+	// Nothing to do when the primary is already active
+	a.status.Active, b.status.Active = true, false
+	if _, err := c.Failback(context.Background()); err == nil {
+		t.Error("expected failback to be refused with an active primary")
+	}
+
+	a.status.Primary = false
+	if _, err := c.Failback(context.Background()); !errors.Is(err, ErrNoPrimaryNode) {
+		t.Errorf("expected ErrNoPrimaryNode, got %v", err)
+	}
+}
+
+func TestClusterClient_RetireSecret(t *testing.T) {
+	rotating := func() *SecretStatus { return &SecretStatus{Fingerprint: "new", Previous: "old"} }
+	a := &fakeNode{status: NodeStatus{NodeID: "a", Secret: rotating()}}
+	b := &fakeNode{status: NodeStatus{NodeID: "b", Secret: &SecretStatus{Fingerprint: "old"}}}
+	c := newTestClient(t,
+		Node{ID: "a", URL: a.serve(t, "secret").URL},
+		Node{ID: "b", URL: b.serve(t, "secret").URL},
+	)
+
+	if _, err := c.RetireSecret(context.Background()); err == nil {
+		t.Fatal("expected retirement to be refused while b has the old secret")
+	}
+	if a.status.Secret.Previous == "" {
+		t.Error("a retired its previous secret although b had not switched")
+	}
+
+	b.status.Secret = rotating()
+	if _, err := c.RetireSecret(context.Background()); err != nil {
+		t.Fatalf("RetireSecret failed: %v", err)
+	}
+	if a.status.Secret.Previous != "" || b.status.Secret.Previous != "" {
+		t.Error("previous secret still accepted")
+	}
+}
+
+func TestClusterClient_ApproveFailover(t *testing.T) {
+	a := &fakeNode{status: NodeStatus{NodeID: "a"}}
+	b := &fakeNode{status: NodeStatus{NodeID: "b", Active: true,
+		Approval: &ApprovalRequest{ID: "9f2c", Phase: "pending"}}}
+	c := newTestClient(t,
+		Node{ID: "a", URL: a.serve(t, "secret").URL},
+		Node{ID: "b", URL: b.serve(t, "secret").URL},
+	)
+
+	if _, err := c.ApproveFailover(context.Background(), "other"); !errors.Is(err, ErrNoApproval) {
+		t.Errorf("expected ErrNoApproval for an unknown request, got %v", err)
+	}
+	node, err := c.ApproveFailover(context.Background(), "")
+	if err != nil {
+		t.Fatalf("ApproveFailover failed: %v", err)
+	}
+	if node.ID != "b" || b.approved != "9f2c" {
+		t.Errorf("expected request 9f2c approved on b, got node=%s id=%q", node.ID, b.approved)
+	}
+	if _, err := c.ApproveFailover(context.Background(), ""); !errors.Is(err, ErrNoApproval) {
+		t.Errorf("expected ErrNoApproval once decided, got %v", err)
+	}
+}
+
+func TestClusterClient_ConfirmActive(t *testing.T) {
+	a := &fakeNode{status: NodeStatus{NodeID: "a", AwaitingConfirmation: true}}
+	b := &fakeNode{status: NodeStatus{NodeID: "b", Active: true}}
+	c := newTestClient(t,
+		Node{ID: "a", URL: a.serve(t, "secret").URL},
+		Node{ID: "b", URL: b.serve(t, "secret").URL},
+	)
+
+	// Refused while another node signs
+	if _, err := c.ConfirmActive(context.Background()); err == nil {
+		t.Fatal("expected confirmation to be refused with an active node")
+	}
+	if a.confirmed != 0 {
+		t.Fatal("expected no confirmation sent")
+	}
+
+	b.status.Active = false
+	n, err := c.ConfirmActive(context.Background())
+	if err != nil {
+		t.Fatalf("ConfirmActive failed: %v", err)
+	}
+	if n.ID != "a" || a.confirmed != 1 {
+		t.Errorf("expected confirmation on a, got %s (%d)", n.ID, a.confirmed)
+	}
+
+	a.status.AwaitingConfirmation = false
+	if _, err := c.ConfirmActive(context.Background()); !errors.Is(err, ErrNoFirstBoot) {
+		t.Errorf("expected ErrNoFirstBoot, got %v", err)
+	}
+}
+
+func TestClusterClient_Drain(t *testing.T) {
+	a := &fakeNode{status: NodeStatus{NodeID: "a", Active: true}, failures: 1}
+	n := Node{ID: "a", URL: a.serve(t, "secret").URL}
+	c := newTestClient(t, n)
+
+	// A drain may have handed over before the error, so it is not retried
+	if err := c.Drain(context.Background(), n); err == nil {
+		t.Fatal("expected the 503 to be returned")
+	}
+	if err := c.Drain(context.Background(), n); err != nil {
+		t.Fatalf("Drain failed: %v", err)
+	}
+	if a.drains != 1 || !a.status.Draining {
+		t.Fatalf("expected one drain, got %d (draining=%v)", a.drains, a.status.Draining)
+	}
+
+	if err := c.Undrain(context.Background(), n); err != nil {
+		t.Fatalf("Undrain failed: %v", err)
+	}
+	if a.status.Draining {
+		t.Error("expected the node undrained")
+	}
+}
@@ -0,0 +1,178 @@
+//go:build integration
+
+package integration
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aldebaranode/syncguard/internal/communication"
+)
+
+// syncguardBin is the daemon binary TestMain builds from this tree
+var syncguardBin string
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "syncguard-it")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	syncguardBin = filepath.Join(dir, "syncguard")
+	build := exec.Command("go", "build", "-o", syncguardBin, "../../cli")
+	build.Stdout, build.Stderr = os.Stdout, os.Stderr
+	if err := build.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "build syncguard: %v\n", err)
+		os.Exit(1)
+	}
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// images returns the CometBFT images to run the suite against:
+// SYNCGUARD_IT_IMAGES, comma-separated, or the release syncguard builds with
+func images() []string {
+	if list := os.Getenv("SYNCGUARD_IT_IMAGES"); list != "" {
+		return strings.Split(list, ",")
+	}
+	return []string{defaultImage}
+}
+
+// daemon is a syncguard process managing one node's container
+type daemon struct {
+	id   string
+	port int
+	dir  string
+	cmd  *exec.Cmd
+}
+
+// daemonConfig is a two-node cluster member with fast health checks. The
+// passive keeps its node synced on the mock key (failover.preheat), so
+// the chain has a copy to continue from once the active's node is gone.
+const daemonConfig = `secret: "integration-secret"
+node:
+  id: %q
+  role: %q
+  is_primary: %t
+  port: %d
+  data_dir: %q
+validator:
+  enabled: true
+  mode: "docker"
+  container: %q
+  stop_timeout: 10
+  restart_delay: 1
+peers:
+  - id: %q
+    address: "127.0.0.1:%d"
+cometbft:
+  rpc_url: %q
+  key_path: %q
+  state_path: %q
+  insecure_key_files: "warn"
+health:
+  interval: 1
+  min_peers: 1
+  timeout: 2
+failover:
+  retry_attempts: 3
+  state_sync_interval: 1
+  grace_period: 600
+  preheat: true
+logging:
+  level: "debug"
+  file: %q
+`
+
+// startDaemon writes the config of a cluster member for node and runs it
+func startDaemon(t *testing.T, node *cometNode, id, role string, port int, peerID string, peerPort int, args ...string) *daemon {
+	t.Helper()
+	d := &daemon{id: id, port: port, dir: t.TempDir()}
+	config := fmt.Sprintf(daemonConfig, id, role, role == "active", port, filepath.Join(d.dir, "data"),
+		node.container, peerID, peerPort, node.RPCURL(), node.KeyPath(), node.StatePath(),
+		filepath.Join(d.dir, "syncguard.log"))
+	configPath := filepath.Join(d.dir, "config.yaml")
+	if err := os.WriteFile(configPath, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	d.cmd = exec.Command(syncguardBin, append([]string{"--config", configPath}, args...)...)
+	if err := d.cmd.Start(); err != nil {
+		t.Fatalf("start %s: %v", id, err)
+	}
+	t.Cleanup(func() {
+		d.cmd.Process.Kill()
+		d.cmd.Wait()
+		if t.Failed() {
+			if log, err := os.ReadFile(filepath.Join(d.dir, "syncguard.log")); err == nil {
+				t.Logf("%s log:\n%s", id, log)
+			}
+		}
+	})
+	return d
+}
+
+// Health returns the daemon's /health, nil while it does not answer
+func (d *daemon) Health() *communication.PeerHealth {
+	var h communication.PeerHealth
+	if err := getJSON(fmt.Sprintf("http://127.0.0.1:%d%s", d.port, communication.PathHealth), &h); err != nil {
+		return nil
+	}
+	return &h
+}
+
+// TestFailover_KillActiveContainer runs two syncguard daemons on a
+// single-validator localnet with a sentry, kills the active node's
+// container, and checks that the standby takes the key and the chain goes
+// on with blocks the validator signed
+func TestFailover_KillActiveContainer(t *testing.T) {
+	for _, image := range images() {
+		t.Run(image, func(t *testing.T) {
+			chain := newLocalnet(t, image, "validator-1", "validator-2", "sentry")
+			chain.Start("sentry")
+
+			portA, portB := freePort(t), freePort(t)
+			standby := startDaemon(t, chain.nodes["validator-2"], "validator-2", "passive", portB, "validator-1", portA)
+			active := startDaemon(t, chain.nodes["validator-1"], "validator-1", "active", portA, "validator-2", portB,
+				"--confirm-active")
+
+			waitFor(t, "blocks from validator-1", 2*time.Minute, func() bool {
+				return chain.Height("sentry") >= 5
+			})
+			if h := active.Health(); h == nil || !h.Active {
+				t.Fatalf("validator-1 not active: %+v", h)
+			}
+			waitFor(t, "the standby node to sync", time.Minute, func() bool {
+				return chain.Height("validator-2") >= chain.Height("sentry")-1
+			})
+
+			chain.Kill("validator-1")
+			killed := chain.Height("sentry")
+			t.Logf("killed validator-1 at height %d", killed)
+
+			waitFor(t, "validator-2 to take over", 2*time.Minute, func() bool {
+				h := standby.Health()
+				return h != nil && h.Active
+			})
+			waitFor(t, "blocks after the failover", 2*time.Minute, func() bool {
+				return chain.Height("sentry") >= killed+5
+			})
+
+			for height := killed + 1; height < chain.Height("sentry"); height++ {
+				signed, err := chain.SignedBy("sentry", height)
+				if err != nil {
+					t.Fatalf("commit %d: %v", height, err)
+				}
+				if !signed {
+					t.Errorf("block %d lacks the validator's signature", height)
+				}
+			}
+		})
+	}
+}
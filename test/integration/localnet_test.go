@@ -0,0 +1,300 @@
+//go:build integration
+
+// Package integration runs syncguard daemons against real CometBFT nodes in
+// Docker. It is left out of `go test ./...`; run it with `make integration`.
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	cmtcfg "github.com/cometbft/cometbft/config"
+	"github.com/cometbft/cometbft/crypto"
+	"github.com/cometbft/cometbft/crypto/ed25519"
+	"github.com/cometbft/cometbft/p2p"
+	"github.com/cometbft/cometbft/privval"
+	"github.com/cometbft/cometbft/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+	"github.com/docker/go-connections/nat"
+)
+
+// defaultImage is the CometBFT release the suite runs against unless
+// SYNCGUARD_IT_IMAGES lists others
+const defaultImage = "cometbft/cometbft:v1.0.1"
+
+const rpcPort = nat.Port("26657/tcp")
+
+// cometNode is one CometBFT container of the localnet
+type cometNode struct {
+	// name is the container's alias on the localnet network
+	name string
+	// home is the host directory mounted as the node's home
+	home      string
+	id        p2p.ID
+	rpcPort   int
+	container string
+}
+
+// RPCURL is the node's RPC as the host reaches it
+func (n *cometNode) RPCURL() string {
+	return fmt.Sprintf("http://127.0.0.1:%d", n.rpcPort)
+}
+
+// KeyPath is the node's validator key on the host
+func (n *cometNode) KeyPath() string {
+	return filepath.Join(n.home, "config", "priv_validator_key.json")
+}
+
+// StatePath is the node's validator state on the host
+func (n *cometNode) StatePath() string {
+	return filepath.Join(n.home, "data", "priv_validator_state.json")
+}
+
+// localnet is a single-validator chain of CometBFT containers running the
+// built-in kvstore app. The first node holds the validator key; the others
+// are full nodes with keys of their own that cannot sign for it.
+type localnet struct {
+	t         *testing.T
+	docker    *client.Client
+	image     string
+	network   string
+	chainID   string
+	validator string
+	nodes     map[string]*cometNode
+}
+
+// newLocalnet writes the homes of the named nodes and creates their
+// containers, without starting them. The test is skipped when no Docker
+// daemon answers.
+func newLocalnet(t *testing.T, cometImage string, names ...string) *localnet {
+	t.Helper()
+	ctx := context.Background()
+
+	docker, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		t.Skipf("docker client: %v", err)
+	}
+	if _, err := docker.Ping(ctx); err != nil {
+		docker.Close()
+		t.Skipf("docker daemon not reachable: %v", err)
+	}
+	t.Cleanup(func() { docker.Close() })
+
+	pull, err := docker.ImagePull(ctx, cometImage, image.PullOptions{})
+	if err != nil {
+		t.Fatalf("pull %s: %v", cometImage, err)
+	}
+	io.Copy(io.Discard, pull)
+	pull.Close()
+
+	suffix := strconv.FormatInt(time.Now().UnixNano(), 36)
+	l := &localnet{
+		t:       t,
+		docker:  docker,
+		image:   cometImage,
+		network: "syncguard-it-" + suffix,
+		chainID: "syncguard-it",
+		nodes:   make(map[string]*cometNode),
+	}
+	if _, err := docker.NetworkCreate(ctx, l.network, network.CreateOptions{}); err != nil {
+		t.Fatalf("create network: %v", err)
+	}
+	t.Cleanup(func() { docker.NetworkRemove(context.Background(), l.network) })
+
+	genValidatorKey := func() (crypto.PrivKey, error) { return ed25519.GenPrivKey(), nil }
+	var genesisValidator types.GenesisValidator
+	for i, name := range names {
+		home := filepath.Join(t.TempDir(), name)
+		cmtcfg.EnsureRoot(home)
+		pv, err := privval.GenFilePV(
+			filepath.Join(home, "config", "priv_validator_key.json"),
+			filepath.Join(home, "data", "priv_validator_state.json"),
+			genValidatorKey)
+		if err != nil {
+			t.Fatalf("generate key for %s: %v", name, err)
+		}
+		pv.Save()
+		nodeKey, err := p2p.LoadOrGenNodeKey(filepath.Join(home, "config", "node_key.json"))
+		if err != nil {
+			t.Fatalf("generate node key for %s: %v", name, err)
+		}
+		if i == 0 {
+			pub, err := pv.GetPubKey()
+			if err != nil {
+				t.Fatal(err)
+			}
+			genesisValidator = types.GenesisValidator{Address: pub.Address(), PubKey: pub, Power: 10, Name: name}
+			l.validator = strings.ToUpper(pub.Address().String())
+		}
+		l.nodes[name] = &cometNode{name: name, home: home, id: nodeKey.ID(), rpcPort: freePort(t)}
+	}
+
+	genesis := types.GenesisDoc{
+		ChainID:         l.chainID,
+		GenesisTime:     time.Now().UTC(),
+		ConsensusParams: types.DefaultConsensusParams(),
+		Validators:      []types.GenesisValidator{genesisValidator},
+	}
+	for _, node := range l.nodes {
+		if err := genesis.SaveAs(filepath.Join(node.home, "config", "genesis.json")); err != nil {
+			t.Fatalf("write genesis: %v", err)
+		}
+		l.writeConfig(node)
+		l.create(node)
+	}
+	return l
+}
+
+// writeConfig points the node at every other node of the localnet
+func (l *localnet) writeConfig(node *cometNode) {
+	var peers []string
+	for _, other := range l.nodes {
+		if other != node {
+			peers = append(peers, fmt.Sprintf("%s@%s:26656", other.id, other.name))
+		}
+	}
+	cfg := cmtcfg.DefaultConfig()
+	cfg.Moniker = node.name
+	cfg.ProxyApp = "kvstore"
+	cfg.RPC.ListenAddress = "tcp://0.0.0.0:26657"
+	cfg.P2P.ListenAddress = "tcp://0.0.0.0:26656"
+	cfg.P2P.PersistentPeers = strings.Join(peers, ",")
+	cfg.P2P.AddrBookStrict = false
+	cfg.P2P.AllowDuplicateIP = true
+	cmtcfg.WriteConfigFile(filepath.Join(node.home, "config", "config.toml"), cfg)
+}
+
+// create creates the node's container, running as the test's user so
+// syncguard can swap the key files the node writes
+func (l *localnet) create(node *cometNode) {
+	ctx := context.Background()
+	resp, err := l.docker.ContainerCreate(ctx,
+		&container.Config{
+			Image:        l.image,
+			User:         fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid()),
+			Entrypoint:   []string{"cometbft"},
+			Cmd:          []string{"node", "--home", "/cometbft"},
+			ExposedPorts: nat.PortSet{rpcPort: struct{}{}},
+		},
+		&container.HostConfig{
+			Binds: []string{node.home + ":/cometbft"},
+			PortBindings: nat.PortMap{rpcPort: []nat.PortBinding{
+				{HostIP: "127.0.0.1", HostPort: strconv.Itoa(node.rpcPort)},
+			}},
+		},
+		&network.NetworkingConfig{EndpointsConfig: map[string]*network.EndpointSettings{
+			l.network: {Aliases: []string{node.name}},
+		}},
+		nil, l.network+"-"+node.name)
+	if err != nil {
+		l.t.Fatalf("create container %s: %v", node.name, err)
+	}
+	node.container = resp.ID
+	l.t.Cleanup(func() {
+		l.docker.ContainerRemove(context.Background(), resp.ID, container.RemoveOptions{Force: true})
+	})
+}
+
+// Start starts a node syncguard does not manage
+func (l *localnet) Start(name string) {
+	if err := l.docker.ContainerStart(context.Background(), l.nodes[name].container, container.StartOptions{}); err != nil {
+		l.t.Fatalf("start %s: %v", name, err)
+	}
+}
+
+// Kill kills a node's container, as a crashed host would stop it
+func (l *localnet) Kill(name string) {
+	if err := l.docker.ContainerKill(context.Background(), l.nodes[name].container, "SIGKILL"); err != nil {
+		l.t.Fatalf("kill %s: %v", name, err)
+	}
+}
+
+// Height returns the latest height the node has, 0 while it does not answer
+func (l *localnet) Height(name string) int64 {
+	var status struct {
+		Result struct {
+			SyncInfo struct {
+				LatestBlockHeight string `json:"latest_block_height"`
+			} `json:"sync_info"`
+		} `json:"result"`
+	}
+	if err := getJSON(l.nodes[name].RPCURL()+"/status", &status); err != nil {
+		return 0
+	}
+	height, _ := strconv.ParseInt(status.Result.SyncInfo.LatestBlockHeight, 10, 64)
+	return height
+}
+
+// SignedBy reports whether the commit for height carries the validator's
+// signature
+func (l *localnet) SignedBy(name string, height int64) (bool, error) {
+	var commit struct {
+		Result struct {
+			SignedHeader struct {
+				Commit struct {
+					Signatures []struct {
+						BlockIDFlag      int    `json:"block_id_flag"`
+						ValidatorAddress string `json:"validator_address"`
+					} `json:"signatures"`
+				} `json:"commit"`
+			} `json:"signed_header"`
+		} `json:"result"`
+	}
+	if err := getJSON(fmt.Sprintf("%s/commit?height=%d", l.nodes[name].RPCURL(), height), &commit); err != nil {
+		return false, err
+	}
+	for _, sig := range commit.Result.SignedHeader.Commit.Signatures {
+		if strings.EqualFold(sig.ValidatorAddress, l.validator) && sig.BlockIDFlag == int(types.BlockIDFlagCommit) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// waitFor polls cond until it holds or timeout passes
+func waitFor(t *testing.T, what string, timeout time.Duration, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out after %s waiting for %s", timeout, what)
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+func getJSON(url string, out interface{}) error {
+	resp, err := (&http.Client{Timeout: 2 * time.Second}).Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned status %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// freePort returns a TCP port nothing listens on right now
+func freePort(t *testing.T) int {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}